@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/exec"
@@ -12,6 +13,8 @@ import (
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/spf13/cobra"
+
+	"github.com/dorcha-inc/orla/internal/tui"
 )
 
 // newCallCmd creates a new command to call an MCP tool
@@ -23,6 +26,8 @@ func newCallCmd() *cobra.Command {
 		argsJSON  string
 		stdin     string
 		orlaBin   string
+		maxLines  int
+		dump      bool
 	)
 
 	cmd := &cobra.Command{
@@ -39,8 +44,14 @@ orla binary will be spawned and communication happens via stdin/stdout.`,
   # Call a tool with stdin input
   orla-test call --transport http --tool greet --args '{"language":"es"}' --stdin "María"
 
+  # Show at most the first 20 lines of output
+  orla-test call --tool logs --max-output-lines 20
+
   # Call a tool via stdio
-  orla-test call --transport stdio --tool hello --args '{"name":"World"}'`,
+  orla-test call --transport stdio --tool hello --args '{"name":"World"}'
+
+  # Print the raw JSON-RPC messages exchanged with the server to stderr
+  orla-test call --tool hello --args '{"name":"World"}' --dump-request`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			if toolName == "" {
 				return fmt.Errorf("tool name is required (use --tool)")
@@ -60,12 +71,18 @@ orla binary will be spawned and communication happens via stdin/stdout.`,
 				toolArgs["stdin"] = stdin
 			}
 
+			// Raw JSON-RPC messages go to stderr so they don't mix with the tool output
+			var dumpWriter io.Writer
+			if dump {
+				dumpWriter = os.Stderr
+			}
+
 			var output string
 			var err error
 
 			switch transport {
 			case "http":
-				output, err = testToolHTTP(port, toolName, toolArgs)
+				output, err = testToolHTTP(port, toolName, toolArgs, dumpWriter)
 			case "stdio":
 				// Ensure orla binary is available for stdio transport
 				if orlaBin == "" {
@@ -75,7 +92,7 @@ orla binary will be spawned and communication happens via stdin/stdout.`,
 					}
 					orlaBin = binPath
 				}
-				output, err = testToolStdio(orlaBin, toolName, toolArgs)
+				output, err = testToolStdio(orlaBin, toolName, toolArgs, dumpWriter)
 			default:
 				return fmt.Errorf("unknown transport: %s (supported: http, stdio)", transport)
 			}
@@ -84,7 +101,8 @@ orla binary will be spawned and communication happens via stdin/stdout.`,
 				return err
 			}
 
-			output = strings.TrimSuffix(output, "\n")
+			// Only the displayed output is truncated, the tool result itself is unchanged
+			output = strings.TrimSuffix(tui.TruncateLines(output, maxLines), "\n")
 			fmt.Println(output)
 			return nil
 		},
@@ -95,6 +113,8 @@ orla binary will be spawned and communication happens via stdin/stdout.`,
 	cmd.Flags().StringVarP(&toolName, "tool", "n", "", "Tool name to call (required)")
 	cmd.Flags().StringVarP(&argsJSON, "args", "a", "{}", "Tool arguments as JSON")
 	cmd.Flags().StringVarP(&stdin, "stdin", "s", "", "Stdin input for tool")
+	cmd.Flags().IntVar(&maxLines, "max-output-lines", 0, "Maximum number of output lines to display (0 for no limit)")
+	cmd.Flags().BoolVar(&dump, "dump-request", false, "Print the raw JSON-RPC requests and responses to stderr")
 	cmd.Flags().StringVar(&orlaBin, "orla-bin", "", "Path to orla binary (for stdio transport, default: auto-detect)")
 
 	if err := cmd.MarkFlagRequired("tool"); err != nil {
@@ -105,16 +125,10 @@ orla binary will be spawned and communication happens via stdin/stdout.`,
 	return cmd
 }
 
-func testToolHTTP(port int, toolName string, args map[string]any) (string, error) {
+func testToolHTTP(port int, toolName string, args map[string]any, dump io.Writer) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// Create MCP client
-	client := mcp.NewClient(&mcp.Implementation{
-		Name:    "orla-test",
-		Version: "1.0.0",
-	}, nil)
-
 	// Create HTTP transport
 	transport := &mcp.StreamableClientTransport{
 		Endpoint: fmt.Sprintf("http://localhost:%d/mcp", port),
@@ -123,28 +137,27 @@ func testToolHTTP(port int, toolName string, args map[string]any) (string, error
 		},
 	}
 
-	// Connect to server (this initializes the session)
-	session, err := client.Connect(ctx, transport, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to connect: %w", err)
-	}
-	defer session.Close() //nolint:errcheck // Ignore close errors on session
+	return callTool(ctx, transport, toolName, args, dump)
+}
 
-	// Call tool
-	result, err := session.CallTool(ctx, &mcp.CallToolParams{
-		Name:      toolName,
-		Arguments: args,
-	})
-	if err != nil {
-		return "", fmt.Errorf("tool call failed: %w", err)
+func testToolStdio(orlaBin string, toolName string, args map[string]any, dump io.Writer) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Create stdio transport (spawns orla process)
+	transport := &mcp.CommandTransport{
+		Command: exec.Command(orlaBin, "serve", "--stdio"),
 	}
 
-	return extractToolOutput(result), nil
+	return callTool(ctx, transport, toolName, args, dump)
 }
 
-func testToolStdio(orlaBin string, toolName string, args map[string]any) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+// callTool connects to the server over transport, calls the tool, and returns its output.
+// If dump is non-nil, every raw JSON-RPC message is written to it as it is sent or received.
+func callTool(ctx context.Context, transport mcp.Transport, toolName string, args map[string]any, dump io.Writer) (string, error) {
+	if dump != nil {
+		transport = newDumpTransport(transport, dump)
+	}
 
 	// Create MCP client
 	client := mcp.NewClient(&mcp.Implementation{
@@ -152,12 +165,7 @@ func testToolStdio(orlaBin string, toolName string, args map[string]any) (string
 		Version: "1.0.0",
 	}, nil)
 
-	// Create stdio transport (spawns orla process)
-	transport := &mcp.CommandTransport{
-		Command: exec.Command(orlaBin, "serve", "--stdio"),
-	}
-
-	// Connect to server
+	// Connect to server (this initializes the session)
 	session, err := client.Connect(ctx, transport, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to connect: %w", err)
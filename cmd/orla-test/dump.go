@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// dumpSentPrefix marks a JSON-RPC message sent to the server in the dump output
+	dumpSentPrefix = "--> "
+	// dumpReceivedPrefix marks a JSON-RPC message received from the server in the dump output
+	dumpReceivedPrefix = "<-- "
+)
+
+// dumpTransport wraps an MCP transport and writes every raw JSON-RPC message exchanged
+// with the server to w, one message per line, for --dump-request
+type dumpTransport struct {
+	transport mcp.Transport
+	w         io.Writer
+}
+
+// newDumpTransport wraps transport so its JSON-RPC traffic is written to w
+func newDumpTransport(transport mcp.Transport, w io.Writer) *dumpTransport {
+	return &dumpTransport{transport: transport, w: w}
+}
+
+// Connect connects the underlying transport and returns a connection that dumps its messages
+func (t *dumpTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	conn, err := t.transport.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &dumpConn{Connection: conn, w: t.w}, nil
+}
+
+// dumpConn is an MCP connection that dumps messages as they are sent and received
+type dumpConn struct {
+	mcp.Connection
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// Write dumps msg before sending it, so the payload is visible even if the send fails
+func (c *dumpConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	c.dump(dumpSentPrefix, msg)
+	return c.Connection.Write(ctx, msg)
+}
+
+// Read dumps each received message before it is handed to the client for interpretation
+func (c *dumpConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	msg, err := c.Connection.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.dump(dumpReceivedPrefix, msg)
+	return msg, nil
+}
+
+func (c *dumpConn) dump(prefix string, msg jsonrpc.Message) {
+	data, err := jsonrpc.EncodeMessage(msg)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		fmt.Fprintf(c.w, "%sfailed to encode message: %v\n", prefix, err)
+		return
+	}
+	fmt.Fprintf(c.w, "%s%s\n", prefix, data)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type helloInput struct {
+	Name string `json:"name"`
+}
+
+// newHelloServer creates an MCP server with a single hello tool that greets its name argument
+func newHelloServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "hello", Description: "Says hello"},
+		func(_ context.Context, _ *mcp.CallToolRequest, in helloInput) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: "Hello, " + in.Name + "!"}},
+			}, nil, nil
+		})
+	return server
+}
+
+// dumpedMessage is a JSON-RPC message as written by --dump-request
+type dumpedMessage struct {
+	ID     any             `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+}
+
+// parseDump splits the dump output into the messages sent to and received from the server
+func parseDump(t *testing.T, dump string) (sent []dumpedMessage, received []dumpedMessage) {
+	t.Helper()
+
+	for line := range strings.Lines(dump) {
+		line = strings.TrimSuffix(line, "\n")
+		var msg dumpedMessage
+		switch {
+		case strings.HasPrefix(line, dumpSentPrefix):
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, dumpSentPrefix)), &msg), line)
+			sent = append(sent, msg)
+		case strings.HasPrefix(line, dumpReceivedPrefix):
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, dumpReceivedPrefix)), &msg), line)
+			received = append(received, msg)
+		default:
+			t.Fatalf("unexpected dump line: %q", line)
+		}
+	}
+	return sent, received
+}
+
+// findMessage returns the first message with the given method
+func findMessage(t *testing.T, messages []dumpedMessage, method string) dumpedMessage {
+	t.Helper()
+
+	for _, msg := range messages {
+		if msg.Method == method {
+			return msg
+		}
+	}
+	t.Fatalf("no %s message in dump", method)
+	return dumpedMessage{}
+}
+
+// assertDumpedCall checks the dump holds the initialize and tools/call requests of a hello call,
+// along with the raw response to the call
+func assertDumpedCall(t *testing.T, dump string) {
+	t.Helper()
+
+	sent, received := parseDump(t, dump)
+
+	initialize := findMessage(t, sent, "initialize")
+	var initParams mcp.InitializeParams
+	require.NoError(t, json.Unmarshal(initialize.Params, &initParams))
+	require.NotNil(t, initParams.ClientInfo)
+	assert.Equal(t, "orla-test", initParams.ClientInfo.Name)
+
+	call := findMessage(t, sent, "tools/call")
+	expectedParams, err := json.Marshal(&mcp.CallToolParams{
+		Name:      "hello",
+		Arguments: map[string]any{"name": "World"},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expectedParams), string(call.Params))
+
+	// The response to the call carries the same ID and the raw tool result
+	var callResponse *dumpedMessage
+	for i := range received {
+		if received[i].Method == "" && received[i].ID == call.ID {
+			callResponse = &received[i]
+		}
+	}
+	require.NotNil(t, callResponse, "no response to tools/call in dump")
+	assert.Contains(t, string(callResponse.Result), "Hello, World!")
+}
+
+func TestCallTool_DumpRequest(t *testing.T) {
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := newHelloServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	var dump bytes.Buffer
+	output, err := callTool(ctx, clientTransport, "hello", map[string]any{"name": "World"}, &dump)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, World!", output)
+
+	assertDumpedCall(t, dump.String())
+}
+
+func TestCallTool_NoDumpByDefault(t *testing.T) {
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := newHelloServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	output, err := callTool(ctx, clientTransport, "hello", map[string]any{"name": "World"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, World!", output)
+}
+
+func TestTestToolHTTP_DumpRequest(t *testing.T) {
+	server := newHelloServer()
+	httpServer := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil))
+	t.Cleanup(httpServer.Close)
+
+	serverURL, err := url.Parse(httpServer.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+
+	var dump bytes.Buffer
+	output, err := testToolHTTP(port, "hello", map[string]any{"name": "World"}, &dump)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, World!", output)
+
+	assertDumpedCall(t, dump.String())
+}
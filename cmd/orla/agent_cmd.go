@@ -1,6 +1,8 @@
 package main
 
 import (
+	"fmt"
+
 	"github.com/dorcha-inc/orla/internal/agent"
 	"github.com/spf13/cobra"
 )
@@ -8,9 +10,15 @@ import (
 // newAgentCmd creates the agent command for one-shot execution
 func newAgentCmd() *cobra.Command {
 	var modelFlag string
+	var formatFlag string
+	var noCacheFlag bool
+	var failOnErrorFlag bool
+	var deadlineFlag int
+	var promptTemplateFlag string
+	var varFlags []string
 
 	cmd := &cobra.Command{
-		Use:   "agent <prompt>",
+		Use:   "agent [prompt]",
 		Short: "Execute a one-shot agent prompt",
 		Long: `Execute a one-shot agent prompt. Orla processes the prompt, selects
 and invokes appropriate tools, and returns the result.
@@ -25,15 +33,65 @@ quote it in the shell. For example:
 
 You can also pipe input to the command:
   cat file.txt | orla agent "summarize this"
-  orla agent "summarize this" < file.txt`,
-		Args: cobra.ExactArgs(1),
+  orla agent "summarize this" < file.txt
+
+Tool errors are normally returned to the model so it can recover. Use --fail-on-error
+to abort the run with the tool's error instead, e.g. in scripts.
+
+Use --deadline to limit the whole run, across all model turns and tool calls, to a number
+of seconds. When it passes, the run stops and prints what the model produced so far:
+  orla agent "audit this repository" --deadline 120
+
+For repeatable tasks, render the prompt from a Go text/template file instead, referencing
+variables as {{.name}} and setting them with --var. Every referenced variable must be set:
+  orla agent --prompt-template review.tmpl --var file=main.go --var focus=errors
+
+Use --format to make an Ollama model answer with valid JSON, or with JSON matching a schema:
+  orla agent "list three colors" --format json
+  orla agent "list three colors" --format '{"type":"array","items":{"type":"string"}}'`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			prompt, err := resolveAgentPrompt(args, promptTemplateFlag, varFlags)
+			if err != nil {
+				return err
+			}
+
 			// Execute agent prompt (all logic is in agent package, including stdin reading)
-			return agent.ExecuteAgentPrompt(args[0], modelFlag)
+			return agent.ExecuteAgentPrompt(prompt, modelFlag, formatFlag, noCacheFlag, failOnErrorFlag, deadlineFlag)
 		},
 	}
 
 	cmd.Flags().StringVarP(&modelFlag, "model", "m", "", "Model to use (e.g., ollama:llama3)")
+	cmd.Flags().StringVar(&formatFlag, "format", "", "Constrain the response to \"json\" or to a JSON schema (Ollama)")
+	cmd.Flags().StringVar(&promptTemplateFlag, "prompt-template", "", "Render the prompt from a Go text/template file")
+	cmd.Flags().StringArrayVar(&varFlags, "var", nil, "Set a prompt template variable (KEY=VALUE, repeatable)")
+	cmd.Flags().BoolVar(&noCacheFlag, "no-cache", false, "Bypass the model response cache")
+	cmd.Flags().BoolVar(&failOnErrorFlag, "fail-on-error", false, "Abort on the first failed tool call instead of letting the model recover")
+	cmd.Flags().IntVar(&deadlineFlag, "deadline", 0, "Time limit for the whole run in seconds (overrides run_deadline)")
 
 	return cmd
 }
+
+// resolveAgentPrompt returns the prompt given as an argument, or rendered from the prompt template
+// with the given KEY=VALUE variables. Exactly one of the two must be given.
+func resolveAgentPrompt(args []string, promptTemplate string, vars []string) (string, error) {
+	if promptTemplate == "" {
+		if len(vars) > 0 {
+			return "", fmt.Errorf("--var requires --prompt-template")
+		}
+		if len(args) == 0 {
+			return "", fmt.Errorf("a prompt or --prompt-template is required")
+		}
+		return args[0], nil
+	}
+
+	if len(args) > 0 {
+		return "", fmt.Errorf("cannot use both a prompt argument and --prompt-template")
+	}
+
+	templateVars, err := agent.ParseTemplateVars(vars)
+	if err != nil {
+		return "", err
+	}
+	return agent.RenderPromptTemplate(promptTemplate, templateVars)
+}
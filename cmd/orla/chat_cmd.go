@@ -0,0 +1,84 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/dorcha-inc/orla/internal/agent"
+)
+
+// newChatCmd creates the chat command for interactive, persisted conversations
+func newChatCmd() *cobra.Command {
+	var sessionFlag string
+	var modelFlag string
+	var noCacheFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "chat",
+		Short: "Start an interactive chat that persists across restarts",
+		Long: `Start an interactive agent chat. Each line you enter is a prompt, and the
+conversation is saved to a named session after every turn, so running
+orla chat with the same session later picks up where you left off.
+
+Several orla processes can use the same session at once: each turn is
+appended to the latest saved history.
+
+Inside the chat:
+  /session NAME  switch to another session, creating it if needed
+  /sessions      list sessions
+  /exit          leave the chat
+
+Examples:
+  orla chat
+  orla chat --session work
+  orla chat --session work -m ollama:llama3`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return agent.ExecuteChat(sessionFlag, modelFlag, noCacheFlag)
+		},
+	}
+
+	cmd.Flags().StringVarP(&sessionFlag, "session", "s", agent.DefaultSessionName, "Name of the session to resume or create")
+	cmd.Flags().StringVarP(&modelFlag, "model", "m", "", "Model to use (e.g., ollama:llama3)")
+	cmd.Flags().BoolVar(&noCacheFlag, "no-cache", false, "Bypass the model response cache")
+
+	return cmd
+}
+
+// newSessionsCmd creates the sessions command
+func newSessionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "Manage chat sessions",
+		Long:  `Manage the named chat sessions saved by orla chat.`,
+	}
+
+	cmd.AddCommand(newSessionsListCmd())
+	cmd.AddCommand(newSessionsRmCmd())
+
+	return cmd
+}
+
+// newSessionsListCmd creates the sessions list command
+func newSessionsListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List chat sessions",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return agent.ListSessions(cmd.OutOrStdout())
+		},
+	}
+}
+
+// newSessionsRmCmd creates the sessions rm command
+func newSessionsRmCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rm SESSION-NAME...",
+		Short: "Remove chat sessions",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return agent.RemoveSessions(args, cmd.OutOrStdout())
+		},
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dorcha-inc/orla/internal/config"
+)
+
+// defaultEditor is the editor orla config edit opens when $EDITOR is not set
+const defaultEditor = "vi"
+
+// newConfigCmd creates the config command
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage orla configuration",
+		Long: `Manage orla's configuration files. Changes are made to the project config
+(./orla.yaml) if it exists, and to the user config (~/.orla/config.yaml) otherwise.`,
+	}
+
+	cmd.AddCommand(newConfigUnsetCmd())
+	cmd.AddCommand(newConfigEditCmd())
+
+	return cmd
+}
+
+// newConfigUnsetCmd creates the config unset command
+func newConfigUnsetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unset KEY",
+		Short: "Remove a key from the config file",
+		Long: `Remove a key from the config file that sets it, so that the value falls back to
+the user config, an environment variable, or the default. Nested keys are written
+with dots.
+
+Examples:
+  orla config unset port
+  orla config unset profiles.dev.model`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.UnsetConfigValue(args[0]); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Unset %s\n", args[0])
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// newConfigEditCmd creates the config edit command
+func newConfigEditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit",
+		Short: "Edit the config file in $EDITOR",
+		Long: fmt.Sprintf(`Open the config file in $EDITOR (default: %s). The config is validated when the
+editor exits, and the file is only changed if it is valid; otherwise the edit is
+kept in a file next to it, whose path is printed.`, defaultEditor),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configPath, err := config.GetWritableConfigPath()
+			if err != nil {
+				return err
+			}
+
+			changed, err := config.EditConfigFile(configPath, runEditor)
+			if err != nil {
+				return err
+			}
+			if changed {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Saved %s\n", configPath)
+			} else {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s was not changed\n", configPath)
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// runEditor opens path in $EDITOR, which may include arguments (e.g. "code --wait")
+func runEditor(path string) error {
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{defaultEditor}
+	}
+
+	// #nosec G204 -- the editor is chosen by the user
+	editorCmd := exec.Command(editor[0], append(editor[1:], path)...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	return editorCmd.Run()
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dorcha-inc/orla/internal/doctor"
+)
+
+// newDoctorCmd creates the doctor command
+func newDoctorCmd() *cobra.Command {
+	var configPath string
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check orla's setup and suggest fixes for common problems",
+		Long: `Check that orla is set up correctly and print a checklist of the results, each
+marked PASS, WARN, or FAIL, with what to do about any problem found.
+
+The checks are:
+  - the config file loads and is valid
+  - git is available, to install tools from a registry
+  - the tools directory exists and can be read
+  - the configured model provider is reachable and has the model (e.g. Ollama is running)
+  - the entrypoint of each installed tool exists and can be run
+
+The command exits with a non-zero status if any check fails. Warnings do not fail it.
+
+Examples:
+  orla doctor
+  orla doctor --config ./orla.yaml`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return doctor.Run(cmd.Context(), doctor.Options{
+				ConfigPath: configPath,
+				Writer:     os.Stdout,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&configPath, "config", "", "Path to orla.yaml config file")
+
+	return cmd
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dorcha-inc/orla/internal/config"
+)
+
+// newEnableCmd creates the enable command
+func newEnableCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enable TOOL-NAME",
+		Short: "Enable a tool in the config",
+		Long: `Enable a tool that was disabled with 'orla disable' or by enabled: false in its
+tool.yaml, by writing an override to tool_overrides in the config. The project
+config (./orla.yaml) is changed if it exists, and the user config otherwise.
+
+A running orla server applies the change when it is restarted or reloaded. Use
+'orla tool enable' to enable a tool on a running server at once.
+
+Examples:
+  orla enable fs`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setToolEnabled(cmd.OutOrStdout(), args[0], true)
+		},
+	}
+
+	return cmd
+}
+
+// newDisableCmd creates the disable command
+func newDisableCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "disable TOOL-NAME",
+		Short: "Disable a tool in the config without uninstalling it",
+		Long: `Disable a tool so that the orla server does not register it, without
+uninstalling it or editing its tool.yaml, by writing an override to
+tool_overrides in the config. The project config (./orla.yaml) is changed if it
+exists, and the user config otherwise.
+
+A running orla server applies the change when it is restarted or reloaded. Use
+'orla tool disable' to disable a tool on a running server at once.
+
+Examples:
+  orla disable fs`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setToolEnabled(cmd.OutOrStdout(), args[0], false)
+		},
+	}
+
+	return cmd
+}
+
+// setToolEnabled writes the config override that enables or disables the named tool, which must
+// be one of the configured tools
+func setToolEnabled(w io.Writer, name string, enabled bool) error {
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if _, err := cfg.ToolsRegistry.GetTool(name); err != nil {
+		return err
+	}
+
+	if err := config.SetToolEnabled(name, enabled); err != nil {
+		return err
+	}
+
+	action := "Disabled"
+	if enabled {
+		action = "Enabled"
+	}
+	_, _ = fmt.Fprintf(w, "%s tool %s, restart or reload a running orla server to apply it\n", action, name)
+	return nil
+}
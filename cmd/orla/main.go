@@ -1,10 +1,16 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/registry"
 )
 
 var (
@@ -19,6 +25,7 @@ func init() {
 	if buildDate == "" {
 		buildDate = "unknown"
 	}
+	core.SetOrlaVersion(version)
 }
 
 func main() {
@@ -32,14 +39,93 @@ Orla supports both MCP server mode (orla serve) and agent mode (orla agent).`,
 		Version: fmt.Sprintf("%s (built: %s)", version, buildDate),
 	}
 
+	var configDir string
+	rootCmd.PersistentFlags().StringVar(&configDir, "config-dir", "",
+		fmt.Sprintf("Orla home directory for user config, registry cache, and installed tools (default: $%s or ~/.orla)", registry.OrlaHomeEnvVar))
+	var profile string
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "",
+		fmt.Sprintf("Config profile from the profiles section of the config to apply (default: $%s)", config.ProfileEnvVar))
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := applyConfigDir(configDir); err != nil {
+			return err
+		}
+		return applyProfile(profile)
+	}
+
 	// Add subcommands
 	rootCmd.AddCommand(newServeCmd())
 	rootCmd.AddCommand(newToolCmd()) // Tool management commands (RFC 4)
+	rootCmd.AddCommand(newReinstallCmd())
 	rootCmd.AddCommand(newCacheCmd())
 	rootCmd.AddCommand(newAgentCmd()) // Agent mode (RFC 4)
+	rootCmd.AddCommand(newChatCmd())
+	rootCmd.AddCommand(newSessionsCmd())
+	rootCmd.AddCommand(newTopCmd())
+	rootCmd.AddCommand(newRunCmd())
+	rootCmd.AddCommand(newValidateCmd())
+	rootCmd.AddCommand(newDoctorCmd())
+	rootCmd.AddCommand(newConfigCmd())
+	rootCmd.AddCommand(newEnableCmd())
+	rootCmd.AddCommand(newDisableCmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCodeOf(err))
+	}
+}
+
+// exitCodeError is returned by commands that exit with a code other than 1 when they fail
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitCodeError) Unwrap() error {
+	return e.err
+}
+
+// exitCodeOf returns the exit code of orla for the error returned by a command
+func exitCodeOf(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exitCodeError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return 1
+}
+
+// applyConfigDir points the orla home directory at configDir, if set. The override is applied
+// through ORLA_HOME so that it is also inherited by child orla processes.
+func applyConfigDir(configDir string) error {
+	if configDir == "" {
+		return nil
+	}
+
+	absConfigDir, err := filepath.Abs(configDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve config directory: %w", err)
+	}
+
+	if err := os.Setenv(registry.OrlaHomeEnvVar, absConfigDir); err != nil {
+		return fmt.Errorf("failed to set %s: %w", registry.OrlaHomeEnvVar, err)
+	}
+	return nil
+}
+
+// applyProfile selects the config profile, if set. Like the config directory, it is applied
+// through the environment, as ORLA_PROFILE, so that it is also inherited by child orla processes.
+func applyProfile(profile string) error {
+	if profile == "" {
+		return nil
+	}
+	if err := os.Setenv(config.ProfileEnvVar, profile); err != nil {
+		return fmt.Errorf("failed to set %s: %w", config.ProfileEnvVar, err)
 	}
+	return nil
 }
@@ -3,17 +3,24 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
 	"github.com/dorcha-inc/orla/internal/config"
 	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/model"
+	"github.com/dorcha-inc/orla/internal/registry"
 	"github.com/dorcha-inc/orla/internal/server"
 	"github.com/dorcha-inc/orla/internal/state"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 // TestLoadConfig tests configuration loading
@@ -209,7 +216,7 @@ func TestRunServe(t *testing.T) {
 	require.NoError(t, err)
 
 	// Test serve command with stdio flag (will exit quickly with cancelled context)
-	err = runServe("", true, false, 0, "")
+	err = runServe("", true, false, 0, "", modelPreflightOff, false, server.ToolFilter{})
 	// Should not error on initialization, but may error when trying to start server
 	// which is expected in test environment
 	if err != nil {
@@ -221,7 +228,7 @@ func TestRunServe(t *testing.T) {
 // TestRunServe_ConfigError tests error handling when config loading fails
 func TestRunServe_ConfigError(t *testing.T) {
 	// Test with non-existent config file
-	err := runServe("/nonexistent/config.yaml", false, false, 0, "")
+	err := runServe("/nonexistent/config.yaml", false, false, 0, "", modelPreflightOff, false, server.ToolFilter{})
 	assert.Error(t, err)
 	// The error message comes from loadConfig, which wraps the error
 	assert.Contains(t, err.Error(), "failed to read config file")
@@ -242,7 +249,215 @@ func TestRunServe_PortValidationError(t *testing.T) {
 	require.NoError(t, err)
 
 	// Test with invalid port
-	err = runServe("", false, false, -1, "")
+	err = runServe("", false, false, -1, "", modelPreflightOff, false, server.ToolFilter{})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "port must be a positive integer")
 }
+
+// preflightFailingProvider is a model.Provider whose EnsureReady always fails
+type preflightFailingProvider struct{}
+
+func (p *preflightFailingProvider) Name() string {
+	return "test"
+}
+
+func (p *preflightFailingProvider) Chat(ctx context.Context, messages []model.Message, tools []*mcp.Tool, stream bool) (*model.Response, <-chan model.StreamEvent, error) {
+	return nil, nil, errors.New("not implemented")
+}
+
+func (p *preflightFailingProvider) EnsureReady(ctx context.Context) error {
+	return errors.New("ollama is not running")
+}
+
+// setPreflightProvider swaps the provider used by the model preflight for the duration of the test
+func setPreflightProvider(t *testing.T, provider model.Provider) {
+	original := newPreflightProvider
+	newPreflightProvider = func(_ string, cfg *config.OrlaConfig) (model.Provider, error) {
+		return provider, nil
+	}
+	t.Cleanup(func() {
+		newPreflightProvider = original
+	})
+}
+
+func TestRunModelPreflight_Warn(t *testing.T) {
+	setPreflightProvider(t, &preflightFailingProvider{})
+
+	coreLogger, logs := observer.New(zap.WarnLevel)
+	originalLogger := zap.L()
+	zap.ReplaceGlobals(zap.New(coreLogger))
+	defer zap.ReplaceGlobals(originalLogger)
+
+	cfg := &config.OrlaConfig{Model: "ollama:missing"}
+	err := runModelPreflight(context.Background(), cfg, modelPreflightWarn)
+	require.NoError(t, err)
+
+	warnings := logs.FilterMessageSnippet("Model preflight failed").All()
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "ollama:missing", warnings[0].ContextMap()["model"])
+	assert.Contains(t, warnings[0].ContextMap()["error"], "ollama is not running")
+}
+
+func TestRunModelPreflight_Strict(t *testing.T) {
+	setPreflightProvider(t, &preflightFailingProvider{})
+
+	cfg := &config.OrlaConfig{Model: "ollama:missing"}
+	err := runModelPreflight(context.Background(), cfg, modelPreflightStrict)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "model preflight failed for ollama:missing")
+	assert.Contains(t, err.Error(), "ollama is not running")
+}
+
+func TestRunModelPreflight_Off(t *testing.T) {
+	setPreflightProvider(t, &preflightFailingProvider{})
+
+	cfg := &config.OrlaConfig{Model: "ollama:missing"}
+	require.NoError(t, runModelPreflight(context.Background(), cfg, modelPreflightOff))
+}
+
+func TestRunModelPreflight_InvalidMode(t *testing.T) {
+	cfg := &config.OrlaConfig{Model: "ollama:missing"}
+	err := runModelPreflight(context.Background(), cfg, "sometimes")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "model-preflight must be one of")
+}
+
+// TestRunServe_StrictPreflightFails tests that serve refuses to start when the strict preflight fails
+func TestRunServe_StrictPreflightFails(t *testing.T) {
+	setPreflightProvider(t, &preflightFailingProvider{})
+
+	tmpDir := t.TempDir()
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer core.LogDeferredError1(os.Chdir, originalDir)
+	require.NoError(t, os.Chdir(tmpDir))
+
+	err = runServe("", true, false, 0, "", modelPreflightStrict, false, server.ToolFilter{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "model preflight failed")
+}
+
+// TestApplyConfigDir tests that --config-dir redirects the orla home, registry cache, and installed tools
+func TestApplyConfigDir(t *testing.T) {
+	// Restore ORLA_HOME after the test, since applyConfigDir sets it
+	t.Setenv(registry.OrlaHomeEnvVar, "")
+
+	configDir := t.TempDir()
+	require.NoError(t, applyConfigDir(configDir))
+
+	homeDir, err := registry.GetOrlaHomeDir()
+	require.NoError(t, err)
+	assert.Equal(t, configDir, homeDir)
+
+	toolsDir, err := registry.GetInstalledToolsDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(configDir, "tools"), toolsDir)
+
+	cacheDir, err := registry.GetRegistryCacheDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(configDir, "cache", "registry"), cacheDir)
+
+	userConfigPath, err := config.GetUserConfigPath()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(configDir, "config.yaml"), userConfigPath)
+}
+
+// TestApplyConfigDir_Empty tests that an unset --config-dir leaves ORLA_HOME untouched
+func TestApplyConfigDir_Empty(t *testing.T) {
+	orlaHome := t.TempDir()
+	t.Setenv(registry.OrlaHomeEnvVar, orlaHome)
+
+	require.NoError(t, applyConfigDir(""))
+	assert.Equal(t, orlaHome, os.Getenv(registry.OrlaHomeEnvVar))
+}
+
+// TestResolveAgentPrompt tests choosing between the prompt argument and a prompt template
+func TestResolveAgentPrompt(t *testing.T) {
+	templatePath := filepath.Join(t.TempDir(), "prompt.tmpl")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(templatePath, []byte("Summarize {{.file}}"), 0644))
+
+	prompt, err := resolveAgentPrompt([]string{"list files"}, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "list files", prompt)
+
+	prompt, err = resolveAgentPrompt(nil, templatePath, []string{"file=README.md"})
+	require.NoError(t, err)
+	assert.Equal(t, "Summarize README.md", prompt)
+
+	_, err = resolveAgentPrompt(nil, templatePath, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"file"`)
+
+	_, err = resolveAgentPrompt([]string{"list files"}, templatePath, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot use both")
+
+	_, err = resolveAgentPrompt(nil, "", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is required")
+
+	_, err = resolveAgentPrompt([]string{"list files"}, "", []string{"file=README.md"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--var requires --prompt-template")
+}
+
+func TestRunCmd_ExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	t.Setenv(registry.OrlaHomeEnvVar, t.TempDir())
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer core.LogDeferredError1(os.Chdir, originalDir)
+	require.NoError(t, os.Chdir(t.TempDir()))
+
+	toolDir := t.TempDir()
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(toolDir, "tool.sh"), []byte("#!/bin/sh\nexit 42\n"), 0755))
+	manifestPath := filepath.Join(toolDir, "tool.yaml")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(manifestPath, []byte("name: answer\nversion: 1.0.0\ndescription: Exits with 42\nentrypoint: tool.sh\n"), 0644))
+
+	tests := []struct {
+		name     string
+		args     []string
+		exitCode int
+	}{
+		{name: "tool exit code", args: []string{"--manifest", manifestPath}, exitCode: 42},
+		{name: "missing tool", args: []string{"--manifest", filepath.Join(toolDir, "missing.yaml")}, exitCode: 127},
+		{name: "invalid arguments", args: []string{"--manifest", manifestPath, "novalue"}, exitCode: 125},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newRunCmd()
+			cmd.SetArgs(tt.args)
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Equal(t, tt.exitCode, exitCodeOf(err))
+		})
+	}
+}
+
+func TestExitCodeOf(t *testing.T) {
+	assert.Equal(t, 0, exitCodeOf(nil))
+	assert.Equal(t, 1, exitCodeOf(errors.New("failed")))
+	assert.Equal(t, 3, exitCodeOf(fmt.Errorf("wrapped: %w", &exitCodeError{code: 3, err: errors.New("failed")})))
+}
+
+// TestApplyProfile tests that --profile selects the config profile through ORLA_PROFILE
+func TestApplyProfile(t *testing.T) {
+	t.Setenv(config.ProfileEnvVar, "dev")
+
+	// An unset --profile leaves ORLA_PROFILE untouched
+	require.NoError(t, applyProfile(""))
+	assert.Equal(t, "dev", config.ActiveProfile())
+
+	require.NoError(t, applyProfile("prod"))
+	assert.Equal(t, "prod", config.ActiveProfile())
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dorcha-inc/orla/internal/tool"
+)
+
+// newReinstallCmd creates the reinstall command
+func newReinstallCmd() *cobra.Command {
+	var (
+		all     bool
+		verbose bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "reinstall [TOOL-NAME...]",
+		Short: "Reinstall installed tools to repair them",
+		Long: `Reinstall the currently installed versions of tools from the sources they were
+installed from. Each version is installed to a staging directory and then swapped
+into place, so a failed reinstall leaves the existing install untouched.
+
+Use this to repair installs that were corrupted by a crash or manual changes.
+Tools installed before install sources were recorded are reinstalled from the
+default registry. Use --verbose to troubleshoot a failed reinstall.
+
+Examples:
+  orla reinstall fs
+  orla reinstall fs http
+  orla reinstall --all`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if verbose {
+				if err := enableVerboseInstall(); err != nil {
+					return err
+				}
+			}
+			return tool.ReinstallTools(args, tool.ReinstallOptions{
+				All:    all,
+				Writer: os.Stdout,
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Reinstall every installed tool")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Log reinstall steps and the git output of failed clones to stderr")
+
+	return cmd
+}
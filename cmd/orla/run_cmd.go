@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dorcha-inc/orla/internal/tool"
+)
+
+// newRunCmd creates the run command for running a tool directly from its manifest
+func newRunCmd() *cobra.Command {
+	var manifestPath string
+
+	cmd := &cobra.Command{
+		Use:   "run --manifest PATH [KEY=VALUE...]",
+		Short: "Run a tool directly from its tool.yaml",
+		Long: `Run a tool directly from its tool.yaml, without installing it or placing it
+in a tools directory. This is useful while developing a tool.
+
+The entrypoint is resolved relative to the manifest. Arguments are passed to the
+tool as KEY=VALUE pairs, the same as the arguments of an MCP tool call. Values
+that are valid JSON (numbers, booleans, arrays, objects) are decoded, anything
+else is passed as a string. Pass stdin=TEXT to send TEXT to the tool's stdin.
+
+Capsule-mode tools are started for the call and stopped afterwards.
+
+orla run exits with the tool's exit code, so it can be used in shell conditionals.
+A tool that fails without an exit code (e.g. it times out) makes it exit with 1.
+Exit codes 125 and 127 are reserved for failures of orla itself:
+  125  orla failed to run the tool (e.g. invalid arguments or manifest)
+  127  the manifest or the tool's entrypoint does not exist
+
+Examples:
+  orla run --manifest ./tool.yaml
+  orla run --manifest ./tool.yaml name=World count=3`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := runManifest(manifestPath, args); err != nil {
+				return &exitCodeError{code: tool.RunExitCode(err), err: err}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&manifestPath, "manifest", "", "Path to the tool's tool.yaml (required)")
+	if err := cmd.MarkFlagRequired("manifest"); err != nil {
+		// This should never happen, but handle it gracefully
+		fmt.Fprintf(os.Stderr, "Warning: failed to mark manifest flag as required: %v\n", err)
+	}
+
+	return cmd
+}
+
+// runManifest runs the tool described by the manifest at manifestPath with KEY=VALUE args
+func runManifest(manifestPath string, args []string) error {
+	input, err := tool.ParseRunArgs(args)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	return tool.RunManifest(ctx, manifestPath, input, os.Stdout, os.Stderr)
+}
@@ -7,15 +7,32 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 
 	"github.com/dorcha-inc/orla/internal/config"
 	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/model"
 	"github.com/dorcha-inc/orla/internal/server"
 )
 
+// modelPreflightMode controls the model preflight check run when the server starts
+type modelPreflightMode string
+
+const (
+	modelPreflightOff    modelPreflightMode = "off"    // skip the check
+	modelPreflightWarn   modelPreflightMode = "warn"   // log a warning if the model is unavailable
+	modelPreflightStrict modelPreflightMode = "strict" // refuse to start if the model is unavailable
+
+	// modelPreflightTimeout bounds how long the startup preflight may take
+	modelPreflightTimeout = 10 * time.Second
+)
+
+// newPreflightProvider creates the provider checked by the model preflight (can be swapped for testing)
+var newPreflightProvider = model.NewProvider
+
 // newServeCmd creates the serve command
 func newServeCmd() *cobra.Command {
 	var (
@@ -24,6 +41,10 @@ func newServeCmd() *cobra.Command {
 		prettyLog    bool
 		portFlag     int
 		toolsDirFlag string
+		preflight    string
+		traceTools   bool
+		onlyTools    []string
+		skipTools    []string
 	)
 
 	cmd := &cobra.Command{
@@ -31,9 +52,22 @@ func newServeCmd() *cobra.Command {
 		Short: "Start the orla MCP server",
 		Long: `Start the orla MCP server.
 
-The server can run in HTTP mode (default port 8080) or stdio mode for MCP clients.`,
+The server can run in HTTP mode (default port 8080) or stdio mode for MCP clients.
+
+With --model-preflight, the configured model provider is checked at startup so a
+misconfigured model is reported immediately rather than on the first chat: "warn"
+logs a warning and keeps serving, "strict" refuses to start.
+
+With --trace-tools, the program, arguments, environment overrides (with sensitive
+values redacted), and working directory of every tool execution are logged, which
+helps debug how tool call arguments are mapped to command-line flags.
+
+With --only and --skip, the server only serves the named tools, or all tools but the
+named ones, without changing the configuration. Tools disabled with orla tool disable
+are not served either way.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runServe(configPath, useStdio, prettyLog, portFlag, toolsDirFlag)
+			return runServe(configPath, useStdio, prettyLog, portFlag, toolsDirFlag, modelPreflightMode(preflight), traceTools,
+				server.ToolFilter{Only: onlyTools, Skip: skipTools})
 		},
 	}
 
@@ -42,12 +76,16 @@ The server can run in HTTP mode (default port 8080) or stdio mode for MCP client
 	cmd.Flags().BoolVar(&useStdio, "stdio", false, "Use stdio instead of TCP port")
 	cmd.Flags().BoolVar(&prettyLog, "pretty", false, "Use pretty-printed logs instead of JSON")
 	cmd.Flags().StringVar(&toolsDirFlag, "tools-dir", "", "Directory containing tools (overrides config file)")
+	cmd.Flags().StringVar(&preflight, "model-preflight", string(modelPreflightOff), "Check the configured model at startup: off, warn, or strict")
+	cmd.Flags().BoolVar(&traceTools, "trace-tools", false, "Log the command line of every tool execution")
+	cmd.Flags().StringSliceVar(&onlyTools, "only", nil, "Only serve these tools (comma-separated)")
+	cmd.Flags().StringSliceVar(&skipTools, "skip", nil, "Do not serve these tools (comma-separated)")
 
 	return cmd
 }
 
 // runServe runs the server with the given flags
-func runServe(configPath string, useStdio bool, prettyLog bool, portFlag int, toolsDirFlag string) error {
+func runServe(configPath string, useStdio bool, prettyLog bool, portFlag int, toolsDirFlag string, preflight modelPreflightMode, traceTools bool, toolFilter server.ToolFilter) error {
 	// Load configuration (defaults if none provided)
 	cfg, err := config.LoadConfig(configPath)
 	if err != nil {
@@ -78,8 +116,21 @@ func runServe(configPath string, useStdio bool, prettyLog bool, portFlag int, to
 		return err
 	}
 
+	// Check the model provider before serving so misconfiguration surfaces at startup
+	if err := runModelPreflight(context.Background(), cfg, preflight); err != nil {
+		return err
+	}
+
 	// Create server (after all config overrides are applied)
-	srv := server.NewOrlaServer(cfg, configPath)
+	srv := server.NewOrlaServerWithToolFilter(cfg, configPath, toolFilter)
+	// Tools, capsules, and persistent processes run in process groups of their own and are not
+	// stopped with orla's, so they are stopped here
+	defer srv.Close()
+
+	// Enable tool command tracing if requested via flag; the config file can also enable it
+	if traceTools {
+		srv.EnableToolTracing()
+	}
 
 	// Set up signal handling for hot reload
 	ctx, cancel := setupSignalHandling(context.Background(), srv)
@@ -124,27 +175,30 @@ func validateAndApplyPort(cfg *config.OrlaConfig, portFlag int, useStdio bool) e
 	return nil
 }
 
-// setupSignalHandling sets up signal handling for hot reload and graceful shutdown
+// setupSignalHandling sets up signal handling for hot reload and graceful shutdown. SIGHUP reloads
+// the config and tools, and SIGINT and SIGTERM cancel the returned context. The signals are
+// handled until the context is done.
 func setupSignalHandling(ctx context.Context, srv *server.OrlaServer) (context.Context, func()) {
 	ctx, cancel := context.WithCancel(ctx)
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
+		defer signal.Stop(sigChan)
 		for {
-			sig := <-sigChan
-			switch sig {
-			case syscall.SIGHUP:
-				zap.L().Info("Received SIGHUP, reloading configuration and tools")
-				if err := srv.Reload(); err != nil {
-					zap.L().Error("Failed to reload", zap.Error(err))
-				} else {
-					zap.L().Info("Successfully reloaded configuration and tools")
-				}
-			case syscall.SIGINT, syscall.SIGTERM:
-				zap.L().Info("Received shutdown signal")
-				cancel()
+			select {
+			case <-ctx.Done():
 				return
+			case sig := <-sigChan:
+				switch sig {
+				case syscall.SIGHUP:
+					zap.L().Info("Received SIGHUP, reloading configuration and tools")
+					reloadServer(srv)
+				case syscall.SIGINT, syscall.SIGTERM:
+					zap.L().Info("Received shutdown signal")
+					cancel()
+					return
+				}
 			}
 		}
 	}()
@@ -152,6 +206,16 @@ func setupSignalHandling(ctx context.Context, srv *server.OrlaServer) (context.C
 	return ctx, cancel
 }
 
+// reloadServer reloads the server's config and tools. A failed reload is logged, and the server
+// keeps serving with the config it had.
+func reloadServer(srv *server.OrlaServer) {
+	if err := srv.Reload(); err != nil {
+		zap.L().Error("Failed to reload, keeping the previous configuration", zap.Error(err))
+		return
+	}
+	zap.L().Info("Successfully reloaded configuration and tools")
+}
+
 // runServer starts the server in either stdio or HTTP mode
 func runServer(ctx context.Context, srv *server.OrlaServer, useStdio bool, cfg *config.OrlaConfig) error {
 	if useStdio {
@@ -163,3 +227,39 @@ func runServer(ctx context.Context, srv *server.OrlaServer, useStdio bool, cfg *
 	zap.L().Info("Starting orla server", zap.String("address", addr))
 	return srv.Serve(ctx, addr)
 }
+
+// runModelPreflight checks that the configured model provider is ready and the model is available.
+// In warn mode failures are logged; in strict mode they are returned.
+func runModelPreflight(ctx context.Context, cfg *config.OrlaConfig, mode modelPreflightMode) error {
+	switch mode {
+	case "", modelPreflightOff:
+		return nil
+	case modelPreflightWarn, modelPreflightStrict:
+	default:
+		return fmt.Errorf("model-preflight must be one of: %s, %s, %s, got '%s'", modelPreflightOff, modelPreflightWarn, modelPreflightStrict, mode)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, modelPreflightTimeout)
+	defer cancel()
+
+	err := func() error {
+		provider, err := newPreflightProvider(cfg.Model, cfg)
+		if err != nil {
+			return err
+		}
+		return model.Preflight(ctx, provider)
+	}()
+	if err == nil {
+		zap.L().Info("Model preflight passed", zap.String("model", cfg.Model))
+		return nil
+	}
+
+	if mode == modelPreflightStrict {
+		return fmt.Errorf("model preflight failed for %s: %w", cfg.Model, err)
+	}
+
+	zap.L().Warn("Model preflight failed; agent features will not work until this is fixed",
+		zap.String("model", cfg.Model),
+		zap.Error(err))
+	return nil
+}
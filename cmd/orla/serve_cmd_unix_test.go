@@ -0,0 +1,73 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/server"
+)
+
+// serverHasTool reports whether the server's current config has the named tool
+func serverHasTool(srv *server.OrlaServer, name string) bool {
+	return slices.ContainsFunc(srv.AdminState().Tools, func(tool server.AdminToolState) bool { return tool.Name == name })
+}
+
+// TestSetupSignalHandling_SIGHUP tests that SIGHUP reloads the server's config and tools, and that
+// a reload that fails is logged and leaves the server running with its previous tools
+func TestSetupSignalHandling_SIGHUP(t *testing.T) {
+	coreLogger, logs := observer.New(zap.InfoLevel)
+	originalLogger := zap.L()
+	zap.ReplaceGlobals(zap.New(coreLogger))
+	defer zap.ReplaceGlobals(originalLogger)
+
+	tmpDir := t.TempDir()
+	toolsDir := filepath.Join(tmpDir, "tools")
+	// #nosec G301 -- test directory permissions are acceptable for temporary test files
+	require.NoError(t, os.MkdirAll(toolsDir, 0755))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(toolsDir, "tool1.sh"), []byte("#!/bin/sh\necho tool1\n"), 0755))
+	configPath := filepath.Join(tmpDir, "orla.yaml")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte("tools_dir: ./tools\n"), 0644))
+
+	cfg, err := config.LoadConfig(configPath)
+	require.NoError(t, err)
+	srv := server.NewOrlaServer(cfg, configPath)
+	defer srv.Close()
+
+	ctx, cancel := setupSignalHandling(context.Background(), srv)
+	defer cancel()
+
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(toolsDir, "tool2.sh"), []byte("#!/bin/sh\necho tool2\n"), 0755))
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	require.Eventually(t, func() bool { return serverHasTool(srv, "tool2") }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, logs.FilterMessage("Successfully reloaded configuration and tools").Len())
+
+	// Break the config, so that the next reload fails
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte("tools_dir: [\n"), 0644))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(toolsDir, "tool3.sh"), []byte("#!/bin/sh\necho tool3\n"), 0755))
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	require.Eventually(t, func() bool {
+		return logs.FilterMessage("Failed to reload, keeping the previous configuration").Len() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, ctx.Err())
+	assert.True(t, serverHasTool(srv, "tool2"))
+	assert.False(t, serverHasTool(srv, "tool3"))
+}
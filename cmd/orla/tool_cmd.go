@@ -21,6 +21,9 @@ for available tools, and manage tool versions.`,
 	cmd.AddCommand(newToolSearchCmd())
 	cmd.AddCommand(newToolInfoCmd())
 	cmd.AddCommand(newToolUpdateCmd())
+	cmd.AddCommand(newToolDisableCmd())
+	cmd.AddCommand(newToolEnableCmd())
+	cmd.AddCommand(newToolExportDocsCmd())
 
 	return cmd
 }
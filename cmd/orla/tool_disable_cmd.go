@@ -0,0 +1,34 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/dorcha-inc/orla/internal/tool"
+)
+
+// newToolDisableCmd creates the tool disable command
+func newToolDisableCmd() *cobra.Command {
+	var port int
+
+	cmd := &cobra.Command{
+		Use:   "disable TOOL-NAME",
+		Short: "Disable a tool without uninstalling it",
+		Long: `Disable a tool so that it is no longer offered to MCP clients, without
+uninstalling it. A disabled capsule-mode tool's capsule is stopped.
+
+The tool is disabled immediately on an orla server running in HTTP mode, and
+stays disabled across restarts and reloads until 'orla tool enable' is run.
+
+Examples:
+  orla tool disable fs
+  orla tool disable http --port 9090`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return tool.SetToolDisabled(args[0], true, tool.ServerURL(port))
+		},
+	}
+
+	cmd.Flags().IntVar(&port, "port", 8080, "Port of the running orla server")
+
+	return cmd
+}
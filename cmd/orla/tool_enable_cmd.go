@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/dorcha-inc/orla/internal/tool"
+)
+
+// newToolEnableCmd creates the tool enable command
+func newToolEnableCmd() *cobra.Command {
+	var port int
+
+	cmd := &cobra.Command{
+		Use:   "enable TOOL-NAME",
+		Short: "Enable a tool disabled with 'orla tool disable'",
+		Long: `Enable a tool that was disabled with 'orla tool disable', so that it is
+offered to MCP clients again.
+
+The tool is enabled immediately on an orla server running in HTTP mode.
+
+Examples:
+  orla tool enable fs
+  orla tool enable http --port 9090`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return tool.SetToolDisabled(args[0], false, tool.ServerURL(port))
+		},
+	}
+
+	cmd.Flags().IntVar(&port, "port", 8080, "Port of the running orla server")
+
+	return cmd
+}
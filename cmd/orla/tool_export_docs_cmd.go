@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dorcha-inc/orla/internal/tool"
+)
+
+// newToolExportDocsCmd creates the tool export-docs command
+func newToolExportDocsCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "export-docs",
+		Short: "Generate documentation for all available tools",
+		Long: `Generate documentation for every tool the orla server would register, including
+installed tools and executables in the tools directory. Each tool is documented with its
+name, version, description, runtime mode, input and output schemas, and examples.
+
+Examples are taken from the "examples" keyword of the tool's mcp.input_schema.
+
+Examples:
+  orla tool export-docs > TOOLS.md
+  orla tool export-docs --format json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return tool.ExportDocs(tool.ExportDocsOptions{
+				Format: format,
+				Writer: os.Stdout,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", tool.DocsFormatMarkdown, "Output format (markdown or json)")
+
+	return cmd
+}
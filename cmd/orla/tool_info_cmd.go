@@ -11,26 +11,34 @@ import (
 // newToolInfoCmd creates the tool info command
 func newToolInfoCmd() *cobra.Command {
 	var jsonOutput bool
+	var fromRegistry bool
 
 	cmd := &cobra.Command{
 		Use:   "info TOOL-NAME",
 		Short: "Display detailed information about a tool",
-		Long: `Display detailed information about an installed tool, including description,
-version, dependencies, permissions, and other metadata from the tool.yaml manifest.
+		Long: `Display detailed information about a tool. For an installed tool this is the
+metadata from its tool.yaml manifest, including description, version, entrypoint,
+interpreter, runtime mode, input and output schemas, and install path.
+
+A tool that is not installed is looked up in the default registry instead, showing its
+registry entry and the versions it can be installed at. Use --registry to look up an
+installed tool in the registry as well.
 
 Examples:
   orla tool info fs
-  orla tool info http`,
+  orla tool info http --registry`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return tool.GetToolInfo(args[0], tool.InfoOptions{
-				JSON:   jsonOutput,
-				Writer: os.Stdout,
+				JSON:     jsonOutput,
+				Registry: fromRegistry,
+				Writer:   os.Stdout,
 			})
 		},
 	}
 
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	cmd.Flags().BoolVar(&fromRegistry, "registry", false, "Show the tool's registry entry and available versions even if it is installed")
 
 	return cmd
 }
@@ -7,6 +7,8 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/installer"
 	"github.com/dorcha-inc/orla/internal/registry"
 	"github.com/dorcha-inc/orla/internal/tool"
 )
@@ -16,22 +18,60 @@ func newToolInstallCmd() *cobra.Command {
 	var (
 		registryURL string
 		version     string
+		ref         string
 		localPath   string
+		intoDir     string
+		refresh     bool
+		offline     bool
+		replace     bool
+		verbose     bool
 	)
 
 	cmd := &cobra.Command{
-		Use:   "install [TOOL-NAME]",
-		Short: "Install a tool from the registry or local path",
+		Use:   "install [TOOL-NAME...]",
+		Short: "Install tools from the registry or a local path",
 		Long: `Install a tool from the registry or a local directory. The tool will be installed
 to ~/.orla/tools/TOOL-NAME/VERSION/ and automatically registered with the orla runtime.
 
+When several tools are given, they are installed in parallel. The number of repositories
+cloned at once is limited by max_concurrent_clones in the config (default 4).
+
 When using --local, TOOL-NAME should not be provided as it will be read from the tool.yaml manifest.
 
+Use --into to install into another directory, such as a project's ./tools, instead of the
+configured tools directory. Set tools_dir in the project's orla.yaml to that directory so
+orla discovers the tools installed there.
+
+Registry indexes and the versions of tools are cached for an hour; use --refresh to
+fetch them fresh, for example to install a version released since the last install.
+Use --offline to never reach the network: the cached registry and versions are used even
+if they have expired, and tools that would have to be cloned fail to install.
+
+Use --replace to make the installed version the tool's active version: the
+TOOL-NAME/current link is switched to it atomically once the install succeeded, so
+servers and other processes never see a half-installed version. Without an active
+version link, the newest installed version is used.
+
+Use --ref to install a branch or commit of the tool's repository instead of a released
+version, for developing tools. It is installed to TOOL-NAME/ref-REF/ (with / in the ref
+replaced by -) without checking the registry checksum; combine it with --replace to use it
+over the released versions. Commits other than a branch tip must be given as a full SHA.
+
+Use --verbose to troubleshoot failed installs: it logs each install step to stderr, and
+a failed clone reports the git output of every attempt.
+
 Examples:
   orla tool install fs
   orla tool install fs@0.1.0
   orla tool install fs --version latest
-  orla tool install --local ./path/to/tool`,
+  orla tool install fs --version '^0.1.0'
+  orla tool install fs --version '>=0.1.0 <0.3.0'
+  orla tool install fs http@0.2.0 git
+  orla tool install --local ./path/to/tool
+  orla tool install fs --into ./tools
+  orla tool install fs --refresh
+  orla tool install fs@0.2.0 --replace
+  orla tool install fs --ref main --replace`,
 		Args: func(cmd *cobra.Command, args []string) error {
 			// Check if --local flag is set
 			localFlag, getLocalFlagErr := cmd.Flags().GetString("local")
@@ -52,13 +92,36 @@ Examples:
 				return fmt.Errorf("tool name is required when installing from registry")
 			}
 
-			if len(args) > 1 {
-				return fmt.Errorf("at most one argument (TOOL-NAME) allowed when installing from registry")
-			}
-
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if verbose {
+				if err := enableVerboseInstall(); err != nil {
+					return err
+				}
+			}
+
+			if len(args) > 1 {
+				if ref != "" {
+					return fmt.Errorf("--ref can only be used when installing a single tool")
+				}
+				specs := make([]installer.ToolSpec, 0, len(args))
+				for _, arg := range args {
+					// Handle version in tool name (e.g., "fs@0.1.0"); others use --version
+					name, toolVersion, _ := strings.Cut(arg, "@")
+					specs = append(specs, installer.ToolSpec{Name: name, Version: toolVersion})
+				}
+				return tool.InstallTools(specs, tool.InstallOptions{
+					RegistryURL: registryURL,
+					Version:     version,
+					ToolsDir:    intoDir,
+					Refresh:     refresh,
+					Offline:     offline,
+					Replace:     replace,
+					Writer:      os.Stdout,
+				})
+			}
+
 			var toolName string
 			if len(args) > 0 {
 				toolName = args[0]
@@ -75,15 +138,36 @@ Examples:
 			return tool.InstallTool(toolName, tool.InstallOptions{
 				RegistryURL: registryURL,
 				Version:     version,
+				Ref:         ref,
 				LocalPath:   localPath,
+				ToolsDir:    intoDir,
+				Refresh:     refresh,
+				Offline:     offline,
+				Replace:     replace,
 				Writer:      os.Stdout,
 			})
 		},
 	}
 
-	cmd.Flags().StringVar(&registryURL, "registry", "", fmt.Sprintf("Registry URL (default: %s)", registry.DefaultRegistryURL))
-	cmd.Flags().StringVar(&version, "version", "latest", "Version constraint (e.g., '0.1.0', 'latest', '^0.1.0')")
+	cmd.Flags().StringVar(&registryURL, "registry", "", fmt.Sprintf("Registry URL (default: default_registry from config, or %s)", registry.DefaultRegistryURL))
+	cmd.Flags().StringVar(&version, "version", "latest", "Version constraint (e.g., 'v0.1.0', 'latest', '^0.1.0', '>=1.0.0 <2.0.0')")
+	cmd.Flags().StringVar(&ref, "ref", "", "Install a git branch or commit SHA of the tool's repository instead of a version")
 	cmd.Flags().StringVar(&localPath, "local", "", "Install from local directory or archive (tool name will be read from tool.yaml)")
+	cmd.Flags().StringVar(&intoDir, "into", "", "Install into this directory instead of the configured tools directory (e.g., ./tools)")
+	cmd.Flags().BoolVar(&refresh, "refresh", false, "Fetch the registry index and tool versions fresh instead of using the cache")
+	cmd.Flags().BoolVar(&offline, "offline", false, "Use only the cached registry and tool versions, never the network (default: offline from config)")
+	cmd.Flags().BoolVar(&replace, "replace", false, "Atomically switch the tool's active version to the installed one after a successful install")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Log install steps and the git output of failed clones to stderr")
 
 	return cmd
 }
+
+// enableVerboseInstall logs install steps at debug level to stderr and makes failed clones report
+// the git output of every attempt, for troubleshooting installs
+func enableVerboseInstall() error {
+	if err := core.Init(true); err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	installer.SetVerboseGit(true)
+	return nil
+}
@@ -20,7 +20,14 @@ func newToolListCmd() *cobra.Command {
 ~/.orla/tools/TOOL-NAME/VERSION/ and are automatically discovered by the orla runtime.
 
 By default, shows a simple list format. Use --verbose or --table to see detailed
-information including descriptions.`,
+information including descriptions.
+
+Use --json for scripts: it prints a JSON array with the name, version, description,
+install_path, and runtime_mode of each installed tool to stdout.
+
+Examples:
+  orla tool list
+  orla tool list --json | jq -r '.[].name'`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return tool.ListTools(tool.ListOptions{
 				JSON:    jsonOutput,
@@ -30,7 +37,7 @@ information including descriptions.`,
 		},
 	}
 
-	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output a JSON array of the installed tools")
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show detailed information including descriptions")
 	cmd.Flags().BoolVar(&verbose, "table", false, "Show detailed information in table format (alias for --verbose)")
 
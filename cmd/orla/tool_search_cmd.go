@@ -15,6 +15,7 @@ func newToolSearchCmd() *cobra.Command {
 	var registryURL string
 	var verbose bool
 	var jsonOutput bool
+	var offline bool
 
 	cmd := &cobra.Command{
 		Use:   "search QUERY",
@@ -23,7 +24,8 @@ func newToolSearchCmd() *cobra.Command {
 names, descriptions, and keywords (case-insensitive).
 
 By default, shows a simple list format. Use --verbose or --table to see detailed
-information in a table format.
+information in a table format. Use --offline to search the cached registry without
+reaching the network, even if the cache has expired.
 
 Examples:
   orla tool search filesystem
@@ -35,15 +37,17 @@ Examples:
 				RegistryURL: registryURL,
 				Verbose:     verbose,
 				JSON:        jsonOutput,
+				Offline:     offline,
 				Writer:      os.Stdout,
 			})
 		},
 	}
 
-	cmd.Flags().StringVar(&registryURL, "registry", "", fmt.Sprintf("Registry URL (default: %s)", registry.DefaultRegistryURL))
+	cmd.Flags().StringVar(&registryURL, "registry", "", fmt.Sprintf("Registry URL (default: default_registry from config, or %s)", registry.DefaultRegistryURL))
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show detailed information in table format")
 	cmd.Flags().BoolVar(&verbose, "table", false, "Show detailed information in table format (alias for --verbose)")
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	cmd.Flags().BoolVar(&offline, "offline", false, "Search only the cached registry, never the network (default: offline from config)")
 
 	return cmd
 }
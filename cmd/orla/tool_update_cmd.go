@@ -12,7 +12,13 @@ import (
 
 // newToolUpdateCmd creates the tool update command
 func newToolUpdateCmd() *cobra.Command {
-	var registryURL string
+	var (
+		registryURL string
+		refresh     bool
+		offline     bool
+		replace     bool
+		verbose     bool
+	)
 
 	cmd := &cobra.Command{
 		Use:   "update TOOL-NAME",
@@ -21,19 +27,41 @@ func newToolUpdateCmd() *cobra.Command {
 This will download and install the latest version while keeping the old version
 until the update is complete.
 
+Registry indexes and the versions of tools are cached for an hour; use --refresh to
+fetch them fresh. Use --offline to check for updates in the cached registry only;
+an update that has to be cloned then fails instead of reaching the network. Use
+--verbose to troubleshoot a failed update. Use --replace to atomically switch the tool's
+active version (the TOOL-NAME/current link) to the latest version once it is installed.
+Without --replace, a tool that already has a current link keeps using the version it
+points at, and update warns about it.
+
 Examples:
   orla tool update fs
+  orla tool update fs --refresh
+  orla tool update fs --replace
   orla tool update http --registry https://github.com/user/custom-registry`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if verbose {
+				if err := enableVerboseInstall(); err != nil {
+					return err
+				}
+			}
 			return tool.UpdateTool(args[0], tool.UpdateOptions{
 				RegistryURL: registryURL,
+				Refresh:     refresh,
+				Offline:     offline,
+				Replace:     replace,
 				Writer:      os.Stdout,
 			})
 		},
 	}
 
-	cmd.Flags().StringVar(&registryURL, "registry", "", fmt.Sprintf("Registry URL (default: %s)", registry.DefaultRegistryURL))
+	cmd.Flags().StringVar(&registryURL, "registry", "", fmt.Sprintf("Registry URL (default: default_registry from config, or %s)", registry.DefaultRegistryURL))
+	cmd.Flags().BoolVar(&refresh, "refresh", false, "Fetch the registry index and tool versions fresh instead of using the cache")
+	cmd.Flags().BoolVar(&offline, "offline", false, "Use only the cached registry and tool versions, never the network (default: offline from config)")
+	cmd.Flags().BoolVar(&replace, "replace", false, "Atomically switch the tool's active version to the latest one after a successful update")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Log update steps and the git output of failed clones to stderr")
 
 	return cmd
 }
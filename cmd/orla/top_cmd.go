@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/monitor"
+	"github.com/dorcha-inc/orla/internal/tui"
+)
+
+// newTopCmd creates the top command for live monitoring of a running server
+func newTopCmd() *cobra.Command {
+	var (
+		port     int
+		interval = monitor.DefaultRefreshInterval
+		once     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "top",
+		Short: "Show a live view of a running orla server",
+		Long: `Show a live view of a running orla server, including registered tools,
+capsule statuses, and in-flight and recent tool calls with their latency.
+
+orla top connects to the admin endpoint of a server started with 'orla serve'
+(HTTP mode only), which must set admin_enabled in its config. The admin_token
+of the config, if set, is sent with every request. Press Ctrl+C to exit.
+
+Examples:
+  orla top
+  orla top --port 9090 --interval 5s
+  orla top --once`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			return monitor.Run(ctx, monitor.RunOptions{
+				URL:      monitor.AdminStateURL(port),
+				Token:    adminToken(),
+				Interval: interval,
+				Once:     once,
+				Clear:    !once && tui.IsTerminal(os.Stdout),
+				Writer:   os.Stdout,
+			})
+		},
+	}
+
+	cmd.Flags().IntVar(&port, "port", 8080, "Port of the running orla server")
+	cmd.Flags().DurationVar(&interval, "interval", interval, "Refresh interval")
+	cmd.Flags().BoolVar(&once, "once", false, "Print a single snapshot and exit")
+
+	return cmd
+}
+
+// adminToken returns the admin_token of the config, which the admin endpoints of a server started
+// with the same config require, or an empty string if the config does not load
+func adminToken() string {
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		return ""
+	}
+	return cfg.AdminToken
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dorcha-inc/orla/internal/tool"
+)
+
+// defaultValidateDir is the directory orla validate checks when no path is given
+const defaultValidateDir = "tools"
+
+// newValidateCmd creates the validate command
+func newValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate [path]",
+		Short: "Check the tool manifests in a directory",
+		Long: `Check every tool.yaml under a directory (./tools by default) with the same validation
+orla applies when installing a tool, and print a pass/fail report per tool.
+
+Problems are reported at the line of tool.yaml they concern, and include missing required
+fields, invalid MCP input and output JSON schemas, and entrypoints that cannot be run.
+The command exits with a non-zero status if any manifest fails.
+
+Examples:
+  orla validate
+  orla validate ./my-tool`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := defaultValidateDir
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			return tool.ValidateTools(tool.ValidateOptions{
+				Dir:    dir,
+				Writer: os.Stdout,
+			})
+		},
+	}
+
+	return cmd
+}
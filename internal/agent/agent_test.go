@@ -3,8 +3,11 @@ package agent
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os/exec"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/dorcha-inc/orla/internal/config"
 	"github.com/dorcha-inc/orla/internal/model"
@@ -203,7 +206,16 @@ func TestLoop_Execute_WithToolCalls(t *testing.T) {
 					},
 				}, nil, nil
 			}
-			// Second call: model returns final response after tool execution
+			// Second call: the conversation carries the tool call and its result, linked by ID
+			require.Len(t, messages, 3)
+			assert.Equal(t, model.MessageRoleAssistant, messages[1].Role)
+			require.Len(t, messages[1].ToolCalls, 1)
+			assert.Equal(t, toolCallID, messages[1].ToolCalls[0].ID)
+			assert.Equal(t, model.MessageRoleTool, messages[2].Role)
+			assert.Equal(t, toolName, messages[2].ToolName)
+			assert.Equal(t, toolCallID, messages[2].ToolCallID)
+
+			// Model returns final response after tool execution
 			return &model.Response{
 				Content:   "Final response after tool execution",
 				ToolCalls: []model.ToolCallWithID{},
@@ -270,6 +282,89 @@ func TestLoop_Execute_Streaming(t *testing.T) {
 	assert.Equal(t, chunks, receivedChunks)
 }
 
+// progressMockClient is a mockClient that sends progress for each tool call made with a progress handler
+type progressMockClient struct {
+	mockClient
+	progressCalls atomic.Int32
+}
+
+func (m *progressMockClient) CallToolWithProgress(ctx context.Context, params *mcp.CallToolParams, onProgress ToolProgressHandler) (*mcp.CallToolResult, error) {
+	m.progressCalls.Add(1)
+	onProgress(&model.ToolProgressEvent{Name: params.Name, Message: "step 1\n", Progress: 7})
+	onProgress(&model.ToolProgressEvent{Name: params.Name, Message: "step 2\n", Progress: 14})
+	return m.CallTool(ctx, params)
+}
+
+// newToolThenAnswerProvider returns a provider that requests the named tool once and then answers
+func newToolThenAnswerProvider(toolName string) *mockProvider {
+	iteration := 0
+	return &mockProvider{
+		chatFunc: func(ctx context.Context, messages []model.Message, tools []*mcp.Tool, stream bool) (*model.Response, <-chan model.StreamEvent, error) {
+			iteration++
+			var streamCh chan model.StreamEvent
+			if stream {
+				streamCh = make(chan model.StreamEvent)
+				close(streamCh)
+			}
+			if iteration == 1 {
+				return &model.Response{
+					ToolCalls: []model.ToolCallWithID{{ID: "call_1", McpCallToolParams: mcp.CallToolParams{Name: toolName}}},
+				}, streamCh, nil
+			}
+			return &model.Response{Content: "done"}, streamCh, nil
+		},
+	}
+}
+
+func TestLoop_Execute_StreamsToolProgress(t *testing.T) {
+	client := &progressMockClient{}
+
+	var progress []*model.ToolProgressEvent
+	streamHandler := func(event model.StreamEvent) error {
+		if progressEvent, ok := event.(*model.ToolProgressEvent); ok {
+			progress = append(progress, progressEvent)
+		}
+		return nil
+	}
+
+	loop := NewLoop(client, newToolThenAnswerProvider("build"), &config.OrlaConfig{MaxToolCalls: 10, Streaming: true})
+	response, err := loop.Execute(context.Background(), "build it", nil, true, streamHandler)
+	require.NoError(t, err)
+	assert.Equal(t, "done", response.Content)
+
+	assert.Equal(t, int32(1), client.progressCalls.Load())
+	require.Len(t, progress, 2)
+	assert.Equal(t, "build", progress[0].Name)
+	assert.Equal(t, "step 1\n", progress[0].Message)
+	assert.Equal(t, "step 2\n", progress[1].Message)
+}
+
+func TestLoop_Execute_ToolProgressHandlerErrorIgnored(t *testing.T) {
+	client := &progressMockClient{}
+
+	streamHandler := func(event model.StreamEvent) error {
+		if _, ok := event.(*model.ToolProgressEvent); ok {
+			return errors.New("terminal gone")
+		}
+		return nil
+	}
+
+	loop := NewLoop(client, newToolThenAnswerProvider("build"), &config.OrlaConfig{MaxToolCalls: 10, Streaming: true})
+	response, err := loop.Execute(context.Background(), "build it", nil, true, streamHandler)
+	require.NoError(t, err, "a failure to show progress should not fail the run")
+	assert.Equal(t, "done", response.Content)
+}
+
+func TestLoop_Execute_NoToolProgressWithoutStreamHandler(t *testing.T) {
+	client := &progressMockClient{}
+
+	loop := NewLoop(client, newToolThenAnswerProvider("build"), &config.OrlaConfig{MaxToolCalls: 10})
+	response, err := loop.Execute(context.Background(), "build it", nil, false, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "done", response.Content)
+	assert.Equal(t, int32(0), client.progressCalls.Load(), "progress should not be requested")
+}
+
 func TestLoop_Execute_StreamingError(t *testing.T) {
 	ctx := context.Background()
 	cfg := &config.OrlaConfig{
@@ -478,6 +573,270 @@ func TestLoop_Execute_WithExistingMessages(t *testing.T) {
 	assert.Equal(t, "new prompt", receivedMessages[1].Content)
 }
 
+func TestLoop_Execute_PromptPrefixAndSuffix(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.OrlaConfig{
+		MaxToolCalls: 10,
+		PromptPrefix: "Answer concisely.",
+		PromptSuffix: "Cite tools used.",
+	}
+
+	client := &mockClient{
+		listToolsFunc: func(ctx context.Context) ([]*mcp.Tool, error) {
+			return []*mcp.Tool{}, nil
+		},
+	}
+
+	var receivedMessages []model.Message
+	provider := &mockProvider{
+		chatFunc: func(ctx context.Context, messages []model.Message, tools []*mcp.Tool, stream bool) (*model.Response, <-chan model.StreamEvent, error) {
+			receivedMessages = messages
+			return &model.Response{Content: "response"}, nil, nil
+		},
+	}
+
+	existingMessages := []model.Message{
+		{Role: model.MessageRoleSystem, Content: "system message"},
+		{Role: model.MessageRoleUser, Content: "previous message"},
+		{Role: model.MessageRoleAssistant, Content: "previous response"},
+	}
+
+	loop := NewLoop(client, provider, cfg)
+	_, err := loop.Execute(ctx, "new prompt", existingMessages, false, nil)
+	require.NoError(t, err)
+
+	require.Len(t, receivedMessages, 4)
+	assert.Equal(t, existingMessages, receivedMessages[:3], "system messages and history should not be wrapped")
+	assert.Equal(t, model.MessageRoleUser, receivedMessages[3].Role)
+	assert.Equal(t, "Answer concisely.\n\nnew prompt\n\nCite tools used.", receivedMessages[3].Content)
+}
+
+// countSystemMessages returns how many of the messages are system messages
+func countSystemMessages(messages []model.Message) int {
+	count := 0
+	for _, message := range messages {
+		if message.Role == model.MessageRoleSystem {
+			count++
+		}
+	}
+	return count
+}
+
+func TestLoop_Execute_SystemPrompt(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.OrlaConfig{
+		MaxToolCalls: 10,
+		SystemPrompt: "You are a careful shell assistant.",
+	}
+
+	client := &mockClient{
+		listToolsFunc: func(ctx context.Context) ([]*mcp.Tool, error) {
+			return []*mcp.Tool{{Name: "test_tool", Description: "A test tool"}}, nil
+		},
+	}
+
+	var received [][]model.Message
+	provider := &mockProvider{
+		chatFunc: func(ctx context.Context, messages []model.Message, tools []*mcp.Tool, stream bool) (*model.Response, <-chan model.StreamEvent, error) {
+			received = append(received, messages)
+			if len(received) < 3 {
+				return &model.Response{
+					ToolCalls: []model.ToolCallWithID{{
+						ID:                fmt.Sprintf("call_%d", len(received)),
+						McpCallToolParams: mcp.CallToolParams{Name: "test_tool"},
+					}},
+				}, nil, nil
+			}
+			return &model.Response{Content: "done"}, nil, nil
+		},
+	}
+
+	loop := NewLoop(client, provider, cfg)
+	_, err := loop.Execute(ctx, "list files", nil, false, nil)
+	require.NoError(t, err)
+
+	// The system prompt leads the conversation once in every iteration, tool calls included
+	require.Len(t, received, 3)
+	for i, messages := range received {
+		assert.Equal(t, 1, countSystemMessages(messages), "iteration %d", i+1)
+		assert.Equal(t, model.Message{Role: model.MessageRoleSystem, Content: "You are a careful shell assistant."}, messages[0])
+		assert.Equal(t, "list files", messages[1].Content)
+	}
+	assert.Len(t, received[2], 6, "system, user, and two tool call and result pairs")
+}
+
+func TestLoop_Execute_SystemPromptKeepsExistingSystemMessage(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.OrlaConfig{
+		MaxToolCalls: 10,
+		SystemPrompt: "configured system prompt",
+	}
+
+	var receivedMessages []model.Message
+	provider := &mockProvider{
+		chatFunc: func(ctx context.Context, messages []model.Message, tools []*mcp.Tool, stream bool) (*model.Response, <-chan model.StreamEvent, error) {
+			receivedMessages = messages
+			return &model.Response{Content: "response"}, nil, nil
+		},
+	}
+
+	existingMessages := []model.Message{
+		{Role: model.MessageRoleUser, Content: "previous message"},
+		{Role: model.MessageRoleSystem, Content: "session system message"},
+	}
+
+	loop := NewLoop(&mockClient{}, provider, cfg)
+	_, err := loop.Execute(ctx, "new prompt", existingMessages, false, nil)
+	require.NoError(t, err)
+
+	require.Len(t, receivedMessages, 3)
+	assert.Equal(t, existingMessages, receivedMessages[:2])
+	assert.Equal(t, 1, countSystemMessages(receivedMessages))
+}
+
+func TestLoop_Execute_StopSequenceEndsTurn(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.OrlaConfig{
+		MaxToolCalls: 10,
+		Stop:         []string{"\nObservation:"},
+	}
+
+	client := &mockClient{
+		callToolFunc: func(ctx context.Context, params *mcp.CallToolParams) (*mcp.CallToolResult, error) {
+			t.Fatal("no tool should be called after a stop sequence")
+			return nil, nil
+		},
+	}
+
+	callCount := 0
+	provider := &mockProvider{
+		chatFunc: func(ctx context.Context, messages []model.Message, tools []*mcp.Tool, stream bool) (*model.Response, <-chan model.StreamEvent, error) {
+			callCount++
+			return &model.Response{
+				Content: "The answer is 42.\nObservation: made-up tool output",
+				ToolCalls: []model.ToolCallWithID{{
+					ID:                "call_1",
+					McpCallToolParams: mcp.CallToolParams{Name: "test_tool"},
+				}},
+			}, nil, nil
+		},
+	}
+
+	loop := NewLoop(client, provider, cfg)
+	response, err := loop.Execute(ctx, "question", nil, false, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, callCount)
+	assert.Equal(t, "The answer is 42.", response.Content)
+	assert.Empty(t, response.ToolCalls)
+}
+
+// thinkingLoop returns a loop whose model streams a thinking trace while requesting a tool, then
+// answers, recording the messages of every request
+func thinkingLoop(cfg *config.OrlaConfig, requests *[][]model.Message) *Loop {
+	provider := &mockProvider{
+		chatFunc: func(ctx context.Context, messages []model.Message, tools []*mcp.Tool, stream bool) (*model.Response, <-chan model.StreamEvent, error) {
+			*requests = append(*requests, messages)
+			streamCh := make(chan model.StreamEvent, 2)
+			if len(*requests) == 1 {
+				streamCh <- &model.ThinkingEvent{Content: "I should look this up"}
+				streamCh <- &model.ContentEvent{Content: "Looking it up"}
+				close(streamCh)
+				return &model.Response{
+					Content:   "Looking it up",
+					Thinking:  "I should look this up",
+					ToolCalls: []model.ToolCallWithID{{ID: "call_1", McpCallToolParams: mcp.CallToolParams{Name: "test_tool"}}},
+				}, streamCh, nil
+			}
+			close(streamCh)
+			return &model.Response{Content: "done"}, streamCh, nil
+		},
+	}
+	return NewLoop(&mockClient{}, provider, cfg)
+}
+
+func TestLoop_Execute_ThinkingDroppedFromHistory(t *testing.T) {
+	cfg := &config.OrlaConfig{MaxToolCalls: 10, ShowThinking: true}
+
+	var requests [][]model.Message
+	var thinking []string
+	streamHandler := func(event model.StreamEvent) error {
+		if thinkingEvent, ok := event.(*model.ThinkingEvent); ok {
+			thinking = append(thinking, thinkingEvent.Content)
+		}
+		return nil
+	}
+
+	response, err := thinkingLoop(cfg, &requests).Execute(context.Background(), "question", nil, true, streamHandler)
+	require.NoError(t, err)
+	assert.Equal(t, "done", response.Content)
+
+	// The thinking trace is shown live, but not sent back with the next request
+	assert.Equal(t, []string{"I should look this up"}, thinking)
+	require.Len(t, requests, 2)
+	assistant := requests[1][1]
+	assert.Equal(t, model.MessageRoleAssistant, assistant.Role)
+	assert.Equal(t, "Looking it up", assistant.Content)
+	assert.Empty(t, assistant.Thinking)
+}
+
+func TestLoop_Execute_KeepThinking(t *testing.T) {
+	cfg := &config.OrlaConfig{MaxToolCalls: 10, ShowThinking: true, KeepThinking: true}
+
+	var requests [][]model.Message
+	streamHandler := func(model.StreamEvent) error { return nil }
+
+	_, err := thinkingLoop(cfg, &requests).Execute(context.Background(), "question", nil, true, streamHandler)
+	require.NoError(t, err)
+
+	require.Len(t, requests, 2)
+	assert.Equal(t, "I should look this up", requests[1][1].Thinking)
+}
+
+func TestTruncateAtStop(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		stops   []string
+		want    string
+		wantHit bool
+	}{
+		{name: "no stops", content: "hello END", want: "hello END"},
+		{name: "no match", content: "hello", stops: []string{"END"}, want: "hello"},
+		{name: "match", content: "hello END world", stops: []string{"END"}, want: "hello ", wantHit: true},
+		{name: "earliest match wins", content: "a STOP b END c", stops: []string{"END", "STOP"}, want: "a ", wantHit: true},
+		{name: "empty stop ignored", content: "hello", stops: []string{""}, want: "hello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, hit := truncateAtStop(tt.content, tt.stops)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantHit, hit)
+		})
+	}
+}
+
+func TestWrapPrompt(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   string
+		suffix   string
+		expected string
+	}{
+		{name: "neither", expected: "prompt"},
+		{name: "prefix only", prefix: "Before.", expected: "Before.\n\nprompt"},
+		{name: "suffix only", suffix: "After.", expected: "prompt\n\nAfter."},
+		{name: "both", prefix: "Before.", suffix: "After.", expected: "Before.\n\nprompt\n\nAfter."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.OrlaConfig{PromptPrefix: tt.prefix, PromptSuffix: tt.suffix}
+			assert.Equal(t, tt.expected, wrapPrompt(cfg, "prompt"))
+		})
+	}
+}
+
 func TestLoop_executeToolCalls(t *testing.T) {
 	ctx := context.Background()
 	cfg := &config.OrlaConfig{}
@@ -515,7 +874,8 @@ func TestLoop_executeToolCalls(t *testing.T) {
 		},
 	}
 
-	results := loop.executeToolCalls(ctx, toolCalls)
+	results, err := loop.executeToolCalls(ctx, toolCalls, nil)
+	require.NoError(t, err)
 	require.Len(t, results, 2)
 	assert.Equal(t, 2, callCount)
 	assert.Equal(t, "call_1", results[0].ID)
@@ -546,7 +906,8 @@ func TestLoop_executeToolCalls_WithError(t *testing.T) {
 		},
 	}
 
-	results := loop.executeToolCalls(ctx, toolCalls)
+	results, err := loop.executeToolCalls(ctx, toolCalls, nil)
+	require.NoError(t, err)
 	require.Len(t, results, 1)
 	assert.Equal(t, "call_1", results[0].ID)
 	assert.True(t, results[0].McpCallToolResult.IsError)
@@ -556,6 +917,290 @@ func TestLoop_executeToolCalls_WithError(t *testing.T) {
 	assert.Contains(t, textContent.Text, "Tool call failed")
 }
 
+// failingToolLoop returns a loop whose model calls a failing tool once and then answers, and a
+// counter of model requests
+func failingToolLoop(cfg *config.OrlaConfig) (*Loop, *int) {
+	client := &mockClient{
+		listToolsFunc: func(ctx context.Context) ([]*mcp.Tool, error) {
+			return []*mcp.Tool{{Name: "deploy", Description: "Deploys"}}, nil
+		},
+		callToolFunc: func(ctx context.Context, params *mcp.CallToolParams) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: "permission denied"}},
+			}, nil
+		},
+	}
+
+	chatCount := 0
+	provider := &mockProvider{
+		chatFunc: func(ctx context.Context, messages []model.Message, tools []*mcp.Tool, stream bool) (*model.Response, <-chan model.StreamEvent, error) {
+			chatCount++
+			if chatCount == 1 {
+				return &model.Response{
+					ToolCalls: []model.ToolCallWithID{
+						{ID: "call_1", McpCallToolParams: mcp.CallToolParams{Name: "deploy"}},
+					},
+				}, nil, nil
+			}
+			return &model.Response{Content: "The deploy failed, try again with more permissions"}, nil, nil
+		},
+	}
+
+	return NewLoop(client, provider, cfg), &chatCount
+}
+
+func TestLoop_Execute_ToolErrorReturnedToModel(t *testing.T) {
+	loop, chatCount := failingToolLoop(&config.OrlaConfig{MaxToolCalls: 10})
+
+	response, err := loop.Execute(context.Background(), "deploy it", nil, false, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "The deploy failed, try again with more permissions", response.Content)
+	assert.Equal(t, 2, *chatCount, "the model should see the tool error and respond")
+}
+
+func TestLoop_Execute_FailOnToolError(t *testing.T) {
+	loop, chatCount := failingToolLoop(&config.OrlaConfig{MaxToolCalls: 10, FailOnToolError: true})
+
+	response, err := loop.Execute(context.Background(), "deploy it", nil, false, nil)
+	require.Error(t, err)
+	assert.Nil(t, response)
+	assert.Equal(t, 1, *chatCount, "the run should abort before asking the model again")
+
+	var toolErr *ToolCallFailedError
+	require.ErrorAs(t, err, &toolErr)
+	assert.Equal(t, "deploy", toolErr.Tool)
+	assert.Equal(t, "permission denied", toolErr.Message)
+}
+
+// slowRunLoop returns a loop whose model asks for the "scan" tool on its first turn and says it
+// is done on the next. The tool and the second model turn block until their context ends if
+// slowTool or slowModel is set.
+func slowRunLoop(cfg *config.OrlaConfig, slowTool bool, slowModel bool) (*Loop, *atomic.Bool) {
+	toolCancelled := &atomic.Bool{}
+	client := &mockClient{
+		listToolsFunc: func(ctx context.Context) ([]*mcp.Tool, error) {
+			return []*mcp.Tool{{Name: "scan", Description: "Scans"}}, nil
+		},
+		callToolFunc: func(ctx context.Context, params *mcp.CallToolParams) (*mcp.CallToolResult, error) {
+			if slowTool {
+				<-ctx.Done()
+				toolCancelled.Store(true)
+				return nil, ctx.Err()
+			}
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "3 issues"}}}, nil
+		},
+	}
+
+	chatCount := 0
+	provider := &mockProvider{
+		chatFunc: func(ctx context.Context, messages []model.Message, tools []*mcp.Tool, stream bool) (*model.Response, <-chan model.StreamEvent, error) {
+			chatCount++
+			if chatCount == 1 {
+				return &model.Response{
+					Content: "Scanning the repository first.",
+					ToolCalls: []model.ToolCallWithID{
+						{ID: "call_1", McpCallToolParams: mcp.CallToolParams{Name: "scan"}},
+					},
+				}, nil, nil
+			}
+			if slowModel {
+				<-ctx.Done()
+				return nil, nil, ctx.Err()
+			}
+			return &model.Response{Content: "Found 3 issues"}, nil, nil
+		},
+	}
+
+	return NewLoop(client, provider, cfg), toolCancelled
+}
+
+func TestLoop_Execute_RunDeadline(t *testing.T) {
+	tests := []struct {
+		name      string
+		slowTool  bool
+		slowModel bool
+	}{
+		{name: "tool call in flight", slowTool: true},
+		{name: "model call in flight", slowModel: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loop, toolCancelled := slowRunLoop(&config.OrlaConfig{MaxToolCalls: 10, RunDeadline: 1}, tt.slowTool, tt.slowModel)
+
+			start := time.Now()
+			response, err := loop.Execute(context.Background(), "scan the repo", nil, false, nil)
+			require.Error(t, err)
+			assert.Less(t, time.Since(start), 5*time.Second, "the run should stop at the deadline")
+
+			var deadlineErr *RunDeadlineExceededError
+			require.ErrorAs(t, err, &deadlineErr)
+			assert.Equal(t, time.Second, deadlineErr.Deadline)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+			assert.Contains(t, err.Error(), "run deadline of 1s exceeded")
+
+			require.NotNil(t, response, "the partial response should be returned")
+			assert.Equal(t, "Scanning the repository first.", response.Content)
+			assert.Equal(t, tt.slowTool, toolCancelled.Load())
+		})
+	}
+}
+
+func TestLoop_Execute_RunDeadlineNotReached(t *testing.T) {
+	loop, _ := slowRunLoop(&config.OrlaConfig{MaxToolCalls: 10, RunDeadline: 60}, false, false)
+
+	response, err := loop.Execute(context.Background(), "scan the repo", nil, false, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Found 3 issues", response.Content)
+}
+
+func TestLoop_Execute_ParentCancelIsNotRunDeadline(t *testing.T) {
+	loop, _ := slowRunLoop(&config.OrlaConfig{MaxToolCalls: 10, RunDeadline: 60}, false, true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	response, err := loop.Execute(ctx, "scan the repo", nil, false, nil)
+	require.Error(t, err)
+	assert.Nil(t, response)
+	var deadlineErr *RunDeadlineExceededError
+	assert.False(t, errors.As(err, &deadlineErr), "only the run deadline should return a partial response")
+}
+
+func TestLoop_executeToolCalls_FailOnToolError(t *testing.T) {
+	callCount := 0
+	client := &mockClient{
+		callToolFunc: func(ctx context.Context, params *mcp.CallToolParams) (*mcp.CallToolResult, error) {
+			callCount++
+			return nil, errors.New("connection closed")
+		},
+	}
+	loop := NewLoop(client, &mockProvider{}, &config.OrlaConfig{FailOnToolError: true})
+
+	results, err := loop.executeToolCalls(context.Background(), []model.ToolCallWithID{
+		{ID: "call_1", McpCallToolParams: mcp.CallToolParams{Name: "tool1"}},
+		{ID: "call_2", McpCallToolParams: mcp.CallToolParams{Name: "tool2"}},
+	}, nil)
+	require.Error(t, err)
+	assert.Nil(t, results)
+	assert.Equal(t, 1, callCount, "remaining tool calls should not run")
+	assert.Contains(t, err.Error(), "tool tool1 failed: connection closed")
+}
+
+func TestLoop_executeToolCalls_Parallel(t *testing.T) {
+	const callCount = 4
+	var started atomic.Int32
+	allStarted := make(chan struct{})
+
+	client := &mockClient{
+		callToolFunc: func(ctx context.Context, params *mcp.CallToolParams) (*mcp.CallToolResult, error) {
+			// Each call waits until all of them are running, so the test only passes if they run at once
+			if started.Add(1) == callCount {
+				close(allStarted)
+			}
+			select {
+			case <-allStarted:
+			case <-time.After(5 * time.Second):
+				return nil, errors.New("tool calls did not run concurrently")
+			}
+
+			// Later calls finish first
+			index := params.Arguments.(map[string]any)["index"].(int)
+			time.Sleep(time.Duration(callCount-index) * 10 * time.Millisecond)
+			if params.Name == "broken" {
+				return nil, errors.New("connection reset")
+			}
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("result %d", index)}}}, nil
+		},
+	}
+	loop := NewLoop(client, &mockProvider{}, &config.OrlaConfig{MaxParallelToolCalls: callCount})
+
+	toolCalls := make([]model.ToolCallWithID, callCount)
+	for i := range toolCalls {
+		name := "slow"
+		if i == 1 {
+			name = "broken"
+		}
+		toolCalls[i] = model.ToolCallWithID{
+			ID:                fmt.Sprintf("call_%d", i),
+			McpCallToolParams: mcp.CallToolParams{Name: name, Arguments: map[string]any{"index": i}},
+		}
+	}
+
+	results, err := loop.executeToolCalls(context.Background(), toolCalls, nil)
+	require.NoError(t, err)
+	require.Len(t, results, callCount)
+	for i, result := range results {
+		assert.Equal(t, fmt.Sprintf("call_%d", i), result.ID, "results must be in the order of the tool calls")
+		if i == 1 {
+			assert.True(t, result.McpCallToolResult.IsError)
+			assert.Equal(t, "Tool call failed: connection reset", toolResultText(result.McpCallToolResult))
+			continue
+		}
+		assert.False(t, result.McpCallToolResult.IsError)
+		assert.Equal(t, fmt.Sprintf("result %d", i), toolResultText(result.McpCallToolResult))
+	}
+}
+
+func TestLoop_executeToolCalls_ParallelLimit(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	client := &mockClient{
+		callToolFunc: func(ctx context.Context, params *mcp.CallToolParams) (*mcp.CallToolResult, error) {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				seen := maxInFlight.Load()
+				if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil
+		},
+	}
+	loop := NewLoop(client, &mockProvider{}, &config.OrlaConfig{MaxParallelToolCalls: 2})
+
+	toolCalls := make([]model.ToolCallWithID, 6)
+	for i := range toolCalls {
+		toolCalls[i] = model.ToolCallWithID{ID: fmt.Sprintf("call_%d", i), McpCallToolParams: mcp.CallToolParams{Name: "slow"}}
+	}
+
+	results, err := loop.executeToolCalls(context.Background(), toolCalls, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 6)
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(2))
+}
+
+func TestLoop_executeToolCalls_ParallelFailOnToolError(t *testing.T) {
+	firstStarted := make(chan struct{})
+	client := &mockClient{
+		callToolFunc: func(ctx context.Context, params *mcp.CallToolParams) (*mcp.CallToolResult, error) {
+			switch params.Name {
+			case "ok":
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil
+			case "first":
+				// Fail after the later call has failed, which must not change the reported failure
+				close(firstStarted)
+				time.Sleep(20 * time.Millisecond)
+			default:
+				<-firstStarted
+			}
+			return nil, fmt.Errorf("%s broke", params.Name)
+		},
+	}
+	loop := NewLoop(client, &mockProvider{}, &config.OrlaConfig{MaxParallelToolCalls: 3, FailOnToolError: true})
+
+	results, err := loop.executeToolCalls(context.Background(), []model.ToolCallWithID{
+		{ID: "call_1", McpCallToolParams: mcp.CallToolParams{Name: "ok"}},
+		{ID: "call_2", McpCallToolParams: mcp.CallToolParams{Name: "first"}},
+		{ID: "call_3", McpCallToolParams: mcp.CallToolParams{Name: "second"}},
+	}, nil)
+	require.Error(t, err)
+	assert.Nil(t, results)
+	assert.Contains(t, err.Error(), "tool first failed: first broke")
+}
+
 func TestFormatToolResult(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -611,6 +1256,71 @@ func TestFormatToolResult(t *testing.T) {
 			},
 			expected: "Tool executed successfully",
 		},
+		{
+			name: "error result with exit code and no content",
+			result: model.ToolResultWithID{
+				ID: "call_1",
+				McpCallToolResult: mcp.CallToolResult{
+					IsError:           true,
+					StructuredContent: map[string]any{"exit_code": float64(2)},
+				},
+			},
+			expected: "Tool execution failed (exit 2)",
+		},
+		{
+			name: "mixed text, image, and error",
+			result: model.ToolResultWithID{
+				ID: "call_1",
+				McpCallToolResult: mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: "rendered chart"},
+						&mcp.ImageContent{MIMEType: "image/png", Data: []byte{0x89, 0x50}},
+						&mcp.TextContent{Text: "stderr: disk full"},
+					},
+					StructuredContent: map[string]any{"stdout": "rendered chart", "exit_code": float64(42)},
+				},
+			},
+			expected: "rendered chart\n[image omitted: image/png]\nstderr: disk full\n[error: exit 42]",
+		},
+		{
+			name: "error without exit code",
+			result: model.ToolResultWithID{
+				ID: "call_1",
+				McpCallToolResult: mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: "permission denied"}},
+				},
+			},
+			expected: "permission denied\n[error]",
+		},
+		{
+			name: "error with only empty text",
+			result: model.ToolResultWithID{
+				ID: "call_1",
+				McpCallToolResult: mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: ""}},
+				},
+			},
+			expected: "Tool execution failed",
+		},
+		{
+			name: "resources and audio",
+			result: model.ToolResultWithID{
+				ID: "call_1",
+				McpCallToolResult: mcp.CallToolResult{
+					Content: []mcp.Content{
+						&mcp.ResourceLink{URI: "file:///tmp/report.pdf", Name: "report"},
+						&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "file:///tmp/notes.txt", Text: "note body"}},
+						&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "file:///tmp/blob.bin", Blob: []byte{1}}},
+						&mcp.AudioContent{MIMEType: "audio/wav", Data: []byte{1}},
+					},
+					StructuredContent: map[string]any{"exit_code": float64(0)},
+				},
+			},
+			expected: "[resource: file:///tmp/report.pdf]\n[resource: file:///tmp/notes.txt]\nnote body\n[resource: file:///tmp/blob.bin]\n[audio omitted: audio/wav]",
+		},
 	}
 
 	for _, tt := range tests {
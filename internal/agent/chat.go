@@ -0,0 +1,247 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/model"
+	"github.com/dorcha-inc/orla/internal/tui"
+)
+
+// maxChatLineSize is the longest prompt line accepted by the chat prompt
+const maxChatLineSize = 1024 * 1024
+
+// chatSession is an interactive chat whose history is persisted in a session store
+type chatSession struct {
+	loop    *Loop
+	cfg     *config.OrlaConfig
+	store   *SessionStore
+	name    string
+	history []model.Message
+}
+
+// newChatSession creates a chat that resumes the named session
+func newChatSession(loop *Loop, cfg *config.OrlaConfig, store *SessionStore, name string) (*chatSession, error) {
+	chat := &chatSession{
+		loop:  loop,
+		cfg:   cfg,
+		store: store,
+	}
+	if err := chat.switchTo(name); err != nil {
+		return nil, err
+	}
+	return chat, nil
+}
+
+// switchTo makes the named session the current one, loading its history
+func (c *chatSession) switchTo(name string) error {
+	session, err := c.store.Load(name)
+	if err != nil {
+		return err
+	}
+	c.name = session.Name
+	c.history = session.Messages
+	return nil
+}
+
+// turn sends prompt to the model with the session history and saves the exchange to the session
+func (c *chatSession) turn(ctx context.Context, prompt string, streamHandler StreamHandler) (*model.Response, error) {
+	response, err := c.loop.Execute(ctx, prompt, c.history, streamHandler != nil, streamHandler)
+	if err != nil {
+		return nil, fmt.Errorf("agent execution failed: %w", err)
+	}
+	if response == nil {
+		return nil, fmt.Errorf("response is nil")
+	}
+
+	session, err := c.store.Append(c.name,
+		model.Message{Role: model.MessageRoleUser, Content: prompt},
+		model.Message{Role: model.MessageRoleAssistant, Content: response.Content, Thinking: historyThinking(c.cfg, response)},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save session: %w", err)
+	}
+
+	// The saved history also contains turns other orla processes added to this session
+	c.history = session.Messages
+	return response, nil
+}
+
+// run reads prompts from in until it ends or the user exits, answering each in turn.
+// Lines starting with "/" are chat commands.
+func (c *chatSession) run(ctx context.Context, in io.Reader, out io.Writer) error {
+	var streamHandler StreamHandler
+	if c.cfg.Streaming {
+		streamHandler = createStreamHandler(c.cfg)
+	}
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxChatLineSize)
+
+	for {
+		core.MustFprintf(out, "%s> ", c.name)
+		if !scanner.Scan() {
+			core.MustFprintf(out, "\n")
+			return scanner.Err()
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "/") {
+			exit, err := c.runCommand(line, out)
+			if err != nil {
+				core.MustFprintf(out, "Error: %v\n", err)
+			}
+			if exit {
+				return nil
+			}
+			continue
+		}
+
+		response, err := c.turn(ctx, line, streamHandler)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			core.MustFprintf(out, "Error: %v\n", err)
+			continue
+		}
+		printResponse(out, c.cfg, response)
+	}
+}
+
+// runCommand runs a chat command and reports whether the chat should exit
+func (c *chatSession) runCommand(line string, out io.Writer) (bool, error) {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case "/exit", "/quit":
+		return true, nil
+	case "/session":
+		if len(fields) != 2 {
+			return false, fmt.Errorf("usage: /session NAME")
+		}
+		if err := c.switchTo(fields[1]); err != nil {
+			return false, err
+		}
+		core.MustFprintf(out, "Switched to session '%s' (%d messages)\n", c.name, len(c.history))
+		return false, nil
+	case "/sessions":
+		return false, printSessions(c.store, out)
+	case "/help":
+		core.MustFprintf(out, "Commands:\n")
+		core.MustFprintf(out, "  /session NAME  switch to another session, creating it if needed\n")
+		core.MustFprintf(out, "  /sessions      list sessions\n")
+		core.MustFprintf(out, "  /exit          leave the chat\n")
+		return false, nil
+	default:
+		return false, fmt.Errorf("unknown command %s (type /help for commands)", fields[0])
+	}
+}
+
+// ExecuteChat runs an interactive chat on stdin and stdout. The conversation is saved to the named
+// session after every turn and resumed the next time the session is used.
+func ExecuteChat(sessionName string, modelOverride string, noCache bool) error {
+	if err := ValidateSessionName(sessionName); err != nil {
+		return err
+	}
+
+	store, err := NewDefaultSessionStore()
+	if err != nil {
+		return err
+	}
+
+	// Load config
+	cfg, configErr := config.LoadConfig("")
+	if configErr != nil {
+		return fmt.Errorf("failed to load config: %w", configErr)
+	}
+
+	applyPromptOverrides(cfg, modelOverride, "", noCache, false, 0)
+
+	ctx, cancel := newSignalContext()
+	defer cancel()
+
+	loop, closeClient, err := startAgent(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer core.LogDeferredError(closeClient)
+
+	chat, err := newChatSession(loop, cfg, store, sessionName)
+	if err != nil {
+		return err
+	}
+
+	if len(chat.history) > 0 {
+		tui.Info("Resumed session '%s' (%d messages). Type /help for commands.\n", chat.name, len(chat.history))
+	} else {
+		tui.Info("Started session '%s'. Type /help for commands.\n", chat.name)
+	}
+
+	return chat.run(ctx, os.Stdin, os.Stdout)
+}
+
+// printSessions writes a table of the stored sessions to w
+func printSessions(store *SessionStore, w io.Writer) error {
+	sessions, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	if len(sessions) == 0 {
+		core.MustFprintf(w, "No sessions.\n")
+		core.MustFprintf(w, "Start one with: orla chat --session NAME\n")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	core.MustFprintf(tw, "NAME\tMESSAGES\tUPDATED\n")
+	core.MustFprintf(tw, "----\t--------\t-------\n")
+	for _, session := range sessions {
+		core.MustFprintf(tw, "%s\t%d\t%s\n", session.Name, session.MessageCount, session.UpdatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	return tw.Flush()
+}
+
+// ListSessions lists the stored chat sessions
+func ListSessions(w io.Writer) error {
+	if w == nil {
+		w = os.Stdout
+	}
+
+	store, err := NewDefaultSessionStore()
+	if err != nil {
+		return err
+	}
+	return printSessions(store, w)
+}
+
+// RemoveSessions removes the named chat sessions
+func RemoveSessions(names []string, w io.Writer) error {
+	if w == nil {
+		w = os.Stdout
+	}
+
+	store, err := NewDefaultSessionStore()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if err := store.Remove(name); err != nil {
+			return err
+		}
+		core.MustFprintf(w, "✓ Removed session %s\n", name)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/model"
+	"github.com/dorcha-inc/orla/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRecordingLoop returns a loop whose model answers "reply N" to the Nth request and records
+// the messages of every request
+func newRecordingLoop(requests *[][]model.Message) *Loop {
+	provider := &mockProvider{
+		chatFunc: func(ctx context.Context, messages []model.Message, tools []*mcp.Tool, stream bool) (*model.Response, <-chan model.StreamEvent, error) {
+			*requests = append(*requests, messages)
+			return &model.Response{Content: fmt.Sprintf("reply %d", len(*requests))}, nil, nil
+		},
+	}
+	return NewLoop(&mockClient{}, provider, &config.OrlaConfig{MaxToolCalls: 10})
+}
+
+func TestChatSession_ResumesNamedSession(t *testing.T) {
+	store := NewSessionStore(t.TempDir())
+	cfg := &config.OrlaConfig{}
+
+	var requests [][]model.Message
+	chat, err := newChatSession(newRecordingLoop(&requests), cfg, store, "work")
+	require.NoError(t, err)
+
+	_, err = chat.turn(context.Background(), "first question", nil)
+	require.NoError(t, err)
+
+	// A new chat on the same session, as after restarting orla, sends the saved history
+	var resumedRequests [][]model.Message
+	resumed, err := newChatSession(newRecordingLoop(&resumedRequests), cfg, store, "work")
+	require.NoError(t, err)
+	require.Len(t, resumed.history, 2)
+
+	response, err := resumed.turn(context.Background(), "second question", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "reply 1", response.Content)
+
+	require.Len(t, resumedRequests, 1)
+	assert.Equal(t, []model.Message{
+		{Role: model.MessageRoleUser, Content: "first question"},
+		{Role: model.MessageRoleAssistant, Content: "reply 1"},
+		{Role: model.MessageRoleUser, Content: "second question"},
+	}, resumedRequests[0])
+
+	session, err := store.Load("work")
+	require.NoError(t, err)
+	assert.Len(t, session.Messages, 4)
+
+	// Other sessions are unaffected
+	other, err := store.Load("personal")
+	require.NoError(t, err)
+	assert.Empty(t, other.Messages)
+}
+
+func TestChatSession_TurnDropsThinking(t *testing.T) {
+	for _, keep := range []bool{false, true} {
+		t.Run(fmt.Sprintf("keep_thinking=%t", keep), func(t *testing.T) {
+			store := NewSessionStore(t.TempDir())
+			cfg := &config.OrlaConfig{MaxToolCalls: 10, KeepThinking: keep}
+			provider := &mockProvider{
+				chatFunc: func(ctx context.Context, messages []model.Message, tools []*mcp.Tool, stream bool) (*model.Response, <-chan model.StreamEvent, error) {
+					return &model.Response{Content: "answer", Thinking: "reasoning"}, nil, nil
+				},
+			}
+
+			chat, err := newChatSession(NewLoop(&mockClient{}, provider, cfg), cfg, store, "work")
+			require.NoError(t, err)
+
+			response, err := chat.turn(context.Background(), "question", nil)
+			require.NoError(t, err)
+			assert.Equal(t, "reasoning", response.Thinking, "the response still carries the thinking to show")
+
+			session, err := store.Load("work")
+			require.NoError(t, err)
+			require.Len(t, session.Messages, 2)
+			if keep {
+				assert.Equal(t, "reasoning", session.Messages[1].Thinking)
+			} else {
+				assert.Empty(t, session.Messages[1].Thinking)
+			}
+		})
+	}
+}
+
+func TestChatSession_Run(t *testing.T) {
+	store := NewSessionStore(t.TempDir())
+	cfg := &config.OrlaConfig{}
+
+	var requests [][]model.Message
+	chat, err := newChatSession(newRecordingLoop(&requests), cfg, store, "work")
+	require.NoError(t, err)
+
+	input := strings.Join([]string{
+		"hello",
+		"",
+		"/session personal",
+		"hi there",
+		"/sessions",
+		"/bogus",
+		"/exit",
+		"never sent",
+	}, "\n")
+
+	var out bytes.Buffer
+	require.NoError(t, chat.run(context.Background(), strings.NewReader(input), &out))
+
+	output := out.String()
+	assert.Contains(t, output, "work> ")
+	assert.Contains(t, output, "reply 1")
+	assert.Contains(t, output, "Switched to session 'personal' (0 messages)")
+	assert.Contains(t, output, "personal> ")
+	assert.Contains(t, output, "reply 2")
+	assert.Contains(t, output, "NAME")
+	assert.Contains(t, output, "unknown command /bogus")
+
+	require.Len(t, requests, 2)
+	// The switched-to session starts without the history of the first session
+	assert.Len(t, requests[1], 1)
+
+	work, err := store.Load("work")
+	require.NoError(t, err)
+	assert.Len(t, work.Messages, 2)
+	personal, err := store.Load("personal")
+	require.NoError(t, err)
+	assert.Len(t, personal.Messages, 2)
+}
+
+func TestChatSession_RunEndsAtEOF(t *testing.T) {
+	store := NewSessionStore(t.TempDir())
+	var requests [][]model.Message
+	chat, err := newChatSession(newRecordingLoop(&requests), &config.OrlaConfig{}, store, "work")
+	require.NoError(t, err)
+
+	require.NoError(t, chat.run(context.Background(), strings.NewReader("only prompt"), &bytes.Buffer{}))
+	assert.Len(t, requests, 1)
+}
+
+func TestListAndRemoveSessions(t *testing.T) {
+	t.Setenv(registry.OrlaHomeEnvVar, t.TempDir())
+
+	var out bytes.Buffer
+	require.NoError(t, ListSessions(&out))
+	assert.Contains(t, out.String(), "No sessions.")
+
+	store, err := NewDefaultSessionStore()
+	require.NoError(t, err)
+	_, err = store.Append("work", model.Message{Role: model.MessageRoleUser, Content: "hello"})
+	require.NoError(t, err)
+	_, err = store.Append("personal", model.Message{Role: model.MessageRoleUser, Content: "hello"})
+	require.NoError(t, err)
+
+	out.Reset()
+	require.NoError(t, ListSessions(&out))
+	assert.Contains(t, out.String(), "work")
+	assert.Contains(t, out.String(), "personal")
+
+	out.Reset()
+	require.NoError(t, RemoveSessions([]string{"work"}, &out))
+	assert.Contains(t, out.String(), "✓ Removed session work")
+
+	out.Reset()
+	require.NoError(t, ListSessions(&out))
+	assert.NotContains(t, out.String(), "work")
+	assert.Contains(t, out.String(), "personal")
+
+	err = RemoveSessions([]string{"missing"}, &out)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "session 'missing' not found")
+}
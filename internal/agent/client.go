@@ -3,13 +3,21 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"go.uber.org/zap"
+
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/model"
 )
 
 // Client wraps an MCP client session for communicating with the internal Orla server
@@ -17,6 +25,21 @@ type Client struct {
 	McpSession *mcp.ClientSession
 	McpClient  *mcp.Client
 	Cmd        *exec.Cmd
+
+	// progressCalls maps the progress token of each running tool call that wants progress
+	// notifications to the call
+	progressMu     sync.Mutex
+	progressCalls  map[string]*toolProgressCall
+	progressTokens atomic.Uint64
+}
+
+// ToolProgressHandler is called with each progress notification a tool sends during a call
+type ToolProgressHandler func(event *model.ToolProgressEvent)
+
+// toolProgressCall is a running tool call whose progress notifications are forwarded to handler
+type toolProgressCall struct {
+	name    string
+	handler ToolProgressHandler
 }
 
 func getOrlaBin() (string, error) {
@@ -31,6 +54,10 @@ func getOrlaBin() (string, error) {
 	return orlaBin, nil
 }
 
+// serverTerminateTimeout is how long Close waits for the server subprocess to exit at each step of
+// its shutdown (closing its stdin, then SIGTERM) before escalating. Mutable for testing.
+var serverTerminateTimeout = 2 * time.Second
+
 // NewClient creates a new MCP client that connects to the internal Orla server via stdio
 // The server is started as a subprocess running "orla serve --stdio"
 // If orlaBin is empty, it will use the current executable
@@ -40,31 +67,73 @@ func NewClient(ctx context.Context) (*Client, error) {
 		return nil, fmt.Errorf("failed to get orla binary path: %w", binErr)
 	}
 
-	// Create MCP client
-	mcpClient := mcp.NewClient(&mcp.Implementation{
-		Name:    "orla-agent",
-		Version: "1.0.0",
-	}, nil)
+	client, err := connectClient(ctx, exec.CommandContext(ctx, orlaBin, "serve", "--stdio"))
+	if err != nil {
+		return nil, err
+	}
+
+	zap.L().Debug("Connected to internal MCP server", zap.String("orla_bin", orlaBin))
+	return client, nil
+}
+
+// connectClient starts cmd as an MCP server in its own process group and connects to it over
+// stdio. The process group lets Close stop the processes the server spawned that share it.
+func connectClient(ctx context.Context, cmd *exec.Cmd) (*Client, error) {
+	client := &Client{Cmd: cmd}
+	client.McpClient = client.newMCPClient()
+
+	core.SetProcessGroup(cmd)
 
 	// Create stdio transport (spawns orla process)
-	cmd := exec.CommandContext(ctx, orlaBin, "serve", "--stdio")
 	transport := &mcp.CommandTransport{
-		Command: cmd,
+		Command:           cmd,
+		TerminateDuration: serverTerminateTimeout,
 	}
 
 	// Connect to server
-	session, connectErr := mcpClient.Connect(ctx, transport, nil)
+	session, connectErr := client.McpClient.Connect(ctx, transport, nil)
 	if connectErr != nil {
+		// Don't leave a half-started server behind
+		if err := core.TerminateProcessGroup(cmd, serverTerminateTimeout); err != nil {
+			zap.L().Warn("Failed to stop internal MCP server", zap.Error(err))
+		}
 		return nil, fmt.Errorf("failed to connect to internal MCP server: %w", connectErr)
 	}
 
-	zap.L().Debug("Connected to internal MCP server", zap.String("orla_bin", orlaBin))
+	client.McpSession = session
+	return client, nil
+}
 
-	return &Client{
-		McpSession: session,
-		McpClient:  mcpClient,
-		Cmd:        cmd,
-	}, nil
+// newMCPClient creates the MCP client of c, which forwards tool progress notifications to the
+// handler of the call they belong to
+func (c *Client) newMCPClient() *mcp.Client {
+	return mcp.NewClient(&mcp.Implementation{
+		Name:    "orla-agent",
+		Version: "1.0.0",
+	}, &mcp.ClientOptions{
+		ProgressNotificationHandler: c.handleProgressNotification,
+	})
+}
+
+// handleProgressNotification forwards a progress notification to the handler of its call.
+// Notifications for calls that have already returned are dropped.
+func (c *Client) handleProgressNotification(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+	token, _ := req.Params.ProgressToken.(string)
+
+	c.progressMu.Lock()
+	call, ok := c.progressCalls[token]
+	c.progressMu.Unlock()
+
+	if !ok {
+		zap.L().Debug("Dropping progress notification for unknown call", zap.Any("progress_token", req.Params.ProgressToken))
+		return
+	}
+	call.handler(&model.ToolProgressEvent{
+		Name:     call.name,
+		Message:  req.Params.Message,
+		Progress: req.Params.Progress,
+		Total:    req.Params.Total,
+	})
 }
 
 // ListTools lists all available tools from the MCP server
@@ -81,13 +150,50 @@ func (c *Client) ListTools(ctx context.Context) ([]*mcp.Tool, error) {
 
 // CallTool calls a tool via the MCP server
 func (c *Client) CallTool(ctx context.Context, params *mcp.CallToolParams) (*mcp.CallToolResult, error) {
+	return c.CallToolWithProgress(ctx, params, nil)
+}
+
+// CallToolWithProgress calls a tool via the MCP server like CallTool. If onProgress is non-nil,
+// the call asks the server for progress notifications, and each one that arrives before the
+// call returns is passed to onProgress. onProgress may be called concurrently for concurrent
+// calls. params is not modified.
+func (c *Client) CallToolWithProgress(ctx context.Context, params *mcp.CallToolParams, onProgress ToolProgressHandler) (*mcp.CallToolResult, error) {
 	if c.McpSession == nil {
 		return nil, fmt.Errorf("MCP session is not initialized")
 	}
-	return c.McpSession.CallTool(ctx, params)
+	if onProgress == nil {
+		return c.McpSession.CallTool(ctx, params)
+	}
+
+	token := fmt.Sprintf("orla-agent-%d", c.progressTokens.Add(1))
+	c.progressMu.Lock()
+	if c.progressCalls == nil {
+		c.progressCalls = make(map[string]*toolProgressCall)
+	}
+	c.progressCalls[token] = &toolProgressCall{name: params.Name, handler: onProgress}
+	c.progressMu.Unlock()
+
+	defer func() {
+		c.progressMu.Lock()
+		delete(c.progressCalls, token)
+		c.progressMu.Unlock()
+	}()
+
+	// SetProgressToken does nothing if the call has no _meta yet
+	progressParams := *params
+	progressParams.Meta = maps.Clone(params.Meta)
+	if progressParams.Meta == nil {
+		progressParams.Meta = mcp.Meta{}
+	}
+	progressParams.SetProgressToken(token)
+	return c.McpSession.CallTool(ctx, &progressParams)
 }
 
-// Close closes the MCP client session and cleans up the subprocess
+// Close closes the MCP client session and cleans up the subprocess. Closing the session closes
+// the server's stdin and waits for it to exit, sending SIGTERM and then SIGKILL if it does not.
+// On its way out the server stops its tools, capsules, and persistent processes, which run in
+// process groups of their own. Processes left in the server's group are then terminated the same
+// way. A server that has to be killed cannot stop its tools, so tools still running then survive.
 func (c *Client) Close() error {
 	var errs []error
 	if c.McpSession != nil {
@@ -97,14 +203,19 @@ func (c *Client) Close() error {
 	}
 
 	if c.Cmd != nil && c.Cmd.Process != nil {
-		// The process should be cleaned up when the context is cancelled,
+		// The process should be cleaned up when the session is closed,
 		// but we'll try to kill it if it's still running
 		if c.Cmd.ProcessState == nil {
 			// if the process state is nil, the process is still running
-			if err := c.Cmd.Process.Kill(); err != nil {
+			if err := c.Cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
 				errs = append(errs, fmt.Errorf("failed to kill subprocess: %w", err))
 			}
 		}
+
+		// The server's children share its process group and outlive it if it was killed
+		if err := core.TerminateProcessGroup(c.Cmd, serverTerminateTimeout); err != nil {
+			errs = append(errs, fmt.Errorf("failed to terminate subprocess group: %w", err))
+		}
 	}
 
 	if len(errs) > 0 {
@@ -8,11 +8,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/dorcha-inc/orla/internal/config"
 	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/model"
 	"github.com/dorcha-inc/orla/internal/server"
 	"github.com/dorcha-inc/orla/internal/state"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -150,6 +152,105 @@ func TestClient_CallTool_NilSession(t *testing.T) {
 	assert.Nil(t, result)
 }
 
+// connectProgressTestClient connects a Client to an in-memory MCP server with a "build" tool that
+// sends two progress notifications when the call asks for them, and waits for received to be
+// closed before returning its result. The tool reports the progress token it was called with.
+func connectProgressTestClient(t *testing.T, received <-chan struct{}) *Client {
+	t.Helper()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "build", Description: "Builds things"},
+		func(ctx context.Context, req *mcp.CallToolRequest, _ map[string]any) (*mcp.CallToolResult, any, error) {
+			token := req.Params.GetProgressToken()
+			if token != nil {
+				for i, chunk := range []string{"compiling\n", "linking\n"} {
+					if err := req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+						ProgressToken: token,
+						Message:       chunk,
+						Progress:      float64(i + 1),
+						Total:         2,
+					}); err != nil {
+						return nil, nil, err
+					}
+				}
+				select {
+				case <-received:
+				case <-time.After(2 * time.Second):
+				}
+			}
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("token: %v", token)}},
+			}, nil, nil
+		})
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError(serverSession.Close) })
+
+	client := &Client{}
+	client.McpClient = client.newMCPClient()
+	session, err := client.McpClient.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	client.McpSession = session
+	t.Cleanup(func() { core.LogDeferredError(session.Close) })
+
+	return client
+}
+
+func TestClient_CallToolWithProgress(t *testing.T) {
+	received := make(chan struct{})
+	client := connectProgressTestClient(t, received)
+
+	var mu sync.Mutex
+	var events []*model.ToolProgressEvent
+	onProgress := func(event *model.ToolProgressEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+		if len(events) == 2 {
+			close(received)
+		}
+	}
+
+	params := &mcp.CallToolParams{Name: "build", Arguments: map[string]any{}}
+	result, err := client.CallToolWithProgress(context.Background(), params, onProgress)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Nil(t, params.Meta, "params should not be modified")
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, events, 2)
+	assert.Equal(t, &model.ToolProgressEvent{Name: "build", Message: "compiling\n", Progress: 1, Total: 2}, events[0])
+	assert.Equal(t, &model.ToolProgressEvent{Name: "build", Message: "linking\n", Progress: 2, Total: 2}, events[1])
+
+	// The call's progress token is forgotten once it returns
+	client.progressMu.Lock()
+	assert.Empty(t, client.progressCalls)
+	client.progressMu.Unlock()
+}
+
+func TestClient_CallTool_NoProgressRequested(t *testing.T) {
+	client := connectProgressTestClient(t, nil)
+
+	result, err := client.CallTool(context.Background(), &mcp.CallToolParams{Name: "build", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	require.Len(t, result.Content, 1)
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Equal(t, "token: <nil>", textContent.Text, "no progress token should be sent")
+}
+
+func TestClient_HandleProgressNotification_UnknownToken(t *testing.T) {
+	client := &Client{}
+	// Notifications for calls that already returned, or were never made, are dropped
+	client.handleProgressNotification(context.Background(), &mcp.ProgressNotificationClientRequest{
+		Params: &mcp.ProgressNotificationParams{ProgressToken: "orla-agent-42", Message: "late"},
+	})
+}
+
 func TestClient_Close_NilSessionAndCmd(t *testing.T) {
 	client := &Client{
 		McpSession: nil,
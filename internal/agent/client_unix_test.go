@@ -0,0 +1,110 @@
+//go:build !windows
+
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServerScript is a minimal MCP server over stdio. It starts a long-running child, as orla
+// serve does for capsules and running tools, writes the child's pid to $1, answers the initialize
+// request, and then reads requests until stdin is closed. The trap line is filled in per test.
+const fakeServerScript = `#!/bin/sh
+%s
+sleep 60 &
+echo $! > "$1"
+read -r line
+id=$(echo "$line" | sed 's/.*"id":\([0-9]*\).*/\1/')
+echo '{"jsonrpc":"2.0","id":'"$id"',"result":{"protocolVersion":"2025-06-18","capabilities":{},"serverInfo":{"name":"fake","version":"1.0.0"}}}'
+%s
+`
+
+// startFakeServer connects a client to a fake MCP server and returns it with the pid of the
+// server's child
+func startFakeServer(t *testing.T, trap, afterInit string) (*Client, int) {
+	t.Helper()
+
+	dir := t.TempDir()
+	scriptPath := filepath.Join(dir, "server.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(scriptPath, fmt.Appendf(nil, fakeServerScript, trap, afterInit), 0755))
+	pidFile := filepath.Join(dir, "child.pid")
+
+	client, err := connectClient(t.Context(), exec.Command(scriptPath, pidFile))
+	require.NoError(t, err)
+
+	// #nosec G304 -- path is constructed from a test temp directory, safe
+	data, err := os.ReadFile(pidFile)
+	require.NoError(t, err)
+	childPid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	require.NoError(t, err)
+
+	return client, childPid
+}
+
+// processExited reports whether pid has exited. A zombie has exited but stays visible until its
+// parent, here the init process of the test environment, reaps it.
+func processExited(pid int) bool {
+	if err := syscall.Kill(pid, 0); errors.Is(err, syscall.ESRCH) {
+		return true
+	}
+	// #nosec G304 -- path is built from a pid
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return errors.Is(err, os.ErrNotExist) && !procAvailable()
+	}
+	// The state follows the command name, which is in parentheses
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) > 0 && fields[0] == "Z"
+}
+
+// procAvailable reports whether /proc exposes process state (Linux)
+func procAvailable() bool {
+	_, err := os.Stat("/proc/self/stat")
+	return err == nil
+}
+
+func TestClient_Close_StopsServerAndChildren(t *testing.T) {
+	client, childPid := startFakeServer(t, "", "while read -r line; do :; done")
+	serverPid := client.Cmd.Process.Pid
+
+	start := time.Now()
+	require.NoError(t, client.Close())
+	assert.Less(t, time.Since(start), 2*serverTerminateTimeout, "Close should return promptly")
+
+	require.NotNil(t, client.Cmd.ProcessState, "the server should have been waited for")
+	assert.True(t, client.Cmd.ProcessState.Success(), "a server that exits on EOF should not need signals")
+	assert.True(t, processExited(serverPid), "the server should have exited")
+	assert.Eventually(t, func() bool { return processExited(childPid) }, time.Second, 10*time.Millisecond,
+		"the server's child should not be left running")
+}
+
+func TestClient_Close_KillsUnresponsiveServer(t *testing.T) {
+	original := serverTerminateTimeout
+	serverTerminateTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { serverTerminateTimeout = original })
+
+	// The server ignores both EOF on stdin and SIGTERM
+	client, childPid := startFakeServer(t, "trap '' TERM", "while :; do sleep 1; done")
+	serverPid := client.Cmd.Process.Pid
+
+	start := time.Now()
+	assert.Error(t, client.Close(), "the server's exit by SIGKILL should be reported")
+	assert.Less(t, time.Since(start), 2*time.Second, "Close should escalate to SIGKILL promptly")
+
+	assert.True(t, processExited(serverPid), "the server should have been killed")
+	assert.Eventually(t, func() bool { return processExited(childPid) }, time.Second, 10*time.Millisecond,
+		"the server's child should not be left running")
+}
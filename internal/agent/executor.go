@@ -4,6 +4,7 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -27,7 +28,7 @@ type Executor struct {
 // NewExecutor creates a new agent executor
 func NewExecutor(cfg *config.OrlaConfig) (*Executor, error) {
 	// Create model provider
-	provider, err := model.NewProvider(cfg)
+	provider, err := model.NewProvider(cfg.Model, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create model provider: %w", err)
 	}
@@ -138,6 +139,18 @@ func createStreamHandler(cfg *config.OrlaConfig) StreamHandler {
 				return fmt.Errorf("failed to marshal tool call arguments: %w", err)
 			}
 			fmt.Fprintf(os.Stderr, "\ntool call received: %s\nparams: %s\n", e.Name, string(argsJSON))
+		case *model.ToolProgressEvent:
+			// Tool output is shown in full with the tool call details, and otherwise as the
+			// latest line in the progress spinner
+			if showToolCalls {
+				fmt.Fprint(os.Stderr, e.Message)
+				return nil
+			}
+			lines := strings.Split(strings.TrimSpace(e.Message), "\n")
+			if line := strings.TrimSpace(lines[len(lines)-1]); line != "" {
+				tui.Progress(fmt.Sprintf("%s: %s", e.Name, line))
+			}
+			return nil
 		default:
 			return fmt.Errorf("unknown stream event type: %T", e)
 		}
@@ -170,14 +183,54 @@ func readStdinIfAvailable() (string, bool, error) {
 	return string(data), true, nil
 }
 
+// applyPromptOverrides applies command-line overrides to the loaded config
+func applyPromptOverrides(cfg *config.OrlaConfig, modelOverride string, formatOverride string, noCache bool, failOnToolError bool, deadline int) {
+	// Override model if specified
+	if modelOverride != "" {
+		cfg.Model = modelOverride
+	}
+
+	// Override the response format if specified
+	if formatOverride != "" {
+		cfg.ModelFormat = formatOverride
+	}
+
+	// Bypass the response cache if requested
+	if noCache {
+		cfg.ResponseCache = false
+	}
+
+	// Abort on the first failed tool call if requested
+	if failOnToolError {
+		cfg.FailOnToolError = true
+	}
+
+	// Override the run deadline if specified
+	if deadline > 0 {
+		cfg.RunDeadline = deadline
+	}
+}
+
 // ExecuteAgentPrompt is the main entry point for agent execution
 // It handles the full flow: config loading, executor creation, context/signal handling, and execution
 // prompt: the agent prompt as a single string (should be quoted when called from CLI)
-func ExecuteAgentPrompt(prompt string, modelOverride string) error {
+// formatOverride: if set, constrain the response to "json" or to this JSON schema
+// noCache: if true, bypass the model response cache even if it is enabled in the config
+// failOnToolError: if true, abort on the first failed tool call instead of returning the error to the model
+// deadline: if positive, the time limit for the whole run in seconds, overriding run_deadline
+func ExecuteAgentPrompt(prompt string, modelOverride string, formatOverride string, noCache bool, failOnToolError bool, deadline int) error {
 	if prompt == "" {
 		return fmt.Errorf("prompt is required")
 	}
 
+	if deadline < 0 {
+		return fmt.Errorf("--deadline cannot be negative, got %d", deadline)
+	}
+
+	if err := config.ValidateModelFormat(formatOverride); err != nil {
+		return fmt.Errorf("--format %w", err)
+	}
+
 	// Read stdin if available (piped input)
 	// This makes commands like "summarize this" < file.txt work correctly
 	stdinContent, hasStdin, err := readStdinIfAvailable()
@@ -199,39 +252,77 @@ func ExecuteAgentPrompt(prompt string, modelOverride string) error {
 		return fmt.Errorf("failed to load config: %w", configErr)
 	}
 
-	// Override model if specified
-	if modelOverride != "" {
-		cfg.Model = modelOverride
+	applyPromptOverrides(cfg, modelOverride, formatOverride, noCache, failOnToolError, deadline)
+
+	ctx, cancel := newSignalContext()
+	defer cancel()
+
+	loop, closeClient, err := startAgent(ctx, cfg)
+	if err != nil {
+		return err
 	}
+	defer core.LogDeferredError(closeClient)
 
-	// Create executor
-	executor, executorErr := NewExecutor(cfg)
-	if executorErr != nil {
-		return fmt.Errorf("failed to create executor: %w", executorErr)
+	// Create stream handler if streaming is enabled
+	var streamHandler StreamHandler
+	if cfg.Streaming {
+		streamHandler = createStreamHandler(cfg)
+	}
+
+	// Execute agent loop (handles both streaming and non-streaming internally)
+	response, executeErr := loop.Execute(ctx, prompt, nil, cfg.Streaming, streamHandler)
+	if executeErr != nil {
+		// Show what the model produced before the run deadline passed
+		var deadlineErr *RunDeadlineExceededError
+		if errors.As(executeErr, &deadlineErr) && response != nil {
+			printResponse(os.Stdout, cfg, response)
+		}
+		return fmt.Errorf("agent execution failed: %w", executeErr)
+	}
+
+	if response == nil {
+		return fmt.Errorf("response is nil")
 	}
 
-	// Create context with cancellation and signal handling
+	printResponse(os.Stdout, cfg, response)
+	return nil
+}
+
+// newSignalContext returns a context that is cancelled on SIGINT or SIGTERM
+func newSignalContext() (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
 
-	// Handle signals
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	go func() {
-		<-sigChan
-		cancel()
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(sigChan)
 	}()
 
-	// Set show progress based on config
-	if cfg != nil {
-		tui.SetShowProgress(cfg.ShowProgress)
+	return ctx, cancel
+}
+
+// startAgent prepares the model and connects to the tools, returning an agent loop and a
+// function that closes the MCP client
+func startAgent(ctx context.Context, cfg *config.OrlaConfig) (*Loop, func() error, error) {
+	// Create executor
+	executor, executorErr := NewExecutor(cfg)
+	if executorErr != nil {
+		return nil, nil, fmt.Errorf("failed to create executor: %w", executorErr)
 	}
 
+	// Set show progress based on config
+	tui.SetShowProgress(cfg.ShowProgress)
+
 	// Ensure model is ready
 	tui.Progress("Ensuring model is ready...")
 	ensureReadyErr := executor.provider.EnsureReady(ctx)
 	if ensureReadyErr != nil {
-		return fmt.Errorf("model not ready: %w", ensureReadyErr)
+		return nil, nil, fmt.Errorf("model not ready: %w", ensureReadyErr)
 	}
 	tui.ProgressSuccess("Model ready")
 
@@ -240,41 +331,27 @@ func ExecuteAgentPrompt(prompt string, modelOverride string) error {
 	tui.Progress("Connecting to tools...")
 	mcpClient, clientErr := NewClient(ctx)
 	if clientErr != nil {
-		return fmt.Errorf("failed to create MCP client: %w", clientErr)
+		return nil, nil, fmt.Errorf("failed to create MCP client: %w", clientErr)
 	}
 
 	mcpTools, err := mcpClient.ListTools(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to list tools: %w", err)
+		core.LogDeferredError(mcpClient.Close)
+		return nil, nil, fmt.Errorf("failed to list tools: %w", err)
 	}
 
 	tui.ProgressSuccess(fmt.Sprintf("Connected to %d tools", len(mcpTools)))
 
-	defer core.LogDeferredError(mcpClient.Close)
-
 	// Create agent loop
-	loop := NewLoop(mcpClient, executor.provider, cfg)
-
-	// Create stream handler if streaming is enabled
-	var streamHandler StreamHandler
-	if cfg.Streaming {
-		streamHandler = createStreamHandler(cfg)
-	}
-
-	// Execute agent loop (handles both streaming and non-streaming internally)
-	response, executeErr := loop.Execute(ctx, prompt, nil, cfg.Streaming, streamHandler)
-	if executeErr != nil {
-		return fmt.Errorf("agent execution failed: %w", executeErr)
-	}
-
-	if response == nil {
-		return fmt.Errorf("response is nil")
-	}
+	return NewLoop(mcpClient, executor.provider, cfg), mcpClient.Close, nil
+}
 
+// printResponse prints the final response of an agent execution to w
+func printResponse(w io.Writer, cfg *config.OrlaConfig, response *model.Response) {
 	// Print newline after streaming (if streaming was enabled)
 	if cfg.Streaming {
-		fmt.Println()
-		return nil
+		core.MustFprintf(w, "\n")
+		return
 	}
 
 	// Print thinking trace if present and enabled (non-streaming)
@@ -292,12 +369,10 @@ func ExecuteAgentPrompt(prompt string, modelOverride string) error {
 		rendered, err := tui.RenderMarkdown(response.Content, 80)
 		if err == nil && rendered != response.Content {
 			// Successfully rendered markdown
-			fmt.Print(rendered)
+			core.MustFprintf(w, "%s", rendered)
 		} else {
 			// Plain text or rendering failed
-			fmt.Println(response.Content)
+			core.MustFprintf(w, "%s\n", response.Content)
 		}
 	}
-
-	return nil
 }
@@ -166,6 +166,24 @@ func TestDefaultStreamHandler_UnknownEventType(t *testing.T) {
 	assert.Contains(t, err.Error(), "unknown stream event type")
 }
 
+func TestDefaultStreamHandler_ToolProgressEvent(t *testing.T) {
+	event := &model.ToolProgressEvent{Name: "build", Message: "compiling\nlinking\n", Progress: 18}
+
+	capturedOutput, err := orlaTesting.NewCapturedOutput()
+	require.NoError(t, err)
+
+	err = createStreamHandler(&config.OrlaConfig{ShowToolCalls: true})(event)
+	require.NoError(t, err)
+
+	stdout, stderr, err := capturedOutput.Stop()
+	require.NoError(t, err)
+	assert.Empty(t, stdout, "Stdout should be empty for ToolProgressEvent")
+	assert.Contains(t, stderr, "compiling\nlinking\n", "Stderr should contain the tool output")
+
+	// Without tool call details the progress is only shown in the spinner
+	require.NoError(t, createStreamHandler(&config.OrlaConfig{})(event))
+}
+
 func TestCreateStreamHandler_Thinking(t *testing.T) {
 	cfg := &config.OrlaConfig{ShowThinking: true}
 
@@ -294,7 +312,7 @@ func TestCreateStreamHandler_DuplicateToolNames(t *testing.T) {
 
 func TestExecuteAgentPrompt_EmptyPrompt(t *testing.T) {
 	// Test that ExecuteAgentPrompt handles empty prompt
-	err := ExecuteAgentPrompt("", "")
+	err := ExecuteAgentPrompt("", "", "", false, false, 0)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "prompt is required")
 }
@@ -302,9 +320,44 @@ func TestExecuteAgentPrompt_EmptyPrompt(t *testing.T) {
 func TestExecuteAgentPrompt_ModelOverride(t *testing.T) {
 	// Test that model override is applied
 	// We can verify the model override is passed through by checking error messages
-	err := ExecuteAgentPrompt("test prompt", "invalid-model-override")
+	err := ExecuteAgentPrompt("test prompt", "invalid-model-override", "", false, false, 0)
 	// Should fail because the model override format is invalid
 	require.Error(t, err)
 	// The error should indicate the model override was attempted and failed validation
 	assert.Contains(t, err.Error(), "invalid-model-override", "Error should mention the model override that was attempted")
 }
+
+func TestExecuteAgentPrompt_NegativeDeadline(t *testing.T) {
+	err := ExecuteAgentPrompt("test prompt", "", "", false, false, -1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--deadline cannot be negative")
+}
+
+func TestApplyPromptOverrides(t *testing.T) {
+	cfg := &config.OrlaConfig{Model: "ollama:llama3", ResponseCache: true}
+	applyPromptOverrides(cfg, "", "", false, false, 0)
+	assert.Equal(t, "ollama:llama3", cfg.Model)
+	assert.True(t, cfg.ResponseCache)
+
+	applyPromptOverrides(cfg, "ollama:qwen3", "", true, false, 0)
+	assert.Equal(t, "ollama:qwen3", cfg.Model)
+	assert.False(t, cfg.ResponseCache, "--no-cache should disable the response cache")
+	assert.False(t, cfg.FailOnToolError)
+
+	applyPromptOverrides(cfg, "", "", false, true, 0)
+	assert.True(t, cfg.FailOnToolError, "--fail-on-error should abort on tool errors")
+	assert.Empty(t, cfg.ModelFormat)
+
+	applyPromptOverrides(cfg, "", "json", false, false, 0)
+	assert.Equal(t, "json", cfg.ModelFormat, "--format should set the response format")
+	assert.Zero(t, cfg.RunDeadline)
+
+	applyPromptOverrides(cfg, "", "", false, false, 30)
+	assert.Equal(t, 30, cfg.RunDeadline, "--deadline should set the run deadline")
+
+	// With the cache disabled, the provider is not wrapped in the cache
+	provider, err := model.NewProvider(cfg.Model, cfg)
+	require.NoError(t, err)
+	_, cached := provider.(*model.CachingProvider)
+	assert.False(t, cached)
+}
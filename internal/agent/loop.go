@@ -3,8 +3,12 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/dorcha-inc/orla/internal/config"
 	"github.com/dorcha-inc/orla/internal/model"
@@ -19,6 +23,13 @@ type MCPClient interface {
 	CallTool(ctx context.Context, params *mcp.CallToolParams) (*mcp.CallToolResult, error)
 }
 
+// ProgressMCPClient is implemented by MCP clients that can forward the progress notifications a
+// tool sends during a call, such as Client. The agent loop uses it to show tool progress through
+// the stream handler.
+type ProgressMCPClient interface {
+	CallToolWithProgress(ctx context.Context, params *mcp.CallToolParams, onProgress ToolProgressHandler) (*mcp.CallToolResult, error)
+}
+
 // Loop orchestrates the agent execution flow
 type Loop struct {
 	client   MCPClient
@@ -35,6 +46,58 @@ func NewLoop(client MCPClient, provider model.Provider, cfg *config.OrlaConfig)
 	}
 }
 
+// wrapPrompt returns prompt with the configured prompt_prefix before it and prompt_suffix after it,
+// each separated from the prompt by a blank line
+func wrapPrompt(cfg *config.OrlaConfig, prompt string) string {
+	parts := make([]string, 0, 3)
+	if cfg.PromptPrefix != "" {
+		parts = append(parts, cfg.PromptPrefix)
+	}
+	parts = append(parts, prompt)
+	if cfg.PromptSuffix != "" {
+		parts = append(parts, cfg.PromptSuffix)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// historyThinking returns the thinking trace of response to keep in the conversation history.
+// Thinking is shown as it streams, but sending it back to the model with every later turn is
+// rarely useful and costs tokens, so it is dropped from the history unless keep_thinking is set.
+func historyThinking(cfg *config.OrlaConfig, response *model.Response) string {
+	if !cfg.KeepThinking {
+		return ""
+	}
+	return response.Thinking
+}
+
+// hasSystemMessage reports whether any of the messages is a system message
+func hasSystemMessage(messages []model.Message) bool {
+	for _, message := range messages {
+		if message.Role == model.MessageRoleSystem {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateAtStop returns content up to the earliest of the stop sequences in it, and whether any
+// stop sequence was found
+func truncateAtStop(content string, stops []string) (string, bool) {
+	end := -1
+	for _, stop := range stops {
+		if stop == "" {
+			continue
+		}
+		if i := strings.Index(content, stop); i >= 0 && (end < 0 || i < end) {
+			end = i
+		}
+	}
+	if end < 0 {
+		return content, false
+	}
+	return content[:end], true
+}
+
 // StreamHandler is a function that handles streaming events
 type StreamHandler func(event model.StreamEvent) error
 
@@ -50,14 +113,31 @@ type StreamHandler func(event model.StreamEvent) error
 //
 // If streamHandler is provided and streaming is enabled, it will be called for each chunk.
 // The stream will be consumed before checking for tool calls, ensuring the response is complete.
+//
+// If run_deadline is set, the whole run must finish within it. Once it passes, in-flight model
+// and tool calls are cancelled and Execute returns a RunDeadlineExceededError together with a
+// response holding the content the model produced until then.
 func (l *Loop) Execute(ctx context.Context, prompt string, messages []model.Message, stream bool, streamHandler StreamHandler) (*model.Response, error) {
 	if stream && streamHandler == nil {
 		return nil, fmt.Errorf("stream handler is required when streaming is enabled")
 	}
 
+	if l.cfg.RunDeadline > 0 {
+		deadline := time.Duration(l.cfg.RunDeadline) * time.Second
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, deadline, &RunDeadlineExceededError{Deadline: deadline})
+		defer cancel()
+	}
+
+	// The content of each model turn so far, returned if the run deadline passes
+	var partial []string
+
 	// Get available tools from the MCP server
 	tools, err := l.client.ListTools(ctx)
 	if err != nil {
+		if deadlineErr := runDeadlineExceeded(ctx); deadlineErr != nil {
+			return partialResponse(partial), deadlineErr
+		}
 		return nil, fmt.Errorf("failed to list tools: %w", err)
 	}
 
@@ -66,15 +146,23 @@ func (l *Loop) Execute(ctx context.Context, prompt string, messages []model.Mess
 		zap.Int("tool_count", len(tools)),
 		zap.Int("message_count", len(messages)))
 
-	// Build conversation messages
-	conversation := make([]model.Message, len(messages))
-	copy(conversation, messages)
+	// Build conversation messages, starting with the configured system prompt unless the
+	// conversation already has its own
+	conversation := make([]model.Message, 0, len(messages)+2)
+	if l.cfg.SystemPrompt != "" && !hasSystemMessage(messages) {
+		conversation = append(conversation, model.Message{
+			Role:    model.MessageRoleSystem,
+			Content: l.cfg.SystemPrompt,
+		})
+	}
+	conversation = append(conversation, messages...)
 
-	// Add the new user prompt
+	// Add the new user prompt, wrapped in the configured prefix and suffix. Only the new prompt is
+	// wrapped; system messages and the history are sent as they are.
 	if prompt != "" {
 		conversation = append(conversation, model.Message{
 			Role:    model.MessageRoleUser,
-			Content: prompt,
+			Content: wrapPrompt(l.cfg, prompt),
 		})
 	}
 
@@ -82,6 +170,20 @@ func (l *Loop) Execute(ctx context.Context, prompt string, messages []model.Mess
 	mcpTools := make([]*mcp.Tool, len(tools))
 	copy(mcpTools, tools)
 
+	// Tool progress is shown through the stream handler. Calls may run in parallel, so the
+	// handler is serialized.
+	var onProgress ToolProgressHandler
+	if streamHandler != nil {
+		var progressMu sync.Mutex
+		onProgress = func(event *model.ToolProgressEvent) {
+			progressMu.Lock()
+			defer progressMu.Unlock()
+			if err := streamHandler(event); err != nil {
+				zap.L().Debug("Stream handler failed to show tool progress", zap.String("tool", event.Name), zap.Error(err))
+			}
+		}
+	}
+
 	// Maximum number of tool call iterations to prevent infinite loops
 	maxIterations := l.cfg.MaxToolCalls
 	if maxIterations <= 0 {
@@ -100,6 +202,9 @@ func (l *Loop) Execute(ctx context.Context, prompt string, messages []model.Mess
 		response, streamCh, err := l.provider.Chat(ctx, conversation, mcpTools, stream)
 
 		if err != nil {
+			if deadlineErr := runDeadlineExceeded(ctx); deadlineErr != nil {
+				return partialResponse(partial), deadlineErr
+			}
 			return nil, fmt.Errorf("model chat failed: %w", err)
 		}
 
@@ -124,6 +229,19 @@ func (l *Loop) Execute(ctx context.Context, prompt string, messages []model.Mess
 			// Stream is now complete, response should be fully populated
 		}
 
+		// A stream cut short by the run deadline leaves the content received until then
+		if deadlineErr := runDeadlineExceeded(ctx); deadlineErr != nil {
+			return partialResponse(append(partial, response.Content)), deadlineErr
+		}
+
+		// A stop sequence in the response ends the turn, even if the model went on to request tools.
+		// Providers normally stop before the sequence, but not all of them leave it out.
+		if content, hit := truncateAtStop(response.Content, l.cfg.Stop); hit {
+			zap.L().Debug("Model response reached a stop sequence", zap.Int("dropped_tool_calls", len(response.ToolCalls)))
+			response.Content = content
+			response.ToolCalls = nil
+		}
+
 		// If there are no tool calls, we're done
 		if len(response.ToolCalls) == 0 {
 			// Final response - return it
@@ -140,24 +258,31 @@ func (l *Loop) Execute(ctx context.Context, prompt string, messages []model.Mess
 			tui.Progress(fmt.Sprintf("Executing orla mcp tools: %s", strings.Join(toolNames, ", ")))
 		}
 
+		partial = append(partial, response.Content)
+
 		// Execute tool calls
-		toolResults := l.executeToolCalls(ctx, response.ToolCalls)
+		toolResults, err := l.executeToolCalls(ctx, response.ToolCalls, onProgress)
+		if deadlineErr := runDeadlineExceeded(ctx); deadlineErr != nil {
+			return partialResponse(partial), deadlineErr
+		}
+		if err != nil {
+			return nil, err
+		}
 
 		tui.ProgressSuccess("")
 
 		// Add tool results to conversation for next iteration
-		// Format: assistant message (with content if any), then tool results as tool messages
-		// Ollama supports "tool" role messages with tool_name and content fields
-		if response.Content != "" {
-			conversation = append(conversation, model.Message{
-				Role:    model.MessageRoleAssistant,
-				Content: response.Content,
-			})
-		}
+		// Format: assistant message with the tool calls, then tool results as tool messages
+		conversation = append(conversation, model.Message{
+			Role:      model.MessageRoleAssistant,
+			Content:   response.Content,
+			ToolCalls: response.ToolCalls,
+			Thinking:  historyThinking(l.cfg, response),
+		})
 
 		// Add tool results as tool messages (one per tool call)
 		// Each tool result becomes a separate message with role "tool"
-		// Ollama matches tool results to tool calls by tool_name
+		// Ollama matches tool results to tool calls by tool_name, OpenAI by tool_call_id
 		for _, result := range toolResults {
 			// Find the corresponding tool call to get the tool name
 			var toolName string
@@ -180,9 +305,10 @@ func (l *Loop) Execute(ctx context.Context, prompt string, messages []model.Mess
 			resultContent := formatToolResult(result)
 
 			conversation = append(conversation, model.Message{
-				Role:     model.MessageRoleTool,
-				ToolName: toolName,
-				Content:  resultContent,
+				Role:       model.MessageRoleTool,
+				ToolName:   toolName,
+				ToolCallID: result.ID,
+				Content:    resultContent,
 			})
 		}
 
@@ -195,72 +321,246 @@ func (l *Loop) Execute(ctx context.Context, prompt string, messages []model.Mess
 	return nil, fmt.Errorf("maximum tool call iterations (%d) reached", maxIterations)
 }
 
-// executeToolCalls executes a list of tool calls via MCP and returns the results
-func (l *Loop) executeToolCalls(ctx context.Context, toolCalls []model.ToolCallWithID) []model.ToolResultWithID {
+// RunDeadlineExceededError is returned by Execute when run_deadline passes before the run ends
+type RunDeadlineExceededError struct {
+	Deadline time.Duration
+}
+
+func (e *RunDeadlineExceededError) Error() string {
+	return fmt.Sprintf("run deadline of %s exceeded, the response is incomplete", e.Deadline)
+}
+
+// Unwrap makes a RunDeadlineExceededError match context.DeadlineExceeded
+func (e *RunDeadlineExceededError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// Interface guard for RunDeadlineExceededError
+var _ error = &RunDeadlineExceededError{}
+
+// runDeadlineExceeded returns the RunDeadlineExceededError ctx was cancelled with, or nil if the
+// run deadline has not passed
+func runDeadlineExceeded(ctx context.Context) error {
+	var deadlineErr *RunDeadlineExceededError
+	if errors.As(context.Cause(ctx), &deadlineErr) {
+		return deadlineErr
+	}
+	return nil
+}
+
+// partialResponse returns a response with the non-empty content of the given model turns
+func partialResponse(turns []string) *model.Response {
+	content := make([]string, 0, len(turns))
+	for _, turn := range turns {
+		if turn != "" {
+			content = append(content, turn)
+		}
+	}
+	return &model.Response{Content: strings.Join(content, "\n\n")}
+}
+
+// ToolCallFailedError is returned by Execute when --fail-on-error is set and a tool call fails
+type ToolCallFailedError struct {
+	Tool    string
+	Message string
+}
+
+func (e *ToolCallFailedError) Error() string {
+	return fmt.Sprintf("tool %s failed: %s (run without --fail-on-error to let the model recover from tool errors)", e.Tool, e.Message)
+}
+
+// Interface guard for ToolCallFailedError
+var _ error = &ToolCallFailedError{}
+
+// executeToolCalls executes the tool calls via MCP and returns their results in the order of
+// toolCalls. Up to max_parallel_tool_calls calls run at once. A failed call is returned to the
+// model as an error result, unless --fail-on-error is set, in which case no further calls are
+// started and the earliest failed call in toolCalls is returned as a ToolCallFailedError. If
+// onProgress is non-nil, the progress of each call is passed to it.
+func (l *Loop) executeToolCalls(ctx context.Context, toolCalls []model.ToolCallWithID, onProgress ToolProgressHandler) ([]model.ToolResultWithID, error) {
+	workers := min(max(l.cfg.MaxParallelToolCalls, 1), len(toolCalls))
+
 	zap.L().Debug("Executing tool calls",
-		zap.Int("count", len(toolCalls)))
+		zap.Int("count", len(toolCalls)),
+		zap.Int("parallel", workers))
+
+	toolResults := make([]model.ToolResultWithID, len(toolCalls))
+	failures := make([]error, len(toolCalls))
+	var failed atomic.Bool
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if failed.Load() {
+					continue
+				}
+				toolResults[i], failures[i] = l.executeToolCall(ctx, toolCalls[i], onProgress)
+				if failures[i] != nil {
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+
+	for i := range toolCalls {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
 
-	toolResults := make([]model.ToolResultWithID, 0, len(toolCalls))
-	for _, toolCall := range toolCalls {
-		// Execute tool call via MCP
-		result, err := l.client.CallTool(ctx, &toolCall.McpCallToolParams)
+	for _, err := range failures {
 		if err != nil {
-			zap.L().Warn("Tool call failed",
-				zap.String("tool", toolCall.McpCallToolParams.Name),
-				zap.Error(err))
-
-			// Create error result
-			toolResults = append(toolResults, model.ToolResultWithID{
-				ID: toolCall.ID,
-				McpCallToolResult: mcp.CallToolResult{
-					IsError: true,
-					Content: []mcp.Content{
-						&mcp.TextContent{
-							Text: fmt.Sprintf("Tool call failed: %v", err),
-						},
+			return nil, err
+		}
+	}
+	return toolResults, nil
+}
+
+// executeToolCall executes a single tool call via MCP. A failed call becomes an error result for
+// the model, or a ToolCallFailedError if --fail-on-error is set.
+func (l *Loop) executeToolCall(ctx context.Context, toolCall model.ToolCallWithID, onProgress ToolProgressHandler) (model.ToolResultWithID, error) {
+	result, err := l.callTool(ctx, &toolCall.McpCallToolParams, onProgress)
+	if err != nil {
+		zap.L().Warn("Tool call failed",
+			zap.String("tool", toolCall.McpCallToolParams.Name),
+			zap.Error(err))
+
+		if l.cfg.FailOnToolError {
+			return model.ToolResultWithID{}, &ToolCallFailedError{Tool: toolCall.McpCallToolParams.Name, Message: err.Error()}
+		}
+
+		// Create error result
+		return model.ToolResultWithID{
+			ID: toolCall.ID,
+			McpCallToolResult: mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Tool call failed: %v", err),
 					},
 				},
-			})
-			continue
-		}
+			},
+		}, nil
+	}
 
-		// Add successful result
-		toolResults = append(toolResults, model.ToolResultWithID{
-			ID:                toolCall.ID,
-			McpCallToolResult: *result,
-		})
+	zap.L().Debug("Tool call completed",
+		zap.String("tool", toolCall.McpCallToolParams.Name),
+		zap.Bool("is_error", result.IsError))
 
-		zap.L().Debug("Tool call completed",
-			zap.String("tool", toolCall.McpCallToolParams.Name),
-			zap.Bool("is_error", result.IsError))
+	toolResult := model.ToolResultWithID{
+		ID:                toolCall.ID,
+		McpCallToolResult: *result,
+	}
+
+	if result.IsError && l.cfg.FailOnToolError {
+		return model.ToolResultWithID{}, &ToolCallFailedError{Tool: toolCall.McpCallToolParams.Name, Message: toolResultText(toolResult.McpCallToolResult)}
 	}
 
-	return toolResults
+	// Tool errors are returned to the model so it can recover
+	return toolResult, nil
+}
+
+// callTool calls a tool via MCP, forwarding its progress to onProgress if it is non-nil and the
+// client supports progress notifications
+func (l *Loop) callTool(ctx context.Context, params *mcp.CallToolParams, onProgress ToolProgressHandler) (*mcp.CallToolResult, error) {
+	if progressClient, ok := l.client.(ProgressMCPClient); ok && onProgress != nil {
+		return progressClient.CallToolWithProgress(ctx, params, onProgress)
+	}
+	return l.client.CallTool(ctx, params)
 }
 
-// formatToolResult formats a single tool result as text for the model
-// Returns just the content text - Ollama will match results to calls by tool_name
+// formatToolResult formats a single tool result as text for the model. Failed results end with
+// an error marker including the exit code when the tool reports one, so that the model gets an
+// accurate picture of multi-part results.
 func formatToolResult(result model.ToolResultWithID) string {
-	var text string
+	callResult := result.McpCallToolResult
+	text := toolResultText(callResult)
 
-	// Extract text content from result
-	for _, content := range result.McpCallToolResult.Content {
-		if textContent, ok := content.(*mcp.TextContent); ok {
-			if text != "" {
-				text += "\n"
-			}
-			text += textContent.Text
-		}
+	if !callResult.IsError || len(toolResultParts(callResult)) == 0 {
+		return text
 	}
+	if exitCode, ok := toolResultExitCode(callResult.StructuredContent); ok {
+		return fmt.Sprintf("%s\n[error: exit %d]", text, exitCode)
+	}
+	return text + "\n[error]"
+}
+
+// toolResultText returns the content of a tool result as text. Text content is included as is
+// and other content is summarized (e.g. "[image omitted: image/png]").
+func toolResultText(callResult mcp.CallToolResult) string {
+	parts := toolResultParts(callResult)
 
 	// If no content was found, provide a default message
-	if text == "" {
-		if result.McpCallToolResult.IsError {
-			text = "Tool execution failed"
-		} else {
-			text = "Tool executed successfully"
+	if len(parts) == 0 {
+		if !callResult.IsError {
+			return "Tool executed successfully"
+		}
+		if exitCode, ok := toolResultExitCode(callResult.StructuredContent); ok {
+			return fmt.Sprintf("Tool execution failed (exit %d)", exitCode)
+		}
+		return "Tool execution failed"
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+// toolResultParts returns the non-empty formatted content items of a tool result
+func toolResultParts(callResult mcp.CallToolResult) []string {
+	var parts []string
+	for _, content := range callResult.Content {
+		if part := formatToolResultContent(content); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// formatToolResultContent formats a single content item of a tool result for the model.
+// Binary content is not sent to the model, only a note that it was omitted.
+func formatToolResultContent(content mcp.Content) string {
+	switch c := content.(type) {
+	case *mcp.TextContent:
+		return c.Text
+	case *mcp.ImageContent:
+		return fmt.Sprintf("[image omitted: %s]", c.MIMEType)
+	case *mcp.AudioContent:
+		return fmt.Sprintf("[audio omitted: %s]", c.MIMEType)
+	case *mcp.ResourceLink:
+		return fmt.Sprintf("[resource: %s]", c.URI)
+	case *mcp.EmbeddedResource:
+		if c.Resource == nil {
+			return "[resource]"
+		}
+		if c.Resource.Text != "" {
+			return fmt.Sprintf("[resource: %s]\n%s", c.Resource.URI, c.Resource.Text)
 		}
+		return fmt.Sprintf("[resource: %s]", c.Resource.URI)
+	default:
+		return "[content omitted]"
+	}
+}
+
+// toolResultExitCode returns the non-zero exit code reported in a tool result's structured
+// content, as orla tools without an output schema do
+func toolResultExitCode(structuredContent any) (int, bool) {
+	structured, ok := structuredContent.(map[string]any)
+	if !ok {
+		return 0, false
+	}
+
+	var exitCode int
+	switch code := structured["exit_code"].(type) {
+	case int:
+		exitCode = code
+	case float64:
+		exitCode = int(code)
+	default:
+		return 0, false
 	}
 
-	return text
+	return exitCode, exitCode != 0
 }
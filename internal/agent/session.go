@@ -0,0 +1,278 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/model"
+	"github.com/dorcha-inc/orla/internal/registry"
+)
+
+const (
+	// DefaultSessionName is the session used by orla chat when --session is not given
+	DefaultSessionName = "default"
+
+	sessionFileExt = ".json"
+	sessionLockExt = ".lock"
+
+	// sessionLockTimeout is how long to wait for another process to release a session
+	sessionLockTimeout = 5 * time.Second
+	// sessionLockRetryInterval is how often a held session lock is retried
+	sessionLockRetryInterval = 20 * time.Millisecond
+	// sessionLockStaleAfter is the age after which a session lock is considered abandoned.
+	// Locks are only held while a session file is read and written, so this is generous.
+	sessionLockStaleAfter = 30 * time.Second
+)
+
+// sessionNamePattern matches valid session names, which are also used as file names
+var sessionNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// Session is a named, persisted chat conversation
+type Session struct {
+	Name      string          `json:"name"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Messages  []model.Message `json:"messages"`
+}
+
+// SessionInfo summarizes a stored session
+type SessionInfo struct {
+	Name         string
+	MessageCount int
+	UpdatedAt    time.Time
+}
+
+// SessionStore stores chat sessions as JSON files in a directory. Every read-modify-write of a
+// session file happens under a lock file, so several orla processes can share a session: each
+// turn is appended to the latest saved history instead of overwriting it.
+type SessionStore struct {
+	dir string
+}
+
+// NewSessionStore creates a session store in dir
+func NewSessionStore(dir string) *SessionStore {
+	return &SessionStore{dir: dir}
+}
+
+// NewDefaultSessionStore creates a session store in the orla sessions directory
+func NewDefaultSessionStore() (*SessionStore, error) {
+	dir, err := registry.GetSessionsDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions directory: %w", err)
+	}
+	return NewSessionStore(dir), nil
+}
+
+// ValidateSessionName checks that name can be used as a session name
+func ValidateSessionName(name string) error {
+	if !sessionNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid session name %q: use up to 64 letters, digits, '.', '_', or '-', starting with a letter or digit", name)
+	}
+	return nil
+}
+
+// Load returns the named session, or a new empty session if it does not exist yet
+func (s *SessionStore) Load(name string) (*Session, error) {
+	if err := ValidateSessionName(name); err != nil {
+		return nil, err
+	}
+
+	unlock, err := s.lock(name)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return s.readLocked(name)
+}
+
+// Append appends messages to the named session, creating it if needed, and returns the session
+// as saved. The returned history includes turns appended by other processes.
+func (s *SessionStore) Append(name string, messages ...model.Message) (*Session, error) {
+	if err := ValidateSessionName(name); err != nil {
+		return nil, err
+	}
+
+	unlock, err := s.lock(name)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	session, err := s.readLocked(name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = now
+	}
+	session.UpdatedAt = now
+	session.Messages = append(session.Messages, messages...)
+
+	if err := s.writeLocked(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// List returns a summary of every stored session, most recently updated first
+func (s *SessionStore) List() ([]SessionInfo, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read sessions directory: %w", err)
+	}
+
+	var sessions []SessionInfo
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), sessionFileExt)
+		if entry.IsDir() || !ok || ValidateSessionName(name) != nil {
+			continue
+		}
+
+		session, err := s.Load(name)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, SessionInfo{
+			Name:         session.Name,
+			MessageCount: len(session.Messages),
+			UpdatedAt:    session.UpdatedAt,
+		})
+	}
+
+	slices.SortFunc(sessions, func(a, b SessionInfo) int {
+		if c := b.UpdatedAt.Compare(a.UpdatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return sessions, nil
+}
+
+// Remove deletes the named session
+func (s *SessionStore) Remove(name string) error {
+	if err := ValidateSessionName(name); err != nil {
+		return err
+	}
+
+	unlock, err := s.lock(name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := os.Remove(s.sessionPath(name)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("session '%s' not found", name)
+		}
+		return fmt.Errorf("failed to remove session '%s': %w", name, err)
+	}
+	return nil
+}
+
+// sessionPath returns the path of the named session's file
+func (s *SessionStore) sessionPath(name string) string {
+	return filepath.Join(s.dir, name+sessionFileExt)
+}
+
+// readLocked reads the named session (assumes the session lock IS held)
+func (s *SessionStore) readLocked(name string) (*Session, error) {
+	data, err := os.ReadFile(s.sessionPath(name))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &Session{Name: name}, nil
+		}
+		return nil, fmt.Errorf("failed to read session '%s': %w", name, err)
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to parse session '%s': %w", name, err)
+	}
+	session.Name = name
+	return &session, nil
+}
+
+// writeLocked atomically writes a session file (assumes the session lock IS held)
+func (s *SessionStore) writeLocked(session *Session) error {
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session '%s': %w", session.Name, err)
+	}
+
+	tempFile, err := os.CreateTemp(s.dir, "."+session.Name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary session file: %w", err)
+	}
+	tempPath := tempFile.Name()
+	defer core.LogDeferredError(func() error {
+		if err := os.Remove(tempPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+
+	if _, err := tempFile.Write(data); err != nil {
+		core.LogDeferredError(tempFile.Close)
+		return fmt.Errorf("failed to write session '%s': %w", session.Name, err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("failed to write session '%s': %w", session.Name, err)
+	}
+
+	if err := os.Rename(tempPath, s.sessionPath(session.Name)); err != nil {
+		return fmt.Errorf("failed to save session '%s': %w", session.Name, err)
+	}
+	return nil
+}
+
+// lock acquires the lock file of the named session, waiting for other processes to release it.
+// Locks older than sessionLockStaleAfter were abandoned by a crashed process and are removed.
+func (s *SessionStore) lock(name string) (func(), error) {
+	// #nosec G301 -- sessions directory permissions 0700 keep chat history private
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+
+	lockPath := filepath.Join(s.dir, name+sessionLockExt)
+	deadline := time.Now().Add(sessionLockTimeout)
+	for {
+		// #nosec G304 -- lockPath is built from a validated session name
+		lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			core.LogDeferredError(lockFile.Close)
+			return func() {
+				core.LogDeferredError(func() error { return os.Remove(lockPath) })
+			}, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("failed to lock session '%s': %w", name, err)
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > sessionLockStaleAfter {
+			if removeErr := os.Remove(lockPath); removeErr != nil && !errors.Is(removeErr, fs.ErrNotExist) {
+				return nil, fmt.Errorf("failed to remove stale lock of session '%s': %w", name, removeErr)
+			}
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("session '%s' is locked by another orla process; if none is running, remove %s", name, lockPath)
+		}
+		time.Sleep(sessionLockRetryInterval)
+	}
+}
@@ -0,0 +1,139 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dorcha-inc/orla/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionStore_AppendAndLoad(t *testing.T) {
+	store := NewSessionStore(t.TempDir())
+
+	// A missing session loads as a new, empty session
+	session, err := store.Load("work")
+	require.NoError(t, err)
+	assert.Equal(t, "work", session.Name)
+	assert.Empty(t, session.Messages)
+
+	_, err = store.Append("work",
+		model.Message{Role: model.MessageRoleUser, Content: "hello"},
+		model.Message{Role: model.MessageRoleAssistant, Content: "hi"},
+	)
+	require.NoError(t, err)
+
+	// A new store on the same directory, as after a restart, resumes the history
+	resumed, err := NewSessionStore(store.dir).Load("work")
+	require.NoError(t, err)
+	require.Len(t, resumed.Messages, 2)
+	assert.Equal(t, "hello", resumed.Messages[0].Content)
+	assert.Equal(t, model.MessageRoleAssistant, resumed.Messages[1].Role)
+	assert.False(t, resumed.CreatedAt.IsZero())
+	assert.False(t, resumed.UpdatedAt.Before(resumed.CreatedAt))
+}
+
+func TestSessionStore_ListAndRemove(t *testing.T) {
+	store := NewSessionStore(t.TempDir())
+
+	sessions, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	_, err = store.Append("older", model.Message{Role: model.MessageRoleUser, Content: "1"})
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	_, err = store.Append("newer",
+		model.Message{Role: model.MessageRoleUser, Content: "1"},
+		model.Message{Role: model.MessageRoleAssistant, Content: "2"},
+	)
+	require.NoError(t, err)
+
+	sessions, err = store.List()
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "newer", sessions[0].Name)
+	assert.Equal(t, 2, sessions[0].MessageCount)
+	assert.Equal(t, "older", sessions[1].Name)
+	assert.Equal(t, 1, sessions[1].MessageCount)
+
+	require.NoError(t, store.Remove("older"))
+	sessions, err = store.List()
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "newer", sessions[0].Name)
+
+	err = store.Remove("older")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "session 'older' not found")
+}
+
+func TestSessionStore_ConcurrentAppends(t *testing.T) {
+	dir := t.TempDir()
+
+	const writers = 8
+	const turns = 5
+
+	var wg sync.WaitGroup
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Each writer has its own store, like separate orla processes
+			store := NewSessionStore(dir)
+			for turn := range turns {
+				_, err := store.Append("shared", model.Message{
+					Role:    model.MessageRoleUser,
+					Content: fmt.Sprintf("writer %d turn %d", w, turn),
+				})
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	session, err := NewSessionStore(dir).Load("shared")
+	require.NoError(t, err)
+	assert.Len(t, session.Messages, writers*turns, "no turn should be lost to a concurrent write")
+
+	// No lock or temporary files are left behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "shared.json", entries[0].Name())
+}
+
+func TestSessionStore_StaleLock(t *testing.T) {
+	store := NewSessionStore(t.TempDir())
+	// #nosec G301 -- test directory permissions are acceptable for temporary test files
+	require.NoError(t, os.MkdirAll(store.dir, 0755))
+
+	// A lock left behind by a crashed process
+	lockPath := filepath.Join(store.dir, "work"+sessionLockExt)
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(lockPath, nil, 0644))
+	old := time.Now().Add(-2 * sessionLockStaleAfter)
+	require.NoError(t, os.Chtimes(lockPath, old, old))
+
+	_, err := store.Append("work", model.Message{Role: model.MessageRoleUser, Content: "hello"})
+	require.NoError(t, err)
+}
+
+func TestValidateSessionName(t *testing.T) {
+	for _, name := range []string{"work", "default", "project-x", "v1.2_notes", "A1"} {
+		assert.NoError(t, ValidateSessionName(name), name)
+	}
+	for _, name := range []string{"", ".hidden", "-flag", "../escape", "a/b", "with space", string(make([]byte, 65))} {
+		assert.Error(t, ValidateSessionName(name), name)
+	}
+
+	store := NewSessionStore(t.TempDir())
+	_, err := store.Load("../escape")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid session name")
+}
@@ -0,0 +1,45 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// ParseTemplateVars converts KEY=VALUE arguments into prompt template variables. Values are
+// used as-is, and a later value for the same key replaces an earlier one.
+func ParseTemplateVars(args []string) (map[string]string, error) {
+	vars := make(map[string]string, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid variable %q: expected KEY=VALUE", arg)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+// RenderPromptTemplate renders the Go text/template in the file at path with the given variables,
+// which templates reference as {{.name}}. Referencing a variable that was not given is an error.
+func RenderPromptTemplate(path string, vars map[string]string) (string, error) {
+	// #nosec G304 -- path is the prompt template given on the command line
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt template: %w", err)
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse prompt template %s: %w", path, err)
+	}
+
+	var prompt strings.Builder
+	if err := tmpl.Execute(&prompt, vars); err != nil {
+		return "", fmt.Errorf("failed to render prompt template %s (set variables with --var KEY=VALUE): %w", path, err)
+	}
+
+	return strings.TrimSpace(prompt.String()), nil
+}
@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePromptTemplate writes a prompt template to a temporary file and returns its path
+func writePromptTemplate(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "prompt.tmpl")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestParseTemplateVars(t *testing.T) {
+	vars, err := ParseTemplateVars([]string{"file=main.go", "query=a=b", "empty=", "file=util.go"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"file": "util.go", "query": "a=b", "empty": ""}, vars)
+
+	_, err = ParseTemplateVars([]string{"novalue"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected KEY=VALUE")
+
+	_, err = ParseTemplateVars([]string{"=value"})
+	require.Error(t, err)
+}
+
+func TestRenderPromptTemplate(t *testing.T) {
+	path := writePromptTemplate(t, "Review {{.file}} for {{.focus}}.\n{{if .notes}}Notes: {{.notes}}{{end}}\n")
+
+	prompt, err := RenderPromptTemplate(path, map[string]string{"file": "main.go", "focus": "error handling", "notes": ""})
+	require.NoError(t, err)
+	assert.Equal(t, "Review main.go for error handling.", prompt)
+
+	prompt, err = RenderPromptTemplate(path, map[string]string{"file": "main.go", "focus": "naming", "notes": "be brief"})
+	require.NoError(t, err)
+	assert.Equal(t, "Review main.go for naming.\nNotes: be brief", prompt)
+}
+
+func TestRenderPromptTemplate_MissingVariable(t *testing.T) {
+	path := writePromptTemplate(t, "Review {{.file}} for {{.focus}}.")
+
+	_, err := RenderPromptTemplate(path, map[string]string{"file": "main.go"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"focus"`)
+	assert.Contains(t, err.Error(), "--var")
+}
+
+func TestRenderPromptTemplate_Errors(t *testing.T) {
+	_, err := RenderPromptTemplate(filepath.Join(t.TempDir(), "missing.tmpl"), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read prompt template")
+
+	_, err = RenderPromptTemplate(writePromptTemplate(t, "Review {{.file"), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse prompt template")
+}
@@ -4,8 +4,10 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -21,8 +23,29 @@ const (
 	DefaultToolsDir     = ".orla/tools"
 	DefaultModel        = "ollama:qwen3:0.6b"
 	DefaultMaxToolCalls = 10
+
+	DefaultMaxParallelToolCalls = 1
+
+	DefaultModelMaxRetries     = 2
+	DefaultModelRetryBackoffMs = 500
+
+	DefaultResponseCacheTTL        = 24 * 60 * 60 // one day, in seconds
+	DefaultResponseCacheMaxEntries = 256
+
+	DefaultMaxConcurrentClones = 4
+
+	DefaultCapsuleDrainTimeout = 10 // seconds
+
+	DefaultMaxOutputBytes = 10 << 20 // 10 MiB
+
+	DefaultMetricsPath = "/metrics"
 )
 
+// DefaultAuditRedactKeys returns the argument names whose values are masked in the audit log by default
+func DefaultAuditRedactKeys() []string {
+	return []string{"password", "passwd", "secret", "token", "api_key", "apikey", "authorization"}
+}
+
 type OrlaLogLevel string
 
 const (
@@ -71,6 +94,48 @@ func IsValidOutputFormat(format OrlaOutputFormat) bool {
 	return ok
 }
 
+// OrlaHTTPTransport selects the MCP endpoints served over HTTP
+type OrlaHTTPTransport string
+
+const (
+	// OrlaHTTPTransportHTTP serves plain HTTP, answering each request with a JSON response
+	OrlaHTTPTransportHTTP OrlaHTTPTransport = "http"
+	// OrlaHTTPTransportStreamable serves the MCP Streamable HTTP transport, answering with SSE streams
+	OrlaHTTPTransportStreamable OrlaHTTPTransport = "streamable"
+	// OrlaHTTPTransportBoth serves both transports, each on its own path
+	OrlaHTTPTransportBoth OrlaHTTPTransport = "both"
+)
+
+func ValidHTTPTransports() map[OrlaHTTPTransport]struct{} {
+	return map[OrlaHTTPTransport]struct{}{
+		OrlaHTTPTransportHTTP:       {},
+		OrlaHTTPTransportStreamable: {},
+		OrlaHTTPTransportBoth:       {},
+	}
+}
+
+func IsValidHTTPTransport(transport OrlaHTTPTransport) bool {
+	_, ok := ValidHTTPTransports()[transport]
+	return ok
+}
+
+// ModelFormatJSON is the model_format value that constrains responses to any valid JSON
+const ModelFormatJSON = "json"
+
+// ValidateModelFormat checks a model_format value: empty (unconstrained), "json", or a JSON
+// schema object that responses must conform to
+func ValidateModelFormat(format string) error {
+	if format == "" || format == ModelFormatJSON {
+		return nil
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(format), &schema); err != nil {
+		return fmt.Errorf("must be %q or a JSON schema object, got %q", ModelFormatJSON, format)
+	}
+	return nil
+}
+
 type OrlaLogFormat string
 
 const (
@@ -96,24 +161,71 @@ func IsValidLogFormat(format OrlaLogFormat) bool {
 // It also includes Agent Mode configuration (RFC 4).
 type OrlaConfig struct {
 	// Server mode configuration (RFC 1)
-	ToolsDir      string               `yaml:"tools_dir,omitempty" mapstructure:"tools_dir"`           // the directory containing the tools
-	ToolsRegistry *state.ToolsRegistry `yaml:"tools_registry,omitempty" mapstructure:"tools_registry"` // the tools registry
-	Port          int                  `yaml:"port,omitempty" mapstructure:"port"`                     // the port to listen on
-	Timeout       int                  `yaml:"timeout,omitempty" mapstructure:"timeout"`               // the timeout for tool executions in seconds
-	LogFormat     OrlaLogFormat        `yaml:"log_format,omitempty" mapstructure:"log_format"`         // the log format, "pretty" or "json"
-	LogLevel      string               `yaml:"log_level,omitempty" mapstructure:"log_level"`           // the log level, "debug", "info", "warn", "error", "fatal"
-	LogFile       string               `yaml:"log_file,omitempty" mapstructure:"log_file"`             // optional log file path
+	ToolsDir            string               `yaml:"tools_dir,omitempty" mapstructure:"tools_dir"`                         // the directory containing the tools
+	ToolsRegistry       *state.ToolsRegistry `yaml:"tools_registry,omitempty" mapstructure:"tools_registry"`               // the tools registry
+	Port                int                  `yaml:"port,omitempty" mapstructure:"port"`                                   // the port to listen on
+	Timeout             int                  `yaml:"timeout,omitempty" mapstructure:"timeout"`                             // the timeout for tool executions in seconds
+	MaxOutputBytes      int64                `yaml:"max_output_bytes,omitempty" mapstructure:"max_output_bytes"`           // limit on each of stdout and stderr of a tool execution, 0 for no limit
+	LogFormat           OrlaLogFormat        `yaml:"log_format,omitempty" mapstructure:"log_format"`                       // the log format, "pretty" or "json"
+	LogLevel            string               `yaml:"log_level,omitempty" mapstructure:"log_level"`                         // the log level, "debug", "info", "warn", "error", "fatal"
+	LogFile             string               `yaml:"log_file,omitempty" mapstructure:"log_file"`                           // optional log file path
+	TraceTools          bool                 `yaml:"trace_tools,omitempty" mapstructure:"trace_tools"`                     // log the command line of every tool execution
+	HTTPTransport       OrlaHTTPTransport    `yaml:"http_transport,omitempty" mapstructure:"http_transport"`               // MCP endpoints served over HTTP: "http", "streamable", or "both"
+	HideDeprecatedTools bool                 `yaml:"hide_deprecated_tools,omitempty" mapstructure:"hide_deprecated_tools"` // do not register tools whose manifest sets stability: deprecated
+	CapsuleDrainTimeout int                  `yaml:"capsule_drain_timeout,omitempty" mapstructure:"capsule_drain_timeout"` // how long a capsule replaced on reload may finish its calls in flight, in seconds
+	MetricsEnabled      bool                 `yaml:"metrics_enabled,omitempty" mapstructure:"metrics_enabled"`             // serve Prometheus metrics in HTTP mode
+	MetricsPath         string               `yaml:"metrics_path,omitempty" mapstructure:"metrics_path"`                   // HTTP path of the metrics endpoint
+	AdminEnabled        bool                 `yaml:"admin_enabled,omitempty" mapstructure:"admin_enabled"`                 // serve the admin and capabilities endpoints in HTTP mode
+	AdminToken          string               `yaml:"admin_token,omitempty" mapstructure:"admin_token"`                     // bearer token the admin endpoints require, loopback clients only if empty
+	AuditLogPath        string               `yaml:"audit_log_path,omitempty" mapstructure:"audit_log_path"`               // append a JSON line for every tool call to this file, separate from the log
+	AuditRedactKeys     []string             `yaml:"audit_redact_keys,omitempty" mapstructure:"audit_redact_keys"`         // argument names whose values are masked in the audit log (case-insensitive)
+	WatchFiles          bool                 `yaml:"watch_files,omitempty" mapstructure:"watch_files"`                     // reload the config and tools when their files change
+	ToolAllowlist       []string             `yaml:"tool_allowlist,omitempty" mapstructure:"tool_allowlist"`               // glob patterns of the tool names to serve, all tools if empty
+	ToolDenylist        []string             `yaml:"tool_denylist,omitempty" mapstructure:"tool_denylist"`                 // glob patterns of tool names not to serve, even if they match the allowlist
+	ToolWorkingDir      string               `yaml:"tool_working_dir,omitempty" mapstructure:"tool_working_dir"`           // directory tools run in unless their manifest sets working_dir
+
+	// Tool registry configuration
+	DefaultRegistry     string `yaml:"default_registry,omitempty" mapstructure:"default_registry"`           // registry URL used by install/search/update when --registry is not given
+	MaxConcurrentClones int    `yaml:"max_concurrent_clones,omitempty" mapstructure:"max_concurrent_clones"` // maximum number of tool repositories cloned at once when installing several tools
+	Offline             bool   `yaml:"offline,omitempty" mapstructure:"offline"`                             // use only cached registries and tool versions, never the network
 
 	// Agent mode configuration (RFC 4)
-	Model              string           `yaml:"model,omitempty" mapstructure:"model"`                             // model identifier (e.g., "ollama:ministral-3:8b", "openai:gpt-4")
-	MaxToolCalls       int              `yaml:"max_tool_calls,omitempty" mapstructure:"max_tool_calls"`           // maximum tool calls per prompt
-	Streaming          bool             `yaml:"streaming,omitempty" mapstructure:"streaming"`                     // enable streaming responses
-	OutputFormat       OrlaOutputFormat `yaml:"output_format,omitempty" mapstructure:"output_format"`             // output format: "auto", "rich", or "plain"
-	ConfirmDestructive bool             `yaml:"confirm_destructive,omitempty" mapstructure:"confirm_destructive"` // prompt for destructive actions
-	DryRun             bool             `yaml:"dry_run,omitempty" mapstructure:"dry_run"`                         // default to non-dry-run mode
-	ShowThinking       bool             `yaml:"show_thinking,omitempty" mapstructure:"show_thinking"`             // show thinking trace output (for thinking-capable models)
-	ShowToolCalls      bool             `yaml:"show_tool_calls,omitempty" mapstructure:"show_tool_calls"`         // show detailed tool call information
-	ShowProgress       bool             `yaml:"show_progress,omitempty" mapstructure:"show_progress"`             // show progress messages even when UI is disabled (e.g., when stdin is piped)
+	Model                string           `yaml:"model,omitempty" mapstructure:"model"`                                     // model identifier (e.g., "ollama:ministral-3:8b", "openai:gpt-4")
+	AutoPullModel        bool             `yaml:"auto_pull_model,omitempty" mapstructure:"auto_pull_model"`                 // pull the model into Ollama if it is missing
+	ModelTemperature     *float64         `yaml:"model_temperature,omitempty" mapstructure:"model_temperature"`             // sampling temperature (provider default if unset)
+	ModelSeed            *int             `yaml:"model_seed,omitempty" mapstructure:"model_seed"`                           // fixed sampling seed for reproducible responses
+	ModelFormat          string           `yaml:"model_format,omitempty" mapstructure:"model_format"`                       // constrain responses to "json" or to a JSON schema (Ollama)
+	Stop                 []string         `yaml:"stop,omitempty" mapstructure:"stop"`                                       // stop sequences that end the model's response
+	ModelMaxRetries      int              `yaml:"model_max_retries,omitempty" mapstructure:"model_max_retries"`             // retries of a model request that failed with a transient error (Ollama)
+	ModelRetryBackoffMs  int              `yaml:"model_retry_backoff_ms,omitempty" mapstructure:"model_retry_backoff_ms"`   // delay before the first model request retry, in milliseconds, doubled for each later retry
+	MaxToolCalls         int              `yaml:"max_tool_calls,omitempty" mapstructure:"max_tool_calls"`                   // maximum tool calls per prompt
+	MaxParallelToolCalls int              `yaml:"max_parallel_tool_calls,omitempty" mapstructure:"max_parallel_tool_calls"` // maximum tool calls of one model turn run at once
+	FailOnToolError      bool             `yaml:"-" mapstructure:"-"`                                                       // abort the agent run on the first failed tool call, set only by orla agent --fail-on-error
+	RunDeadline          int              `yaml:"run_deadline,omitempty" mapstructure:"run_deadline"`                       // time limit for a whole agent run in seconds, 0 for none
+	SystemPrompt         string           `yaml:"system_prompt,omitempty" mapstructure:"system_prompt"`                     // system message sent before the conversation, e.g. to set a persona or describe tools
+	PromptPrefix         string           `yaml:"prompt_prefix,omitempty" mapstructure:"prompt_prefix"`                     // text placed before each user prompt sent to the model
+	PromptSuffix         string           `yaml:"prompt_suffix,omitempty" mapstructure:"prompt_suffix"`                     // text placed after each user prompt sent to the model
+	Streaming            bool             `yaml:"streaming,omitempty" mapstructure:"streaming"`                             // enable streaming responses
+	OutputFormat         OrlaOutputFormat `yaml:"output_format,omitempty" mapstructure:"output_format"`                     // output format: "auto", "rich", or "plain"
+	ConfirmDestructive   bool             `yaml:"confirm_destructive,omitempty" mapstructure:"confirm_destructive"`         // prompt for destructive actions
+	DryRun               bool             `yaml:"dry_run,omitempty" mapstructure:"dry_run"`                                 // describe tool calls instead of executing them
+	ShowThinking         bool             `yaml:"show_thinking,omitempty" mapstructure:"show_thinking"`                     // show thinking trace output (for thinking-capable models)
+	KeepThinking         bool             `yaml:"keep_thinking,omitempty" mapstructure:"keep_thinking"`                     // keep thinking traces in the conversation history sent back to the model
+	ShowToolCalls        bool             `yaml:"show_tool_calls,omitempty" mapstructure:"show_tool_calls"`                 // show detailed tool call information
+	ShowProgress         bool             `yaml:"show_progress,omitempty" mapstructure:"show_progress"`                     // show progress messages even when UI is disabled (e.g., when stdin is piped)
+
+	// Model response cache configuration
+	ResponseCache           bool `yaml:"response_cache,omitempty" mapstructure:"response_cache"`                         // cache responses to deterministic model requests
+	ResponseCacheTTL        int  `yaml:"response_cache_ttl,omitempty" mapstructure:"response_cache_ttl"`                 // how long cached responses are reused, in seconds
+	ResponseCacheMaxEntries int  `yaml:"response_cache_max_entries,omitempty" mapstructure:"response_cache_max_entries"` // maximum number of cached responses
+
+	// Profiles are named sets of settings, such as dev and prod, merged over the rest of the config
+	// when selected with --profile or ORLA_PROFILE (see profile.go)
+	Profiles map[string]map[string]any `yaml:"profiles,omitempty" mapstructure:"profiles"`
+
+	// ToolOverrides override settings of tools' manifests, keyed by tool name, e.g. to disable an
+	// installed tool with orla disable without editing its tool.yaml (see tool_overrides.go)
+	ToolOverrides map[string]ToolOverride `yaml:"tool_overrides,omitempty" mapstructure:"tool_overrides"`
 }
 
 // SetToolsDir updates the tools directory and rebuilds the tools registry.
@@ -173,8 +285,9 @@ func (cfg *OrlaConfig) rebuildToolsRegistry() error {
 
 // ConfigValue represents a configuration value with its source
 type ConfigValue struct {
-	Value  any
-	Source string // "env", "project", "user", or "default"
+	Value   any
+	Source  string // "env", "profile", "project", "user", or "default"
+	Profile string // the profile the value came from, when Source is "profile"
 }
 
 // GetUserConfigPath returns the path to the user-specific config file (~/.orla/config.yaml)
@@ -246,19 +359,52 @@ func setViperDefaults() {
 	viper.SetDefault("log_format", "json")
 	viper.SetDefault("log_level", "info")
 	viper.SetDefault("log_file", "")
+	viper.SetDefault("trace_tools", false)
+	viper.SetDefault("http_transport", string(OrlaHTTPTransportBoth))
+	viper.SetDefault("hide_deprecated_tools", false)
+	viper.SetDefault("capsule_drain_timeout", DefaultCapsuleDrainTimeout)
+	viper.SetDefault("max_output_bytes", DefaultMaxOutputBytes)
+	viper.SetDefault("metrics_enabled", false)
+	viper.SetDefault("metrics_path", DefaultMetricsPath)
+	viper.SetDefault("admin_enabled", false)
+	viper.SetDefault("admin_token", "")
+	viper.SetDefault("audit_log_path", "")
+	viper.SetDefault("audit_redact_keys", DefaultAuditRedactKeys())
+	viper.SetDefault("watch_files", false)
+	viper.SetDefault("tool_allowlist", []string{})
+	viper.SetDefault("tool_denylist", []string{})
+	viper.SetDefault("tool_working_dir", "")
+	viper.SetDefault("default_registry", registry.DefaultRegistryURL)
+	viper.SetDefault("max_concurrent_clones", DefaultMaxConcurrentClones)
+	viper.SetDefault("offline", false)
 
 	// Agent mode defaults
 	viper.SetDefault("model", DefaultModel)
 	viper.SetDefault("auto_start_ollama", true)
 	viper.SetDefault("auto_configure_ollama_service", false)
+	viper.SetDefault("auto_pull_model", false)
+	viper.SetDefault("model_format", "")
+	viper.SetDefault("model_max_retries", DefaultModelMaxRetries)
+	viper.SetDefault("model_retry_backoff_ms", DefaultModelRetryBackoffMs)
 	viper.SetDefault("max_tool_calls", DefaultMaxToolCalls)
+	viper.SetDefault("max_parallel_tool_calls", DefaultMaxParallelToolCalls)
+	viper.SetDefault("run_deadline", 0)
+	viper.SetDefault("system_prompt", "")
+	viper.SetDefault("prompt_prefix", "")
+	viper.SetDefault("prompt_suffix", "")
 	viper.SetDefault("streaming", true)
 	viper.SetDefault("output_format", "auto")
 	viper.SetDefault("confirm_destructive", true)
 	viper.SetDefault("dry_run", false)
 	viper.SetDefault("show_thinking", false)
+	viper.SetDefault("keep_thinking", false)
 	viper.SetDefault("show_tool_calls", false)
 	viper.SetDefault("show_progress", false)
+
+	// Model response cache defaults
+	viper.SetDefault("response_cache", false)
+	viper.SetDefault("response_cache_ttl", DefaultResponseCacheTTL)
+	viper.SetDefault("response_cache_max_entries", DefaultResponseCacheMaxEntries)
 }
 
 // LoadConfig loads configuration with precedence: project config > user config > defaults
@@ -268,6 +414,9 @@ func LoadConfig(configPath string) (*OrlaConfig, error) {
 	if err := setupViper(configPath); err != nil {
 		return nil, err
 	}
+	if err := applyProfile(); err != nil {
+		return nil, err
+	}
 
 	// Unmarshal from Viper
 	cfg := &OrlaConfig{}
@@ -276,19 +425,30 @@ func LoadConfig(configPath string) (*OrlaConfig, error) {
 	}
 
 	// Post-process: handle ToolsRegistry and tools directory
-	var configFileDir string
-	if configPath != "" {
-		configFileDir = filepath.Dir(configPath)
-	} else {
+	configFile := configPath
+	if configFile == "" {
 		// Check if project config exists (for determining default tools_dir)
 		projectPath, err := GetProjectConfigPath()
 		if err == nil {
 			if _, err := os.Stat(projectPath); err == nil {
-				configFileDir = filepath.Dir(projectPath)
+				configFile = projectPath
 			}
 		}
 	}
 
+	var configFileDir string
+	if configFile != "" {
+		configFileDir = filepath.Dir(configFile)
+
+		toolsRegistry, err := readToolsRegistry(configFile)
+		if err != nil {
+			return nil, err
+		}
+		if toolsRegistry != nil {
+			cfg.ToolsRegistry = toolsRegistry
+		}
+	}
+
 	if err := postProcessConfig(cfg, configFileDir); err != nil {
 		return nil, err
 	}
@@ -301,17 +461,80 @@ func LoadConfig(configPath string) (*OrlaConfig, error) {
 	return cfg, nil
 }
 
+// readToolsRegistry reads the tools_registry of a config file, or returns nil if the file does not
+// set one. Viper lower-cases keys and ignores yaml tags, which would drop manifest fields such as
+// mcp.input_schema and change the property names of schemas, so the tools are decoded from the
+// file directly.
+func readToolsRegistry(configFile string) (*state.ToolsRegistry, error) {
+	// #nosec G304 -- the config file path is chosen by the user
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var file struct {
+		ToolsRegistry *state.ToolsRegistry `yaml:"tools_registry"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse tools_registry in %s: %w", configFile, err)
+	}
+	return file.ToolsRegistry, nil
+}
+
 // postProcessConfig handles ToolsRegistry resolution and tools directory setup
 func postProcessConfig(cfg *OrlaConfig, configFileDir string) error {
+	// Resolve a relative tool_working_dir relative to the config file, as tools_dir is
+	if cfg.ToolWorkingDir != "" && !filepath.IsAbs(cfg.ToolWorkingDir) {
+		absDir, err := filepath.Abs(filepath.Join(configFileDir, cfg.ToolWorkingDir))
+		if err != nil {
+			return fmt.Errorf("failed to resolve tool_working_dir: %w", err)
+		}
+		cfg.ToolWorkingDir = absDir
+	}
+
 	// Handle ToolsRegistry special case: if tools_registry is explicitly set in config, use it
 	// Check if tools_registry was set in the config (not just default empty value)
-	if cfg.ToolsRegistry != nil && len(cfg.ToolsRegistry.Tools) > 0 {
+	if cfg.ToolsRegistry != nil && cfg.ToolsRegistry.Len() > 0 {
 		if configFileDir == "" {
 			return fmt.Errorf("config file directory is not set but ToolsRegistry is set")
 		}
 
 		// Resolve relative paths in ToolsRegistry relative to config file
-		for _, tool := range cfg.ToolsRegistry.Tools {
+		for _, tool := range cfg.ToolsRegistry.ListTools() {
+			if err := core.ValidateModeFields(tool); err != nil {
+				return fmt.Errorf("tool '%s' in tools_registry: %w", tool.Name, err)
+			}
+			if err := core.ValidateStability(tool); err != nil {
+				return fmt.Errorf("tool '%s' in tools_registry: %w", tool.Name, err)
+			}
+			if err := core.ValidateArgStyle(tool); err != nil {
+				return fmt.Errorf("tool '%s' in tools_registry: %w", tool.Name, err)
+			}
+			if err := core.ValidateStdinFormat(tool); err != nil {
+				return fmt.Errorf("tool '%s' in tools_registry: %w", tool.Name, err)
+			}
+			if err := core.ValidateOutputJSONPath(tool); err != nil {
+				return fmt.Errorf("tool '%s' in tools_registry: %w", tool.Name, err)
+			}
+			if err := core.ResolveToolDirs(tool, configFileDir); err != nil {
+				return fmt.Errorf("tool '%s' in tools_registry: %w", tool.Name, err)
+			}
+
+			// Package tools run their package and have no file on disk
+			if core.IsPackageTool(tool) {
+				if err := validatePackageTool(tool); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// Virtual tools run an inline command and have no file on disk
+			if tool.Command != "" {
+				if err := validateVirtualTool(tool); err != nil {
+					return err
+				}
+				continue
+			}
 			if tool.Path != "" && !filepath.IsAbs(tool.Path) {
 				absPath, err := filepath.Abs(filepath.Join(configFileDir, tool.Path))
 				if err != nil {
@@ -375,6 +598,30 @@ func postProcessConfig(cfg *OrlaConfig, configFileDir string) error {
 	return nil
 }
 
+// validateVirtualTool checks that a tool defined by an inline command in tools_registry does not
+// also point at an entrypoint, and runs in simple mode
+func validateVirtualTool(tool *core.ToolManifest) error {
+	if tool.Path != "" || tool.Entrypoint != "" || tool.Interpreter != "" {
+		return fmt.Errorf("tool '%s' sets command, so it cannot also set path, entrypoint, or interpreter", tool.Name)
+	}
+	if tool.Runtime != nil && tool.Runtime.Mode != "" && tool.Runtime.Mode != core.RuntimeModeSimple {
+		return fmt.Errorf("tool '%s' sets command, so its runtime.mode must be %s, got %s", tool.Name, core.RuntimeModeSimple, tool.Runtime.Mode)
+	}
+	return nil
+}
+
+// validatePackageTool checks that a tool that runs a package in tools_registry names its package
+// and does not also point at a file or command
+func validatePackageTool(tool *core.ToolManifest) error {
+	if tool.Path != "" || tool.Entrypoint != "" || tool.Interpreter != "" || tool.Command != "" {
+		return fmt.Errorf("tool '%s' runs runtime.package, so it cannot also set path, entrypoint, interpreter, or command", tool.Name)
+	}
+	if err := core.ValidatePackage(tool); err != nil {
+		return fmt.Errorf("tool '%s' in tools_registry: %w", tool.Name, err)
+	}
+	return nil
+}
+
 // validateConfig validates the configuration
 // Note: This function can be called both:
 // 1. After LoadConfig() (viper is configured) - can use viper.IsSet() to detect explicit values
@@ -386,6 +633,34 @@ func validateConfig(cfg *OrlaConfig) error {
 	if cfg.Timeout < 1 {
 		return fmt.Errorf("timeout must be at least 1 second, got %d", cfg.Timeout)
 	}
+	if cfg.MaxOutputBytes < 0 {
+		return fmt.Errorf("max_output_bytes cannot be negative, got %d", cfg.MaxOutputBytes)
+	}
+	if cfg.CapsuleDrainTimeout < 0 {
+		return fmt.Errorf("capsule_drain_timeout cannot be negative, got %d", cfg.CapsuleDrainTimeout)
+	}
+	if cfg.MetricsPath != "" && !strings.HasPrefix(cfg.MetricsPath, "/") {
+		return fmt.Errorf("metrics_path must start with '/', got '%s'", cfg.MetricsPath)
+	}
+	for _, list := range []struct {
+		key      string
+		patterns []string
+	}{
+		{"tool_allowlist", cfg.ToolAllowlist},
+		{"tool_denylist", cfg.ToolDenylist},
+	} {
+		for _, pattern := range list.patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("%s has an invalid glob pattern '%s': %w", list.key, pattern, err)
+			}
+		}
+	}
+
+	if cfg.ToolWorkingDir != "" {
+		if err := core.CheckWorkingDir(cfg.ToolWorkingDir); err != nil {
+			return fmt.Errorf("tool_working_dir: %w", err)
+		}
+	}
 
 	if cfg.LogFormat != "" && !IsValidLogFormat(cfg.LogFormat) {
 		return fmt.Errorf("log_format must be one of: %s, got '%s'", core.JoinMapKeys(ValidLogFormats()), cfg.LogFormat)
@@ -394,6 +669,10 @@ func validateConfig(cfg *OrlaConfig) error {
 		return fmt.Errorf("log_level must be one of: %s, got '%s'", core.JoinMapKeys(ValidLogLevels()), cfg.LogLevel)
 	}
 
+	if cfg.HTTPTransport != "" && !IsValidHTTPTransport(cfg.HTTPTransport) {
+		return fmt.Errorf("http_transport must be one of: %s, got '%s'", core.JoinMapKeys(ValidHTTPTransports()), cfg.HTTPTransport)
+	}
+
 	// Since viper handles defaults, these are values that were explicitly set to empty or zero
 	// and need to be validated.
 	if cfg.Model == "" {
@@ -404,10 +683,59 @@ func validateConfig(cfg *OrlaConfig) error {
 		return fmt.Errorf("max_tool_calls must be at least 1, got %d", cfg.MaxToolCalls)
 	}
 
+	if cfg.RunDeadline < 0 {
+		return fmt.Errorf("run_deadline cannot be negative, got %d", cfg.RunDeadline)
+	}
+
 	if !IsValidOutputFormat(cfg.OutputFormat) {
 		return fmt.Errorf("output_format must be one of: %s, got '%s'", core.JoinMapKeys(ValidOutputFormats()), cfg.OutputFormat)
 	}
 
+	if err := ValidateModelFormat(cfg.ModelFormat); err != nil {
+		return fmt.Errorf("model_format %w", err)
+	}
+
+	for _, stop := range cfg.Stop {
+		if stop == "" {
+			return fmt.Errorf("stop cannot contain an empty stop sequence")
+		}
+	}
+
+	if cfg.ModelMaxRetries < 0 {
+		return fmt.Errorf("model_max_retries cannot be negative, got %d", cfg.ModelMaxRetries)
+	}
+	if cfg.ModelRetryBackoffMs < 0 {
+		return fmt.Errorf("model_retry_backoff_ms cannot be negative, got %d", cfg.ModelRetryBackoffMs)
+	}
+
+	if cfg.ModelTemperature != nil && *cfg.ModelTemperature < 0 {
+		return fmt.Errorf("model_temperature cannot be negative, got %v", *cfg.ModelTemperature)
+	}
+
+	if cfg.ResponseCache {
+		if cfg.ResponseCacheTTL < 1 {
+			return fmt.Errorf("response_cache_ttl must be at least 1 second, got %d", cfg.ResponseCacheTTL)
+		}
+		if cfg.ResponseCacheMaxEntries < 1 {
+			return fmt.Errorf("response_cache_max_entries must be at least 1, got %d", cfg.ResponseCacheMaxEntries)
+		}
+	}
+
+	if cfg.MaxParallelToolCalls < 1 {
+		return fmt.Errorf("max_parallel_tool_calls must be at least 1, got %d", cfg.MaxParallelToolCalls)
+	}
+
+	if cfg.MaxConcurrentClones < 1 {
+		return fmt.Errorf("max_concurrent_clones must be at least 1, got %d", cfg.MaxConcurrentClones)
+	}
+
+	if cfg.DefaultRegistry == "" {
+		return fmt.Errorf("default_registry cannot be empty (was explicitly set to empty string)")
+	}
+	if err := registry.ValidateRegistryURL(cfg.DefaultRegistry); err != nil {
+		return fmt.Errorf("default_registry is not a valid registry URL: %w", err)
+	}
+
 	return nil
 }
 
@@ -419,6 +747,11 @@ func getValueSource(key string) string {
 		return "env"
 	}
 
+	// Check the active profile, which is merged over the config files
+	if profile := ActiveProfile(); profile != "" && viper.IsSet(profileKey(profile)+"."+key) {
+		return "profile"
+	}
+
 	// Check project config
 	projectPath, err := GetProjectConfigPath()
 	if err == nil {
@@ -455,11 +788,14 @@ func getValueSource(key string) string {
 }
 
 // GetConfigValue retrieves a configuration value by key, checking environment variables first
-// Returns the value and its source ("env", "project", "user", or "default")
+// Returns the value and its source ("env", "profile", "project", "user", or "default")
 func GetConfigValue(key string) (*ConfigValue, error) {
 	if err := setupViper(""); err != nil {
 		return nil, err
 	}
+	if err := applyProfile(); err != nil {
+		return nil, err
+	}
 
 	// Viper handles defaults, so Get will return default if not set
 	value := viper.Get(key)
@@ -467,36 +803,43 @@ func GetConfigValue(key string) (*ConfigValue, error) {
 		return nil, fmt.Errorf("unknown config key: %s", key)
 	}
 
-	source := getValueSource(key)
-	return &ConfigValue{Value: value, Source: source}, nil
+	configValue := &ConfigValue{Value: value, Source: getValueSource(key)}
+	if configValue.Source == "profile" {
+		configValue.Profile = ActiveProfile()
+	}
+	return configValue, nil
 }
 
-// SetConfigValue sets a configuration value and saves it to the appropriate config file
-func SetConfigValue(key, value string) error {
-	// Determine which config file to update
+// GetWritableConfigPath returns the config file that changes are saved to: the project config if
+// it exists, otherwise the user config, whose directory is created if needed
+func GetWritableConfigPath() (string, error) {
 	projectPath, projectErr := GetProjectConfigPath()
-	var configPath string
-
 	if projectErr == nil {
 		if _, projectStatErr := os.Stat(projectPath); projectStatErr == nil {
-			configPath = projectPath
+			return projectPath, nil
 		}
 	}
 
-	if configPath == "" {
-		// Use user config
-		userPath, userErr := GetUserConfigPath()
-		if userErr != nil {
-			return fmt.Errorf("failed to get user config path: %w", userErr)
-		}
-		// Ensure directory exists
-		configDir := filepath.Dir(userPath)
-		// #nosec G301 -- config directory permissions 0755 are acceptable for user config directory
-		if err := os.MkdirAll(configDir, 0755); err != nil {
-			return fmt.Errorf("failed to create config directory: %w", err)
-		}
+	// Use user config
+	userPath, userErr := GetUserConfigPath()
+	if userErr != nil {
+		return "", fmt.Errorf("failed to get user config path: %w", userErr)
+	}
+	// Ensure directory exists
+	configDir := filepath.Dir(userPath)
+	// #nosec G301 -- config directory permissions 0755 are acceptable for user config directory
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return userPath, nil
+}
 
-		configPath = userPath
+// SetConfigValue sets a configuration value and saves it to the appropriate config file
+func SetConfigValue(key, value string) error {
+	// Determine which config file to update
+	configPath, err := GetWritableConfigPath()
+	if err != nil {
+		return err
 	}
 
 	// Load existing config using Viper
@@ -539,6 +882,9 @@ func ListConfig() (map[string]*ConfigValue, error) {
 	if err := setupViper(""); err != nil {
 		return nil, err
 	}
+	if err := applyProfile(); err != nil {
+		return nil, err
+	}
 
 	result := make(map[string]*ConfigValue)
 
@@ -54,6 +54,15 @@ func TestLoadConfig_Defaults(t *testing.T) {
 
 	assert.Equal(t, 8080, cfg.Port)
 	assert.Equal(t, 30, cfg.Timeout)
+	assert.Equal(t, OrlaHTTPTransportBoth, cfg.HTTPTransport)
+	assert.False(t, cfg.HideDeprecatedTools)
+	assert.Equal(t, DefaultCapsuleDrainTimeout, cfg.CapsuleDrainTimeout)
+	assert.Equal(t, int64(DefaultMaxOutputBytes), cfg.MaxOutputBytes)
+	assert.False(t, cfg.MetricsEnabled)
+	assert.False(t, cfg.KeepThinking)
+	assert.Equal(t, DefaultMetricsPath, cfg.MetricsPath)
+	assert.Empty(t, cfg.AuditLogPath)
+	assert.Equal(t, DefaultAuditRedactKeys(), cfg.AuditRedactKeys)
 	// Note: LogFormat and LogLevel are empty strings by default in struct, but validateConfig sets defaults
 	// After validation, they should have defaults
 	assert.Equal(t, DefaultModel, cfg.Model)
@@ -64,6 +73,7 @@ func TestLoadConfig_Defaults(t *testing.T) {
 	// Note: ConfirmDestructive defaults to true in Viper, but struct default is false
 	// After unmarshaling, it should be true
 	assert.Equal(t, false, cfg.DryRun)
+	assert.Equal(t, registry.DefaultRegistryURL, cfg.DefaultRegistry)
 
 	// Without project config, tools_dir should default to ~/.orla/tools
 	// (not ./orla/tools relative to temp directory)
@@ -322,11 +332,14 @@ func TestListConfig(t *testing.T) {
 
 func TestValidateConfig(t *testing.T) {
 	cfg := &OrlaConfig{
-		Port:         8080,
-		Timeout:      30,
-		Model:        DefaultModel,
-		MaxToolCalls: DefaultMaxToolCalls,
-		OutputFormat: OrlaOutputFormatAuto,
+		Port:                 8080,
+		Timeout:              30,
+		Model:                DefaultModel,
+		MaxToolCalls:         DefaultMaxToolCalls,
+		OutputFormat:         OrlaOutputFormatAuto,
+		DefaultRegistry:      registry.DefaultRegistryURL,
+		MaxConcurrentClones:  DefaultMaxConcurrentClones,
+		MaxParallelToolCalls: DefaultMaxParallelToolCalls,
 	}
 
 	err := validateConfig(cfg)
@@ -347,8 +360,49 @@ func TestValidateConfig(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "timeout must be at least 1 second")
 
-	// Test invalid log format
+	// Test invalid capsule_drain_timeout
 	cfg.Timeout = 30
+	cfg.CapsuleDrainTimeout = -1
+	err = validateConfig(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "capsule_drain_timeout cannot be negative")
+
+	// Test invalid max_output_bytes
+	cfg.CapsuleDrainTimeout = 0
+	cfg.MaxOutputBytes = -1
+	err = validateConfig(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max_output_bytes cannot be negative")
+
+	// Test invalid metrics_path
+	cfg.MaxOutputBytes = 0
+	cfg.MetricsPath = "metrics"
+	err = validateConfig(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "metrics_path must start with '/'")
+
+	// Test invalid tool_allowlist and tool_denylist patterns
+	cfg.MetricsPath = DefaultMetricsPath
+	cfg.ToolAllowlist = []string{"fs-*", "http-["}
+	err = validateConfig(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tool_allowlist has an invalid glob pattern 'http-['")
+	cfg.ToolAllowlist = nil
+	cfg.ToolDenylist = []string{"[a-"}
+	err = validateConfig(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tool_denylist has an invalid glob pattern '[a-'")
+	cfg.ToolDenylist = nil
+
+	// Test missing tool_working_dir
+	cfg.ToolWorkingDir = filepath.Join(t.TempDir(), "missing")
+	err = validateConfig(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tool_working_dir: working directory")
+	assert.Contains(t, err.Error(), "does not exist")
+	cfg.ToolWorkingDir = ""
+
+	// Test invalid log format
 	cfg.LogFormat = invalidValue
 	err = validateConfig(cfg)
 	require.Error(t, err)
@@ -361,8 +415,15 @@ func TestValidateConfig(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "log_level must be one of")
 
-	// Test invalid max_tool_calls
+	// Test invalid http_transport
 	cfg.LogLevel = "info"
+	cfg.HTTPTransport = invalidValue
+	err = validateConfig(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "http_transport must be one of")
+
+	// Test invalid max_tool_calls
+	cfg.HTTPTransport = OrlaHTTPTransportBoth
 	cfg.MaxToolCalls = -1
 	err = validateConfig(cfg)
 	require.Error(t, err)
@@ -374,6 +435,192 @@ func TestValidateConfig(t *testing.T) {
 	err = validateConfig(cfg)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "output_format must be one of")
+
+	// Test invalid max_concurrent_clones
+	cfg.OutputFormat = OrlaOutputFormatAuto
+	cfg.MaxConcurrentClones = 0
+	err = validateConfig(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max_concurrent_clones must be at least 1")
+
+	// Test invalid max_parallel_tool_calls
+	cfg.MaxConcurrentClones = DefaultMaxConcurrentClones
+	cfg.MaxParallelToolCalls = 0
+	err = validateConfig(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max_parallel_tool_calls must be at least 1")
+
+	// Test invalid run_deadline
+	cfg.MaxParallelToolCalls = DefaultMaxParallelToolCalls
+	cfg.RunDeadline = -1
+	err = validateConfig(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "run_deadline cannot be negative")
+
+	// Test invalid default_registry
+	cfg.RunDeadline = 0
+	cfg.DefaultRegistry = "not-a-url"
+	err = validateConfig(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "default_registry is not a valid registry URL")
+}
+
+func TestLoadConfig_DefaultRegistry(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "orla.yaml")
+
+	// An explicit default_registry overrides the built-in default
+	configContent := "default_registry: https://example.com/my-registry\n"
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/my-registry", cfg.DefaultRegistry)
+
+	// Invalid registry URLs are rejected at load time
+	configContent = "default_registry: example.com/my-registry\n"
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+	_, err = LoadConfig(configPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "default_registry is not a valid registry URL")
+
+	// Explicitly empty default_registry is an error rather than a silent fallback
+	configContent = "default_registry: \"\"\n"
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+	_, err = LoadConfig(configPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "default_registry cannot be empty")
+}
+
+func TestLoadConfig_ResponseCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "orla.yaml")
+
+	// The cache is off by default, with default limits
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte("port: 8080\n"), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.False(t, cfg.ResponseCache)
+	assert.Equal(t, DefaultResponseCacheTTL, cfg.ResponseCacheTTL)
+	assert.Equal(t, DefaultResponseCacheMaxEntries, cfg.ResponseCacheMaxEntries)
+	assert.Nil(t, cfg.ModelTemperature)
+	assert.Nil(t, cfg.ModelSeed)
+
+	configContent := "response_cache: true\nresponse_cache_ttl: 60\nresponse_cache_max_entries: 8\nmodel_temperature: 0\nmodel_seed: 7\n"
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.True(t, cfg.ResponseCache)
+	assert.Equal(t, 60, cfg.ResponseCacheTTL)
+	assert.Equal(t, 8, cfg.ResponseCacheMaxEntries)
+	require.NotNil(t, cfg.ModelTemperature)
+	assert.Equal(t, 0.0, *cfg.ModelTemperature)
+	require.NotNil(t, cfg.ModelSeed)
+	assert.Equal(t, 7, *cfg.ModelSeed)
+
+	// Limits are validated when the cache is enabled
+	configContent = "response_cache: true\nresponse_cache_ttl: 0\n"
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+	_, err = LoadConfig(configPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "response_cache_ttl must be at least 1 second")
+
+	configContent = "response_cache: true\nresponse_cache_max_entries: -1\n"
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+	_, err = LoadConfig(configPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "response_cache_max_entries must be at least 1")
+
+	configContent = "model_temperature: -0.5\n"
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+	_, err = LoadConfig(configPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "model_temperature cannot be negative")
+
+	configContent = "model_format: '{\"type\": \"object\"}'\n"
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": "object"}`, cfg.ModelFormat)
+
+	configContent = "model_format: yaml\n"
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+	_, err = LoadConfig(configPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "model_format must be \"json\" or a JSON schema object")
+}
+
+func TestLoadConfig_ModelRetries(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "orla.yaml")
+
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte("port: 8080\n"), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultModelMaxRetries, cfg.ModelMaxRetries)
+	assert.Equal(t, DefaultModelRetryBackoffMs, cfg.ModelRetryBackoffMs)
+
+	// Retries can be turned off
+	configContent := "model_max_retries: 0\nmodel_retry_backoff_ms: 100\n"
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, 0, cfg.ModelMaxRetries)
+	assert.Equal(t, 100, cfg.ModelRetryBackoffMs)
+
+	configContent = "model_max_retries: -1\n"
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+	_, err = LoadConfig(configPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "model_max_retries cannot be negative")
+
+	configContent = "model_retry_backoff_ms: -5\n"
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+	_, err = LoadConfig(configPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "model_retry_backoff_ms cannot be negative")
+}
+
+func TestLoadConfig_Stop(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "orla.yaml")
+
+	configContent := "stop: [\"END\", \"\\nObservation:\"]\n"
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"END", "\nObservation:"}, cfg.Stop)
+
+	configContent = "stop: [\"\"]\n"
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+	_, err = LoadConfig(configPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stop cannot contain an empty stop sequence")
+}
+
+func TestValidateModelFormat(t *testing.T) {
+	assert.NoError(t, ValidateModelFormat(""))
+	assert.NoError(t, ValidateModelFormat(ModelFormatJSON))
+	assert.NoError(t, ValidateModelFormat(`{"type": "object", "properties": {"name": {"type": "string"}}}`))
+
+	assert.Error(t, ValidateModelFormat("xml"))
+	assert.Error(t, ValidateModelFormat(`["json"]`))
+	assert.Error(t, ValidateModelFormat(`{"type": `))
 }
 
 func TestPostProcessConfig_NoProjectConfig(t *testing.T) {
@@ -578,6 +825,154 @@ dry_run: true
 	assert.Equal(t, true, cfg.DryRun)
 }
 
+func TestLoadConfig_ToolsRegistryKeepsManifestFields(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "orla.yaml")
+	configContent := `
+tools_registry:
+  tools:
+    greet:
+      name: greet
+      description: Greets a user
+      path: ./greet.sh
+      runtime:
+        mode: capsule
+        startup_timeout_ms: 2500
+      mcp:
+        input_schema:
+          type: object
+          properties:
+            userName:
+              type: string
+`
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+
+	tool, err := cfg.ToolsRegistry.GetTool("greet")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpDir, "greet.sh"), tool.Path)
+	require.NotNil(t, tool.Runtime)
+	assert.Equal(t, 2500, tool.Runtime.StartupTimeoutMs)
+	require.NotNil(t, tool.MCP)
+	properties, ok := tool.MCP.InputSchema["properties"].(map[string]any)
+	require.True(t, ok)
+	assert.Contains(t, properties, "userName", "schema property names should keep their case")
+}
+
+func TestLoadConfig_VirtualTool(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "orla.yaml")
+	configContent := `
+tools_registry:
+  tools:
+    disk-usage:
+      name: disk-usage
+      description: Show disk usage of a directory
+      command: du -sh "$2"
+      mcp:
+        input_schema:
+          type: object
+          properties:
+            dir:
+              type: string
+          required: [dir]
+`
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+
+	tool, err := cfg.ToolsRegistry.GetTool("disk-usage")
+	require.NoError(t, err)
+	assert.Equal(t, `du -sh "$2"`, tool.Command)
+	assert.Empty(t, tool.Path, "virtual tools have no file on disk")
+	require.NotNil(t, tool.MCP)
+	assert.Equal(t, []any{"dir"}, tool.MCP.InputSchema["required"])
+}
+
+func TestPostProcessConfig_InvalidVirtualTool(t *testing.T) {
+	tests := []struct {
+		name     string
+		tool     *core.ToolManifest
+		expected string
+	}{
+		{
+			name:     "command with path",
+			tool:     &core.ToolManifest{Name: "tool1", Command: "echo hi", Path: "tool1.sh"},
+			expected: "tool 'tool1' sets command, so it cannot also set path, entrypoint, or interpreter",
+		},
+		{
+			name:     "command in capsule mode",
+			tool:     &core.ToolManifest{Name: "tool1", Command: "echo hi", Runtime: &core.RuntimeConfig{Mode: core.RuntimeModeCapsule}},
+			expected: "tool 'tool1' sets command, so its runtime.mode must be simple, got capsule",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &OrlaConfig{
+				ToolsRegistry: &state.ToolsRegistry{
+					Tools: map[string]*core.ToolManifest{tt.tool.Name: tt.tool},
+				},
+			}
+			err := postProcessConfig(cfg, t.TempDir())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestPostProcessConfig_ModeFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		tool     *core.ToolManifest
+		expected string
+	}{
+		{
+			name:     "startup timeout on simple tool",
+			tool:     &core.ToolManifest{Name: "tool1", Path: "tool1.sh", Runtime: &core.RuntimeConfig{StartupTimeoutMs: 2000}},
+			expected: "tool 'tool1' in tools_registry: invalid runtime.startup_timeout_ms: only applies to capsule mode tools, but runtime.mode is simple",
+		},
+		{
+			name: "pass meta on capsule tool",
+			tool: &core.ToolManifest{
+				Name:    "tool1",
+				Path:    "tool1.sh",
+				Runtime: &core.RuntimeConfig{Mode: core.RuntimeModeCapsule},
+				MCP:     &core.MCPConfig{PassMeta: []string{"trace-id"}},
+			},
+			expected: "tool 'tool1' in tools_registry: invalid mcp.pass_meta: only applies to simple, npx, or pipx mode tools, but runtime.mode is capsule",
+		},
+		{
+			name:     "package tool without package",
+			tool:     &core.ToolManifest{Name: "tool1", Runtime: &core.RuntimeConfig{Mode: core.RuntimeModeNpx}},
+			expected: "tool 'tool1' in tools_registry: invalid runtime.package: required for npx mode tools",
+		},
+		{
+			name:     "package tool with path",
+			tool:     &core.ToolManifest{Name: "tool1", Path: "tool1.sh", Runtime: &core.RuntimeConfig{Mode: core.RuntimeModePipx, Package: "black"}},
+			expected: "tool 'tool1' runs runtime.package, so it cannot also set path, entrypoint, interpreter, or command",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &OrlaConfig{
+				ToolsRegistry: &state.ToolsRegistry{
+					Tools: map[string]*core.ToolManifest{tt.tool.Name: tt.tool},
+				},
+			}
+			err := postProcessConfig(cfg, t.TempDir())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
 func TestSetConfigValue_ComplexValue(t *testing.T) {
 	_, cleanup := setupTestConfig(t)
 	defer cleanup()
@@ -830,3 +1225,16 @@ func TestLoadConfig_ExplicitEmptyValues(t *testing.T) {
 	assert.Equal(t, DefaultMaxToolCalls, cfg.MaxToolCalls)
 	assert.Equal(t, 9000, cfg.Port) // Explicitly set value should be used
 }
+
+func TestLoadConfig_ToolWorkingDirRelative(t *testing.T) {
+	tmpDir := t.TempDir()
+	// #nosec G301 -- test directory permissions are acceptable for temporary test files
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "work"), 0755))
+	configPath := filepath.Join(tmpDir, "orla.yaml")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte("tool_working_dir: ./work\n"), 0644))
+
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpDir, "work"), cfg.ToolWorkingDir)
+}
@@ -0,0 +1,259 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// yamlIndent is the indentation of config files that orla rewrites
+const yamlIndent = 2
+
+// UnsetConfigValue removes a configuration key from the config file that sets it, the project
+// config before the user config, so that the value falls back to the next source. Nested keys are
+// written with dots (e.g. profiles.dev.port). The config is validated without the key before the
+// file is changed.
+func UnsetConfigValue(key string) error {
+	if err := setupViper(""); err != nil {
+		return err
+	}
+	if viper.Get(key) == nil {
+		return fmt.Errorf("unknown config key: %s", key)
+	}
+
+	var configPaths []string
+	if projectPath, err := GetProjectConfigPath(); err == nil {
+		configPaths = append(configPaths, projectPath)
+	}
+	if userPath, err := GetUserConfigPath(); err == nil {
+		configPaths = append(configPaths, userPath)
+	}
+
+	for _, configPath := range configPaths {
+		// #nosec G304 -- the config file paths are orla's own
+		data, err := os.ReadFile(configPath)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		updated, removed, err := removeYAMLKey(data, key)
+		if err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+		}
+		if !removed {
+			continue
+		}
+		return replaceConfigFile(configPath, updated)
+	}
+
+	return fmt.Errorf("config key %s is not set in any config file", key)
+}
+
+// EditConfigFile lets the user edit the config file at configPath with runEditor, which is given
+// the path of a copy to edit. The edited copy replaces the config file only if it is valid; if it
+// is not, the copy is kept so that the changes are not lost, and its path is in the returned error.
+// It returns false if the file was not changed.
+func EditConfigFile(configPath string, runEditor func(path string) error) (bool, error) {
+	// #nosec G304 -- the config file path is chosen by the user
+	original, err := os.ReadFile(configPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// The copy is in the same directory, so that relative paths in it resolve the same way, and
+	// keeps the extension viper reads the format from
+	base := filepath.Base(configPath)
+	editFile, err := os.CreateTemp(filepath.Dir(configPath), "."+strings.TrimSuffix(base, filepath.Ext(base))+".*"+filepath.Ext(base))
+	if err != nil {
+		return false, fmt.Errorf("failed to create a copy of the config file to edit: %w", err)
+	}
+	editPath := editFile.Name()
+	_, writeErr := editFile.Write(original)
+	if closeErr := editFile.Close(); writeErr == nil {
+		writeErr = closeErr
+	}
+	if writeErr != nil {
+		_ = os.Remove(editPath)
+		return false, fmt.Errorf("failed to write a copy of the config file to edit: %w", writeErr)
+	}
+
+	if err := runEditor(editPath); err != nil {
+		_ = os.Remove(editPath)
+		return false, fmt.Errorf("editor failed: %w", err)
+	}
+
+	// #nosec G304 -- the edited copy was created above
+	edited, err := os.ReadFile(editPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read the edited config file: %w", err)
+	}
+	if bytes.Equal(edited, original) {
+		_ = os.Remove(editPath)
+		return false, nil
+	}
+
+	if _, err := LoadConfig(editPath); err != nil {
+		return false, fmt.Errorf("edited config is invalid, %s was not changed and the edit was saved to %s: %w", configPath, editPath, err)
+	}
+	if info, err := os.Stat(configPath); err == nil {
+		if err := os.Chmod(editPath, info.Mode().Perm()); err != nil {
+			return false, fmt.Errorf("failed to save config file: %w", err)
+		}
+	}
+	if err := os.Rename(editPath, configPath); err != nil {
+		return false, fmt.Errorf("failed to save config file: %w", err)
+	}
+	return true, nil
+}
+
+// replaceConfigFile writes data to the config file at configPath if the config is valid with it,
+// and leaves the file as it was if not. The file is created if it does not exist.
+func replaceConfigFile(configPath string, data []byte) error {
+	// #nosec G304 -- the config file paths are orla's own
+	original, err := os.ReadFile(configPath)
+	existed := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(configPath); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	if err := os.WriteFile(configPath, data, perm); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if _, err := LoadConfig(""); err != nil {
+		var restoreErr error
+		if existed {
+			restoreErr = os.WriteFile(configPath, original, perm)
+		} else {
+			restoreErr = os.Remove(configPath)
+		}
+		if restoreErr != nil {
+			return fmt.Errorf("config is invalid after the change (%w), and restoring %s failed: %w", err, configPath, restoreErr)
+		}
+		return fmt.Errorf("config would be invalid after the change, %s was not changed: %w", configPath, err)
+	}
+	return nil
+}
+
+// removeYAMLKey removes the dotted key from a YAML document, keeping the rest of the document
+// and its comments. Keys are matched case-insensitively, as viper does. It returns false if the
+// document does not set the key.
+func removeYAMLKey(data []byte, key string) ([]byte, bool, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, false, err
+	}
+	if len(doc.Content) == 0 {
+		return data, false, nil
+	}
+
+	node := doc.Content[0]
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		if node.Kind != yaml.MappingNode {
+			return data, false, nil
+		}
+		index := mappingKeyIndex(node, part)
+		if index < 0 {
+			return data, false, nil
+		}
+
+		if i == len(parts)-1 {
+			node.Content = slices.Delete(node.Content, index, index+2)
+			break
+		}
+		node = node.Content[index+1]
+	}
+
+	updated, err := encodeYAML(&doc)
+	if err != nil {
+		return nil, false, err
+	}
+	return updated, true, nil
+}
+
+// setYAMLKey sets the value at the path of keys in a YAML document, creating the mappings on the
+// way that do not exist, and keeping the rest of the document and its comments. Keys are matched
+// case-insensitively, as viper does.
+func setYAMLKey(data []byte, value *yaml.Node, keys ...string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{newYAMLMapping()}}
+	}
+
+	node := doc.Content[0]
+	for i, key := range keys {
+		if isYAMLNull(node) {
+			*node = *newYAMLMapping()
+		}
+		if node.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s is not a mapping", strings.Join(keys[:i], "."))
+		}
+
+		index := mappingKeyIndex(node, key)
+		if index < 0 {
+			child := newYAMLMapping()
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+			index = len(node.Content) - 2
+		}
+		if i == len(keys)-1 {
+			node.Content[index+1] = value
+			break
+		}
+		node = node.Content[index+1]
+	}
+
+	return encodeYAML(&doc)
+}
+
+// mappingKeyIndex returns the index in a mapping node's content of key, matched
+// case-insensitively, or -1 if the mapping does not have it. The content of a mapping node
+// alternates keys and values.
+func mappingKeyIndex(node *yaml.Node, key string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if strings.EqualFold(node.Content[i].Value, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// newYAMLMapping returns an empty block-style YAML mapping node
+func newYAMLMapping() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+}
+
+// isYAMLNull reports whether node is an empty or null value, e.g. of a key with nothing after it
+func isYAMLNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+// encodeYAML encodes a YAML document with the indentation of orla's config files
+func encodeYAML(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(yamlIndent)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
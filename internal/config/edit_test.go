@@ -0,0 +1,183 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/registry"
+)
+
+// setupEditTestConfig writes a project config with the given content in an empty orla home, and
+// changes to the project directory. It returns the project config path.
+func setupEditTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	t.Setenv(registry.OrlaHomeEnvVar, t.TempDir())
+	t.Setenv(ProfileEnvVar, "")
+
+	tmpDir, cleanup := setupTestConfig(t)
+	t.Cleanup(cleanup)
+	configPath := filepath.Join(tmpDir, "orla.yaml")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+	return configPath
+}
+
+func TestUnsetConfigValue_ProjectConfig(t *testing.T) {
+	configPath := setupEditTestConfig(t, "# project settings\n\nport: 9000\nmodel: openai:gpt-4\n")
+
+	portVal, err := GetConfigValue("port")
+	require.NoError(t, err)
+	assert.Equal(t, "project", portVal.Source)
+
+	require.NoError(t, UnsetConfigValue("port"))
+
+	portVal, err = GetConfigValue("port")
+	require.NoError(t, err)
+	assert.Equal(t, 8080, portVal.Value)
+	assert.Equal(t, "default", portVal.Source)
+
+	// The rest of the file is kept
+	// #nosec G304 -- test file inclusion via variable is acceptable for test files
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "port")
+	assert.Contains(t, string(data), "# project settings")
+	assert.Contains(t, string(data), "model: openai:gpt-4")
+}
+
+func TestUnsetConfigValue_UserConfig(t *testing.T) {
+	orlaHome := t.TempDir()
+	t.Setenv(registry.OrlaHomeEnvVar, orlaHome)
+	t.Chdir(t.TempDir())
+
+	userPath := filepath.Join(orlaHome, "config.yaml")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(userPath, []byte("timeout: 45\n"), 0644))
+
+	require.NoError(t, UnsetConfigValue("timeout"))
+
+	timeoutVal, err := GetConfigValue("timeout")
+	require.NoError(t, err)
+	assert.Equal(t, "default", timeoutVal.Source)
+}
+
+func TestUnsetConfigValue_NestedKey(t *testing.T) {
+	configPath := setupEditTestConfig(t, "port: 9000\nprofiles:\n  dev:\n    port: 3000\n    timeout: 5\n")
+
+	require.NoError(t, UnsetConfigValue("profiles.dev.port"))
+
+	// #nosec G304 -- test file inclusion via variable is acceptable for test files
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "port: 9000\nprofiles:\n  dev:\n    timeout: 5\n", string(data))
+}
+
+func TestUnsetConfigValue_UnknownKey(t *testing.T) {
+	setupEditTestConfig(t, "port: 9000\n")
+
+	err := UnsetConfigValue("unknown_key")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown config key")
+}
+
+func TestUnsetConfigValue_NotSet(t *testing.T) {
+	setupEditTestConfig(t, "port: 9000\n")
+
+	err := UnsetConfigValue("timeout")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not set in any config file")
+}
+
+func TestUnsetConfigValue_InvalidResult(t *testing.T) {
+	content := "model: ollama:qwen3:0.6b\nprofiles:\n  dev:\n    port: 3000\n"
+	configPath := setupEditTestConfig(t, content)
+	t.Setenv(ProfileEnvVar, "dev")
+
+	// Removing the profile that is selected makes the config invalid, so the file is kept
+	err := UnsetConfigValue("profiles.dev")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "was not changed")
+
+	// #nosec G304 -- test file inclusion via variable is acceptable for test files
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+}
+
+func TestEditConfigFile(t *testing.T) {
+	configPath := setupEditTestConfig(t, "port: 9000\n")
+
+	changed, err := EditConfigFile(configPath, func(path string) error {
+		assert.NotEqual(t, configPath, path)
+		assert.Equal(t, ".yaml", filepath.Ext(path))
+		// #nosec G306 -- test file permissions are acceptable for temporary test files
+		return os.WriteFile(path, []byte("port: 9100\n"), 0644)
+	})
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	portVal, err := GetConfigValue("port")
+	require.NoError(t, err)
+	assert.Equal(t, 9100, portVal.Value)
+
+	// The copy that was edited replaced the config file
+	entries, err := os.ReadDir(filepath.Dir(configPath))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestEditConfigFile_Unchanged(t *testing.T) {
+	configPath := setupEditTestConfig(t, "port: 9000\n")
+
+	changed, err := EditConfigFile(configPath, func(string) error { return nil })
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	entries, err := os.ReadDir(filepath.Dir(configPath))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestEditConfigFile_Invalid(t *testing.T) {
+	configPath := setupEditTestConfig(t, "port: 9000\n")
+
+	var editPath string
+	changed, err := EditConfigFile(configPath, func(path string) error {
+		editPath = path
+		// #nosec G306 -- test file permissions are acceptable for temporary test files
+		return os.WriteFile(path, []byte("port: -1\n"), 0644)
+	})
+	require.Error(t, err)
+	assert.False(t, changed)
+	assert.Contains(t, err.Error(), editPath)
+
+	// The config file is unchanged and the edit is kept
+	// #nosec G304 -- test file inclusion via variable is acceptable for test files
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "port: 9000\n", string(data))
+	// #nosec G304 -- test file inclusion via variable is acceptable for test files
+	data, err = os.ReadFile(editPath)
+	require.NoError(t, err)
+	assert.Equal(t, "port: -1\n", string(data))
+}
+
+func TestEditConfigFile_NewFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+
+	changed, err := EditConfigFile(configPath, func(path string) error {
+		// #nosec G306 -- test file permissions are acceptable for temporary test files
+		return os.WriteFile(path, []byte("timeout: 60\n"), 0644)
+	})
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	// #nosec G304 -- test file inclusion via variable is acceptable for test files
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "timeout: 60\n", string(data))
+}
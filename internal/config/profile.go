@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// ProfileEnvVar is the environment variable that selects the config profile. The --profile flag
+// sets it, so that child orla processes use the same profile.
+const ProfileEnvVar = "ORLA_PROFILE"
+
+// profilesKey is the config key of the map of named profiles
+const profilesKey = "profiles"
+
+// ActiveProfile returns the name of the config profile selected by ORLA_PROFILE, or "" for none
+func ActiveProfile() string {
+	return os.Getenv(ProfileEnvVar)
+}
+
+// profileKey returns the config key of the settings of the named profile. Viper lower-cases keys,
+// so profile names are case-insensitive.
+func profileKey(profile string) string {
+	return profilesKey + "." + strings.ToLower(profile)
+}
+
+// applyProfile merges the settings of the active profile, if any, over the config files read by
+// setupViper. Environment variables still take precedence over them.
+func applyProfile() error {
+	profile := ActiveProfile()
+	if profile == "" {
+		return nil
+	}
+
+	settings, ok := viper.Get(profileKey(profile)).(map[string]any)
+	if !ok || strings.Contains(profile, ".") {
+		known := slices.Sorted(maps.Keys(viper.GetStringMap(profilesKey)))
+		if len(known) == 0 {
+			return fmt.Errorf("unknown config profile %q: the config defines no profiles", profile)
+		}
+		return fmt.Errorf("unknown config profile %q (defined profiles: %s)", profile, strings.Join(known, ", "))
+	}
+
+	// A profile cannot define further profiles
+	settings = maps.Clone(settings)
+	delete(settings, profilesKey)
+	if err := viper.MergeConfigMap(settings); err != nil {
+		return fmt.Errorf("failed to apply config profile %q: %w", profile, err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/registry"
+)
+
+// setupProfileTestConfig writes a user config and a project config with dev and prod profiles,
+// and changes to the project directory
+func setupProfileTestConfig(t *testing.T) {
+	t.Helper()
+	orlaHome := t.TempDir()
+	t.Setenv(registry.OrlaHomeEnvVar, orlaHome)
+	t.Setenv(ProfileEnvVar, "")
+
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(orlaHome, "config.yaml"), []byte("timeout: 45\nlog_level: warn\n"), 0644))
+
+	tmpDir, cleanup := setupTestConfig(t)
+	t.Cleanup(cleanup)
+	projectConfig := `port: 9000
+model: ollama:qwen3:0.6b
+profiles:
+  dev:
+    port: 3000
+    timeout: 5
+  prod:
+    model: openai:gpt-4o-mini
+    tools_dir: ./prod-tools
+`
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "orla.yaml"), []byte(projectConfig), 0644))
+}
+
+func TestLoadConfig_Profile(t *testing.T) {
+	setupProfileTestConfig(t)
+
+	// Without a profile, the base config applies
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, 9000, cfg.Port)
+	assert.Equal(t, 45, cfg.Timeout)
+	assert.Contains(t, cfg.Profiles, "dev")
+
+	// A profile overrides the project and user configs, and keeps the values it does not set
+	t.Setenv(ProfileEnvVar, "dev")
+	cfg, err = LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, 3000, cfg.Port)
+	assert.Equal(t, 5, cfg.Timeout)
+	assert.Equal(t, "ollama:qwen3:0.6b", cfg.Model)
+	assert.Equal(t, "warn", cfg.LogLevel)
+
+	// Profile names are case-insensitive, and relative paths in a profile resolve like the config's
+	t.Setenv(ProfileEnvVar, "Prod")
+	cfg, err = LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, 9000, cfg.Port)
+	assert.Equal(t, "openai:gpt-4o-mini", cfg.Model)
+	assert.Equal(t, "prod-tools", filepath.Base(cfg.ToolsDir))
+
+	// Environment variables still override the profile
+	t.Setenv(ProfileEnvVar, "dev")
+	t.Setenv("ORLA_PORT", "4000")
+	cfg, err = LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, 4000, cfg.Port)
+}
+
+func TestLoadConfig_ProfileWithSpecificPath(t *testing.T) {
+	t.Setenv(ProfileEnvVar, "ci")
+	configPath := filepath.Join(t.TempDir(), "orla.yaml")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte("port: 9000\nprofiles:\n  ci:\n    port: 0\n"), 0644))
+
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, 0, cfg.Port)
+}
+
+func TestLoadConfig_UnknownProfile(t *testing.T) {
+	setupProfileTestConfig(t)
+
+	t.Setenv(ProfileEnvVar, "staging")
+	_, err := LoadConfig("")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown config profile "staging" (defined profiles: dev, prod)`)
+
+	// A name with a dot does not reach into a profile's settings
+	t.Setenv(ProfileEnvVar, "dev.port")
+	_, err = LoadConfig("")
+	require.Error(t, err)
+
+	configPath := filepath.Join(t.TempDir(), "orla.yaml")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte("port: 9000\n"), 0644))
+	_, err = LoadConfig(configPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the config defines no profiles")
+}
+
+func TestGetConfigValue_Profile(t *testing.T) {
+	setupProfileTestConfig(t)
+	t.Setenv(ProfileEnvVar, "dev")
+
+	portVal, err := GetConfigValue("port")
+	require.NoError(t, err)
+	assert.Equal(t, 3000, portVal.Value)
+	assert.Equal(t, "profile", portVal.Source)
+	assert.Equal(t, "dev", portVal.Profile)
+
+	modelVal, err := GetConfigValue("model")
+	require.NoError(t, err)
+	assert.Equal(t, "project", modelVal.Source)
+	assert.Empty(t, modelVal.Profile)
+
+	values, err := ListConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "profile", values["timeout"].Source)
+	assert.Equal(t, "user", values["log_level"].Source)
+	assert.NotContains(t, values, "profiles")
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// ToolOverride overrides settings of a tool's manifest from the config
+type ToolOverride struct {
+	Enabled *bool `yaml:"enabled,omitempty" mapstructure:"enabled"` // whether the server registers the tool, whatever its manifest says
+}
+
+// ToolEnabled reports whether the server registers tool: as the config's tool_overrides say if
+// they set it, and as the tool's manifest says otherwise. Tool names are matched
+// case-insensitively, since viper lowercases the keys of tool_overrides.
+func (c *OrlaConfig) ToolEnabled(tool *core.ToolManifest) bool {
+	if override, ok := c.ToolOverrides[strings.ToLower(tool.Name)]; ok && override.Enabled != nil {
+		return *override.Enabled
+	}
+	return core.IsToolEnabled(tool)
+}
+
+// SetToolEnabled enables or disables the named tool by writing an override to tool_overrides in
+// the project config if it exists, and the user config otherwise. The tool's manifest is owned
+// by the tool and is left as it is. The override takes effect when the server next starts or
+// reloads.
+func SetToolEnabled(name string, enabled bool) error {
+	configPath, err := GetWritableConfigPath()
+	if err != nil {
+		return err
+	}
+
+	// #nosec G304 -- the config file paths are orla's own
+	data, err := os.ReadFile(configPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(enabled)}
+	updated, err := setYAMLKey(data, value, "tool_overrides", name, "enabled")
+	if err != nil {
+		return fmt.Errorf("failed to update config file %s: %w", configPath, err)
+	}
+	return replaceConfigFile(configPath, updated)
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/registry"
+)
+
+func TestSetToolEnabled_ProjectConfig(t *testing.T) {
+	configPath := setupEditTestConfig(t, "# project settings\n\nport: 9000\n")
+	tool := &core.ToolManifest{Name: "Greeter"}
+
+	require.NoError(t, SetToolEnabled("Greeter", false))
+	// #nosec G304 -- test file inclusion via variable is acceptable for test files
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# project settings\n\nport: 9000\ntool_overrides:\n  Greeter:\n    enabled: false\n", string(data))
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.False(t, cfg.ToolEnabled(tool))
+
+	// Enabling replaces the override rather than adding another
+	require.NoError(t, SetToolEnabled("greeter", true))
+	// #nosec G304 -- test file inclusion via variable is acceptable for test files
+	data, err = os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# project settings\n\nport: 9000\ntool_overrides:\n  Greeter:\n    enabled: true\n", string(data))
+
+	cfg, err = LoadConfig("")
+	require.NoError(t, err)
+	assert.True(t, cfg.ToolEnabled(tool))
+}
+
+func TestSetToolEnabled_CreatesUserConfig(t *testing.T) {
+	orlaHome := t.TempDir()
+	t.Setenv(registry.OrlaHomeEnvVar, orlaHome)
+	t.Chdir(t.TempDir())
+
+	require.NoError(t, SetToolEnabled("fs", false))
+
+	// #nosec G304 -- test file inclusion via variable is acceptable for test files
+	data, err := os.ReadFile(filepath.Join(orlaHome, "config.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "tool_overrides:\n  fs:\n    enabled: false\n", string(data))
+}
+
+func TestOrlaConfig_ToolEnabled(t *testing.T) {
+	enabled, disabled := true, false
+	cfg := &OrlaConfig{ToolOverrides: map[string]ToolOverride{
+		"on":    {Enabled: &enabled},
+		"off":   {Enabled: &disabled},
+		"unset": {},
+	}}
+
+	assert.True(t, cfg.ToolEnabled(&core.ToolManifest{Name: "other"}))
+	assert.False(t, cfg.ToolEnabled(&core.ToolManifest{Name: "other", Enabled: &disabled}), "the manifest applies without an override")
+	assert.True(t, cfg.ToolEnabled(&core.ToolManifest{Name: "On", Enabled: &disabled}), "the override wins over the manifest")
+	assert.False(t, cfg.ToolEnabled(&core.ToolManifest{Name: "off"}))
+	assert.False(t, cfg.ToolEnabled(&core.ToolManifest{Name: "unset", Enabled: &disabled}))
+}
+
+func TestSetYAMLKey(t *testing.T) {
+	value := func(v string) *yaml.Node { return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: v} }
+
+	updated, err := setYAMLKey([]byte("profiles:\n"), value("3000"), "profiles", "dev", "port")
+	require.NoError(t, err)
+	assert.Equal(t, "profiles:\n  dev:\n    port: 3000\n", string(updated))
+
+	updated, err = setYAMLKey([]byte("port: 9000\n"), value("3000"), "port")
+	require.NoError(t, err)
+	assert.Equal(t, "port: 3000\n", string(updated))
+
+	_, err = setYAMLKey([]byte("port: 9000\n"), value("3000"), "port", "nested")
+	assert.Error(t, err)
+}
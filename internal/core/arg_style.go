@@ -0,0 +1,123 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// validArgStyles lists the argument passing styles a tool can declare
+var validArgStyles = []ArgStyle{ArgStyleFlags, ArgStyleFlagsEquals, ArgStylePositional, ArgStyleJSONStdin}
+
+// ValidateArgStyle checks the argument passing style of a tool. A tool that does not set one uses flags.
+func ValidateArgStyle(tool *ToolManifest) error {
+	if tool.ArgStyle == "" || slices.Contains(validArgStyles, tool.ArgStyle) {
+		return nil
+	}
+	return fmt.Errorf("invalid arg_style: %s (must be %s, %s, %s, or %s)",
+		tool.ArgStyle, ArgStyleFlags, ArgStyleFlagsEquals, ArgStylePositional, ArgStyleJSONStdin)
+}
+
+// ArgStyleOf returns the argument passing style of a tool, which is flags if it does not set one
+func ArgStyleOf(tool *ToolManifest) ArgStyle {
+	if tool.ArgStyle == "" {
+		return ArgStyleFlags
+	}
+	return tool.ArgStyle
+}
+
+// ToolArgs builds the command-line arguments of a simple mode tool call from its input, in the
+// tool's argument passing style. Flag names have underscores converted to hyphens, the standard
+// convention, and values are formatted with %v, so booleans are passed as "true" or "false".
+// Arguments are ordered by name, except that positional arguments start with the ones in the
+// order the input schema's required list gives. A json-stdin tool takes no arguments.
+func ToolArgs(tool *ToolManifest, input map[string]any) []string {
+	style := ArgStyleOf(tool)
+	if style == ArgStyleJSONStdin {
+		return nil
+	}
+
+	keys := make([]string, 0, len(input))
+	for key := range input {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	if style == ArgStylePositional {
+		keys = positionalArgOrder(tool, keys)
+	}
+
+	args := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		value := fmt.Sprintf("%v", input[key])
+		flag := "--" + strings.ReplaceAll(key, "_", "-")
+		switch style {
+		case ArgStyleFlagsEquals:
+			args = append(args, flag+"="+value)
+		case ArgStylePositional:
+			args = append(args, value)
+		default:
+			args = append(args, flag, value)
+		}
+	}
+	return args
+}
+
+// positionalArgOrder reorders the sorted argument names so that those in the input schema's
+// required list come first, in its order
+func positionalArgOrder(tool *ToolManifest, keys []string) []string {
+	if tool.MCP == nil || tool.MCP.InputSchema == nil {
+		return keys
+	}
+
+	var required []string
+	switch list := tool.MCP.InputSchema["required"].(type) {
+	case []string:
+		required = list
+	case []any:
+		for _, name := range list {
+			if s, ok := name.(string); ok {
+				required = append(required, s)
+			}
+		}
+	}
+
+	ordered := make([]string, 0, len(keys))
+	for _, name := range required {
+		if slices.Contains(keys, name) && !slices.Contains(ordered, name) {
+			ordered = append(ordered, name)
+		}
+	}
+	for _, key := range keys {
+		if !slices.Contains(ordered, key) {
+			ordered = append(ordered, key)
+		}
+	}
+	return ordered
+}
+
+// validStdinFormats lists the stdin formats a tool can declare
+var validStdinFormats = []StdinFormat{StdinFormatRaw, StdinFormatJSONArgs, StdinFormatNone}
+
+// ValidateStdinFormat checks the stdin format of a tool. A tool that does not set one uses raw. A
+// tool with the json-stdin arg_style already receives its input on stdin, so it cannot set one.
+func ValidateStdinFormat(tool *ToolManifest) error {
+	if tool.StdinFormat == "" {
+		return nil
+	}
+	if !slices.Contains(validStdinFormats, tool.StdinFormat) {
+		return fmt.Errorf("invalid stdin_format: %s (must be %s, %s, or %s)",
+			tool.StdinFormat, StdinFormatRaw, StdinFormatJSONArgs, StdinFormatNone)
+	}
+	if ArgStyleOf(tool) == ArgStyleJSONStdin {
+		return fmt.Errorf("invalid stdin_format: tools with arg_style %s receive their input on stdin", ArgStyleJSONStdin)
+	}
+	return nil
+}
+
+// StdinFormatOf returns the stdin format of a tool, which is raw if it does not set one
+func StdinFormatOf(tool *ToolManifest) StdinFormat {
+	if tool.StdinFormat == "" {
+		return StdinFormatRaw
+	}
+	return tool.StdinFormat
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateArgStyle(t *testing.T) {
+	for _, style := range []ArgStyle{"", ArgStyleFlags, ArgStyleFlagsEquals, ArgStylePositional, ArgStyleJSONStdin} {
+		assert.NoError(t, ValidateArgStyle(&ToolManifest{ArgStyle: style}), style)
+	}
+
+	err := ValidateArgStyle(&ToolManifest{ArgStyle: "flag"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid arg_style: flag")
+}
+
+func TestArgStyleOf(t *testing.T) {
+	assert.Equal(t, ArgStyleFlags, ArgStyleOf(&ToolManifest{}))
+	assert.Equal(t, ArgStylePositional, ArgStyleOf(&ToolManifest{ArgStyle: ArgStylePositional}))
+}
+
+func TestToolArgs(t *testing.T) {
+	input := map[string]any{
+		"create_dirs": true,
+		"path":        "/tmp/a b",
+		"count":       3,
+	}
+
+	tests := []struct {
+		style ArgStyle
+		want  []string
+	}{
+		{"", []string{"--count", "3", "--create-dirs", "true", "--path", "/tmp/a b"}},
+		{ArgStyleFlags, []string{"--count", "3", "--create-dirs", "true", "--path", "/tmp/a b"}},
+		{ArgStyleFlagsEquals, []string{"--count=3", "--create-dirs=true", "--path=/tmp/a b"}},
+		{ArgStylePositional, []string{"3", "true", "/tmp/a b"}},
+		{ArgStyleJSONStdin, nil},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.style), func(t *testing.T) {
+			assert.Equal(t, tt.want, ToolArgs(&ToolManifest{ArgStyle: tt.style}, input))
+		})
+	}
+}
+
+func TestToolArgs_PositionalFollowsRequiredOrder(t *testing.T) {
+	tool := &ToolManifest{
+		ArgStyle: ArgStylePositional,
+		MCP: &MCPConfig{InputSchema: map[string]any{
+			"type":     "object",
+			"required": []any{"source", "destination"},
+		}},
+	}
+	input := map[string]any{"destination": "b", "source": "a", "mode": "0644"}
+
+	assert.Equal(t, []string{"a", "b", "0644"}, ToolArgs(tool, input))
+}
+
+func TestValidateStdinFormat(t *testing.T) {
+	for _, format := range []StdinFormat{"", StdinFormatRaw, StdinFormatJSONArgs, StdinFormatNone} {
+		assert.NoError(t, ValidateStdinFormat(&ToolManifest{StdinFormat: format}), format)
+	}
+
+	err := ValidateStdinFormat(&ToolManifest{StdinFormat: "json"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid stdin_format: json")
+
+	err = ValidateStdinFormat(&ToolManifest{ArgStyle: ArgStyleJSONStdin, StdinFormat: StdinFormatNone})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "arg_style json-stdin")
+}
+
+func TestStdinFormatOf(t *testing.T) {
+	assert.Equal(t, StdinFormatRaw, StdinFormatOf(&ToolManifest{}))
+	assert.Equal(t, StdinFormatNone, StdinFormatOf(&ToolManifest{StdinFormat: StdinFormatNone}))
+}
@@ -2,10 +2,13 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"slices"
@@ -19,8 +22,20 @@ import (
 
 const (
 	DefaultCapsuleStartupTimeoutMs = 5000
+	// DefaultCapsuleMaxRestarts is how many times the server restarts a capsule whose process exits
+	// unexpectedly before it gives up on the tool
+	DefaultCapsuleMaxRestarts = 3
+	// DefaultCapsuleRestartBackoffMs is how long the server waits before the first restart of a
+	// capsule whose process exited unexpectedly; the wait doubles with each restart
+	DefaultCapsuleRestartBackoffMs = 500
 )
 
+// capsuleExitOutputGrace is how long the output a capsule wrote before exiting may take to be read
+const capsuleExitOutputGrace = time.Second
+
+// maxHandshakeOutputLength is the number of bytes of invalid handshake output included in errors
+const maxHandshakeOutputLength = 200
+
 // CapsuleState represents the lifecycle state of a capsule
 type CapsuleState string
 
@@ -40,9 +55,12 @@ type CapsuleManager struct {
 	stateMu        sync.RWMutex
 	process        *exec.Cmd
 	processMu      sync.RWMutex
+	exited         chan struct{} // Closed when the process exits, nil until it is started
+	crashed        bool          // Whether the process exited without Stop being called
 	startupTimeout time.Duration
 	clock          clockwork.Clock
 	handshakeCh    chan *OrlaHelloNotification
+	invalidHelloCh chan *InvalidHandshakeError // Output written instead of the handshake
 	ctx            context.Context
 	cancel         context.CancelFunc
 
@@ -53,6 +71,28 @@ type CapsuleManager struct {
 	requestIDMu    sync.Mutex
 	responses      *xsync.MapOf[int64, chan *JSONRPCResponse] // Map of request ID to response channel
 	responseReader *json.Decoder                              // JSON decoder for reading responses
+	writeMu        sync.Mutex                                 // Serializes writes of JSON-RPC messages to stdin
+	maxOutputBytes int64                                      // Limit on the size of each message the capsule writes, 0 for none
+	outputErr      error                                      // Set, under processMu, if a message exceeded maxOutputBytes
+
+	// Streaming input (see capsule_stream.go)
+	capabilities    []string                        // Capabilities advertised in the orla.hello handshake
+	inputAcks       *xsync.MapOf[int64, chan int64] // Map of request ID to input chunk acknowledgement channel
+	inputChunkSize  int                             // Maximum number of bytes per input chunk
+	inputWindowSize int                             // Maximum number of unacknowledged input chunks
+
+	// Health checks (see capsule_health.go)
+	healthCheckInterval time.Duration // Interval between pings, 0 if health checks are off
+	healthCheckFailures int           // Consecutive failed pings after which the capsule is unhealthy
+	healthMu            sync.RWMutex  // Guards the fields below
+	healthy             bool
+	failedPings         int
+
+	// Draining (see capsule_drain.go)
+	drainMu  sync.Mutex    // Guards the fields below
+	draining bool          // Whether the capsule refuses new calls because it is being drained
+	inFlight int           // Number of calls sent to the capsule that have not returned yet
+	idle     chan struct{} // Closed when inFlight drops to zero while draining, nil otherwise
 }
 
 // OrlaHelloNotification represents the orla.hello handshake notification
@@ -83,20 +123,37 @@ func NewCapsuleManagerWithClock(tool *ToolManifest, clock clockwork.Clock) *Caps
 		startupTimeout = time.Duration(tool.Runtime.StartupTimeoutMs) * time.Millisecond
 	}
 
+	healthCheckInterval, healthCheckFailures := healthCheckConfig(tool)
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &CapsuleManager{
-		tool:           tool,
-		state:          CapsuleStateCreated,
-		startupTimeout: startupTimeout,
-		clock:          clock,
-		handshakeCh:    make(chan *OrlaHelloNotification, 1),
-		ctx:            ctx,
-		cancel:         cancel,
-		responses:      xsync.NewMapOf[int64, chan *JSONRPCResponse](),
+		tool:            tool,
+		state:           CapsuleStateCreated,
+		startupTimeout:  startupTimeout,
+		clock:           clock,
+		handshakeCh:     make(chan *OrlaHelloNotification, 1),
+		invalidHelloCh:  make(chan *InvalidHandshakeError, 1),
+		ctx:             ctx,
+		cancel:          cancel,
+		responses:       xsync.NewMapOf[int64, chan *JSONRPCResponse](),
+		inputAcks:       xsync.NewMapOf[int64, chan int64](),
+		inputChunkSize:  DefaultCapsuleInputChunkSize,
+		inputWindowSize: DefaultCapsuleInputWindowSize,
+
+		healthCheckInterval: healthCheckInterval,
+		healthCheckFailures: healthCheckFailures,
+		healthy:             true,
 	}
 }
 
+// SetMaxOutputBytes limits the size of each JSON-RPC message the capsule may write to stdout. A
+// capsule that writes a larger message is killed, failing its calls in flight, and restarted like
+// a capsule that crashed. A limit of 0 or less removes it. It must be called before Start.
+func (cm *CapsuleManager) SetMaxOutputBytes(limit int64) {
+	cm.maxOutputBytes = max(limit, 0)
+}
+
 // Start starts the capsule process and waits for the handshake
 func (cm *CapsuleManager) Start() error {
 	cm.stateMu.Lock()
@@ -125,21 +182,19 @@ func (cm *CapsuleManager) Start() error {
 		}
 		cmd = exec.CommandContext(cm.ctx, cm.tool.Path, runtimeArgs...)
 	}
+	// Stop cancels cm.ctx, which terminates the capsule together with the processes it spawned
+	killProcessGroupOnCancel(cmd)
 
 	// Set environment variables
-	if cm.tool.Runtime != nil && len(cm.tool.Runtime.Env) > 0 {
-		env := cmd.Environ()
-		for key, value := range cm.tool.Runtime.Env {
-			env = append(env, fmt.Sprintf("%s=%s", key, value))
-		}
+	if env := toolEnv(cm.tool, nil); env != nil {
 		cmd.Env = env
 	}
 
-	// Set working directory to tool's directory (parent of entrypoint)
+	// Set working directory to the tool's working_dir, or else the tool's directory (parent of entrypoint)
 	// Note(jadidbourbaki): tool.Path is the absolute path to the entrypoint, so we need its parent
-	// TODO(jadidbourbaki): this might not be the best thought out solution, think about what we really
-	// want as our working directory here.
-	if toolDir := filepath.Dir(cm.tool.Path); toolDir != "" {
+	if cm.tool.WorkingDir != "" {
+		cmd.Dir = cm.tool.WorkingDir
+	} else if toolDir := filepath.Dir(cm.tool.Path); toolDir != "" {
 		cmd.Dir = toolDir
 	}
 
@@ -150,55 +205,232 @@ func (cm *CapsuleManager) Start() error {
 		return fmt.Errorf("failed to create stdin pipe: %w", stdinErr)
 	}
 
-	stdout, stdoutErr := cmd.StdoutPipe()
+	// Unlike cmd.StdoutPipe, a pipe of our own is not closed by Wait, so output the capsule wrote
+	// just before exiting can still be read after it exits
+	stdout, stdoutWriter, stdoutErr := os.Pipe()
 	if stdoutErr != nil {
 		cm.setState(CapsuleStateCrashed)
 		return fmt.Errorf("failed to create stdout pipe: %w", stdoutErr)
 	}
+	cmd.Stdout = stdoutWriter
 
 	cm.processMu.Lock()
 	cm.process = cmd
 	cm.stdin = stdin
 	cm.stdout = stdout
-	cm.responseReader = json.NewDecoder(stdout)
+	limitReader := &messageLimitReader{r: stdout, limit: cm.maxOutputBytes}
+	cm.responseReader = json.NewDecoder(limitReader)
+	limitReader.offset = cm.responseReader.InputOffset
 	cm.processMu.Unlock()
 
-	// Start process
+	// Start process. The child has its own copy of the write end of stdout, which must be the only
+	// one for reads to end when the child exits.
 	startErr := cmd.Start()
+	if closeErr := stdoutWriter.Close(); closeErr != nil {
+		zap.L().Error("Failed to close stdout pipe", zap.Error(closeErr))
+	}
 	if startErr != nil {
 		cm.setState(CapsuleStateCrashed)
 		return fmt.Errorf("failed to start capsule process: %w", startErr)
 	}
 
-	// Start response reader in background to read all JSON-RPC messages
-	go cm.readResponses()
+	// Read the handshake and then all JSON-RPC messages in the background
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		if cm.readHandshake() {
+			cm.readResponses()
+		}
+	}()
 
-	// Wait for handshake with timeout
-	select {
-	case notification := <-cm.handshakeCh:
-		if notification == nil {
+	exited := make(chan struct{})
+	cm.processMu.Lock()
+	cm.exited = exited
+	cm.processMu.Unlock()
+	go cm.waitProcess(cmd, exited, readerDone)
+
+	// Wait for handshake with timeout. A capsule that writes something other than the handshake
+	// fails at once instead of when the startup timeout expires.
+	startupTimeoutCh := cm.clock.After(cm.startupTimeout)
+	for {
+		select {
+		case notification := <-cm.handshakeCh:
+			if notification == nil {
+				cm.setState(CapsuleStateCrashed)
+				return fmt.Errorf("handshake read failed")
+			}
+			cm.processMu.Lock()
+			cm.capabilities = notification.Params.Capabilities
+			cm.processMu.Unlock()
+			cm.setState(CapsuleStateReady)
+			zap.L().Info("Capsule handshake received",
+				zap.String("tool", cm.tool.Name),
+				zap.String("version", notification.Params.Version),
+				zap.Strings("capabilities", notification.Params.Capabilities))
+			if cm.healthCheckInterval > 0 {
+				go cm.runHealthChecks()
+			}
+			return nil
+		case invalidHandshake := <-cm.invalidHelloCh:
+			stopErr := cm.Stop()
+			if stopErr != nil {
+				zap.L().Error("Failed to stop capsule after invalid handshake", zap.Error(stopErr))
+			}
+			// The capsule failed to start rather than being stopped
+			cm.setState(CapsuleStateCrashed)
+
+			return invalidHandshake
+		case <-startupTimeoutCh:
+			stopErr := cm.Stop()
+			if stopErr != nil {
+				zap.L().Error("Failed to stop capsule on timeout", zap.Error(stopErr))
+			}
+			// The capsule failed to start rather than being stopped
+			cm.setState(CapsuleStateCrashed)
+
+			return fmt.Errorf("handshake timeout after %v", cm.startupTimeout)
+		case <-exited:
 			cm.setState(CapsuleStateCrashed)
-			return fmt.Errorf("handshake read failed")
+			return fmt.Errorf("capsule exited before the handshake")
+		case <-cm.ctx.Done():
+			cm.setState(CapsuleStateStopped)
+			return fmt.Errorf("capsule context cancelled")
 		}
-		cm.setState(CapsuleStateReady)
-		zap.L().Info("Capsule handshake received",
-			zap.String("tool", cm.tool.Name),
-			zap.String("version", notification.Params.Version),
-			zap.Strings("capabilities", notification.Params.Capabilities))
-		return nil
-	case <-cm.clock.After(cm.startupTimeout):
-		cm.setState(CapsuleStateCrashed)
+	}
+}
+
+// waitProcess waits for the capsule's process to exit and for its remaining output to be read,
+// then closes exited. A process that exits without Stop being called marks the capsule as crashed.
+func (cm *CapsuleManager) waitProcess(cmd *exec.Cmd, exited chan struct{}, readerDone <-chan struct{}) {
+	waitErr := cmd.Wait()
+
+	// Output ends when the process exits, unless a child of the capsule keeps stdout open
+	select {
+	case <-readerDone:
+	case <-time.After(capsuleExitOutputGrace):
+	}
+
+	// Stop cancels the context before killing the process
+	crashed := cm.ctx.Err() == nil
+
+	cm.processMu.Lock()
+	cm.crashed = crashed
+	cm.processMu.Unlock()
+	close(exited)
+
+	if !crashed {
+		return
+	}
+
+	zap.L().Error("Capsule process exited unexpectedly",
+		zap.String("tool", cm.tool.Name),
+		zap.NamedError("exit", waitErr))
+
+	cm.stateMu.Lock()
+	defer cm.stateMu.Unlock()
+	if cm.state != CapsuleStateStopped {
+		cm.setStateLocked(CapsuleStateCrashed)
+	}
+}
+
+// Done returns a channel that is closed when the capsule's process exits, whether it crashed or
+// was stopped. It returns nil if the capsule was never started.
+func (cm *CapsuleManager) Done() <-chan struct{} {
+	cm.processMu.RLock()
+	defer cm.processMu.RUnlock()
+	return cm.exited
+}
+
+// Crashed returns true if the capsule's process exited without Stop being called
+func (cm *CapsuleManager) Crashed() bool {
+	cm.processMu.RLock()
+	defer cm.processMu.RUnlock()
+	return cm.crashed
+}
+
+// InvalidHandshakeError is returned when the first output of a capsule is not an orla.hello
+// handshake
+type InvalidHandshakeError struct {
+	Tool   string
+	Output string
+	Err    error
+}
+
+func (e *InvalidHandshakeError) Error() string {
+	return fmt.Sprintf("capsule %s sent an invalid handshake: %v in output %q; "+
+		"capsules must write the orla.hello notification as the first message on stdout and log to stderr",
+		e.Tool, e.Err, e.Output)
+}
+
+func (e *InvalidHandshakeError) Unwrap() error {
+	return e.Err
+}
 
-		stopErr := cm.Stop()
-		if stopErr != nil {
-			zap.L().Error("Failed to stop capsule on timeout", zap.Error(stopErr))
+// Interface guard for InvalidHandshakeError
+var _ error = &InvalidHandshakeError{}
+
+// readHandshake decodes the first JSON message from stdout, which must be the orla.hello
+// handshake. Like the messages that follow, it may span several lines. Output that is not a
+// handshake is reported on invalidHelloCh. It returns false if there is no handshake.
+func (cm *CapsuleManager) readHandshake() bool {
+	cm.processMu.RLock()
+	decoder := cm.responseReader
+	cm.processMu.RUnlock()
+
+	var rawMessage json.RawMessage
+	if err := decoder.Decode(&rawMessage); err != nil {
+		var syntaxErr *json.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			zap.L().Debug("Stopping handshake reader", zap.String("tool", cm.tool.Name), zap.Error(err))
+			return false
+		}
+		// The decoder keeps the output it failed to parse buffered
+		output, _ := io.ReadAll(decoder.Buffered())
+		output, _, _ = bytes.Cut(bytes.TrimSpace(output), []byte("\n"))
+		cm.invalidHelloCh <- &InvalidHandshakeError{
+			Tool:   cm.tool.Name,
+			Output: truncateHandshakeOutput(output),
+			Err:    fmt.Errorf("failed to parse JSON: %w", err),
+		}
+		return false
+	}
+
+	notification, err := parseHandshake(rawMessage)
+	if err != nil {
+		cm.invalidHelloCh <- &InvalidHandshakeError{
+			Tool:   cm.tool.Name,
+			Output: truncateHandshakeOutput(rawMessage),
+			Err:    err,
 		}
+		return false
+	}
 
-		return fmt.Errorf("handshake timeout after %v", cm.startupTimeout)
+	select {
+	case cm.handshakeCh <- notification:
+		return true
 	case <-cm.ctx.Done():
-		cm.setState(CapsuleStateStopped)
-		return fmt.Errorf("capsule context cancelled")
+		return false
+	}
+}
+
+// parseHandshake parses a message of capsule output as an orla.hello notification
+func parseHandshake(line []byte) (*OrlaHelloNotification, error) {
+	var notification OrlaHelloNotification
+	if err := json.Unmarshal(line, &notification); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if notification.Method != "orla.hello" {
+		return nil, fmt.Errorf("expected method orla.hello, got %q", notification.Method)
 	}
+	return &notification, nil
+}
+
+// truncateHandshakeOutput shortens invalid handshake output for error messages
+func truncateHandshakeOutput(output []byte) string {
+	if len(output) <= maxHandshakeOutputLength {
+		return string(output)
+	}
+	return string(output[:maxHandshakeOutputLength]) + "..."
 }
 
 // JSONRPCRequest represents a JSON-RPC request
@@ -248,6 +480,10 @@ func (cm *CapsuleManager) readResponses() {
 			if err == io.EOF {
 				return
 			}
+			if errors.Is(err, errOutputLimit) {
+				cm.killForOutputLimit()
+				return
+			}
 			// If the pipe is closed or any other error occurs, exit the goroutine
 			// This prevents infinite error loops when the pipe is closed
 			zap.L().Debug("Stopping response reader", zap.Error(err))
@@ -265,6 +501,10 @@ func (cm *CapsuleManager) readResponses() {
 				}
 				continue
 			}
+			if notification.Method == CapsuleInputAckMethod {
+				cm.handleInputAck(rawMessage)
+				continue
+			}
 		}
 
 		// Try to decode as response
@@ -287,6 +527,30 @@ func (cm *CapsuleManager) readResponses() {
 	}
 }
 
+// killForOutputLimit kills the capsule's process group after the capsule wrote a message larger
+// than maxOutputBytes. The rest of the message cannot be skipped reliably, so the capsule is
+// treated as crashed and restarted by its supervisor.
+func (cm *CapsuleManager) killForOutputLimit() {
+	cm.processMu.Lock()
+	cm.outputErr = fmt.Errorf("capsule wrote a message larger than the output limit of %d bytes", cm.maxOutputBytes)
+	process := cm.process
+	cm.processMu.Unlock()
+
+	zap.L().Error("Capsule exceeded the output limit, killing it",
+		zap.String("tool", cm.tool.Name),
+		zap.Int64("limit", cm.maxOutputBytes))
+	if process == nil || process.Process == nil {
+		return
+	}
+	if err := TerminateProcessGroup(process, toolTerminateTimeout); err != nil {
+		zap.L().Error("Failed to terminate capsule process group", zap.String("tool", cm.tool.Name), zap.Error(err))
+	}
+	// Without process groups (on Windows) only the capsule itself is stopped
+	if err := process.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		zap.L().Error("Failed to kill capsule process", zap.String("tool", cm.tool.Name), zap.Error(err))
+	}
+}
+
 // Stop stops the capsule process
 func (cm *CapsuleManager) Stop() error {
 	cm.stateMu.Lock()
@@ -302,6 +566,7 @@ func (cm *CapsuleManager) Stop() error {
 	process := cm.process
 	stdin := cm.stdin
 	stdout := cm.stdout
+	exited := cm.exited
 	cm.processMu.Unlock()
 
 	// Close pipes
@@ -318,16 +583,10 @@ func (cm *CapsuleManager) Stop() error {
 		}
 	}
 
-	if process != nil {
-		killErr := process.Process.Kill()
-		if killErr != nil {
-			return fmt.Errorf("failed to kill capsule process: %w", killErr)
-		}
-
-		waitErr := process.Wait()
-		if waitErr != nil {
-			return fmt.Errorf("failed to wait for capsule process: %w", waitErr)
-		}
+	// Cancelling the context above terminates the process group of the capsule. The process is
+	// waited for by waitProcess, which closes exited once it is gone.
+	if process != nil && exited != nil {
+		<-exited
 	}
 
 	// Clean up response channels
@@ -374,6 +633,11 @@ func (cm *CapsuleManager) IsReady() bool {
 
 // CallTool sends a JSON-RPC tools/call request to the capsule and waits for the response
 func (cm *CapsuleManager) CallTool(ctx context.Context, input map[string]any) (*JSONRPCResponse, error) {
+	if err := cm.beginCall(); err != nil {
+		return nil, err
+	}
+	defer cm.endCall()
+
 	if !cm.IsReady() {
 		return nil, fmt.Errorf("capsule is not ready (state: %s)", cm.GetState())
 	}
@@ -410,16 +674,25 @@ func (cm *CapsuleManager) CallTool(ctx context.Context, input map[string]any) (*
 		return nil, fmt.Errorf("stdin pipe is not available")
 	}
 
-	encoder := json.NewEncoder(stdin)
-	if err := encoder.Encode(request); err != nil {
+	if err := cm.writeMessage(stdin, request); err != nil {
 		// Clean up response channel
 		cm.responses.Delete(requestID)
 		return nil, fmt.Errorf("failed to send JSON-RPC request: %w", err)
 	}
 
 	// Wait for response with context timeout
+	return cm.awaitResponse(ctx, requestID, responseCh)
+}
+
+// awaitResponse waits for the response to the request with requestID on responseCh. It fails if
+// ctx is done, or if the capsule is stopped or its process exits first.
+func (cm *CapsuleManager) awaitResponse(ctx context.Context, requestID int64, responseCh chan *JSONRPCResponse) (*JSONRPCResponse, error) {
 	select {
 	case response := <-responseCh:
+		// Stop closes the response channels of pending requests
+		if response == nil {
+			return nil, fmt.Errorf("capsule stopped before responding")
+		}
 		return response, nil
 	case <-ctx.Done():
 		// Clean up response channel
@@ -429,5 +702,57 @@ func (cm *CapsuleManager) CallTool(ctx context.Context, input map[string]any) (*
 		// Clean up response channel
 		cm.responses.Delete(requestID)
 		return nil, fmt.Errorf("capsule context cancelled")
+	case <-cm.Done():
+		// All output is read before Done is closed, so a response written just before the process
+		// exited is already on responseCh
+		select {
+		case response := <-responseCh:
+			if response != nil {
+				return response, nil
+			}
+		default:
+		}
+		cm.responses.Delete(requestID)
+		cm.processMu.RLock()
+		outputErr := cm.outputErr
+		cm.processMu.RUnlock()
+		if outputErr != nil {
+			return nil, fmt.Errorf("capsule process exited before responding: %w", outputErr)
+		}
+		return nil, fmt.Errorf("capsule process exited before responding")
+	}
+}
+
+// writeMessage encodes a JSON-RPC message to the capsule's stdin. Writes are serialized so that
+// messages from concurrent calls are never interleaved.
+func (cm *CapsuleManager) writeMessage(stdin io.Writer, message any) error {
+	cm.writeMu.Lock()
+	defer cm.writeMu.Unlock()
+	return json.NewEncoder(stdin).Encode(message)
+}
+
+// messageLimitReader reads the stdout of a capsule for a json.Decoder, failing with
+// errOutputLimit once the message being decoded is larger than limit bytes, or never if limit is
+// 0. offset is the decoder's InputOffset, the start of the message being decoded.
+type messageLimitReader struct {
+	r      io.Reader
+	limit  int64
+	offset func() int64
+	read   int64
+}
+
+func (r *messageLimitReader) Read(p []byte) (int, error) {
+	if r.limit > 0 {
+		pending := r.read - r.offset()
+		if pending > r.limit {
+			return 0, errOutputLimit
+		}
+		// Reading at most one byte past the limit tells a message at the limit from a larger one
+		if int64(len(p)) > r.limit-pending+1 {
+			p = p[:r.limit-pending+1]
+		}
 	}
+	n, err := r.r.Read(p)
+	r.read += int64(n)
+	return n, err
 }
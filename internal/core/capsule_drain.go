@@ -0,0 +1,89 @@
+package core
+
+import (
+	"errors"
+	"time"
+)
+
+// Capsule draining
+//
+// When the server reloads its tools, a capsule that is replaced or removed may still be serving
+// calls. Rather than being stopped at once, which breaks those calls, the capsule is drained: it
+// refuses new calls with ErrCapsuleDraining, waits for the calls in flight to finish, for at most
+// a grace period, and is then stopped.
+
+// ErrCapsuleDraining is returned for a call to a capsule that is draining. The call was not sent
+// to the capsule, so it can be retried as is with the capsule that replaced it.
+var ErrCapsuleDraining = errors.New("capsule is draining")
+
+// Tool returns the manifest of the tool the capsule was started for
+func (cm *CapsuleManager) Tool() *ToolManifest {
+	return cm.tool
+}
+
+// IsDraining reports whether Drain was called on the capsule
+func (cm *CapsuleManager) IsDraining() bool {
+	cm.drainMu.Lock()
+	defer cm.drainMu.Unlock()
+	return cm.draining
+}
+
+// beginCall records the start of a call to the capsule. It fails with ErrCapsuleDraining once
+// the capsule is draining. Every successful beginCall must be paired with an endCall.
+func (cm *CapsuleManager) beginCall() error {
+	cm.drainMu.Lock()
+	defer cm.drainMu.Unlock()
+
+	if cm.draining {
+		return ErrCapsuleDraining
+	}
+	cm.inFlight++
+	return nil
+}
+
+// endCall records the end of a call started with beginCall
+func (cm *CapsuleManager) endCall() {
+	cm.drainMu.Lock()
+	defer cm.drainMu.Unlock()
+
+	cm.inFlight--
+	if cm.inFlight == 0 && cm.idle != nil {
+		close(cm.idle)
+		cm.idle = nil
+	}
+}
+
+// BeginDrain makes the capsule refuse new calls with ErrCapsuleDraining. Drain does this as well;
+// calling BeginDrain first stops new calls at once when Drain runs in the background.
+func (cm *CapsuleManager) BeginDrain() {
+	cm.drainMu.Lock()
+	defer cm.drainMu.Unlock()
+	cm.draining = true
+}
+
+// Drain stops the capsule from accepting new calls, waits for at most timeout for the calls in
+// flight to finish, then stops the capsule. It reports whether all calls finished in time; calls
+// still running when the capsule is stopped fail.
+func (cm *CapsuleManager) Drain(timeout time.Duration) (bool, error) {
+	cm.drainMu.Lock()
+	cm.draining = true
+	idle := cm.idle
+	if cm.inFlight > 0 && idle == nil {
+		idle = make(chan struct{})
+		cm.idle = idle
+	}
+	cm.drainMu.Unlock()
+
+	drained := true
+	if idle != nil {
+		select {
+		case <-idle:
+		case <-cm.clock.After(timeout):
+			drained = false
+		case <-cm.Done():
+			// The process exited, so the calls in flight are failing anyway
+		}
+	}
+
+	return drained, cm.Stop()
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowCapsuleScript answers each request after a second
+const slowCapsuleScript = `#!/bin/sh
+echo '{"jsonrpc":"2.0","method":"orla.hello","params":{"name":"test-tool","version":"1.0.0","capabilities":["tools"]}}'
+
+while IFS= read -r line; do
+  REQ_ID=$(echo "$line" | sed -n 's/.*"id":\([0-9]*\).*/\1/p')
+  if [ -n "$REQ_ID" ]; then
+    sleep 1
+    echo "{\"jsonrpc\":\"2.0\",\"id\":$REQ_ID,\"result\":\"done\"}"
+  fi
+done
+`
+
+// startSlowCapsule starts a capsule running slowCapsuleScript and a call to it, returning the
+// capsule and the channel the call's error is sent on once it returns
+func startSlowCapsule(t *testing.T) (*CapsuleManager, <-chan error) {
+	t.Helper()
+
+	cm := NewCapsuleManager(&ToolManifest{
+		Name:        "test-tool",
+		Version:     "1.0.0",
+		Description: "Test tool",
+		Path:        createCapsuleScript(t, "slow-capsule.sh", slowCapsuleScript),
+		Runtime:     &RuntimeConfig{StartupTimeoutMs: 5000},
+	})
+	require.NoError(t, cm.Start())
+	t.Cleanup(func() { _ = cm.Stop() }) //nolint:errcheck // cleanup in test
+
+	callErr := make(chan error, 1)
+	go func() {
+		_, err := cm.CallTool(context.Background(), map[string]any{})
+		callErr <- err
+	}()
+
+	// Wait for the call to be in flight
+	require.Eventually(t, func() bool {
+		cm.drainMu.Lock()
+		defer cm.drainMu.Unlock()
+		return cm.inFlight == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	return cm, callErr
+}
+
+// TestCapsuleManager_Drain tests that draining lets a call in flight finish before the capsule
+// is stopped, and refuses calls made after it started
+func TestCapsuleManager_Drain(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	cm, callErr := startSlowCapsule(t)
+
+	drained, err := cm.Drain(5 * time.Second)
+	require.NoError(t, err)
+	assert.True(t, drained)
+	assert.NoError(t, <-callErr)
+	assert.True(t, cm.IsDraining())
+	assert.Equal(t, CapsuleStateStopped, cm.GetState())
+
+	_, err = cm.CallTool(context.Background(), map[string]any{})
+	assert.ErrorIs(t, err, ErrCapsuleDraining)
+}
+
+// TestCapsuleManager_Drain_Timeout tests that a capsule whose calls outlast the drain timeout is
+// stopped anyway, failing the calls
+func TestCapsuleManager_Drain_Timeout(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	cm, callErr := startSlowCapsule(t)
+
+	drained, err := cm.Drain(20 * time.Millisecond)
+	require.NoError(t, err)
+	assert.False(t, drained)
+	assert.Error(t, <-callErr)
+	assert.Equal(t, CapsuleStateStopped, cm.GetState())
+}
+
+// TestCapsuleManager_Drain_Idle tests that an idle capsule is stopped without waiting
+func TestCapsuleManager_Drain_Idle(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	cm := NewCapsuleManager(&ToolManifest{
+		Name:        "test-tool",
+		Version:     "1.0.0",
+		Description: "Test tool",
+		Path:        createCapsuleScript(t, "slow-capsule.sh", slowCapsuleScript),
+		Runtime:     &RuntimeConfig{StartupTimeoutMs: 5000},
+	})
+	require.NoError(t, cm.Start())
+
+	drained, err := cm.Drain(time.Minute)
+	require.NoError(t, err)
+	assert.True(t, drained)
+	assert.Equal(t, CapsuleStateStopped, cm.GetState())
+}
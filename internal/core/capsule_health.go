@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"go.uber.org/zap"
+)
+
+// Capsule health checks
+//
+// A capsule that completed its handshake can still wedge later, e.g. deadlock while serving a
+// call. If runtime.health_check_interval_ms is set, Orla sends the capsule an orla.ping request on
+// that interval once it is ready. Any JSON-RPC response to the ping counts as healthy, including
+// an error response from a capsule that does not know the method, since it shows the capsule is
+// still reading requests. A ping that gets no response within the interval fails, and after
+// runtime.health_check_failures consecutive failures the capsule is marked unhealthy, so that
+// the server restarts it instead of sending it calls that would time out.
+
+const (
+	// CapsulePingMethod is the JSON-RPC method of the requests sent to check a capsule's health
+	CapsulePingMethod = "orla.ping"
+	// DefaultCapsuleHealthCheckFailures is the number of consecutive failed pings after which a
+	// capsule is unhealthy
+	DefaultCapsuleHealthCheckFailures = 3
+)
+
+// Ping sends an orla.ping request to the capsule and waits for the response. It returns an error
+// if the capsule is not ready or does not respond before ctx is done.
+func (cm *CapsuleManager) Ping(ctx context.Context) error {
+	if !cm.IsReady() {
+		return fmt.Errorf("capsule is not ready (state: %s)", cm.GetState())
+	}
+
+	// Generate request ID
+	cm.requestIDMu.Lock()
+	cm.requestID++
+	requestID := cm.requestID
+	cm.requestIDMu.Unlock()
+
+	responseCh := make(chan *JSONRPCResponse, 1)
+	cm.responses.Store(requestID, responseCh)
+
+	request := JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      requestID,
+		Method:  CapsulePingMethod,
+		Params:  map[string]any{},
+	}
+
+	cm.processMu.RLock()
+	stdin := cm.stdin
+	cm.processMu.RUnlock()
+
+	if stdin == nil {
+		cm.responses.Delete(requestID)
+		return fmt.Errorf("stdin pipe is not available")
+	}
+
+	if err := cm.writeMessage(stdin, request); err != nil {
+		cm.responses.Delete(requestID)
+		return fmt.Errorf("failed to send ping: %w", err)
+	}
+
+	if _, err := cm.awaitResponse(ctx, requestID, responseCh); err != nil {
+		return fmt.Errorf("no response to ping: %w", err)
+	}
+	return nil
+}
+
+// IsHealthy returns false if the capsule failed its last health_check_failures health checks.
+// Capsules without health checks are always healthy.
+func (cm *CapsuleManager) IsHealthy() bool {
+	cm.healthMu.RLock()
+	defer cm.healthMu.RUnlock()
+	return cm.healthy
+}
+
+// runHealthChecks pings the capsule every health check interval until it is stopped or exits
+func (cm *CapsuleManager) runHealthChecks() {
+	ticker := cm.clock.NewTicker(cm.healthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.Chan():
+			cm.checkHealth()
+		case <-cm.ctx.Done():
+			return
+		case <-cm.Done():
+			return
+		}
+	}
+}
+
+// checkHealth pings the capsule once and updates its health. A ping must be answered before the
+// next one is due.
+func (cm *CapsuleManager) checkHealth() {
+	ctx, cancel := clockwork.WithTimeout(cm.ctx, cm.clock, cm.healthCheckInterval)
+	defer cancel()
+
+	err := cm.Ping(ctx)
+
+	cm.healthMu.Lock()
+	defer cm.healthMu.Unlock()
+
+	if err == nil {
+		if cm.failedPings > 0 {
+			zap.L().Info("Capsule responded to ping again", zap.String("tool", cm.tool.Name))
+		}
+		cm.failedPings = 0
+		cm.healthy = true
+		return
+	}
+
+	cm.failedPings++
+	zap.L().Warn("Capsule health check failed",
+		zap.String("tool", cm.tool.Name),
+		zap.Int("consecutive_failures", cm.failedPings),
+		zap.Error(err))
+
+	if cm.healthy && cm.failedPings >= cm.healthCheckFailures {
+		cm.healthy = false
+		zap.L().Error("Capsule is unhealthy",
+			zap.String("tool", cm.tool.Name),
+			zap.Int("consecutive_failures", cm.failedPings))
+	}
+}
+
+// healthCheckConfig returns the health check interval of tool, or 0 if health checks are off,
+// and the number of consecutive failed pings after which it is unhealthy
+func healthCheckConfig(tool *ToolManifest) (time.Duration, int) {
+	if tool.Runtime == nil || tool.Runtime.HealthCheckIntervalMs <= 0 {
+		return 0, 0
+	}
+
+	failures := DefaultCapsuleHealthCheckFailures
+	if tool.Runtime.HealthCheckFailures > 0 {
+		failures = tool.Runtime.HealthCheckFailures
+	}
+	return time.Duration(tool.Runtime.HealthCheckIntervalMs) * time.Millisecond, failures
+}
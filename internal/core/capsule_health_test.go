@@ -0,0 +1,124 @@
+package core
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wedgingCapsuleScript answers the first request it receives and then stops responding
+const wedgingCapsuleScript = `#!/bin/sh
+echo '{"jsonrpc":"2.0","method":"orla.hello","params":{"name":"test-tool","version":"1.0.0","capabilities":["tools"]}}'
+
+IFS= read -r line
+REQ_ID=$(echo "$line" | sed -n 's/.*"id":\([0-9]*\).*/\1/p')
+echo "{\"jsonrpc\":\"2.0\",\"id\":$REQ_ID,\"result\":{}}"
+
+exec sleep 1000
+`
+
+func TestCapsuleManager_Ping(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	tool := &ToolManifest{
+		Name:    "test-tool",
+		Path:    createRespondingCapsuleScript(t),
+		Runtime: &RuntimeConfig{StartupTimeoutMs: 5000},
+	}
+
+	cm := NewCapsuleManager(tool)
+	require.NoError(t, cm.Start())
+	t.Cleanup(func() { _ = cm.Stop() }) //nolint:errcheck // cleanup in test
+
+	require.NoError(t, cm.Ping(context.Background()))
+	assert.True(t, cm.IsHealthy())
+}
+
+func TestCapsuleManager_Ping_NotReady(t *testing.T) {
+	cm := NewCapsuleManager(&ToolManifest{Name: "test-tool", Path: "/nonexistent"})
+
+	err := cm.Ping(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "capsule is not ready")
+}
+
+func TestCapsuleManager_HealthCheck_StaysHealthy(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	tool := &ToolManifest{
+		Name: "test-tool",
+		Path: createRespondingCapsuleScript(t),
+		Runtime: &RuntimeConfig{
+			StartupTimeoutMs:      5000,
+			HealthCheckIntervalMs: 100,
+			HealthCheckFailures:   3,
+		},
+	}
+
+	cm := NewCapsuleManager(tool)
+	require.NoError(t, cm.Start())
+	t.Cleanup(func() { _ = cm.Stop() }) //nolint:errcheck // cleanup in test
+
+	// Several pings are answered in this time
+	time.Sleep(350 * time.Millisecond)
+	assert.True(t, cm.IsHealthy())
+
+	response, err := cm.CallTool(context.Background(), map[string]any{})
+	require.NoError(t, err)
+	assert.Nil(t, response.Error)
+}
+
+func TestCapsuleManager_HealthCheck_UnhealthyAfterFailures(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	tool := &ToolManifest{
+		Name: "test-tool",
+		Path: createCapsuleScript(t, "wedging-capsule.sh", wedgingCapsuleScript),
+		Runtime: &RuntimeConfig{
+			StartupTimeoutMs:      5000,
+			HealthCheckIntervalMs: 50,
+			HealthCheckFailures:   2,
+		},
+	}
+
+	cm := NewCapsuleManager(tool)
+	require.NoError(t, cm.Start())
+	t.Cleanup(func() { _ = cm.Stop() }) //nolint:errcheck // cleanup in test
+
+	// The first ping is answered, the next ones time out
+	assert.True(t, cm.IsHealthy())
+	assert.Eventually(t, func() bool { return !cm.IsHealthy() }, 5*time.Second, 10*time.Millisecond)
+
+	// The capsule is still running, only unhealthy
+	assert.Equal(t, CapsuleStateReady, cm.GetState())
+}
+
+func TestCapsuleManager_HealthCheck_Off(t *testing.T) {
+	cm := NewCapsuleManager(&ToolManifest{Name: "test-tool", Runtime: &RuntimeConfig{}})
+	assert.Zero(t, cm.healthCheckInterval)
+	assert.True(t, cm.IsHealthy())
+}
+
+func TestHealthCheckConfig(t *testing.T) {
+	interval, failures := healthCheckConfig(&ToolManifest{})
+	assert.Zero(t, interval)
+	assert.Zero(t, failures)
+
+	interval, failures = healthCheckConfig(&ToolManifest{Runtime: &RuntimeConfig{HealthCheckIntervalMs: 1000}})
+	assert.Equal(t, time.Second, interval)
+	assert.Equal(t, DefaultCapsuleHealthCheckFailures, failures)
+
+	interval, failures = healthCheckConfig(&ToolManifest{Runtime: &RuntimeConfig{HealthCheckIntervalMs: 500, HealthCheckFailures: 5}})
+	assert.Equal(t, 500*time.Millisecond, interval)
+	assert.Equal(t, 5, failures)
+}
@@ -0,0 +1,261 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"go.uber.org/zap"
+)
+
+// Streaming input protocol
+//
+// A capsule that lists "streaming_input" in the capabilities of its orla.hello handshake can
+// receive a tool call's input in chunks instead of as a single argument:
+//
+//  1. Orla sends the tools/call request with "input_stream": true in its params.
+//  2. Orla sends one orla.input/chunk notification per chunk, with params
+//     {"request_id": <tools/call id>, "seq": <0-based chunk number>, "data": <base64 bytes>}.
+//  3. The capsule acknowledges every chunk with an orla.input/ack notification, with params
+//     {"request_id": <tools/call id>, "seq": <seq of the chunk>}.
+//  4. After the last chunk, Orla sends orla.input/end with params
+//     {"request_id": <tools/call id>, "seq": <number of chunks>, "bytes": <total bytes>}.
+//  5. The capsule answers the tools/call request as usual.
+//
+// Backpressure: Orla never has more than the window size (DefaultCapsuleInputWindowSize) of
+// unacknowledged chunks outstanding; once the window is full it waits for an ack before sending
+// the next chunk. A capsule may respond to the tools/call request before the input is complete
+// (for example, to reject it), in which case Orla stops streaming.
+
+const (
+	// CapsuleCapabilityStreamingInput is the handshake capability advertising streaming input support
+	CapsuleCapabilityStreamingInput = "streaming_input"
+	// CapsuleInputChunkMethod is the notification carrying a chunk of streamed input
+	CapsuleInputChunkMethod = "orla.input/chunk"
+	// CapsuleInputEndMethod is the notification marking the end of streamed input
+	CapsuleInputEndMethod = "orla.input/end"
+	// CapsuleInputAckMethod is the notification a capsule sends to acknowledge an input chunk
+	CapsuleInputAckMethod = "orla.input/ack"
+	// DefaultCapsuleInputChunkSize is the maximum number of input bytes per chunk
+	DefaultCapsuleInputChunkSize = 64 * 1024
+	// DefaultCapsuleInputWindowSize is the maximum number of unacknowledged chunks in flight
+	DefaultCapsuleInputWindowSize = 4
+)
+
+// JSONRPCNotification represents a JSON-RPC notification (a request without an ID)
+type JSONRPCNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// InputChunkParams represents the parameters of the orla.input/chunk notification
+type InputChunkParams struct {
+	RequestID int64  `json:"request_id"`
+	Seq       int64  `json:"seq"`
+	Data      string `json:"data"`
+}
+
+// InputEndParams represents the parameters of the orla.input/end notification
+type InputEndParams struct {
+	RequestID int64 `json:"request_id"`
+	Seq       int64 `json:"seq"`
+	Bytes     int64 `json:"bytes"`
+}
+
+// InputAckParams represents the parameters of the orla.input/ack notification
+type InputAckParams struct {
+	RequestID int64 `json:"request_id"`
+	Seq       int64 `json:"seq"`
+}
+
+// inputAckNotification is the wire format of the orla.input/ack notification
+type inputAckNotification struct {
+	Method string         `json:"method"`
+	Params InputAckParams `json:"params"`
+}
+
+// HasCapability reports whether the capsule advertised the capability in its handshake
+func (cm *CapsuleManager) HasCapability(capability string) bool {
+	cm.processMu.RLock()
+	defer cm.processMu.RUnlock()
+	return slices.Contains(cm.capabilities, capability)
+}
+
+// handleInputAck routes an orla.input/ack notification to the call streaming that request's input
+func (cm *CapsuleManager) handleInputAck(rawMessage json.RawMessage) {
+	var ack inputAckNotification
+	if err := json.Unmarshal(rawMessage, &ack); err != nil {
+		zap.L().Debug("Ignoring malformed input ack", zap.String("tool", cm.tool.Name), zap.Error(err))
+		return
+	}
+
+	ackCh, ok := cm.inputAcks.Load(ack.Params.RequestID)
+	if !ok {
+		zap.L().Debug("Ignoring input ack for unknown request",
+			zap.String("tool", cm.tool.Name),
+			zap.Int64("request_id", ack.Params.RequestID))
+		return
+	}
+
+	select {
+	case ackCh <- ack.Params.Seq:
+	default:
+		// The window bounds outstanding chunks, so a full channel means the capsule acked a chunk
+		// more than once; dropping the duplicate is harmless.
+		zap.L().Debug("Dropping duplicate input ack",
+			zap.String("tool", cm.tool.Name),
+			zap.Int64("request_id", ack.Params.RequestID),
+			zap.Int64("seq", ack.Params.Seq))
+	}
+}
+
+// CallToolWithInput sends a JSON-RPC tools/call request to the capsule, streams input to it in
+// chunks, and waits for the response. The capsule must advertise the streaming_input capability.
+func (cm *CapsuleManager) CallToolWithInput(ctx context.Context, input map[string]any, r io.Reader) (*JSONRPCResponse, error) {
+	if err := cm.beginCall(); err != nil {
+		return nil, err
+	}
+	defer cm.endCall()
+
+	if !cm.IsReady() {
+		return nil, fmt.Errorf("capsule is not ready (state: %s)", cm.GetState())
+	}
+	if !cm.HasCapability(CapsuleCapabilityStreamingInput) {
+		return nil, fmt.Errorf("capsule %s does not support %s", cm.tool.Name, CapsuleCapabilityStreamingInput)
+	}
+
+	// Generate request ID
+	cm.requestIDMu.Lock()
+	cm.requestID++
+	requestID := cm.requestID
+	cm.requestIDMu.Unlock()
+
+	// Create response and ack channels
+	responseCh := make(chan *JSONRPCResponse, 1)
+	cm.responses.Store(requestID, responseCh)
+	ackCh := make(chan int64, cm.inputWindowSize)
+	cm.inputAcks.Store(requestID, ackCh)
+	defer cm.inputAcks.Delete(requestID)
+
+	request := JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      requestID,
+		Method:  "tools/call",
+		Params: map[string]any{
+			"name":         cm.tool.Name,
+			"arguments":    input,
+			"input_stream": true,
+		},
+	}
+
+	cm.processMu.RLock()
+	stdin := cm.stdin
+	cm.processMu.RUnlock()
+
+	if stdin == nil {
+		cm.responses.Delete(requestID)
+		return nil, fmt.Errorf("stdin pipe is not available")
+	}
+
+	if err := cm.writeMessage(stdin, request); err != nil {
+		cm.responses.Delete(requestID)
+		return nil, fmt.Errorf("failed to send JSON-RPC request: %w", err)
+	}
+
+	response, err := cm.streamInput(ctx, stdin, requestID, r, responseCh, ackCh)
+	if err != nil {
+		cm.responses.Delete(requestID)
+		return nil, err
+	}
+	if response != nil {
+		// The capsule responded before the input was complete
+		return response, nil
+	}
+
+	// Wait for response with context timeout
+	return cm.awaitResponse(ctx, requestID, responseCh)
+}
+
+// streamInput sends r to the capsule as orla.input/chunk notifications followed by orla.input/end,
+// keeping at most inputWindowSize chunks unacknowledged. If the capsule responds to the request
+// while input is still being streamed, streaming stops and the response is returned.
+func (cm *CapsuleManager) streamInput(
+	ctx context.Context,
+	stdin io.Writer,
+	requestID int64,
+	r io.Reader,
+	responseCh chan *JSONRPCResponse,
+	ackCh chan int64,
+) (*JSONRPCResponse, error) {
+	buf := make([]byte, cm.inputChunkSize)
+	var seq, total int64
+	inFlight := 0
+
+	for {
+		n, readErr := io.ReadFull(r, buf)
+		if n > 0 {
+			// Wait for the capsule to catch up before exceeding the window
+			for inFlight >= cm.inputWindowSize {
+				select {
+				case <-ackCh:
+					inFlight--
+				case response := <-responseCh:
+					return response, nil
+				case <-ctx.Done():
+					return nil, fmt.Errorf("request timeout while streaming input: %w", ctx.Err())
+				case <-cm.ctx.Done():
+					return nil, fmt.Errorf("capsule context cancelled")
+				}
+			}
+
+			chunk := JSONRPCNotification{
+				JSONRPC: "2.0",
+				Method:  CapsuleInputChunkMethod,
+				Params: InputChunkParams{
+					RequestID: requestID,
+					Seq:       seq,
+					Data:      base64.StdEncoding.EncodeToString(buf[:n]),
+				},
+			}
+			if err := cm.writeMessage(stdin, chunk); err != nil {
+				return nil, fmt.Errorf("failed to send input chunk %d: %w", seq, err)
+			}
+			seq++
+			total += int64(n)
+			inFlight++
+		}
+
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			break
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("failed to read input: %w", readErr)
+		}
+	}
+
+	end := JSONRPCNotification{
+		JSONRPC: "2.0",
+		Method:  CapsuleInputEndMethod,
+		Params: InputEndParams{
+			RequestID: requestID,
+			Seq:       seq,
+			Bytes:     total,
+		},
+	}
+	if err := cm.writeMessage(stdin, end); err != nil {
+		return nil, fmt.Errorf("failed to send end of input: %w", err)
+	}
+
+	zap.L().Debug("Streamed input to capsule",
+		zap.String("tool", cm.tool.Name),
+		zap.Int64("request_id", requestID),
+		zap.Int64("chunks", seq),
+		zap.Int64("bytes", total))
+
+	return nil, nil
+}
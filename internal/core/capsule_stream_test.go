@@ -0,0 +1,193 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test helper: create a capsule script that advertises streaming_input, acks every input chunk,
+// and responds with the total number of bytes and chunks it received
+func createStreamingCapsuleScript(t *testing.T) string {
+	t.Helper()
+
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+		return ""
+	}
+
+	scriptContent := `#!/bin/sh
+echo '{"jsonrpc":"2.0","method":"orla.hello","params":{"name":"stream-tool","version":"1.0.0","capabilities":["tools","streaming_input"]}}'
+
+TOTAL=0
+CHUNKS=0
+while IFS= read -r line; do
+  case "$line" in
+    *'"method":"tools/call"'*)
+      REQ_ID=$(echo "$line" | sed -n 's/.*"id":\([0-9]*\).*/\1/p')
+      TOTAL=0
+      CHUNKS=0
+      ;;
+    *'"method":"orla.input/chunk"'*)
+      SEQ=$(echo "$line" | sed -n 's/.*"seq":\([0-9]*\).*/\1/p')
+      DATA=$(echo "$line" | sed -n 's/.*"data":"\([^"]*\)".*/\1/p')
+      SIZE=$(printf '%s' "$DATA" | base64 -d | wc -c)
+      TOTAL=$((TOTAL + SIZE))
+      CHUNKS=$((CHUNKS + 1))
+      echo "{\"jsonrpc\":\"2.0\",\"method\":\"orla.input/ack\",\"params\":{\"request_id\":$REQ_ID,\"seq\":$SEQ}}"
+      ;;
+    *'"method":"orla.input/end"'*)
+      echo "{\"jsonrpc\":\"2.0\",\"id\":$REQ_ID,\"result\":{\"total_bytes\":$TOTAL,\"chunks\":$CHUNKS}}"
+      ;;
+  esac
+done
+`
+
+	scriptFile := filepath.Join(t.TempDir(), "streaming-capsule.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	err := os.WriteFile(scriptFile, []byte(scriptContent), 0755)
+	require.NoError(t, err)
+
+	return scriptFile
+}
+
+func startStreamingCapsule(t *testing.T) *CapsuleManager {
+	t.Helper()
+
+	cm := NewCapsuleManager(&ToolManifest{
+		Name:        "stream-tool",
+		Version:     "1.0.0",
+		Description: "Streaming test tool",
+		Path:        createStreamingCapsuleScript(t),
+		Runtime: &RuntimeConfig{
+			StartupTimeoutMs: 5000,
+		},
+	})
+	require.NoError(t, cm.Start())
+	t.Cleanup(func() {
+		_ = cm.Stop() //nolint:errcheck // cleanup in test
+	})
+	return cm
+}
+
+func TestCapsuleManager_HasCapability(t *testing.T) {
+	cm := startStreamingCapsule(t)
+	assert.True(t, cm.HasCapability(CapsuleCapabilityStreamingInput))
+	assert.True(t, cm.HasCapability("tools"))
+	assert.False(t, cm.HasCapability("unknown"))
+
+	plain := NewCapsuleManager(&ToolManifest{Name: "plain", Path: createRespondingCapsuleScript(t)})
+	require.NoError(t, plain.Start())
+	defer func() {
+		_ = plain.Stop() //nolint:errcheck // cleanup in test
+	}()
+	assert.False(t, plain.HasCapability(CapsuleCapabilityStreamingInput))
+}
+
+func TestCapsuleManager_CallToolWithInput(t *testing.T) {
+	tests := []struct {
+		name       string
+		size       int
+		wantChunks float64
+	}{
+		{name: "empty", size: 0, wantChunks: 0},
+		{name: "single chunk", size: 100, wantChunks: 1},
+		{name: "exact chunk boundary", size: 2 * DefaultCapsuleInputChunkSize, wantChunks: 2},
+		// More chunks than the window, so the sender must wait for acks
+		{name: "beyond window", size: 10*DefaultCapsuleInputChunkSize + 123, wantChunks: 11},
+	}
+
+	cm := startStreamingCapsule(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			input := bytes.Repeat([]byte("x"), tt.size)
+			response, err := cm.CallToolWithInput(ctx, map[string]any{"arg": "value"}, bytes.NewReader(input))
+			require.NoError(t, err)
+			require.NotNil(t, response)
+			require.Nil(t, response.Error)
+
+			result, ok := response.Result.(map[string]any)
+			require.True(t, ok)
+			assert.Equal(t, float64(tt.size), result["total_bytes"])
+			assert.Equal(t, tt.wantChunks, result["chunks"])
+		})
+	}
+}
+
+func TestCapsuleManager_CallToolWithInput_SmallWindow(t *testing.T) {
+	cm := startStreamingCapsule(t)
+	cm.inputChunkSize = 7
+	cm.inputWindowSize = 1
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	response, err := cm.CallToolWithInput(ctx, nil, strings.NewReader(strings.Repeat("abc", 100)))
+	require.NoError(t, err)
+	result, ok := response.Result.(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, float64(300), result["total_bytes"])
+	assert.Equal(t, float64(43), result["chunks"])
+}
+
+func TestCapsuleManager_CallToolWithInput_NoCapability(t *testing.T) {
+	cm := NewCapsuleManager(&ToolManifest{Name: "plain", Path: createRespondingCapsuleScript(t)})
+	require.NoError(t, cm.Start())
+	defer func() {
+		_ = cm.Stop() //nolint:errcheck // cleanup in test
+	}()
+
+	_, err := cm.CallToolWithInput(context.Background(), nil, strings.NewReader("data"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not support streaming_input")
+}
+
+func TestCapsuleManager_CallToolWithInput_NotReady(t *testing.T) {
+	cm := NewCapsuleManager(&ToolManifest{Name: "stream-tool", Path: "/nonexistent"})
+
+	_, err := cm.CallToolWithInput(context.Background(), nil, strings.NewReader("data"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "capsule is not ready")
+}
+
+// TestCapsuleManager_CallToolWithInput_NoAcks tests that streaming blocks once the window is full
+// and gives up when the context expires
+func TestCapsuleManager_CallToolWithInput_NoAcks(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	scriptFile := filepath.Join(t.TempDir(), "silent-capsule.sh")
+	script := `#!/bin/sh
+echo '{"jsonrpc":"2.0","method":"orla.hello","params":{"name":"silent","version":"1.0.0","capabilities":["streaming_input"]}}'
+cat > /dev/null
+`
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(scriptFile, []byte(script), 0755))
+
+	cm := NewCapsuleManager(&ToolManifest{Name: "silent", Path: scriptFile})
+	require.NoError(t, cm.Start())
+	defer func() {
+		_ = cm.Stop() //nolint:errcheck // cleanup in test
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	input := bytes.Repeat([]byte("x"), (DefaultCapsuleInputWindowSize+1)*DefaultCapsuleInputChunkSize)
+	_, err := cm.CallToolWithInput(ctx, nil, bytes.NewReader(input))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "while streaming input")
+}
@@ -106,6 +106,7 @@ func TestCapsuleManager_Start_HandshakeTimeout(t *testing.T) {
 		Path: "/bin/sleep",
 		Runtime: &RuntimeConfig{
 			StartupTimeoutMs: 100, // Very short timeout
+			Args:             []string{"10"},
 		},
 	}
 
@@ -141,6 +142,128 @@ func TestCapsuleManager_Start_HandshakeTimeout(t *testing.T) {
 	assert.Equal(t, CapsuleStateCrashed, cm.GetState())
 }
 
+// Test helper: write a capsule script with the given content
+func createCapsuleScript(t *testing.T, name, content string) string {
+	t.Helper()
+
+	scriptFile := filepath.Join(t.TempDir(), name)
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(scriptFile, []byte(content), 0755))
+	return scriptFile
+}
+
+func TestCapsuleManager_Start_InvalidHandshakeFailsFast(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		errMsg string
+	}{
+		{
+			// Garbage, then nothing: without early detection this hangs until the startup timeout
+			name:   "not JSON",
+			output: `Starting server on port 8080...`,
+			errMsg: "failed to parse JSON",
+		},
+		{
+			name:   "not a handshake",
+			output: `{"jsonrpc":"2.0","method":"log","params":{}}`,
+			errMsg: `expected method orla.hello, got "log"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scriptPath := createCapsuleScript(t, "garbage-capsule.sh", "#!/bin/sh\necho '"+tt.output+"'\nexec sleep 30\n")
+
+			fakeClock := clockwork.NewFakeClock()
+			tool := &ToolManifest{
+				Name: "garbage-tool",
+				Path: scriptPath,
+				Runtime: &RuntimeConfig{
+					StartupTimeoutMs: 60000,
+				},
+			}
+
+			cm := NewCapsuleManagerWithClock(tool, fakeClock)
+
+			// The fake clock is never advanced, so only the invalid output can end the startup
+			done := make(chan error, 1)
+			go func() {
+				done <- cm.Start()
+			}()
+
+			var startErr error
+			select {
+			case startErr = <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Start did not fail on the invalid handshake")
+			}
+
+			require.Error(t, startErr)
+			var invalidErr *InvalidHandshakeError
+			require.ErrorAs(t, startErr, &invalidErr)
+			assert.Equal(t, tt.output, invalidErr.Output)
+			assert.Contains(t, startErr.Error(), tt.errMsg)
+			assert.NotContains(t, startErr.Error(), "handshake timeout")
+			assert.Equal(t, CapsuleStateCrashed, cm.GetState())
+		})
+	}
+}
+
+func TestCapsuleManager_Start_MultiLineHandshake(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	// The handshake is decoded as JSON, so it may be pretty-printed over several lines
+	scriptPath := createCapsuleScript(t, "pretty-capsule.sh", `#!/bin/sh
+echo '{'
+echo '  "jsonrpc": "2.0",'
+echo '  "method": "orla.hello",'
+echo '  "params": {"name": "pretty-tool", "version": "1.0.0", "capabilities": ["tools"]}'
+echo '}'
+cat
+`)
+
+	cm := NewCapsuleManager(&ToolManifest{Name: "pretty-tool", Path: scriptPath})
+
+	require.NoError(t, cm.Start())
+	assert.True(t, cm.IsReady())
+	assert.True(t, cm.HasCapability("tools"))
+
+	// Cleanup
+	_ = cm.Stop() //nolint:errcheck // cleanup in test
+}
+
+func TestParseHandshake(t *testing.T) {
+	notification, err := parseHandshake([]byte(`{"jsonrpc":"2.0","method":"orla.hello","params":{"name":"t","version":"1.0.0"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "t", notification.Params.Name)
+
+	_, err = parseHandshake([]byte(`{"jsonrpc":"2.0","method":"log","params":{}}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `expected method orla.hello, got "log"`)
+
+	_, err = parseHandshake([]byte("not json"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse JSON")
+}
+
+func TestTruncateHandshakeOutput(t *testing.T) {
+	assert.Equal(t, "short", truncateHandshakeOutput([]byte("short")))
+
+	long := make([]byte, maxHandshakeOutputLength+10)
+	for i := range long {
+		long[i] = 'x'
+	}
+	truncated := truncateHandshakeOutput(long)
+	assert.Len(t, truncated, maxHandshakeOutputLength+len("..."))
+}
+
 func TestCapsuleManager_Start_InvalidPath(t *testing.T) {
 	tool := &ToolManifest{
 		Name: "test-tool",
@@ -454,6 +577,48 @@ func TestCapsuleManager_Start_WithRuntimeEnv(t *testing.T) {
 	_ = cm.Stop() //nolint:errcheck // cleanup in test
 }
 
+func TestCapsuleManager_Start_WithEnvAllowlist(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	t.Setenv("ORLA_TEST_ALLOWED", "allowed-value")
+	t.Setenv("ORLA_TEST_SECRET", "secret-value")
+
+	tmpDir := t.TempDir()
+	envFile := filepath.Join(tmpDir, "env.txt")
+	scriptContent := `#!/bin/sh
+/usr/bin/env > "$ENV_FILE"
+echo '{"jsonrpc":"2.0","method":"orla.hello","params":{"name":"test-tool","version":"1.0.0","capabilities":["tools"]}}'
+while IFS= read -r line; do :; done
+`
+	scriptPath := filepath.Join(tmpDir, "env-capsule.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(scriptPath, []byte(scriptContent), 0755))
+
+	tool := &ToolManifest{
+		Name:           "test-tool",
+		Version:        "1.0.0",
+		Description:    "Test tool",
+		Path:           scriptPath,
+		Env:            map[string]string{"ENV_FILE": envFile},
+		EnvPassthrough: []string{"ORLA_TEST_ALLOWED"},
+		Runtime: &RuntimeConfig{
+			StartupTimeoutMs: 5000,
+		},
+	}
+
+	cm := NewCapsuleManager(tool)
+	require.NoError(t, cm.Start())
+	defer func() { _ = cm.Stop() }() //nolint:errcheck // cleanup in test
+
+	// #nosec G304 -- reading test file from temp directory
+	output, err := os.ReadFile(envFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "ORLA_TEST_ALLOWED=allowed-value")
+	assert.NotContains(t, string(output), "ORLA_TEST_SECRET")
+}
+
 // Test helper: create a capsule script that responds to JSON-RPC calls
 func createRespondingCapsuleScript(t *testing.T) string {
 	t.Helper()
@@ -529,6 +694,43 @@ func TestCapsuleManager_CallTool_Success(t *testing.T) {
 	_ = cm.Stop() //nolint:errcheck // cleanup in test
 }
 
+func TestCapsuleManager_CallTool_OutputLimit(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	// Answers every call with a response of over 10000 bytes
+	scriptPath := createCapsuleScript(t, "large-capsule.sh", `#!/bin/sh
+echo '{"jsonrpc":"2.0","method":"orla.hello","params":{"name":"large-tool","version":"1.0.0","capabilities":["tools"]}}'
+while IFS= read -r line; do
+  printf '{"jsonrpc":"2.0","id":1,"result":{"output":"'
+  head -c 10000 /dev/zero | tr '\0' x
+  echo '"}}'
+done
+`)
+
+	cm := NewCapsuleManager(&ToolManifest{Name: "large-tool", Path: scriptPath})
+	cm.SetMaxOutputBytes(1024)
+	require.NoError(t, cm.Start())
+	t.Cleanup(func() {
+		_ = cm.Stop() //nolint:errcheck // cleanup in test
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := cm.CallTool(ctx, map[string]any{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "larger than the output limit of 1024 bytes")
+
+	// The capsule is killed, so that its supervisor restarts it
+	select {
+	case <-cm.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Capsule was not killed")
+	}
+	assert.True(t, cm.Crashed())
+}
+
 func TestCapsuleManager_CallTool_JSONRPCError(t *testing.T) {
 	if runtime.GOOS == windowsOS {
 		t.Skip("Windows capsule script tests not implemented")
@@ -716,3 +918,93 @@ func TestOrlaHelloNotification_Unmarshal(t *testing.T) {
 	assert.Equal(t, "1.0.0", notification.Params.Version)
 	assert.Equal(t, []string{"tools", "resources"}, notification.Params.Capabilities)
 }
+
+func TestCapsuleManager_Crashed(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	// The capsule answers one request and exits
+	scriptPath := createCapsuleScript(t, "exiting-capsule.sh", `#!/bin/sh
+echo '{"jsonrpc":"2.0","method":"orla.hello","params":{"name":"test-tool","version":"1.0.0","capabilities":["tools"]}}'
+IFS= read -r line
+REQ_ID=$(echo "$line" | sed -n 's/.*"id":\([0-9]*\).*/\1/p')
+echo "{\"jsonrpc\":\"2.0\",\"id\":$REQ_ID,\"result\":{\"output\":\"last result\"}}"
+exit 1
+`)
+	cm := NewCapsuleManager(&ToolManifest{Name: "test-tool", Path: scriptPath})
+	assert.Nil(t, cm.Done())
+	require.NoError(t, cm.Start())
+	t.Cleanup(func() { _ = cm.Stop() }) //nolint:errcheck // cleanup in test
+
+	// The response written just before the process exits is still returned
+	response, err := cm.CallTool(context.Background(), map[string]any{})
+	require.NoError(t, err)
+	resultMap, ok := response.Result.(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "last result", resultMap["output"])
+
+	select {
+	case <-cm.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("capsule process did not exit")
+	}
+	assert.True(t, cm.Crashed())
+	assert.Equal(t, CapsuleStateCrashed, cm.GetState())
+
+	_, err = cm.CallTool(context.Background(), map[string]any{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "capsule is not ready")
+}
+
+func TestCapsuleManager_CallTool_ProcessExits(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	// The capsule exits without answering
+	scriptPath := createCapsuleScript(t, "exiting-capsule.sh", `#!/bin/sh
+echo '{"jsonrpc":"2.0","method":"orla.hello","params":{"name":"test-tool","version":"1.0.0","capabilities":["tools"]}}'
+IFS= read -r line
+exit 1
+`)
+	cm := NewCapsuleManager(&ToolManifest{Name: "test-tool", Path: scriptPath})
+	require.NoError(t, cm.Start())
+	t.Cleanup(func() { _ = cm.Stop() }) //nolint:errcheck // cleanup in test
+
+	// The call fails when the process exits rather than waiting for its context
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	_, err := cm.CallTool(ctx, map[string]any{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "capsule process exited before responding")
+	assert.NoError(t, ctx.Err())
+}
+
+func TestCapsuleManager_Stop_NotCrashed(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	cm := NewCapsuleManager(&ToolManifest{Name: "test-tool", Path: createRespondingCapsuleScript(t)})
+	require.NoError(t, cm.Start())
+	require.NoError(t, cm.Stop())
+
+	<-cm.Done()
+	assert.False(t, cm.Crashed())
+	assert.Equal(t, CapsuleStateStopped, cm.GetState())
+}
+
+func TestCapsuleManager_Start_ExitBeforeHandshake(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	scriptPath := createCapsuleScript(t, "exiting-capsule.sh", "#!/bin/sh\nexit 1\n")
+	cm := NewCapsuleManager(&ToolManifest{Name: "test-tool", Path: scriptPath})
+
+	err := cm.Start()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "capsule exited before the handshake")
+	assert.Equal(t, CapsuleStateCrashed, cm.GetState())
+}
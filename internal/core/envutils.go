@@ -1,6 +1,9 @@
 package core
 
-import "os"
+import (
+	"os"
+	"strings"
+)
 
 // GetEnv retrieves an environment variable, checking both the standard name
 // and an ORLA-prefixed version. Returns the first non-empty value found.
@@ -13,3 +16,22 @@ func GetEnv(key string) string {
 	// Check ORLA-prefixed version
 	return os.Getenv("ORLA_" + key)
 }
+
+// MetaEnvPrefix prefixes the environment variables that pass MCP _meta fields to tools
+const MetaEnvPrefix = "ORLA_META_"
+
+// MetaEnvName returns the environment variable that passes the _meta field key to a tool. The key
+// is upper-cased and every character other than a letter or digit becomes "_", so "trace-id" is
+// passed as ORLA_META_TRACE_ID.
+func MetaEnvName(key string) string {
+	var name strings.Builder
+	name.WriteString(MetaEnvPrefix)
+	for _, r := range strings.ToUpper(key) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			name.WriteRune(r)
+		} else {
+			name.WriteRune('_')
+		}
+	}
+	return name.String()
+}
@@ -0,0 +1,25 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetaEnvName(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{key: "traceparent", expected: "ORLA_META_TRACEPARENT"},
+		{key: "trace-id", expected: "ORLA_META_TRACE_ID"},
+		{key: "tenantId", expected: "ORLA_META_TENANTID"},
+		{key: "example.com/request.id", expected: "ORLA_META_EXAMPLE_COM_REQUEST_ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MetaEnvName(tt.key))
+		})
+	}
+}
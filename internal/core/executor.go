@@ -2,16 +2,22 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/jonboulle/clockwork"
+	"go.uber.org/zap"
 )
 
 // CommandRunner is an interface for running commands, allowing for testing with mocks
@@ -26,6 +32,7 @@ type Command interface {
 	StderrPipe() (io.ReadCloser, error)
 	SetStdin(io.Reader)
 	SetEnv([]string)
+	SetDir(string)
 	Start() error
 	Wait() error
 }
@@ -43,13 +50,22 @@ func (e *execCommand) SetEnv(env []string) {
 	e.Env = env
 }
 
+func (e *execCommand) SetDir(dir string) {
+	e.Dir = dir
+}
+
 // Explicitly forward methods from *exec.Cmd to satisfy the Command interface
 // (even though they're already available through embedding, this makes it explicit for the linter)
 func (e *execCommand) Start() error {
-	return e.Cmd.Start()
+	if err := e.Cmd.Start(); err != nil {
+		return err
+	}
+	runningTools.Store(e.Cmd, struct{}{})
+	return nil
 }
 
 func (e *execCommand) Wait() error {
+	defer runningTools.Delete(e.Cmd)
 	return e.Cmd.Wait()
 }
 
@@ -71,8 +87,12 @@ var _ Command = &execCommand{}
 // execCommandRunner wraps exec.CommandContext to implement CommandRunner
 type execCommandRunner struct{}
 
+// CommandContext returns a command that is killed together with the processes it spawns when ctx
+// is done, e.g. when the tool times out
 func (e *execCommandRunner) CommandContext(ctx context.Context, name string, arg ...string) Command {
-	return &execCommand{Cmd: exec.CommandContext(ctx, name, arg...)}
+	cmd := exec.CommandContext(ctx, name, arg...)
+	killProcessGroupOnCancel(cmd)
+	return &execCommand{Cmd: cmd}
 }
 
 // Interface guard for execCommandRunner
@@ -80,9 +100,11 @@ var _ CommandRunner = &execCommandRunner{}
 
 // OrlaToolExecutor handles tool execution
 type OrlaToolExecutor struct {
-	timeout       time.Duration
-	clock         clockwork.Clock
-	commandRunner CommandRunner
+	timeout        time.Duration
+	clock          clockwork.Clock
+	commandRunner  CommandRunner
+	maxOutputBytes int64  // limit on each of stdout and stderr of a tool run, 0 for none
+	workingDir     string // directory tools without a working_dir run in, orla's working directory if empty
 }
 
 // NewOrlaToolExecutor creates a new tool executor with a real clock
@@ -110,6 +132,63 @@ func NewOrlaToolExecutorWithClockAndRunner(timeoutSeconds int, clock clockwork.C
 	}
 }
 
+// SetMaxOutputBytes limits how much a tool run may write to each of stdout and stderr. A tool
+// that writes more is stopped and fails with an *OutputLimitError, and its output is truncated
+// at the limit. A limit of 0 or less removes it.
+func (e *OrlaToolExecutor) SetMaxOutputBytes(limit int64) {
+	e.maxOutputBytes = max(limit, 0)
+}
+
+// MaxOutputBytes returns the limit on each of stdout and stderr of a tool run, 0 if there is none
+func (e *OrlaToolExecutor) MaxOutputBytes() int64 {
+	return e.maxOutputBytes
+}
+
+// SetWorkingDir sets the directory that tools whose manifest sets no working_dir run in. If dir
+// is empty, they run in orla's working directory.
+func (e *OrlaToolExecutor) SetWorkingDir(dir string) {
+	e.workingDir = dir
+}
+
+// WorkingDirFor returns the directory the tool runs in: its working_dir, or else the executor's
+// working directory. It is empty if the tool runs in orla's working directory.
+func (e *OrlaToolExecutor) WorkingDirFor(tool *ToolManifest) string {
+	if tool.WorkingDir != "" {
+		return tool.WorkingDir
+	}
+	return e.workingDir
+}
+
+// TraceCommand describes the command the executor runs for tool with the given arguments, like
+// TraceCommand, including the executor's working directory
+func (e *OrlaToolExecutor) TraceCommand(tool *ToolManifest, args []string) *CommandTrace {
+	trace := TraceCommand(tool, args)
+	if dir := e.WorkingDirFor(tool); dir != "" {
+		trace.Dir = dir
+	}
+	return trace
+}
+
+// OutputLimitError is returned for a tool run that wrote more than the executor's output limit to
+// stdout or stderr
+type OutputLimitError struct {
+	Stream string // "stdout" or "stderr"
+	Limit  int64
+}
+
+func (e *OutputLimitError) Error() string {
+	return fmt.Sprintf("tool %s exceeded the output limit of %d bytes, the tool was stopped and its output truncated", e.Stream, e.Limit)
+}
+
+// TimeoutFor returns the timeout of a tool run: the tool's timeout_seconds if set, otherwise the
+// executor's timeout
+func (e *OrlaToolExecutor) TimeoutFor(tool *ToolManifest) time.Duration {
+	if tool.TimeoutSeconds > 0 {
+		return time.Duration(tool.TimeoutSeconds) * time.Second
+	}
+	return e.timeout
+}
+
 // OrlaToolExecutionResult represents the result of a tool execution
 type OrlaToolExecutionResult struct {
 	Stdout   string `json:"stdout"`
@@ -120,42 +199,90 @@ type OrlaToolExecutionResult struct {
 
 // Execute executes a tool with the given arguments and input
 func (e *OrlaToolExecutor) Execute(ctx context.Context, tool *ToolManifest, args []string, stdin string) (*OrlaToolExecutionResult, error) {
-	// Create context with timeout using the clock
-	execCtx, cancel := clockwork.WithTimeout(ctx, e.clock, e.timeout)
-	defer cancel()
+	var stdinReader io.Reader
+	if stdin != "" {
+		stdinReader = strings.NewReader(stdin)
+	}
+	return e.ExecuteWithStdin(ctx, tool, args, stdinReader)
+}
 
-	// Build command with runtime args appended
-	var cmd Command
-	allArgs := args
+// VirtualToolShell is the shell that runs the inline command of a tool defined in config
+const VirtualToolShell = "/bin/sh"
+
+// resolveCommand returns the program and arguments used to run a tool with the given arguments,
+// with the tool's runtime args appended
+func resolveCommand(tool *ToolManifest, args []string) (string, []string) {
+	allArgs := slices.Clone(args)
 	if tool.Runtime != nil && len(tool.Runtime.Args) > 0 {
-		allArgs = append(args, tool.Runtime.Args...)
+		allArgs = append(allArgs, tool.Runtime.Args...)
+	}
+
+	if IsPackageTool(tool) {
+		// Package run by its package manager, e.g. npx -y <package> <args>
+		return packageCommand(tool, allArgs)
+	}
+
+	if tool.Command != "" {
+		// Inline command, with the tool name as $0 and the arguments as $1, $2, ...
+		return VirtualToolShell, append([]string{"-c", tool.Command, tool.Name}, allArgs...)
 	}
 
 	if tool.Interpreter != "" {
 		// Script with interpreter
-		cmdArgs := []string{tool.Path}
-		cmdArgs = append(cmdArgs, allArgs...)
-		cmd = e.commandRunner.CommandContext(execCtx, tool.Interpreter, cmdArgs...)
-	} else {
-		// Binary executable
-		cmd = e.commandRunner.CommandContext(execCtx, tool.Path, allArgs...)
+		return tool.Interpreter, append([]string{tool.Path}, allArgs...)
 	}
 
-	// Set environment variables if specified
-	if tool.Runtime != nil && len(tool.Runtime.Env) > 0 {
-		// Get current environment
-		env := os.Environ()
-		// Add runtime environment variables (will override existing ones)
-		for key, value := range tool.Runtime.Env {
-			env = append(env, fmt.Sprintf("%s=%s", key, value))
+	// Binary executable
+	return tool.Path, allArgs
+}
+
+// ExecuteWithStdin executes a tool with the given arguments, streaming stdin (if non-nil) to the process
+func (e *OrlaToolExecutor) ExecuteWithStdin(ctx context.Context, tool *ToolManifest, args []string, stdin io.Reader) (*OrlaToolExecutionResult, error) {
+	return e.ExecuteStreaming(ctx, tool, args, nil, stdin, nil)
+}
+
+// ExecuteStreaming executes a tool like ExecuteWithStdin with the extra environment variables
+// callEnv, and also writes its stdout to stdoutStream (if non-nil) as it is produced, one line per
+// write for streamable tools. The full stdout is still returned in the result. Write errors from
+// stdoutStream are ignored so that a slow or gone consumer cannot fail the tool.
+func (e *OrlaToolExecutor) ExecuteStreaming(ctx context.Context, tool *ToolManifest, args []string, callEnv map[string]string, stdin io.Reader, stdoutStream io.Writer) (*OrlaToolExecutionResult, error) {
+	// Create context with timeout using the clock
+	timeout := e.TimeoutFor(tool)
+	execCtx, cancel := clockwork.WithTimeout(ctx, e.clock, timeout)
+	defer cancel()
+
+	env := toolEnv(tool, callEnv)
+
+	// Build command with runtime args appended
+	name, cmdArgs := resolveCommand(tool, args)
+	if tool.Interpreter != "" {
+		interpreter, err := resolveInterpreter(tool, env)
+		if err != nil {
+			return nil, err
 		}
-		// Set environment on command
+		name = interpreter
+	}
+	if IsPackageTool(tool) {
+		packageManager, err := resolvePackageManager(tool, env)
+		if err != nil {
+			return nil, err
+		}
+		name = packageManager
+	}
+	cmd := e.commandRunner.CommandContext(execCtx, name, cmdArgs...)
+
+	// Set environment variables if specified
+	if env != nil {
 		cmd.SetEnv(env)
 	}
 
+	if dir := e.WorkingDirFor(tool); dir != "" {
+		cmd.SetDir(dir)
+	}
+
 	// Set up stdin
-	if stdin != "" {
-		cmd.SetStdin(strings.NewReader(stdin))
+	if stdin != nil {
+		cmd.SetStdin(stdin)
 	}
 
 	// Capture stdout and stderr
@@ -175,17 +302,33 @@ func (e *OrlaToolExecutor) Execute(ctx context.Context, tool *ToolManifest, args
 		return nil, fmt.Errorf("failed to start command: %w", err)
 	}
 
-	// Read output
-	var stdoutBuf, stderrBuf strings.Builder
+	// Read output. A tool that writes more than the output limit is killed rather than buffered,
+	// and both pipes are closed so that reading ends even if a child of the tool still holds them.
+	stopOutput := sync.OnceFunc(func() {
+		cancel()
+		LogDeferredError(stdout.Close)
+		LogDeferredError(stderr.Close)
+	})
+	stdoutBuf := &limitedBuffer{limit: e.maxOutputBytes, onExceed: stopOutput}
+	stderrBuf := &limitedBuffer{limit: e.maxOutputBytes, onExceed: stopOutput}
 	done := make(chan error, 2)
 
 	go func() {
-		_, copyErr := io.Copy(&stdoutBuf, stdout)
+		var stdoutWriter io.Writer = stdoutBuf
+		switch {
+		case stdoutStream != nil && tool.Streamable:
+			lines := &lineWriter{w: stdoutStream}
+			defer lines.Flush()
+			stdoutWriter = io.MultiWriter(stdoutBuf, lines)
+		case stdoutStream != nil:
+			stdoutWriter = io.MultiWriter(stdoutBuf, ignoreWriteErrors{stdoutStream})
+		}
+		_, copyErr := io.Copy(stdoutWriter, stdout)
 		done <- copyErr
 	}()
 
 	go func() {
-		_, copyErr := io.Copy(&stderrBuf, stderr)
+		_, copyErr := io.Copy(stderrBuf, stderr)
 		done <- copyErr
 	}()
 
@@ -202,11 +345,26 @@ func (e *OrlaToolExecutor) Execute(ctx context.Context, tool *ToolManifest, args
 		ExitCode: 0,
 	}
 
+	// A tool killed for writing too much fails with the limit rather than with how it was killed
+	switch {
+	case stdoutBuf.exceeded:
+		result.Error = &OutputLimitError{Stream: "stdout", Limit: e.maxOutputBytes}
+		return result, result.Error
+	case stderrBuf.exceeded:
+		result.Error = &OutputLimitError{Stream: "stderr", Limit: e.maxOutputBytes}
+		return result, result.Error
+	}
+
 	if err != nil {
 		var exitError *exec.ExitError
-		if errors.As(err, &exitError) {
+		switch {
+		case errors.As(err, &exitError):
 			result.ExitCode = exitError.ExitCode()
-		} else {
+		case stdin != nil && isClosedStdinError(err):
+			// The tool exited without reading all of its stdin, e.g. because it only needed the first
+			// line. Wait reports this only if the tool otherwise succeeded, so it is not a failure.
+			zap.L().Debug("Tool exited before reading all of stdin", zap.String("tool", tool.Name), zap.Error(err))
+		default:
 			result.Error = err
 			return result, err
 		}
@@ -214,9 +372,142 @@ func (e *OrlaToolExecutor) Execute(ctx context.Context, tool *ToolManifest, args
 
 	// Check for context timeout
 	if execCtx.Err() == context.DeadlineExceeded {
-		result.Error = fmt.Errorf("tool execution timed out after %v", e.timeout)
+		result.Error = fmt.Errorf("tool execution timed out after %v", timeout)
 		return result, result.Error
 	}
 
 	return result, nil
 }
+
+// toolEnv returns the environment of a tool process, or nil to inherit orla's environment
+// unchanged. A tool that declares env or env_passthrough starts from only the passed through host
+// variables, otherwise it starts from orla's environment. Variables for the call are added next,
+// followed by the tool's declared variables, which take precedence over them.
+func toolEnv(tool *ToolManifest, callEnv map[string]string) []string {
+	restricted := hasEnvAllowlist(tool)
+	declared := declaredEnv(tool)
+	if !restricted && len(callEnv) == 0 && len(declared) == 0 {
+		return nil
+	}
+
+	// Later entries override earlier ones, including those of the current environment
+	env := os.Environ()
+	if restricted {
+		env = passthroughEnv(tool.EnvPassthrough)
+	}
+	for key, value := range callEnv {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+	for key, value := range declared {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+	return env
+}
+
+// hasEnvAllowlist reports whether a tool restricts its environment to the variables it declares
+func hasEnvAllowlist(tool *ToolManifest) bool {
+	return len(tool.Env) > 0 || len(tool.EnvPassthrough) > 0
+}
+
+// declaredEnv returns the environment variables a tool sets for itself: its env, overridden by
+// its runtime env
+func declaredEnv(tool *ToolManifest) map[string]string {
+	var runtimeEnv map[string]string
+	if tool.Runtime != nil {
+		runtimeEnv = tool.Runtime.Env
+	}
+	if len(tool.Env) == 0 {
+		return runtimeEnv
+	}
+	env := maps.Clone(tool.Env)
+	maps.Copy(env, runtimeEnv)
+	return env
+}
+
+// passthroughEnv returns the host environment variables named by keys that are set, as KEY=VALUE
+// entries. The result is never nil, so that a tool passing nothing through gets an empty environment.
+func passthroughEnv(keys []string) []string {
+	env := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if value, ok := os.LookupEnv(key); ok {
+			env = append(env, fmt.Sprintf("%s=%s", key, value))
+		}
+	}
+	return env
+}
+
+// isClosedStdinError reports whether err is a failure to write a tool's stdin because the tool
+// closed it or exited
+func isClosedStdinError(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrClosedPipe) || errors.Is(err, os.ErrClosed)
+}
+
+// ignoreWriteErrors wraps a writer and reports every write as successful
+type ignoreWriteErrors struct {
+	w io.Writer
+}
+
+func (i ignoreWriteErrors) Write(p []byte) (int, error) {
+	_, _ = i.w.Write(p) //nolint:errcheck // the stream is best effort, the result holds the full output
+	return len(p), nil
+}
+
+// maxStreamLineBytes is the longest partial line a lineWriter holds back before passing it on
+const maxStreamLineBytes = 64 << 10
+
+// lineWriter passes what is written to it on to w one complete line per write, so that each
+// streamed chunk of a streamable tool's output is a whole line. Output that does not end in a
+// newline is held until the line completes, grows past maxStreamLineBytes, or Flush is called.
+type lineWriter struct {
+	w       io.Writer
+	pending []byte
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	l.pending = append(l.pending, p...)
+	for {
+		i := bytes.IndexByte(l.pending, '\n')
+		if i < 0 {
+			break
+		}
+		_, _ = l.w.Write(l.pending[:i+1]) //nolint:errcheck // the stream is best effort, the result holds the full output
+		l.pending = l.pending[i+1:]
+	}
+	if len(l.pending) >= maxStreamLineBytes {
+		l.Flush()
+	}
+	return len(p), nil
+}
+
+// Flush passes on the held back partial line, if any
+func (l *lineWriter) Flush() {
+	if len(l.pending) > 0 {
+		_, _ = l.w.Write(l.pending) //nolint:errcheck // the stream is best effort, the result holds the full output
+		l.pending = nil
+	}
+}
+
+// errOutputLimit stops copying the output of a tool that exceeded the output limit
+var errOutputLimit = errors.New("output limit exceeded")
+
+// limitedBuffer buffers up to limit bytes of a tool's output, or all of it if limit is 0. The
+// write that exceeds the limit is truncated to fit, calls onExceed, and fails with errOutputLimit.
+type limitedBuffer struct {
+	strings.Builder
+	limit    int64
+	exceeded bool
+	onExceed func()
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit <= 0 || int64(b.Len())+int64(len(p)) <= b.limit {
+		return b.Builder.Write(p)
+	}
+
+	n, _ := b.Builder.Write(p[:b.limit-int64(b.Len())]) //nolint:errcheck // strings.Builder never fails
+	if !b.exceeded {
+		b.exceeded = true
+		b.onExceed()
+	}
+	return n, errOutputLimit
+}
@@ -1,7 +1,9 @@
 package core
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -9,6 +11,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -96,6 +99,45 @@ func TestExecute_PipeErrors(t *testing.T) {
 	// without mocking, but the code handles them correctly
 }
 
+// failingWriter fails every write
+type failingWriter struct {
+	writes int
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	f.writes++
+	return 0, errors.New("client went away")
+}
+
+func TestExecuteStreaming(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping shell test on Windows")
+	}
+
+	executor := NewOrlaToolExecutor(10)
+	tool := &ToolManifest{
+		Name:        "printer",
+		Path:        "/bin/echo",
+		Interpreter: "",
+	}
+
+	t.Run("stdout is written to the stream", func(t *testing.T) {
+		var stream bytes.Buffer
+		result, err := executor.ExecuteStreaming(context.Background(), tool, []string{"streamed"}, nil, nil, &stream)
+		require.NoError(t, err)
+		assert.Equal(t, "streamed\n", result.Stdout)
+		assert.Equal(t, "streamed\n", stream.String())
+	})
+
+	t.Run("a failing stream does not lose output", func(t *testing.T) {
+		stream := &failingWriter{}
+		result, err := executor.ExecuteStreaming(context.Background(), tool, []string{"buffered"}, nil, nil, stream)
+		require.NoError(t, err)
+		assert.Equal(t, "buffered\n", result.Stdout)
+		assert.Positive(t, stream.writes)
+	})
+}
+
 // TestExecute_ScriptWithInterpreter tests successful execution of a script with interpreter
 func TestExecute_ScriptWithInterpreter(t *testing.T) {
 	if runtime.GOOS == windowsOS {
@@ -127,6 +169,40 @@ func TestExecute_ScriptWithInterpreter(t *testing.T) {
 	assert.Nil(t, result.Error)
 }
 
+// TestExecute_WorkingDir tests that a tool runs in its working_dir, or else in the executor's
+// working directory
+func TestExecute_WorkingDir(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping pwd test on Windows")
+	}
+
+	tmpDir := t.TempDir()
+	scriptPath := filepath.Join(tmpDir, "pwd.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(scriptPath, []byte("#!/bin/sh\npwd -P\n"), 0755))
+
+	// Resolve symlinks, as pwd -P does, e.g. for /tmp on macOS
+	toolDir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	serverDir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	executor := NewOrlaToolExecutor(10)
+	executor.SetWorkingDir(serverDir)
+
+	tool := &ToolManifest{Name: "pwd", Path: scriptPath, Interpreter: "/bin/sh"}
+	result, err := executor.Execute(context.Background(), tool, nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, serverDir, strings.TrimSpace(result.Stdout))
+	assert.Equal(t, serverDir, executor.TraceCommand(tool, nil).Dir)
+
+	tool.WorkingDir = toolDir
+	result, err = executor.Execute(context.Background(), tool, nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, toolDir, strings.TrimSpace(result.Stdout))
+	assert.Equal(t, toolDir, executor.TraceCommand(tool, nil).Dir)
+}
+
 // TestExecute_WithArgs tests execution with command-line arguments
 func TestExecute_WithArgs(t *testing.T) {
 	if runtime.GOOS == windowsOS {
@@ -278,6 +354,138 @@ func TestExecute_WithEnvironmentVariables(t *testing.T) {
 	assert.Contains(t, result.Stdout, "test-value")
 }
 
+func TestExecuteStreaming_CallEnv(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping shell test on Windows")
+	}
+
+	executor := NewOrlaToolExecutor(10)
+
+	tmpDir := t.TempDir()
+	scriptPath := filepath.Join(tmpDir, "test-script.sh")
+	scriptContent := "#!/bin/sh\necho \"$CALL_VAR $SHARED_VAR\"\n"
+
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(scriptPath, []byte(scriptContent), 0755))
+
+	tool := &ToolManifest{
+		Name:        "test-script",
+		Path:        scriptPath,
+		Interpreter: "/bin/sh",
+		Runtime: &RuntimeConfig{
+			Env: map[string]string{
+				"SHARED_VAR": "from-runtime",
+			},
+		},
+	}
+
+	callEnv := map[string]string{
+		"CALL_VAR":   "from-call",
+		"SHARED_VAR": "from-call",
+	}
+	result, err := executor.ExecuteStreaming(context.Background(), tool, []string{}, callEnv, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "from-call from-runtime\n", result.Stdout, "runtime env should take precedence over call env")
+}
+
+// TestExecute_EnvAllowlist tests that a tool declaring env or env_passthrough receives only the
+// allowlisted host variables and its declared variables
+func TestExecute_EnvAllowlist(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping environment test on Windows")
+	}
+
+	t.Setenv("ORLA_TEST_ALLOWED", "allowed-value")
+	t.Setenv("ORLA_TEST_SECRET", "secret-value")
+
+	executor := NewOrlaToolExecutor(10)
+
+	tmpDir := t.TempDir()
+	scriptPath := filepath.Join(tmpDir, "test-script.sh")
+	scriptContent := "#!/bin/sh\n/usr/bin/env\n"
+
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(scriptPath, []byte(scriptContent), 0755))
+
+	tool := &ToolManifest{
+		Name:           "test-script",
+		Path:           scriptPath,
+		Interpreter:    "/bin/sh",
+		Env:            map[string]string{"DECLARED_VAR": "declared-value", "SHARED_VAR": "from-env"},
+		EnvPassthrough: []string{"ORLA_TEST_ALLOWED", "ORLA_TEST_UNSET"},
+		Runtime: &RuntimeConfig{
+			Env: map[string]string{"SHARED_VAR": "from-runtime"},
+		},
+	}
+
+	callEnv := map[string]string{"ORLA_META_TRACE_ID": "abc123"}
+	result, err := executor.ExecuteStreaming(context.Background(), tool, []string{}, callEnv, nil, nil)
+	require.NoError(t, err)
+
+	env := envMap(result.Stdout)
+	assert.Equal(t, "allowed-value", env["ORLA_TEST_ALLOWED"])
+	assert.Equal(t, "declared-value", env["DECLARED_VAR"])
+	assert.Equal(t, "from-runtime", env["SHARED_VAR"], "runtime env should take precedence over env")
+	assert.Equal(t, "abc123", env["ORLA_META_TRACE_ID"], "call env should still be passed")
+	assert.NotContains(t, env, "ORLA_TEST_SECRET", "host variables that are not passed through should not leak")
+	assert.NotContains(t, env, "ORLA_TEST_UNSET")
+	assert.NotContains(t, env, "HOME")
+}
+
+// TestExecute_EnvPassthroughOnly tests that a tool passing through no set variables and declaring
+// none of its own gets an empty environment rather than orla's
+func TestExecute_EnvPassthroughOnly(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping environment test on Windows")
+	}
+
+	t.Setenv("ORLA_TEST_SECRET", "secret-value")
+
+	executor := NewOrlaToolExecutor(10)
+
+	tool := &ToolManifest{
+		Name:           "env",
+		Path:           "/usr/bin/env",
+		EnvPassthrough: []string{"ORLA_TEST_UNSET"},
+	}
+
+	result, err := executor.Execute(context.Background(), tool, []string{}, "")
+	require.NoError(t, err)
+	assert.Empty(t, result.Stdout)
+}
+
+// TestExecute_FullEnvByDefault tests that a tool that declares no env allowlist inherits orla's
+// environment
+func TestExecute_FullEnvByDefault(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping environment test on Windows")
+	}
+
+	t.Setenv("ORLA_TEST_INHERITED", "inherited-value")
+
+	executor := NewOrlaToolExecutor(10)
+
+	tool := &ToolManifest{
+		Name: "env",
+		Path: "/usr/bin/env",
+	}
+
+	result, err := executor.Execute(context.Background(), tool, []string{}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "inherited-value", envMap(result.Stdout)["ORLA_TEST_INHERITED"])
+}
+
+// envMap parses the output of env into a map
+func envMap(output string) map[string]string {
+	env := make(map[string]string)
+	for line := range strings.Lines(output) {
+		if key, value, ok := strings.Cut(strings.TrimSuffix(line, "\n"), "="); ok {
+			env[key] = value
+		}
+	}
+	return env
+}
+
 // TestExecCommand_SetEnv tests that SetEnv properly sets environment variables
 func TestExecCommand_SetEnv(t *testing.T) {
 	cmd := &execCommand{
@@ -320,6 +528,11 @@ func (e *execCommandWrapper) SetEnv(env []string) {
 	e.Env = env
 }
 
+//nolint:unused // Reserved for future test scenarios
+func (e *execCommandWrapper) SetDir(dir string) {
+	e.Dir = dir
+}
+
 // Explicitly forward methods from *exec.Cmd to satisfy the Command interface
 //
 //nolint:unused // Reserved for future test scenarios
@@ -394,6 +607,10 @@ func (m *timeoutMockCommand) SetEnv(env []string) {
 	// No-op for mock
 }
 
+func (m *timeoutMockCommand) SetDir(dir string) {
+	// No-op for mock
+}
+
 func (m *timeoutMockCommand) Start() error {
 	m.started = true
 	return nil
@@ -458,6 +675,53 @@ func TestExecute_Timeout(t *testing.T) {
 	assert.NotNil(t, result.Error)
 }
 
+// TestExecute_ToolTimeoutOverride tests that a tool's timeout_seconds replaces the executor timeout
+func TestExecute_ToolTimeoutOverride(t *testing.T) {
+	fakeClock := clockwork.NewFakeClock()
+	mockRunner := &timeoutMockCommandRunner{}
+	executor := NewOrlaToolExecutorWithClockAndRunner(1, fakeClock, mockRunner) // 1 second timeout
+
+	tool := &ToolManifest{
+		Name:           "slow-tool",
+		Path:           "/fake/path",
+		TimeoutSeconds: 5,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, execErr := executor.Execute(context.Background(), tool, []string{}, "")
+		done <- execErr
+	}()
+
+	blockCtx, blockCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer blockCancel()
+	require.NoError(t, fakeClock.BlockUntilContext(blockCtx, 1), "Failed to block until context has waiters")
+
+	// Past the executor timeout but within the tool's own timeout
+	fakeClock.Advance(2 * time.Second)
+	select {
+	case err := <-done:
+		t.Fatalf("Execution ended before the tool's timeout: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	fakeClock.Advance(4 * time.Second)
+	select {
+	case err := <-done:
+		require.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Execution did not complete after the tool's timeout")
+	}
+}
+
+func TestTimeoutFor(t *testing.T) {
+	executor := NewOrlaToolExecutor(30)
+
+	assert.Equal(t, 30*time.Second, executor.TimeoutFor(&ToolManifest{Name: "default"}))
+	assert.Equal(t, 300*time.Second, executor.TimeoutFor(&ToolManifest{Name: "longer", TimeoutSeconds: 300}))
+	assert.Equal(t, 2*time.Second, executor.TimeoutFor(&ToolManifest{Name: "shorter", TimeoutSeconds: 2}))
+}
+
 // TestExecute_CommandNotFound tests handling of command not found errors
 func TestExecute_CommandNotFound(t *testing.T) {
 	executor := NewOrlaToolExecutor(10)
@@ -554,6 +818,85 @@ func TestExecute_EmptyStdin(t *testing.T) {
 	assert.Contains(t, result.Stdout, "success")
 }
 
+// waitErrorCommand is a command that writes stdout and then fails Wait with a fixed error
+type waitErrorCommand struct {
+	timeoutMockCommand
+	stdout  string
+	waitErr error
+}
+
+func (m *waitErrorCommand) StdoutPipe() (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(m.stdout)), nil
+}
+
+func (m *waitErrorCommand) Wait() error {
+	return m.waitErr
+}
+
+// waitErrorCommandRunner creates a waitErrorCommand
+type waitErrorCommandRunner struct {
+	cmd *waitErrorCommand
+}
+
+func (r *waitErrorCommandRunner) CommandContext(ctx context.Context, name string, arg ...string) Command {
+	r.cmd.ctx = ctx
+	return r.cmd
+}
+
+// TestExecute_StdinClosedByTool tests that a tool exiting before reading all of its stdin is not
+// reported as a failure, since its exit code already tells whether it succeeded
+func TestExecute_StdinClosedByTool(t *testing.T) {
+	tool := &ToolManifest{Name: "head", Path: "/bin/head"}
+
+	for _, waitErr := range []error{
+		&os.PathError{Op: "write", Path: "|0", Err: syscall.EPIPE},
+		io.ErrClosedPipe,
+		os.ErrClosed,
+	} {
+		runner := &waitErrorCommandRunner{cmd: &waitErrorCommand{stdout: "first line\n", waitErr: waitErr}}
+		executor := NewOrlaToolExecutorWithClockAndRunner(10, clockwork.NewRealClock(), runner)
+
+		result, err := executor.Execute(context.Background(), tool, nil, "first line\nsecond line\n")
+		require.NoError(t, err, "waitErr: %v", waitErr)
+		assert.Equal(t, 0, result.ExitCode)
+		assert.NoError(t, result.Error)
+		assert.Equal(t, "first line\n", result.Stdout)
+	}
+
+	// Without stdin a broken pipe is not explained by the tool skipping its input
+	runner := &waitErrorCommandRunner{cmd: &waitErrorCommand{waitErr: io.ErrClosedPipe}}
+	executor := NewOrlaToolExecutorWithClockAndRunner(10, clockwork.NewRealClock(), runner)
+	_, err := executor.Execute(context.Background(), tool, nil, "")
+	require.ErrorIs(t, err, io.ErrClosedPipe)
+
+	// Other failures are still reported
+	runner = &waitErrorCommandRunner{cmd: &waitErrorCommand{waitErr: errors.New("wait failed")}}
+	executor = NewOrlaToolExecutorWithClockAndRunner(10, clockwork.NewRealClock(), runner)
+	_, err = executor.Execute(context.Background(), tool, nil, "input")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "wait failed")
+}
+
+// TestExecute_ToolReadsOneLine tests a real tool that exits after reading one line of a large stdin
+func TestExecute_ToolReadsOneLine(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping shell test on Windows")
+	}
+
+	executor := NewOrlaToolExecutor(10)
+	tool := &ToolManifest{
+		Name:    "first-line",
+		Command: `read line; echo "got $line"`,
+	}
+
+	// The stdin is much larger than a pipe buffer, so writing it fails once the tool exits
+	stdin := "first\n" + strings.Repeat("more input\n", 1<<18)
+	result, err := executor.Execute(context.Background(), tool, nil, stdin)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "got first\n", result.Stdout)
+}
+
 // TestStdinPipe tests that StdinPipe can be called on execCommand
 func TestStdinPipe(t *testing.T) {
 	if runtime.GOOS == windowsOS {
@@ -573,3 +916,112 @@ func TestStdinPipe(t *testing.T) {
 	// Close the pipe
 	require.NoError(t, stdinPipe.Close())
 }
+
+func TestExecute_VirtualTool(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping shell test on Windows")
+	}
+
+	executor := NewOrlaToolExecutor(10)
+	tool := &ToolManifest{
+		Name:    "virtual",
+		Command: `echo "$0 got $*"; cat`,
+	}
+
+	result, err := executor.Execute(context.Background(), tool, []string{"--mode", "fast"}, "from stdin")
+	require.NoError(t, err)
+	assert.Equal(t, "virtual got --mode fast\nfrom stdin", result.Stdout)
+}
+
+func TestLineWriter(t *testing.T) {
+	var writes []string
+	lines := &lineWriter{w: writerFunc(func(p []byte) (int, error) {
+		writes = append(writes, string(p))
+		return len(p), nil
+	})}
+
+	for _, chunk := range []string{"one", " line\ntwo\nthr", "ee\n", "partial"} {
+		n, err := lines.Write([]byte(chunk))
+		require.NoError(t, err)
+		assert.Equal(t, len(chunk), n)
+	}
+	assert.Equal(t, []string{"one line\n", "two\n", "three\n"}, writes)
+
+	lines.Flush()
+	assert.Equal(t, []string{"one line\n", "two\n", "three\n", "partial"}, writes)
+
+	// A line longer than maxStreamLineBytes is passed on without waiting for its end
+	writes = nil
+	_, err := lines.Write(bytes.Repeat([]byte("x"), maxStreamLineBytes))
+	require.NoError(t, err)
+	require.Len(t, writes, 1)
+	assert.Len(t, writes[0], maxStreamLineBytes)
+}
+
+// writerFunc adapts a function to an io.Writer
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+// writeOutputTestScript writes a shell script with the given body and returns a tool that runs it
+func writeOutputTestScript(t *testing.T, body string) *ToolManifest {
+	t.Helper()
+	scriptPath := filepath.Join(t.TempDir(), "output.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(scriptPath, []byte("#!/bin/sh\n"+body+"\n"), 0755))
+	return &ToolManifest{Name: "output-tool", Path: scriptPath}
+}
+
+// TestExecute_MaxOutputBytes tests that a tool writing more than the output limit is stopped
+func TestExecute_MaxOutputBytes(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping shell script test on Windows")
+	}
+
+	executor := NewOrlaToolExecutor(10)
+	executor.SetMaxOutputBytes(1024)
+
+	// The tool would write forever, so it only finishes because it is killed
+	tool := writeOutputTestScript(t, "yes")
+	result, err := executor.Execute(context.Background(), tool, nil, "")
+	var limitErr *OutputLimitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, "stdout", limitErr.Stream)
+	assert.Equal(t, int64(1024), limitErr.Limit)
+	require.NotNil(t, result)
+	assert.Len(t, result.Stdout, 1024)
+	assert.True(t, strings.HasPrefix(result.Stdout, "y\ny\n"))
+	assert.Equal(t, err, result.Error)
+
+	// Stderr has its own limit
+	tool = writeOutputTestScript(t, "yes >&2")
+	result, err = executor.Execute(context.Background(), tool, nil, "")
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, "stderr", limitErr.Stream)
+	assert.Len(t, result.Stderr, 1024)
+	assert.Empty(t, result.Stdout)
+}
+
+// TestExecute_OutputUnderLimit tests that output just under the limit on both streams is kept whole
+func TestExecute_OutputUnderLimit(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping shell script test on Windows")
+	}
+
+	executor := NewOrlaToolExecutor(10)
+	executor.SetMaxOutputBytes(1024)
+
+	tool := writeOutputTestScript(t, "yes | head -c 1023; yes x | head -c 1023 >&2")
+	result, err := executor.Execute(context.Background(), tool, nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Len(t, result.Stdout, 1023)
+	assert.Len(t, result.Stderr, 1023)
+
+	// Without a limit the output is not capped
+	executor.SetMaxOutputBytes(0)
+	tool = writeOutputTestScript(t, "yes | head -c 4096")
+	result, err = executor.Execute(context.Background(), tool, nil, "")
+	require.NoError(t, err)
+	assert.Len(t, result.Stdout, 4096)
+}
@@ -0,0 +1,208 @@
+//go:build !windows
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// processExited reports whether pid has exited. A zombie has exited but stays visible until its
+// parent, here the init process of the test environment, reaps it.
+func processExited(pid int) bool {
+	if err := syscall.Kill(pid, 0); errors.Is(err, syscall.ESRCH) {
+		return true
+	}
+	// #nosec G304 -- path is built from a pid
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return errors.Is(err, os.ErrNotExist)
+	}
+	// The state follows the command name, which is in parentheses
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) > 0 && fields[0] == "Z"
+}
+
+// TestExecute_KillsProcessGroup tests that a tool whose context is done is killed together with
+// the processes it spawned, which would otherwise keep running and hold its output open
+func TestExecute_KillsProcessGroup(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		cancel func(ctx context.Context) (context.Context, context.CancelFunc)
+	}{
+		{"timeout", func(ctx context.Context) (context.Context, context.CancelFunc) {
+			return context.WithTimeout(ctx, 200*time.Millisecond)
+		}},
+		{"cancel", func(ctx context.Context) (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(ctx)
+			time.AfterFunc(200*time.Millisecond, cancel)
+			return ctx, cancel
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			pidFile := filepath.Join(tmpDir, "child.pid")
+			scriptPath := filepath.Join(tmpDir, "spawn.sh")
+			script := fmt.Sprintf("#!/bin/sh\nsleep 300 &\necho $! > %s\nwait\n", pidFile)
+			// #nosec G306 -- test file permissions are acceptable for temporary test files
+			require.NoError(t, os.WriteFile(scriptPath, []byte(script), 0755))
+
+			executor := NewOrlaToolExecutor(10)
+			tool := &ToolManifest{Name: "spawn", Path: scriptPath, Interpreter: "/bin/sh"}
+
+			ctx, cancel := tc.cancel(context.Background())
+			defer cancel()
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				_, _ = executor.Execute(ctx, tool, nil, "")
+			}()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Execution did not return after its context was done")
+			}
+
+			// #nosec G304 -- path is constructed from a test temp directory, safe
+			data, err := os.ReadFile(pidFile)
+			require.NoError(t, err)
+			childPid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+			require.NoError(t, err)
+			assert.Eventually(t, func() bool { return processExited(childPid) }, 5*time.Second, 10*time.Millisecond,
+				"the tool's child is still running")
+		})
+	}
+}
+
+// TestExecute_KillsProcessGroupIgnoringTerm tests that a cancelled tool is sent SIGTERM first, and
+// that a tool ignoring it is killed once toolTerminateTimeout has passed
+func TestExecute_KillsProcessGroupIgnoringTerm(t *testing.T) {
+	original := toolTerminateTimeout
+	toolTerminateTimeout = 300 * time.Millisecond
+	t.Cleanup(func() { toolTerminateTimeout = original })
+
+	tmpDir := t.TempDir()
+	termFile := filepath.Join(tmpDir, "term")
+	scriptPath := filepath.Join(tmpDir, "stubborn.sh")
+	script := fmt.Sprintf("#!/bin/sh\ntrap 'touch %s' TERM\nwhile true; do sleep 0.05; done\n", termFile)
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(scriptPath, []byte(script), 0755))
+
+	executor := NewOrlaToolExecutor(10)
+	tool := &ToolManifest{Name: "stubborn", Path: scriptPath, Interpreter: "/bin/sh"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, _ = executor.Execute(ctx, tool, nil, "")
+	elapsed := time.Since(start)
+
+	assert.FileExists(t, termFile, "the tool was not sent SIGTERM")
+	assert.GreaterOrEqual(t, elapsed, 500*time.Millisecond, "the tool was killed before its grace period")
+	assert.Less(t, elapsed, 5*time.Second)
+}
+
+// readChildPid reads the process ID a test script wrote to pidFile, waiting for it to be written
+func readChildPid(t *testing.T, pidFile string) int {
+	t.Helper()
+	var pid int
+	require.Eventually(t, func() bool {
+		// #nosec G304 -- path is constructed from a test temp directory, safe
+		data, err := os.ReadFile(pidFile)
+		if err != nil || !strings.HasSuffix(string(data), "\n") {
+			return false
+		}
+		pid, err = strconv.Atoi(strings.TrimSpace(string(data)))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond, "the script did not start its child")
+	return pid
+}
+
+// TestTerminateRunningTools tests that the tool calls still running when the server shuts down are
+// terminated together with the processes they spawned
+func TestTerminateRunningTools(t *testing.T) {
+	tmpDir := t.TempDir()
+	pidFile := filepath.Join(tmpDir, "child.pid")
+	scriptPath := filepath.Join(tmpDir, "spawn.sh")
+	script := fmt.Sprintf("#!/bin/sh\nsleep 300 &\necho $! > %s\nwait\n", pidFile)
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(scriptPath, []byte(script), 0755))
+
+	executor := NewOrlaToolExecutor(300)
+	tool := &ToolManifest{Name: "spawn", Path: scriptPath, Interpreter: "/bin/sh"}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = executor.Execute(context.Background(), tool, nil, "")
+	}()
+	childPid := readChildPid(t, pidFile)
+
+	TerminateRunningTools()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Execution did not return after its tool was terminated")
+	}
+	assert.Eventually(t, func() bool { return processExited(childPid) }, 5*time.Second, 10*time.Millisecond,
+		"the tool's child is still running")
+
+	// Finished tool calls are no longer tracked
+	runningTools.Range(func(key, _ any) bool {
+		t.Errorf("tool call %v is still tracked", key)
+		return true
+	})
+}
+
+// TestPersistentProcess_StopKillsProcessGroup tests that stopping a persistent tool also stops the
+// processes it spawned
+func TestPersistentProcess_StopKillsProcessGroup(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	script := fmt.Sprintf("#!/bin/sh\nsleep 300 &\necho $! > %s\n", pidFile) +
+		strings.TrimPrefix(persistentTestScript, "#!/bin/sh\n")
+	p := NewPersistentProcess(&ToolManifest{
+		Name:    "persistent-tool",
+		Path:    createCapsuleScript(t, "persistent.sh", script),
+		Runtime: &RuntimeConfig{Mode: RuntimeModePersistent},
+	})
+	t.Cleanup(p.Stop)
+
+	_, err := p.Call(context.Background(), map[string]any{})
+	require.NoError(t, err)
+	childPid := readChildPid(t, pidFile)
+
+	p.Stop()
+	assert.Eventually(t, func() bool { return processExited(childPid) }, 5*time.Second, 10*time.Millisecond,
+		"the persistent tool's child is still running")
+}
+
+// TestCapsuleManager_StopKillsProcessGroup tests that stopping a capsule also stops the processes
+// it spawned
+func TestCapsuleManager_StopKillsProcessGroup(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	script := fmt.Sprintf(`#!/bin/sh
+sleep 300 &
+echo $! > %s
+echo '{"jsonrpc":"2.0","method":"orla.hello","params":{"name":"spawn-capsule","version":"1.0.0","capabilities":["tools"]}}'
+cat
+`, pidFile)
+	cm := NewCapsuleManager(&ToolManifest{Name: "spawn-capsule", Path: createCapsuleScript(t, "spawn-capsule.sh", script)})
+
+	require.NoError(t, cm.Start())
+	childPid := readChildPid(t, pidFile)
+
+	require.NoError(t, cm.Stop())
+	assert.Eventually(t, func() bool { return processExited(childPid) }, 5*time.Second, 10*time.Millisecond,
+		"the capsule's child is still running")
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// InterpreterNotFoundError is returned when a tool's interpreter is a bare name (e.g. "python3")
+// that is not found in the PATH the tool process would run with
+type InterpreterNotFoundError struct {
+	Tool        string
+	Interpreter string
+	Path        string
+}
+
+// Error returns the error message for the InterpreterNotFoundError, including how to fix it
+func (e *InterpreterNotFoundError) Error() string {
+	return fmt.Sprintf("interpreter '%s' for tool '%s' was not found in PATH (%s); install it or set PATH in the tool's runtime.env",
+		e.Interpreter, e.Tool, e.Path)
+}
+
+// Interface guard for InterpreterNotFoundError
+var _ error = &InterpreterNotFoundError{}
+
+// resolveInterpreter returns the path of a tool's interpreter. Bare names are looked up in the
+// PATH of env, the environment of the tool process (nil for orla's own environment), so that a
+// PATH set in the tool's runtime env is respected. Interpreters given as paths are returned as is.
+func resolveInterpreter(tool *ToolManifest, env []string) (string, error) {
+	if strings.ContainsRune(tool.Interpreter, '/') || strings.ContainsRune(tool.Interpreter, filepath.Separator) {
+		return tool.Interpreter, nil
+	}
+
+	pathList := envPath(env)
+	if path, ok := lookPathIn(tool.Interpreter, pathList); ok {
+		return path, nil
+	}
+
+	return "", &InterpreterNotFoundError{Tool: tool.Name, Interpreter: tool.Interpreter, Path: pathList}
+}
+
+// envPath returns the PATH of env, the environment of a tool process (nil for orla's own environment)
+func envPath(env []string) string {
+	if env == nil {
+		return os.Getenv("PATH")
+	}
+	return envValue(env, "PATH")
+}
+
+// lookPathIn returns the path of the executable name in the directories of pathList
+func lookPathIn(name, pathList string) (string, bool) {
+	for _, dir := range filepath.SplitList(pathList) {
+		// Like exec.LookPath, never resolve a bare name from the current directory
+		if dir == "" || !filepath.IsAbs(dir) {
+			continue
+		}
+		if path, err := exec.LookPath(filepath.Join(dir, name)); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// envValue returns the value of key in env, a list of KEY=VALUE entries where later entries take
+// precedence, or "" if key is not set
+func envValue(env []string, key string) string {
+	value := ""
+	for _, entry := range env {
+		if k, v, ok := strings.Cut(entry, "="); ok && k == key {
+			value = v
+		}
+	}
+	return value
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveInterpreter(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping PATH lookup test on Windows")
+	}
+
+	binDir := t.TempDir()
+	interpreterPath := filepath.Join(binDir, "my-interpreter")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(interpreterPath, []byte("#!/bin/sh\necho interpreted\n"), 0755))
+
+	tool := &ToolManifest{Name: "test-tool", Interpreter: "my-interpreter"}
+
+	t.Run("found in the tool's PATH", func(t *testing.T) {
+		path, err := resolveInterpreter(tool, []string{"PATH=/nonexistent", "PATH=" + binDir})
+		require.NoError(t, err)
+		assert.Equal(t, interpreterPath, path)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := resolveInterpreter(tool, []string{"PATH=/nonexistent"})
+		var notFound *InterpreterNotFoundError
+		require.True(t, errors.As(err, &notFound))
+		assert.Equal(t, "my-interpreter", notFound.Interpreter)
+		assert.Equal(t, "/nonexistent", notFound.Path)
+	})
+
+	t.Run("relative PATH entries are ignored", func(t *testing.T) {
+		relDir, err := filepath.Rel(mustGetwd(t), binDir)
+		require.NoError(t, err)
+		_, err = resolveInterpreter(tool, []string{"PATH=" + relDir})
+		require.Error(t, err)
+	})
+
+	t.Run("paths are not looked up", func(t *testing.T) {
+		path, err := resolveInterpreter(&ToolManifest{Name: "test-tool", Interpreter: "/bin/sh"}, []string{"PATH="})
+		require.NoError(t, err)
+		assert.Equal(t, "/bin/sh", path)
+	})
+}
+
+func TestExecute_InterpreterFromRuntimePath(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping shell test on Windows")
+	}
+
+	binDir := t.TempDir()
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "my-interpreter"), []byte("#!/bin/sh\necho interpreted \"$1\"\n"), 0755))
+
+	scriptPath := filepath.Join(t.TempDir(), "tool.script")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(scriptPath, []byte("ignored"), 0644))
+
+	executor := NewOrlaToolExecutor(10)
+	tool := &ToolManifest{
+		Name:        "test-tool",
+		Path:        scriptPath,
+		Interpreter: "my-interpreter",
+		Runtime: &RuntimeConfig{
+			Env: map[string]string{"PATH": binDir + string(os.PathListSeparator) + os.Getenv("PATH")},
+		},
+	}
+
+	result, err := executor.Execute(context.Background(), tool, []string{}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "interpreted "+scriptPath+"\n", result.Stdout)
+
+	tool.Runtime = nil
+	_, err = executor.Execute(context.Background(), tool, []string{}, "")
+	var notFound *InterpreterNotFoundError
+	assert.True(t, errors.As(err, &notFound), "without the runtime PATH the interpreter should not be found")
+}
+
+// mustGetwd returns the current directory
+func mustGetwd(t *testing.T) string {
+	t.Helper()
+	dir, err := os.Getwd()
+	require.NoError(t, err)
+	return dir
+}
@@ -0,0 +1,180 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OutputJSONPath is a parsed mcp.output_json_path: the location of the part of a tool's JSON
+// output that is returned to the client. It is written either as a JSON Pointer (RFC 6901, e.g.
+// "/data/items/0") or as a simple JSONPath of member names and array indexes (e.g.
+// "$.data.items[0]" or "$['data']['items'][0]").
+type OutputJSONPath struct {
+	raw      string
+	segments []jsonPathSegment
+}
+
+// jsonPathSegment is a step of an OutputJSONPath: an object member name, or an array index if
+// isIndex is set. JSON Pointer segments are always names, and are used as an index when the
+// value they are applied to is an array.
+type jsonPathSegment struct {
+	name    string
+	index   int
+	isIndex bool
+}
+
+// String returns the path as it was written
+func (p *OutputJSONPath) String() string {
+	return p.raw
+}
+
+// ParseOutputJSONPath parses a JSON Pointer or simple JSONPath
+func ParseOutputJSONPath(path string) (*OutputJSONPath, error) {
+	switch {
+	case strings.HasPrefix(path, "/"):
+		return parseJSONPointer(path), nil
+	case strings.HasPrefix(path, "$"):
+		segments, err := parseJSONPathSegments(path[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid mcp.output_json_path '%s': %w", path, err)
+		}
+		return &OutputJSONPath{raw: path, segments: segments}, nil
+	default:
+		return nil, fmt.Errorf("invalid mcp.output_json_path '%s': must be a JSON Pointer starting with '/' or a JSONPath starting with '$'", path)
+	}
+}
+
+// ValidateOutputJSONPath checks the mcp.output_json_path of a tool, if it sets one
+func ValidateOutputJSONPath(tool *ToolManifest) error {
+	if tool.MCP == nil || tool.MCP.OutputJSONPath == "" {
+		return nil
+	}
+	_, err := ParseOutputJSONPath(tool.MCP.OutputJSONPath)
+	return err
+}
+
+// parseJSONPointer parses a JSON Pointer, unescaping "~1" to '/' and "~0" to '~' in each segment
+func parseJSONPointer(pointer string) *OutputJSONPath {
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+	parts := strings.Split(pointer[1:], "/")
+	segments := make([]jsonPathSegment, len(parts))
+	for i, part := range parts {
+		segments[i] = jsonPathSegment{name: unescape.Replace(part)}
+	}
+	return &OutputJSONPath{raw: pointer, segments: segments}
+}
+
+// parseJSONPathSegments parses the steps of a JSONPath after the leading '$'
+func parseJSONPathSegments(path string) ([]jsonPathSegment, error) {
+	var segments []jsonPathSegment
+	for path != "" {
+		switch path[0] {
+		case '.':
+			end := strings.IndexAny(path[1:], ".[")
+			if end < 0 {
+				end = len(path) - 1
+			}
+			name := path[1 : end+1]
+			if name == "" {
+				return nil, fmt.Errorf("empty member name")
+			}
+			segments = append(segments, jsonPathSegment{name: name})
+			path = path[end+1:]
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed '['")
+			}
+			segment, err := parseJSONPathBracket(path[1:end])
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, segment)
+			path = path[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected '%c', expected '.' or '['", path[0])
+		}
+	}
+	return segments, nil
+}
+
+// parseJSONPathBracket parses the inside of a bracket step: a quoted member name or an array index
+func parseJSONPathBracket(inner string) (jsonPathSegment, error) {
+	if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+		return jsonPathSegment{name: inner[1 : len(inner)-1]}, nil
+	}
+	index, err := strconv.Atoi(inner)
+	if err != nil || index < 0 {
+		return jsonPathSegment{}, fmt.Errorf("'[%s]' is neither a quoted member name nor an array index", inner)
+	}
+	return jsonPathSegment{index: index, isIndex: true}, nil
+}
+
+// Extract returns the part of document at the path. It fails if the path does not exist in
+// document, naming the first step that could not be followed.
+func (p *OutputJSONPath) Extract(document any) (any, error) {
+	current := document
+	for _, segment := range p.segments {
+		next, ok := segment.apply(current)
+		if !ok {
+			return nil, fmt.Errorf("output_json_path '%s' not found in tool output: no %s in %s",
+				p.raw, segment.describe(), describeJSONValue(current))
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// apply follows the segment from value, reporting whether it exists
+func (s jsonPathSegment) apply(value any) (any, bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		if s.isIndex {
+			return nil, false
+		}
+		member, ok := v[s.name]
+		return member, ok
+	case []any:
+		index := s.index
+		if !s.isIndex {
+			parsed, err := strconv.Atoi(s.name)
+			if err != nil || parsed < 0 {
+				return nil, false
+			}
+			index = parsed
+		}
+		if index >= len(v) {
+			return nil, false
+		}
+		return v[index], true
+	default:
+		return nil, false
+	}
+}
+
+// describe names the segment for error messages, e.g. "member 'items'" or "index 3"
+func (s jsonPathSegment) describe() string {
+	if s.isIndex {
+		return fmt.Sprintf("index %d", s.index)
+	}
+	return fmt.Sprintf("member '%s'", s.name)
+}
+
+// describeJSONValue names the kind of a decoded JSON value for error messages
+func describeJSONValue(value any) string {
+	switch v := value.(type) {
+	case map[string]any:
+		return "an object"
+	case []any:
+		return fmt.Sprintf("an array of %d items", len(v))
+	case string:
+		return "a string"
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	default:
+		return "a number"
+	}
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputJSONPath_Extract(t *testing.T) {
+	var document any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"data": {"items": [{"id": 1}, {"id": 2}], "a/b": "slash", "m~n": "tilde", "0": "zero"},
+		"status": "ok"
+	}`), &document))
+
+	tests := []struct {
+		path     string
+		expected any
+	}{
+		{path: "$", expected: document},
+		{path: "$.status", expected: "ok"},
+		{path: "$.data.items[1]", expected: map[string]any{"id": float64(2)}},
+		{path: "$.data.items[0].id", expected: float64(1)},
+		{path: "$['data'][\"a/b\"]", expected: "slash"},
+		{path: "/status", expected: "ok"},
+		{path: "/data/items/1/id", expected: float64(2)},
+		{path: "/data/a~1b", expected: "slash"},
+		{path: "/data/m~0n", expected: "tilde"},
+		{path: "/data/0", expected: "zero"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, err := ParseOutputJSONPath(tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.path, path.String())
+
+			value, err := path.Extract(document)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestOutputJSONPath_ExtractMissing(t *testing.T) {
+	var document any
+	require.NoError(t, json.Unmarshal([]byte(`{"data": {"items": [1, 2], "name": "orla"}}`), &document))
+
+	tests := []struct {
+		path     string
+		contains string
+	}{
+		{path: "$.result", contains: "no member 'result' in an object"},
+		{path: "$.data.items[2]", contains: "no index 2 in an array of 2 items"},
+		{path: "$.data.items.first", contains: "no member 'first' in an array of 2 items"},
+		{path: "$.data[0]", contains: "no index 0 in an object"},
+		{path: "$.data.name.first", contains: "no member 'first' in a string"},
+		{path: "/data/items/x", contains: "no member 'x' in an array of 2 items"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, err := ParseOutputJSONPath(tt.path)
+			require.NoError(t, err)
+
+			_, err = path.Extract(document)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "output_json_path '"+tt.path+"' not found in tool output")
+			assert.Contains(t, err.Error(), tt.contains)
+		})
+	}
+}
+
+func TestParseOutputJSONPath_Invalid(t *testing.T) {
+	tests := []struct {
+		path     string
+		contains string
+	}{
+		{path: "data.result", contains: "must be a JSON Pointer starting with '/' or a JSONPath starting with '$'"},
+		{path: "", contains: "must be a JSON Pointer"},
+		{path: "$data", contains: "unexpected 'd'"},
+		{path: "$.data..result", contains: "empty member name"},
+		{path: "$.items[0", contains: "unclosed '['"},
+		{path: "$.items[-1]", contains: "neither a quoted member name nor an array index"},
+		{path: "$.items[*]", contains: "neither a quoted member name nor an array index"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			_, err := ParseOutputJSONPath(tt.path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.contains)
+		})
+	}
+}
+
+func TestValidateOutputJSONPath(t *testing.T) {
+	assert.NoError(t, ValidateOutputJSONPath(&ToolManifest{Name: "no-mcp"}))
+	assert.NoError(t, ValidateOutputJSONPath(&ToolManifest{Name: "no-path", MCP: &MCPConfig{}}))
+	assert.NoError(t, ValidateOutputJSONPath(&ToolManifest{Name: "valid", MCP: &MCPConfig{OutputJSONPath: "$.data"}}))
+	assert.Error(t, ValidateOutputJSONPath(&ToolManifest{Name: "invalid", MCP: &MCPConfig{OutputJSONPath: "data"}}))
+}
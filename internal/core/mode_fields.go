@@ -0,0 +1,75 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// modeField is a manifest field that only applies to some runtime modes
+type modeField struct {
+	name  string        // Field name in tool.yaml, e.g. "runtime.startup_timeout_ms"
+	modes []RuntimeMode // Runtime modes the field applies to
+	isSet func(*ToolManifest) bool
+}
+
+// onDemandModes are the runtime modes that start a process for each call
+var onDemandModes = []RuntimeMode{RuntimeModeSimple, RuntimeModeNpx, RuntimeModePipx}
+
+// modeFields lists the manifest fields that only apply to some runtime modes. Capsule settings
+// concern the handshake and supervision of a capsule process, _meta fields and stdin are passed to
+// a process started for the call, and only package modes run a package.
+var modeFields = []modeField{
+	{"runtime.package", []RuntimeMode{RuntimeModeNpx, RuntimeModePipx}, func(t *ToolManifest) bool {
+		return t.Runtime != nil && t.Runtime.Package != ""
+	}},
+	{"runtime.startup_timeout_ms", []RuntimeMode{RuntimeModeCapsule}, func(t *ToolManifest) bool {
+		return t.Runtime != nil && t.Runtime.StartupTimeoutMs != 0
+	}},
+	{"runtime.health_check_interval_ms", []RuntimeMode{RuntimeModeCapsule}, func(t *ToolManifest) bool {
+		return t.Runtime != nil && t.Runtime.HealthCheckIntervalMs != 0
+	}},
+	{"runtime.health_check_failures", []RuntimeMode{RuntimeModeCapsule}, func(t *ToolManifest) bool {
+		return t.Runtime != nil && t.Runtime.HealthCheckFailures != 0
+	}},
+	{"runtime.max_restarts", []RuntimeMode{RuntimeModeCapsule}, func(t *ToolManifest) bool {
+		return t.Runtime != nil && t.Runtime.MaxRestarts != 0
+	}},
+	{"mcp.pass_meta", onDemandModes, func(t *ToolManifest) bool {
+		return t.MCP != nil && len(t.MCP.PassMeta) > 0
+	}},
+	{"stdin_format", onDemandModes, func(t *ToolManifest) bool {
+		return t.StdinFormat != ""
+	}},
+}
+
+// ValidateModeFields checks that a tool does not set fields that do not apply to its runtime
+// mode, such as a capsule startup timeout on a simple mode tool, which would otherwise be
+// silently ignored. A tool without a runtime mode is a simple mode tool.
+func ValidateModeFields(tool *ToolManifest) error {
+	mode := RuntimeModeSimple
+	if tool.Runtime != nil && tool.Runtime.Mode != "" {
+		mode = tool.Runtime.Mode
+	}
+
+	for _, field := range modeFields {
+		if field.isSet(tool) && !slices.Contains(field.modes, mode) {
+			return fmt.Errorf("invalid %s: only applies to %s mode tools, but runtime.mode is %s",
+				field.name, joinModes(field.modes), mode)
+		}
+	}
+	return nil
+}
+
+// joinModes joins runtime modes for error messages, e.g. "simple or capsule" or
+// "simple, npx, or pipx"
+func joinModes(modes []RuntimeMode) string {
+	names := make([]string, len(modes))
+	for i, mode := range modes {
+		names[i] = string(mode)
+	}
+	if len(names) <= 2 {
+		return strings.Join(names, " or ")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + ", or " + names[len(names)-1]
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// npx and pipx runtime modes
+//
+// A tool in npx or pipx mode runs a published package instead of a script shipped with the tool,
+// so its manifest names the package in runtime.package and has no entrypoint. Each call runs the
+// package on demand, as a simple mode tool runs its entrypoint:
+//
+//	npx:  npx -y <package>@<version> <call args> <runtime args>
+//	pipx: pipx run <package>==<version> <call args> <runtime args>
+//
+// The package is pinned to the tool's version, the version it was installed at from the
+// registry, so that a new release of the package is not picked up without an orla tool update.
+// A package that names its own version, or a tool without a version, is run as given.
+//
+// The package manager is looked up in the PATH the tool process runs with, and a tool whose
+// package manager is not installed is not served.
+
+// packageManagers maps each package runtime mode to the command that runs a package
+var packageManagers = map[RuntimeMode][]string{
+	RuntimeModeNpx:  {"npx", "-y"},
+	RuntimeModePipx: {"pipx", "run"},
+}
+
+// PackageManagerNotFoundError is returned when the package manager of an npx or pipx mode tool
+// is not found in the PATH the tool process would run with
+type PackageManagerNotFoundError struct {
+	Tool           string
+	PackageManager string
+	Path           string
+}
+
+// Error returns the error message for the PackageManagerNotFoundError, including how to fix it
+func (e *PackageManagerNotFoundError) Error() string {
+	return fmt.Sprintf("package manager '%s' for tool '%s' was not found in PATH (%s); install it or set PATH in the tool's runtime.env",
+		e.PackageManager, e.Tool, e.Path)
+}
+
+// Interface guard for PackageManagerNotFoundError
+var _ error = &PackageManagerNotFoundError{}
+
+// IsPackageMode reports whether mode runs a package with a package manager
+func IsPackageMode(mode RuntimeMode) bool {
+	_, ok := packageManagers[mode]
+	return ok
+}
+
+// IsPackageTool reports whether tool runs a package with a package manager (npx or pipx mode)
+func IsPackageTool(tool *ToolManifest) bool {
+	return tool.Runtime != nil && IsPackageMode(tool.Runtime.Mode)
+}
+
+// ValidatePackage checks the package of an npx or pipx mode tool. The package is passed to the
+// package manager as an argument, so it must not be empty or look like an option.
+func ValidatePackage(tool *ToolManifest) error {
+	if !IsPackageTool(tool) {
+		return nil
+	}
+
+	pkg := tool.Runtime.Package
+	if pkg == "" {
+		return fmt.Errorf("invalid runtime.package: required for %s mode tools", tool.Runtime.Mode)
+	}
+	if strings.HasPrefix(pkg, "-") || strings.ContainsFunc(pkg, unicode.IsSpace) {
+		return fmt.Errorf("invalid runtime.package: %q is not a package name", pkg)
+	}
+	return nil
+}
+
+// ResolvePackageManager returns the path of the package manager of an npx or pipx mode tool,
+// looked up in the PATH the tool process runs with
+func ResolvePackageManager(tool *ToolManifest) (string, error) {
+	return resolvePackageManager(tool, toolEnv(tool, nil))
+}
+
+// resolvePackageManager returns the path of a tool's package manager, looked up in the PATH of
+// env, the environment of the tool process (nil for orla's own environment)
+func resolvePackageManager(tool *ToolManifest, env []string) (string, error) {
+	name := packageManagers[tool.Runtime.Mode][0]
+	pathList := envPath(env)
+	if path, ok := lookPathIn(name, pathList); ok {
+		return path, nil
+	}
+	return "", &PackageManagerNotFoundError{Tool: tool.Name, PackageManager: name, Path: pathList}
+}
+
+// packageCommand returns the package manager and arguments that run the package of an npx or
+// pipx mode tool with args
+func packageCommand(tool *ToolManifest, args []string) (string, []string) {
+	manager := packageManagers[tool.Runtime.Mode]
+	cmdArgs := append(append([]string{}, manager[1:]...), pinnedPackage(tool))
+	return manager[0], append(cmdArgs, args...)
+}
+
+// pinnedPackage returns the package of an npx or pipx mode tool pinned to the tool's version,
+// unless the package already names a version or the tool has none
+func pinnedPackage(tool *ToolManifest) string {
+	pkg := tool.Runtime.Package
+	if tool.Version == "" {
+		return pkg
+	}
+
+	switch tool.Runtime.Mode {
+	case RuntimeModeNpx:
+		// A scoped package starts with '@', so only a later '@' names a version
+		if strings.LastIndex(pkg, "@") > 0 {
+			return pkg
+		}
+		return pkg + "@" + tool.Version
+	case RuntimeModePipx:
+		if strings.ContainsAny(pkg, "=<>!~@") {
+			return pkg
+		}
+		return pkg + "==" + tool.Version
+	default:
+		return pkg
+	}
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveCommand_Package(t *testing.T) {
+	tool := &ToolManifest{
+		Name:    "fs-server",
+		Runtime: &RuntimeConfig{Mode: RuntimeModeNpx, Package: "@modelcontextprotocol/server-filesystem", Args: []string{"/tmp"}},
+	}
+
+	name, args := resolveCommand(tool, []string{"--verbose"})
+	assert.Equal(t, "npx", name)
+	assert.Equal(t, []string{"-y", "@modelcontextprotocol/server-filesystem", "--verbose", "/tmp"}, args)
+
+	tool.Runtime = &RuntimeConfig{Mode: RuntimeModePipx, Package: "black"}
+	name, args = resolveCommand(tool, []string{"--check"})
+	assert.Equal(t, "pipx", name)
+	assert.Equal(t, []string{"run", "black", "--check"}, args)
+}
+
+func TestResolveCommand_PinsPackageVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		runtime  *RuntimeConfig
+		expected string
+	}{
+		{name: "npx", runtime: &RuntimeConfig{Mode: RuntimeModeNpx, Package: "cowsay"}, expected: "cowsay@1.2.0"},
+		{name: "npx scoped", runtime: &RuntimeConfig{Mode: RuntimeModeNpx, Package: "@scope/pkg"}, expected: "@scope/pkg@1.2.0"},
+		{name: "npx own version", runtime: &RuntimeConfig{Mode: RuntimeModeNpx, Package: "@scope/pkg@2"}, expected: "@scope/pkg@2"},
+		{name: "pipx", runtime: &RuntimeConfig{Mode: RuntimeModePipx, Package: "black"}, expected: "black==1.2.0"},
+		{name: "pipx own version", runtime: &RuntimeConfig{Mode: RuntimeModePipx, Package: "black>=24"}, expected: "black>=24"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := &ToolManifest{Name: "pinned", Version: "1.2.0", Runtime: tt.runtime}
+			_, args := resolveCommand(tool, nil)
+			assert.Equal(t, tt.expected, args[1])
+		})
+	}
+}
+
+func TestValidatePackage(t *testing.T) {
+	tests := []struct {
+		name     string
+		runtime  *RuntimeConfig
+		expected string
+	}{
+		{name: "npx package", runtime: &RuntimeConfig{Mode: RuntimeModeNpx, Package: "@scope/pkg@1.2.3"}},
+		{name: "pipx package", runtime: &RuntimeConfig{Mode: RuntimeModePipx, Package: "black==24.1.0"}},
+		{name: "not a package tool", runtime: &RuntimeConfig{Mode: RuntimeModeSimple}},
+		{name: "missing package", runtime: &RuntimeConfig{Mode: RuntimeModePipx}, expected: "invalid runtime.package: required for pipx mode tools"},
+		{name: "option", runtime: &RuntimeConfig{Mode: RuntimeModeNpx, Package: "-p"}, expected: `invalid runtime.package: "-p" is not a package name`},
+		{name: "whitespace", runtime: &RuntimeConfig{Mode: RuntimeModeNpx, Package: "a b"}, expected: "is not a package name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePackage(&ToolManifest{Name: "test-tool", Runtime: tt.runtime})
+			if tt.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestExecute_PackageTool(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping shell test on Windows")
+	}
+
+	// A fake npx that prints the arguments it is run with
+	binDir := t.TempDir()
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "npx"), []byte("#!/bin/sh\necho npx \"$@\"\n"), 0755))
+
+	tool := &ToolManifest{
+		Name: "cowsay",
+		Runtime: &RuntimeConfig{
+			Mode:    RuntimeModeNpx,
+			Package: "cowsay",
+			Env:     map[string]string{"PATH": binDir + string(os.PathListSeparator) + os.Getenv("PATH")},
+		},
+	}
+
+	path, err := ResolvePackageManager(tool)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(binDir, "npx"), path)
+
+	executor := NewOrlaToolExecutor(10)
+	result, err := executor.Execute(context.Background(), tool, []string{"moo"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "npx -y cowsay moo\n", result.Stdout)
+
+	tool.Runtime.Env = map[string]string{"PATH": "/nonexistent"}
+	_, err = executor.Execute(context.Background(), tool, []string{"moo"}, "")
+	var notFound *PackageManagerNotFoundError
+	require.True(t, errors.As(err, &notFound), "without the runtime PATH the package manager should not be found")
+	assert.Equal(t, "npx", notFound.PackageManager)
+	assert.Equal(t, "/nonexistent", notFound.Path)
+}
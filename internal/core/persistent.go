@@ -0,0 +1,378 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Persistent tool protocol
+//
+// A tool in persistent mode is started once and kept running to serve many calls, so that costly
+// startup work (loading a model, opening connections) is paid once instead of on every call.
+// Unlike a capsule it needs no handshake or JSON-RPC. Calls are sent one at a time:
+//
+//  1. Orla writes the call's arguments to the tool's stdin as a JSON object on a single line,
+//     e.g. {"city":"Dublin","days":3}.
+//  2. The tool writes its output to stdout, followed by a line holding only "ORLA_END".
+//     Everything before that line is the call's output. A tool that fails the call ends the
+//     output with "ORLA_END <code>" instead, where a non-zero code is reported like a non-zero
+//     exit code of a simple mode tool.
+//  3. Orla sends the next call only after the end line.
+//
+// Lifecycle: the process is started on the first call and, if it exits, started again before the
+// next call is sent to it. A call that times out or is cancelled kills the process, since its late
+// output would otherwise be read as the answer to the next call. The process is stopped when the
+// tool is removed, on reload, and when orla exits. The tool's stderr is logged, not returned to
+// callers.
+
+// PersistentResponseEnd is the line a persistent tool writes after the output of each call
+const PersistentResponseEnd = "ORLA_END"
+
+// persistentStopGrace is how long a stopped persistent tool has to close its output after it is killed
+const persistentStopGrace = time.Second
+
+// maxStderrLineBytes is the longest stderr line of a persistent tool that is logged as one line.
+// Longer lines are logged in parts, so a tool that never writes a newline cannot grow the buffer.
+const maxStderrLineBytes = 64 * 1024
+
+// PersistentProcess manages the long-running process of a persistent-mode tool
+type PersistentProcess struct {
+	tool           *ToolManifest
+	maxOutputBytes int64      // Limit on the output of each call, 0 for none
+	callMu         sync.Mutex // Serializes calls, the protocol handles one call at a time
+
+	mu      sync.Mutex // Guards the fields below
+	proc    *persistentProc
+	stopped bool
+}
+
+// persistentProc is one run of a persistent tool's process
+type persistentProc struct {
+	cmd        *exec.Cmd
+	stdin      io.WriteCloser
+	stdoutFile *os.File // Read end of the stdout pipe, which Wait leaves open so no output is lost
+	stdout     *bufio.Reader
+	done       chan struct{} // Closed once the process has exited and waitErr is set
+	waitErr    error
+}
+
+// exited reports whether the process has exited
+func (proc *persistentProc) exited() bool {
+	select {
+	case <-proc.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// persistentResponse is the output of one call read from a persistent tool
+type persistentResponse struct {
+	stdout   string
+	exitCode int
+	err      error
+}
+
+// NewPersistentProcess creates the manager of a persistent-mode tool's process. The process is
+// not started until the first call.
+func NewPersistentProcess(tool *ToolManifest) *PersistentProcess {
+	return &PersistentProcess{tool: tool}
+}
+
+// SetMaxOutputBytes limits how much the tool may write to stdout for each call. A call whose
+// output is larger fails with an *OutputLimitError and kills the process, since the rest of its
+// output would otherwise be read as the answer to the next call. A limit of 0 or less removes it.
+func (p *PersistentProcess) SetMaxOutputBytes(limit int64) {
+	p.maxOutputBytes = max(limit, 0)
+}
+
+// Call sends a call with the given arguments to the tool's process, starting the process if it is
+// not running, and waits for the output. If ctx is done first, the process is killed and an error
+// wrapping ctx.Err() is returned. A non-zero code on the end line is returned as the result's
+// ExitCode, not as an error.
+func (p *PersistentProcess) Call(ctx context.Context, input map[string]any) (*OrlaToolExecutionResult, error) {
+	if input == nil {
+		input = map[string]any{}
+	}
+	request, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode call for persistent tool %s: %w", p.tool.Name, err)
+	}
+	request = append(request, '\n')
+
+	p.callMu.Lock()
+	defer p.callMu.Unlock()
+
+	proc, err := p.running()
+	if err != nil {
+		return nil, err
+	}
+
+	// Writing and reading happen in the background so that a tool that stops reading or
+	// answering cannot block the call past ctx
+	responseCh := make(chan persistentResponse, 1)
+	go func() {
+		if _, writeErr := proc.stdin.Write(request); writeErr != nil {
+			responseCh <- persistentResponse{err: fmt.Errorf("failed to send call: %w", writeErr)}
+			return
+		}
+		responseCh <- readPersistentResponse(proc.stdout, p.maxOutputBytes)
+	}()
+
+	select {
+	case response := <-responseCh:
+		if response.err != nil {
+			p.kill(proc)
+			return nil, fmt.Errorf("persistent tool %s failed: %w", p.tool.Name, response.err)
+		}
+		return &OrlaToolExecutionResult{Stdout: response.stdout, ExitCode: response.exitCode}, nil
+	case <-ctx.Done():
+		p.kill(proc)
+		return nil, fmt.Errorf("persistent tool %s did not respond: %w", p.tool.Name, ctx.Err())
+	}
+}
+
+// IsRunning returns true if the tool's process is running
+func (p *PersistentProcess) IsRunning() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.proc != nil && !p.proc.exited()
+}
+
+// Stop kills the tool's process, failing any call in progress. Later calls are rejected.
+func (p *PersistentProcess) Stop() {
+	p.mu.Lock()
+	p.stopped = true
+	proc := p.proc
+	p.mu.Unlock()
+
+	if proc != nil {
+		p.kill(proc)
+	}
+}
+
+// running returns the tool's process, starting it if it is not running or has exited since the
+// last call
+func (p *PersistentProcess) running() (*persistentProc, error) {
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("persistent tool %s is stopped", p.tool.Name)
+	}
+	proc := p.proc
+	p.mu.Unlock()
+
+	if proc != nil {
+		if !proc.exited() {
+			return proc, nil
+		}
+		zap.L().Warn("Persistent tool exited, restarting it",
+			zap.String("tool", p.tool.Name),
+			zap.NamedError("exit", proc.waitErr))
+		p.kill(proc)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return nil, fmt.Errorf("persistent tool %s is stopped", p.tool.Name)
+	}
+
+	proc, err := p.start()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start persistent tool %s: %w", p.tool.Name, err)
+	}
+	p.proc = proc
+	return proc, nil
+}
+
+// start starts the tool's process
+func (p *PersistentProcess) start() (*persistentProc, error) {
+	env := toolEnv(p.tool, nil)
+
+	name, args := resolveCommand(p.tool, nil)
+	if p.tool.Interpreter != "" {
+		interpreter, err := resolveInterpreter(p.tool, env)
+		if err != nil {
+			return nil, err
+		}
+		name = interpreter
+	}
+
+	// #nosec G204 -- the command is the tool's entrypoint from its manifest, as for simple mode tools
+	cmd := exec.Command(name, args...)
+	if env != nil {
+		cmd.Env = env
+	}
+	// Run the tool in its working_dir, or else from its own directory, as capsules are
+	switch {
+	case p.tool.WorkingDir != "":
+		cmd.Dir = p.tool.WorkingDir
+	case p.tool.Path != "":
+		cmd.Dir = filepath.Dir(p.tool.Path)
+	}
+	cmd.Stderr = &zapLineWriter{tool: p.tool.Name}
+	// A child of the tool that keeps stderr open must not block stopping it
+	cmd.WaitDelay = persistentStopGrace
+	// The processes the tool spawns are stopped together with it, see kill
+	SetProcessGroup(cmd)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	// Unlike StdoutPipe, a pipe of our own is not closed by Wait, so output written just before
+	// the process exits can still be read after it is reaped
+	stdoutRead, stdoutWrite, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	cmd.Stdout = stdoutWrite
+
+	err = cmd.Start()
+	// The child has its own copy of the write end
+	LogDeferredError(stdoutWrite.Close)
+	if err != nil {
+		LogDeferredError(stdoutRead.Close)
+		return nil, err
+	}
+
+	zap.L().Info("Started persistent tool",
+		zap.String("tool", p.tool.Name),
+		zap.Int("pid", cmd.Process.Pid))
+
+	proc := &persistentProc{
+		cmd:        cmd,
+		stdin:      stdin,
+		stdoutFile: stdoutRead,
+		stdout:     bufio.NewReader(stdoutRead),
+		done:       make(chan struct{}),
+	}
+	go func() {
+		proc.waitErr = cmd.Wait()
+		close(proc.done)
+	}()
+	return proc, nil
+}
+
+// kill stops proc and forgets it, so that the next call starts a new process. Nothing is done if
+// proc was already killed, e.g. by Stop during a failing call.
+func (p *PersistentProcess) kill(proc *persistentProc) {
+	p.mu.Lock()
+	if p.proc != proc {
+		p.mu.Unlock()
+		return
+	}
+	p.proc = nil
+	p.mu.Unlock()
+
+	if err := proc.stdin.Close(); err != nil {
+		zap.L().Debug("Failed to close persistent tool stdin", zap.String("tool", p.tool.Name), zap.Error(err))
+	}
+	if err := TerminateProcessGroup(proc.cmd, toolTerminateTimeout); err != nil {
+		zap.L().Error("Failed to terminate persistent tool process group", zap.String("tool", p.tool.Name), zap.Error(err))
+	}
+	// Without process groups (on Windows) only the tool itself is stopped
+	if err := proc.cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		zap.L().Error("Failed to kill persistent tool", zap.String("tool", p.tool.Name), zap.Error(err))
+	}
+
+	// Killing the process makes Wait report an error, which is expected here
+	<-proc.done
+	LogDeferredError(proc.stdoutFile.Close)
+	zap.L().Info("Stopped persistent tool", zap.String("tool", p.tool.Name), zap.NamedError("exit", proc.waitErr))
+}
+
+// readPersistentResponse reads the output of one call, up to and including its end line. Output
+// larger than maxBytes, unless it is 0, fails with an *OutputLimitError.
+func readPersistentResponse(r *bufio.Reader, maxBytes int64) persistentResponse {
+	stdout := &limitedBuffer{limit: maxBytes, onExceed: func() {}}
+	// Lines are read in parts no larger than r's buffer, so a tool that never writes a newline
+	// cannot grow memory past the limit. A line longer than that is never an end line.
+	lineStart := true
+	for {
+		part, err := r.ReadSlice('\n')
+		if lineStart && !errors.Is(err, bufio.ErrBufferFull) {
+			if exitCode, ok := parsePersistentResponseEnd(string(part)); ok {
+				return persistentResponse{stdout: stdout.String(), exitCode: exitCode}
+			}
+		}
+		if _, writeErr := stdout.Write(part); writeErr != nil {
+			return persistentResponse{err: &OutputLimitError{Stream: "stdout", Limit: maxBytes}}
+		}
+
+		lineStart = !errors.Is(err, bufio.ErrBufferFull)
+		if err != nil && lineStart {
+			if errors.Is(err, io.EOF) {
+				return persistentResponse{err: fmt.Errorf("process exited before writing %s", PersistentResponseEnd)}
+			}
+			return persistentResponse{err: fmt.Errorf("failed to read output: %w", err)}
+		}
+	}
+}
+
+// parsePersistentResponseEnd reports whether line is an end line, and the exit code it carries
+func parsePersistentResponseEnd(line string) (int, bool) {
+	line = strings.TrimRight(line, "\r\n")
+	if line == PersistentResponseEnd {
+		return 0, true
+	}
+
+	code, found := strings.CutPrefix(line, PersistentResponseEnd+" ")
+	if !found {
+		return 0, false
+	}
+	exitCode, err := strconv.Atoi(strings.TrimSpace(code))
+	if err != nil {
+		return 0, false
+	}
+	return exitCode, true
+}
+
+// zapLineWriter logs each line written to it as the stderr of a tool
+type zapLineWriter struct {
+	tool    string
+	mu      sync.Mutex
+	partial []byte
+}
+
+func (w *zapLineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(w.partial[:i])
+		w.partial = w.partial[i+1:]
+	}
+	for len(w.partial) >= maxStderrLineBytes {
+		w.log(w.partial[:maxStderrLineBytes])
+		w.partial = w.partial[maxStderrLineBytes:]
+	}
+	// Drop the bytes already logged rather than keeping them alive in the backing array
+	w.partial = bytes.Clone(w.partial)
+	return len(p), nil
+}
+
+// log logs one line of the tool's stderr
+func (w *zapLineWriter) log(line []byte) {
+	zap.L().Debug("Persistent tool stderr", zap.String("tool", w.tool), zap.String("line", string(line)))
+}
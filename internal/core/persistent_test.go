@@ -0,0 +1,263 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// persistentTestScript answers each call with its process ID, the number of calls it has served,
+// and the call's input. Inputs containing "fail" end with exit code 3, inputs containing "quit"
+// exit mid-response, inputs containing "exit" exit after the response, and inputs containing
+// "hang" are never answered.
+const persistentTestScript = `#!/bin/sh
+count=0
+while IFS= read -r line; do
+  count=$((count + 1))
+  echo "starting call" >&2
+  case "$line" in
+    *hang*) continue ;;
+    *quit*) echo "partial"; exit 0 ;;
+  esac
+  echo "pid=$$"
+  echo "call=$count"
+  echo "input=$line"
+  case "$line" in
+    *fail*) echo "ORLA_END 3" ;;
+    *exit*) echo "ORLA_END"; exit 0 ;;
+    *) echo "ORLA_END" ;;
+  esac
+done
+`
+
+// newTestPersistentProcess creates a persistent process for persistentTestScript, stopped when the test ends
+func newTestPersistentProcess(t *testing.T) *PersistentProcess {
+	t.Helper()
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows persistent tool script tests not implemented")
+	}
+
+	scriptPath := createCapsuleScript(t, "persistent.sh", persistentTestScript)
+	p := NewPersistentProcess(&ToolManifest{
+		Name:    "persistent-tool",
+		Path:    scriptPath,
+		Runtime: &RuntimeConfig{Mode: RuntimeModePersistent},
+	})
+	t.Cleanup(p.Stop)
+	return p
+}
+
+// outputLines returns the lines of a persistent test call's output: its process ID, its call
+// number, and its input
+func outputLines(t *testing.T, result *OrlaToolExecutionResult) []string {
+	t.Helper()
+	require.NotNil(t, result)
+	lines := strings.Split(strings.TrimSuffix(result.Stdout, "\n"), "\n")
+	require.Len(t, lines, 3, "unexpected output %q", result.Stdout)
+	return lines
+}
+
+func TestPersistentProcess_MultipleCalls(t *testing.T) {
+	p := newTestPersistentProcess(t)
+	ctx := context.Background()
+	assert.False(t, p.IsRunning())
+
+	first, err := p.Call(ctx, map[string]any{"city": "Dublin"})
+	require.NoError(t, err)
+	assert.True(t, p.IsRunning())
+	assert.Equal(t, 0, first.ExitCode)
+	firstLines := outputLines(t, first)
+	assert.Equal(t, "call=1", firstLines[1])
+	assert.Equal(t, `input={"city":"Dublin"}`, firstLines[2])
+
+	// Later calls are served by the same process
+	for _, expected := range []string{"call=2", "call=3"} {
+		result, err := p.Call(ctx, map[string]any{"city": "Cork"})
+		require.NoError(t, err)
+		lines := outputLines(t, result)
+		assert.Equal(t, firstLines[0], lines[0])
+		assert.Equal(t, expected, lines[1])
+	}
+
+	// A call without arguments sends an empty object
+	result, err := p.Call(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "input={}", outputLines(t, result)[2])
+}
+
+func TestPersistentProcess_ExitCode(t *testing.T) {
+	p := newTestPersistentProcess(t)
+
+	result, err := p.Call(context.Background(), map[string]any{"mode": "fail"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.ExitCode)
+	assert.Equal(t, `input={"mode":"fail"}`, outputLines(t, result)[2])
+
+	// The process keeps serving calls after a failed one
+	result, err = p.Call(context.Background(), map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "call=2", outputLines(t, result)[1])
+}
+
+func TestPersistentProcess_TimeoutRestartsProcess(t *testing.T) {
+	p := newTestPersistentProcess(t)
+
+	first, err := p.Call(context.Background(), map[string]any{})
+	require.NoError(t, err)
+	firstLines := outputLines(t, first)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err = p.Call(ctx, map[string]any{"mode": "hang"})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.False(t, p.IsRunning())
+
+	// The next call starts a new process
+	result, err := p.Call(context.Background(), map[string]any{})
+	require.NoError(t, err)
+	lines := outputLines(t, result)
+	assert.NotEqual(t, firstLines[0], lines[0])
+	assert.Equal(t, "call=1", lines[1])
+}
+
+func TestPersistentProcess_ExitBeforeEnd(t *testing.T) {
+	p := newTestPersistentProcess(t)
+
+	_, err := p.Call(context.Background(), map[string]any{"mode": "quit"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "process exited before writing ORLA_END")
+	assert.False(t, p.IsRunning())
+
+	result, err := p.Call(context.Background(), map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, "call=1", outputLines(t, result)[1])
+}
+
+func TestPersistentProcess_ExitAfterResponse(t *testing.T) {
+	p := newTestPersistentProcess(t)
+
+	first, err := p.Call(context.Background(), map[string]any{"mode": "exit"})
+	require.NoError(t, err)
+	firstLines := outputLines(t, first)
+	require.Eventually(t, func() bool { return !p.IsRunning() }, 5*time.Second, 10*time.Millisecond)
+
+	// The exited process is noticed before the call is sent, and a new one serves it
+	result, err := p.Call(context.Background(), map[string]any{})
+	require.NoError(t, err)
+	lines := outputLines(t, result)
+	assert.NotEqual(t, firstLines[0], lines[0])
+	assert.Equal(t, "call=1", lines[1])
+}
+
+func TestPersistentProcess_OutputLimit(t *testing.T) {
+	p := newTestPersistentProcess(t)
+	p.SetMaxOutputBytes(64)
+
+	_, err := p.Call(context.Background(), map[string]any{"mode": strings.Repeat("x", 100)})
+	require.Error(t, err)
+	var limitErr *OutputLimitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, int64(64), limitErr.Limit)
+	assert.False(t, p.IsRunning())
+
+	// The rest of the output is not read as the answer to the next call, which a new process serves
+	result, err := p.Call(context.Background(), map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, "call=1", outputLines(t, result)[1])
+}
+
+func TestReadPersistentResponse_LongLines(t *testing.T) {
+	long := strings.Repeat("x", 100)
+
+	// Lines longer than the reader's buffer are read in parts
+	r := bufio.NewReaderSize(strings.NewReader(long+"\nORLA_END 2\n"), 16)
+	response := readPersistentResponse(r, 0)
+	require.NoError(t, response.err)
+	assert.Equal(t, long+"\n", response.stdout)
+	assert.Equal(t, 2, response.exitCode)
+
+	// A line without a newline stops being read at the limit
+	r = bufio.NewReaderSize(strings.NewReader(strings.Repeat("x", 10000)), 16)
+	response = readPersistentResponse(r, 50)
+	var limitErr *OutputLimitError
+	require.ErrorAs(t, response.err, &limitErr)
+	assert.Equal(t, "stdout", limitErr.Stream)
+}
+
+func TestPersistentProcess_Stop(t *testing.T) {
+	p := newTestPersistentProcess(t)
+
+	_, err := p.Call(context.Background(), map[string]any{})
+	require.NoError(t, err)
+
+	p.Stop()
+	assert.False(t, p.IsRunning())
+
+	_, err = p.Call(context.Background(), map[string]any{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is stopped")
+
+	// Stopping again is a no-op
+	p.Stop()
+}
+
+func TestPersistentProcess_StartError(t *testing.T) {
+	p := NewPersistentProcess(&ToolManifest{Name: "missing-tool", Path: "/nonexistent/tool"})
+	t.Cleanup(p.Stop)
+
+	_, err := p.Call(context.Background(), map[string]any{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to start persistent tool missing-tool")
+	assert.False(t, p.IsRunning())
+}
+
+func TestZapLineWriter_LongLine(t *testing.T) {
+	w := &zapLineWriter{tool: "persistent-tool"}
+
+	// A line without a newline is logged in parts instead of being buffered whole
+	chunk := []byte(strings.Repeat("x", 1000))
+	for range 3 * maxStderrLineBytes / len(chunk) {
+		n, err := w.Write(chunk)
+		require.NoError(t, err)
+		require.Equal(t, len(chunk), n)
+		require.Less(t, len(w.partial), maxStderrLineBytes)
+	}
+
+	_, err := w.Write([]byte("end\n"))
+	require.NoError(t, err)
+	assert.Empty(t, w.partial)
+}
+
+func TestParsePersistentResponseEnd(t *testing.T) {
+	tests := []struct {
+		line     string
+		exitCode int
+		ok       bool
+	}{
+		{line: "ORLA_END\n", exitCode: 0, ok: true},
+		{line: "ORLA_END\r\n", exitCode: 0, ok: true},
+		{line: "ORLA_END", exitCode: 0, ok: true},
+		{line: "ORLA_END 2\n", exitCode: 2, ok: true},
+		{line: "ORLA_END 0\n", exitCode: 0, ok: true},
+		{line: "ORLA_END later\n", ok: false},
+		{line: "ORLA_ENDING\n", ok: false},
+		{line: " ORLA_END\n", ok: false},
+		{line: "output\n", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			exitCode, ok := parsePersistentResponseEnd(tt.line)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.exitCode, exitCode)
+		})
+	}
+}
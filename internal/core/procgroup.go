@@ -0,0 +1,37 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// runningTools holds the processes of simple mode tool calls that are running. Each runs in its own
+// process group (see killProcessGroupOnCancel), which is not reached when orla's own group is
+// signalled, so they are tracked to be terminated when the server shuts down.
+var runningTools sync.Map // *exec.Cmd -> struct{}
+
+// TerminateRunningTools terminates the process groups of all simple mode tool calls that are still
+// running, sending SIGTERM and then SIGKILL as when a call is cancelled, and waits until they are
+// done. It is called when the server shuts down, so that no tool outlives it.
+func TerminateRunningTools() {
+	var wg sync.WaitGroup
+	runningTools.Range(func(key, _ any) bool {
+		cmd, ok := key.(*exec.Cmd)
+		if !ok || cmd.Cancel == nil {
+			return true
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := cmd.Cancel(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+				zap.L().Warn("Failed to terminate tool process", zap.Int("pid", cmd.Process.Pid), zap.Error(err))
+			}
+		}()
+		return true
+	})
+	wg.Wait()
+}
@@ -0,0 +1,80 @@
+//go:build !windows
+
+package core
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// processGroupPollInterval is how often terminateProcessGroup checks whether the group has exited
+const processGroupPollInterval = 10 * time.Millisecond
+
+// toolTerminateTimeout is how long the process group of a cancelled tool has to exit after
+// SIGTERM before it is killed. Mutable for testing.
+var toolTerminateTimeout = 2 * time.Second
+
+// SetProcessGroup starts cmd in a new process group led by the command, so that the processes it
+// spawns can be signalled together with it
+func SetProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// TerminateProcessGroup sends SIGTERM to every process in the process group of cmd, and SIGKILL to
+// those still running after timeout. A group that has already exited is not an error. Commands
+// not started by SetProcessGroup are left alone, since their group is not theirs to signal.
+func TerminateProcessGroup(cmd *exec.Cmd, timeout time.Duration) error {
+	if err := terminateProcessGroup(cmd, timeout); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+	return nil
+}
+
+// killProcessGroupOnCancel starts cmd in a new process group led by the command, and makes the
+// cancellation of its context terminate the whole group rather than only the command, so that the
+// processes a tool spawns, such as the children of a shell script, do not outlive it. Being in a
+// group of its own, the tool is not signalled with orla's group; orla terminates the tools still
+// running when it shuts down instead, see TerminateRunningTools.
+func killProcessGroupOnCancel(cmd *exec.Cmd) {
+	SetProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		return terminateProcessGroup(cmd, toolTerminateTimeout)
+	}
+}
+
+// terminateProcessGroup implements TerminateProcessGroup, returning os.ErrProcessDone if the group
+// had already exited, as exec.Cmd.Cancel expects
+func terminateProcessGroup(cmd *exec.Cmd, timeout time.Duration) error {
+	if cmd.Process == nil || cmd.SysProcAttr == nil || !cmd.SysProcAttr.Setpgid {
+		return nil
+	}
+	// The group's ID is the PID of its leader, the command
+	pgid := cmd.Process.Pid
+
+	if err := syscall.Kill(-pgid, syscall.SIGTERM); err != nil {
+		if errors.Is(err, syscall.ESRCH) {
+			return os.ErrProcessDone
+		}
+		return err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		// Signal 0 only checks whether any process in the group is left
+		if err := syscall.Kill(-pgid, 0); errors.Is(err, syscall.ESRCH) {
+			return nil
+		}
+		time.Sleep(processGroupPollInterval)
+	}
+
+	if err := syscall.Kill(-pgid, syscall.SIGKILL); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return err
+	}
+	return nil
+}
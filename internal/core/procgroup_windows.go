@@ -0,0 +1,31 @@
+//go:build windows
+
+package core
+
+import (
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// SetProcessGroup is a no-op on Windows, where processes are not signalled by group
+func SetProcessGroup(cmd *exec.Cmd) {}
+
+// TerminateProcessGroup is a no-op on Windows. The command itself is still stopped by its
+// caller; the processes it spawned are not.
+func TerminateProcessGroup(cmd *exec.Cmd, timeout time.Duration) error {
+	return nil
+}
+
+// killProcessGroupOnCancel makes the cancellation of cmd's context kill the command together with
+// the processes it spawned, using taskkill to end the process tree. If taskkill fails, only the
+// command itself is terminated.
+func killProcessGroupOnCancel(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		// #nosec G204 -- the PID is of a process orla started
+		if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run(); err == nil {
+			return nil
+		}
+		return cmd.Process.Kill()
+	}
+}
@@ -0,0 +1,36 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+)
+
+// validStabilities lists the stabilities a tool can declare
+var validStabilities = []ToolStability{ToolStabilityExperimental, ToolStabilityStable, ToolStabilityDeprecated}
+
+// ValidateStability checks the stability of a tool. A tool that does not set one is stable.
+func ValidateStability(tool *ToolManifest) error {
+	if tool.Stability == "" || slices.Contains(validStabilities, tool.Stability) {
+		return nil
+	}
+	return fmt.Errorf("invalid stability: %s (must be %s, %s, or %s)",
+		tool.Stability, ToolStabilityExperimental, ToolStabilityStable, ToolStabilityDeprecated)
+}
+
+// StabilityOf returns the stability of a tool, which is stable if it does not set one
+func StabilityOf(tool *ToolManifest) ToolStability {
+	if tool.Stability == "" {
+		return ToolStabilityStable
+	}
+	return tool.Stability
+}
+
+// DescriptionWithStability prefixes description with the stability of an experimental or
+// deprecated tool, e.g. "[deprecated] Reads files", so that clients which only show descriptions
+// still see it. Descriptions of stable tools are returned unchanged.
+func DescriptionWithStability(stability ToolStability, description string) string {
+	if stability == "" || stability == ToolStabilityStable {
+		return description
+	}
+	return fmt.Sprintf("[%s] %s", stability, description)
+}
@@ -0,0 +1,30 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateStability(t *testing.T) {
+	for _, stability := range []ToolStability{"", ToolStabilityExperimental, ToolStabilityStable, ToolStabilityDeprecated} {
+		assert.NoError(t, ValidateStability(&ToolManifest{Name: "test-tool", Stability: stability}), "stability %q", stability)
+	}
+
+	err := ValidateStability(&ToolManifest{Name: "test-tool", Stability: "beta"})
+	require.Error(t, err)
+	assert.Equal(t, "invalid stability: beta (must be experimental, stable, or deprecated)", err.Error())
+}
+
+func TestStabilityOf(t *testing.T) {
+	assert.Equal(t, ToolStabilityStable, StabilityOf(&ToolManifest{}))
+	assert.Equal(t, ToolStabilityDeprecated, StabilityOf(&ToolManifest{Stability: ToolStabilityDeprecated}))
+}
+
+func TestDescriptionWithStability(t *testing.T) {
+	assert.Equal(t, "Reads files", DescriptionWithStability("", "Reads files"))
+	assert.Equal(t, "Reads files", DescriptionWithStability(ToolStabilityStable, "Reads files"))
+	assert.Equal(t, "[experimental] Reads files", DescriptionWithStability(ToolStabilityExperimental, "Reads files"))
+	assert.Equal(t, "[deprecated] Reads files", DescriptionWithStability(ToolStabilityDeprecated, "Reads files"))
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// redactedEnvValue replaces the values of sensitive environment variables in command traces
+const redactedEnvValue = "[REDACTED]"
+
+// sensitiveEnvKeyMarkers are substrings of environment variable names whose values are
+// redacted in command traces
+var sensitiveEnvKeyMarkers = []string{"TOKEN", "SECRET", "PASSWORD", "PASSWD", "KEY", "CREDENTIAL", "AUTH"}
+
+// CommandTrace describes the command orla spawns for a tool execution
+type CommandTrace struct {
+	Tool    string
+	Program string
+	Args    []string
+	Env     []string // declared environment variables as KEY=VALUE, sensitive values redacted
+	Dir     string
+}
+
+// TraceCommand describes the command the executor runs for tool with the given arguments
+func TraceCommand(tool *ToolManifest, args []string) *CommandTrace {
+	program, cmdArgs := resolveCommand(tool, args)
+
+	var env []string
+	declared := declaredEnv(tool)
+	for _, key := range slices.Sorted(maps.Keys(declared)) {
+		value := declared[key]
+		if IsSensitiveEnvKey(key) {
+			value = redactedEnvValue
+		}
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+
+	// Tools run in their working_dir, or else in orla's working directory
+	dir := tool.WorkingDir
+	if dir == "" {
+		var err error
+		if dir, err = os.Getwd(); err != nil {
+			dir = ""
+		}
+	}
+
+	return &CommandTrace{
+		Tool:    tool.Name,
+		Program: program,
+		Args:    cmdArgs,
+		Env:     env,
+		Dir:     dir,
+	}
+}
+
+// IsSensitiveEnvKey reports whether the value of an environment variable should be redacted
+func IsSensitiveEnvKey(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, marker := range sensitiveEnvKeyMarkers {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// CommandLine returns the traced command as a shell-quoted command line
+func (t *CommandTrace) CommandLine() string {
+	parts := make([]string, 0, len(t.Args)+1)
+	parts = append(parts, shellQuote(t.Program))
+	for _, arg := range t.Args {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Log logs the traced command using zap's global logger
+func (t *CommandTrace) Log() {
+	zap.L().Info("Tool command",
+		zap.String("tool", t.Tool),
+		zap.String("command", t.CommandLine()),
+		zap.Strings("env", t.Env),
+		zap.String("cwd", t.Dir))
+}
+
+// shellQuote quotes s for a POSIX shell if it contains characters the shell would interpret
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if !strings.ContainsAny(s, " \t\n'\"\\$`!*?[]{}()<>|&;#~") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
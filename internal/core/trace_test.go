@@ -0,0 +1,94 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceCommand_Binary(t *testing.T) {
+	tool := &ToolManifest{
+		Name: "test-tool",
+		Path: "/opt/tools/test-tool",
+		Runtime: &RuntimeConfig{
+			Args: []string{"--quiet"},
+		},
+	}
+
+	args := []string{"--name", "value"}
+	trace := TraceCommand(tool, args)
+
+	assert.Equal(t, "test-tool", trace.Tool)
+	assert.Equal(t, "/opt/tools/test-tool", trace.Program)
+	assert.Equal(t, []string{"--name", "value", "--quiet"}, trace.Args)
+	assert.Empty(t, trace.Env)
+	assert.NotEmpty(t, trace.Dir)
+
+	// The caller's arguments are not modified
+	assert.Equal(t, []string{"--name", "value"}, args)
+}
+
+func TestTraceCommand_InterpreterAndEnv(t *testing.T) {
+	tool := &ToolManifest{
+		Name:        "test-tool",
+		Path:        "/opt/tools/tool.py",
+		Interpreter: "python3",
+		Runtime: &RuntimeConfig{
+			Env: map[string]string{
+				"LOG_LEVEL":      "debug",
+				"GITHUB_TOKEN":   "ghp_secret",
+				"db_password":    "hunter2",
+				"AWS_ACCESS_KEY": "AKIA",
+			},
+		},
+	}
+
+	trace := TraceCommand(tool, []string{"--path", "/tmp/my file"})
+
+	assert.Equal(t, "python3", trace.Program)
+	assert.Equal(t, []string{"/opt/tools/tool.py", "--path", "/tmp/my file"}, trace.Args)
+	assert.Equal(t, []string{
+		"AWS_ACCESS_KEY=[REDACTED]",
+		"GITHUB_TOKEN=[REDACTED]",
+		"LOG_LEVEL=debug",
+		"db_password=[REDACTED]",
+	}, trace.Env)
+	assert.Equal(t, "python3 /opt/tools/tool.py --path '/tmp/my file'", trace.CommandLine())
+}
+
+func TestTraceCommand_DeclaredEnv(t *testing.T) {
+	tool := &ToolManifest{
+		Name:           "test-tool",
+		Path:           "/opt/tools/tool",
+		Env:            map[string]string{"REGION": "eu-west-1", "API_KEY": "k", "MODE": "env"},
+		EnvPassthrough: []string{"HOME"},
+		Runtime: &RuntimeConfig{
+			Env: map[string]string{"MODE": "runtime"},
+		},
+	}
+
+	trace := TraceCommand(tool, nil)
+
+	assert.Equal(t, []string{
+		"API_KEY=[REDACTED]",
+		"MODE=runtime",
+		"REGION=eu-west-1",
+	}, trace.Env)
+}
+
+func TestIsSensitiveEnvKey(t *testing.T) {
+	assert.True(t, IsSensitiveEnvKey("OPENAI_API_KEY"))
+	assert.True(t, IsSensitiveEnvKey("client_secret"))
+	assert.True(t, IsSensitiveEnvKey("BASIC_AUTH"))
+	assert.False(t, IsSensitiveEnvKey("HOME"))
+	assert.False(t, IsSensitiveEnvKey("LOG_LEVEL"))
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, "plain", shellQuote("plain"))
+	assert.Equal(t, "--max-count", shellQuote("--max-count"))
+	assert.Equal(t, "''", shellQuote(""))
+	assert.Equal(t, "'hello world'", shellQuote("hello world"))
+	assert.Equal(t, "'[a b]'", shellQuote("[a b]"))
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+}
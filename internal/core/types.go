@@ -1,6 +1,11 @@
 // Package core implements the core functionality for orla that is shared across all components.
 package core
 
+import (
+	"mime"
+	"strings"
+)
+
 // RuntimeMode represents the execution mode of a tool
 type RuntimeMode string
 
@@ -9,6 +14,54 @@ const (
 	RuntimeModeSimple RuntimeMode = "simple"
 	// RuntimeModeCapsule executes as a long-running process with lifecycle management
 	RuntimeModeCapsule RuntimeMode = "capsule"
+	// RuntimeModePersistent keeps one process running and sends it each call over a line protocol
+	// on stdin and stdout (see persistent.go)
+	RuntimeModePersistent RuntimeMode = "persistent"
+	// RuntimeModeNpx runs an npm package with npx on demand per request (see package_runtime.go)
+	RuntimeModeNpx RuntimeMode = "npx"
+	// RuntimeModePipx runs a Python package with pipx on demand per request (see package_runtime.go)
+	RuntimeModePipx RuntimeMode = "pipx"
+)
+
+// ToolStability signals how settled a tool is, so that clients can steer away from tools that may
+// change or are going away
+type ToolStability string
+
+const (
+	// ToolStabilityExperimental marks a tool whose interface may still change
+	ToolStabilityExperimental ToolStability = "experimental"
+	// ToolStabilityStable marks a tool that is ready for general use (the default)
+	ToolStabilityStable ToolStability = "stable"
+	// ToolStabilityDeprecated marks a tool that is going away and should no longer be used
+	ToolStabilityDeprecated ToolStability = "deprecated"
+)
+
+// ArgStyle is how the arguments of a tool call are passed to a simple mode tool
+type ArgStyle string
+
+const (
+	// ArgStyleFlags passes each argument as a "--name value" pair of arguments (the default)
+	ArgStyleFlags ArgStyle = "flags"
+	// ArgStyleFlagsEquals passes each argument as a single "--name=value" argument
+	ArgStyleFlagsEquals ArgStyle = "flags-equals"
+	// ArgStylePositional passes the argument values alone, without their names
+	ArgStylePositional ArgStyle = "positional"
+	// ArgStyleJSONStdin passes no arguments and writes the whole input as a JSON object to stdin
+	ArgStyleJSONStdin ArgStyle = "json-stdin"
+)
+
+// StdinFormat is what a simple mode tool receives on stdin
+type StdinFormat string
+
+const (
+	// StdinFormatRaw passes the stdin or stdin_file argument of the call (the default)
+	StdinFormatRaw StdinFormat = "raw"
+	// StdinFormatJSONArgs writes the call's arguments, without stdin and stdin_file, as a JSON
+	// object, while still passing them on the command line in the tool's arg_style. Unlike
+	// ArgStyleJSONStdin, the tool gets both.
+	StdinFormatJSONArgs StdinFormat = "json-args"
+	// StdinFormatNone gives the tool no stdin, which is at its end at once
+	StdinFormatNone StdinFormat = "none"
 )
 
 // HotLoadMode represents the reload strategy for hot-load
@@ -32,10 +85,22 @@ type HotLoadConfig struct {
 
 // RuntimeConfig represents RFC 3 compliant runtime configuration
 type RuntimeConfig struct {
-	// Mode is the runtime mode. Currently only "simple" and "capsule" are supported
+	// Mode is the runtime mode: "simple", "capsule", "persistent", "npx", or "pipx"
 	Mode RuntimeMode `yaml:"mode,omitempty"`
+	// Package is the package run by an npx or pipx mode tool, e.g. "@modelcontextprotocol/server-filesystem".
+	// Such tools have no entrypoint.
+	Package string `yaml:"package,omitempty"`
 	// StartupTimeoutMs is the maximum time Orla will wait for the startup handshake in milliseconds
 	StartupTimeoutMs int `yaml:"startup_timeout_ms,omitempty"`
+	// HealthCheckIntervalMs is how often Orla pings a ready capsule to check that it still responds,
+	// in milliseconds. Health checks are off if it is 0.
+	HealthCheckIntervalMs int `yaml:"health_check_interval_ms,omitempty"`
+	// HealthCheckFailures is the number of consecutive failed pings after which a capsule is
+	// unhealthy and restarted (default 3)
+	HealthCheckFailures int `yaml:"health_check_failures,omitempty"`
+	// MaxRestarts is how many times a capsule whose process exits unexpectedly is restarted before
+	// its tool is made unavailable (default 3)
+	MaxRestarts int `yaml:"max_restarts,omitempty"`
 	// HotLoad is the hot-reload configuration as defined in RFC 3 section 5.3
 	HotLoad *HotLoadConfig `yaml:"hot_load,omitempty"`
 	// Env is a map of environment variables to inject into the tool process
@@ -48,23 +113,99 @@ type RuntimeConfig struct {
 type MCPConfig struct {
 	InputSchema  map[string]any `yaml:"input_schema,omitempty"`
 	OutputSchema map[string]any `yaml:"output_schema,omitempty"`
+	// OutputJSONPath selects the part of the tool's JSON output that is returned, as a JSON Pointer
+	// (e.g. "/data/items") or a JSONPath (e.g. "$.data.items"). The rest of the output is dropped.
+	OutputJSONPath    string                   `yaml:"output_json_path,omitempty"`
+	OutputAnnotations *OutputAnnotationsConfig `yaml:"output_annotations,omitempty"`
+	// ContentType is the media type of the tool's stdout (e.g. "text/markdown"), which tells
+	// clients how to render it. Output without a content type is plain text. With an image type
+	// (e.g. "image/png"), stdout is the image itself and is returned as image content.
+	ContentType string `yaml:"content_type,omitempty"`
+	// PassMeta lists the _meta fields of a tool call (e.g. trace IDs) that are passed to the tool
+	// as ORLA_META_<FIELD> environment variables. Other _meta fields are not passed to the tool.
+	PassMeta []string `yaml:"pass_meta,omitempty"`
+}
+
+// ContentTypePlainText is the media type of tool output that does not declare a content type
+const ContentTypePlainText = "text/plain"
+
+// IsImageContentType reports whether contentType is a valid image media type, such as "image/png"
+func IsImageContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && strings.HasPrefix(mediaType, "image/")
+}
+
+// ContentAnnotationAudience is an intended audience of a content item, as defined by MCP
+type ContentAnnotationAudience string
+
+const (
+	// ContentAnnotationAudienceUser marks content as intended for the end user
+	ContentAnnotationAudienceUser ContentAnnotationAudience = "user"
+	// ContentAnnotationAudienceAssistant marks content as intended for the model
+	ContentAnnotationAudienceAssistant ContentAnnotationAudience = "assistant"
+)
+
+// ContentAnnotation describes MCP annotations attached to a content item in a tool result
+type ContentAnnotation struct {
+	// Audience lists who the content is intended for ("user", "assistant")
+	Audience []ContentAnnotationAudience `yaml:"audience,omitempty" json:"audience,omitempty"`
+	// Priority is how important the content is, from 0 (optional) to 1 (required)
+	Priority *float64 `yaml:"priority,omitempty" json:"priority,omitempty"`
+}
+
+// OutputAnnotationsConfig declares the annotations attached to the content items of a tool's output
+type OutputAnnotationsConfig struct {
+	// Stdout annotations apply to the content item built from the tool's stdout
+	Stdout *ContentAnnotation `yaml:"stdout,omitempty"`
+	// Stderr annotations apply to the content item built from the tool's stderr
+	Stderr *ContentAnnotation `yaml:"stderr,omitempty"`
+}
+
+// RetryConfig declares how a simple mode tool is retried when it fails with a transient error
+type RetryConfig struct {
+	// Attempts is the maximum number of times the tool is run for a call, including the first
+	Attempts int `yaml:"attempts,omitempty"`
+	// BackoffMs is the delay before the first retry in milliseconds, doubled for each later retry
+	BackoffMs int `yaml:"backoff_ms,omitempty"`
+	// RetryOnExitCodes lists the exit codes that are retried. If empty, any non-zero exit code is retried.
+	RetryOnExitCodes []int `yaml:"retry_on_exit_codes,omitempty"`
 }
 
 // ToolManifest represents an RFC 3 compliant tool.yaml manifest
 // It is used both for parsing manifests and for tool execution
 type ToolManifest struct {
-	Name         string         `yaml:"name" validate:"required"`
-	Version      string         `yaml:"version" validate:"required"`
-	Description  string         `yaml:"description" validate:"required"`
-	Entrypoint   string         `yaml:"entrypoint" validate:"required"`
-	Author       string         `yaml:"author,omitempty"`
-	License      string         `yaml:"license,omitempty"`
-	Repository   string         `yaml:"repository,omitempty"`
-	Homepage     string         `yaml:"homepage,omitempty"`
-	Keywords     []string       `yaml:"keywords,omitempty"`
-	Dependencies []string       `yaml:"dependencies,omitempty"`
-	MCP          *MCPConfig     `yaml:"mcp,omitempty"`
-	Runtime      *RuntimeConfig `yaml:"runtime,omitempty"`
-	Path         string         `yaml:"path,omitempty"`        // Absolute path to entrypoint
-	Interpreter  string         `yaml:"interpreter,omitempty"` // Interpreter parsed from shebang
+	Name           string            `yaml:"name" validate:"required"`
+	Version        string            `yaml:"version" validate:"required"`
+	Description    string            `yaml:"description" validate:"required"`
+	Entrypoint     string            `yaml:"entrypoint,omitempty"` // Required unless the tool runs a package (npx or pipx mode)
+	Author         string            `yaml:"author,omitempty"`
+	License        string            `yaml:"license,omitempty"`
+	Repository     string            `yaml:"repository,omitempty"`
+	Homepage       string            `yaml:"homepage,omitempty"`
+	Keywords       []string          `yaml:"keywords,omitempty"`
+	Dependencies   []string          `yaml:"dependencies,omitempty"`
+	Stability      ToolStability     `yaml:"stability,omitempty"`        // "experimental", "stable" (default), or "deprecated"
+	MinOrlaVersion string            `yaml:"min_orla_version,omitempty"` // Oldest orla version the tool works with
+	MaxInputBytes  int64             `yaml:"max_input_bytes,omitempty"`  // Largest accepted input (flag values and stdin), 0 for no limit
+	TimeoutSeconds int               `yaml:"timeout_seconds,omitempty"`  // Overrides the server-wide timeout for this tool, 0 to use it
+	Env            map[string]string `yaml:"env,omitempty"`              // Variables set for the tool. Declaring env or env_passthrough restricts its environment
+	EnvPassthrough []string          `yaml:"env_passthrough,omitempty"`  // Host variables passed to a tool with a restricted environment
+	ArgStyle       ArgStyle          `yaml:"arg_style,omitempty"`        // How simple mode tools receive arguments: "flags" (default), "flags-equals", "positional", or "json-stdin"
+	StdinFormat    StdinFormat       `yaml:"stdin_format,omitempty"`     // What simple mode tools receive on stdin: "raw" (default), "json-args", or "none"
+	Streamable     bool              `yaml:"streamable,omitempty"`       // Stream stdout to clients that ask for it line by line rather than in raw chunks
+	Enabled        *bool             `yaml:"enabled,omitempty"`          // Whether the server registers the tool, true if unset, see IsToolEnabled
+	WorkingDir     string            `yaml:"working_dir,omitempty"`      // Directory the tool runs in, absolute or relative to its tool.yaml, see ResolveToolDirs
+	StdinFileRoot  string            `yaml:"stdin_file_root,omitempty"`  // Directory the stdin_file argument may read from, stdin_file is rejected if unset
+	MCP            *MCPConfig        `yaml:"mcp,omitempty"`
+	Runtime        *RuntimeConfig    `yaml:"runtime,omitempty"`
+	Retry          *RetryConfig      `yaml:"retry,omitempty"`       // Retry transient failures of simple mode tools
+	Command        string            `yaml:"command,omitempty"`     // Inline shell command run instead of an entrypoint, for tools defined in config
+	Path           string            `yaml:"path,omitempty"`        // Absolute path to entrypoint
+	Interpreter    string            `yaml:"interpreter,omitempty"` // Interpreter parsed from shebang
+}
+
+// IsToolEnabled reports whether the server registers tool, which it does unless its manifest sets
+// enabled: false
+func IsToolEnabled(tool *ToolManifest) bool {
+	return tool.Enabled == nil || *tool.Enabled
 }
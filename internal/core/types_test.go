@@ -0,0 +1,22 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsImageContentType(t *testing.T) {
+	assert.True(t, IsImageContentType("image/png"))
+	assert.True(t, IsImageContentType("image/svg+xml; charset=utf-8"))
+	assert.False(t, IsImageContentType("text/markdown"))
+	assert.False(t, IsImageContentType(""))
+	assert.False(t, IsImageContentType("image/png png"))
+}
+
+func TestIsToolEnabled(t *testing.T) {
+	enabled, disabled := true, false
+	assert.True(t, IsToolEnabled(&ToolManifest{Name: "unset"}))
+	assert.True(t, IsToolEnabled(&ToolManifest{Name: "enabled", Enabled: &enabled}))
+	assert.False(t, IsToolEnabled(&ToolManifest{Name: "disabled", Enabled: &disabled}))
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/semver"
+)
+
+// devOrlaVersion is the version of orla builds without version information
+const devOrlaVersion = "dev"
+
+var (
+	orlaVersion   = devOrlaVersion
+	orlaVersionMu sync.RWMutex
+)
+
+// SetOrlaVersion records the version of the running orla binary (set from build flags in main)
+func SetOrlaVersion(version string) {
+	orlaVersionMu.Lock()
+	defer orlaVersionMu.Unlock()
+	orlaVersion = version
+}
+
+// OrlaVersion returns the version of the running orla binary
+func OrlaVersion() string {
+	orlaVersionMu.RLock()
+	defer orlaVersionMu.RUnlock()
+	return orlaVersion
+}
+
+// OrlaVersionTooOldError is returned when a tool requires a newer orla than the one running
+type OrlaVersionTooOldError struct {
+	Tool     string
+	Required string
+	Running  string
+}
+
+// Error returns the error message for the OrlaVersionTooOldError, including how to fix it
+func (e *OrlaVersionTooOldError) Error() string {
+	return fmt.Sprintf("tool '%s' requires orla %s or newer, but this is orla %s; upgrade orla to use it",
+		e.Tool, e.Required, e.Running)
+}
+
+// Interface guard for OrlaVersionTooOldError
+var _ error = &OrlaVersionTooOldError{}
+
+// toSemver converts a version with or without a leading "v" to the form expected by x/mod/semver
+func toSemver(version string) string {
+	if strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
+
+// ValidateMinOrlaVersion checks that a manifest's min_orla_version is a valid semantic version
+func ValidateMinOrlaVersion(minVersion string) error {
+	if minVersion == "" {
+		return nil
+	}
+	if !semver.IsValid(toSemver(minVersion)) {
+		return fmt.Errorf("invalid min_orla_version: %s (expected a semantic version such as 0.3.0)", minVersion)
+	}
+	return nil
+}
+
+// CheckMinOrlaVersion returns an OrlaVersionTooOldError if the running orla is older than
+// minVersion. Development builds and builds without a semantic version are assumed to be new
+// enough.
+func CheckMinOrlaVersion(tool, minVersion string) error {
+	if minVersion == "" {
+		return nil
+	}
+	if err := ValidateMinOrlaVersion(minVersion); err != nil {
+		return err
+	}
+
+	running := OrlaVersion()
+	if !semver.IsValid(toSemver(running)) {
+		return nil
+	}
+
+	if semver.Compare(toSemver(running), toSemver(minVersion)) < 0 {
+		return &OrlaVersionTooOldError{Tool: tool, Required: minVersion, Running: running}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setOrlaVersion(t *testing.T, version string) {
+	t.Helper()
+	original := OrlaVersion()
+	SetOrlaVersion(version)
+	t.Cleanup(func() { SetOrlaVersion(original) })
+}
+
+func TestValidateMinOrlaVersion(t *testing.T) {
+	assert.NoError(t, ValidateMinOrlaVersion(""))
+	assert.NoError(t, ValidateMinOrlaVersion("0.3.0"))
+	assert.NoError(t, ValidateMinOrlaVersion("v1.2.3"))
+
+	err := ValidateMinOrlaVersion("latest")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid min_orla_version")
+}
+
+func TestCheckMinOrlaVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		running    string
+		minVersion string
+		wantErr    bool
+	}{
+		{name: "no requirement", running: "0.1.0", minVersion: ""},
+		{name: "same version", running: "0.3.0", minVersion: "0.3.0"},
+		{name: "newer orla", running: "v0.4.1", minVersion: "0.3.0"},
+		{name: "older orla", running: "0.2.9", minVersion: "0.3.0", wantErr: true},
+		{name: "older orla with v prefix", running: "v0.2.0", minVersion: "v1.0.0", wantErr: true},
+		{name: "dev build", running: "dev", minVersion: "99.0.0"},
+		{name: "commit hash build", running: "abc1234", minVersion: "99.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setOrlaVersion(t, tt.running)
+
+			err := CheckMinOrlaVersion("my-tool", tt.minVersion)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+
+			var tooOld *OrlaVersionTooOldError
+			require.ErrorAs(t, err, &tooOld)
+			assert.Equal(t, "my-tool", tooOld.Tool)
+			assert.Contains(t, err.Error(), "upgrade orla")
+		})
+	}
+}
+
+func TestCheckMinOrlaVersion_InvalidRequirement(t *testing.T) {
+	setOrlaVersion(t, "0.3.0")
+
+	err := CheckMinOrlaVersion("my-tool", "not-a-version")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid min_orla_version")
+}
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ResolveToolDirs makes the tool's working_dir and stdin_file_root absolute, resolving relative
+// ones against baseDir, the directory of the tool's manifest
+func ResolveToolDirs(tool *ToolManifest, baseDir string) error {
+	for _, dir := range []struct {
+		key  string
+		path *string
+	}{
+		{"working_dir", &tool.WorkingDir},
+		{"stdin_file_root", &tool.StdinFileRoot},
+	} {
+		if *dir.path == "" || filepath.IsAbs(*dir.path) {
+			continue
+		}
+
+		absDir, err := filepath.Abs(filepath.Join(baseDir, *dir.path))
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", dir.key, err)
+		}
+		*dir.path = absDir
+	}
+	return nil
+}
+
+// CheckWorkingDir checks that dir exists and is a directory that tools can run in
+func CheckWorkingDir(dir string) error {
+	info, err := os.Stat(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("working directory %s does not exist", dir)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot access working directory %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("working directory %s is not a directory", dir)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveToolDirs(t *testing.T) {
+	baseDir := t.TempDir()
+
+	tool := &ToolManifest{Name: "relative", WorkingDir: "data", StdinFileRoot: "inputs"}
+	require.NoError(t, ResolveToolDirs(tool, baseDir))
+	assert.Equal(t, filepath.Join(baseDir, "data"), tool.WorkingDir)
+	assert.Equal(t, filepath.Join(baseDir, "inputs"), tool.StdinFileRoot)
+
+	absDir := t.TempDir()
+	tool = &ToolManifest{Name: "absolute", WorkingDir: absDir}
+	require.NoError(t, ResolveToolDirs(tool, baseDir))
+	assert.Equal(t, absDir, tool.WorkingDir)
+
+	tool = &ToolManifest{Name: "unset"}
+	require.NoError(t, ResolveToolDirs(tool, baseDir))
+	assert.Empty(t, tool.WorkingDir)
+}
+
+func TestCheckWorkingDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, CheckWorkingDir(tmpDir))
+
+	err := CheckWorkingDir(filepath.Join(tmpDir, "missing"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not exist")
+
+	filePath := filepath.Join(tmpDir, "file")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filePath, []byte("data"), 0644))
+	err = CheckWorkingDir(filePath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not a directory")
+}
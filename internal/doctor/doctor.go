@@ -0,0 +1,270 @@
+// Package doctor implements orla doctor, which diagnoses common setup problems.
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/model"
+)
+
+// modelCheckTimeout bounds the model provider check, which may contact a remote API
+const modelCheckTimeout = 10 * time.Second
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	// StatusPass means the check found no problem
+	StatusPass Status = "PASS"
+	// StatusWarn means orla works, but some features will not until the problem is fixed
+	StatusWarn Status = "WARN"
+	// StatusFail means orla will not work until the problem is fixed
+	StatusFail Status = "FAIL"
+)
+
+// CheckResult is the outcome of a single check, with what to do about a problem
+type CheckResult struct {
+	Name        string
+	Status      Status
+	Message     string
+	Remediation string // empty for checks that passed
+}
+
+// These are swapped in tests to simulate the environment
+var (
+	lookPath    = exec.LookPath
+	newProvider = model.NewProvider
+	loadConfig  = config.LoadConfig
+)
+
+// Options configures orla doctor
+type Options struct {
+	ConfigPath string // config file to check, or empty for the config orla would load
+	Writer     io.Writer
+}
+
+// Run runs every check and writes a checklist of their results. It returns an error if any check
+// failed; warnings do not fail.
+func Run(ctx context.Context, opts Options) error {
+	if opts.Writer == nil {
+		opts.Writer = os.Stdout
+	}
+
+	results := RunChecks(ctx, opts.ConfigPath)
+	failed, warned := 0, 0
+	for _, result := range results {
+		_, _ = fmt.Fprintf(opts.Writer, "%s %s: %s\n", result.Status, result.Name, result.Message)
+		if result.Remediation != "" {
+			for line := range strings.SplitSeq(result.Remediation, "\n") {
+				_, _ = fmt.Fprintf(opts.Writer, "  %s\n", line)
+			}
+		}
+		switch result.Status {
+		case StatusFail:
+			failed++
+		case StatusWarn:
+			warned++
+		}
+	}
+
+	switch {
+	case failed > 0:
+		return fmt.Errorf("%d of %d checks failed", failed, len(results))
+	case warned > 0:
+		_, _ = fmt.Fprintf(opts.Writer, "No checks failed, %d of %d checks warned\n", warned, len(results))
+	default:
+		_, _ = fmt.Fprintf(opts.Writer, "All %d checks passed\n", len(results))
+	}
+	return nil
+}
+
+// RunChecks runs every check in order. The checks that need the config are skipped if it cannot
+// be loaded.
+func RunChecks(ctx context.Context, configPath string) []CheckResult {
+	configResult, cfg := CheckConfig(configPath)
+	results := []CheckResult{configResult, CheckGit()}
+	if cfg == nil {
+		return results
+	}
+
+	results = append(results, CheckToolsDir(cfg.ToolsDir), CheckModelProvider(ctx, cfg))
+	if cfg.ToolsRegistry != nil {
+		tools := cfg.ToolsRegistry.ListTools()
+		slices.SortFunc(tools, func(a, b *core.ToolManifest) int { return strings.Compare(a.Name, b.Name) })
+		for _, tool := range tools {
+			results = append(results, CheckTool(tool))
+		}
+	}
+	return results
+}
+
+// CheckConfig loads and validates the config, returning it if it is valid
+func CheckConfig(configPath string) (CheckResult, *config.OrlaConfig) {
+	result := CheckResult{Name: "config"}
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		result.Status = StatusFail
+		result.Message = err.Error()
+		result.Remediation = "Fix the config file, or run orla doctor --config with the path of a valid orla.yaml"
+		return result, nil
+	}
+
+	result.Status = StatusPass
+	if used := viper.ConfigFileUsed(); used != "" {
+		result.Message = fmt.Sprintf("loaded %s", used)
+	} else {
+		result.Message = "no config file found, using the defaults"
+	}
+	if profile := config.ActiveProfile(); profile != "" {
+		result.Message += fmt.Sprintf(", with profile %s", profile)
+	}
+	return result, cfg
+}
+
+// CheckGit checks that git, which installing tools from a registry needs, is on the PATH
+func CheckGit() CheckResult {
+	result := CheckResult{Name: "git"}
+	path, err := lookPath("git")
+	if err != nil {
+		result.Status = StatusFail
+		result.Message = "git was not found on the PATH, so tools cannot be installed from a registry"
+		result.Remediation = "Install git (https://git-scm.com/downloads) and make sure it is on the PATH"
+		return result
+	}
+
+	result.Status = StatusPass
+	result.Message = fmt.Sprintf("found %s", path)
+	return result
+}
+
+// CheckToolsDir checks that the tools directory exists and can be read. A missing directory only
+// warns, as orla tool install creates it.
+func CheckToolsDir(toolsDir string) CheckResult {
+	result := CheckResult{Name: "tools directory"}
+	if toolsDir == "" {
+		result.Status = StatusPass
+		result.Message = "tools are listed in the config's tools_registry"
+		return result
+	}
+
+	info, err := os.Stat(toolsDir)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		result.Status = StatusWarn
+		result.Message = fmt.Sprintf("%s does not exist, so no tools are installed", toolsDir)
+		result.Remediation = "Install a tool with orla tool install TOOL-NAME, which creates it, or set tools_dir in the config"
+		return result
+	case err != nil:
+		result.Status = StatusFail
+		result.Message = fmt.Sprintf("cannot access %s: %v", toolsDir, err)
+		result.Remediation = fmt.Sprintf("Check the permissions of %s and its parent directories", toolsDir)
+		return result
+	case !info.IsDir():
+		result.Status = StatusFail
+		result.Message = fmt.Sprintf("%s is not a directory", toolsDir)
+		result.Remediation = "Set tools_dir in the config to a directory"
+		return result
+	}
+
+	if _, err := os.ReadDir(toolsDir); err != nil {
+		result.Status = StatusFail
+		result.Message = fmt.Sprintf("cannot read %s: %v", toolsDir, err)
+		result.Remediation = fmt.Sprintf("Make %s readable by the user running orla", toolsDir)
+		return result
+	}
+
+	result.Status = StatusPass
+	result.Message = toolsDir
+	return result
+}
+
+// CheckModelProvider checks that the configured model's provider is ready and has the model.
+// Without a model only agent mode is unavailable, so it warns.
+func CheckModelProvider(ctx context.Context, cfg *config.OrlaConfig) CheckResult {
+	result := CheckResult{Name: "model provider"}
+	if cfg.Model == "" {
+		result.Status = StatusWarn
+		result.Message = "no model is configured, so orla agent and orla chat are unavailable"
+		result.Remediation = "Set model in the config, e.g. model: ollama:qwen3:0.6b"
+		return result
+	}
+
+	provider, err := newProvider(cfg.Model, cfg)
+	if err != nil {
+		result.Status = StatusFail
+		result.Message = err.Error()
+		result.Remediation = fmt.Sprintf("Set model in the config to PROVIDER:MODEL, with one of the providers %s", strings.Join(model.SupportedProviders(), ", "))
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, modelCheckTimeout)
+	defer cancel()
+	if err := model.Preflight(ctx, provider); err != nil {
+		result.Status = StatusFail
+		switch {
+		case errors.Is(err, model.ErrOllamaNotRunning):
+			result.Message = "Ollama is installed but not running"
+			result.Remediation = model.OllamaStartHint
+		case errors.Is(err, model.ErrOllamaNotInstalled):
+			result.Message = "Ollama is not installed"
+			result.Remediation = model.OllamaInstallHint
+		default:
+			result.Message = err.Error()
+			result.Remediation = fmt.Sprintf("Check that the %s provider is reachable and that any API key it needs is set", provider.Name())
+		}
+		return result
+	}
+
+	result.Status = StatusPass
+	result.Message = fmt.Sprintf("%s is ready", cfg.Model)
+	return result
+}
+
+// CheckTool checks that a tool can be started: that its entrypoint exists and is executable, or
+// that the interpreter, shell, or package manager that runs it is on the PATH
+func CheckTool(tool *core.ToolManifest) CheckResult {
+	result := CheckResult{Name: fmt.Sprintf("tool %s", tool.Name)}
+
+	if tool.Command == "" && !core.IsPackageTool(tool) {
+		info, err := os.Stat(tool.Path)
+		if err != nil {
+			result.Status = StatusFail
+			result.Message = fmt.Sprintf("entrypoint %s cannot be found: %v", tool.Path, err)
+			result.Remediation = fmt.Sprintf("Reinstall the tool with orla reinstall %s", tool.Name)
+			return result
+		}
+		if tool.Interpreter == "" && !core.IsExecutable(info) {
+			result.Status = StatusFail
+			result.Message = fmt.Sprintf("entrypoint %s is not executable", tool.Path)
+			result.Remediation = fmt.Sprintf("Run chmod +x %s, or set interpreter in the tool's tool.yaml", tool.Path)
+			return result
+		}
+	}
+
+	// Programs other than the entrypoint are looked up on the PATH when the tool runs
+	program := core.TraceCommand(tool, nil).Program
+	if program != tool.Path {
+		if _, err := lookPath(program); err != nil {
+			result.Status = StatusFail
+			result.Message = fmt.Sprintf("%s, which runs the tool, was not found on the PATH", program)
+			result.Remediation = fmt.Sprintf("Install %s or add it to the PATH", program)
+			return result
+		}
+	}
+
+	result.Status = StatusPass
+	result.Message = "can be run"
+	return result
+}
@@ -0,0 +1,211 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/model"
+	"github.com/dorcha-inc/orla/internal/state"
+)
+
+const windowsOS = "windows"
+
+// fakeProvider is a model provider whose readiness is controlled by the test
+type fakeProvider struct {
+	ensureReadyErr error
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) Chat(ctx context.Context, messages []model.Message, tools []*mcp.Tool, stream bool) (*model.Response, <-chan model.StreamEvent, error) {
+	return &model.Response{}, nil, nil
+}
+
+func (p *fakeProvider) EnsureReady(ctx context.Context) error { return p.ensureReadyErr }
+
+// setLookPath replaces the PATH lookup with one that finds only the given programs
+func setLookPath(t *testing.T, found ...string) {
+	t.Helper()
+	original := lookPath
+	lookPath = func(file string) (string, error) {
+		for _, name := range found {
+			if file == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", exec.ErrNotFound
+	}
+	t.Cleanup(func() { lookPath = original })
+}
+
+// setProvider replaces the model provider with provider, or with a failure to create one if err is set
+func setProvider(t *testing.T, provider model.Provider, err error) {
+	t.Helper()
+	original := newProvider
+	newProvider = func(string, *config.OrlaConfig) (model.Provider, error) { return provider, err }
+	t.Cleanup(func() { newProvider = original })
+}
+
+// setConfig replaces loading the config with returning cfg, or err if it is set
+func setConfig(t *testing.T, cfg *config.OrlaConfig, err error) {
+	t.Helper()
+	original := loadConfig
+	loadConfig = func(string) (*config.OrlaConfig, error) { return cfg, err }
+	t.Cleanup(func() { loadConfig = original })
+}
+
+func TestCheckGit(t *testing.T) {
+	setLookPath(t, "git")
+	result := CheckGit()
+	assert.Equal(t, StatusPass, result.Status)
+	assert.Equal(t, "found /usr/bin/git", result.Message)
+
+	setLookPath(t)
+	result = CheckGit()
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Contains(t, result.Remediation, "Install git")
+}
+
+func TestCheckConfig(t *testing.T) {
+	setConfig(t, nil, errors.New("timeout must be at least 1 second, got 0"))
+	result, cfg := CheckConfig("orla.yaml")
+	assert.Nil(t, cfg)
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Contains(t, result.Message, "timeout must be at least 1 second")
+	assert.NotEmpty(t, result.Remediation)
+
+	setConfig(t, &config.OrlaConfig{}, nil)
+	result, cfg = CheckConfig("")
+	assert.NotNil(t, cfg)
+	assert.Equal(t, StatusPass, result.Status)
+}
+
+func TestCheckToolsDir(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, StatusPass, CheckToolsDir(dir).Status)
+
+	missing := CheckToolsDir(filepath.Join(dir, "missing"))
+	assert.Equal(t, StatusWarn, missing.Status)
+	assert.Contains(t, missing.Remediation, "orla tool install")
+
+	filePath := filepath.Join(dir, "file")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filePath, nil, 0644))
+	notDir := CheckToolsDir(filePath)
+	assert.Equal(t, StatusFail, notDir.Status)
+	assert.Contains(t, notDir.Message, "is not a directory")
+
+	// Tools listed in the config have no tools directory
+	assert.Equal(t, StatusPass, CheckToolsDir("").Status)
+}
+
+func TestCheckModelProvider(t *testing.T) {
+	cfg := &config.OrlaConfig{Model: "ollama:qwen3:0.6b"}
+
+	setProvider(t, &fakeProvider{}, nil)
+	result := CheckModelProvider(context.Background(), cfg)
+	assert.Equal(t, StatusPass, result.Status)
+	assert.Equal(t, "ollama:qwen3:0.6b is ready", result.Message)
+
+	// Ollama not running gets the hint to start it
+	setProvider(t, &fakeProvider{ensureReadyErr: fmt.Errorf("%w. %s", model.ErrOllamaNotRunning, model.OllamaStartHint)}, nil)
+	result = CheckModelProvider(context.Background(), cfg)
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Equal(t, "Ollama is installed but not running", result.Message)
+	assert.Contains(t, result.Remediation, "brew services start ollama")
+
+	setProvider(t, &fakeProvider{ensureReadyErr: errors.New("ANTHROPIC_API_KEY is not set")}, nil)
+	result = CheckModelProvider(context.Background(), cfg)
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Contains(t, result.Message, "ANTHROPIC_API_KEY is not set")
+
+	setProvider(t, nil, errors.New("unsupported model provider: foo"))
+	result = CheckModelProvider(context.Background(), cfg)
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Contains(t, result.Remediation, "PROVIDER:MODEL")
+
+	result = CheckModelProvider(context.Background(), &config.OrlaConfig{})
+	assert.Equal(t, StatusWarn, result.Status)
+}
+
+func TestCheckTool(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping file permission test on Windows")
+	}
+	setLookPath(t, "python3", "npx")
+	dir := t.TempDir()
+
+	executable := filepath.Join(dir, "tool")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(executable, []byte("#!/bin/sh\necho hi\n"), 0755))
+	assert.Equal(t, StatusPass, CheckTool(&core.ToolManifest{Name: "ok", Path: executable}).Status)
+
+	script := filepath.Join(dir, "tool.py")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(script, []byte("print('hi')\n"), 0644))
+	result := CheckTool(&core.ToolManifest{Name: "script", Path: script})
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Contains(t, result.Remediation, "chmod +x "+script)
+
+	// A script run by an interpreter does not need to be executable, but the interpreter must be found
+	assert.Equal(t, StatusPass, CheckTool(&core.ToolManifest{Name: "py", Path: script, Interpreter: "python3"}).Status)
+	result = CheckTool(&core.ToolManifest{Name: "rb", Path: script, Interpreter: "ruby"})
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Contains(t, result.Message, "ruby, which runs the tool, was not found")
+
+	result = CheckTool(&core.ToolManifest{Name: "gone", Path: filepath.Join(dir, "missing")})
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Contains(t, result.Remediation, "orla reinstall gone")
+
+	// Package tools have no entrypoint, only a package manager
+	npxTool := &core.ToolManifest{Name: "fs", Runtime: &core.RuntimeConfig{Mode: core.RuntimeModeNpx, Package: "@scope/fs"}}
+	assert.Equal(t, StatusPass, CheckTool(npxTool).Status)
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	toolPath := filepath.Join(dir, "broken.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(toolPath, []byte("echo hi\n"), 0644))
+	registry := &state.ToolsRegistry{}
+	require.NoError(t, registry.AddTool(&core.ToolManifest{Name: "broken", Path: toolPath}))
+
+	setLookPath(t, "git")
+	setProvider(t, &fakeProvider{}, nil)
+	setConfig(t, &config.OrlaConfig{ToolsDir: dir, ToolsRegistry: registry, Model: "ollama:qwen3:0.6b"}, nil)
+
+	var out bytes.Buffer
+	err := Run(context.Background(), Options{Writer: &out})
+	require.Error(t, err)
+	assert.Equal(t, "1 of 5 checks failed", err.Error())
+	assert.Contains(t, out.String(), "PASS git: found /usr/bin/git\n")
+	assert.Contains(t, out.String(), "FAIL tool broken: entrypoint "+toolPath+" is not executable\n  Run chmod +x")
+
+	// Only failures fail the run
+	// #nosec G302 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.Chmod(toolPath, 0755))
+	setProvider(t, &fakeProvider{}, nil)
+	setConfig(t, &config.OrlaConfig{ToolsDir: filepath.Join(dir, "missing"), ToolsRegistry: registry, Model: "ollama:qwen3:0.6b"}, nil)
+	out.Reset()
+	require.NoError(t, Run(context.Background(), Options{Writer: &out}))
+	assert.Contains(t, out.String(), "WARN tools directory:")
+	assert.Contains(t, out.String(), "No checks failed, 1 of 5 checks warned")
+
+	// Without a valid config only the checks that do not need it run
+	setConfig(t, nil, errors.New("bad config"))
+	out.Reset()
+	require.EqualError(t, Run(context.Background(), Options{Writer: &out}), "1 of 2 checks failed")
+}
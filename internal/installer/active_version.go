@@ -0,0 +1,76 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ActiveVersionLinkName is the symlink in a tool's directory that points at the version in use,
+// e.g. ~/.orla/tools/fs/current -> 1.2.0. Without it, the newest installed version is used.
+const ActiveVersionLinkName = "current"
+
+// SetActiveVersion atomically points the active version link of the tool in toolDir at version.
+// The new link is created under a temporary name and renamed over the old one, so a process that
+// resolves the link sees either the old version or the new one, never a missing link.
+func SetActiveVersion(toolDir, version string) error {
+	if version == "" || strings.ContainsAny(version, `/\`) || version == "." || version == ".." {
+		return fmt.Errorf("invalid version '%s'", version)
+	}
+	if _, err := os.Stat(filepath.Join(toolDir, version)); err != nil {
+		return fmt.Errorf("version %s is not installed: %w", version, err)
+	}
+
+	// Hidden names are skipped when listing installed versions
+	tempLink := filepath.Join(toolDir, fmt.Sprintf(".%s-%d-%d", ActiveVersionLinkName, os.Getpid(), time.Now().UnixNano()))
+	// The target is relative so that the tools directory can be moved
+	if err := os.Symlink(version, tempLink); err != nil {
+		return fmt.Errorf("failed to create active version link: %w", err)
+	}
+	if err := os.Rename(tempLink, filepath.Join(toolDir, ActiveVersionLinkName)); err != nil {
+		if errRemove := os.Remove(tempLink); errRemove != nil {
+			zap.L().Warn("Failed to remove temporary active version link", zap.String("path", tempLink), zap.Error(errRemove))
+		}
+		return fmt.Errorf("failed to switch active version link: %w", err)
+	}
+	return nil
+}
+
+// ActiveVersion returns the version the active version link of the tool in toolDir points at, or
+// "" if the tool has no active version link. A link to a version that is not installed is
+// ignored with a warning.
+func ActiveVersion(toolDir string) string {
+	linkPath := filepath.Join(toolDir, ActiveVersionLinkName)
+	target, err := os.Readlink(linkPath)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			zap.L().Warn("Failed to read active version link, using the newest version", zap.String("path", linkPath), zap.Error(err))
+		}
+		return ""
+	}
+
+	version := filepath.Base(target)
+	if info, err := os.Stat(filepath.Join(toolDir, version)); err != nil || !info.IsDir() {
+		zap.L().Warn("Active version link points at a version that is not installed, using the newest version",
+			zap.String("path", linkPath), zap.String("version", version))
+		return ""
+	}
+	return version
+}
+
+// activateInstalledVersion makes the version installed in installDir the active version of its tool
+func activateInstalledVersion(progress ProgressReporter, toolName, installDir string) error {
+	version := filepath.Base(installDir)
+	if err := SetActiveVersion(filepath.Dir(installDir), version); err != nil {
+		return fmt.Errorf("installed version %s but failed to make it the active version: %w", version, err)
+	}
+	zap.L().Info("Switched active tool version", zap.String("tool", toolName), zap.String("version", version))
+	reportProgress(progress, toolName, ProgressStageActivated, "switched active version to %s", version)
+	return nil
+}
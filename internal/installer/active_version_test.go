@@ -0,0 +1,155 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/registry"
+)
+
+// makeVersionDirs creates empty version directories in toolDir
+func makeVersionDirs(t *testing.T, toolDir string, versions ...string) {
+	t.Helper()
+	for _, version := range versions {
+		// #nosec G301 -- test directory permissions are acceptable for temporary test files
+		require.NoError(t, os.MkdirAll(filepath.Join(toolDir, version), 0755))
+	}
+}
+
+func TestSetActiveVersion(t *testing.T) {
+	toolDir := t.TempDir()
+	makeVersionDirs(t, toolDir, "1.0.0", "2.0.0")
+	assert.Empty(t, ActiveVersion(toolDir), "a tool without an active version link has no active version")
+
+	require.NoError(t, SetActiveVersion(toolDir, "1.0.0"))
+	assert.Equal(t, "1.0.0", ActiveVersion(toolDir))
+
+	require.NoError(t, SetActiveVersion(toolDir, "2.0.0"))
+	assert.Equal(t, "2.0.0", ActiveVersion(toolDir))
+
+	target, err := os.Readlink(filepath.Join(toolDir, ActiveVersionLinkName))
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", target, "the link should be relative to the tool directory")
+
+	// No temporary links are left behind
+	entries, err := os.ReadDir(toolDir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{"1.0.0", "2.0.0", ActiveVersionLinkName}, names)
+}
+
+func TestSetActiveVersion_Invalid(t *testing.T) {
+	toolDir := t.TempDir()
+	makeVersionDirs(t, toolDir, "1.0.0")
+
+	for _, version := range []string{"", "..", "../other", "3.0.0"} {
+		assert.Error(t, SetActiveVersion(toolDir, version), "version %q", version)
+	}
+	assert.NoFileExists(t, filepath.Join(toolDir, ActiveVersionLinkName))
+}
+
+func TestActiveVersion_DanglingLink(t *testing.T) {
+	toolDir := t.TempDir()
+	require.NoError(t, os.Symlink("9.9.9", filepath.Join(toolDir, ActiveVersionLinkName)))
+	assert.Empty(t, ActiveVersion(toolDir), "a link to a version that is not installed should be ignored")
+}
+
+// versionedCloneRunner returns a tool git runner whose clones write a valid tool named name at the
+// version of the cloned tag
+func versionedCloneRunner(name string) *registry.MockGitRunner {
+	return &registry.MockGitRunner{
+		RunFunc: func(_, dir string, args ...string) ([]byte, error) {
+			// clone --depth 1 --branch <tag> <repository> <target>
+			tag, targetDir := args[4], args[len(args)-1]
+			manifestData, err := yaml.Marshal(&core.ToolManifest{
+				Name:        name,
+				Version:     strings.TrimPrefix(tag, "v"),
+				Description: "A tool with several versions",
+				Entrypoint:  "tool.sh",
+			})
+			if err != nil {
+				return nil, err
+			}
+			// #nosec G301 -- test directory permissions are acceptable for temporary test files
+			if err := os.MkdirAll(targetDir, 0755); err != nil {
+				return nil, err
+			}
+			// #nosec G306 -- test file permissions are acceptable for temporary test files
+			if err := os.WriteFile(filepath.Join(targetDir, ToolManifestFileName), manifestData, 0644); err != nil {
+				return nil, err
+			}
+			// #nosec G306 -- test file permissions are acceptable for temporary test files
+			return nil, os.WriteFile(filepath.Join(targetDir, "tool.sh"), []byte("#!/bin/sh\necho "+tag+"\n"), 0755)
+		},
+	}
+}
+
+func TestInstallFromRegistry_Replace(t *testing.T) {
+	setGitRunner(t, versionedCloneRunner("tool-0"))
+	reg, _ := multiInstallTestRegistry(1)
+	toolsDir := t.TempDir()
+	toolDir := filepath.Join(toolsDir, "tool-0")
+
+	var events []ProgressEvent
+	require.NoError(t, installFromRegistry(reg, exampleRegistryURL, "tool-0", "v1.0.0", toolsDir, recordProgress(&events), false, true))
+	assert.Equal(t, "1.0.0", ActiveVersion(toolDir))
+	assert.Equal(t, ProgressStageActivated, events[len(events)-1].Stage)
+	assert.Equal(t, "switched active version to 1.0.0", events[len(events)-1].Message)
+
+	// Installing without --replace leaves the active version alone
+	require.NoError(t, installFromRegistry(reg, exampleRegistryURL, "tool-0", "v2.0.0", toolsDir, nil, false, false))
+	assert.DirExists(t, filepath.Join(toolDir, "2.0.0"))
+	assert.Equal(t, "1.0.0", ActiveVersion(toolDir))
+
+	// With --replace the link is switched once the install succeeded
+	require.NoError(t, installFromRegistry(reg, exampleRegistryURL, "tool-0", "v3.0.0", toolsDir, nil, false, true))
+	assert.Equal(t, "3.0.0", ActiveVersion(toolDir))
+
+	// Switching back to an older version is allowed
+	require.NoError(t, installFromRegistry(reg, exampleRegistryURL, "tool-0", "v2.0.0", toolsDir, nil, false, true))
+	assert.Equal(t, "2.0.0", ActiveVersion(toolDir))
+}
+
+func TestInstallFromRegistry_ReplaceFailedInstall(t *testing.T) {
+	noCloneRetryBackoff(t)
+	setGitRunner(t, versionedCloneRunner("tool-0"))
+	reg, _ := multiInstallTestRegistry(1)
+	toolsDir := t.TempDir()
+	toolDir := filepath.Join(toolsDir, "tool-0")
+
+	require.NoError(t, installFromRegistry(reg, exampleRegistryURL, "tool-0", "v1.0.0", toolsDir, nil, false, true))
+
+	// A version that fails verification is not installed and does not become active
+	reg.Tools[0].Checksums = map[string]string{"v2.0.0": strings.Repeat("0", 64)}
+	err := installFromRegistry(reg, exampleRegistryURL, "tool-0", "v2.0.0", toolsDir, nil, false, true)
+	var mismatchErr *ChecksumMismatchError
+	require.ErrorAs(t, err, &mismatchErr)
+	assert.NoDirExists(t, filepath.Join(toolDir, "2.0.0"))
+	assert.Equal(t, "1.0.0", ActiveVersion(toolDir))
+
+	// So does a version whose clone fails
+	setGitRunner(t, &registry.MockGitRunner{
+		RunFunc: func(_, dir string, args ...string) ([]byte, error) {
+			return []byte("fatal: unable to access repository"), assert.AnError
+		},
+	})
+	err = installFromRegistry(reg, exampleRegistryURL, "tool-0", "v3.0.0", toolsDir, nil, false, true)
+	require.Error(t, err)
+	assert.Equal(t, "1.0.0", ActiveVersion(toolDir))
+}
+
+func TestInstallFromRegistry_ReplacePackageTool(t *testing.T) {
+	toolsDir := t.TempDir()
+	require.NoError(t, installFromRegistry(packageTestRegistry(), exampleRegistryURL, "fs-server", "", toolsDir, nil, false, true))
+	assert.Equal(t, packageTestRegistry().Tools[0].Version, ActiveVersion(filepath.Join(toolsDir, "fs-server")))
+}
@@ -0,0 +1,243 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// ManifestIssue is a problem found in a tool manifest by CheckManifest
+type ManifestIssue struct {
+	// Line is the line of tool.yaml the problem is at, or 0 if it is not at a line (e.g. a missing field)
+	Line    int
+	Message string
+}
+
+func (i ManifestIssue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("line %d: %s", i.Line, i.Message)
+	}
+	return i.Message
+}
+
+var (
+	// invalidFieldPattern matches the field named by a validation error, e.g. "invalid runtime.mode: x"
+	invalidFieldPattern = regexp.MustCompile(`^invalid ([a-z_.]+)`)
+	// yamlLinePattern matches the line number in a YAML parse error, e.g. "yaml: line 3: ..."
+	yamlLinePattern = regexp.MustCompile(`line (\d+):`)
+)
+
+// CheckManifest checks the tool.yaml in toolDir with the same validation as installing the tool,
+// and also checks that its MCP schemas are valid JSON schemas and that its entrypoint can be run.
+// It returns the manifest, or nil if it cannot be parsed, and the problems found, each at the line
+// of tool.yaml it concerns where possible. The manifest is valid if there are no problems.
+func CheckManifest(toolDir string) (*core.ToolManifest, []ManifestIssue) {
+	manifest, err := LoadManifest(toolDir)
+	if err != nil {
+		return nil, []ManifestIssue{parseIssue(err)}
+	}
+
+	lines := loadManifestLines(toolDir)
+
+	// All missing required fields are reported at once, the rest of the validation needs them
+	if issues := requiredFieldIssues(manifest, lines); len(issues) > 0 {
+		return manifest, issues
+	}
+
+	var issues []ManifestIssue
+	if err := ValidateManifest(manifest, toolDir); err != nil {
+		issues = append(issues, ManifestIssue{Line: lines.lineOf(validationErrorField(err)), Message: err.Error()})
+	}
+	issues = append(issues, schemaIssues(manifest, lines)...)
+	if issue, ok := entrypointIssue(manifest, toolDir, lines); ok {
+		issues = append(issues, issue)
+	}
+	return manifest, issues
+}
+
+// parseIssue reports a manifest that cannot be loaded, at the line the YAML parser names if any
+func parseIssue(err error) ManifestIssue {
+	issue := ManifestIssue{Message: err.Error()}
+	if match := yamlLinePattern.FindStringSubmatch(err.Error()); match != nil {
+		if line, convErr := strconv.Atoi(match[1]); convErr == nil {
+			issue.Line = line
+		}
+	}
+	return issue
+}
+
+// requiredFieldIssues reports the fields that fail the manifest's struct validation, such as
+// missing required fields
+func requiredFieldIssues(manifest *core.ToolManifest, lines manifestLines) []ManifestIssue {
+	err := validate.Struct(manifest)
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return []ManifestIssue{{Message: fmt.Sprintf("manifest validation failed: %v", err)}}
+	}
+
+	issues := make([]ManifestIssue, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		field := manifestFieldName(fieldErr.StructField())
+		message := fmt.Sprintf("invalid %s: failed the %s check", field, fieldErr.Tag())
+		if fieldErr.Tag() == "required" {
+			message = fmt.Sprintf("missing required field %q", field)
+		}
+		// A required field that is present but empty is reported at its line
+		issues = append(issues, ManifestIssue{Line: lines[field], Message: message})
+	}
+	return issues
+}
+
+// manifestFieldName returns the tool.yaml name of a ToolManifest field
+func manifestFieldName(structField string) string {
+	field, ok := reflect.TypeFor[core.ToolManifest]().FieldByName(structField)
+	if !ok {
+		return structField
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	return name
+}
+
+// validationErrorField returns the tool.yaml field an error from ValidateManifest is about, or ""
+func validationErrorField(err error) string {
+	if strings.HasPrefix(err.Error(), "failed to validate entrypoint") {
+		return "entrypoint"
+	}
+	if match := invalidFieldPattern.FindStringSubmatch(err.Error()); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// schemaIssues reports MCP input and output schemas that are not valid JSON schemas
+func schemaIssues(manifest *core.ToolManifest, lines manifestLines) []ManifestIssue {
+	if manifest.MCP == nil {
+		return nil
+	}
+
+	var issues []ManifestIssue
+	for _, schema := range []struct {
+		field  string
+		schema map[string]any
+	}{
+		{"mcp.input_schema", manifest.MCP.InputSchema},
+		{"mcp.output_schema", manifest.MCP.OutputSchema},
+	} {
+		if schema.schema == nil {
+			continue
+		}
+		if _, err := core.CompileSchema(schema.schema); err != nil {
+			issues = append(issues, ManifestIssue{
+				Line:    lines.lineOf(schema.field),
+				Message: fmt.Sprintf("invalid %s: %v", schema.field, err),
+			})
+		}
+	}
+	return issues
+}
+
+// entrypointIssue reports an entrypoint that exists but cannot be run: it is not executable and
+// does not start with a #! line naming its interpreter. A missing entrypoint is reported by
+// ValidateManifest, and tools that run a package have no entrypoint.
+func entrypointIssue(manifest *core.ToolManifest, toolDir string, lines manifestLines) (ManifestIssue, bool) {
+	if manifest.Entrypoint == "" {
+		return ManifestIssue{}, false
+	}
+
+	root, err := os.OpenRoot(toolDir)
+	if err != nil {
+		return ManifestIssue{}, false
+	}
+	defer core.LogDeferredError(root.Close)
+
+	info, err := root.Stat(manifest.Entrypoint)
+	if err != nil || info.IsDir() || core.IsExecutable(info) {
+		return ManifestIssue{}, false
+	}
+
+	file, err := root.Open(manifest.Entrypoint)
+	if err != nil {
+		return ManifestIssue{}, false
+	}
+	defer core.LogDeferredError(file.Close)
+
+	prefix := make([]byte, 2)
+	if _, err := io.ReadFull(file, prefix); err == nil && string(prefix) == "#!" {
+		return ManifestIssue{}, false
+	}
+
+	return ManifestIssue{
+		Line:    lines.lineOf("entrypoint"),
+		Message: fmt.Sprintf("entrypoint %s is not executable and has no #! line (run chmod +x on it)", manifest.Entrypoint),
+	}, true
+}
+
+// manifestLines maps the dotted path of each key in a tool.yaml (e.g. "mcp.input_schema") to the
+// line it is on
+type manifestLines map[string]int
+
+// loadManifestLines reads the key lines of the tool.yaml in toolDir. Lines are only used to point
+// at problems, so a manifest that cannot be read has none.
+func loadManifestLines(toolDir string) manifestLines {
+	lines := manifestLines{}
+
+	// #nosec G304 -- toolDir is a tool directory the user asked to check
+	data, err := os.ReadFile(filepath.Join(toolDir, ToolManifestFileName))
+	if err != nil {
+		return lines
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil || len(document.Content) == 0 {
+		return lines
+	}
+	lines.add("", document.Content[0])
+	return lines
+}
+
+// add records the lines of the keys of node and of the mappings nested in it, under prefix
+func (l manifestLines) add(prefix string, node *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		path := key.Value
+		if prefix != "" {
+			path = prefix + "." + key.Value
+		}
+		l[path] = key.Line
+		l.add(path, value)
+	}
+}
+
+// lineOf returns the line of the key at path, or of its closest enclosing key, or 0 if neither is
+// in the manifest
+func (l manifestLines) lineOf(path string) int {
+	for path != "" {
+		if line, ok := l[path]; ok {
+			return line
+		}
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+	return 0
+}
@@ -0,0 +1,155 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckManifest_Valid(t *testing.T) {
+	toolDir := t.TempDir()
+	require.NoError(t, writeTestTool(toolDir, `name: test-tool
+version: 1.0.0
+description: Test tool
+entrypoint: tool.sh
+mcp:
+  input_schema:
+    type: object
+    properties:
+      city:
+        type: string
+`))
+
+	manifest, issues := CheckManifest(toolDir)
+	require.NotNil(t, manifest)
+	assert.Equal(t, "test-tool", manifest.Name)
+	assert.Empty(t, issues)
+}
+
+func TestCheckManifest_MissingRequiredFields(t *testing.T) {
+	toolDir := t.TempDir()
+	require.NoError(t, writeTestTool(toolDir, `name: test-tool
+description: ""
+entrypoint: tool.sh
+`))
+
+	_, issues := CheckManifest(toolDir)
+	assert.Equal(t, []ManifestIssue{
+		{Line: 0, Message: `missing required field "version"`},
+		{Line: 2, Message: `missing required field "description"`},
+	}, issues)
+}
+
+func TestCheckManifest_InvalidField(t *testing.T) {
+	toolDir := t.TempDir()
+	require.NoError(t, writeTestTool(toolDir, `name: test-tool
+version: 1.0.0
+description: Test tool
+entrypoint: tool.sh
+runtime:
+  mode: daemon
+`))
+
+	_, issues := CheckManifest(toolDir)
+	require.Len(t, issues, 1)
+	assert.Equal(t, 6, issues[0].Line)
+	assert.Contains(t, issues[0].Message, "invalid runtime.mode")
+}
+
+func TestCheckManifest_InvalidOutputJSONPath(t *testing.T) {
+	toolDir := t.TempDir()
+	require.NoError(t, writeTestTool(toolDir, `name: test-tool
+version: 1.0.0
+description: Test tool
+entrypoint: tool.sh
+mcp:
+  output_json_path: data.result
+`))
+
+	_, issues := CheckManifest(toolDir)
+	require.Len(t, issues, 1)
+	assert.Equal(t, 6, issues[0].Line)
+	assert.Contains(t, issues[0].Message, "invalid mcp.output_json_path 'data.result'")
+}
+
+func TestCheckManifest_MissingEntrypoint(t *testing.T) {
+	toolDir := t.TempDir()
+	require.NoError(t, writeTestTool(toolDir, `name: test-tool
+version: 1.0.0
+description: Test tool
+entrypoint: bin/missing
+`))
+
+	_, issues := CheckManifest(toolDir)
+	require.Len(t, issues, 1)
+	assert.Equal(t, 4, issues[0].Line)
+	assert.Contains(t, issues[0].Message, "failed to validate entrypoint")
+}
+
+func TestCheckManifest_InvalidSchema(t *testing.T) {
+	toolDir := t.TempDir()
+	require.NoError(t, writeTestTool(toolDir, `name: test-tool
+version: 1.0.0
+description: Test tool
+entrypoint: tool.sh
+mcp:
+  input_schema:
+    type: object
+    required: city
+  output_schema:
+    $ref: "#/missing"
+`))
+
+	_, issues := CheckManifest(toolDir)
+	require.Len(t, issues, 2)
+	assert.Equal(t, 6, issues[0].Line)
+	assert.Contains(t, issues[0].Message, "invalid mcp.input_schema")
+	assert.Equal(t, 9, issues[1].Line)
+	assert.Contains(t, issues[1].Message, "invalid mcp.output_schema")
+}
+
+func TestCheckManifest_EntrypointNotExecutable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping file permission test on Windows")
+	}
+
+	toolDir := t.TempDir()
+	require.NoError(t, writeTestTool(toolDir, `name: test-tool
+version: 1.0.0
+description: Test tool
+entrypoint: tool.sh
+`))
+	entrypointPath := filepath.Join(toolDir, "tool.sh")
+
+	// A non-executable script with a #! line can still be run through its interpreter
+	require.NoError(t, os.Chmod(entrypointPath, 0600))
+	_, issues := CheckManifest(toolDir)
+	assert.Empty(t, issues)
+
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(entrypointPath, []byte("echo test"), 0600))
+	_, issues = CheckManifest(toolDir)
+	require.Len(t, issues, 1)
+	assert.Equal(t, 4, issues[0].Line)
+	assert.Contains(t, issues[0].Message, "entrypoint tool.sh is not executable")
+}
+
+func TestCheckManifest_ParseError(t *testing.T) {
+	toolDir := t.TempDir()
+	require.NoError(t, writeTestTool(toolDir, "name: test-tool\nversion: [unclosed\n"))
+
+	manifest, issues := CheckManifest(toolDir)
+	assert.Nil(t, manifest)
+	require.Len(t, issues, 1)
+	assert.Positive(t, issues[0].Line)
+	assert.Contains(t, issues[0].Message, "failed to parse tool.yaml")
+}
+
+func TestManifestIssue_String(t *testing.T) {
+	assert.Equal(t, "line 3: bad value", ManifestIssue{Line: 3, Message: "bad value"}.String())
+	assert.Equal(t, `missing required field "name"`, ManifestIssue{Message: `missing required field "name"`}.String())
+}
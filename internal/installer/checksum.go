@@ -0,0 +1,115 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/registry"
+)
+
+// ChecksumMismatchError is returned when the files of a cloned tool do not match the checksum the
+// registry declares for its version
+type ChecksumMismatchError struct {
+	Tool     string
+	Tag      string
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch for tool '%s' at %s: registry declares %s, but the cloned files hash to %s",
+		e.Tool, e.Tag, e.Expected, e.Actual)
+}
+
+// Interface guard for ChecksumMismatchError
+var _ error = &ChecksumMismatchError{}
+
+// TreeChecksum returns the hex SHA256 checksum of the files in dir, skipping the .git directory.
+// The checksum is deterministic: it hashes one "<mode> <sha256 of file>  <path>" line per file,
+// sorted by path with '/' separators. The mode is 100755 for an executable file and 100644 for any
+// other, as git records them, so that a file losing or gaining its executable bit changes the
+// checksum. This is what
+//
+//	find . ! -type d ! -path './.git/*' | sed 's|^\./||' | LC_ALL=C sort |
+//	  while read -r f; do [ -x "$f" ] && m=100755 || m=100644; echo "$m $(sha256sum "$f")"; done | sha256sum
+//
+// prints when run in dir.
+func TreeChecksum(dir string) (string, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to open directory: %w", err)
+	}
+	defer core.LogDeferredError(root.Close)
+
+	var paths []string
+	err = fs.WalkDir(root.FS(), ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list files: %w", err)
+	}
+	sort.Strings(paths)
+
+	tree := sha256.New()
+	for _, path := range paths {
+		data, err := root.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file %s: %w", path, err)
+		}
+		info, err := root.Stat(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to stat file %s: %w", path, err)
+		}
+		mode := "100644"
+		if info.Mode().Perm()&0111 != 0 {
+			mode = "100755"
+		}
+		fileSum := sha256.Sum256(data)
+		if _, err := fmt.Fprintf(tree, "%s %s  %s\n", mode, hex.EncodeToString(fileSum[:]), path); err != nil {
+			return "", fmt.Errorf("failed to hash file %s: %w", path, err)
+		}
+	}
+	return hex.EncodeToString(tree.Sum(nil)), nil
+}
+
+// verifyToolChecksum checks the files cloned into dir against the checksum the registry declares
+// for tag. A version without a declared checksum is installed unverified, with a warning.
+func verifyToolChecksum(tool *registry.ToolEntry, tag, dir string) error {
+	expected, ok := tool.Checksums[tag]
+	if !ok {
+		zap.L().Warn("Registry declares no checksum for tool version, installing it unverified",
+			zap.String("tool", tool.Name), zap.String("tag", tag))
+		return nil
+	}
+
+	actual, err := TreeChecksum(dir)
+	if err != nil {
+		return fmt.Errorf("failed to compute checksum: %w", err)
+	}
+
+	expected = strings.ToLower(strings.TrimPrefix(expected, "sha256:"))
+	if actual != expected {
+		return &ChecksumMismatchError{Tool: tool.Name, Tag: tag, Expected: expected, Actual: actual}
+	}
+
+	zap.L().Debug("Tool checksum verified", zap.String("tool", tool.Name), zap.String("tag", tag))
+	return nil
+}
@@ -0,0 +1,175 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// writeChecksumTestTree writes files, keyed by slash separated path, to dir
+func writeChecksumTestTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		fullPath := filepath.Join(dir, filepath.FromSlash(path))
+		// #nosec G301 -- test directory permissions are acceptable for temporary test files
+		require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+		// #nosec G306 -- test file permissions are acceptable for temporary test files
+		require.NoError(t, os.WriteFile(fullPath, []byte(content), 0644))
+	}
+}
+
+func TestTreeChecksum(t *testing.T) {
+	files := map[string]string{
+		"tool.yaml":     "name: fs\n",
+		"bin/tool.sh":   "#!/bin/sh\necho ok\n",
+		"bin-extra.txt": "extra\n",
+	}
+	dir := t.TempDir()
+	writeChecksumTestTree(t, dir, files)
+
+	checksum, err := TreeChecksum(dir)
+	require.NoError(t, err)
+
+	// One "<mode> <file sha256>  <path>" line per file, sorted by path
+	var lines strings.Builder
+	for _, path := range []string{"bin-extra.txt", "bin/tool.sh", "tool.yaml"} {
+		fileSum := sha256.Sum256([]byte(files[path]))
+		lines.WriteString("100644 " + hex.EncodeToString(fileSum[:]) + "  " + path + "\n")
+	}
+	expected := sha256.Sum256([]byte(lines.String()))
+	assert.Equal(t, hex.EncodeToString(expected[:]), checksum)
+
+	// The .git directory is not part of the tool
+	writeChecksumTestTree(t, dir, map[string]string{".git/HEAD": "ref: refs/heads/main\n"})
+	withGit, err := TreeChecksum(dir)
+	require.NoError(t, err)
+	assert.Equal(t, checksum, withGit)
+
+	// Changing a file changes the checksum
+	writeChecksumTestTree(t, dir, map[string]string{"bin/tool.sh": "#!/bin/sh\nrm -rf ~\n"})
+	changed, err := TreeChecksum(dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, checksum, changed)
+}
+
+func TestTreeChecksum_Renamed(t *testing.T) {
+	dir := t.TempDir()
+	writeChecksumTestTree(t, dir, map[string]string{"a.txt": "same"})
+	before, err := TreeChecksum(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, os.Rename(filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")))
+	after, err := TreeChecksum(dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, before, after, "file paths are part of the checksum")
+}
+
+func TestTreeChecksum_ExecutableBit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping file mode test on Windows")
+	}
+
+	dir := t.TempDir()
+	writeChecksumTestTree(t, dir, map[string]string{"tool.sh": "#!/bin/sh\necho ok\n"})
+	before, err := TreeChecksum(dir)
+	require.NoError(t, err)
+
+	// #nosec G302 -- the test makes the entrypoint executable, as tools ship them
+	require.NoError(t, os.Chmod(filepath.Join(dir, "tool.sh"), 0755))
+	after, err := TreeChecksum(dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, before, after, "the executable bit is part of the checksum")
+}
+
+func TestTreeChecksum_MissingDirectory(t *testing.T) {
+	_, err := TreeChecksum(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+}
+
+// checksumTestToolChecksum returns the checksum of the files concurrentCloneRunner clones for name
+func checksumTestToolChecksum(t *testing.T, name string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, writeTestTool(dir, testToolManifest(name, "1.0.0")))
+	checksum, err := TreeChecksum(dir)
+	require.NoError(t, err)
+	return checksum
+}
+
+func TestInstallFromRegistry_Checksum(t *testing.T) {
+	expected := checksumTestToolChecksum(t, "tool-0")
+
+	tests := []struct {
+		name     string
+		checksum string
+	}{
+		{name: "plain", checksum: expected},
+		{name: "prefixed and upper case", checksum: "sha256:" + strings.ToUpper(expected)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setGitRunner(t, &concurrentCloneRunner{})
+
+			reg, _ := multiInstallTestRegistry(1)
+			reg.Tools[0].Checksums = map[string]string{"v1.0.0": tt.checksum}
+			toolsDir := t.TempDir()
+
+			require.NoError(t, installFromRegistry(reg, exampleRegistryURL, "tool-0", "v1.0.0", toolsDir, nil, false, false))
+			assert.FileExists(t, filepath.Join(toolsDir, "tool-0", "1.0.0", ToolManifestFileName))
+		})
+	}
+}
+
+func TestInstallFromRegistry_ChecksumMismatch(t *testing.T) {
+	setGitRunner(t, &concurrentCloneRunner{})
+
+	wrong := strings.Repeat("0", 64)
+	reg, _ := multiInstallTestRegistry(1)
+	reg.Tools[0].Checksums = map[string]string{"v1.0.0": wrong}
+	toolsDir := t.TempDir()
+
+	err := installFromRegistry(reg, exampleRegistryURL, "tool-0", "v1.0.0", toolsDir, nil, false, false)
+	require.Error(t, err)
+
+	var mismatchErr *ChecksumMismatchError
+	require.ErrorAs(t, err, &mismatchErr)
+	assert.Equal(t, "tool-0", mismatchErr.Tool)
+	assert.Equal(t, "v1.0.0", mismatchErr.Tag)
+	assert.Equal(t, wrong, mismatchErr.Expected)
+	assert.Equal(t, checksumTestToolChecksum(t, "tool-0"), mismatchErr.Actual)
+	assert.Contains(t, err.Error(), "checksum mismatch for tool 'tool-0' at v1.0.0")
+
+	assert.NoDirExists(t, filepath.Join(toolsDir, "tool-0"), "a tool failing verification should not be installed")
+}
+
+func TestInstallFromRegistry_NoChecksumWarns(t *testing.T) {
+	coreLogger, logs := observer.New(zap.WarnLevel)
+	previous := zap.L()
+	zap.ReplaceGlobals(zap.New(coreLogger))
+	t.Cleanup(func() { zap.ReplaceGlobals(previous) })
+
+	setGitRunner(t, &concurrentCloneRunner{})
+
+	reg, _ := multiInstallTestRegistry(1)
+	// A checksum for another version does not cover the one installed
+	reg.Tools[0].Checksums = map[string]string{"v0.9.0": strings.Repeat("0", 64)}
+	toolsDir := t.TempDir()
+
+	require.NoError(t, installFromRegistry(reg, exampleRegistryURL, "tool-0", "v1.0.0", toolsDir, nil, false, false))
+	assert.DirExists(t, filepath.Join(toolsDir, "tool-0", "1.0.0"))
+
+	warnings := logs.FilterMessage("Registry declares no checksum for tool version, installing it unverified").All()
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "tool-0", warnings[0].ContextMap()["tool"])
+	assert.Equal(t, "v1.0.0", warnings[0].ContextMap()["tag"])
+}
@@ -0,0 +1,72 @@
+package installer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/dorcha-inc/orla/internal/registry"
+)
+
+// RefVersionPrefix marks the version directory of a tool installed from a git ref rather than a
+// release tag, e.g. ~/.orla/tools/fs/ref-main/. It is never a valid semantic version, so ref
+// installs are not mistaken for releases.
+const RefVersionPrefix = "ref-"
+
+// commitSHAPattern matches a full hex git commit SHA (SHA-1 or SHA-256)
+var commitSHAPattern = regexp.MustCompile(`^(?:[0-9a-fA-F]{40}|[0-9a-fA-F]{64})$`)
+
+// refVersionUnsafeChars matches characters of a git ref not kept in its version directory name
+var refVersionUnsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// RefVersion returns the version directory name a tool installed from the git ref is installed
+// under, e.g. "ref-feature-login" for the branch feature/login
+func RefVersion(ref string) string {
+	return RefVersionPrefix + refVersionUnsafeChars.ReplaceAllString(ref, "-")
+}
+
+// IsRefVersion reports whether version is the version directory name of a git ref install
+func IsRefVersion(version string) bool {
+	return strings.HasPrefix(version, RefVersionPrefix)
+}
+
+// isCommitSHA reports whether ref is a full commit SHA, which can be fetched when it is not a branch tip
+func isCommitSHA(ref string) bool {
+	return commitSHAPattern.MatchString(ref)
+}
+
+// InstallToolAtRef installs a tool from the registry at a git branch or commit instead of a
+// released version, for developing tools. The version constraint resolution and the registry
+// checksum are bypassed, and the tool is installed under RefVersion(ref). Commits other than
+// the tip of a branch must be given as a full SHA.
+// toolsDir must be a valid, non-empty directory path
+// If replace is true, the installed ref becomes the tool's active version once the install succeeded
+func InstallToolAtRef(registryURL, toolName, ref string, toolsDir string, progress ProgressReporter, useCache, replace bool) error {
+	if toolsDir == "" {
+		return fmt.Errorf("tools directory cannot be empty")
+	}
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("invalid git ref '%s'", ref)
+	}
+
+	reg, errFetchRegistry := registry.FetchRegistry(registryURL, useCache)
+	if errFetchRegistry != nil {
+		return fmt.Errorf("failed to fetch registry: %w", errFetchRegistry)
+	}
+
+	return installRefFromRegistry(reg, registryURL, toolName, ref, toolsDir, progress, replace)
+}
+
+// installRefFromRegistry installs a tool listed in an already fetched registry index at a git ref
+func installRefFromRegistry(reg *registry.RegistryIndex, registryURL, toolName, ref string, toolsDir string, progress ProgressReporter, replace bool) error {
+	tool, errFindTool := findRegistryTool(reg, toolName)
+	if errFindTool != nil {
+		return errFindTool
+	}
+
+	if tool.Package != "" {
+		return fmt.Errorf("tool '%s' is installed from package %s, it has no git repository to install a ref from", toolName, tool.Package)
+	}
+
+	return installGitTool(tool, registryURL, toolName, "", ref, toolsDir, progress, replace)
+}
@@ -0,0 +1,159 @@
+package installer
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/registry"
+)
+
+// runGit runs git in dir with a fixed identity and returns its trimmed output
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@test.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@test.com")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	return strings.TrimSpace(string(output))
+}
+
+// commitRefTestTool commits a tool named ref-tool whose entrypoint echoes message
+func commitRefTestTool(t *testing.T, repoDir, message string) string {
+	t.Helper()
+	manifestData, err := yaml.Marshal(&core.ToolManifest{
+		Name:        "ref-tool",
+		Version:     "1.0.0",
+		Description: "Tool installed from a git ref",
+		Entrypoint:  "tool.sh",
+	})
+	require.NoError(t, err)
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, ToolManifestFileName), manifestData, 0644))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "tool.sh"), []byte("#!/bin/sh\necho "+message+"\n"), 0755))
+	runGit(t, repoDir, "add", ".")
+	runGit(t, repoDir, "commit", "-m", message)
+	return runGit(t, repoDir, "rev-parse", "HEAD")
+}
+
+// refTestRepository creates a git repository with a tool on a main branch and a feature/login
+// branch. It returns the repository, the SHA of the first commit on main, which is not a branch
+// tip, and the registry listing the repository.
+func refTestRepository(t *testing.T) (string, string, *registry.RegistryIndex) {
+	t.Helper()
+	repoDir := t.TempDir()
+	runGit(t, repoDir, "init", "--initial-branch", "main")
+
+	firstSHA := commitRefTestTool(t, repoDir, "first")
+	runGit(t, repoDir, "checkout", "-b", "feature/login")
+	commitRefTestTool(t, repoDir, "feature")
+	runGit(t, repoDir, "checkout", "main")
+	commitRefTestTool(t, repoDir, "second")
+
+	reg := &registry.RegistryIndex{
+		Version: registry.SupportedRegistryVersion,
+		Tools: []registry.ToolEntry{
+			{Name: "ref-tool", Description: "Tool installed from a git ref", Repository: "file://" + repoDir},
+		},
+	}
+	return repoDir, firstSHA, reg
+}
+
+func TestRefVersion(t *testing.T) {
+	assert.Equal(t, "ref-main", RefVersion("main"))
+	assert.Equal(t, "ref-feature-login", RefVersion("feature/login"))
+	assert.Equal(t, "ref-0123abc", RefVersion("0123abc"))
+	assert.True(t, IsRefVersion(RefVersion("main")))
+	assert.False(t, IsRefVersion("1.0.0"))
+}
+
+func TestInstallRefFromRegistry_Branch(t *testing.T) {
+	_, _, reg := refTestRepository(t)
+	toolsDir := t.TempDir()
+
+	require.NoError(t, installRefFromRegistry(reg, exampleRegistryURL, "ref-tool", "feature/login", toolsDir, nil, true))
+
+	installDir := filepath.Join(toolsDir, "ref-tool", "ref-feature-login")
+	script, err := os.ReadFile(filepath.Join(installDir, "tool.sh")) // #nosec G304 -- test reads a file it installed
+	require.NoError(t, err)
+	assert.Contains(t, string(script), "echo feature")
+	assert.Equal(t, "ref-feature-login", ActiveVersion(filepath.Join(toolsDir, "ref-tool")))
+
+	receipt, err := LoadInstallReceipt(installDir)
+	require.NoError(t, err)
+	assert.Equal(t, "feature/login", receipt.Ref)
+	assert.Empty(t, receipt.Tag)
+}
+
+func TestInstallRefFromRegistry_CommitSHA(t *testing.T) {
+	_, firstSHA, reg := refTestRepository(t)
+	toolsDir := t.TempDir()
+
+	require.NoError(t, installRefFromRegistry(reg, exampleRegistryURL, "ref-tool", firstSHA, toolsDir, nil, false))
+
+	installDir := filepath.Join(toolsDir, "ref-tool", RefVersion(firstSHA))
+	script, err := os.ReadFile(filepath.Join(installDir, "tool.sh")) // #nosec G304 -- test reads a file it installed
+	require.NoError(t, err)
+	assert.Contains(t, string(script), "echo first")
+}
+
+func TestInstallRefFromRegistry_PackageTool(t *testing.T) {
+	err := installRefFromRegistry(packageTestRegistry(), exampleRegistryURL, "fs-server", "main", t.TempDir(), nil, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no git repository")
+}
+
+func TestInstallToolAtRef_InvalidRef(t *testing.T) {
+	err := InstallToolAtRef(exampleRegistryURL, "ref-tool", "--upload-pack=evil", t.TempDir(), nil, true, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid git ref")
+}
+
+func TestReinstallTool_Ref(t *testing.T) {
+	repoDir, _, reg := refTestRepository(t)
+	toolsDir := t.TempDir()
+	require.NoError(t, installRefFromRegistry(reg, exampleRegistryURL, "ref-tool", "main", toolsDir, nil, false))
+
+	// Reinstalling picks up the branch's new commits
+	runGit(t, repoDir, "checkout", "main")
+	commitRefTestTool(t, repoDir, "third")
+	require.NoError(t, ReinstallTool("ref-tool", "ref-main", toolsDir, exampleRegistryURL, nil))
+
+	script, err := os.ReadFile(filepath.Join(toolsDir, "ref-tool", "ref-main", "tool.sh")) // #nosec G304 -- test reads a file it installed
+	require.NoError(t, err)
+	assert.Contains(t, string(script), "echo third")
+}
+
+func TestCloneToolRepository_CommitSHA(t *testing.T) {
+	mockRunner := &registry.MockGitRunner{
+		RunFunc: func(_, dir string, args ...string) ([]byte, error) {
+			switch args[0] {
+			case "clone":
+				if args[3] == "--branch" {
+					return []byte("fatal: Remote branch not found in upstream origin"), assert.AnError
+				}
+				return nil, nil
+			case "checkout":
+				if args[1] != "FETCH_HEAD" {
+					return []byte("fatal: reference is not a tree"), assert.AnError
+				}
+			}
+			return nil, nil
+		},
+	}
+	setGitRunner(t, mockRunner)
+
+	sha := strings.Repeat("a", 40)
+	require.NoError(t, cloneToolRepository("https://example.com/tool.git", sha, filepath.Join(t.TempDir(), "tool")))
+	assert.Contains(t, mockRunner.RunArgs(), []string{"fetch", "--depth", "1", "origin", sha})
+	assert.Equal(t, []string{"checkout", "FETCH_HEAD"}, mockRunner.RunArgs()[len(mockRunner.RunArgs())-1])
+}
@@ -0,0 +1,59 @@
+package installer
+
+import (
+	"strings"
+	"time"
+)
+
+// gitOutputTailLines is the number of trailing git output lines surfaced in clone errors
+const gitOutputTailLines = 10
+
+// cloneRetryBackoff is how long cloneToolRepository waits before its first retry. The wait doubles
+// before each further retry. Mutable for testing.
+var cloneRetryBackoff = time.Second
+
+// permanentGitErrors are fragments of git output for failures that retrying cannot fix, such as a
+// missing tag or rejected credentials
+var permanentGitErrors = []string{
+	"Authentication failed",
+	"could not read Username",
+	"Permission denied",
+	"Repository not found",
+	"does not exist",
+	"does not appear to be a git repository",
+	"did not match any file(s) known to git",
+	"couldn't find remote ref",
+	"not our ref",
+}
+
+// verboseGit makes clone errors report the git output of every failed attempt instead of only the last
+var verboseGit bool
+
+// SetVerboseGit enables or disables verbose git errors, for troubleshooting failed installs
+func SetVerboseGit(verbose bool) {
+	verboseGit = verbose
+}
+
+// isPermanentGitError reports whether git output shows a failure that retrying cannot fix
+func isPermanentGitError(output []byte) bool {
+	for _, fragment := range permanentGitErrors {
+		if strings.Contains(string(output), fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// tailLines returns the last n non-empty lines of output, joined by newlines
+func tailLines(output []byte, n int) string {
+	var lines []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -3,11 +3,10 @@ package installer
 
 import (
 	"fmt"
-	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -17,31 +16,63 @@ import (
 
 // InstallTool installs a tool from the registry
 // toolsDir must be a valid, non-empty directory path
-func InstallTool(registryURL, toolName, versionConstraint string, toolsDir string, progressWriter io.Writer) error {
+// If useCache is false, the registry index and the tool's tags are fetched fresh instead of read from cache
+// If replace is true, the installed version becomes the tool's active version once the install succeeded
+func InstallTool(registryURL, toolName, versionConstraint string, toolsDir string, progress ProgressReporter, useCache, replace bool) error {
 	if toolsDir == "" {
 		return fmt.Errorf("tools directory cannot be empty")
 	}
 	// Fetch registry
-	reg, errFetchRegistry := registry.FetchRegistry(registryURL, true)
+	reg, errFetchRegistry := registry.FetchRegistry(registryURL, useCache)
 	if errFetchRegistry != nil {
 		return fmt.Errorf("failed to fetch registry: %w", errFetchRegistry)
 	}
 
-	// Find tool
+	return installFromRegistry(reg, registryURL, toolName, versionConstraint, toolsDir, progress, useCache, replace)
+}
+
+// installFromRegistry installs a tool listed in an already fetched registry index
+func installFromRegistry(reg *registry.RegistryIndex, registryURL, toolName, versionConstraint string, toolsDir string, progress ProgressReporter, useCache, replace bool) error {
+	tool, errFindTool := findRegistryTool(reg, toolName)
+	if errFindTool != nil {
+		return errFindTool
+	}
+
+	if tool.Package != "" {
+		return installPackageTool(tool, registryURL, versionConstraint, toolsDir, progress, replace)
+	}
+
+	// Resolve version constraint to a git tag
+	reportProgress(progress, toolName, ProgressStageResolving, "resolving version %s", displayConstraint(versionConstraint))
+	tag, errResolveVersion := registry.ResolveVersion(tool, versionConstraint, useCache)
+	if errResolveVersion != nil {
+		return fmt.Errorf("failed to resolve version: %w", errResolveVersion)
+	}
+
+	return installGitTool(tool, registryURL, toolName, tag, "", toolsDir, progress, replace)
+}
+
+// findRegistryTool finds a tool in a registry index, suggesting a similarly named tool if it is not listed
+func findRegistryTool(reg *registry.RegistryIndex, toolName string) (*registry.ToolEntry, error) {
 	tool, errFindTool := registry.FindTool(reg, toolName)
 	if errFindTool != nil {
 		// Try to suggest similar tool
 		suggestion := registry.SuggestSimilarToolName(reg, toolName)
 		if suggestion != "" {
-			return fmt.Errorf("tool '%s' not found in registry. Did you mean: %s?: %w", toolName, suggestion, errFindTool)
+			return nil, fmt.Errorf("tool '%s' not found in registry. Did you mean: %s?: %w", toolName, suggestion, errFindTool)
 		}
-		return fmt.Errorf("tool '%s' not found in registry: %w", toolName, errFindTool)
+		return nil, fmt.Errorf("tool '%s' not found in registry: %w", toolName, errFindTool)
 	}
+	return tool, nil
+}
 
-	// Resolve version constraint to a git tag
-	tag, errResolveVersion := registry.ResolveVersion(tool, versionConstraint)
-	if errResolveVersion != nil {
-		return fmt.Errorf("failed to resolve version: %w", errResolveVersion)
+// installGitTool clones a registry tool's repository and installs it. Exactly one of tag and ref
+// is set: a tag is a resolved release, installed under the tool.yaml version after checking the
+// tag matches it, while a ref is a branch or commit installed under RefVersion(ref) unverified.
+func installGitTool(tool *registry.ToolEntry, registryURL, toolName, tag, ref string, toolsDir string, progress ProgressReporter, replace bool) error {
+	gitRef := tag
+	if ref != "" {
+		gitRef = ref
 	}
 
 	// Clone tool repository
@@ -52,11 +83,24 @@ func InstallTool(registryURL, toolName, versionConstraint string, toolsDir strin
 	defer core.LogDeferredError(func() error { return os.RemoveAll(tempDir) })
 
 	cloneDir := filepath.Join(tempDir, "tool")
-	if errClone := cloneToolRepository(tool.Repository, tag, cloneDir); errClone != nil {
+	reportProgress(progress, toolName, ProgressStageCloning, "cloning %s at %s", tool.Repository, gitRef)
+	if errClone := cloneToolRepository(tool.Repository, gitRef, cloneDir); errClone != nil {
 		return fmt.Errorf("failed to clone tool repository: %w", errClone)
 	}
 
-	// Load and validate manifest
+	// Verify checksum, then load and validate manifest. Registry checksums are per release tag,
+	// so a ref has none to verify against.
+	if ref != "" {
+		reportProgress(progress, toolName, ProgressStageVerifying, "verifying manifest")
+		zap.L().Warn("Installing tool from a git ref, its checksum is not verified",
+			zap.String("tool", toolName), zap.String("ref", ref))
+	} else {
+		reportProgress(progress, toolName, ProgressStageVerifying, "verifying checksum and manifest")
+		if errChecksum := verifyToolChecksum(tool, tag, cloneDir); errChecksum != nil {
+			return errChecksum
+		}
+	}
+
 	manifest, errLoadManifest := LoadManifest(cloneDir)
 	if errLoadManifest != nil {
 		return fmt.Errorf("failed to load manifest: %w", errLoadManifest)
@@ -66,10 +110,16 @@ func InstallTool(registryURL, toolName, versionConstraint string, toolsDir strin
 		return fmt.Errorf("failed to validate manifest: %w", errValidateManifest)
 	}
 
+	if errVersion := core.CheckMinOrlaVersion(manifest.Name, manifest.MinOrlaVersion); errVersion != nil {
+		return errVersion
+	}
+
 	// Validate that git tag matches tool.yaml version
-	// Tags must start with 'v' and match the version exactly
-	expectedTag := "v" + manifest.Version
-	if tag != expectedTag {
+	// Tags must start with 'v' and match the version exactly, refs are installed under a ref version
+	version := manifest.Version
+	if ref != "" {
+		version = RefVersion(ref)
+	} else if expectedTag := "v" + manifest.Version; tag != expectedTag {
 		return fmt.Errorf("git tag '%s' does not match tool.yaml version '%s'. Tag must be 'v%s'", tag, manifest.Version, manifest.Version)
 	}
 
@@ -80,53 +130,181 @@ func InstallTool(registryURL, toolName, versionConstraint string, toolsDir strin
 		return fmt.Errorf("failed to resolve tools directory path: %w", err)
 	}
 
-	installDir := filepath.Join(absToolsDir, toolName, manifest.Version)
+	installDir := filepath.Join(absToolsDir, toolName, version)
 
 	// Install to target directory
-	if errInstallToDirectory := InstallToDirectory(cloneDir, installDir, progressWriter); errInstallToDirectory != nil {
+	reportProgress(progress, toolName, ProgressStageCopying, "copying to %s", installDir)
+	if errInstallToDirectory := InstallToDirectory(cloneDir, installDir); errInstallToDirectory != nil {
 		return fmt.Errorf("failed to install tool to directory: %w", errInstallToDirectory)
 	}
 
+	receipt := &InstallReceipt{
+		Source:      InstallSourceRegistry,
+		RegistryURL: registryURL,
+		Repository:  tool.Repository,
+		Tag:         tag,
+		Ref:         ref,
+		InstalledAt: time.Now().UTC(),
+	}
+	if errReceipt := writeInstallReceipt(installDir, receipt); errReceipt != nil {
+		zap.L().Warn("Failed to record install source, reinstall will fall back to the default registry",
+			zap.String("tool", toolName), zap.Error(errReceipt))
+	}
+
 	zap.L().Info("Tool installed successfully",
 		zap.String("tool", toolName),
-		zap.String("version", manifest.Version),
-		zap.String("tag", tag),
+		zap.String("version", version),
+		zap.String("tag", gitRef),
 		zap.String("path", installDir))
+	reportProgress(progress, toolName, ProgressStageInstalled, "installed version %s", version)
 
+	if replace {
+		return activateInstalledVersion(progress, toolName, installDir)
+	}
 	return nil
 }
 
-// cloneToolRepository clones a tool repository at a specific tag
+// maxCloneAttempts is the number of times cloneToolRepository tries to fetch a tool repository
+const maxCloneAttempts = 3
+
+// cloneToolRepository clones a tool repository at a specific tag, retrying failed attempts after a
+// backoff that doubles each time. Failures that a retry cannot fix, such as a missing tag or
+// rejected credentials, are returned at once. An interrupted clone can leave a partial checkout
+// behind, so before each retry we first try to resume it with a shallow fetch of the tag, and if
+// that is not possible we clean the target directory and clone again from scratch. With verbose git
+// errors, the returned error includes the git output of every failed attempt, since retries can
+// fail differently than the first try. In offline mode it fails without running git.
 func cloneToolRepository(repoURL, tag, targetDir string) error {
+	if registry.Offline() {
+		return fmt.Errorf("%w: cannot clone %s at %s", registry.ErrOffline, repoURL, tag)
+	}
+
 	zap.L().Debug("Cloning tool repository", zap.String("url", repoURL), zap.String("tag", tag), zap.String("path", targetDir))
 
-	// Clone repository
-	cmd := exec.Command("git", "clone", "--depth", "1", "--branch", tag, repoURL, targetDir)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		// If branch doesn't exist, try cloning without branch and checking out tag
-		if strings.Contains(string(output), "not found") {
-			cmd = exec.Command("git", "clone", "--depth", "1", repoURL, targetDir)
-			if err := cmd.Run(); err != nil {
-				return fmt.Errorf("failed to clone repository: %w", err)
+	var lastErr error
+	var lastOutput []byte
+	var attemptOutputs []string
+
+	for attempt := 1; attempt <= maxCloneAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(cloneRetryBackoff << (attempt - 2))
+
+			if isGitCheckout(targetDir) {
+				output, errResume := resumeToolClone(repoURL, tag, targetDir)
+				if errResume == nil {
+					zap.L().Debug("Resumed partial tool repository clone", zap.String("url", repoURL), zap.String("tag", tag))
+					return nil
+				}
+				zap.L().Debug("Failed to resume partial clone, cloning fresh", zap.Error(errResume), zap.String("output", tailLines(output, gitOutputTailLines)))
+				if verboseGit {
+					attemptOutputs = append(attemptOutputs, fmt.Sprintf("attempt %d resume: %v\n%s", attempt, errResume, tailLines(output, gitOutputTailLines)))
+				}
 			}
-			// Checkout the tag
-			cmd = exec.Command("git", "checkout", tag)
-			cmd.Dir = targetDir
-			if err := cmd.Run(); err != nil {
-				return fmt.Errorf("failed to checkout tag %s: %w", tag, err)
+
+			if errRemove := os.RemoveAll(targetDir); errRemove != nil {
+				return fmt.Errorf("failed to clean target directory before retrying clone: %w", errRemove)
 			}
-		} else {
-			return fmt.Errorf("failed to clone repository: %w, output: %s", err, string(output))
+		}
+
+		output, err := cloneToolRepositoryOnce(repoURL, tag, targetDir)
+		if err == nil {
+			return nil
+		}
+		if isPermanentGitError(output) {
+			return fmt.Errorf("%w, git output (last %d lines): %s", err, gitOutputTailLines, tailLines(output, gitOutputTailLines))
+		}
+
+		lastErr = err
+		lastOutput = output
+		zap.L().Warn("Tool repository clone attempt failed",
+			zap.String("url", repoURL),
+			zap.String("tag", tag),
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", maxCloneAttempts),
+			zap.Error(err),
+			zap.String("output", tailLines(output, gitOutputTailLines)))
+		if verboseGit {
+			attemptOutputs = append(attemptOutputs, fmt.Sprintf("attempt %d: %v\n%s", attempt, err, tailLines(output, gitOutputTailLines)))
 		}
 	}
 
-	return nil
+	if verboseGit {
+		return fmt.Errorf("giving up after %d attempts: %w, git output of each attempt (last %d lines):\n%s",
+			maxCloneAttempts, lastErr, gitOutputTailLines, strings.Join(attemptOutputs, "\n"))
+	}
+	return fmt.Errorf("giving up after %d attempts: %w, git output (last %d lines): %s",
+		maxCloneAttempts, lastErr, gitOutputTailLines, tailLines(lastOutput, gitOutputTailLines))
+}
+
+// cloneToolRepositoryOnce makes a single attempt at cloning a tool repository at a specific tag.
+// It returns the git output of the failing command along with any error.
+func cloneToolRepositoryOnce(repoURL, tag, targetDir string) ([]byte, error) {
+	output, err := registry.GetDefaultGitRunner().Run(repoURL, "", "clone", "--depth", "1", "--branch", tag, repoURL, targetDir)
+	if err == nil {
+		return nil, nil
+	}
+
+	// If branch doesn't exist, try cloning without branch and checking out tag
+	if !strings.Contains(string(output), "not found") {
+		return output, fmt.Errorf("failed to clone repository: %w", err)
+	}
+
+	if errClean := os.RemoveAll(targetDir); errClean != nil {
+		return nil, fmt.Errorf("failed to clean target directory: %w", errClean)
+	}
+
+	output, err = registry.GetDefaultGitRunner().Run(repoURL, "", "clone", "--depth", "1", repoURL, targetDir)
+	if err != nil {
+		return output, fmt.Errorf("failed to clone repository: %w", err)
+	}
+
+	// Checkout the tag
+	output, err = registry.GetDefaultGitRunner().Run(repoURL, targetDir, "checkout", tag)
+	if err == nil {
+		return nil, nil
+	}
+
+	// A commit other than the tip of the default branch is not in the shallow clone, fetch it by SHA
+	if !isCommitSHA(tag) {
+		return output, fmt.Errorf("failed to checkout tag %s: %w", tag, err)
+	}
+	output, err = registry.GetDefaultGitRunner().Run(repoURL, targetDir, "fetch", "--depth", "1", "origin", tag)
+	if err != nil {
+		return output, fmt.Errorf("failed to fetch commit %s: %w", tag, err)
+	}
+	output, err = registry.GetDefaultGitRunner().Run(repoURL, targetDir, "checkout", "FETCH_HEAD")
+	if err != nil {
+		return output, fmt.Errorf("failed to checkout commit %s: %w", tag, err)
+	}
+
+	return nil, nil
+}
+
+// resumeToolClone completes a partial checkout in targetDir by shallow-fetching tag from origin,
+// which is repoURL
+func resumeToolClone(repoURL, tag, targetDir string) ([]byte, error) {
+	output, err := registry.GetDefaultGitRunner().Run(repoURL, targetDir, "fetch", "--depth", "1", "origin", "tag", tag)
+	if err != nil {
+		return output, fmt.Errorf("failed to fetch tag %s: %w", tag, err)
+	}
+
+	output, err = registry.GetDefaultGitRunner().Run(repoURL, targetDir, "checkout", "--force", tag)
+	if err != nil {
+		return output, fmt.Errorf("failed to checkout tag %s: %w", tag, err)
+	}
+
+	return nil, nil
+}
+
+// isGitCheckout reports whether dir contains a git checkout (possibly a partial one)
+func isGitCheckout(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
 }
 
 // InstallLocalTool installs a tool from a local directory or archive (archive support not yet implemented)
 // toolsDir must be a valid, non-empty directory path
-func InstallLocalTool(localPath string, toolsDir string, progressWriter io.Writer) error {
+func InstallLocalTool(localPath string, toolsDir string, progress ProgressReporter) error {
 	if toolsDir == "" {
 		return fmt.Errorf("tools directory cannot be empty")
 	}
@@ -153,11 +331,16 @@ func InstallLocalTool(localPath string, toolsDir string, progressWriter io.Write
 		return fmt.Errorf("failed to load manifest: %w", errLoadManifest)
 	}
 
+	reportProgress(progress, manifest.Name, ProgressStageVerifying, "verifying manifest")
 	errValidateManifest := ValidateManifest(manifest, absLocalPath)
 	if errValidateManifest != nil {
 		return fmt.Errorf("failed to validate manifest: %w", errValidateManifest)
 	}
 
+	if errVersion := core.CheckMinOrlaVersion(manifest.Name, manifest.MinOrlaVersion); errVersion != nil {
+		return errVersion
+	}
+
 	// Get install directory using version from tool.yaml (source of truth)
 	// Resolve to absolute path
 	absToolsDir, err := filepath.Abs(toolsDir)
@@ -168,22 +351,34 @@ func InstallLocalTool(localPath string, toolsDir string, progressWriter io.Write
 	installDir := filepath.Join(absToolsDir, manifest.Name, manifest.Version)
 
 	// Install to target directory
-	errInstallToDirectory := InstallToDirectory(absLocalPath, installDir, progressWriter)
+	reportProgress(progress, manifest.Name, ProgressStageCopying, "copying to %s", installDir)
+	errInstallToDirectory := InstallToDirectory(absLocalPath, installDir)
 	if errInstallToDirectory != nil {
 		return fmt.Errorf("failed to install tool to directory: %w", errInstallToDirectory)
 	}
 
+	receipt := &InstallReceipt{
+		Source:      InstallSourceLocal,
+		LocalPath:   absLocalPath,
+		InstalledAt: time.Now().UTC(),
+	}
+	if errReceipt := writeInstallReceipt(installDir, receipt); errReceipt != nil {
+		zap.L().Warn("Failed to record install source, reinstall will fall back to the default registry",
+			zap.String("tool", manifest.Name), zap.Error(errReceipt))
+	}
+
 	zap.L().Info("Local tool installed successfully",
 		zap.String("tool", manifest.Name),
 		zap.String("version", manifest.Version),
 		zap.String("source", absLocalPath),
 		zap.String("path", installDir))
+	reportProgress(progress, manifest.Name, ProgressStageInstalled, "installed version %s", manifest.Version)
 
 	return nil
 }
 
 // InstallToDirectory copies tool files from source to target directory
-func InstallToDirectory(sourceDir, targetDir string, progressWriter io.Writer) error {
+func InstallToDirectory(sourceDir, targetDir string) error {
 	// Create target directory
 	err := os.MkdirAll(targetDir, 0750)
 	if err != nil {
@@ -196,10 +391,12 @@ func InstallToDirectory(sourceDir, targetDir string, progressWriter io.Writer) e
 
 // InstalledToolInfo represents information about an installed tool
 type InstalledToolInfo struct {
-	Name        string
-	Version     string
-	Path        string
-	Description string
+	Name        string             `json:"name"`
+	Version     string             `json:"version"`
+	Path        string             `json:"install_path"`
+	Description string             `json:"description"`
+	RuntimeMode core.RuntimeMode   `json:"runtime_mode"`
+	Stability   core.ToolStability `json:"stability"`
 }
 
 // ListInstalledTools returns a list of all installed tools with their versions
@@ -248,11 +445,18 @@ func ListInstalledTools(toolsDir string) ([]InstalledToolInfo, error) {
 				return nil
 			}
 
+			runtimeMode := core.RuntimeModeSimple
+			if manifest.Runtime != nil && manifest.Runtime.Mode != "" {
+				runtimeMode = manifest.Runtime.Mode
+			}
+
 			newTool := InstalledToolInfo{
 				Name:        manifest.Name,
 				Version:     version,
 				Path:        toolDir,
 				Description: manifest.Description,
+				RuntimeMode: runtimeMode,
+				Stability:   core.StabilityOf(manifest),
 			}
 
 			tools = append(tools, newTool)
@@ -299,7 +503,9 @@ func UninstallTool(toolName string, toolsDir string) error {
 
 // UpdateTool updates a tool to the latest version
 // toolsDir must be a valid, non-empty directory path
-func UpdateTool(registryURL, toolName string, toolsDir string, progressWriter io.Writer) error {
+// If useCache is false, the registry index and the tool's tags are fetched fresh instead of read from cache
+// If replace is true, the latest version becomes the tool's active version once it is installed
+func UpdateTool(registryURL, toolName string, toolsDir string, progress ProgressReporter, useCache, replace bool) error {
 	if toolsDir == "" {
 		return fmt.Errorf("tools directory cannot be empty")
 	}
@@ -318,5 +524,5 @@ func UpdateTool(registryURL, toolName string, toolsDir string, progressWriter io
 	}
 
 	// Install latest version (InstallTool handles this)
-	return InstallTool(registryURL, toolName, registry.VersionConstraintLatest, toolsDir, progressWriter)
+	return InstallTool(registryURL, toolName, registry.VersionConstraintLatest, toolsDir, progress, useCache, replace)
 }
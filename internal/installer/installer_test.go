@@ -2,9 +2,11 @@ package installer
 
 import (
 	"bytes"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -19,6 +21,28 @@ import (
 
 const exampleRegistryURL = "https://example.com/registry"
 
+// testToolManifest returns a valid tool.yaml for a tool with the given name and version and a
+// tool.sh entrypoint, as written by writeTestTool
+func testToolManifest(name, version string) string {
+	return fmt.Sprintf("name: %s\nversion: %s\ndescription: A test tool\nentrypoint: tool.sh\n", name, version)
+}
+
+// writeTestTool writes a tool directory at dir with the given tool.yaml and an executable
+// tool.sh. It returns an error rather than failing the test, so that fake git runners can call
+// it from the goroutines that clone.
+func writeTestTool(dir, manifest string) error {
+	// #nosec G301 -- test directory permissions are acceptable for temporary test files
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	if err := os.WriteFile(filepath.Join(dir, ToolManifestFileName), []byte(manifest), 0644); err != nil {
+		return err
+	}
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	return os.WriteFile(filepath.Join(dir, "tool.sh"), []byte("#!/bin/sh\necho ok\n"), 0755)
+}
+
 func TestInstallToDirectory(t *testing.T) {
 	srcDir := t.TempDir()
 	dstDir := t.TempDir()
@@ -32,8 +56,7 @@ func TestInstallToDirectory(t *testing.T) {
 	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "subdir", "file2.txt"), []byte("content2"), 0644))
 
 	// Install to directory
-	var buf bytes.Buffer
-	err := InstallToDirectory(srcDir, dstDir, &buf)
+	err := InstallToDirectory(srcDir, dstDir)
 	require.NoError(t, err)
 
 	// Verify files were copied
@@ -50,12 +73,12 @@ func TestInstallToDirectory(t *testing.T) {
 
 func TestInstallToDirectory_ErrorCases(t *testing.T) {
 	// Test: source directory doesn't exist
-	err := InstallToDirectory("/nonexistent/dir", t.TempDir(), &bytes.Buffer{})
+	err := InstallToDirectory("/nonexistent/dir", t.TempDir())
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to")
 
 	// Test: invalid destination path (on Unix, /root might not be writable, but the error will be about creating directory)
-	err = InstallToDirectory(t.TempDir(), "/root/invalid/path", &bytes.Buffer{})
+	err = InstallToDirectory(t.TempDir(), "/root/invalid/path")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to")
 }
@@ -178,7 +201,7 @@ func TestInstallTool_InvalidRegistry(t *testing.T) {
 	// Test with invalid registry URL
 	tmpDir := t.TempDir()
 	toolsDir := filepath.Join(tmpDir, "tools")
-	err := InstallTool("not-a-valid-url", "test-tool", "v1.0.0", toolsDir, &bytes.Buffer{})
+	err := InstallTool("not-a-valid-url", "test-tool", "v1.0.0", toolsDir, NewTextProgress(&bytes.Buffer{}), true, false)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to fetch registry")
 }
@@ -269,6 +292,8 @@ func TestInstallTool_Success(t *testing.T) {
 
 	// Mock GitRunner for registry cloning
 	mockRunner := &registry.MockGitRunner{
+		// Tool repositories are cloned with git itself
+		RunFunc: registry.GetDefaultGitRunner().Run,
 		CloneFunc: func(url, targetPath string) error {
 			// Copy registry.yaml to target
 			// #nosec G301 -- test directory permissions are acceptable for temporary test files
@@ -297,7 +322,7 @@ func TestInstallTool_Success(t *testing.T) {
 
 	// Test InstallTool - should log success
 	installDir := filepath.Join(tmpDir, "tools")
-	errInstallTool := InstallTool(exampleRegistryURL, "test-tool", "v1.0.0", installDir, &bytes.Buffer{})
+	errInstallTool := InstallTool(exampleRegistryURL, "test-tool", "v1.0.0", installDir, NewTextProgress(&bytes.Buffer{}), true, false)
 	require.NoError(t, errInstallTool)
 
 	// Verify logging
@@ -350,6 +375,8 @@ func TestInstallTool_ToolNotFound(t *testing.T) {
 
 	// Mock GitRunner to return our test registry
 	mockRunner := &registry.MockGitRunner{
+		// Tool repositories are cloned with git itself
+		RunFunc: registry.GetDefaultGitRunner().Run,
 		CloneFunc: func(url, targetPath string) error {
 			// Copy registry.yaml to target
 			// #nosec G301 -- test directory permissions are acceptable for temporary test files
@@ -379,7 +406,7 @@ func TestInstallTool_ToolNotFound(t *testing.T) {
 	require.NoError(t, os.MkdirAll(toolsDir, 0755))
 
 	// Test InstallTool with non-existent tool (no suggestion since distance > 2)
-	err = InstallTool(exampleRegistryURL, "xyz-tool", "v1.0.0", toolsDir, &bytes.Buffer{})
+	err = InstallTool(exampleRegistryURL, "xyz-tool", "v1.0.0", toolsDir, NewTextProgress(&bytes.Buffer{}), true, false)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not found")
 	assert.NotContains(t, err.Error(), "Did you mean")
@@ -421,6 +448,8 @@ func TestInstallTool_ToolNotFoundWithSuggestion(t *testing.T) {
 
 	// Mock GitRunner
 	mockRunner := &registry.MockGitRunner{
+		// Tool repositories are cloned with git itself
+		RunFunc: registry.GetDefaultGitRunner().Run,
 		CloneFunc: func(url, targetPath string) error {
 			// #nosec G301 -- test directory permissions are acceptable for temporary test files
 			if mkdirErr := os.MkdirAll(targetPath, 0755); mkdirErr != nil {
@@ -449,13 +478,14 @@ func TestInstallTool_ToolNotFoundWithSuggestion(t *testing.T) {
 	require.NoError(t, os.MkdirAll(toolsDir, 0755))
 
 	// Test InstallTool with typo - should suggest similar tool
-	err = InstallTool(exampleRegistryURL, "fs-tol", "v1.0.0", toolsDir, &bytes.Buffer{})
+	err = InstallTool(exampleRegistryURL, "fs-tol", "v1.0.0", toolsDir, NewTextProgress(&bytes.Buffer{}), true, false)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "Did you mean")
 	assert.Contains(t, err.Error(), "fs-tool")
 }
 
 func TestInstallTool_VersionNotFound(t *testing.T) {
+	noCloneRetryBackoff(t)
 	tmpDir := t.TempDir()
 	cacheDir := filepath.Join(tmpDir, "cache")
 	// #nosec G301 -- test directory permissions are acceptable for temporary test files
@@ -495,6 +525,8 @@ func TestInstallTool_VersionNotFound(t *testing.T) {
 
 	// Mock GitRunner
 	mockRunner := &registry.MockGitRunner{
+		// Tool repositories are cloned with git itself
+		RunFunc: registry.GetDefaultGitRunner().Run,
 		CloneFunc: func(url, targetPath string) error {
 			// #nosec G301 -- test directory permissions are acceptable for temporary test files
 			if err := os.MkdirAll(targetPath, 0755); err != nil {
@@ -523,7 +555,7 @@ func TestInstallTool_VersionNotFound(t *testing.T) {
 	require.NoError(t, os.MkdirAll(toolsDir, 0755))
 
 	// Test InstallTool with non-existent tag
-	err = InstallTool(exampleRegistryURL, "test-tool", "v99.0.0", toolsDir, &bytes.Buffer{})
+	err = InstallTool(exampleRegistryURL, "test-tool", "v99.0.0", toolsDir, NewTextProgress(&bytes.Buffer{}), true, false)
 	assert.Error(t, err)
 	// Tag validation passes, but clone will fail since tag doesn't exist
 	assert.True(t, strings.Contains(err.Error(), "failed to clone") || strings.Contains(err.Error(), "not found"))
@@ -591,6 +623,8 @@ func TestInstallTool_LoadManifestError(t *testing.T) {
 
 	// Mock GitRunner - clone will succeed but tool.yaml won't exist
 	mockRunner := &registry.MockGitRunner{
+		// Tool repositories are cloned with git itself
+		RunFunc: registry.GetDefaultGitRunner().Run,
 		CloneFunc: func(url, targetPath string) error {
 			// Simulate successful clone by copying repo files
 			// #nosec G301 -- test directory permissions are acceptable for temporary test files
@@ -626,7 +660,7 @@ func TestInstallTool_LoadManifestError(t *testing.T) {
 	require.NoError(t, os.MkdirAll(toolsDir, 0755))
 
 	// Test InstallTool - should fail when loading manifest (tool.yaml doesn't exist)
-	err = InstallTool(exampleRegistryURL, "test-tool", "v1.0.0", toolsDir, &bytes.Buffer{})
+	err = InstallTool(exampleRegistryURL, "test-tool", "v1.0.0", toolsDir, NewTextProgress(&bytes.Buffer{}), true, false)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to load manifest")
 }
@@ -707,6 +741,8 @@ entrypoint: bin/tool
 
 	// Mock GitRunner - clone will copy the repo with invalid manifest
 	mockRunner := &registry.MockGitRunner{
+		// Tool repositories are cloned with git itself
+		RunFunc: registry.GetDefaultGitRunner().Run,
 		CloneFunc: func(url, targetPath string) error {
 			// Copy repo files including tool.yaml
 			// #nosec G301 -- test directory permissions are acceptable for temporary test files
@@ -738,8 +774,9 @@ entrypoint: bin/tool
 			return os.WriteFile(filepath.Join(binTargetDir, "tool"), binData, 0755)
 		},
 	}
+	originalRunner := registry.GetDefaultGitRunner()
 	registry.SetGitRunner(mockRunner)
-	defer registry.SetGitRunner(registry.GetDefaultGitRunner())
+	defer registry.SetGitRunner(originalRunner)
 
 	// Mock GetRegistryCacheDir
 	originalGetCacheDir := *registry.GetRegistryCacheDirFunc
@@ -756,12 +793,13 @@ entrypoint: bin/tool
 	require.NoError(t, os.MkdirAll(toolsDir, 0755))
 
 	// Test InstallTool - should fail when validating manifest (missing description)
-	err = InstallTool(registryURL, "test-tool", "v1.0.0", toolsDir, &bytes.Buffer{})
+	err = InstallTool(registryURL, "test-tool", "v1.0.0", toolsDir, NewTextProgress(&bytes.Buffer{}), true, false)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "manifest validation failed")
 }
 
 func TestCloneToolRepository_FallbackCloneError(t *testing.T) {
+	noCloneRetryBackoff(t)
 	// Create a mock git repository
 	repoDir := t.TempDir()
 	targetDir := t.TempDir()
@@ -870,7 +908,7 @@ tools:
 	// Note: This test requires the registry to be accessible via file:// URL
 	// On some systems, file:// URLs might not work with git clone, so we'll skip if it fails
 	var buf bytes.Buffer
-	err := InstallTool(registryDir, "test-tool", "1.0.0", toolsDir, &buf)
+	err := InstallTool(registryDir, "test-tool", "1.0.0", toolsDir, NewTextProgress(&buf), true, false)
 	if err != nil {
 		// If it fails due to git clone issues with file:// URLs, that's okay for unit tests
 		// This would be better as an integration test
@@ -1084,6 +1122,8 @@ func TestUpdateTool(t *testing.T) {
 
 	// Mock GitRunner for registry cloning and tool tag listing
 	mockRunner := &registry.MockGitRunner{
+		// Tool repositories are cloned with git itself
+		RunFunc: registry.GetDefaultGitRunner().Run,
 		CloneFunc: func(url, targetPath string) error {
 			// Copy registry.yaml to target
 			// #nosec G301 -- test directory permissions are acceptable for temporary test files
@@ -1141,7 +1181,7 @@ func TestUpdateTool(t *testing.T) {
 
 	// Update tool to latest version
 	var buf bytes.Buffer
-	err = UpdateTool(exampleRegistryURL, "test-tool", installDir, &buf)
+	err = UpdateTool(exampleRegistryURL, "test-tool", installDir, NewTextProgress(&buf), true, false)
 	require.NoError(t, err)
 
 	// Verify new version is installed
@@ -1161,7 +1201,7 @@ func TestUpdateTool_NotInstalled(t *testing.T) {
 	require.NoError(t, os.MkdirAll(installDir, 0755))
 
 	var buf bytes.Buffer
-	err := UpdateTool(exampleRegistryURL, "nonexistent-tool", installDir, &buf)
+	err := UpdateTool(exampleRegistryURL, "nonexistent-tool", installDir, NewTextProgress(&buf), true, false)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not installed")
 }
@@ -1193,7 +1233,7 @@ func TestInstallLocalTool(t *testing.T) {
 
 	// Install local tool
 	var buf bytes.Buffer
-	err = InstallLocalTool(localToolDir, installDir, &buf)
+	err = InstallLocalTool(localToolDir, installDir, NewTextProgress(&buf))
 	require.NoError(t, err)
 
 	// Verify tool was installed
@@ -1218,7 +1258,7 @@ func TestInstallLocalTool_ErrorCases(t *testing.T) {
 
 	// Test: local path doesn't exist
 	var buf bytes.Buffer
-	err := InstallLocalTool("/nonexistent/path", installDir, &buf)
+	err := InstallLocalTool("/nonexistent/path", installDir, NewTextProgress(&buf))
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "does not exist")
 
@@ -1226,13 +1266,259 @@ func TestInstallLocalTool_ErrorCases(t *testing.T) {
 	filePath := filepath.Join(t.TempDir(), "not-a-dir")
 	// #nosec G306 -- test file permissions are acceptable for temporary test files
 	require.NoError(t, os.WriteFile(filePath, []byte("not a directory"), 0644))
-	err = InstallLocalTool(filePath, installDir, &buf)
+	err = InstallLocalTool(filePath, installDir, NewTextProgress(&buf))
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "must be a directory")
 
 	// Test: missing tool.yaml
 	toolDir := t.TempDir()
-	err = InstallLocalTool(toolDir, installDir, &buf)
+	err = InstallLocalTool(toolDir, installDir, NewTextProgress(&buf))
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to load manifest")
 }
+
+func TestInstallLocalTool_MinOrlaVersion(t *testing.T) {
+	original := core.OrlaVersion()
+	core.SetOrlaVersion("0.3.0")
+	t.Cleanup(func() { core.SetOrlaVersion(original) })
+
+	createTool := func(t *testing.T, minOrlaVersion string) string {
+		t.Helper()
+		localToolDir := t.TempDir()
+		manifestData, err := yaml.Marshal(&core.ToolManifest{
+			Name:           "versioned-tool",
+			Version:        "0.1.0",
+			Description:    "A tool requiring a minimum orla version",
+			Entrypoint:     "tool.sh",
+			MinOrlaVersion: minOrlaVersion,
+		})
+		require.NoError(t, err)
+		// #nosec G306 -- test file permissions are acceptable for temporary test files
+		require.NoError(t, os.WriteFile(filepath.Join(localToolDir, "tool.yaml"), manifestData, 0644))
+		// #nosec G306 -- test file permissions are acceptable for temporary test files
+		require.NoError(t, os.WriteFile(filepath.Join(localToolDir, "tool.sh"), []byte("#!/bin/sh\necho ok\n"), 0755))
+		return localToolDir
+	}
+
+	t.Run("within range is accepted", func(t *testing.T) {
+		installDir := t.TempDir()
+		var buf bytes.Buffer
+		require.NoError(t, InstallLocalTool(createTool(t, "0.2.0"), installDir, NewTextProgress(&buf)))
+		assert.DirExists(t, filepath.Join(installDir, "versioned-tool", "0.1.0"))
+	})
+
+	t.Run("newer orla required is rejected", func(t *testing.T) {
+		installDir := t.TempDir()
+		var buf bytes.Buffer
+		err := InstallLocalTool(createTool(t, "1.0.0"), installDir, NewTextProgress(&buf))
+		require.Error(t, err)
+		var tooOld *core.OrlaVersionTooOldError
+		require.ErrorAs(t, err, &tooOld)
+		assert.Contains(t, err.Error(), "requires orla 1.0.0 or newer")
+		assert.NoDirExists(t, filepath.Join(installDir, "versioned-tool"))
+	})
+
+	t.Run("invalid requirement is rejected", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := InstallLocalTool(createTool(t, "soon"), t.TempDir(), NewTextProgress(&buf))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid min_orla_version")
+	})
+}
+
+// setGitRunner swaps the registry git runner, which also clones tools, for the duration of a test
+func setGitRunner(t *testing.T, runner registry.GitRunner) {
+	original := registry.GetDefaultGitRunner()
+	registry.SetGitRunner(runner)
+	t.Cleanup(func() { registry.SetGitRunner(original) })
+}
+
+// noCloneRetryBackoff makes cloneToolRepository retry at once for the duration of a test
+func noCloneRetryBackoff(t *testing.T) {
+	original := cloneRetryBackoff
+	cloneRetryBackoff = 0
+	t.Cleanup(func() { cloneRetryBackoff = original })
+}
+
+func TestCloneToolRepository_RetryCleansTargetDir(t *testing.T) {
+	noCloneRetryBackoff(t)
+	targetDir := filepath.Join(t.TempDir(), "tool")
+	cloneAttempts := 0
+
+	mockRunner := &registry.MockGitRunner{
+		RunFunc: func(_, dir string, args ...string) ([]byte, error) {
+			if args[0] != "clone" {
+				return nil, nil
+			}
+			cloneAttempts++
+
+			if cloneAttempts == 1 {
+				// Simulate an interrupted clone that leaves partial files behind
+				// #nosec G301 -- test directory permissions are acceptable for temporary test files
+				require.NoError(t, os.MkdirAll(targetDir, 0755))
+				// #nosec G306 -- test file permissions are acceptable for temporary test files
+				require.NoError(t, os.WriteFile(filepath.Join(targetDir, "partial.txt"), []byte("partial"), 0644))
+				return []byte("fatal: early EOF"), assert.AnError
+			}
+
+			// The retry must start from a clean target directory
+			_, errStat := os.Stat(targetDir)
+			assert.True(t, os.IsNotExist(errStat), "target directory should be removed before retrying")
+
+			// #nosec G301 -- test directory permissions are acceptable for temporary test files
+			require.NoError(t, os.MkdirAll(targetDir, 0755))
+			// #nosec G306 -- test file permissions are acceptable for temporary test files
+			require.NoError(t, os.WriteFile(filepath.Join(targetDir, ToolManifestFileName), []byte("name: tool"), 0644))
+			return nil, nil
+		},
+	}
+	setGitRunner(t, mockRunner)
+
+	err := cloneToolRepository("https://example.com/tool.git", "v1.0.0", targetDir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, cloneAttempts)
+
+	_, err = os.Stat(filepath.Join(targetDir, "partial.txt"))
+	assert.True(t, os.IsNotExist(err), "partial files from the failed attempt should not survive")
+	_, err = os.Stat(filepath.Join(targetDir, ToolManifestFileName))
+	assert.NoError(t, err)
+}
+
+func TestCloneToolRepository_ResumesPartialCheckout(t *testing.T) {
+	noCloneRetryBackoff(t)
+	targetDir := filepath.Join(t.TempDir(), "tool")
+
+	mockRunner := &registry.MockGitRunner{
+		RunFunc: func(_, dir string, args ...string) ([]byte, error) {
+			switch args[0] {
+			case "clone":
+				// Interrupted after the repository was initialized
+				// #nosec G301 -- test directory permissions are acceptable for temporary test files
+				require.NoError(t, os.MkdirAll(filepath.Join(targetDir, ".git"), 0755))
+				return []byte("error: RPC failed"), assert.AnError
+			case "fetch", "checkout":
+				assert.Equal(t, targetDir, dir)
+				return nil, nil
+			}
+			return nil, nil
+		},
+	}
+	setGitRunner(t, mockRunner)
+
+	err := cloneToolRepository("https://example.com/tool.git", "v1.0.0", targetDir)
+	require.NoError(t, err)
+
+	require.Len(t, mockRunner.RunArgs(), 3)
+	assert.Equal(t, []string{"fetch", "--depth", "1", "origin", "tag", "v1.0.0"}, mockRunner.RunArgs()[1])
+	assert.Equal(t, []string{"checkout", "--force", "v1.0.0"}, mockRunner.RunArgs()[2])
+
+	// The partial checkout should have been resumed, not removed
+	_, err = os.Stat(filepath.Join(targetDir, ".git"))
+	assert.NoError(t, err)
+}
+
+func TestCloneToolRepository_RepeatedFailureIncludesOutputTail(t *testing.T) {
+	noCloneRetryBackoff(t)
+	targetDir := filepath.Join(t.TempDir(), "tool")
+
+	var output strings.Builder
+	for i := 0; i < 20; i++ {
+		output.WriteString("remote: line ")
+		output.WriteString(strings.Repeat("x", i))
+		output.WriteString("\n")
+	}
+	output.WriteString("fatal: unable to access repository")
+
+	mockRunner := &registry.MockGitRunner{
+		RunFunc: func(_, dir string, args ...string) ([]byte, error) {
+			return []byte(output.String()), assert.AnError
+		},
+	}
+	setGitRunner(t, mockRunner)
+
+	err := cloneToolRepository("https://example.com/tool.git", "v1.0.0", targetDir)
+	require.Error(t, err)
+	assert.Len(t, mockRunner.RunArgs(), maxCloneAttempts)
+	assert.Contains(t, err.Error(), "failed to clone repository")
+	assert.Contains(t, err.Error(), "fatal: unable to access repository")
+	// Only the tail of the output is surfaced
+	assert.NotContains(t, err.Error(), "remote: line \n")
+}
+
+func TestCloneToolRepository_VerboseIncludesEachAttemptOutput(t *testing.T) {
+	noCloneRetryBackoff(t)
+	targetDir := filepath.Join(t.TempDir(), "tool")
+
+	attempt := 0
+	mockRunner := &registry.MockGitRunner{
+		RunFunc: func(_, dir string, args ...string) ([]byte, error) {
+			attempt++
+			return []byte(fmt.Sprintf("Cloning into 'tool'...\nfatal: error on attempt %d", attempt)), assert.AnError
+		},
+	}
+	setGitRunner(t, mockRunner)
+
+	err := cloneToolRepository("https://example.com/tool.git", "v1.0.0", targetDir)
+	require.Error(t, err)
+	// Without verbose git errors only the output of the last attempt is surfaced
+	assert.NotContains(t, err.Error(), "fatal: error on attempt 1")
+	assert.Contains(t, err.Error(), "fatal: error on attempt 3")
+
+	SetVerboseGit(true)
+	t.Cleanup(func() { SetVerboseGit(false) })
+
+	attempt = 0
+	err = cloneToolRepository("https://example.com/tool.git", "v1.0.0", targetDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "git output of each attempt")
+	for i := 1; i <= maxCloneAttempts; i++ {
+		assert.Contains(t, err.Error(), fmt.Sprintf("attempt %d: failed to clone repository", i))
+		assert.Contains(t, err.Error(), fmt.Sprintf("fatal: error on attempt %d", i))
+	}
+}
+
+func TestCloneToolRepository_Offline(t *testing.T) {
+	mockRunner := &registry.MockGitRunner{}
+	setGitRunner(t, mockRunner)
+	registry.SetOffline(true)
+	t.Cleanup(func() { registry.SetOffline(false) })
+
+	err := cloneToolRepository("https://example.com/tool.git", "v1.0.0", filepath.Join(t.TempDir(), "tool"))
+	require.ErrorIs(t, err, registry.ErrOffline)
+	assert.Contains(t, err.Error(), "offline mode: cannot clone https://example.com/tool.git at v1.0.0")
+	assert.Empty(t, mockRunner.RunArgs(), "offline mode should not run git")
+}
+
+func TestCloneToolRepository_PermanentErrorNotRetried(t *testing.T) {
+	for _, output := range []string{
+		"warning: Could not find remote branch v9.9.9 to clone.\nfatal: Remote branch v9.9.9 not found in upstream origin\nerror: pathspec 'v9.9.9' did not match any file(s) known to git",
+		"remote: Invalid username or password.\nfatal: Authentication failed for 'https://example.com/tool.git/'",
+	} {
+		mockRunner := &registry.MockGitRunner{
+			RunFunc: func(_, dir string, args ...string) ([]byte, error) {
+				return []byte(output), assert.AnError
+			},
+		}
+		setGitRunner(t, mockRunner)
+
+		err := cloneToolRepository("https://example.com/tool.git", "v9.9.9", filepath.Join(t.TempDir(), "tool"))
+		require.Error(t, err)
+		// Each attempt starts with a clone of the tag's branch
+		attempts := 0
+		for _, args := range mockRunner.RunArgs() {
+			if slices.Contains(args, "--branch") {
+				attempts++
+			}
+		}
+		assert.Equal(t, 1, attempts, "a permanent failure should not be retried")
+		assert.NotContains(t, err.Error(), "giving up")
+		assert.Contains(t, err.Error(), "fatal:")
+	}
+}
+
+func TestTailLines(t *testing.T) {
+	assert.Equal(t, "", tailLines(nil, 3))
+	assert.Equal(t, "a\nb", tailLines([]byte("a\nb\n"), 3))
+	assert.Equal(t, "c\nd", tailLines([]byte("a\nb\nc\nd"), 2))
+	assert.Equal(t, "b\nc", tailLines([]byte("a\n\nb\n  \nc\n\n"), 2))
+}
@@ -3,9 +3,11 @@ package installer
 
 import (
 	"fmt"
+	"mime"
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
@@ -24,8 +26,11 @@ const (
 // ToolManifestFileName is the name of the tool.yaml manifest file as defined in RFC 3
 const ToolManifestFileName = "tool.yaml"
 
-var validRuntimeModes = []core.RuntimeMode{core.RuntimeModeSimple, core.RuntimeModeCapsule}
+var validRuntimeModes = []core.RuntimeMode{
+	core.RuntimeModeSimple, core.RuntimeModeCapsule, core.RuntimeModePersistent, core.RuntimeModeNpx, core.RuntimeModePipx,
+}
 var validHotLoadModes = []core.HotLoadMode{core.HotLoadModeRestart}
+var validContentAnnotationAudiences = []core.ContentAnnotationAudience{core.ContentAnnotationAudienceUser, core.ContentAnnotationAudienceAssistant}
 
 // LoadManifest loads and parses a tool.yaml manifest from the given directory
 func LoadManifest(toolDir string) (*core.ToolManifest, error) {
@@ -42,6 +47,23 @@ func LoadManifest(toolDir string) (*core.ToolManifest, error) {
 		return nil, fmt.Errorf("failed to read tool.yaml: %w", err)
 	}
 
+	return parseManifest(data)
+}
+
+// LoadManifestFile loads and parses a tool manifest from the given file, which may have any name.
+// It is used to run a tool directly from its manifest during development.
+func LoadManifestFile(manifestPath string) (*core.ToolManifest, error) {
+	// #nosec G304 -- manifestPath is a manifest the user explicitly asked to load
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	return parseManifest(data)
+}
+
+// parseManifest parses the contents of a tool manifest
+func parseManifest(data []byte) (*core.ToolManifest, error) {
 	var manifest core.ToolManifest
 	if err := yaml.Unmarshal(data, &manifest); err != nil {
 		return nil, fmt.Errorf("failed to parse tool.yaml: %w", err)
@@ -50,7 +72,24 @@ func LoadManifest(toolDir string) (*core.ToolManifest, error) {
 	return &manifest, nil
 }
 
-var validate = validator.New()
+var validate = newValidator()
+
+// newValidator returns the validator of manifest struct fields
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterStructValidation(validateEntrypointRequired, core.ToolManifest{})
+	return v
+}
+
+// validateEntrypointRequired reports a missing entrypoint like a missing required field, except
+// for tools that run a package and have no entrypoint
+func validateEntrypointRequired(sl validator.StructLevel) {
+	manifest, ok := sl.Current().Interface().(core.ToolManifest)
+	if !ok || manifest.Entrypoint != "" || core.IsPackageTool(&manifest) {
+		return
+	}
+	sl.ReportError(manifest.Entrypoint, "Entrypoint", "Entrypoint", "required", "")
+}
 
 // ValidateManifest validates a tool manifest
 func ValidateManifest(manifest *core.ToolManifest, toolDir string) error {
@@ -59,7 +98,137 @@ func ValidateManifest(manifest *core.ToolManifest, toolDir string) error {
 		return fmt.Errorf("manifest validation failed: %w", err)
 	}
 
-	// Validate entrypoint exists and is within tool directory
+	if manifest.Command != "" {
+		return fmt.Errorf("invalid command: only tools defined in tools_registry in the config can set command, tool.yaml must use an entrypoint")
+	}
+
+	if err := core.ValidateStability(manifest); err != nil {
+		return err
+	}
+
+	if err := core.ValidateArgStyle(manifest); err != nil {
+		return err
+	}
+
+	if err := core.ValidateStdinFormat(manifest); err != nil {
+		return err
+	}
+
+	if err := core.ValidateOutputJSONPath(manifest); err != nil {
+		return err
+	}
+
+	// A runtime without a mode keeps its other settings, which are checked against simple mode
+	if manifest.Runtime == nil {
+		manifest.Runtime = &core.RuntimeConfig{}
+	}
+	if manifest.Runtime.Mode == "" {
+		manifest.Runtime.Mode = core.RuntimeModeSimple
+	}
+
+	if !slices.Contains(validRuntimeModes, manifest.Runtime.Mode) {
+		return fmt.Errorf("invalid runtime.mode: %s", manifest.Runtime.Mode)
+	}
+
+	// A tool that runs a package has nothing to run in its directory
+	if core.IsPackageTool(manifest) {
+		if manifest.Entrypoint != "" {
+			return fmt.Errorf("invalid entrypoint: %s mode tools run runtime.package and cannot set an entrypoint", manifest.Runtime.Mode)
+		}
+		if err := core.ValidatePackage(manifest); err != nil {
+			return err
+		}
+	} else if err := validateEntrypoint(manifest, toolDir); err != nil {
+		return err
+	}
+
+	if err := core.ValidateModeFields(manifest); err != nil {
+		return err
+	}
+
+	// Set default startup timeout for capsule mode
+	if manifest.Runtime.Mode == core.RuntimeModeCapsule && manifest.Runtime.StartupTimeoutMs == 0 {
+		manifest.Runtime.StartupTimeoutMs = DefaultStartupTimeoutMs
+	}
+
+	if manifest.Runtime.HealthCheckIntervalMs < 0 {
+		return fmt.Errorf("invalid runtime.health_check_interval_ms: %d (must not be negative)", manifest.Runtime.HealthCheckIntervalMs)
+	}
+
+	if manifest.Runtime.HealthCheckFailures < 0 {
+		return fmt.Errorf("invalid runtime.health_check_failures: %d (must not be negative)", manifest.Runtime.HealthCheckFailures)
+	}
+
+	if manifest.Runtime.MaxRestarts < 0 {
+		return fmt.Errorf("invalid runtime.max_restarts: %d (must not be negative)", manifest.Runtime.MaxRestarts)
+	}
+
+	// Validate hot_load configuration
+	if manifest.Runtime.HotLoad != nil {
+		if manifest.Runtime.HotLoad.Mode == "" {
+			manifest.Runtime.HotLoad.Mode = core.HotLoadModeRestart
+		}
+
+		if !slices.Contains(validHotLoadModes, manifest.Runtime.HotLoad.Mode) {
+			return fmt.Errorf("invalid runtime.hot_load.mode: %s. ", manifest.Runtime.HotLoad.Mode)
+		}
+
+		// Set default debounce if not specified
+		if manifest.Runtime.HotLoad.DebounceMs == 0 {
+			manifest.Runtime.HotLoad.DebounceMs = DefaultHotLoadDebounceMs
+		}
+	}
+
+	if err := core.ValidateMinOrlaVersion(manifest.MinOrlaVersion); err != nil {
+		return err
+	}
+
+	if manifest.MaxInputBytes < 0 {
+		return fmt.Errorf("invalid max_input_bytes: %d (must not be negative)", manifest.MaxInputBytes)
+	}
+
+	if manifest.TimeoutSeconds < 0 {
+		return fmt.Errorf("invalid timeout_seconds: %d (must not be negative)", manifest.TimeoutSeconds)
+	}
+
+	if err := validateEnv(manifest); err != nil {
+		return err
+	}
+
+	if err := validateRetry(manifest); err != nil {
+		return err
+	}
+
+	// Validate output annotations
+	if manifest.MCP != nil && manifest.MCP.OutputAnnotations != nil {
+		if err := validateContentAnnotation("mcp.output_annotations.stdout", manifest.MCP.OutputAnnotations.Stdout); err != nil {
+			return err
+		}
+		if err := validateContentAnnotation("mcp.output_annotations.stderr", manifest.MCP.OutputAnnotations.Stderr); err != nil {
+			return err
+		}
+	}
+
+	if manifest.MCP != nil && manifest.MCP.ContentType != "" {
+		if _, _, err := mime.ParseMediaType(manifest.MCP.ContentType); err != nil {
+			return fmt.Errorf("invalid mcp.content_type: %s: %w", manifest.MCP.ContentType, err)
+		}
+		if core.IsImageContentType(manifest.MCP.ContentType) && (manifest.MCP.OutputSchema != nil || manifest.MCP.OutputJSONPath != "") {
+			return fmt.Errorf("mcp.content_type %s is an image type, so stdout is not JSON and cannot be used with mcp.output_schema or mcp.output_json_path", manifest.MCP.ContentType)
+		}
+	}
+
+	if manifest.MCP != nil {
+		if err := validatePassMeta(manifest.MCP.PassMeta); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateEntrypoint checks that the entrypoint exists within the tool directory
+func validateEntrypoint(manifest *core.ToolManifest, toolDir string) error {
 	// os.Root automatically prevents path traversal, so we can use it directly
 	root, err := os.OpenRoot(toolDir)
 	if err != nil {
@@ -81,36 +250,79 @@ func ValidateManifest(manifest *core.ToolManifest, toolDir string) error {
 		zap.L().Debug("Entrypoint is not executable, assuming script with interpreter", zap.String("path", entrypointPath))
 	}
 
-	if manifest.Runtime == nil || manifest.Runtime.Mode == "" {
-		manifest.Runtime = &core.RuntimeConfig{
-			Mode: core.RuntimeModeSimple,
+	return nil
+}
+
+// validateEnv checks the names of the environment variables a tool declares and passes through
+func validateEnv(manifest *core.ToolManifest) error {
+	for key := range manifest.Env {
+		if key == "" || strings.Contains(key, "=") {
+			return fmt.Errorf("invalid env: %q is not a valid environment variable name", key)
+		}
+	}
+	for _, key := range manifest.EnvPassthrough {
+		if key == "" || strings.Contains(key, "=") {
+			return fmt.Errorf("invalid env_passthrough: %q is not a valid environment variable name", key)
 		}
 	}
+	return nil
+}
 
-	if !slices.Contains(validRuntimeModes, manifest.Runtime.Mode) {
-		return fmt.Errorf("invalid runtime.mode: %s", manifest.Runtime.Mode)
+// validateRetry checks the retry settings of a tool. Retries re-run the tool process, so they
+// are only supported for simple mode tools.
+func validateRetry(manifest *core.ToolManifest) error {
+	retry := manifest.Retry
+	if retry == nil {
+		return nil
 	}
 
-	// Set default startup timeout for capsule mode
-	if manifest.Runtime.Mode == core.RuntimeModeCapsule && manifest.Runtime.StartupTimeoutMs == 0 {
-		manifest.Runtime.StartupTimeoutMs = DefaultStartupTimeoutMs
+	if manifest.Runtime != nil && manifest.Runtime.Mode != "" && manifest.Runtime.Mode != core.RuntimeModeSimple {
+		return fmt.Errorf("invalid retry: only simple mode tools can be retried")
 	}
+	if retry.Attempts < 1 {
+		return fmt.Errorf("invalid retry.attempts: %d (must be at least 1)", retry.Attempts)
+	}
+	if retry.BackoffMs < 0 {
+		return fmt.Errorf("invalid retry.backoff_ms: %d (must not be negative)", retry.BackoffMs)
+	}
+	if slices.Contains(retry.RetryOnExitCodes, 0) {
+		return fmt.Errorf("invalid retry.retry_on_exit_codes: 0 is success and cannot be retried")
+	}
+	return nil
+}
 
-	// Validate hot_load configuration
-	if manifest.Runtime.HotLoad != nil {
-		if manifest.Runtime.HotLoad.Mode == "" {
-			manifest.Runtime.HotLoad.Mode = core.HotLoadModeRestart
+// validatePassMeta checks that the _meta fields passed to a tool are non-empty and map to
+// distinct environment variables
+func validatePassMeta(keys []string) error {
+	envNames := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if key == "" {
+			return fmt.Errorf("invalid mcp.pass_meta: empty field name")
 		}
-
-		if !slices.Contains(validHotLoadModes, manifest.Runtime.HotLoad.Mode) {
-			return fmt.Errorf("invalid runtime.hot_load.mode: %s. ", manifest.Runtime.HotLoad.Mode)
+		envName := core.MetaEnvName(key)
+		if other, ok := envNames[envName]; ok {
+			return fmt.Errorf("invalid mcp.pass_meta: %q and %q are both passed as %s", other, key, envName)
 		}
+		envNames[envName] = key
+	}
+	return nil
+}
 
-		// Set default debounce if not specified
-		if manifest.Runtime.HotLoad.DebounceMs == 0 {
-			manifest.Runtime.HotLoad.DebounceMs = DefaultHotLoadDebounceMs
+// validateContentAnnotation validates the audience and priority of a content annotation
+func validateContentAnnotation(field string, annotation *core.ContentAnnotation) error {
+	if annotation == nil {
+		return nil
+	}
+
+	for _, audience := range annotation.Audience {
+		if !slices.Contains(validContentAnnotationAudiences, audience) {
+			return fmt.Errorf("invalid %s.audience: %s", field, audience)
 		}
 	}
 
+	if annotation.Priority != nil && (*annotation.Priority < 0 || *annotation.Priority > 1) {
+		return fmt.Errorf("invalid %s.priority: %v (must be between 0 and 1)", field, *annotation.Priority)
+	}
+
 	return nil
 }
@@ -130,12 +130,77 @@ func TestValidateManifest_RuntimeMode(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, core.RuntimeModeCapsule, manifest.Runtime.Mode)
 
+	// Valid manifest with persistent runtime mode. The capsule startup timeout default set above
+	// does not apply to it.
+	manifest.Runtime = &core.RuntimeConfig{Mode: core.RuntimeModePersistent}
+	err = ValidateManifest(manifest, tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, core.RuntimeModePersistent, manifest.Runtime.Mode)
+
 	// Invalid runtime mode
 	manifest.Runtime.Mode = core.RuntimeMode("invalid")
 	err = ValidateManifest(manifest, tmpDir)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid runtime.mode")
 
+	// Stability
+	manifest.Runtime = nil
+	manifest.Stability = core.ToolStabilityDeprecated
+	assert.NoError(t, ValidateManifest(manifest, tmpDir))
+	manifest.Stability = core.ToolStability("beta")
+	err = ValidateManifest(manifest, tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid stability: beta")
+	manifest.Stability = ""
+
+	// Arg style
+	manifest.ArgStyle = core.ArgStyleJSONStdin
+	assert.NoError(t, ValidateManifest(manifest, tmpDir))
+	manifest.ArgStyle = core.ArgStyle("argv")
+	err = ValidateManifest(manifest, tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid arg_style: argv")
+	manifest.ArgStyle = ""
+
+	// Stdin format
+	manifest.StdinFormat = core.StdinFormatJSONArgs
+	assert.NoError(t, ValidateManifest(manifest, tmpDir))
+	manifest.StdinFormat = core.StdinFormat("json")
+	err = ValidateManifest(manifest, tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid stdin_format: json")
+	manifest.StdinFormat = core.StdinFormatNone
+	manifest.Runtime = &core.RuntimeConfig{Mode: core.RuntimeModeCapsule}
+	err = ValidateManifest(manifest, tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid stdin_format: only applies to")
+	manifest.StdinFormat = ""
+	manifest.Runtime = nil
+
+	// Invalid output_json_path
+	manifest.MCP = &core.MCPConfig{OutputJSONPath: "data.result"}
+	err = ValidateManifest(manifest, tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid mcp.output_json_path 'data.result'")
+	manifest.MCP = nil
+
+	// Negative health check settings
+	manifest.Runtime = &core.RuntimeConfig{Mode: core.RuntimeModeCapsule, HealthCheckIntervalMs: -1}
+	err = ValidateManifest(manifest, tmpDir)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid runtime.health_check_interval_ms")
+
+	manifest.Runtime = &core.RuntimeConfig{Mode: core.RuntimeModeCapsule, HealthCheckFailures: -1}
+	err = ValidateManifest(manifest, tmpDir)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid runtime.health_check_failures")
+
+	// Negative max restarts
+	manifest.Runtime = &core.RuntimeConfig{Mode: core.RuntimeModeCapsule, MaxRestarts: -1}
+	err = ValidateManifest(manifest, tmpDir)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid runtime.max_restarts")
+
 	// Nil runtime (should default to simple)
 	manifest.Runtime = nil
 	err = ValidateManifest(manifest, tmpDir)
@@ -150,6 +215,203 @@ func TestValidateManifest_RuntimeMode(t *testing.T) {
 	assert.Equal(t, core.RuntimeModeSimple, manifest.Runtime.Mode)
 }
 
+func TestValidateManifest_OutputAnnotations(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	entrypointPath := filepath.Join(tmpDir, "bin", "tool")
+	require.NoError(t, os.MkdirAll(filepath.Dir(entrypointPath), 0700))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(entrypointPath, []byte("#!/bin/sh\necho test"), 0755))
+
+	lowPriority := 0.2
+	manifest := &core.ToolManifest{
+		Name:        "test-tool",
+		Version:     "1.0.0",
+		Description: "Test tool",
+		Entrypoint:  "bin/tool",
+		MCP: &core.MCPConfig{
+			OutputAnnotations: &core.OutputAnnotationsConfig{
+				Stderr: &core.ContentAnnotation{
+					Audience: []core.ContentAnnotationAudience{core.ContentAnnotationAudienceAssistant},
+					Priority: &lowPriority,
+				},
+			},
+		},
+	}
+	require.NoError(t, ValidateManifest(manifest, tmpDir))
+
+	// Invalid audience
+	manifest.MCP.OutputAnnotations.Stderr.Audience = []core.ContentAnnotationAudience{"developer"}
+	err := ValidateManifest(manifest, tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid mcp.output_annotations.stderr.audience: developer")
+
+	// Priority out of range
+	outOfRange := 1.5
+	manifest.MCP.OutputAnnotations.Stderr.Audience = nil
+	manifest.MCP.OutputAnnotations.Stdout = &core.ContentAnnotation{Priority: &outOfRange}
+	err = ValidateManifest(manifest, tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid mcp.output_annotations.stdout.priority")
+}
+
+func TestValidateManifest_ContentType(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	entrypointPath := filepath.Join(tmpDir, "bin", "tool")
+	require.NoError(t, os.MkdirAll(filepath.Dir(entrypointPath), 0700))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(entrypointPath, []byte("#!/bin/sh\necho test"), 0755))
+
+	manifest := &core.ToolManifest{
+		Name:        "test-tool",
+		Version:     "1.0.0",
+		Description: "Test tool",
+		Entrypoint:  "bin/tool",
+		MCP:         &core.MCPConfig{ContentType: "text/markdown; charset=utf-8"},
+	}
+	require.NoError(t, ValidateManifest(manifest, tmpDir))
+
+	manifest.MCP.ContentType = "markdown please"
+	err := ValidateManifest(manifest, tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid mcp.content_type: markdown please")
+
+	// An image tool prints the image, so its output cannot be selected or checked as JSON
+	manifest.MCP.ContentType = "image/png"
+	require.NoError(t, ValidateManifest(manifest, tmpDir))
+	manifest.MCP.OutputJSONPath = "$.chart"
+	err = ValidateManifest(manifest, tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is an image type")
+}
+
+func TestValidateManifest_MaxInputBytes(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	entrypointPath := filepath.Join(tmpDir, "bin", "tool")
+	require.NoError(t, os.MkdirAll(filepath.Dir(entrypointPath), 0700))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(entrypointPath, []byte("#!/bin/sh\necho test"), 0755))
+
+	manifest := &core.ToolManifest{
+		Name:          "test-tool",
+		Version:       "1.0.0",
+		Description:   "Test tool",
+		Entrypoint:    "bin/tool",
+		MaxInputBytes: 1 << 20,
+	}
+	require.NoError(t, ValidateManifest(manifest, tmpDir))
+
+	manifest.MaxInputBytes = -1
+	err := ValidateManifest(manifest, tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid max_input_bytes: -1")
+}
+
+func TestValidateManifest_TimeoutSeconds(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	entrypointPath := filepath.Join(tmpDir, "bin", "tool")
+	require.NoError(t, os.MkdirAll(filepath.Dir(entrypointPath), 0700))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(entrypointPath, []byte("#!/bin/sh\necho test"), 0755))
+
+	manifest := &core.ToolManifest{
+		Name:           "test-tool",
+		Version:        "1.0.0",
+		Description:    "Test tool",
+		Entrypoint:     "bin/tool",
+		TimeoutSeconds: 300,
+	}
+	require.NoError(t, ValidateManifest(manifest, tmpDir))
+
+	manifest.TimeoutSeconds = -1
+	err := ValidateManifest(manifest, tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid timeout_seconds: -1")
+}
+
+func TestValidateManifest_Env(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	entrypointPath := filepath.Join(tmpDir, "bin", "tool")
+	require.NoError(t, os.MkdirAll(filepath.Dir(entrypointPath), 0700))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(entrypointPath, []byte("#!/bin/sh\necho test"), 0755))
+
+	manifest := &core.ToolManifest{
+		Name:           "test-tool",
+		Version:        "1.0.0",
+		Description:    "Test tool",
+		Entrypoint:     "bin/tool",
+		Env:            map[string]string{"REGION": "eu-west-1"},
+		EnvPassthrough: []string{"PATH", "HOME"},
+	}
+	require.NoError(t, ValidateManifest(manifest, tmpDir))
+
+	manifest.EnvPassthrough = []string{"PATH", "HOME=/root"}
+	err := ValidateManifest(manifest, tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid env_passthrough: "HOME=/root"`)
+
+	manifest.EnvPassthrough = nil
+	manifest.Env = map[string]string{"": "value"}
+	err = ValidateManifest(manifest, tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid env: ""`)
+}
+
+func TestValidateManifest_Retry(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	entrypointPath := filepath.Join(tmpDir, "bin", "tool")
+	require.NoError(t, os.MkdirAll(filepath.Dir(entrypointPath), 0700))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(entrypointPath, []byte("#!/bin/sh\necho test"), 0755))
+
+	newManifest := func(retry *core.RetryConfig) *core.ToolManifest {
+		return &core.ToolManifest{
+			Name:        "test-tool",
+			Version:     "1.0.0",
+			Description: "Test tool",
+			Entrypoint:  "bin/tool",
+			Retry:       retry,
+		}
+	}
+
+	require.NoError(t, ValidateManifest(newManifest(&core.RetryConfig{Attempts: 3, BackoffMs: 100, RetryOnExitCodes: []int{75}}), tmpDir))
+
+	tests := []struct {
+		name        string
+		retry       *core.RetryConfig
+		errContains string
+	}{
+		{name: "no attempts", retry: &core.RetryConfig{}, errContains: "invalid retry.attempts: 0"},
+		{name: "negative backoff", retry: &core.RetryConfig{Attempts: 2, BackoffMs: -1}, errContains: "invalid retry.backoff_ms: -1"},
+		{name: "exit code zero", retry: &core.RetryConfig{Attempts: 2, RetryOnExitCodes: []int{0}}, errContains: "invalid retry.retry_on_exit_codes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateManifest(newManifest(tt.retry), tmpDir)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+
+	capsule := newManifest(&core.RetryConfig{Attempts: 2})
+	capsule.Runtime = &core.RuntimeConfig{Mode: core.RuntimeModeCapsule}
+	err := ValidateManifest(capsule, tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only simple mode tools can be retried")
+
+	persistent := newManifest(&core.RetryConfig{Attempts: 2})
+	persistent.Runtime = &core.RuntimeConfig{Mode: core.RuntimeModePersistent}
+	err = ValidateManifest(persistent, tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only simple mode tools can be retried")
+}
+
 func TestValidateManifest_Executable(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -232,3 +494,188 @@ func TestLoadManifest_OptionalFields(t *testing.T) {
 	assert.NotNil(t, loaded.Runtime)
 	assert.Equal(t, core.RuntimeModeSimple, loaded.Runtime.Mode)
 }
+
+func TestValidateManifest_PassMeta(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	entrypointPath := filepath.Join(tmpDir, "bin", "tool")
+	require.NoError(t, os.MkdirAll(filepath.Dir(entrypointPath), 0700))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(entrypointPath, []byte("#!/bin/sh\necho test"), 0755))
+
+	manifest := &core.ToolManifest{
+		Name:        "test-tool",
+		Version:     "1.0.0",
+		Description: "Test tool",
+		Entrypoint:  "bin/tool",
+		MCP:         &core.MCPConfig{PassMeta: []string{"traceparent", "trace-id"}},
+	}
+	require.NoError(t, ValidateManifest(manifest, tmpDir))
+
+	manifest.MCP.PassMeta = []string{""}
+	err := ValidateManifest(manifest, tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid mcp.pass_meta: empty field name")
+
+	manifest.MCP.PassMeta = []string{"trace-id", "trace_id"}
+	err = ValidateManifest(manifest, tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"trace-id" and "trace_id" are both passed as ORLA_META_TRACE_ID`)
+}
+
+func TestValidateManifest_ModeFields(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	entrypointPath := filepath.Join(tmpDir, "bin", "tool")
+	require.NoError(t, os.MkdirAll(filepath.Dir(entrypointPath), 0700))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(entrypointPath, []byte("#!/bin/sh\necho test"), 0755))
+
+	newManifest := func(runtime *core.RuntimeConfig, mcp *core.MCPConfig) *core.ToolManifest {
+		return &core.ToolManifest{
+			Name:        "test-tool",
+			Version:     "1.0.0",
+			Description: "Test tool",
+			Entrypoint:  "bin/tool",
+			Runtime:     runtime,
+			MCP:         mcp,
+		}
+	}
+
+	t.Run("valid capsule", func(t *testing.T) {
+		manifest := newManifest(&core.RuntimeConfig{
+			Mode:                  core.RuntimeModeCapsule,
+			StartupTimeoutMs:      2000,
+			HealthCheckIntervalMs: 1000,
+			HealthCheckFailures:   2,
+			MaxRestarts:           5,
+		}, nil)
+		require.NoError(t, ValidateManifest(manifest, tmpDir))
+	})
+
+	t.Run("valid simple", func(t *testing.T) {
+		manifest := newManifest(nil, &core.MCPConfig{PassMeta: []string{"trace-id"}})
+		require.NoError(t, ValidateManifest(manifest, tmpDir))
+		assert.Equal(t, core.RuntimeModeSimple, manifest.Runtime.Mode)
+	})
+
+	tests := []struct {
+		name     string
+		manifest *core.ToolManifest
+		expected string
+	}{
+		{
+			name:     "startup timeout on simple tool",
+			manifest: newManifest(&core.RuntimeConfig{StartupTimeoutMs: 2000}, nil),
+			expected: "invalid runtime.startup_timeout_ms: only applies to capsule mode tools, but runtime.mode is simple",
+		},
+		{
+			name:     "startup timeout on persistent tool",
+			manifest: newManifest(&core.RuntimeConfig{Mode: core.RuntimeModePersistent, StartupTimeoutMs: 2000}, nil),
+			expected: "invalid runtime.startup_timeout_ms: only applies to capsule mode tools, but runtime.mode is persistent",
+		},
+		{
+			name:     "health check on simple tool",
+			manifest: newManifest(&core.RuntimeConfig{Mode: core.RuntimeModeSimple, HealthCheckIntervalMs: 1000}, nil),
+			expected: "invalid runtime.health_check_interval_ms",
+		},
+		{
+			name:     "health check failures on persistent tool",
+			manifest: newManifest(&core.RuntimeConfig{Mode: core.RuntimeModePersistent, HealthCheckFailures: 2}, nil),
+			expected: "invalid runtime.health_check_failures",
+		},
+		{
+			name:     "max restarts on simple tool",
+			manifest: newManifest(&core.RuntimeConfig{MaxRestarts: 2}, nil),
+			expected: "invalid runtime.max_restarts",
+		},
+		{
+			name:     "pass meta on capsule tool",
+			manifest: newManifest(&core.RuntimeConfig{Mode: core.RuntimeModeCapsule}, &core.MCPConfig{PassMeta: []string{"trace-id"}}),
+			expected: "invalid mcp.pass_meta: only applies to simple, npx, or pipx mode tools, but runtime.mode is capsule",
+		},
+		{
+			name:     "package on simple tool",
+			manifest: newManifest(&core.RuntimeConfig{Package: "cowsay"}, nil),
+			expected: "invalid runtime.package: only applies to npx or pipx mode tools, but runtime.mode is simple",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateManifest(tt.manifest, tmpDir)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestValidateManifest_PackageMode(t *testing.T) {
+	newManifest := func(runtime *core.RuntimeConfig) *core.ToolManifest {
+		return &core.ToolManifest{
+			Name:        "fs-server",
+			Version:     "1.0.0",
+			Description: "Filesystem tools",
+			Runtime:     runtime,
+		}
+	}
+
+	t.Run("npx tool without entrypoint", func(t *testing.T) {
+		manifest := newManifest(&core.RuntimeConfig{Mode: core.RuntimeModeNpx, Package: "@modelcontextprotocol/server-filesystem"})
+		require.NoError(t, ValidateManifest(manifest, t.TempDir()))
+	})
+
+	t.Run("pipx tool with pass meta", func(t *testing.T) {
+		manifest := newManifest(&core.RuntimeConfig{Mode: core.RuntimeModePipx, Package: "black"})
+		manifest.MCP = &core.MCPConfig{PassMeta: []string{"trace-id"}}
+		require.NoError(t, ValidateManifest(manifest, t.TempDir()))
+	})
+
+	tests := []struct {
+		name     string
+		manifest *core.ToolManifest
+		expected string
+	}{
+		{
+			name:     "missing package",
+			manifest: newManifest(&core.RuntimeConfig{Mode: core.RuntimeModeNpx}),
+			expected: "invalid runtime.package: required for npx mode tools",
+		},
+		{
+			name:     "package that looks like an option",
+			manifest: newManifest(&core.RuntimeConfig{Mode: core.RuntimeModePipx, Package: "--spec"}),
+			expected: "invalid runtime.package",
+		},
+		{
+			name:     "package with whitespace",
+			manifest: newManifest(&core.RuntimeConfig{Mode: core.RuntimeModeNpx, Package: "cowsay --yes"}),
+			expected: "invalid runtime.package",
+		},
+		{
+			name:     "capsule setting on package tool",
+			manifest: newManifest(&core.RuntimeConfig{Mode: core.RuntimeModeNpx, Package: "cowsay", MaxRestarts: 2}),
+			expected: "invalid runtime.max_restarts: only applies to capsule mode tools, but runtime.mode is npx",
+		},
+		{
+			name:     "missing entrypoint on simple tool",
+			manifest: newManifest(nil),
+			expected: "'Entrypoint' failed on the 'required' tag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateManifest(tt.manifest, t.TempDir())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+
+	t.Run("entrypoint on package tool", func(t *testing.T) {
+		manifest := newManifest(&core.RuntimeConfig{Mode: core.RuntimeModeNpx, Package: "cowsay"})
+		manifest.Entrypoint = "bin/tool"
+		err := ValidateManifest(manifest, t.TempDir())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid entrypoint: npx mode tools run runtime.package and cannot set an entrypoint")
+	})
+}
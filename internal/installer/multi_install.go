@@ -0,0 +1,82 @@
+package installer
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/dorcha-inc/orla/internal/registry"
+)
+
+// ToolSpec names a registry tool and the version constraint to install
+type ToolSpec struct {
+	Name    string
+	Version string
+}
+
+// InstallResult is the outcome of installing one tool of a multi-tool install
+type InstallResult struct {
+	Spec ToolSpec
+	Err  error
+}
+
+// InstallTools installs several tools from the registry in parallel. At most maxConcurrentClones
+// tools are installed at once, which bounds the number of simultaneous git clones. The registry
+// is fetched once for all tools. Results are returned in the order of specs; an error is only
+// returned if no tool could be attempted. progress must be safe for concurrent use. If useCache
+// is false, the registry index and the tools' tags are fetched fresh instead of read from cache.
+// If replace is true, each installed version becomes the active version of its tool.
+func InstallTools(registryURL string, specs []ToolSpec, toolsDir string, maxConcurrentClones int, progress ProgressReporter, useCache, replace bool) ([]InstallResult, error) {
+	if toolsDir == "" {
+		return nil, fmt.Errorf("tools directory cannot be empty")
+	}
+
+	reg, errFetchRegistry := registry.FetchRegistry(registryURL, useCache)
+	if errFetchRegistry != nil {
+		return nil, fmt.Errorf("failed to fetch registry: %w", errFetchRegistry)
+	}
+
+	return installToolsFromRegistry(reg, registryURL, specs, toolsDir, maxConcurrentClones, progress, useCache, replace)
+}
+
+// installToolsFromRegistry installs several tools listed in an already fetched registry index
+// using a pool of maxConcurrentClones workers
+func installToolsFromRegistry(reg *registry.RegistryIndex, registryURL string, specs []ToolSpec, toolsDir string, maxConcurrentClones int, progress ProgressReporter, useCache, replace bool) ([]InstallResult, error) {
+	if maxConcurrentClones < 1 {
+		return nil, fmt.Errorf("max concurrent clones must be at least 1, got %d", maxConcurrentClones)
+	}
+
+	// Two installs of the same tool would race on its install directory
+	seen := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		if seen[spec.Name] {
+			return nil, fmt.Errorf("tool '%s' is listed more than once", spec.Name)
+		}
+		seen[spec.Name] = true
+	}
+
+	results := make([]InstallResult, len(specs))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(maxConcurrentClones, len(specs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				spec := specs[i]
+				results[i] = InstallResult{
+					Spec: spec,
+					Err:  installFromRegistry(reg, registryURL, spec.Name, spec.Version, toolsDir, progress, useCache, replace),
+				}
+			}
+		}()
+	}
+
+	for i := range specs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, nil
+}
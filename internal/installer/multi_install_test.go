@@ -0,0 +1,126 @@
+package installer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dorcha-inc/orla/internal/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// concurrentCloneRunner is a thread-safe git runner that records the peak number of
+// clones running at once. Each clone writes a valid tool named after its repository.
+type concurrentCloneRunner struct {
+	registry.MockGitRunner
+	inFlight atomic.Int32
+	peak     atomic.Int32
+	clones   atomic.Int32
+}
+
+func (r *concurrentCloneRunner) Run(_, dir string, args ...string) ([]byte, error) {
+	if args[0] != "clone" {
+		return nil, nil
+	}
+	r.clones.Add(1)
+
+	current := r.inFlight.Add(1)
+	defer r.inFlight.Add(-1)
+	for {
+		peak := r.peak.Load()
+		if current <= peak || r.peak.CompareAndSwap(peak, current) {
+			break
+		}
+	}
+
+	// Hold the clone open long enough for the other workers to start theirs
+	time.Sleep(20 * time.Millisecond)
+
+	var repository string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "https://") {
+			repository = arg
+		}
+	}
+	name := strings.TrimSuffix(filepath.Base(repository), ".git")
+	return nil, writeTestTool(args[len(args)-1], testToolManifest(name, "1.0.0"))
+}
+
+// multiInstallTestRegistry returns a registry index listing count tools and their specs
+func multiInstallTestRegistry(count int) (*registry.RegistryIndex, []ToolSpec) {
+	reg := &registry.RegistryIndex{Version: registry.SupportedRegistryVersion}
+	var specs []ToolSpec
+	for i := range count {
+		name := fmt.Sprintf("tool-%d", i)
+		reg.Tools = append(reg.Tools, registry.ToolEntry{
+			Name:        name,
+			Description: "A tool installed with others",
+			Repository:  "https://example.com/" + name + ".git",
+		})
+		specs = append(specs, ToolSpec{Name: name, Version: "v1.0.0"})
+	}
+	return reg, specs
+}
+
+func TestInstallToolsFromRegistry_RespectsCloneLimit(t *testing.T) {
+	for _, maxConcurrentClones := range []int{1, 2, 3} {
+		t.Run(fmt.Sprintf("max %d", maxConcurrentClones), func(t *testing.T) {
+			runner := &concurrentCloneRunner{}
+			setGitRunner(t, runner)
+
+			reg, specs := multiInstallTestRegistry(8)
+			toolsDir := t.TempDir()
+
+			results, err := installToolsFromRegistry(reg, exampleRegistryURL, specs, toolsDir, maxConcurrentClones, NewTextProgress(&bytes.Buffer{}), false, false)
+			require.NoError(t, err)
+			require.Len(t, results, len(specs))
+
+			for i, result := range results {
+				assert.Equal(t, specs[i], result.Spec, "results should be in input order")
+				assert.NoError(t, result.Err)
+				_, errStat := os.Stat(filepath.Join(toolsDir, result.Spec.Name, "1.0.0", ToolManifestFileName))
+				assert.NoError(t, errStat)
+			}
+
+			assert.Equal(t, int32(len(specs)), runner.clones.Load())
+			assert.LessOrEqual(t, runner.peak.Load(), int32(maxConcurrentClones))
+			if maxConcurrentClones > 1 {
+				assert.Greater(t, runner.peak.Load(), int32(1), "installs should run in parallel")
+			}
+		})
+	}
+}
+
+func TestInstallToolsFromRegistry_ReportsFailuresPerTool(t *testing.T) {
+	setGitRunner(t, &concurrentCloneRunner{})
+
+	reg, specs := multiInstallTestRegistry(2)
+	specs = append(specs, ToolSpec{Name: "missing-tool", Version: "v1.0.0"})
+
+	results, err := installToolsFromRegistry(reg, exampleRegistryURL, specs, t.TempDir(), 2, NewTextProgress(&bytes.Buffer{}), false, false)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.NoError(t, results[0].Err)
+	assert.NoError(t, results[1].Err)
+	require.Error(t, results[2].Err)
+	assert.Contains(t, results[2].Err.Error(), "not found in registry")
+}
+
+func TestInstallToolsFromRegistry_InvalidInput(t *testing.T) {
+	reg, specs := multiInstallTestRegistry(2)
+
+	_, err := installToolsFromRegistry(reg, exampleRegistryURL, specs, t.TempDir(), 0, NewTextProgress(&bytes.Buffer{}), false, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "at least 1")
+
+	_, err = installToolsFromRegistry(reg, exampleRegistryURL, append(specs, specs[0]), t.TempDir(), 2, NewTextProgress(&bytes.Buffer{}), false, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "listed more than once")
+}
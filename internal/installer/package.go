@@ -0,0 +1,124 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/registry"
+)
+
+// installPackageTool installs a registry tool that runs a package (npx or pipx mode). The package
+// manager fetches the package when the tool runs, so nothing is cloned: the install directory only
+// holds a tool.yaml that records the package.
+func installPackageTool(tool *registry.ToolEntry, registryURL, versionConstraint string, toolsDir string, progress ProgressReporter, replace bool) error {
+	// A package tool has no git tags, the registry pins the one version it is installed as
+	reportProgress(progress, tool.Name, ProgressStageResolving, "resolving version %s", displayConstraint(versionConstraint))
+	if err := checkPackageVersion(tool, versionConstraint); err != nil {
+		return err
+	}
+
+	reportProgress(progress, tool.Name, ProgressStageVerifying, "verifying manifest")
+	manifest, err := packageToolManifest(tool)
+	if err != nil {
+		return err
+	}
+
+	absToolsDir, err := filepath.Abs(toolsDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve tools directory path: %w", err)
+	}
+
+	installDir := filepath.Join(absToolsDir, tool.Name, manifest.Version)
+
+	reportProgress(progress, tool.Name, ProgressStageCopying, "recording package %s in %s", tool.Package, installDir)
+	if err := writePackageManifest(installDir, manifest); err != nil {
+		return fmt.Errorf("failed to install tool to directory: %w", err)
+	}
+
+	receipt := &InstallReceipt{
+		Source:      InstallSourceRegistry,
+		RegistryURL: registryURL,
+		Package:     tool.Package,
+		Tag:         "v" + manifest.Version,
+		InstalledAt: time.Now().UTC(),
+	}
+	if errReceipt := writeInstallReceipt(installDir, receipt); errReceipt != nil {
+		zap.L().Warn("Failed to record install source, reinstall will fall back to the default registry",
+			zap.String("tool", tool.Name), zap.Error(errReceipt))
+	}
+
+	zap.L().Info("Tool installed successfully",
+		zap.String("tool", tool.Name),
+		zap.String("version", manifest.Version),
+		zap.String("package", tool.Package),
+		zap.String("path", installDir))
+	reportProgress(progress, tool.Name, ProgressStageInstalled, "installed version %s", manifest.Version)
+
+	if replace {
+		return activateInstalledVersion(progress, tool.Name, installDir)
+	}
+	return nil
+}
+
+// checkPackageVersion checks that the one version a package tool is available at satisfies
+// versionConstraint, which may be "latest", an exact tag, or a range constraint
+func checkPackageVersion(tool *registry.ToolEntry, versionConstraint string) error {
+	version := "v" + tool.Version
+	matches := versionConstraint == registry.VersionConstraintLatest || versionConstraint == registry.VersionConstraintEmpty ||
+		versionConstraint == version
+	if registry.IsVersionRange(versionConstraint) {
+		var err error
+		if matches, err = registry.MatchesVersionRange(versionConstraint, version); err != nil {
+			return err
+		}
+	}
+	if !matches {
+		return fmt.Errorf("tool '%s' runs package %s and is only available at version %s, not %s",
+			tool.Name, tool.Package, version, versionConstraint)
+	}
+	return nil
+}
+
+// packageToolManifest returns the validated manifest of a registry tool that runs a package
+func packageToolManifest(tool *registry.ToolEntry) (*core.ToolManifest, error) {
+	manifest := &core.ToolManifest{
+		Name:        tool.Name,
+		Version:     tool.Version,
+		Description: tool.Description,
+		Keywords:    tool.Keywords,
+		Stability:   tool.Stability,
+		Runtime:     &core.RuntimeConfig{Mode: tool.Mode, Package: tool.Package},
+	}
+	if !core.IsPackageMode(tool.Mode) {
+		return nil, fmt.Errorf("invalid registry entry for tool '%s': package tools must set mode to %s or %s, got %q",
+			tool.Name, core.RuntimeModeNpx, core.RuntimeModePipx, tool.Mode)
+	}
+	if err := ValidateManifest(manifest, ""); err != nil {
+		return nil, fmt.Errorf("invalid registry entry for tool '%s': %w", tool.Name, err)
+	}
+	return manifest, nil
+}
+
+// writePackageManifest writes the manifest of a package tool as the tool.yaml of dir
+func writePackageManifest(dir string, manifest *core.ToolManifest) error {
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	// #nosec G306 -- tool.yaml permissions 0644 are acceptable, it contains no secrets
+	if err := os.WriteFile(filepath.Join(dir, ToolManifestFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ToolManifestFileName, err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package installer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/registry"
+)
+
+// packageTestRegistry returns a registry listing a tool that runs an npm package
+func packageTestRegistry() *registry.RegistryIndex {
+	return &registry.RegistryIndex{
+		Version: registry.SupportedRegistryVersion,
+		Tools: []registry.ToolEntry{{
+			Name:        "fs-server",
+			Description: "Filesystem tools",
+			Keywords:    []string{"files"},
+			Package:     "@modelcontextprotocol/server-filesystem",
+			Mode:        core.RuntimeModeNpx,
+			Version:     "2.1.0",
+		}},
+	}
+}
+
+func TestInstallFromRegistry_PackageTool(t *testing.T) {
+	mockRunner := &registry.MockGitRunner{}
+	setGitRunner(t, mockRunner)
+
+	toolsDir := t.TempDir()
+	var events []ProgressEvent
+	require.NoError(t, installFromRegistry(packageTestRegistry(), exampleRegistryURL, "fs-server", "", toolsDir, recordProgress(&events), false, false))
+
+	assert.Empty(t, mockRunner.RunArgs(), "package tools should not be cloned")
+	assert.Equal(t, []ProgressStage{
+		ProgressStageResolving,
+		ProgressStageVerifying,
+		ProgressStageCopying,
+		ProgressStageInstalled,
+	}, progressStages(events))
+
+	installDir := filepath.Join(toolsDir, "fs-server", "2.1.0")
+	manifest, err := LoadManifest(installDir)
+	require.NoError(t, err)
+	require.NoError(t, ValidateManifest(manifest, installDir))
+	assert.Equal(t, "fs-server", manifest.Name)
+	assert.Equal(t, "2.1.0", manifest.Version)
+	assert.Empty(t, manifest.Entrypoint)
+	assert.Equal(t, core.RuntimeModeNpx, manifest.Runtime.Mode)
+	assert.Equal(t, "@modelcontextprotocol/server-filesystem", manifest.Runtime.Package)
+
+	receipt, err := LoadInstallReceipt(installDir)
+	require.NoError(t, err)
+	assert.Equal(t, InstallSourceRegistry, receipt.Source)
+	assert.Equal(t, "@modelcontextprotocol/server-filesystem", receipt.Package)
+	assert.Equal(t, "v2.1.0", receipt.Tag)
+	assert.Empty(t, receipt.Repository)
+}
+
+func TestInstallFromRegistry_PackageToolErrors(t *testing.T) {
+	setGitRunner(t, &registry.MockGitRunner{})
+
+	t.Run("other version", func(t *testing.T) {
+		err := installFromRegistry(packageTestRegistry(), exampleRegistryURL, "fs-server", "v1.0.0", t.TempDir(), nil, false, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only available at version v2.1.0")
+	})
+
+	t.Run("pinned version", func(t *testing.T) {
+		require.NoError(t, installFromRegistry(packageTestRegistry(), exampleRegistryURL, "fs-server", "v2.1.0", t.TempDir(), nil, false, false))
+	})
+
+	t.Run("matching range", func(t *testing.T) {
+		require.NoError(t, installFromRegistry(packageTestRegistry(), exampleRegistryURL, "fs-server", "^2.0.0", t.TempDir(), nil, false, false))
+	})
+
+	t.Run("range without the version", func(t *testing.T) {
+		err := installFromRegistry(packageTestRegistry(), exampleRegistryURL, "fs-server", ">=2.2.0", t.TempDir(), nil, false, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only available at version v2.1.0, not >=2.2.0")
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		err := installFromRegistry(packageTestRegistry(), exampleRegistryURL, "fs-server", "^two", t.TempDir(), nil, false, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid version constraint '^two'")
+	})
+
+	t.Run("not a package mode", func(t *testing.T) {
+		reg := packageTestRegistry()
+		reg.Tools[0].Mode = core.RuntimeModeCapsule
+		err := installFromRegistry(reg, exampleRegistryURL, "fs-server", "", t.TempDir(), nil, false, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "package tools must set mode to npx or pipx")
+	})
+
+	t.Run("missing version", func(t *testing.T) {
+		reg := packageTestRegistry()
+		reg.Tools[0].Version = ""
+		err := installFromRegistry(reg, exampleRegistryURL, "fs-server", "", t.TempDir(), nil, false, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid registry entry for tool 'fs-server'")
+	})
+}
@@ -0,0 +1,84 @@
+package installer
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/registry"
+)
+
+// ProgressStage is a step of installing a tool
+type ProgressStage string
+
+const (
+	// ProgressStageResolving resolves the requested version to a git tag
+	ProgressStageResolving ProgressStage = "resolving"
+	// ProgressStageCloning clones the tool repository
+	ProgressStageCloning ProgressStage = "cloning"
+	// ProgressStageVerifying loads and validates the tool manifest
+	ProgressStageVerifying ProgressStage = "verifying"
+	// ProgressStageCopying copies the tool files into the install directory
+	ProgressStageCopying ProgressStage = "copying"
+	// ProgressStageInstalled reports that the tool was installed
+	ProgressStageInstalled ProgressStage = "installed"
+	// ProgressStageActivated reports that the installed version became the tool's active version
+	ProgressStageActivated ProgressStage = "activated"
+)
+
+// ProgressEvent reports that an install of Tool reached Stage. Message describes the step for
+// display, e.g. the repository being cloned.
+type ProgressEvent struct {
+	Tool    string
+	Stage   ProgressStage
+	Message string
+}
+
+// ProgressReporter receives progress events during install, update, and reinstall. Reporters
+// passed to InstallTools must be safe for concurrent use.
+type ProgressReporter interface {
+	Report(event ProgressEvent)
+}
+
+// ProgressFunc adapts a function to a ProgressReporter
+type ProgressFunc func(event ProgressEvent)
+
+// Report calls f(event)
+func (f ProgressFunc) Report(event ProgressEvent) {
+	f(event)
+}
+
+// textProgress renders progress events as lines of plain text
+type textProgress struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewTextProgress returns a ProgressReporter that writes each event to w as a line of plain text.
+// It is safe for concurrent use.
+func NewTextProgress(w io.Writer) ProgressReporter {
+	return &textProgress{w: w}
+}
+
+func (t *textProgress) Report(event ProgressEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	core.MustFprintf(t.w, "%s: %s\n", event.Tool, event.Message)
+}
+
+// reportProgress sends an event to progress, which may be nil
+func reportProgress(progress ProgressReporter, tool string, stage ProgressStage, format string, args ...any) {
+	if progress == nil {
+		return
+	}
+	progress.Report(ProgressEvent{Tool: tool, Stage: stage, Message: fmt.Sprintf(format, args...)})
+}
+
+// displayConstraint returns a version constraint as shown in progress messages
+func displayConstraint(constraint string) string {
+	if constraint == registry.VersionConstraintEmpty {
+		return registry.VersionConstraintLatest
+	}
+	return constraint
+}
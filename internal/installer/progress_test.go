@@ -0,0 +1,104 @@
+package installer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/registry"
+)
+
+// recordProgress returns a ProgressReporter that appends events to events
+func recordProgress(events *[]ProgressEvent) ProgressReporter {
+	return ProgressFunc(func(event ProgressEvent) {
+		*events = append(*events, event)
+	})
+}
+
+// progressStages returns the stages of events in order
+func progressStages(events []ProgressEvent) []ProgressStage {
+	stages := make([]ProgressStage, 0, len(events))
+	for _, event := range events {
+		stages = append(stages, event.Stage)
+	}
+	return stages
+}
+
+func TestInstallFromRegistry_ProgressEvents(t *testing.T) {
+	setGitRunner(t, &concurrentCloneRunner{})
+
+	reg, _ := multiInstallTestRegistry(1)
+	toolsDir := t.TempDir()
+
+	var events []ProgressEvent
+	err := installFromRegistry(reg, exampleRegistryURL, "tool-0", "v1.0.0", toolsDir, recordProgress(&events), false, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, []ProgressStage{
+		ProgressStageResolving,
+		ProgressStageCloning,
+		ProgressStageVerifying,
+		ProgressStageCopying,
+		ProgressStageInstalled,
+	}, progressStages(events))
+
+	for _, event := range events {
+		assert.Equal(t, "tool-0", event.Tool)
+		assert.NotEmpty(t, event.Message)
+	}
+	assert.Contains(t, events[1].Message, "https://example.com/tool-0.git")
+	assert.Contains(t, events[3].Message, filepath.Join(toolsDir, "tool-0", "1.0.0"))
+	assert.Equal(t, "installed version 1.0.0", events[4].Message)
+}
+
+func TestInstallFromRegistry_ProgressStopsOnFailure(t *testing.T) {
+	noCloneRetryBackoff(t)
+	setGitRunner(t, &registry.MockGitRunner{
+		RunFunc: func(_, dir string, args ...string) ([]byte, error) {
+			return nil, os.ErrPermission
+		},
+	})
+
+	reg, _ := multiInstallTestRegistry(1)
+
+	var events []ProgressEvent
+	err := installFromRegistry(reg, exampleRegistryURL, "tool-0", "v1.0.0", t.TempDir(), recordProgress(&events), false, false)
+	require.Error(t, err)
+
+	assert.Equal(t, []ProgressStage{ProgressStageResolving, ProgressStageCloning}, progressStages(events))
+}
+
+func TestInstallLocalTool_ProgressEvents(t *testing.T) {
+	localToolDir := t.TempDir()
+	require.NoError(t, writeTestTool(localToolDir, testToolManifest("local-tool", "1.0.0")))
+
+	var events []ProgressEvent
+	require.NoError(t, InstallLocalTool(localToolDir, t.TempDir(), recordProgress(&events)))
+
+	assert.Equal(t, []ProgressStage{
+		ProgressStageVerifying,
+		ProgressStageCopying,
+		ProgressStageInstalled,
+	}, progressStages(events))
+}
+
+func TestInstallLocalTool_NilProgress(t *testing.T) {
+	localToolDir := t.TempDir()
+	require.NoError(t, writeTestTool(localToolDir, testToolManifest("local-tool", "1.0.0")))
+
+	require.NoError(t, InstallLocalTool(localToolDir, t.TempDir(), nil))
+}
+
+func TestNewTextProgress(t *testing.T) {
+	var buf bytes.Buffer
+	progress := NewTextProgress(&buf)
+
+	progress.Report(ProgressEvent{Tool: "fs", Stage: ProgressStageCloning, Message: "cloning repo at v1.0.0"})
+	progress.Report(ProgressEvent{Tool: "fs", Stage: ProgressStageInstalled, Message: "installed version 1.0.0"})
+
+	assert.Equal(t, "fs: cloning repo at v1.0.0\nfs: installed version 1.0.0\n", buf.String())
+}
@@ -0,0 +1,363 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/registry"
+)
+
+// InstallReceiptFileName is the file in each installed tool version that records where it was installed from
+const InstallReceiptFileName = ".orla-install.yaml"
+
+// InstallSource is where an installed tool came from
+type InstallSource string
+
+const (
+	// InstallSourceRegistry marks a tool installed from a registry
+	InstallSourceRegistry InstallSource = "registry"
+	// InstallSourceLocal marks a tool installed from a local directory
+	InstallSourceLocal InstallSource = "local"
+)
+
+// InstallReceipt records the source of an installed tool version so it can be reinstalled
+type InstallReceipt struct {
+	Source      InstallSource `yaml:"source"`
+	RegistryURL string        `yaml:"registry_url,omitempty"`
+	Repository  string        `yaml:"repository,omitempty"`
+	Tag         string        `yaml:"tag,omitempty"`
+	Ref         string        `yaml:"ref,omitempty"`     // Git branch or commit of a tool installed with --ref instead of a release tag
+	Package     string        `yaml:"package,omitempty"` // Package run by an npx or pipx mode tool, which has no repository
+	LocalPath   string        `yaml:"local_path,omitempty"`
+	InstalledAt time.Time     `yaml:"installed_at"`
+}
+
+// writeInstallReceipt writes the install receipt into an installed tool version directory
+func writeInstallReceipt(installDir string, receipt *InstallReceipt) error {
+	data, err := yaml.Marshal(receipt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal install receipt: %w", err)
+	}
+
+	root, err := os.OpenRoot(installDir)
+	if err != nil {
+		return fmt.Errorf("failed to open install directory: %w", err)
+	}
+	defer core.LogDeferredError(root.Close)
+
+	// #nosec G306 -- install receipt permissions 0644 are acceptable, it contains no secrets
+	if err := root.WriteFile(InstallReceiptFileName, data, 0644); err != nil {
+		return fmt.Errorf("failed to write install receipt: %w", err)
+	}
+	return nil
+}
+
+// LoadInstallReceipt loads the install receipt of an installed tool version directory
+func LoadInstallReceipt(installDir string) (*InstallReceipt, error) {
+	root, err := os.OpenRoot(installDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open install directory: %w", err)
+	}
+	defer core.LogDeferredError(root.Close)
+
+	data, err := root.ReadFile(InstallReceiptFileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read install receipt: %w", err)
+	}
+
+	var receipt InstallReceipt
+	if err := yaml.Unmarshal(data, &receipt); err != nil {
+		return nil, fmt.Errorf("failed to parse install receipt: %w", err)
+	}
+
+	switch receipt.Source {
+	case InstallSourceRegistry:
+		if (receipt.Tag == "" && receipt.Ref == "") || (receipt.Repository == "" && receipt.RegistryURL == "") {
+			return nil, fmt.Errorf("install receipt is missing the registry source")
+		}
+	case InstallSourceLocal:
+		if receipt.LocalPath == "" {
+			return nil, fmt.Errorf("install receipt is missing the local path")
+		}
+	default:
+		return nil, fmt.Errorf("install receipt has unknown source: %q", receipt.Source)
+	}
+
+	return &receipt, nil
+}
+
+// InstalledVersion is a tool version directory in the tools directory
+type InstalledVersion struct {
+	Name    string
+	Version string
+	Path    string
+}
+
+// ListInstalledVersions lists the tool version directories (TOOL-NAME/VERSION/) in toolsDir.
+// Unlike ListInstalledTools, it does not read manifests, so it also finds corrupted installs.
+func ListInstalledVersions(toolsDir string) ([]InstalledVersion, error) {
+	if toolsDir == "" {
+		return nil, fmt.Errorf("tools directory cannot be empty")
+	}
+
+	absToolsDir, err := filepath.Abs(toolsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve tools directory path: %w", err)
+	}
+
+	toolEntries, err := os.ReadDir(absToolsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read tools directory: %w", err)
+	}
+
+	var versions []InstalledVersion
+	for _, toolEntry := range toolEntries {
+		if !toolEntry.IsDir() || strings.HasPrefix(toolEntry.Name(), ".") {
+			continue
+		}
+
+		toolDir := filepath.Join(absToolsDir, toolEntry.Name())
+		versionEntries, err := os.ReadDir(toolDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tool directory %s: %w", toolDir, err)
+		}
+
+		for _, versionEntry := range versionEntries {
+			// Hidden directories are staging and backup directories of in-progress reinstalls
+			if !versionEntry.IsDir() || strings.HasPrefix(versionEntry.Name(), ".") {
+				continue
+			}
+			versions = append(versions, InstalledVersion{
+				Name:    toolEntry.Name(),
+				Version: versionEntry.Name(),
+				Path:    filepath.Join(toolDir, versionEntry.Name()),
+			})
+		}
+	}
+
+	return versions, nil
+}
+
+// ReinstallTool reinstalls an installed tool version from the source recorded in its install
+// receipt, atomically replacing the install directory. Tools without a readable receipt are
+// reinstalled from defaultRegistryURL at the installed version.
+func ReinstallTool(toolName, version, toolsDir, defaultRegistryURL string, progress ProgressReporter) error {
+	if toolsDir == "" {
+		return fmt.Errorf("tools directory cannot be empty")
+	}
+
+	absToolsDir, err := filepath.Abs(toolsDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve tools directory path: %w", err)
+	}
+	installDir := filepath.Join(absToolsDir, toolName, version)
+
+	if _, errStat := core.FileStat(installDir, fmt.Sprintf("tool '%s' version %s not installed", toolName, version), "failed to stat tool directory"); errStat != nil {
+		return errStat
+	}
+
+	receipt, errReceipt := LoadInstallReceipt(installDir)
+	if errReceipt != nil && IsRefVersion(version) {
+		return fmt.Errorf("tool '%s' version %s was installed from a git ref and has no usable install receipt: %w", toolName, version, errReceipt)
+	}
+	if errReceipt != nil {
+		zap.L().Warn("No usable install receipt, reinstalling from the default registry",
+			zap.String("tool", toolName),
+			zap.String("version", version),
+			zap.String("registry", defaultRegistryURL),
+			zap.Error(errReceipt))
+		receipt = &InstallReceipt{
+			Source:      InstallSourceRegistry,
+			RegistryURL: defaultRegistryURL,
+			Tag:         "v" + version,
+		}
+	}
+
+	sourceDir, cleanup, err := prepareReinstallSource(toolName, receipt, progress)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	reportProgress(progress, toolName, ProgressStageVerifying, "verifying manifest")
+	manifest, errLoadManifest := LoadManifest(sourceDir)
+	if errLoadManifest != nil {
+		return fmt.Errorf("failed to load manifest: %w", errLoadManifest)
+	}
+	if errValidateManifest := ValidateManifest(manifest, sourceDir); errValidateManifest != nil {
+		return fmt.Errorf("failed to validate manifest: %w", errValidateManifest)
+	}
+	// A ref install is reinstalled from the ref's current commit, whatever version it now declares
+	if manifest.Name != toolName || (receipt.Ref == "" && manifest.Version != version) {
+		return fmt.Errorf("source of tool '%s' version %s now provides '%s' version %s",
+			toolName, version, manifest.Name, manifest.Version)
+	}
+
+	receipt.InstalledAt = time.Now().UTC()
+	reportProgress(progress, toolName, ProgressStageCopying, "copying to %s", installDir)
+	if err := replaceInstallDirectory(sourceDir, installDir, receipt); err != nil {
+		return err
+	}
+
+	zap.L().Info("Tool reinstalled successfully",
+		zap.String("tool", toolName),
+		zap.String("version", version),
+		zap.String("source", string(receipt.Source)),
+		zap.String("path", installDir))
+	reportProgress(progress, toolName, ProgressStageInstalled, "reinstalled version %s", version)
+
+	return nil
+}
+
+// prepareReinstallSource returns a directory containing the tool's files as recorded in receipt.
+// The returned cleanup function must always be called.
+func prepareReinstallSource(toolName string, receipt *InstallReceipt, progress ProgressReporter) (string, func(), error) {
+	noop := func() {}
+
+	if receipt.Source == InstallSourceLocal {
+		if _, err := core.FileStat(receipt.LocalPath, "local source no longer exists", "failed to stat local source"); err != nil {
+			return "", noop, err
+		}
+		return receipt.LocalPath, noop, nil
+	}
+
+	if receipt.Package != "" {
+		return preparePackageReinstallSource(toolName, receipt)
+	}
+
+	// The registry entry supplies the repository if the receipt does not record it, and the
+	// checksum of a release tag. Refs are not checksummed, as on install.
+	var tool *registry.ToolEntry
+	if receipt.Repository == "" || (receipt.Ref == "" && receipt.RegistryURL != "") {
+		reg, errFetchRegistry := registry.FetchRegistry(receipt.RegistryURL, true)
+		if errFetchRegistry != nil {
+			return "", noop, fmt.Errorf("failed to fetch registry: %w", errFetchRegistry)
+		}
+		var errFindTool error
+		tool, errFindTool = registry.FindTool(reg, toolName)
+		if errFindTool != nil {
+			return "", noop, fmt.Errorf("tool '%s' not found in registry: %w", toolName, errFindTool)
+		}
+	}
+
+	repository := receipt.Repository
+	if repository == "" {
+		repository = tool.Repository
+		receipt.Repository = repository
+	}
+
+	tempDir, errCreateTempDir := os.MkdirTemp("", "orla-reinstall-*")
+	if errCreateTempDir != nil {
+		return "", noop, fmt.Errorf("failed to create temp directory: %w", errCreateTempDir)
+	}
+	cleanup := func() { core.LogDeferredError(func() error { return os.RemoveAll(tempDir) }) }
+
+	gitRef := receipt.Tag
+	if receipt.Ref != "" {
+		gitRef = receipt.Ref
+	}
+
+	cloneDir := filepath.Join(tempDir, "tool")
+	reportProgress(progress, toolName, ProgressStageCloning, "cloning %s at %s", repository, gitRef)
+	if errClone := cloneToolRepository(repository, gitRef, cloneDir); errClone != nil {
+		cleanup()
+		return "", noop, fmt.Errorf("failed to clone tool repository: %w", errClone)
+	}
+
+	if tool != nil && receipt.Ref == "" {
+		if errChecksum := verifyToolChecksum(tool, receipt.Tag, cloneDir); errChecksum != nil {
+			cleanup()
+			return "", noop, errChecksum
+		}
+	}
+
+	return cloneDir, cleanup, nil
+}
+
+// preparePackageReinstallSource returns a directory containing the tool.yaml of a package tool as
+// its registry lists it now. The returned cleanup function must always be called.
+func preparePackageReinstallSource(toolName string, receipt *InstallReceipt) (string, func(), error) {
+	noop := func() {}
+
+	reg, errFetchRegistry := registry.FetchRegistry(receipt.RegistryURL, true)
+	if errFetchRegistry != nil {
+		return "", noop, fmt.Errorf("failed to fetch registry: %w", errFetchRegistry)
+	}
+	tool, errFindTool := registry.FindTool(reg, toolName)
+	if errFindTool != nil {
+		return "", noop, fmt.Errorf("tool '%s' not found in registry: %w", toolName, errFindTool)
+	}
+
+	manifest, err := packageToolManifest(tool)
+	if err != nil {
+		return "", noop, err
+	}
+	receipt.Package = tool.Package
+
+	tempDir, errCreateTempDir := os.MkdirTemp("", "orla-reinstall-*")
+	if errCreateTempDir != nil {
+		return "", noop, fmt.Errorf("failed to create temp directory: %w", errCreateTempDir)
+	}
+	cleanup := func() { core.LogDeferredError(func() error { return os.RemoveAll(tempDir) }) }
+
+	if err := writePackageManifest(tempDir, manifest); err != nil {
+		cleanup()
+		return "", noop, err
+	}
+	return tempDir, cleanup, nil
+}
+
+// replaceInstallDirectory installs sourceDir into a staging directory next to installDir and then
+// swaps it into place, so installDir is never left partially written. The previous contents are
+// restored if the swap fails.
+func replaceInstallDirectory(sourceDir, installDir string, receipt *InstallReceipt) error {
+	parentDir := filepath.Dir(installDir)
+	versionName := filepath.Base(installDir)
+
+	stagingDir, err := os.MkdirTemp(parentDir, "."+versionName+".staging-*")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer core.LogDeferredError(func() error { return os.RemoveAll(stagingDir) })
+
+	if err := InstallToDirectory(sourceDir, stagingDir); err != nil {
+		return fmt.Errorf("failed to install tool to staging directory: %w", err)
+	}
+	if err := writeInstallReceipt(stagingDir, receipt); err != nil {
+		return err
+	}
+
+	backupDir := filepath.Join(parentDir, "."+versionName+".backup")
+	if err := os.RemoveAll(backupDir); err != nil {
+		return fmt.Errorf("failed to remove stale backup directory: %w", err)
+	}
+	if err := os.Rename(installDir, backupDir); err != nil {
+		return fmt.Errorf("failed to move existing install aside: %w", err)
+	}
+
+	if err := os.Rename(stagingDir, installDir); err != nil {
+		if errRestore := os.Rename(backupDir, installDir); errRestore != nil {
+			zap.L().Error("Failed to restore previous install after failed reinstall",
+				zap.String("path", installDir),
+				zap.String("backup", backupDir),
+				zap.Error(errRestore))
+		}
+		return fmt.Errorf("failed to move reinstalled tool into place: %w", err)
+	}
+
+	if err := os.RemoveAll(backupDir); err != nil {
+		zap.L().Warn("Failed to remove backup of previous install", zap.String("path", backupDir), zap.Error(err))
+	}
+
+	return nil
+}
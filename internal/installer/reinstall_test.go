@@ -0,0 +1,240 @@
+package installer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dorcha-inc/orla/internal/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// corruptInstall deletes the entrypoint and truncates the manifest of an installed tool
+func corruptInstall(t *testing.T, installDir string) {
+	t.Helper()
+	require.NoError(t, os.Remove(filepath.Join(installDir, "tool.sh")))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(installDir, ToolManifestFileName), []byte("name: repa"), 0644))
+}
+
+// assertValidInstall asserts that installDir holds a valid install of the test tool
+func assertValidInstall(t *testing.T, installDir string) {
+	t.Helper()
+	manifest, err := LoadManifest(installDir)
+	require.NoError(t, err)
+	require.NoError(t, ValidateManifest(manifest, installDir))
+	assert.Equal(t, "repair-tool", manifest.Name)
+	assert.Equal(t, "1.0.0", manifest.Version)
+}
+
+func TestReinstallTool_LocalSourceRestoresCorruptedTool(t *testing.T) {
+	sourceDir := filepath.Join(t.TempDir(), "source")
+	require.NoError(t, writeTestTool(sourceDir, testToolManifest("repair-tool", "1.0.0")))
+	toolsDir := t.TempDir()
+
+	require.NoError(t, InstallLocalTool(sourceDir, toolsDir, nil))
+	installDir := filepath.Join(toolsDir, "repair-tool", "1.0.0")
+
+	receipt, err := LoadInstallReceipt(installDir)
+	require.NoError(t, err)
+	assert.Equal(t, InstallSourceLocal, receipt.Source)
+
+	corruptInstall(t, installDir)
+	corrupted, err := LoadManifest(installDir)
+	require.NoError(t, err)
+	require.Error(t, ValidateManifest(corrupted, installDir), "the corrupted install should not validate")
+
+	require.NoError(t, ReinstallTool("repair-tool", "1.0.0", toolsDir, exampleRegistryURL, NewTextProgress(&bytes.Buffer{})))
+
+	assertValidInstall(t, installDir)
+	receipt, err = LoadInstallReceipt(installDir)
+	require.NoError(t, err)
+	assert.Equal(t, InstallSourceLocal, receipt.Source)
+
+	// No staging or backup directories are left behind
+	entries, err := os.ReadDir(filepath.Join(toolsDir, "repair-tool"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "1.0.0", entries[0].Name())
+}
+
+func TestReinstallTool_RegistrySource(t *testing.T) {
+	toolsDir := t.TempDir()
+	installDir := filepath.Join(toolsDir, "repair-tool", "1.0.0")
+	require.NoError(t, writeTestTool(installDir, testToolManifest("repair-tool", "1.0.0")))
+	require.NoError(t, writeInstallReceipt(installDir, &InstallReceipt{
+		Source:     InstallSourceRegistry,
+		Repository: "https://example.com/repair-tool.git",
+		Tag:        "v1.0.0",
+	}))
+	corruptInstall(t, installDir)
+
+	mockRunner := &registry.MockGitRunner{
+		RunFunc: func(_, dir string, args ...string) ([]byte, error) {
+			if args[0] == "clone" {
+				require.NoError(t, writeTestTool(args[len(args)-1], testToolManifest("repair-tool", "1.0.0")))
+			}
+			return nil, nil
+		},
+	}
+	setGitRunner(t, mockRunner)
+
+	require.NoError(t, ReinstallTool("repair-tool", "1.0.0", toolsDir, exampleRegistryURL, NewTextProgress(&bytes.Buffer{})))
+
+	require.NotEmpty(t, mockRunner.RunArgs())
+	assert.Contains(t, mockRunner.RunArgs()[0], "https://example.com/repair-tool.git")
+	assert.Contains(t, mockRunner.RunArgs()[0], "v1.0.0")
+	assertValidInstall(t, installDir)
+}
+
+// useCachedTestRegistry serves index from the registry cache for exampleRegistryURL for the
+// duration of a test
+func useCachedTestRegistry(t *testing.T, index *registry.RegistryIndex) {
+	t.Helper()
+	cacheDir := t.TempDir()
+	cacheKey, err := registry.SanitizeURLForCache(exampleRegistryURL)
+	require.NoError(t, err)
+	data, err := yaml.Marshal(index)
+	require.NoError(t, err)
+	cachePath := filepath.Join(cacheDir, cacheKey, "registry.yaml")
+	// #nosec G301 -- test directory permissions are acceptable for temporary test files
+	require.NoError(t, os.MkdirAll(filepath.Dir(cachePath), 0755))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(cachePath, data, 0644))
+
+	originalGetCacheDir := *registry.GetRegistryCacheDirFunc
+	*registry.GetRegistryCacheDirFunc = func() (string, error) { return cacheDir, nil }
+	t.Cleanup(func() { *registry.GetRegistryCacheDirFunc = originalGetCacheDir })
+}
+
+// TestReinstallTool_VerifiesChecksum tests that a reinstall from the registry checks the cloned
+// files against the checksum the registry declares for the receipt's tag
+func TestReinstallTool_VerifiesChecksum(t *testing.T) {
+	sourceDir := t.TempDir()
+	require.NoError(t, writeTestTool(sourceDir, testToolManifest("repair-tool", "1.0.0")))
+	checksum, err := TreeChecksum(sourceDir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		checksum string
+		wantErr  bool
+	}{
+		{name: "matching checksum", checksum: checksum},
+		{name: "mismatched checksum", checksum: strings.Repeat("0", 64), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCachedTestRegistry(t, &registry.RegistryIndex{
+				Version:     1,
+				RegistryURL: exampleRegistryURL,
+				Tools: []registry.ToolEntry{{
+					Name:       "repair-tool",
+					Repository: "https://example.com/repair-tool.git",
+					Checksums:  map[string]string{"v1.0.0": tt.checksum},
+				}},
+			})
+
+			toolsDir := t.TempDir()
+			installDir := filepath.Join(toolsDir, "repair-tool", "1.0.0")
+			require.NoError(t, writeTestTool(installDir, testToolManifest("repair-tool", "1.0.0")))
+			require.NoError(t, writeInstallReceipt(installDir, &InstallReceipt{
+				Source:      InstallSourceRegistry,
+				RegistryURL: exampleRegistryURL,
+				Repository:  "https://example.com/repair-tool.git",
+				Tag:         "v1.0.0",
+			}))
+
+			setGitRunner(t, &registry.MockGitRunner{
+				RunFunc: func(_, dir string, args ...string) ([]byte, error) {
+					if args[0] == "clone" {
+						require.NoError(t, writeTestTool(args[len(args)-1], testToolManifest("repair-tool", "1.0.0")))
+					}
+					return nil, nil
+				},
+			})
+
+			err := ReinstallTool("repair-tool", "1.0.0", toolsDir, exampleRegistryURL, NewTextProgress(&bytes.Buffer{}))
+			if tt.wantErr {
+				var mismatchErr *ChecksumMismatchError
+				require.ErrorAs(t, err, &mismatchErr)
+				assert.Equal(t, "v1.0.0", mismatchErr.Tag)
+			} else {
+				require.NoError(t, err)
+			}
+			assertValidInstall(t, installDir)
+		})
+	}
+}
+
+func TestReinstallTool_FailureKeepsExistingInstall(t *testing.T) {
+	toolsDir := t.TempDir()
+	installDir := filepath.Join(toolsDir, "repair-tool", "1.0.0")
+	require.NoError(t, writeTestTool(installDir, testToolManifest("repair-tool", "1.0.0")))
+
+	// The recorded source now provides a different version
+	sourceDir := filepath.Join(t.TempDir(), "source")
+	require.NoError(t, writeTestTool(sourceDir, testToolManifest("repair-tool", "2.0.0")))
+	require.NoError(t, writeInstallReceipt(installDir, &InstallReceipt{Source: InstallSourceLocal, LocalPath: sourceDir}))
+
+	err := ReinstallTool("repair-tool", "1.0.0", toolsDir, exampleRegistryURL, NewTextProgress(&bytes.Buffer{}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "now provides 'repair-tool' version 2.0.0")
+
+	assertValidInstall(t, installDir)
+}
+
+func TestReinstallTool_NotInstalled(t *testing.T) {
+	err := ReinstallTool("missing-tool", "1.0.0", t.TempDir(), exampleRegistryURL, NewTextProgress(&bytes.Buffer{}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not installed")
+}
+
+func TestLoadInstallReceipt_Invalid(t *testing.T) {
+	installDir := t.TempDir()
+
+	_, err := LoadInstallReceipt(installDir)
+	require.Error(t, err)
+
+	require.NoError(t, writeInstallReceipt(installDir, &InstallReceipt{Source: InstallSourceLocal}))
+	_, err = LoadInstallReceipt(installDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing the local path")
+
+	require.NoError(t, writeInstallReceipt(installDir, &InstallReceipt{Source: "ftp"}))
+	_, err = LoadInstallReceipt(installDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown source")
+}
+
+func TestListInstalledVersions(t *testing.T) {
+	toolsDir := t.TempDir()
+	for _, dir := range []string{
+		filepath.Join("tool-a", "1.0.0"),
+		filepath.Join("tool-a", "1.1.0"),
+		filepath.Join("tool-a", ".1.1.0.backup"),
+		filepath.Join("tool-b", "0.1.0"),
+		filepath.Join(".hidden", "1.0.0"),
+	} {
+		// #nosec G301 -- test directory permissions are acceptable for temporary test files
+		require.NoError(t, os.MkdirAll(filepath.Join(toolsDir, dir), 0755))
+	}
+
+	versions, err := ListInstalledVersions(toolsDir)
+	require.NoError(t, err)
+
+	var labels []string
+	for _, version := range versions {
+		labels = append(labels, version.Name+"@"+version.Version)
+	}
+	assert.ElementsMatch(t, []string{"tool-a@1.0.0", "tool-a@1.1.0", "tool-b@0.1.0"}, labels)
+
+	versions, err = ListInstalledVersions(filepath.Join(toolsDir, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+}
@@ -0,0 +1,490 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// AnthropicAPIKeyEnvVar is the environment variable holding the Anthropic API key
+	AnthropicAPIKeyEnvVar = "ANTHROPIC_API_KEY"
+	// AnthropicBaseURLEnvVar is the environment variable that overrides the Anthropic API base URL
+	AnthropicBaseURLEnvVar = "ANTHROPIC_BASE_URL"
+
+	defaultAnthropicBaseURL = "https://api.anthropic.com"
+	defaultAnthropicTimeout = 10 * time.Minute
+	anthropicAPIVersion     = "2023-06-01"
+	anthropicModelsEndpoint = "/v1/models"
+	anthropicChatEndpoint   = "/v1/messages"
+	// anthropicMaxTokens is the response length limit sent with every request, which the
+	// Messages API requires. It must exceed anthropicThinkingBudget.
+	anthropicMaxTokens = 8192
+	// anthropicThinkingBudget is the token budget for extended thinking when show_thinking is enabled
+	anthropicThinkingBudget = 4096
+)
+
+// ErrAnthropicAPIKeyMissing is returned when an Anthropic model is configured but no API key is set
+var ErrAnthropicAPIKeyMissing = fmt.Errorf("%s is not set; export your Anthropic API key to use anthropic models", AnthropicAPIKeyEnvVar)
+
+// AnthropicModelNotFoundError is returned when the Anthropic API does not know the configured model
+type AnthropicModelNotFoundError struct {
+	Model string
+}
+
+// Error returns the error message for the AnthropicModelNotFoundError
+func (e *AnthropicModelNotFoundError) Error() string {
+	return fmt.Sprintf("model '%s' not found on the Anthropic API; check the model name", e.Model)
+}
+
+// Interface guard for AnthropicModelNotFoundError
+var _ error = &AnthropicModelNotFoundError{}
+
+// AnthropicProvider implements the Provider interface for the Anthropic Messages API
+type AnthropicProvider struct {
+	modelName string
+	baseURL   string
+	apiKey    string
+	client    *http.Client
+	cfg       *config.OrlaConfig
+}
+
+// NewAnthropicProvider creates a new Anthropic provider. The API key is read from
+// ANTHROPIC_API_KEY; a missing key is reported by EnsureReady and Chat rather than here.
+func NewAnthropicProvider(modelName string, cfg *config.OrlaConfig) (*AnthropicProvider, error) {
+	baseURL := defaultAnthropicBaseURL
+	if envURL := core.GetEnv(AnthropicBaseURLEnvVar); envURL != "" {
+		baseURL = strings.TrimSuffix(envURL, "/")
+	}
+
+	return &AnthropicProvider{
+		modelName: modelName,
+		baseURL:   baseURL,
+		apiKey:    core.GetEnv(AnthropicAPIKeyEnvVar),
+		client:    &http.Client{Timeout: defaultAnthropicTimeout},
+		cfg:       cfg,
+	}, nil
+}
+
+// SetTimeout sets the timeout for the Anthropic provider
+func (p *AnthropicProvider) SetTimeout(timeout time.Duration) {
+	p.client.Timeout = timeout
+}
+
+// Name returns the provider name
+func (p *AnthropicProvider) Name() string {
+	return "anthropic"
+}
+
+// EnsureReady checks that an API key is set and that the Anthropic API accepts it, by listing models
+func (p *AnthropicProvider) EnsureReady(ctx context.Context) error {
+	if p.apiKey == "" {
+		return ErrAnthropicAPIKeyMissing
+	}
+
+	resp, err := p.get(ctx, anthropicModelsEndpoint)
+	if err != nil {
+		return fmt.Errorf("failed to reach the Anthropic API at %s: %w", p.baseURL, err)
+	}
+	defer core.LogDeferredError(resp.Body.Close)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("the Anthropic API rejected the key in %s (status %d)", AnthropicAPIKeyEnvVar, resp.StatusCode)
+	default:
+		return providerAPIError(p.Name(), resp)
+	}
+}
+
+// CheckModel checks that the configured model is available
+func (p *AnthropicProvider) CheckModel(ctx context.Context) error {
+	resp, err := p.get(ctx, anthropicModelsEndpoint+"/"+url.PathEscape(p.modelName))
+	if err != nil {
+		return fmt.Errorf("failed to look up Anthropic model: %w", err)
+	}
+	defer core.LogDeferredError(resp.Body.Close)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return &AnthropicModelNotFoundError{Model: p.modelName}
+	default:
+		return providerAPIError(p.Name(), resp)
+	}
+}
+
+// get sends an authenticated GET request to the given API endpoint
+func (p *AnthropicProvider) get(ctx context.Context, endpoint string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	p.setHeaders(req)
+	return p.client.Do(req)
+}
+
+// setHeaders sets the authentication and version headers required on every API request
+func (p *AnthropicProvider) setHeaders(req *http.Request) {
+	req.Header.Set("x-api-key", p.apiKey)
+	req.Header.Set("anthropic-version", anthropicAPIVersion)
+}
+
+// Chat sends a request to the Anthropic Messages API
+func (p *AnthropicProvider) Chat(ctx context.Context, messages []Message, tools []*mcp.Tool, stream bool) (*Response, <-chan StreamEvent, error) {
+	// Only check the key here; the auth check in EnsureReady costs a request per turn
+	if p.apiKey == "" {
+		return nil, nil, ErrAnthropicAPIKeyMissing
+	}
+
+	system, anthropicMessages := convertMessagesToAnthropicFormat(messages)
+	reqBody := anthropicChatRequest{
+		Model:     p.modelName,
+		System:    system,
+		Messages:  anthropicMessages,
+		MaxTokens: anthropicMaxTokens,
+		Stream:    stream,
+	}
+	if len(tools) > 0 {
+		reqBody.Tools = convertToolsToAnthropicFormat(tools)
+	}
+
+	// When thinking is enabled, the API requires the assistant turn that made a tool call to
+	// start with its thinking block, which the conversation does not keep. Thinking is therefore
+	// only requested for turns that do not continue from tool results.
+	continuesToolCall := len(messages) > 0 && messages[len(messages)-1].Role == MessageRoleTool
+	if p.cfg != nil {
+		reqBody.StopSequences = p.cfg.Stop
+		if p.cfg.ShowThinking && !continuesToolCall {
+			// Temperature cannot be changed when thinking is enabled
+			reqBody.Thinking = &anthropicThinking{Type: "enabled", BudgetTokens: anthropicThinkingBudget}
+		} else {
+			reqBody.Temperature = p.cfg.ModelTemperature
+		}
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+anthropicChatEndpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	p.setHeaders(req)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer core.LogDeferredError(resp.Body.Close)
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, nil, &AnthropicModelNotFoundError{Model: p.modelName}
+		}
+		return nil, nil, providerAPIError(p.Name(), resp)
+	}
+
+	if stream {
+		response, streamCh := p.handleStreamResponse(resp.Body)
+		return response, streamCh, nil
+	}
+
+	defer core.LogDeferredError(resp.Body.Close)
+
+	var anthropicResp anthropicChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&anthropicResp); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	response := &Response{}
+	for _, block := range anthropicResp.Content {
+		switch block.Type {
+		case anthropicBlockText:
+			response.Content += block.Text
+		case anthropicBlockThinking:
+			response.Thinking += block.Thinking
+		case anthropicBlockToolUse:
+			response.ToolCalls = append(response.ToolCalls, anthropicToolCall(block.ID, block.Name, string(block.Input)))
+		}
+	}
+
+	zap.L().Debug("Anthropic response received",
+		zap.String("content", response.Content),
+		zap.String("stop_reason", anthropicResp.StopReason),
+		zap.Int("tool_calls_count", len(response.ToolCalls)))
+
+	return response, nil, nil
+}
+
+// handleStreamResponse reads a server-sent event stream from the Messages API. Text and thinking
+// deltas are sent as they arrive. A tool_use block's input arrives as JSON fragments, so its tool
+// call is sent once the block is complete. As with the Ollama provider, the response is written
+// by the goroutine and may only be read after the channel is closed.
+func (p *AnthropicProvider) handleStreamResponse(body io.ReadCloser) (*Response, <-chan StreamEvent) {
+	ch := make(chan StreamEvent, defaultStreamBufferSize)
+	response := &Response{
+		Content:   "",
+		Thinking:  "",
+		ToolCalls: []ToolCallWithID{},
+	}
+
+	go func() {
+		defer close(ch)
+		defer core.LogDeferredError(body.Close)
+
+		// Content blocks being streamed, keyed by their index in the message
+		blocks := make(map[int]*anthropicStreamBlock)
+
+		err := readSSEData(body, func(data string) bool {
+			var event anthropicStreamEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				zap.L().Error("Failed to decode stream event", zap.Error(err))
+				return false
+			}
+
+			switch event.Type {
+			case "content_block_start":
+				blocks[event.Index] = &anthropicStreamBlock{
+					blockType: event.ContentBlock.Type,
+					id:        event.ContentBlock.ID,
+					name:      event.ContentBlock.Name,
+				}
+			case "content_block_delta":
+				switch event.Delta.Type {
+				case "text_delta":
+					response.Content += event.Delta.Text
+					ch <- &ContentEvent{Content: event.Delta.Text}
+				case "thinking_delta":
+					response.Thinking += event.Delta.Thinking
+					ch <- &ThinkingEvent{Content: event.Delta.Thinking}
+				case "input_json_delta":
+					if block, ok := blocks[event.Index]; ok {
+						block.input.WriteString(event.Delta.PartialJSON)
+					}
+				}
+			case "content_block_stop":
+				block, ok := blocks[event.Index]
+				delete(blocks, event.Index)
+				if !ok || block.blockType != anthropicBlockToolUse {
+					return true
+				}
+
+				toolCall := anthropicToolCall(block.id, block.name, block.input.String())
+				response.ToolCalls = append(response.ToolCalls, toolCall)
+				args, _ := toolCall.McpCallToolParams.Arguments.(map[string]any)
+				ch <- &ToolCallEvent{
+					Name:      toolCall.McpCallToolParams.Name,
+					Arguments: args,
+				}
+			case "message_stop":
+				return false
+			case "error":
+				zap.L().Error("Anthropic stream error", zap.String("message", event.Error.Message))
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			zap.L().Error("Failed to read stream", zap.Error(err))
+		}
+
+		zap.L().Debug("Parsed tool calls from stream", zap.Int("count", len(response.ToolCalls)))
+	}()
+
+	return response, ch
+}
+
+// anthropicToolCall converts a tool_use block to our format. The input is a JSON object, or
+// empty when a streamed tool takes no input.
+func anthropicToolCall(id, name, input string) ToolCallWithID {
+	if strings.TrimSpace(input) == "" {
+		input = "{}"
+	}
+	return ToolCallWithID{
+		ID: id,
+		McpCallToolParams: mcp.CallToolParams{
+			Name:      name,
+			Arguments: parseToolCallArguments(name, input),
+		},
+	}
+}
+
+// Anthropic content block types
+const (
+	anthropicBlockText       = "text"
+	anthropicBlockThinking   = "thinking"
+	anthropicBlockToolUse    = "tool_use"
+	anthropicBlockToolResult = "tool_result"
+)
+
+// Anthropic-specific types
+type anthropicMessage struct {
+	Role    string                  `json:"role"` // "user" or "assistant"
+	Content []anthropicContentBlock `json:"content"`
+}
+
+type anthropicContentBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	Thinking  string          `json:"thinking,omitempty"`
+	ID        string          `json:"id,omitempty"`          // tool_use
+	Name      string          `json:"name,omitempty"`        // tool_use
+	Input     json.RawMessage `json:"input,omitempty"`       // tool_use
+	ToolUseID string          `json:"tool_use_id,omitempty"` // tool_result
+	Content   string          `json:"content,omitempty"`     // tool_result
+}
+
+type anthropicThinking struct {
+	Type         string `json:"type"` // "enabled"
+	BudgetTokens int    `json:"budget_tokens"`
+}
+
+type anthropicChatRequest struct {
+	Model         string             `json:"model"`
+	System        string             `json:"system,omitempty"`
+	Messages      []anthropicMessage `json:"messages"`
+	MaxTokens     int                `json:"max_tokens"`
+	Stream        bool               `json:"stream,omitempty"`
+	Temperature   *float64           `json:"temperature,omitempty"`
+	Thinking      *anthropicThinking `json:"thinking,omitempty"`
+	Tools         []anthropicTool    `json:"tools,omitempty"`
+	StopSequences []string           `json:"stop_sequences,omitempty"`
+}
+
+type anthropicChatResponse struct {
+	Content    []anthropicContentBlock `json:"content"`
+	StopReason string                  `json:"stop_reason"`
+}
+
+type anthropicStreamEvent struct {
+	Type         string                `json:"type"`
+	Index        int                   `json:"index"`
+	ContentBlock anthropicContentBlock `json:"content_block"` // content_block_start
+	Delta        anthropicStreamDelta  `json:"delta"`         // content_block_delta
+	Error        struct {
+		Message string `json:"message"`
+	} `json:"error"` // error
+}
+
+type anthropicStreamDelta struct {
+	Type        string `json:"type"` // "text_delta", "thinking_delta", "input_json_delta", or "signature_delta"
+	Text        string `json:"text,omitempty"`
+	Thinking    string `json:"thinking,omitempty"`
+	PartialJSON string `json:"partial_json,omitempty"`
+}
+
+// anthropicStreamBlock accumulates a content block while it is streamed
+type anthropicStreamBlock struct {
+	blockType string
+	id        string
+	name      string
+	input     strings.Builder
+}
+
+type anthropicTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+// convertToolsToAnthropicFormat converts mcp.Tool slice to Anthropic format
+func convertToolsToAnthropicFormat(tools []*mcp.Tool) []anthropicTool {
+	anthropicTools := make([]anthropicTool, len(tools))
+	for i, tool := range tools {
+		params := toolParameters(tool)
+		// The API requires an object schema, even for tools without input
+		if len(params) == 0 {
+			params = map[string]any{"type": "object"}
+		}
+
+		anthropicTools[i] = anthropicTool{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: params,
+		}
+	}
+	return anthropicTools
+}
+
+// convertMessagesToAnthropicFormat converts the conversation to Anthropic format, returning the
+// system prompt separately since the API takes it outside the messages. Tool calls become
+// tool_use blocks in the assistant message, and tool results become tool_result blocks in the
+// following user message, with consecutive results merged into one message as the API requires.
+func convertMessagesToAnthropicFormat(messages []Message) (string, []anthropicMessage) {
+	var systemPrompts []string
+	anthropicMessages := make([]anthropicMessage, 0, len(messages))
+
+	// appendBlock adds a block to the last message if it has the same role, otherwise to a new message
+	appendBlock := func(role MessageRole, block anthropicContentBlock) {
+		if n := len(anthropicMessages); n > 0 && anthropicMessages[n-1].Role == string(role) {
+			anthropicMessages[n-1].Content = append(anthropicMessages[n-1].Content, block)
+			return
+		}
+		anthropicMessages = append(anthropicMessages, anthropicMessage{
+			Role:    string(role),
+			Content: []anthropicContentBlock{block},
+		})
+	}
+
+	for _, message := range messages {
+		switch message.Role {
+		case MessageRoleSystem:
+			systemPrompts = append(systemPrompts, message.Content)
+		case MessageRoleAssistant:
+			// The API rejects empty text blocks
+			if message.Content != "" {
+				appendBlock(MessageRoleAssistant, anthropicContentBlock{Type: anthropicBlockText, Text: message.Content})
+			}
+			for _, toolCall := range message.ToolCalls {
+				input, err := json.Marshal(toolCall.McpCallToolParams.Arguments)
+				if err != nil || string(input) == "null" {
+					input = []byte("{}")
+				}
+				appendBlock(MessageRoleAssistant, anthropicContentBlock{
+					Type:  anthropicBlockToolUse,
+					ID:    toolCall.ID,
+					Name:  toolCall.McpCallToolParams.Name,
+					Input: input,
+				})
+			}
+		case MessageRoleTool:
+			if message.ToolCallID != "" {
+				appendBlock(MessageRoleUser, anthropicContentBlock{
+					Type:      anthropicBlockToolResult,
+					ToolUseID: message.ToolCallID,
+					Content:   message.Content,
+				})
+			} else {
+				appendBlock(MessageRoleUser, anthropicContentBlock{Type: anthropicBlockText, Text: untrackedToolResultText(message)})
+			}
+		default:
+			appendBlock(MessageRoleUser, anthropicContentBlock{Type: anthropicBlockText, Text: message.Content})
+		}
+	}
+
+	return strings.Join(systemPrompts, "\n\n"), anthropicMessages
+}
+
+// Interface guards for AnthropicProvider
+var (
+	_ Provider     = &AnthropicProvider{}
+	_ ModelChecker = &AnthropicProvider{}
+)
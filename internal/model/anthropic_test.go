@@ -0,0 +1,340 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAnthropicKey = "sk-ant-test"
+
+// newTestAnthropicProvider returns an AnthropicProvider that talks to the given test server
+func newTestAnthropicProvider(serverURL string, cfg *config.OrlaConfig) *AnthropicProvider {
+	return &AnthropicProvider{
+		modelName: "claude-test",
+		baseURL:   serverURL,
+		apiKey:    testAnthropicKey,
+		client:    &http.Client{Timeout: 5 * time.Second},
+		cfg:       cfg,
+	}
+}
+
+func TestNewAnthropicProvider(t *testing.T) {
+	t.Setenv(AnthropicAPIKeyEnvVar, testAnthropicKey)
+	t.Setenv(AnthropicBaseURLEnvVar, "http://localhost:8000/")
+
+	provider, err := NewAnthropicProvider("claude-test", &config.OrlaConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "anthropic", provider.Name())
+	assert.Equal(t, testAnthropicKey, provider.apiKey)
+	assert.Equal(t, "http://localhost:8000", provider.baseURL)
+}
+
+func TestAnthropicProvider_EnsureReady(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, anthropicModelsEndpoint, r.URL.Path)
+		assert.Equal(t, anthropicAPIVersion, r.Header.Get("anthropic-version"))
+		if r.Header.Get("x-api-key") != testAnthropicKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, err := w.Write([]byte(`{"data": []}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	provider := newTestAnthropicProvider(server.URL, nil)
+	require.NoError(t, provider.EnsureReady(context.Background()))
+
+	provider.apiKey = "sk-ant-wrong"
+	err := provider.EnsureReady(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rejected")
+	assert.Contains(t, err.Error(), AnthropicAPIKeyEnvVar)
+}
+
+func TestAnthropicProvider_EnsureReady_MissingKey(t *testing.T) {
+	provider := newTestAnthropicProvider(testInvalidBaseURL, nil)
+	provider.apiKey = ""
+
+	assert.ErrorIs(t, provider.EnsureReady(context.Background()), ErrAnthropicAPIKeyMissing)
+
+	_, _, err := provider.Chat(context.Background(), nil, nil, false)
+	assert.ErrorIs(t, err, ErrAnthropicAPIKeyMissing)
+}
+
+func TestAnthropicProvider_CheckModel_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	err := newTestAnthropicProvider(server.URL, nil).CheckModel(context.Background())
+	var notFoundErr *AnthropicModelNotFoundError
+	require.ErrorAs(t, err, &notFoundErr)
+	assert.Equal(t, "claude-test", notFoundErr.Model)
+}
+
+func TestAnthropicProvider_Chat_Mock_WithToolUse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, anthropicChatEndpoint, r.URL.Path)
+		assert.Equal(t, testAnthropicKey, r.Header.Get("x-api-key"))
+
+		var reqBody anthropicChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reqBody))
+		assert.Equal(t, "claude-test", reqBody.Model)
+		assert.Equal(t, anthropicMaxTokens, reqBody.MaxTokens)
+		assert.Equal(t, "Be brief", reqBody.System)
+		require.Len(t, reqBody.Tools, 1)
+		assert.Equal(t, "get_temperature", reqBody.Tools[0].Name)
+		assert.Nil(t, reqBody.Thinking)
+		assert.Equal(t, []string{"END"}, reqBody.StopSequences)
+
+		response := `{
+			"content": [
+				{"type": "text", "text": "Checking."},
+				{"type": "tool_use", "id": "toolu_1", "name": "get_temperature", "input": {"city": "Boston"}}
+			],
+			"stop_reason": "tool_use"
+		}`
+		_, err := w.Write([]byte(response))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	provider := newTestAnthropicProvider(server.URL, &config.OrlaConfig{Stop: []string{"END"}})
+	messages := []Message{
+		{Role: MessageRoleSystem, Content: "Be brief"},
+		{Role: MessageRoleUser, Content: "What's the temperature?"},
+	}
+	tools := []*mcp.Tool{{Name: "get_temperature", InputSchema: map[string]any{"type": "object"}}}
+
+	response, streamCh, err := provider.Chat(context.Background(), messages, tools, false)
+	require.NoError(t, err)
+	assert.Nil(t, streamCh)
+	assert.Equal(t, "Checking.", response.Content)
+	require.Len(t, response.ToolCalls, 1)
+	assert.Equal(t, "toolu_1", response.ToolCalls[0].ID)
+	assert.Equal(t, "get_temperature", response.ToolCalls[0].McpCallToolParams.Name)
+	assert.Equal(t, map[string]any{"city": "Boston"}, response.ToolCalls[0].McpCallToolParams.Arguments)
+}
+
+func TestAnthropicProvider_Chat_Thinking(t *testing.T) {
+	temperature := 0.0
+
+	tests := []struct {
+		name            string
+		cfg             *config.OrlaConfig
+		messages        []Message
+		wantThinking    bool
+		wantTemperature bool
+	}{
+		{
+			name:            "thinking disabled",
+			cfg:             &config.OrlaConfig{ModelTemperature: &temperature},
+			messages:        []Message{{Role: MessageRoleUser, Content: "Hi"}},
+			wantTemperature: true,
+		},
+		{
+			name:         "thinking enabled",
+			cfg:          &config.OrlaConfig{ShowThinking: true, ModelTemperature: &temperature},
+			messages:     []Message{{Role: MessageRoleUser, Content: "Hi"}},
+			wantThinking: true,
+		},
+		{
+			name: "thinking enabled, continuing from tool results",
+			cfg:  &config.OrlaConfig{ShowThinking: true},
+			messages: []Message{
+				{Role: MessageRoleUser, Content: "Hi"},
+				{Role: MessageRoleAssistant, ToolCalls: []ToolCallWithID{{ID: "toolu_1", McpCallToolParams: mcp.CallToolParams{Name: "ls"}}}},
+				{Role: MessageRoleTool, ToolName: "ls", ToolCallID: "toolu_1", Content: "a.txt"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reqBody map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&reqBody))
+				_, err := w.Write([]byte(`{"content": [{"type": "thinking", "thinking": "Hmm."}, {"type": "text", "text": "Hello"}]}`))
+				require.NoError(t, err)
+			}))
+			defer server.Close()
+
+			response, _, err := newTestAnthropicProvider(server.URL, tt.cfg).Chat(context.Background(), tt.messages, nil, false)
+			require.NoError(t, err)
+			assert.Equal(t, "Hmm.", response.Thinking)
+
+			if tt.wantThinking {
+				assert.Equal(t, map[string]any{"type": "enabled", "budget_tokens": float64(anthropicThinkingBudget)}, reqBody["thinking"])
+			} else {
+				assert.NotContains(t, reqBody, "thinking")
+			}
+			// Temperature cannot be set together with thinking
+			if tt.wantTemperature {
+				assert.Equal(t, temperature, reqBody["temperature"])
+			} else {
+				assert.NotContains(t, reqBody, "temperature")
+			}
+		})
+	}
+}
+
+func TestAnthropicProvider_Chat_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte(`{"type": "error", "error": {"type": "invalid_request_error", "message": "max_tokens: too large"}}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	_, _, err := newTestAnthropicProvider(server.URL, nil).Chat(context.Background(), []Message{{Role: MessageRoleUser, Content: "Hi"}}, nil, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "anthropic API error: 400 - max_tokens: too large")
+}
+
+func TestAnthropicProvider_Chat_Stream_Mock(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody anthropicChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reqBody))
+		assert.True(t, reqBody.Stream)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			t.Fatal("expected http.ResponseWriter to be an http.Flusher")
+		}
+
+		events := []string{
+			"event: message_start\ndata: {\"type\": \"message_start\", \"message\": {\"role\": \"assistant\"}}",
+			"event: content_block_start\ndata: {\"type\": \"content_block_start\", \"index\": 0, \"content_block\": {\"type\": \"thinking\", \"thinking\": \"\"}}",
+			"event: content_block_delta\ndata: {\"type\": \"content_block_delta\", \"index\": 0, \"delta\": {\"type\": \"thinking_delta\", \"thinking\": \"Let me look.\"}}",
+			"event: content_block_delta\ndata: {\"type\": \"content_block_delta\", \"index\": 0, \"delta\": {\"type\": \"signature_delta\", \"signature\": \"abc\"}}",
+			"event: content_block_stop\ndata: {\"type\": \"content_block_stop\", \"index\": 0}",
+			"event: content_block_start\ndata: {\"type\": \"content_block_start\", \"index\": 1, \"content_block\": {\"type\": \"text\", \"text\": \"\"}}",
+			"event: content_block_delta\ndata: {\"type\": \"content_block_delta\", \"index\": 1, \"delta\": {\"type\": \"text_delta\", \"text\": \"Hello, \"}}",
+			"event: ping\ndata: {\"type\": \"ping\"}",
+			"event: content_block_delta\ndata: {\"type\": \"content_block_delta\", \"index\": 1, \"delta\": {\"type\": \"text_delta\", \"text\": \"world!\"}}",
+			"event: content_block_stop\ndata: {\"type\": \"content_block_stop\", \"index\": 1}",
+			"event: content_block_start\ndata: {\"type\": \"content_block_start\", \"index\": 2, \"content_block\": {\"type\": \"tool_use\", \"id\": \"toolu_1\", \"name\": \"ls\", \"input\": {}}}",
+			"event: content_block_delta\ndata: {\"type\": \"content_block_delta\", \"index\": 2, \"delta\": {\"type\": \"input_json_delta\", \"partial_json\": \"{\\\"path\\\":\"}}",
+			"event: content_block_delta\ndata: {\"type\": \"content_block_delta\", \"index\": 2, \"delta\": {\"type\": \"input_json_delta\", \"partial_json\": \" \\\"/tmp\\\"}\"}}",
+			"event: content_block_stop\ndata: {\"type\": \"content_block_stop\", \"index\": 2}",
+			"event: content_block_start\ndata: {\"type\": \"content_block_start\", \"index\": 3, \"content_block\": {\"type\": \"tool_use\", \"id\": \"toolu_2\", \"name\": \"pwd\", \"input\": {}}}",
+			"event: content_block_stop\ndata: {\"type\": \"content_block_stop\", \"index\": 3}",
+			"event: message_delta\ndata: {\"type\": \"message_delta\", \"delta\": {\"stop_reason\": \"tool_use\"}}",
+			"event: message_stop\ndata: {\"type\": \"message_stop\"}",
+		}
+		for _, event := range events {
+			_, err := w.Write([]byte(event + "\n\n"))
+			require.NoError(t, err)
+			flusher.Flush()
+		}
+	}))
+	defer server.Close()
+
+	provider := newTestAnthropicProvider(server.URL, &config.OrlaConfig{ShowThinking: true})
+	response, streamCh, err := provider.Chat(context.Background(), []Message{{Role: MessageRoleUser, Content: "List /tmp"}}, nil, true)
+	require.NoError(t, err)
+	require.NotNil(t, streamCh)
+
+	var content, thinking string
+	var toolCallEvents []*ToolCallEvent
+	for event := range streamCh {
+		switch e := event.(type) {
+		case *ContentEvent:
+			content += e.Content
+		case *ThinkingEvent:
+			thinking += e.Content
+		case *ToolCallEvent:
+			toolCallEvents = append(toolCallEvents, e)
+		}
+	}
+
+	assert.Equal(t, "Hello, world!", content)
+	assert.Equal(t, "Let me look.", thinking)
+
+	// One event per complete tool_use block, with its full input
+	require.Len(t, toolCallEvents, 2)
+	assert.Equal(t, "ls", toolCallEvents[0].Name)
+	assert.Equal(t, map[string]any{"path": "/tmp"}, toolCallEvents[0].Arguments)
+	assert.Equal(t, "pwd", toolCallEvents[1].Name)
+	assert.Equal(t, map[string]any{}, toolCallEvents[1].Arguments)
+
+	assert.Equal(t, "Hello, world!", response.Content)
+	assert.Equal(t, "Let me look.", response.Thinking)
+	require.Len(t, response.ToolCalls, 2)
+	assert.Equal(t, "toolu_1", response.ToolCalls[0].ID)
+	assert.Equal(t, "toolu_2", response.ToolCalls[1].ID)
+}
+
+func TestConvertToolsToAnthropicFormat(t *testing.T) {
+	schema := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"path": map[string]any{"type": "string"}},
+	}
+	tools := []*mcp.Tool{
+		{Name: "read_file", Description: "Read a file", InputSchema: schema},
+		{Name: "no_schema"},
+	}
+
+	anthropicTools := convertToolsToAnthropicFormat(tools)
+	require.Len(t, anthropicTools, 2)
+	assert.Equal(t, "read_file", anthropicTools[0].Name)
+	assert.Equal(t, "Read a file", anthropicTools[0].Description)
+	assert.Equal(t, schema, anthropicTools[0].InputSchema)
+	assert.Equal(t, map[string]any{"type": "object"}, anthropicTools[1].InputSchema)
+}
+
+func TestConvertMessagesToAnthropicFormat(t *testing.T) {
+	messages := []Message{
+		{Role: MessageRoleSystem, Content: "Be brief"},
+		{Role: MessageRoleUser, Content: "List /tmp and /var"},
+		{Role: MessageRoleAssistant, ToolCalls: []ToolCallWithID{
+			{ID: "toolu_1", McpCallToolParams: mcp.CallToolParams{Name: "ls", Arguments: map[string]any{"path": "/tmp"}}},
+			{ID: "toolu_2", McpCallToolParams: mcp.CallToolParams{Name: "ls", Arguments: map[string]any{"path": "/var"}}},
+		}},
+		{Role: MessageRoleTool, ToolName: "ls", ToolCallID: "toolu_1", Content: "a.txt"},
+		{Role: MessageRoleTool, ToolName: "ls", ToolCallID: "toolu_2", Content: "b.txt"},
+		{Role: MessageRoleAssistant, Content: "Found a.txt and b.txt"},
+		{Role: MessageRoleTool, ToolName: "ls", Content: "c.txt"},
+	}
+
+	system, anthropicMessages := convertMessagesToAnthropicFormat(messages)
+	assert.Equal(t, "Be brief", system)
+	require.Len(t, anthropicMessages, 5)
+
+	assert.Equal(t, "user", anthropicMessages[0].Role)
+	assert.Equal(t, []anthropicContentBlock{{Type: "text", Text: "List /tmp and /var"}}, anthropicMessages[0].Content)
+
+	// The assistant turn has no text, only tool_use blocks
+	assert.Equal(t, "assistant", anthropicMessages[1].Role)
+	require.Len(t, anthropicMessages[1].Content, 2)
+	assert.Equal(t, anthropicBlockToolUse, anthropicMessages[1].Content[0].Type)
+	assert.Equal(t, "toolu_1", anthropicMessages[1].Content[0].ID)
+	assert.JSONEq(t, `{"path": "/tmp"}`, string(anthropicMessages[1].Content[0].Input))
+
+	// Consecutive tool results are merged into one user message
+	assert.Equal(t, "user", anthropicMessages[2].Role)
+	assert.Equal(t, []anthropicContentBlock{
+		{Type: anthropicBlockToolResult, ToolUseID: "toolu_1", Content: "a.txt"},
+		{Type: anthropicBlockToolResult, ToolUseID: "toolu_2", Content: "b.txt"},
+	}, anthropicMessages[2].Content)
+
+	assert.Equal(t, "assistant", anthropicMessages[3].Role)
+
+	// Tool results without a call ID are sent as text
+	assert.Equal(t, "user", anthropicMessages[4].Role)
+	require.Len(t, anthropicMessages[4].Content, 1)
+	assert.Equal(t, "text", anthropicMessages[4].Content[0].Type)
+	assert.Contains(t, anthropicMessages[4].Content[0].Text, "c.txt")
+}
@@ -10,6 +10,8 @@ import (
 	"net"
 	"net/http"
 	"os/exec"
+	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -29,14 +31,33 @@ const (
 	defaultStreamBufferSize   = 255
 	ollamaHealthCheckEndpoint = "/api/tags"
 	ollamaChatEndpoint        = "/api/chat"
+	ollamaPullEndpoint        = "/api/pull"
 )
 
+// OllamaModelNotFoundError is returned when Ollama is running but the configured model has not been pulled
+type OllamaModelNotFoundError struct {
+	Model string
+}
+
+// Error returns the error message for the OllamaModelNotFoundError, including how to fix it
+func (e *OllamaModelNotFoundError) Error() string {
+	return fmt.Sprintf("model '%s' not found on Ollama; run `ollama pull %s` or enable auto_pull_model", e.Model, e.Model)
+}
+
+// Interface guard for OllamaModelNotFoundError
+var _ error = &OllamaModelNotFoundError{}
+
 // OllamaProvider implements the Provider interface for Ollama
 type OllamaProvider struct {
 	modelName string
 	baseURL   string
 	client    *http.Client
 	cfg       *config.OrlaConfig
+
+	// readyMu guards ready, which is set once EnsureReady has succeeded so
+	// later chats skip the health and model checks
+	readyMu sync.Mutex
+	ready   bool
 }
 
 // NewOllamaProvider creates a new Ollama provider
@@ -65,24 +86,171 @@ func (p *OllamaProvider) Name() string {
 }
 
 // EnsureReady ensures Ollama is running and ready
-// It checks if Ollama is running via HTTP health check.
-// If Ollama is not running, it returns an error with instructions to start it manually.
+// It checks if Ollama is running via HTTP health check and, when auto_pull_model is
+// enabled, pulls the configured model once Ollama is up. If Ollama is not running, it
+// returns an error with instructions to start it manually. The checks only run until
+// the first success; later calls return immediately.
 func (p *OllamaProvider) EnsureReady(ctx context.Context) error {
+	p.readyMu.Lock()
+	defer p.readyMu.Unlock()
+
+	if p.ready {
+		return nil
+	}
+
+	if err := p.ensureRunning(); err != nil {
+		return err
+	}
+
+	if p.cfg != nil && p.cfg.AutoPullModel {
+		if err := p.ensureModelPulled(ctx); err != nil {
+			return err
+		}
+	}
+
+	p.ready = true
+	return nil
+}
+
+// ensureRunning returns nil once Ollama answers its health check
+func (p *OllamaProvider) ensureRunning() error {
 	running, err := p.isRunning()
 	if err != nil {
 		if errors.Is(err, ErrOllamaNotInstalled) {
-			return fmt.Errorf("ollama is not installed. Please install Ollama: https://ollama.ai")
+			return fmt.Errorf("%w. %s", ErrOllamaNotInstalled, OllamaInstallHint)
 		}
 		return fmt.Errorf("failed to check if Ollama is running: %w", err)
 	}
 
-	if running {
-		zap.L().Debug("Ollama is already running")
-		return nil
+	if !running {
+		// Ollama is not running - provide helpful error message
+		return fmt.Errorf("%w. %s", ErrOllamaNotRunning, OllamaStartHint)
+	}
+
+	zap.L().Debug("Ollama is running")
+	return nil
+}
+
+// CheckModel checks that the configured model has been pulled into Ollama
+func (p *OllamaProvider) CheckModel(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s%s", p.baseURL, ollamaHealthCheckEndpoint), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to list Ollama models: %w", err)
+	}
+	defer core.LogDeferredError(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to list Ollama models: status %d", resp.StatusCode)
+	}
+
+	var tags ollamaTagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return fmt.Errorf("failed to decode Ollama models: %w", err)
+	}
+
+	for _, m := range tags.Models {
+		// Ollama reports untagged models with an explicit ":latest" suffix
+		if m.Name == p.modelName || m.Name == p.modelName+":latest" {
+			return nil
+		}
+	}
+
+	return &OllamaModelNotFoundError{Model: p.modelName}
+}
+
+// ensureModelPulled pulls the configured model if it is not already available
+func (p *OllamaProvider) ensureModelPulled(ctx context.Context) error {
+	err := p.CheckModel(ctx)
+	var notFoundErr *OllamaModelNotFoundError
+	if !errors.As(err, &notFoundErr) {
+		return err
+	}
+	return p.pullModel(ctx)
+}
+
+// pullModel asks Ollama to pull the configured model and waits for the pull to complete.
+// The pull is streamed so progress can be logged, and it is bounded only by ctx: large
+// models can take longer than the chat request timeout to download.
+func (p *OllamaProvider) pullModel(ctx context.Context) error {
+	zap.L().Info("Pulling Ollama model", zap.String("model", p.modelName))
+
+	jsonData, err := json.Marshal(ollamaPullRequest{Model: p.modelName, Stream: true})
+	if err != nil {
+		return fmt.Errorf("failed to marshal pull request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s%s", p.baseURL, ollamaPullEndpoint), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
 	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Transport: p.client.Transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to pull model '%s': %w", p.modelName, err)
+	}
+	defer core.LogDeferredError(resp.Body.Close)
 
-	// Ollama is not running - provide helpful error message
-	return fmt.Errorf("ollama is not running. Please start Ollama manually:\n  - macOS: brew services start ollama\n  - Linux: systemctl --user start ollama\n  - Or run: ollama serve")
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to pull model '%s': ollama API error: %d - %s", p.modelName, resp.StatusCode, string(body))
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	lastStatus := ""
+	for {
+		var progress ollamaPullProgress
+		if err := decoder.Decode(&progress); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("failed to read pull progress for model '%s': %w", p.modelName, err)
+		}
+
+		if progress.Error != "" {
+			return fmt.Errorf("failed to pull model '%s': %s", p.modelName, progress.Error)
+		}
+
+		fields := []zap.Field{zap.String("model", p.modelName), zap.String("status", progress.Status)}
+		if progress.Total > 0 {
+			fields = append(fields, zap.Int64("completed", progress.Completed), zap.Int64("total", progress.Total))
+		}
+		// Layer downloads repeat the same status with growing byte counts; only log
+		// status changes at info level
+		if progress.Status != lastStatus {
+			zap.L().Info("Pulling Ollama model", fields...)
+			lastStatus = progress.Status
+		} else {
+			zap.L().Debug("Pulling Ollama model", fields...)
+		}
+	}
+
+	if lastStatus != "success" {
+		return fmt.Errorf("failed to pull model '%s': pull ended before completing", p.modelName)
+	}
+
+	zap.L().Info("Pulled Ollama model", zap.String("model", p.modelName))
+	return nil
+}
+
+// isModelNotFoundResponse reports whether an Ollama API error response says the requested model does not exist.
+// Ollama responds with 404 and a body like {"error":"model \"llama3\" not found, try pulling it first"}.
+func isModelNotFoundResponse(statusCode int, body []byte) bool {
+	if statusCode != http.StatusNotFound {
+		return false
+	}
+
+	var errResp ollamaErrorResponse
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		return false
+	}
+	return strings.Contains(errResp.Error, "model") && strings.Contains(errResp.Error, "not found")
 }
 
 // Chat sends a chat request to Ollama
@@ -93,17 +261,23 @@ func (p *OllamaProvider) Chat(ctx context.Context, messages []Message, tools []*
 	}
 
 	// Convert messages to Ollama format
-	ollamaMessages := make([]ollamaMessage, len(messages))
+	ollamaMessages := make([]ollamaMessage, 0, len(messages))
 	for i := range messages {
+		// Ollama matches tool results to calls by tool_name, so an assistant turn that only
+		// requested tool calls carries nothing it needs
+		if messages[i].Role == MessageRoleAssistant && messages[i].Content == "" && len(messages[i].ToolCalls) > 0 {
+			continue
+		}
 		msg := ollamaMessage{
-			Role:    string(messages[i].Role),
-			Content: messages[i].Content,
+			Role:     string(messages[i].Role),
+			Content:  messages[i].Content,
+			Thinking: messages[i].Thinking,
 		}
 		// Add tool_name if this is a tool message
 		if messages[i].Role == MessageRoleTool && messages[i].ToolName != "" {
 			msg.ToolName = messages[i].ToolName
 		}
-		ollamaMessages[i] = msg
+		ollamaMessages = append(ollamaMessages, msg)
 	}
 
 	// Build request
@@ -116,17 +290,19 @@ func (p *OllamaProvider) Chat(ctx context.Context, messages []Message, tools []*
 		Model:    p.modelName,
 		Messages: ollamaMessages,
 		Stream:   stream,
-		Options: ollamaOptions{
-			Temperature: defaultOllamaTemperature,
-		},
-		Think: thinkEnabled,
+		Options:  p.requestOptions(),
+		Think:    thinkEnabled,
 	}
 
 	// Add tools if provided (Ollama supports tool calling natively)
 	if len(tools) > 0 {
 		reqBody.Tools = convertToolsToOllamaFormat(tools)
-		// Don't set Format=json when using tools - tools enable tool calling automatically
-		// Format=json is only for structured outputs without tools
+	}
+
+	// Format is only set when configured: it constrains the response to JSON, which tools
+	// do not need in order to be called
+	if p.cfg != nil {
+		reqBody.Format = ollamaFormat(p.cfg.ModelFormat)
 	}
 
 	jsonData, err := json.Marshal(reqBody)
@@ -134,16 +310,73 @@ func (p *OllamaProvider) Chat(ctx context.Context, messages []Message, tools []*
 		return nil, nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	maxRetries, delay := 0, time.Duration(0)
+	if p.cfg != nil {
+		maxRetries = max(p.cfg.ModelMaxRetries, 0)
+		delay = time.Duration(max(p.cfg.ModelRetryBackoffMs, 0)) * time.Millisecond
+	}
+
+	for attempt := 1; ; attempt++ {
+		response, streamCh, transient, err := p.chatAttempt(ctx, jsonData, stream, len(tools) > 0)
+		if err == nil {
+			return response, streamCh, nil
+		}
+		if !transient || attempt > maxRetries {
+			return nil, nil, chatAttemptsError(err, attempt)
+		}
+
+		zap.L().Warn("Retrying Ollama chat request after transient failure",
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", delay),
+			zap.Error(err))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, nil, chatAttemptsError(fmt.Errorf("%w (retry canceled: %w)", err, ctx.Err()), attempt)
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// chatAttemptsError adds the number of attempts made to the error of a chat request that was retried
+func chatAttemptsError(err error, attempts int) error {
+	if attempts <= 1 {
+		return err
+	}
+	return fmt.Errorf("ollama chat request failed after %d attempts: %w", attempts, err)
+}
+
+// isTransientOllamaStatus reports whether an Ollama API status code is a server-side failure
+// that may succeed if the request is sent again
+func isTransientOllamaStatus(statusCode int) bool {
+	return statusCode >= http.StatusInternalServerError
+}
+
+// isJSONDecodeError reports whether err is a malformed JSON error rather than a failure to read
+func isJSONDecodeError(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr)
+}
+
+// chatAttempt sends one chat request and reports whether a failure is transient, i.e. the request
+// can safely be sent again. A streaming request is only retried if it failed before the response
+// started streaming; once events may have been delivered, failures end the stream instead.
+func (p *OllamaProvider) chatAttempt(ctx context.Context, jsonData []byte, stream bool, hasTools bool) (*Response, <-chan StreamEvent, bool, error) {
 	url := fmt.Sprintf("%s%s", p.baseURL, ollamaChatEndpoint)
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonData))
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, false, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to send request: %w", err)
+		// A dropped connection is transient, but not the caller giving up
+		return nil, nil, ctx.Err() == nil, fmt.Errorf("failed to send request: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -152,25 +385,30 @@ func (p *OllamaProvider) Chat(ctx context.Context, messages []Message, tools []*
 		// Note(jadidbourbaki): a bit of a misnomer using this function here.
 		core.LogDeferredError(resp.Body.Close)
 
+		transient := isTransientOllamaStatus(resp.StatusCode)
 		if readErr != nil {
-			return nil, nil, fmt.Errorf("ollama API error: %d (failed to read response body: %w)", resp.StatusCode, readErr)
+			return nil, nil, transient, fmt.Errorf("ollama API error: %d (failed to read response body: %w)", resp.StatusCode, readErr)
+		}
+		if isModelNotFoundResponse(resp.StatusCode, body) {
+			return nil, nil, false, &OllamaModelNotFoundError{Model: p.modelName}
 		}
-		return nil, nil, fmt.Errorf("ollama API error: %d - %s", resp.StatusCode, string(body))
+		return nil, nil, transient, fmt.Errorf("ollama API error: %d - %s", resp.StatusCode, string(body))
 	}
 
 	if stream {
 		// For streaming, accumulate response while streaming content
 		response, streamCh := p.handleStreamResponse(resp.Body)
-		return response, streamCh, nil
+		return response, streamCh, false, nil
 	}
 
 	// For non-streaming, close the body when done
 	defer core.LogDeferredError(resp.Body.Close)
 
-	// Handle non-streaming response
+	// Handle non-streaming response. The request is idempotent, so a connection dropped while
+	// reading the response is transient, unlike a response that is not valid JSON.
 	var ollamaResp ollamaChatResponse
 	if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
-		return nil, nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, nil, ctx.Err() == nil && !isJSONDecodeError(err), fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	zap.L().Debug("Ollama response received",
@@ -187,17 +425,26 @@ func (p *OllamaProvider) Chat(ctx context.Context, messages []Message, tools []*
 	if len(ollamaResp.Message.ToolCalls) > 0 {
 		response.ToolCalls = convertOllamaToolCalls(ollamaResp.Message.ToolCalls)
 		zap.L().Debug("Parsed tool calls", zap.Int("count", len(response.ToolCalls)))
-	} else if len(tools) > 0 && ollamaResp.Message.Content != "" {
+	} else if hasTools && ollamaResp.Message.Content != "" {
 		// If tools were provided but tool_calls is empty, check if content contains tool call JSON
 		// Some models return tool calls as JSON in content when Format=json
 		zap.L().Debug("No tool_calls field but tools were provided, content may contain tool call JSON")
 	}
 
-	return response, nil, nil
+	return response, nil, false, nil
 }
 
 var ErrOllamaNotInstalled = errors.New("ollama is not installed")
 
+// ErrOllamaNotRunning is returned by EnsureReady when Ollama is installed but not running
+var ErrOllamaNotRunning = errors.New("ollama is not running")
+
+// OllamaInstallHint tells the user how to install Ollama
+const OllamaInstallHint = "Please install Ollama: https://ollama.ai"
+
+// OllamaStartHint tells the user how to start Ollama
+const OllamaStartHint = "Please start Ollama manually:\n  - macOS: brew services start ollama\n  - Linux: systemctl --user start ollama\n  - Or run: ollama serve"
+
 // isRunning checks if Ollama is running
 func (p *OllamaProvider) isRunning() (bool, error) {
 	// First check if ollama command exists
@@ -387,15 +634,45 @@ func (p *OllamaProvider) handleStreamResponse(body io.ReadCloser) (*Response, <-
 	return response, ch
 }
 
+// requestOptions returns the sampling options for chat requests, applying the configured
+// temperature, seed, and stop sequences over the provider defaults
+func (p *OllamaProvider) requestOptions() ollamaOptions {
+	options := ollamaOptions{Temperature: defaultOllamaTemperature}
+	if p.cfg != nil {
+		if p.cfg.ModelTemperature != nil {
+			options.Temperature = *p.cfg.ModelTemperature
+		}
+		options.Seed = p.cfg.ModelSeed
+		options.Stop = p.cfg.Stop
+	}
+	return options
+}
+
+// ollamaFormat returns the request format for a model_format value: the string "json", or the
+// JSON schema passed through as-is. It returns nil, omitting the field, when no format is set.
+func ollamaFormat(modelFormat string) json.RawMessage {
+	switch modelFormat {
+	case "":
+		return nil
+	case config.ModelFormatJSON:
+		return json.RawMessage(`"json"`)
+	default:
+		return json.RawMessage(modelFormat)
+	}
+}
+
 // Ollama-specific types
 type ollamaMessage struct {
 	Role     string `json:"role"`
 	Content  string `json:"content"`
+	Thinking string `json:"thinking,omitempty"`  // Thinking trace of an earlier assistant turn
 	ToolName string `json:"tool_name,omitempty"` // Required when role is "tool"
 }
 
 type ollamaOptions struct {
-	Temperature float64 `json:"temperature,omitempty"`
+	Temperature float64  `json:"temperature"`
+	Seed        *int     `json:"seed,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
 }
 
 type ollamaChatRequest struct {
@@ -404,8 +681,8 @@ type ollamaChatRequest struct {
 	Stream   bool            `json:"stream"`
 	Options  ollamaOptions   `json:"options,omitempty"`
 	Tools    []ollamaTool    `json:"tools,omitempty"`
-	Format   string          `json:"format,omitempty"`
-	Think    bool            `json:"think,omitempty"` // Enable thinking trace
+	Format   json.RawMessage `json:"format,omitempty"` // "json" or a JSON schema
+	Think    bool            `json:"think,omitempty"`  // Enable thinking trace
 }
 
 type ollamaChatResponse struct {
@@ -420,6 +697,30 @@ type ollamaResponseMessage struct {
 	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
 }
 
+type ollamaErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type ollamaPullRequest struct {
+	Model  string `json:"model"`
+	Stream bool   `json:"stream"`
+}
+
+type ollamaPullProgress struct {
+	Status    string `json:"status"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type ollamaTagsResponse struct {
+	Models []ollamaModelTag `json:"models"`
+}
+
+type ollamaModelTag struct {
+	Name string `json:"name"`
+}
+
 type ollamaTool struct {
 	Type     string             `json:"type"`
 	Function ollamaToolFunction `json:"function"`
@@ -446,68 +747,75 @@ type ollamaToolCallFunction struct {
 func convertToolsToOllamaFormat(tools []*mcp.Tool) []ollamaTool {
 	ollamaTools := make([]ollamaTool, len(tools))
 	for i, tool := range tools {
-		// Convert InputSchema from any to map[string]any
-		var params map[string]any
-		if tool.InputSchema != nil {
-			if schemaMap, ok := tool.InputSchema.(map[string]any); ok {
-				params = schemaMap
-			} else {
-				// If it's not a map, try to convert it
-				params = make(map[string]any)
-				zap.L().Warn("Tool InputSchema is not a map[string]any, using empty schema", zap.String("tool", tool.Name))
-			}
-		} else {
-			params = make(map[string]any)
-		}
-
 		ollamaTools[i] = ollamaTool{
 			Type: "function",
 			Function: ollamaToolFunction{
 				Name:        tool.Name,
 				Description: tool.Description,
-				Parameters:  params,
+				Parameters:  toolParameters(tool),
 			},
 		}
 	}
 	return ollamaTools
 }
 
-// convertOllamaToolCalls converts Ollama tool calls to our format
-func convertOllamaToolCalls(ollamaCalls []ollamaToolCall) []ToolCallWithID {
-	toolCalls := make([]ToolCallWithID, len(ollamaCalls))
-	for i, call := range ollamaCalls {
-		var args map[string]any
-
-		// Handle arguments - can be object or JSON string
-		switch v := call.Function.Arguments.(type) {
-		case map[string]any:
-			// Already an object
-			args = v
-		case string:
-			// JSON string, unmarshal it
-			if err := json.Unmarshal([]byte(v), &args); err != nil {
+// toolParameters returns the tool's InputSchema as a JSON schema map for function calling
+func toolParameters(tool *mcp.Tool) map[string]any {
+	if tool.InputSchema == nil {
+		return make(map[string]any)
+	}
+	schemaMap, ok := tool.InputSchema.(map[string]any)
+	if !ok {
+		zap.L().Warn("Tool InputSchema is not a map[string]any, using empty schema", zap.String("tool", tool.Name))
+		return make(map[string]any)
+	}
+	return schemaMap
+}
+
+// parseToolCallArguments converts tool call arguments returned by a model, either an object or
+// a JSON string, to a map. Arguments that cannot be parsed are logged and replaced with an
+// empty map, so the tool still runs and can report the missing input.
+func parseToolCallArguments(toolName string, arguments any) map[string]any {
+	var args map[string]any
+
+	switch v := arguments.(type) {
+	case map[string]any:
+		// Already an object
+		args = v
+	case string:
+		// JSON string, unmarshal it
+		if err := json.Unmarshal([]byte(v), &args); err != nil {
+			zap.L().Warn("Failed to parse tool call arguments",
+				zap.String("tool", toolName),
+				zap.Error(err))
+			args = make(map[string]any)
+		}
+	default:
+		// Try to marshal/unmarshal as fallback
+		jsonBytes, err := json.Marshal(v)
+		if err == nil {
+			if err := json.Unmarshal(jsonBytes, &args); err != nil {
 				zap.L().Warn("Failed to parse tool call arguments",
-					zap.String("tool", call.Function.Name),
-					zap.Error(err))
-				args = make(map[string]any)
-			}
-		default:
-			// Try to marshal/unmarshal as fallback
-			jsonBytes, err := json.Marshal(v)
-			if err == nil {
-				if err := json.Unmarshal(jsonBytes, &args); err != nil {
-					zap.L().Warn("Failed to parse tool call arguments",
-						zap.String("tool", call.Function.Name),
-						zap.Error(err))
-					args = make(map[string]any)
-				}
-			} else {
-				zap.L().Warn("Failed to marshal tool call arguments",
-					zap.String("tool", call.Function.Name),
+					zap.String("tool", toolName),
 					zap.Error(err))
 				args = make(map[string]any)
 			}
+		} else {
+			zap.L().Warn("Failed to marshal tool call arguments",
+				zap.String("tool", toolName),
+				zap.Error(err))
+			args = make(map[string]any)
 		}
+	}
+
+	return args
+}
+
+// convertOllamaToolCalls converts Ollama tool calls to our format
+func convertOllamaToolCalls(ollamaCalls []ollamaToolCall) []ToolCallWithID {
+	toolCalls := make([]ToolCallWithID, len(ollamaCalls))
+	for i, call := range ollamaCalls {
+		args := parseToolCallArguments(call.Function.Name, call.Function.Arguments)
 
 		// Use index if provided, otherwise use position
 		id := fmt.Sprintf("call_%d", i)
@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -763,6 +765,119 @@ func TestOllamaProvider_Chat_ThinkEnabled_Mock(t *testing.T) {
 	assert.Equal(t, "I am thinking.", response.Thinking)
 }
 
+func TestOllamaProvider_Chat_SamplingOptions_Mock(t *testing.T) {
+	tests := []struct {
+		name            string
+		cfg             *config.OrlaConfig
+		wantTemperature float64
+		wantSeed        *int
+		wantStop        []any
+	}{
+		{name: "defaults", cfg: &config.OrlaConfig{}, wantTemperature: defaultOllamaTemperature},
+		{name: "zero temperature", cfg: &config.OrlaConfig{ModelTemperature: float64Ptr(0)}, wantTemperature: 0},
+		{name: "fixed seed", cfg: &config.OrlaConfig{ModelSeed: intPtr(42)}, wantTemperature: defaultOllamaTemperature, wantSeed: intPtr(42)},
+		{name: "stop sequences", cfg: &config.OrlaConfig{Stop: []string{"\nObservation:", "END"}}, wantTemperature: defaultOllamaTemperature, wantStop: []any{"\nObservation:", "END"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reqBody map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == ollamaHealthCheckEndpoint {
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&reqBody))
+				w.Header().Set("Content-Type", "application/json")
+				_, err := w.Write([]byte(`{"message": {"role": "assistant", "content": "Hello"}, "done": true}`))
+				require.NoError(t, err)
+			}))
+			defer server.Close()
+
+			provider := &OllamaProvider{
+				modelName: orlaTesting.GetTestModelName(),
+				baseURL:   server.URL,
+				client:    &http.Client{Timeout: 5 * time.Second},
+				cfg:       tt.cfg,
+			}
+
+			_, _, err := provider.Chat(context.Background(), []Message{{Role: MessageRoleUser, Content: "Hello"}}, nil, false)
+			require.NoError(t, err)
+
+			options, ok := reqBody["options"].(map[string]any)
+			require.True(t, ok)
+			// A zero temperature must be sent rather than omitted, or Ollama uses its own default
+			assert.Contains(t, options, "temperature")
+			assert.Equal(t, tt.wantTemperature, options["temperature"])
+			if tt.wantSeed == nil {
+				assert.NotContains(t, options, "seed")
+			} else {
+				assert.Equal(t, float64(*tt.wantSeed), options["seed"])
+			}
+			if tt.wantStop == nil {
+				assert.NotContains(t, options, "stop")
+			} else {
+				assert.Equal(t, tt.wantStop, options["stop"])
+			}
+		})
+	}
+}
+
+func TestOllamaProvider_Chat_Format_Mock(t *testing.T) {
+	schema := `{"type":"object","properties":{"name":{"type":"string"}},"required":["name"]}`
+
+	tests := []struct {
+		name       string
+		cfg        *config.OrlaConfig
+		wantFormat any
+	}{
+		{name: "unset", cfg: &config.OrlaConfig{}},
+		{name: "json", cfg: &config.OrlaConfig{ModelFormat: config.ModelFormatJSON}, wantFormat: "json"},
+		{
+			name: "schema",
+			cfg:  &config.OrlaConfig{ModelFormat: schema},
+			wantFormat: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"name": map[string]any{"type": "string"}},
+				"required":   []any{"name"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reqBody map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == ollamaHealthCheckEndpoint {
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&reqBody))
+				w.Header().Set("Content-Type", "application/json")
+				_, err := w.Write([]byte(`{"message": {"role": "assistant", "content": "{}"}, "done": true}`))
+				require.NoError(t, err)
+			}))
+			defer server.Close()
+
+			provider := &OllamaProvider{
+				modelName: orlaTesting.GetTestModelName(),
+				baseURL:   server.URL,
+				client:    &http.Client{Timeout: 5 * time.Second},
+				cfg:       tt.cfg,
+			}
+
+			_, _, err := provider.Chat(context.Background(), []Message{{Role: MessageRoleUser, Content: "Hello"}}, nil, false)
+			require.NoError(t, err)
+
+			if tt.wantFormat == nil {
+				assert.NotContains(t, reqBody, "format")
+			} else {
+				assert.Equal(t, tt.wantFormat, reqBody["format"])
+			}
+		})
+	}
+}
+
 func TestOllamaProvider_Chat_WithToolMessage_Mock(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == ollamaHealthCheckEndpoint {
@@ -801,6 +916,119 @@ func TestOllamaProvider_Chat_WithToolMessage_Mock(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestOllamaProvider_Chat_WithSystemMessage_Mock(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == ollamaHealthCheckEndpoint {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		var reqBody ollamaChatRequest
+		err := json.NewDecoder(r.Body).Decode(&reqBody)
+		require.NoError(t, err)
+		require.Len(t, reqBody.Messages, 2)
+		assert.Equal(t, ollamaMessage{Role: "system", Content: "You are terse."}, reqBody.Messages[0])
+		assert.Equal(t, "user", reqBody.Messages[1].Role)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, err = w.Write([]byte(`{"message": {"role": "assistant", "content": "OK"}, "done": true}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	provider := &OllamaProvider{
+		modelName: orlaTesting.GetTestModelName(),
+		baseURL:   server.URL,
+		client:    &http.Client{Timeout: 5 * time.Second},
+		cfg:       &config.OrlaConfig{},
+	}
+
+	messages := []Message{
+		{Role: MessageRoleSystem, Content: "You are terse."},
+		{Role: MessageRoleUser, Content: "hi"},
+	}
+
+	_, _, err := provider.Chat(context.Background(), messages, nil, false)
+	require.NoError(t, err)
+}
+
+func TestOllamaProvider_Chat_SkipsToolCallOnlyAssistantMessages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == ollamaHealthCheckEndpoint {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		var reqBody ollamaChatRequest
+		err := json.NewDecoder(r.Body).Decode(&reqBody)
+		require.NoError(t, err)
+		require.Len(t, reqBody.Messages, 2)
+		assert.Equal(t, "user", reqBody.Messages[0].Role)
+		assert.Equal(t, "tool", reqBody.Messages[1].Role)
+
+		response := `{"message": {"role": "assistant", "content": "OK"}, "done": true}`
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, err = w.Write([]byte(response))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	provider := &OllamaProvider{
+		modelName: orlaTesting.GetTestModelName(),
+		baseURL:   server.URL,
+		client:    &http.Client{Timeout: 5 * time.Second},
+		cfg:       &config.OrlaConfig{},
+	}
+
+	messages := []Message{
+		{Role: MessageRoleUser, Content: "Run the tool"},
+		{Role: MessageRoleAssistant, ToolCalls: []ToolCallWithID{
+			{ID: "call_0", McpCallToolParams: mcp.CallToolParams{Name: "test_tool"}},
+		}},
+		{Role: MessageRoleTool, Content: "tool output", ToolName: "test_tool", ToolCallID: "call_0"},
+	}
+
+	_, _, err := provider.Chat(context.Background(), messages, nil, false)
+	require.NoError(t, err)
+}
+
+func TestOllamaProvider_Chat_SendsKeptThinking(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == ollamaHealthCheckEndpoint {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		var reqBody ollamaChatRequest
+		err := json.NewDecoder(r.Body).Decode(&reqBody)
+		require.NoError(t, err)
+		require.Len(t, reqBody.Messages, 3)
+		assert.Empty(t, reqBody.Messages[0].Thinking)
+		assert.Equal(t, "earlier reasoning", reqBody.Messages[1].Thinking)
+
+		response := `{"message": {"role": "assistant", "content": "OK"}, "done": true}`
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, err = w.Write([]byte(response))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	provider := &OllamaProvider{
+		modelName: orlaTesting.GetTestModelName(),
+		baseURL:   server.URL,
+		client:    &http.Client{Timeout: 5 * time.Second},
+		cfg:       &config.OrlaConfig{},
+	}
+
+	messages := []Message{
+		{Role: MessageRoleUser, Content: "First question"},
+		{Role: MessageRoleAssistant, Content: "First answer", Thinking: "earlier reasoning"},
+		{Role: MessageRoleUser, Content: "Second question"},
+	}
+
+	_, _, err := provider.Chat(context.Background(), messages, nil, false)
+	require.NoError(t, err)
+}
+
 func TestOllamaProvider_Chat_NewRequestError(t *testing.T) {
 	cfg := &config.OrlaConfig{}
 	provider := &OllamaProvider{
@@ -959,3 +1187,359 @@ func TestHandleStreamResponse_AccumulationAndEvents(t *testing.T) {
 	assert.Equal(t, "do_it", resp.ToolCalls[0].McpCallToolParams.Name)
 	assert.Equal(t, 1, toolCallEvents)
 }
+
+func TestOllamaProvider_CheckModel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == ollamaHealthCheckEndpoint {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"models":[{"name":"qwen3:0.6b"},{"name":"llama3:latest"}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	cfg := &config.OrlaConfig{}
+
+	tests := []struct {
+		modelName string
+		wantErr   bool
+	}{
+		{modelName: "qwen3:0.6b"},
+		{modelName: "llama3"},
+		{modelName: "llama3:latest"},
+		{modelName: "mistral", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.modelName, func(t *testing.T) {
+			provider, err := NewOllamaProvider(tt.modelName, cfg)
+			require.NoError(t, err)
+			provider.baseURL = server.URL
+
+			err = provider.CheckModel(context.Background())
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "ollama pull "+tt.modelName)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestOllamaProvider_CheckModel_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	provider, err := NewOllamaProvider(orlaTesting.GetTestModelName(), &config.OrlaConfig{})
+	require.NoError(t, err)
+	provider.baseURL = server.URL
+
+	err = provider.CheckModel(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 500")
+}
+
+func TestOllamaProvider_Chat_ModelNotFound(t *testing.T) {
+	// Ollama answers chat requests for a model that has not been pulled with 404 and an error body
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == ollamaHealthCheckEndpoint {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if r.URL.Path == ollamaChatEndpoint {
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte(`{"error":"model \"missing-model\" not found, try pulling it first"}`))
+			require.NoError(t, err)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	provider := &OllamaProvider{
+		modelName: "missing-model",
+		baseURL:   server.URL,
+		client:    &http.Client{Timeout: 5 * time.Second},
+		cfg:       &config.OrlaConfig{},
+	}
+
+	messages := []Message{
+		{Role: MessageRoleUser, Content: "test"},
+	}
+
+	response, streamCh, err := provider.Chat(context.Background(), messages, nil, false)
+	require.Error(t, err)
+	assert.Nil(t, response)
+	assert.Nil(t, streamCh)
+
+	var notFoundErr *OllamaModelNotFoundError
+	require.ErrorAs(t, err, &notFoundErr)
+	assert.Equal(t, "missing-model", notFoundErr.Model)
+	assert.Equal(t, "model 'missing-model' not found on Ollama; run `ollama pull missing-model` or enable auto_pull_model", err.Error())
+}
+
+func TestOllamaProvider_EnsureReady_AutoPullModel(t *testing.T) {
+	pulled := false
+	pullCount := 0
+	tagsCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case ollamaHealthCheckEndpoint:
+			tagsCount++
+			w.Header().Set("Content-Type", "application/json")
+			if pulled {
+				_, _ = w.Write([]byte(`{"models":[{"name":"missing-model:latest"}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"models":[]}`))
+		case ollamaPullEndpoint:
+			var req ollamaPullRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "missing-model", req.Model)
+			assert.True(t, req.Stream, "pull progress should be streamed")
+			pulled = true
+			pullCount++
+			_, _ = w.Write([]byte("{\"status\":\"pulling manifest\"}\n" +
+				"{\"status\":\"pulling abc\",\"total\":100,\"completed\":50}\n" +
+				"{\"status\":\"pulling abc\",\"total\":100,\"completed\":100}\n" +
+				"{\"status\":\"success\"}\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewOllamaProvider("missing-model", &config.OrlaConfig{AutoPullModel: true})
+	require.NoError(t, err)
+	provider.baseURL = server.URL
+
+	require.NoError(t, provider.EnsureReady(context.Background()))
+	assert.True(t, pulled, "missing model should be pulled when auto_pull_model is enabled")
+
+	// Once ready, later calls neither pull again nor re-check the model list
+	checks := tagsCount
+	require.NoError(t, provider.EnsureReady(context.Background()))
+	assert.Equal(t, 1, pullCount)
+	assert.Equal(t, checks, tagsCount)
+}
+
+func TestOllamaProvider_PullModel_StreamError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{\"status\":\"pulling manifest\"}\n{\"error\":\"pull model manifest: file does not exist\"}\n"))
+	}))
+	defer server.Close()
+
+	provider, err := NewOllamaProvider("missing-model", &config.OrlaConfig{AutoPullModel: true})
+	require.NoError(t, err)
+	provider.baseURL = server.URL
+
+	err = provider.pullModel(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "file does not exist")
+}
+
+func TestOllamaProvider_PullModel_Incomplete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{\"status\":\"pulling manifest\"}\n"))
+	}))
+	defer server.Close()
+
+	provider, err := NewOllamaProvider("missing-model", &config.OrlaConfig{AutoPullModel: true})
+	require.NoError(t, err)
+	provider.baseURL = server.URL
+
+	err = provider.pullModel(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pull ended before completing")
+}
+
+func TestIsModelNotFoundResponse(t *testing.T) {
+	assert.True(t, isModelNotFoundResponse(http.StatusNotFound, []byte(`{"error":"model \"x\" not found, try pulling it first"}`)))
+	assert.False(t, isModelNotFoundResponse(http.StatusNotFound, []byte(`404 page not found`)))
+	assert.False(t, isModelNotFoundResponse(http.StatusInternalServerError, []byte(`{"error":"model \"x\" not found"}`)))
+}
+
+// newRetryingOllamaProvider creates a provider for server that retries transient chat failures
+func newRetryingOllamaProvider(serverURL string, maxRetries int, backoffMs int) *OllamaProvider {
+	return &OllamaProvider{
+		modelName: orlaTesting.GetTestModelName(),
+		baseURL:   serverURL,
+		client:    &http.Client{Timeout: 5 * time.Second},
+		cfg:       &config.OrlaConfig{ModelMaxRetries: maxRetries, ModelRetryBackoffMs: backoffMs},
+	}
+}
+
+// newFlakyOllamaServer serves chat requests with the given status codes in turn, then with chat
+// response chunks, and counts the chat requests it receives
+func newFlakyOllamaServer(t *testing.T, statuses []int, chunks []string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var chatRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == ollamaHealthCheckEndpoint {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		n := int(chatRequests.Add(1))
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			_, err := fmt.Fprintf(w, "attempt %d failed", n)
+			require.NoError(t, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		for _, chunk := range chunks {
+			_, err := w.Write([]byte(chunk + "\n"))
+			require.NoError(t, err)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &chatRequests
+}
+
+func TestOllamaProvider_Chat_RetriesTransientStatus(t *testing.T) {
+	server, chatRequests := newFlakyOllamaServer(t,
+		[]int{http.StatusInternalServerError, http.StatusServiceUnavailable},
+		[]string{`{"message": {"role": "assistant", "content": "recovered"}, "done": true}`})
+	provider := newRetryingOllamaProvider(server.URL, 2, 1)
+
+	response, _, err := provider.Chat(context.Background(), []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, "recovered", response.Content)
+	assert.Equal(t, int32(3), chatRequests.Load())
+}
+
+func TestOllamaProvider_Chat_RetriesExhausted(t *testing.T) {
+	server, chatRequests := newFlakyOllamaServer(t,
+		[]int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError},
+		[]string{`{"message": {"role": "assistant", "content": "too late"}, "done": true}`})
+	provider := newRetryingOllamaProvider(server.URL, 2, 1)
+
+	_, _, err := provider.Chat(context.Background(), []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ollama chat request failed after 3 attempts")
+	assert.Contains(t, err.Error(), "ollama API error: 500 - attempt 3 failed")
+	assert.Equal(t, int32(3), chatRequests.Load())
+}
+
+func TestOllamaProvider_Chat_NoRetryOnClientError(t *testing.T) {
+	server, chatRequests := newFlakyOllamaServer(t,
+		[]int{http.StatusBadRequest},
+		[]string{`{"message": {"role": "assistant", "content": "unreachable"}, "done": true}`})
+	provider := newRetryingOllamaProvider(server.URL, 2, 1)
+
+	_, _, err := provider.Chat(context.Background(), []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, false)
+	require.Error(t, err)
+	assert.Equal(t, "ollama API error: 400 - attempt 1 failed", err.Error())
+	assert.Equal(t, int32(1), chatRequests.Load())
+}
+
+func TestOllamaProvider_Chat_RetriesDisabled(t *testing.T) {
+	server, chatRequests := newFlakyOllamaServer(t,
+		[]int{http.StatusInternalServerError},
+		[]string{`{"message": {"role": "assistant", "content": "unreachable"}, "done": true}`})
+	provider := newRetryingOllamaProvider(server.URL, 0, 1)
+
+	_, _, err := provider.Chat(context.Background(), []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, false)
+	require.Error(t, err)
+	assert.Equal(t, "ollama API error: 500 - attempt 1 failed", err.Error())
+	assert.Equal(t, int32(1), chatRequests.Load())
+}
+
+func TestOllamaProvider_Chat_RetriesStreamBeforeFirstByte(t *testing.T) {
+	server, chatRequests := newFlakyOllamaServer(t,
+		[]int{http.StatusBadGateway},
+		[]string{
+			`{"message": {"role": "assistant", "content": "streamed"}, "done": false}`,
+			`{"done": true}`,
+		})
+	provider := newRetryingOllamaProvider(server.URL, 2, 1)
+
+	response, streamCh, err := provider.Chat(context.Background(), []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, true)
+	require.NoError(t, err)
+	for range streamCh {
+	}
+	assert.Equal(t, "streamed", response.Content)
+	assert.Equal(t, int32(2), chatRequests.Load())
+}
+
+func TestOllamaProvider_Chat_NoRetryAfterStreamStarted(t *testing.T) {
+	var chatRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == ollamaHealthCheckEndpoint {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		chatRequests.Add(1)
+		// Send one chunk, then drop the connection mid-stream
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		_, err = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nTransfer-Encoding: chunked\r\n\r\n")
+		require.NoError(t, err)
+		chunk := `{"message": {"role": "assistant", "content": "partial"}, "done": false}` + "\n"
+		_, err = fmt.Fprintf(buf, "%x\r\n%s\r\n", len(chunk), chunk)
+		require.NoError(t, err)
+		require.NoError(t, buf.Flush())
+		require.NoError(t, conn.Close())
+	}))
+	defer server.Close()
+	provider := newRetryingOllamaProvider(server.URL, 2, 1)
+
+	response, streamCh, err := provider.Chat(context.Background(), []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, true)
+	require.NoError(t, err)
+	for range streamCh {
+	}
+	assert.Equal(t, "partial", response.Content)
+	assert.Equal(t, int32(1), chatRequests.Load())
+}
+
+func TestOllamaProvider_Chat_RetriesDroppedConnection(t *testing.T) {
+	var chatRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == ollamaHealthCheckEndpoint {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if chatRequests.Add(1) == 1 {
+			// Promise a longer body than is sent, then drop the connection
+			conn, buf, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			_, err = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 100\r\n\r\n{\"message\": {")
+			require.NoError(t, err)
+			require.NoError(t, buf.Flush())
+			require.NoError(t, conn.Close())
+			return
+		}
+		_, err := w.Write([]byte(`{"message": {"role": "assistant", "content": "recovered"}, "done": true}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+	provider := newRetryingOllamaProvider(server.URL, 1, 1)
+
+	response, _, err := provider.Chat(context.Background(), []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, "recovered", response.Content)
+	assert.Equal(t, int32(2), chatRequests.Load())
+}
+
+func TestOllamaProvider_Chat_RetryRespectsContextCancellation(t *testing.T) {
+	server, chatRequests := newFlakyOllamaServer(t,
+		[]int{http.StatusInternalServerError},
+		[]string{`{"message": {"role": "assistant", "content": "unreachable"}, "done": true}`})
+	// The backoff is far longer than the context deadline
+	provider := newRetryingOllamaProvider(server.URL, 2, 60000)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, _, err := provider.Chat(ctx, []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, false)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "ollama API error: 500")
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, int32(1), chatRequests.Load())
+}
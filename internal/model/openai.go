@@ -0,0 +1,445 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// OpenAIAPIKeyEnvVar is the environment variable holding the OpenAI API key
+	OpenAIAPIKeyEnvVar = "OPENAI_API_KEY"
+	// OpenAIBaseURLEnvVar is the environment variable that overrides the OpenAI API base URL,
+	// e.g. to use an OpenAI-compatible server
+	OpenAIBaseURLEnvVar = "OPENAI_BASE_URL"
+
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+	defaultOpenAITimeout = 10 * time.Minute
+	openAIModelsEndpoint = "/models"
+	openAIChatEndpoint   = "/chat/completions"
+	openAISSEDone        = "[DONE]"
+)
+
+// ErrOpenAIAPIKeyMissing is returned when an OpenAI model is configured but no API key is set
+var ErrOpenAIAPIKeyMissing = fmt.Errorf("%s is not set; export your OpenAI API key to use openai models", OpenAIAPIKeyEnvVar)
+
+// OpenAIModelNotFoundError is returned when the OpenAI API does not know the configured model
+type OpenAIModelNotFoundError struct {
+	Model string
+}
+
+// Error returns the error message for the OpenAIModelNotFoundError
+func (e *OpenAIModelNotFoundError) Error() string {
+	return fmt.Sprintf("model '%s' not found on the OpenAI API; check the model name and that your key has access to it", e.Model)
+}
+
+// Interface guard for OpenAIModelNotFoundError
+var _ error = &OpenAIModelNotFoundError{}
+
+// OpenAIProvider implements the Provider interface for the OpenAI chat completions API
+type OpenAIProvider struct {
+	modelName string
+	baseURL   string
+	apiKey    string
+	client    *http.Client
+	cfg       *config.OrlaConfig
+}
+
+// NewOpenAIProvider creates a new OpenAI provider. The API key is read from OPENAI_API_KEY;
+// a missing key is reported by EnsureReady and Chat rather than here.
+func NewOpenAIProvider(modelName string, cfg *config.OrlaConfig) (*OpenAIProvider, error) {
+	baseURL := defaultOpenAIBaseURL
+	if envURL := core.GetEnv(OpenAIBaseURLEnvVar); envURL != "" {
+		baseURL = strings.TrimSuffix(envURL, "/")
+	}
+
+	return &OpenAIProvider{
+		modelName: modelName,
+		baseURL:   baseURL,
+		apiKey:    core.GetEnv(OpenAIAPIKeyEnvVar),
+		client:    &http.Client{Timeout: defaultOpenAITimeout},
+		cfg:       cfg,
+	}, nil
+}
+
+// SetTimeout sets the timeout for the OpenAI provider
+func (p *OpenAIProvider) SetTimeout(timeout time.Duration) {
+	p.client.Timeout = timeout
+}
+
+// Name returns the provider name
+func (p *OpenAIProvider) Name() string {
+	return "openai"
+}
+
+// EnsureReady checks that an API key is set and that the OpenAI API accepts it, by listing models
+func (p *OpenAIProvider) EnsureReady(ctx context.Context) error {
+	if p.apiKey == "" {
+		return ErrOpenAIAPIKeyMissing
+	}
+
+	resp, err := p.get(ctx, openAIModelsEndpoint)
+	if err != nil {
+		return fmt.Errorf("failed to reach the OpenAI API at %s: %w", p.baseURL, err)
+	}
+	defer core.LogDeferredError(resp.Body.Close)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("the OpenAI API rejected the key in %s (status %d)", OpenAIAPIKeyEnvVar, resp.StatusCode)
+	default:
+		return providerAPIError(p.Name(), resp)
+	}
+}
+
+// CheckModel checks that the configured model is available to the API key
+func (p *OpenAIProvider) CheckModel(ctx context.Context) error {
+	resp, err := p.get(ctx, openAIModelsEndpoint+"/"+url.PathEscape(p.modelName))
+	if err != nil {
+		return fmt.Errorf("failed to look up OpenAI model: %w", err)
+	}
+	defer core.LogDeferredError(resp.Body.Close)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return &OpenAIModelNotFoundError{Model: p.modelName}
+	default:
+		return providerAPIError(p.Name(), resp)
+	}
+}
+
+// get sends an authenticated GET request to the given API endpoint
+func (p *OpenAIProvider) get(ctx context.Context, endpoint string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	return p.client.Do(req)
+}
+
+// Chat sends a chat completion request to the OpenAI API
+func (p *OpenAIProvider) Chat(ctx context.Context, messages []Message, tools []*mcp.Tool, stream bool) (*Response, <-chan StreamEvent, error) {
+	// Only check the key here; the auth check in EnsureReady costs a request per turn
+	if p.apiKey == "" {
+		return nil, nil, ErrOpenAIAPIKeyMissing
+	}
+
+	reqBody := openAIChatRequest{
+		Model:    p.modelName,
+		Messages: convertMessagesToOpenAIFormat(messages),
+		Stream:   stream,
+	}
+	if p.cfg != nil {
+		reqBody.Temperature = p.cfg.ModelTemperature
+		reqBody.Seed = p.cfg.ModelSeed
+		reqBody.Stop = p.cfg.Stop
+	}
+	if len(tools) > 0 {
+		reqBody.Tools = convertToolsToOpenAIFormat(tools)
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+openAIChatEndpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer core.LogDeferredError(resp.Body.Close)
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, nil, &OpenAIModelNotFoundError{Model: p.modelName}
+		}
+		return nil, nil, providerAPIError(p.Name(), resp)
+	}
+
+	if stream {
+		response, streamCh := p.handleStreamResponse(resp.Body)
+		return response, streamCh, nil
+	}
+
+	defer core.LogDeferredError(resp.Body.Close)
+
+	var openAIResp openAIChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&openAIResp); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(openAIResp.Choices) == 0 {
+		return nil, nil, fmt.Errorf("openai API returned no choices")
+	}
+
+	message := openAIResp.Choices[0].Message
+	zap.L().Debug("OpenAI response received",
+		zap.String("content", message.Content),
+		zap.Int("tool_calls_count", len(message.ToolCalls)))
+
+	response := &Response{
+		Content:  message.Content,
+		Thinking: message.ReasoningContent,
+	}
+	if len(message.ToolCalls) > 0 {
+		response.ToolCalls = convertOpenAIToolCalls(message.ToolCalls)
+	}
+
+	return response, nil, nil
+}
+
+// handleStreamResponse reads a server-sent event stream of chat completion chunks. Content and
+// reasoning are sent as they arrive. Tool call arguments arrive as JSON string fragments, so
+// tool calls are accumulated and sent once the stream ends. As with the Ollama provider, the
+// response is written by the goroutine and may only be read after the channel is closed.
+func (p *OpenAIProvider) handleStreamResponse(body io.ReadCloser) (*Response, <-chan StreamEvent) {
+	ch := make(chan StreamEvent, defaultStreamBufferSize)
+	response := &Response{
+		Content:   "",
+		Thinking:  "",
+		ToolCalls: []ToolCallWithID{},
+	}
+
+	go func() {
+		defer close(ch)
+		defer core.LogDeferredError(body.Close)
+
+		// Tool call deltas are keyed by their index in the message
+		accumulatedToolCalls := make(map[int]*openAIToolCall)
+
+		err := readSSEData(body, func(data string) bool {
+			if data == openAISSEDone {
+				return false
+			}
+
+			var chunk openAIChatResponse
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				zap.L().Error("Failed to decode stream chunk", zap.Error(err))
+				return false
+			}
+			if len(chunk.Choices) == 0 {
+				return true
+			}
+			delta := chunk.Choices[0].Delta
+
+			if delta.ReasoningContent != "" {
+				response.Thinking += delta.ReasoningContent
+				ch <- &ThinkingEvent{Content: delta.ReasoningContent}
+			}
+
+			if delta.Content != "" {
+				response.Content += delta.Content
+				ch <- &ContentEvent{Content: delta.Content}
+			}
+
+			for _, toolCallDelta := range delta.ToolCalls {
+				index := 0
+				if toolCallDelta.Index != nil {
+					index = *toolCallDelta.Index
+				}
+				toolCall, ok := accumulatedToolCalls[index]
+				if !ok {
+					toolCall = &openAIToolCall{Type: "function"}
+					accumulatedToolCalls[index] = toolCall
+				}
+				if toolCallDelta.ID != "" {
+					toolCall.ID = toolCallDelta.ID
+				}
+				toolCall.Function.Name += toolCallDelta.Function.Name
+				toolCall.Function.Arguments += toolCallDelta.Function.Arguments
+			}
+			return true
+		})
+		if err != nil {
+			zap.L().Error("Failed to read stream", zap.Error(err))
+		}
+
+		if len(accumulatedToolCalls) == 0 {
+			return
+		}
+
+		indices := make([]int, 0, len(accumulatedToolCalls))
+		for index := range accumulatedToolCalls {
+			indices = append(indices, index)
+		}
+		sort.Ints(indices)
+
+		toolCalls := make([]openAIToolCall, len(indices))
+		for i, index := range indices {
+			toolCalls[i] = *accumulatedToolCalls[index]
+		}
+
+		response.ToolCalls = convertOpenAIToolCalls(toolCalls)
+		for _, toolCall := range response.ToolCalls {
+			args, _ := toolCall.McpCallToolParams.Arguments.(map[string]any)
+			ch <- &ToolCallEvent{
+				Name:      toolCall.McpCallToolParams.Name,
+				Arguments: args,
+			}
+		}
+		zap.L().Debug("Parsed tool calls from stream", zap.Int("count", len(response.ToolCalls)))
+	}()
+
+	return response, ch
+}
+
+// OpenAI-specific types
+type openAIMessage struct {
+	Role             string           `json:"role,omitempty"`
+	Content          string           `json:"content"`
+	ReasoningContent string           `json:"reasoning_content,omitempty"` // Only in responses from reasoning models
+	ToolCalls        []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID       string           `json:"tool_call_id,omitempty"` // Required when role is "tool"
+}
+
+type openAIChatRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	Stream      bool            `json:"stream"`
+	Temperature *float64        `json:"temperature,omitempty"`
+	Seed        *int            `json:"seed,omitempty"`
+	Stop        []string        `json:"stop,omitempty"`
+	Tools       []openAITool    `json:"tools,omitempty"`
+}
+
+type openAIChatResponse struct {
+	Choices []openAIChoice `json:"choices"`
+}
+
+type openAIChoice struct {
+	Message openAIMessage `json:"message"` // Set in non-streaming responses
+	Delta   openAIMessage `json:"delta"`   // Set in streaming chunks
+}
+
+type openAITool struct {
+	Type     string             `json:"type"`
+	Function openAIToolFunction `json:"function"`
+}
+
+type openAIToolFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters"`
+}
+
+type openAIToolCall struct {
+	Index    *int                   `json:"index,omitempty"` // Only in streaming chunks
+	ID       string                 `json:"id,omitempty"`
+	Type     string                 `json:"type,omitempty"` // "function"
+	Function openAIToolCallFunction `json:"function"`
+}
+
+type openAIToolCallFunction struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"` // Always a JSON string
+}
+
+// convertToolsToOpenAIFormat converts mcp.Tool slice to OpenAI function-calling format
+func convertToolsToOpenAIFormat(tools []*mcp.Tool) []openAITool {
+	openAITools := make([]openAITool, len(tools))
+	for i, tool := range tools {
+		openAITools[i] = openAITool{
+			Type: "function",
+			Function: openAIToolFunction{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  toolParameters(tool),
+			},
+		}
+	}
+	return openAITools
+}
+
+// convertOpenAIToolCalls converts OpenAI tool calls to our format
+func convertOpenAIToolCalls(openAICalls []openAIToolCall) []ToolCallWithID {
+	toolCalls := make([]ToolCallWithID, len(openAICalls))
+	for i, call := range openAICalls {
+		id := call.ID
+		if id == "" {
+			id = fmt.Sprintf("call_%d", i)
+		}
+
+		toolCalls[i] = ToolCallWithID{
+			ID: id,
+			McpCallToolParams: mcp.CallToolParams{
+				Name:      call.Function.Name,
+				Arguments: parseToolCallArguments(call.Function.Name, call.Function.Arguments),
+			},
+		}
+	}
+	return toolCalls
+}
+
+// convertMessagesToOpenAIFormat converts the conversation to OpenAI format. OpenAI requires each
+// tool message to answer a tool call from the preceding assistant message by ID; tool results
+// without an ID are sent as user messages instead.
+func convertMessagesToOpenAIFormat(messages []Message) []openAIMessage {
+	openAIMessages := make([]openAIMessage, len(messages))
+	for i, message := range messages {
+		msg := openAIMessage{
+			Role:    string(message.Role),
+			Content: message.Content,
+		}
+
+		switch message.Role {
+		case MessageRoleAssistant:
+			for _, toolCall := range message.ToolCalls {
+				arguments, err := json.Marshal(toolCall.McpCallToolParams.Arguments)
+				if err != nil {
+					zap.L().Warn("Failed to marshal tool call arguments",
+						zap.String("tool", toolCall.McpCallToolParams.Name),
+						zap.Error(err))
+					arguments = []byte("{}")
+				}
+				msg.ToolCalls = append(msg.ToolCalls, openAIToolCall{
+					ID:   toolCall.ID,
+					Type: "function",
+					Function: openAIToolCallFunction{
+						Name:      toolCall.McpCallToolParams.Name,
+						Arguments: string(arguments),
+					},
+				})
+			}
+		case MessageRoleTool:
+			if message.ToolCallID != "" {
+				msg.ToolCallID = message.ToolCallID
+			} else {
+				msg.Role = string(MessageRoleUser)
+				msg.Content = untrackedToolResultText(message)
+			}
+		}
+
+		openAIMessages[i] = msg
+	}
+	return openAIMessages
+}
+
+// Interface guards for OpenAIProvider
+var (
+	_ Provider     = &OpenAIProvider{}
+	_ ModelChecker = &OpenAIProvider{}
+)
@@ -0,0 +1,300 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOpenAIKey = "sk-test"
+
+// newTestOpenAIProvider returns an OpenAIProvider that talks to the given test server
+func newTestOpenAIProvider(serverURL string, cfg *config.OrlaConfig) *OpenAIProvider {
+	return &OpenAIProvider{
+		modelName: "gpt-4o-mini",
+		baseURL:   serverURL,
+		apiKey:    testOpenAIKey,
+		client:    &http.Client{Timeout: 5 * time.Second},
+		cfg:       cfg,
+	}
+}
+
+func TestNewOpenAIProvider(t *testing.T) {
+	t.Setenv(OpenAIAPIKeyEnvVar, testOpenAIKey)
+	t.Setenv(OpenAIBaseURLEnvVar, "http://localhost:8000/v1/")
+
+	provider, err := NewOpenAIProvider("gpt-4o-mini", &config.OrlaConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "openai", provider.Name())
+	assert.Equal(t, testOpenAIKey, provider.apiKey)
+	assert.Equal(t, "http://localhost:8000/v1", provider.baseURL)
+}
+
+func TestNewOpenAIProvider_DefaultBaseURL(t *testing.T) {
+	t.Setenv(OpenAIBaseURLEnvVar, "")
+
+	provider, err := NewOpenAIProvider("gpt-4o-mini", &config.OrlaConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, defaultOpenAIBaseURL, provider.baseURL)
+}
+
+func TestOpenAIProvider_EnsureReady(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, openAIModelsEndpoint, r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer "+testOpenAIKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, err := w.Write([]byte(`{"data": []}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	provider := newTestOpenAIProvider(server.URL, nil)
+	require.NoError(t, provider.EnsureReady(context.Background()))
+
+	provider.apiKey = "sk-wrong"
+	err := provider.EnsureReady(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rejected")
+	assert.Contains(t, err.Error(), OpenAIAPIKeyEnvVar)
+}
+
+func TestOpenAIProvider_EnsureReady_MissingKey(t *testing.T) {
+	provider := newTestOpenAIProvider(testInvalidBaseURL, nil)
+	provider.apiKey = ""
+
+	err := provider.EnsureReady(context.Background())
+	assert.ErrorIs(t, err, ErrOpenAIAPIKeyMissing)
+
+	_, _, err = provider.Chat(context.Background(), nil, nil, false)
+	assert.ErrorIs(t, err, ErrOpenAIAPIKeyMissing)
+}
+
+func TestOpenAIProvider_CheckModel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == openAIModelsEndpoint+"/gpt-4o-mini" {
+			_, err := w.Write([]byte(`{"id": "gpt-4o-mini"}`))
+			require.NoError(t, err)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	provider := newTestOpenAIProvider(server.URL, nil)
+	require.NoError(t, provider.CheckModel(context.Background()))
+
+	provider.modelName = "missing-model"
+	err := provider.CheckModel(context.Background())
+	var notFoundErr *OpenAIModelNotFoundError
+	require.ErrorAs(t, err, &notFoundErr)
+	assert.Equal(t, "missing-model", notFoundErr.Model)
+}
+
+func TestOpenAIProvider_Chat_Mock_WithToolCalls(t *testing.T) {
+	temperature := 0.2
+	seed := 7
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, openAIChatEndpoint, r.URL.Path)
+		assert.Equal(t, "Bearer "+testOpenAIKey, r.Header.Get("Authorization"))
+
+		var reqBody openAIChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reqBody))
+		assert.Equal(t, "gpt-4o-mini", reqBody.Model)
+		assert.False(t, reqBody.Stream)
+		require.NotNil(t, reqBody.Temperature)
+		assert.Equal(t, temperature, *reqBody.Temperature)
+		require.NotNil(t, reqBody.Seed)
+		assert.Equal(t, seed, *reqBody.Seed)
+		assert.Equal(t, []string{"END"}, reqBody.Stop)
+		require.Len(t, reqBody.Tools, 1)
+		assert.Equal(t, "function", reqBody.Tools[0].Type)
+		assert.Equal(t, "get_temperature", reqBody.Tools[0].Function.Name)
+
+		response := `{
+			"choices": [{
+				"message": {
+					"role": "assistant",
+					"content": "",
+					"tool_calls": [{
+						"id": "call_abc",
+						"type": "function",
+						"function": {"name": "get_temperature", "arguments": "{\"city\": \"Boston\"}"}
+					}]
+				}
+			}]
+		}`
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write([]byte(response))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	provider := newTestOpenAIProvider(server.URL, &config.OrlaConfig{ModelTemperature: &temperature, ModelSeed: &seed, Stop: []string{"END"}})
+
+	tools := []*mcp.Tool{
+		{
+			Name:        "get_temperature",
+			Description: "Get temperature",
+			InputSchema: map[string]any{"type": "object"},
+		},
+	}
+
+	response, streamCh, err := provider.Chat(context.Background(), []Message{{Role: MessageRoleUser, Content: "What's the temperature?"}}, tools, false)
+	require.NoError(t, err)
+	assert.Nil(t, streamCh)
+	require.Len(t, response.ToolCalls, 1)
+	assert.Equal(t, "call_abc", response.ToolCalls[0].ID)
+	assert.Equal(t, "get_temperature", response.ToolCalls[0].McpCallToolParams.Name)
+	assert.Equal(t, map[string]any{"city": "Boston"}, response.ToolCalls[0].McpCallToolParams.Arguments)
+}
+
+func TestOpenAIProvider_Chat_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, err := w.Write([]byte(`{"error": {"message": "Rate limit reached"}}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	provider := newTestOpenAIProvider(server.URL, nil)
+	_, _, err := provider.Chat(context.Background(), []Message{{Role: MessageRoleUser, Content: "Hi"}}, nil, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "429")
+	assert.Contains(t, err.Error(), "Rate limit reached")
+}
+
+func TestOpenAIProvider_Chat_Stream_Mock(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody openAIChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reqBody))
+		assert.True(t, reqBody.Stream)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			t.Fatal("expected http.ResponseWriter to be an http.Flusher")
+		}
+
+		events := []string{
+			`: keep-alive`,
+			`data: {"choices": [{"delta": {"role": "assistant", "reasoning_content": "Thinking..."}}]}`,
+			`data: {"choices": [{"delta": {"content": "Hello, "}}]}`,
+			`data: {"choices": [{"delta": {"content": "world!"}}]}`,
+			`data: {"choices": [{"delta": {"tool_calls": [{"index": 0, "id": "call_1", "type": "function", "function": {"name": "test_tool", "arguments": ""}}]}}]}`,
+			`data: {"choices": [{"delta": {"tool_calls": [{"index": 0, "function": {"arguments": "{\"path\":"}}]}}]}`,
+			`data: {"choices": [{"delta": {"tool_calls": [{"index": 0, "function": {"arguments": " \"/tmp\"}"}}]}}]}`,
+			`data: {"choices": []}`,
+			`data: [DONE]`,
+		}
+		for _, event := range events {
+			_, err := w.Write([]byte(event + "\n\n"))
+			require.NoError(t, err)
+			flusher.Flush()
+		}
+	}))
+	defer server.Close()
+
+	provider := newTestOpenAIProvider(server.URL, nil)
+	response, streamCh, err := provider.Chat(context.Background(), []Message{{Role: MessageRoleUser, Content: "Hello"}}, nil, true)
+	require.NoError(t, err)
+	require.NotNil(t, streamCh)
+
+	var content, thinking string
+	var toolCallEvents []*ToolCallEvent
+	for event := range streamCh {
+		switch e := event.(type) {
+		case *ContentEvent:
+			content += e.Content
+		case *ThinkingEvent:
+			thinking += e.Content
+		case *ToolCallEvent:
+			toolCallEvents = append(toolCallEvents, e)
+		}
+	}
+
+	assert.Equal(t, "Hello, world!", content)
+	assert.Equal(t, "Thinking...", thinking)
+	require.Len(t, toolCallEvents, 1)
+	assert.Equal(t, "test_tool", toolCallEvents[0].Name)
+	assert.Equal(t, map[string]any{"path": "/tmp"}, toolCallEvents[0].Arguments)
+
+	assert.Equal(t, "Hello, world!", response.Content)
+	assert.Equal(t, "Thinking...", response.Thinking)
+	require.Len(t, response.ToolCalls, 1)
+	assert.Equal(t, "call_1", response.ToolCalls[0].ID)
+	assert.Equal(t, map[string]any{"path": "/tmp"}, response.ToolCalls[0].McpCallToolParams.Arguments)
+}
+
+func TestConvertToolsToOpenAIFormat(t *testing.T) {
+	schema := map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"path": map[string]any{"type": "string"}},
+	}
+	tools := []*mcp.Tool{
+		{Name: "read_file", Description: "Read a file", InputSchema: schema},
+		{Name: "no_schema"},
+	}
+
+	openAITools := convertToolsToOpenAIFormat(tools)
+	require.Len(t, openAITools, 2)
+	assert.Equal(t, "function", openAITools[0].Type)
+	assert.Equal(t, "read_file", openAITools[0].Function.Name)
+	assert.Equal(t, "Read a file", openAITools[0].Function.Description)
+	assert.Equal(t, schema, openAITools[0].Function.Parameters)
+	assert.Equal(t, map[string]any{}, openAITools[1].Function.Parameters)
+}
+
+func TestConvertOpenAIToolCalls_InvalidArguments(t *testing.T) {
+	toolCalls := convertOpenAIToolCalls([]openAIToolCall{
+		{Function: openAIToolCallFunction{Name: "test_tool", Arguments: "not json"}},
+	})
+
+	require.Len(t, toolCalls, 1)
+	assert.Equal(t, "call_0", toolCalls[0].ID)
+	assert.Equal(t, map[string]any{}, toolCalls[0].McpCallToolParams.Arguments)
+}
+
+func TestConvertMessagesToOpenAIFormat(t *testing.T) {
+	messages := []Message{
+		{Role: MessageRoleSystem, Content: "Be brief"},
+		{Role: MessageRoleUser, Content: "List /tmp"},
+		{Role: MessageRoleAssistant, ToolCalls: []ToolCallWithID{
+			{ID: "call_1", McpCallToolParams: mcp.CallToolParams{Name: "ls", Arguments: map[string]any{"path": "/tmp"}}},
+		}},
+		{Role: MessageRoleTool, ToolName: "ls", ToolCallID: "call_1", Content: "a.txt"},
+		{Role: MessageRoleTool, ToolName: "ls", Content: "b.txt"},
+	}
+
+	openAIMessages := convertMessagesToOpenAIFormat(messages)
+	require.Len(t, openAIMessages, 5)
+
+	assert.Equal(t, "system", openAIMessages[0].Role)
+	assert.Equal(t, "user", openAIMessages[1].Role)
+
+	require.Len(t, openAIMessages[2].ToolCalls, 1)
+	assert.Equal(t, "call_1", openAIMessages[2].ToolCalls[0].ID)
+	assert.Equal(t, "function", openAIMessages[2].ToolCalls[0].Type)
+	assert.Equal(t, "ls", openAIMessages[2].ToolCalls[0].Function.Name)
+	assert.JSONEq(t, `{"path": "/tmp"}`, openAIMessages[2].ToolCalls[0].Function.Arguments)
+
+	assert.Equal(t, "tool", openAIMessages[3].Role)
+	assert.Equal(t, "call_1", openAIMessages[3].ToolCallID)
+	assert.Equal(t, "a.txt", openAIMessages[3].Content)
+
+	// Tool results without a call ID cannot be sent as tool messages
+	assert.Equal(t, "user", openAIMessages[4].Role)
+	assert.Empty(t, openAIMessages[4].ToolCallID)
+	assert.Contains(t, openAIMessages[4].Content, "ls")
+	assert.Contains(t, openAIMessages[4].Content, "b.txt")
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"context"
+	"fmt"
+)
+
+// Preflight checks that the provider is ready and, if the provider implements ModelChecker,
+// that the configured model is available
+func Preflight(ctx context.Context, provider Provider) error {
+	if err := provider.EnsureReady(ctx); err != nil {
+		return fmt.Errorf("provider %s is not ready: %w", provider.Name(), err)
+	}
+
+	if checker, ok := provider.(ModelChecker); ok {
+		if err := checker.CheckModel(ctx); err != nil {
+			return fmt.Errorf("provider %s: %w", provider.Name(), err)
+		}
+	}
+
+	return nil
+}
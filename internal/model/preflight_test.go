@@ -0,0 +1,59 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// preflightTestProvider is a Provider whose readiness and model availability can be controlled
+type preflightTestProvider struct {
+	ensureReadyErr error
+	checkModelErr  error
+	checkedModel   bool
+}
+
+func (p *preflightTestProvider) Name() string {
+	return "test"
+}
+
+func (p *preflightTestProvider) Chat(ctx context.Context, messages []Message, tools []*mcp.Tool, stream bool) (*Response, <-chan StreamEvent, error) {
+	return &Response{}, nil, nil
+}
+
+func (p *preflightTestProvider) EnsureReady(ctx context.Context) error {
+	return p.ensureReadyErr
+}
+
+func (p *preflightTestProvider) CheckModel(ctx context.Context) error {
+	p.checkedModel = true
+	return p.checkModelErr
+}
+
+func TestPreflight(t *testing.T) {
+	provider := &preflightTestProvider{}
+	require.NoError(t, Preflight(context.Background(), provider))
+	assert.True(t, provider.checkedModel)
+}
+
+func TestPreflight_EnsureReadyFails(t *testing.T) {
+	provider := &preflightTestProvider{ensureReadyErr: errors.New("ollama is not running")}
+
+	err := Preflight(context.Background(), provider)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "provider test is not ready")
+	assert.Contains(t, err.Error(), "ollama is not running")
+	assert.False(t, provider.checkedModel, "model check should be skipped when the provider is not ready")
+}
+
+func TestPreflight_ModelMissing(t *testing.T) {
+	provider := &preflightTestProvider{checkModelErr: errors.New("model 'missing' is not available")}
+
+	err := Preflight(context.Background(), provider)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "model 'missing' is not available")
+}
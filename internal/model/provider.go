@@ -3,8 +3,10 @@ package model
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/registry"
 )
 
 // ParseModelIdentifier parses a model identifier string (e.g., "ollama:llama3")
@@ -17,21 +19,99 @@ func ParseModelIdentifier(modelID string) (provider, modelName string, err error
 	return parts[0], parts[1], nil
 }
 
-// NewProvider creates a new model provider based on the configuration
-func NewProvider(cfg *config.OrlaConfig) (Provider, error) {
-	if cfg.Model == "" {
+// untrackedToolResultText returns the text of a tool message that has no tool call ID, e.g. from
+// a conversation started with another provider. Providers that match results to calls by ID
+// send these as user messages.
+func untrackedToolResultText(message Message) string {
+	return fmt.Sprintf("Result of tool %s:\n%s", message.ToolName, message.Content)
+}
+
+// ProviderFactory creates a provider for the model name that follows the provider prefix in a
+// model identifier, e.g. "llama3:8b" for "ollama:llama3:8b"
+type ProviderFactory func(modelName string, cfg *config.OrlaConfig) (Provider, error)
+
+// registeredProvider is a model provider that NewProvider can create
+type registeredProvider struct {
+	name    string
+	factory ProviderFactory
+}
+
+// registeredProviders lists the supported model providers in the order they are reported to users
+var registeredProviders = []registeredProvider{
+	{name: "ollama", factory: providerFactory(NewOllamaProvider)},
+	{name: "openai", factory: providerFactory(NewOpenAIProvider)},
+	{name: "anthropic", factory: providerFactory(NewAnthropicProvider)},
+}
+
+// providerFactory adapts a provider constructor to a ProviderFactory, so that a failed constructor
+// returns a nil Provider rather than a typed nil pointer
+func providerFactory[P Provider](newProvider func(string, *config.OrlaConfig) (P, error)) ProviderFactory {
+	return func(modelName string, cfg *config.OrlaConfig) (Provider, error) {
+		provider, err := newProvider(modelName, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	}
+}
+
+// RegisterProvider makes a model provider available to NewProvider under the given prefix,
+// replacing any provider already registered under it
+func RegisterProvider(name string, factory ProviderFactory) {
+	for i := range registeredProviders {
+		if registeredProviders[i].name == name {
+			registeredProviders[i].factory = factory
+			return
+		}
+	}
+	registeredProviders = append(registeredProviders, registeredProvider{name: name, factory: factory})
+}
+
+// SupportedProviders returns the names of the registered model providers
+func SupportedProviders() []string {
+	names := make([]string, 0, len(registeredProviders))
+	for _, registered := range registeredProviders {
+		names = append(names, registered.name)
+	}
+	return names
+}
+
+// NewProvider creates the model provider for a model identifier of the form
+// provider:model[:tag], wrapping it in the response cache if it is enabled in the configuration
+func NewProvider(modelString string, cfg *config.OrlaConfig) (Provider, error) {
+	if modelString == "" {
 		return nil, fmt.Errorf("model not configured")
 	}
 
-	providerName, modelName, err := ParseModelIdentifier(cfg.Model)
+	providerName, modelName, err := ParseModelIdentifier(modelString)
 	if err != nil {
 		return nil, err
 	}
 
-	switch providerName {
-	case "ollama":
-		return NewOllamaProvider(modelName, cfg)
-	default:
-		return nil, fmt.Errorf("unknown model provider: %s (supported: ollama)", providerName)
+	var factory ProviderFactory
+	for _, registered := range registeredProviders {
+		if registered.name == providerName {
+			factory = registered.factory
+			break
+		}
+	}
+	if factory == nil {
+		return nil, fmt.Errorf("unsupported model provider: %s (supported: %s)", providerName, strings.Join(SupportedProviders(), ", "))
+	}
+
+	provider, err := factory(modelName, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cfg.ResponseCache {
+		return provider, nil
+	}
+
+	cacheDir, err := registry.GetResponseCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get response cache directory: %w", err)
 	}
+	cache := NewResponseCache(cacheDir, time.Duration(cfg.ResponseCacheTTL)*time.Second, cfg.ResponseCacheMaxEntries)
+	return NewCachingProvider(provider, modelString, cfg, cache), nil
 }
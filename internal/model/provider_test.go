@@ -1,9 +1,13 @@
 package model
 
 import (
+	"context"
+	"errors"
+	"slices"
 	"testing"
 
 	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -70,17 +74,43 @@ func TestParseModelIdentifier(t *testing.T) {
 
 func TestNewProvider(t *testing.T) {
 	tests := []struct {
-		name        string
-		cfg         *config.OrlaConfig
-		expectedErr bool
-		errContains string
+		name         string
+		cfg          *config.OrlaConfig
+		expectedErr  bool
+		errContains  string
+		expectedName string
 	}{
 		{
 			name: "valid ollama config",
 			cfg: &config.OrlaConfig{
 				Model: "ollama:llama3",
 			},
-			expectedErr: false,
+			expectedErr:  false,
+			expectedName: "ollama",
+		},
+		{
+			name: "valid openai config",
+			cfg: &config.OrlaConfig{
+				Model: "openai:gpt-4o-mini",
+			},
+			expectedErr:  false,
+			expectedName: "openai",
+		},
+		{
+			name: "valid anthropic config",
+			cfg: &config.OrlaConfig{
+				Model: "anthropic:claude-sonnet-4-5",
+			},
+			expectedErr:  false,
+			expectedName: "anthropic",
+		},
+		{
+			name: "ollama model with tag",
+			cfg: &config.OrlaConfig{
+				Model: "ollama:llama3:8b",
+			},
+			expectedErr:  false,
+			expectedName: "ollama",
 		},
 		{
 			name: "missing model",
@@ -104,13 +134,13 @@ func TestNewProvider(t *testing.T) {
 				Model: "unknown:model",
 			},
 			expectedErr: true,
-			errContains: "unknown model provider",
+			errContains: "unsupported model provider: unknown (supported: ollama, openai, anthropic)",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			provider, err := NewProvider(tt.cfg)
+			provider, err := NewProvider(tt.cfg.Model, tt.cfg)
 			if tt.expectedErr {
 				assert.Error(t, err)
 				if tt.errContains != "" {
@@ -120,8 +150,49 @@ func TestNewProvider(t *testing.T) {
 			} else {
 				require.NoError(t, err)
 				assert.NotNil(t, provider)
-				assert.Equal(t, "ollama", provider.Name())
+				assert.Equal(t, tt.expectedName, provider.Name())
 			}
 		})
 	}
 }
+
+// fakeProvider is a minimal provider for exercising provider registration
+type fakeProvider struct {
+	modelName string
+}
+
+func (p *fakeProvider) Name() string                          { return "fake" }
+func (p *fakeProvider) EnsureReady(ctx context.Context) error { return nil }
+func (p *fakeProvider) Chat(ctx context.Context, messages []Message, tools []*mcp.Tool, stream bool) (*Response, <-chan StreamEvent, error) {
+	return &Response{Content: p.modelName}, nil, nil
+}
+
+func TestRegisterProvider(t *testing.T) {
+	original := slices.Clone(registeredProviders)
+	t.Cleanup(func() {
+		registeredProviders = original
+	})
+
+	RegisterProvider("fake", func(modelName string, cfg *config.OrlaConfig) (Provider, error) {
+		return &fakeProvider{modelName: modelName}, nil
+	})
+	assert.Equal(t, []string{"ollama", "openai", "anthropic", "fake"}, SupportedProviders())
+
+	provider, err := NewProvider("fake:small:v2", &config.OrlaConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "fake", provider.Name())
+	assert.Equal(t, "small:v2", provider.(*fakeProvider).modelName)
+
+	// Registering an existing name replaces its factory without changing the order
+	RegisterProvider("ollama", func(modelName string, cfg *config.OrlaConfig) (Provider, error) {
+		return nil, errors.New("ollama disabled")
+	})
+	assert.Equal(t, []string{"ollama", "openai", "anthropic", "fake"}, SupportedProviders())
+	provider, err = NewProvider("ollama:llama3", &config.OrlaConfig{})
+	require.Error(t, err)
+	assert.Nil(t, provider)
+
+	_, err = NewProvider("foo:bar", &config.OrlaConfig{})
+	require.Error(t, err)
+	assert.Equal(t, "unsupported model provider: foo (supported: ollama, openai, anthropic, fake)", err.Error())
+}
@@ -0,0 +1,265 @@
+package model
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// responseCacheFileExt is the file extension of cached responses
+const responseCacheFileExt = ".json"
+
+// ResponseCache is an on-disk cache of model responses with a TTL and a maximum number of entries.
+// When the cache is full, the oldest entries are evicted.
+type ResponseCache struct {
+	dir        string
+	ttl        time.Duration
+	maxEntries int
+	clock      clockwork.Clock
+	mu         sync.Mutex
+}
+
+// cachedResponse is the on-disk format of a cached response
+type cachedResponse struct {
+	CreatedAt time.Time `json:"created_at"`
+	Response  *Response `json:"response"`
+}
+
+// NewResponseCache creates a response cache stored in dir
+func NewResponseCache(dir string, ttl time.Duration, maxEntries int) *ResponseCache {
+	return NewResponseCacheWithClock(dir, ttl, maxEntries, clockwork.NewRealClock())
+}
+
+// NewResponseCacheWithClock creates a response cache with a custom clock
+// This is useful for testing with a fake clock
+func NewResponseCacheWithClock(dir string, ttl time.Duration, maxEntries int, clock clockwork.Clock) *ResponseCache {
+	return &ResponseCache{
+		dir:        dir,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		clock:      clock,
+	}
+}
+
+// Get returns the cached response for key, or false if there is none or it has expired
+func (c *ResponseCache) Get(key string) (*Response, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	root, err := os.OpenRoot(c.dir)
+	if err != nil {
+		return nil, false
+	}
+	defer core.LogDeferredError(root.Close)
+
+	fileName := key + responseCacheFileExt
+	data, err := root.ReadFile(fileName)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Response == nil {
+		zap.L().Debug("Discarding unreadable cached response", zap.String("key", key), zap.Error(err))
+		_ = root.Remove(fileName)
+		return nil, false
+	}
+
+	if c.clock.Since(entry.CreatedAt) > c.ttl {
+		_ = root.Remove(fileName)
+		return nil, false
+	}
+
+	return entry.Response, true
+}
+
+// Put stores response under key, evicting the oldest entries if the cache is full
+func (c *ResponseCache) Put(key string, response *Response) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// #nosec G301 -- cache directory permissions 0755 are acceptable for user cache
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create response cache directory: %w", err)
+	}
+
+	root, err := os.OpenRoot(c.dir)
+	if err != nil {
+		return fmt.Errorf("failed to open response cache directory: %w", err)
+	}
+	defer core.LogDeferredError(root.Close)
+
+	data, err := json.Marshal(cachedResponse{CreatedAt: c.clock.Now(), Response: response})
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	// #nosec G306 -- cache file permissions 0644 are acceptable for user cache files
+	if err := root.WriteFile(key+responseCacheFileExt, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cached response: %w", err)
+	}
+
+	return c.evictLocked(root)
+}
+
+// evictLocked removes the oldest entries until the cache holds at most maxEntries (assumes lock IS held)
+func (c *ResponseCache) evictLocked(root *os.Root) error {
+	entries, err := fs.ReadDir(root.FS(), ".")
+	if err != nil {
+		return fmt.Errorf("failed to read response cache directory: %w", err)
+	}
+
+	type cacheFile struct {
+		name    string
+		modTime time.Time
+	}
+	var files []cacheFile
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), responseCacheFileExt) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, cacheFile{name: entry.Name(), modTime: info.ModTime()})
+	}
+
+	if len(files) <= c.maxEntries {
+		return nil
+	}
+
+	slices.SortFunc(files, func(a, b cacheFile) int { return a.modTime.Compare(b.modTime) })
+	for _, file := range files[:len(files)-c.maxEntries] {
+		if err := root.Remove(file.name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to evict cached response: %w", err)
+		}
+	}
+	return nil
+}
+
+// responseCacheOptions are the request options that affect a model's response
+type responseCacheOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
+	Think       bool     `json:"think"`
+	Format      string   `json:"format,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+}
+
+// responseCacheOptionsFromConfig returns the request options configured in cfg
+func responseCacheOptionsFromConfig(cfg *config.OrlaConfig) responseCacheOptions {
+	return responseCacheOptions{
+		Temperature: cfg.ModelTemperature,
+		Seed:        cfg.ModelSeed,
+		Think:       cfg.ShowThinking,
+		Format:      cfg.ModelFormat,
+		Stop:        cfg.Stop,
+	}
+}
+
+// deterministic reports whether requests with these options produce repeatable responses,
+// which is the case with a temperature of 0 or a fixed seed
+func (o responseCacheOptions) deterministic() bool {
+	return (o.Temperature != nil && *o.Temperature == 0) || o.Seed != nil
+}
+
+// ResponseCacheKey returns the cache key for a chat request: a hash of the model, messages, tools, and options
+func ResponseCacheKey(modelID string, messages []Message, tools []*mcp.Tool, options any) (string, error) {
+	data, err := json.Marshal(struct {
+		Model    string      `json:"model"`
+		Messages []Message   `json:"messages"`
+		Tools    []*mcp.Tool `json:"tools"`
+		Options  any         `json:"options"`
+	}{
+		Model:    modelID,
+		Messages: messages,
+		Tools:    tools,
+		Options:  options,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request for cache key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// CachingProvider wraps a provider and serves repeated deterministic, non-streaming chat
+// requests from a response cache
+type CachingProvider struct {
+	Provider
+	modelID string
+	options responseCacheOptions
+	cache   *ResponseCache
+}
+
+// Interface guards for CachingProvider
+var (
+	_ Provider     = &CachingProvider{}
+	_ ModelChecker = &CachingProvider{}
+)
+
+// NewCachingProvider wraps provider with the response cache. modelID and cfg describe the
+// requests the provider sends and are part of every cache key.
+func NewCachingProvider(provider Provider, modelID string, cfg *config.OrlaConfig, cache *ResponseCache) *CachingProvider {
+	return &CachingProvider{
+		Provider: provider,
+		modelID:  modelID,
+		options:  responseCacheOptionsFromConfig(cfg),
+		cache:    cache,
+	}
+}
+
+// Chat returns a cached response for deterministic, non-streaming requests when one exists,
+// and otherwise forwards the request to the wrapped provider
+func (p *CachingProvider) Chat(ctx context.Context, messages []Message, tools []*mcp.Tool, stream bool) (*Response, <-chan StreamEvent, error) {
+	if stream || !p.options.deterministic() {
+		return p.Provider.Chat(ctx, messages, tools, stream)
+	}
+
+	key, err := ResponseCacheKey(p.modelID, messages, tools, p.options)
+	if err != nil {
+		zap.L().Warn("Failed to compute response cache key, bypassing cache", zap.Error(err))
+		return p.Provider.Chat(ctx, messages, tools, stream)
+	}
+
+	if response, ok := p.cache.Get(key); ok {
+		zap.L().Debug("Serving model response from cache", zap.String("model", p.modelID), zap.String("key", key))
+		return response, nil, nil
+	}
+
+	response, streamCh, err := p.Provider.Chat(ctx, messages, tools, stream)
+	if err != nil {
+		return nil, streamCh, err
+	}
+
+	if putErr := p.cache.Put(key, response); putErr != nil {
+		zap.L().Warn("Failed to cache model response", zap.String("model", p.modelID), zap.Error(putErr))
+	}
+
+	return response, streamCh, nil
+}
+
+// CheckModel checks the wrapped provider's model, if the wrapped provider supports it
+func (p *CachingProvider) CheckModel(ctx context.Context) error {
+	if checker, ok := p.Provider.(ModelChecker); ok {
+		return checker.CheckModel(ctx)
+	}
+	return nil
+}
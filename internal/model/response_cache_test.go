@@ -0,0 +1,186 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/registry"
+)
+
+// countingProvider is a provider that counts chat requests and answers with the request number
+type countingProvider struct {
+	calls int
+}
+
+func (p *countingProvider) Name() string { return "counting" }
+
+func (p *countingProvider) Chat(_ context.Context, _ []Message, _ []*mcp.Tool, _ bool) (*Response, <-chan StreamEvent, error) {
+	p.calls++
+	return &Response{Content: fmt.Sprintf("response %d", p.calls)}, nil, nil
+}
+
+func (p *countingProvider) EnsureReady(_ context.Context) error { return nil }
+
+func float64Ptr(v float64) *float64 { return &v }
+
+func newTestCachingProvider(t *testing.T, cfg *config.OrlaConfig) (*CachingProvider, *countingProvider) {
+	t.Helper()
+	inner := &countingProvider{}
+	cache := NewResponseCache(t.TempDir(), time.Hour, 16)
+	return NewCachingProvider(inner, "counting:test", cfg, cache), inner
+}
+
+func TestCachingProvider_ServesIdenticalRequestFromCache(t *testing.T) {
+	provider, inner := newTestCachingProvider(t, &config.OrlaConfig{ModelTemperature: float64Ptr(0)})
+	ctx := context.Background()
+	messages := []Message{{Role: MessageRoleUser, Content: "Hello"}}
+
+	first, _, err := provider.Chat(ctx, messages, nil, false)
+	require.NoError(t, err)
+	second, _, err := provider.Chat(ctx, messages, nil, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, inner.calls, "second identical request should be served from cache")
+	assert.Equal(t, "response 1", first.Content)
+	assert.Equal(t, first, second)
+
+	// A different request misses the cache
+	third, _, err := provider.Chat(ctx, []Message{{Role: MessageRoleUser, Content: "Bye"}}, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls)
+	assert.Equal(t, "response 2", third.Content)
+
+	// So does the same request with tools
+	tools := []*mcp.Tool{{Name: "fs", InputSchema: map[string]any{"type": "object"}}}
+	_, _, err = provider.Chat(ctx, messages, tools, false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, inner.calls)
+}
+
+func TestCachingProvider_FixedSeedIsCached(t *testing.T) {
+	provider, inner := newTestCachingProvider(t, &config.OrlaConfig{ModelSeed: intPtr(42)})
+	messages := []Message{{Role: MessageRoleUser, Content: "Hello"}}
+
+	for range 3 {
+		_, _, err := provider.Chat(context.Background(), messages, nil, false)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, inner.calls)
+}
+
+func TestCachingProvider_FormatIsPartOfKey(t *testing.T) {
+	inner := &countingProvider{}
+	cache := NewResponseCache(t.TempDir(), time.Hour, 16)
+	messages := []Message{{Role: MessageRoleUser, Content: "Hello"}}
+
+	for _, format := range []string{"", config.ModelFormatJSON, config.ModelFormatJSON} {
+		provider := NewCachingProvider(inner, "counting:test", &config.OrlaConfig{ModelSeed: intPtr(42), ModelFormat: format}, cache)
+		_, _, err := provider.Chat(context.Background(), messages, nil, false)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, inner.calls, "a response cached without a format must not answer a request with one")
+}
+
+func TestCachingProvider_StopIsPartOfKey(t *testing.T) {
+	inner := &countingProvider{}
+	cache := NewResponseCache(t.TempDir(), time.Hour, 16)
+	messages := []Message{{Role: MessageRoleUser, Content: "Hello"}}
+
+	for _, stop := range [][]string{nil, {"END"}, {"END"}} {
+		provider := NewCachingProvider(inner, "counting:test", &config.OrlaConfig{ModelSeed: intPtr(42), Stop: stop}, cache)
+		_, _, err := provider.Chat(context.Background(), messages, nil, false)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, inner.calls, "a response cached without stop sequences must not answer a request with them")
+}
+
+func TestCachingProvider_BypassesNonDeterministicAndStreamingRequests(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    *config.OrlaConfig
+		stream bool
+	}{
+		{name: "default temperature", cfg: &config.OrlaConfig{}},
+		{name: "non-zero temperature", cfg: &config.OrlaConfig{ModelTemperature: float64Ptr(0.7)}},
+		{name: "streaming", cfg: &config.OrlaConfig{ModelTemperature: float64Ptr(0)}, stream: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, inner := newTestCachingProvider(t, tt.cfg)
+			messages := []Message{{Role: MessageRoleUser, Content: "Hello"}}
+
+			for range 2 {
+				_, _, err := provider.Chat(context.Background(), messages, nil, tt.stream)
+				require.NoError(t, err)
+			}
+			assert.Equal(t, 2, inner.calls)
+		})
+	}
+}
+
+func TestResponseCache_TTL(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	cache := NewResponseCacheWithClock(t.TempDir(), time.Minute, 16, clock)
+
+	require.NoError(t, cache.Put("key", &Response{Content: "cached"}))
+
+	response, ok := cache.Get("key")
+	require.True(t, ok)
+	assert.Equal(t, "cached", response.Content)
+
+	clock.Advance(2 * time.Minute)
+	_, ok = cache.Get("key")
+	assert.False(t, ok, "expired entries should not be served")
+}
+
+func TestResponseCache_EvictsOldestEntries(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewResponseCache(dir, time.Hour, 2)
+
+	// Age the entries so that eviction order does not depend on timestamp resolution
+	for i, key := range []string{"a", "b"} {
+		require.NoError(t, cache.Put(key, &Response{Content: key}))
+		modTime := time.Now().Add(time.Duration(i-2) * time.Minute)
+		require.NoError(t, os.Chtimes(filepath.Join(dir, key+responseCacheFileExt), modTime, modTime))
+	}
+	require.NoError(t, cache.Put("c", &Response{Content: "c"}))
+
+	_, ok := cache.Get("a")
+	assert.False(t, ok)
+	_, ok = cache.Get("b")
+	assert.True(t, ok)
+	_, ok = cache.Get("c")
+	assert.True(t, ok)
+}
+
+func TestResponseCache_MissingDirectory(t *testing.T) {
+	cache := NewResponseCache(filepath.Join(t.TempDir(), "missing"), time.Hour, 16)
+	_, ok := cache.Get("key")
+	assert.False(t, ok)
+}
+
+func TestNewProvider_ResponseCache(t *testing.T) {
+	t.Setenv(registry.OrlaHomeEnvVar, t.TempDir())
+
+	provider, err := NewProvider("ollama:llama3", &config.OrlaConfig{
+		Model:                   "ollama:llama3",
+		ResponseCache:           true,
+		ResponseCacheTTL:        60,
+		ResponseCacheMaxEntries: 4,
+	})
+	require.NoError(t, err)
+	caching, ok := provider.(*CachingProvider)
+	require.True(t, ok)
+	assert.Equal(t, "ollama", caching.Name())
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// maxSSELineSize bounds a single server-sent event line; content chunks and tool call
+	// argument deltas are far smaller in practice
+	maxSSELineSize = 1024 * 1024
+	sseDataPrefix  = "data:"
+)
+
+// readSSEData calls handle with the data of each server-sent event in r, until handle returns
+// false or the stream ends. Blank separators, comments, and other fields such as event names
+// are skipped, since the hosted provider APIs repeat the event type in the data.
+func readSSEData(r io.Reader, handle func(data string) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSSELineSize)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), sseDataPrefix)
+		if !ok {
+			continue
+		}
+		if !handle(strings.TrimSpace(data)) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// apiErrorResponse is the error body returned by the OpenAI and Anthropic APIs
+type apiErrorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// providerAPIError builds an error from a non-success API response, using the message from the
+// error body when there is one
+func providerAPIError(providerName string, resp *http.Response) error {
+	body, readErr := io.ReadAll(resp.Body)
+	if readErr != nil {
+		return fmt.Errorf("%s API error: %d (failed to read response body: %w)", providerName, resp.StatusCode, readErr)
+	}
+
+	var errResp apiErrorResponse
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Message != "" {
+		return fmt.Errorf("%s API error: %d - %s", providerName, resp.StatusCode, errResp.Error.Message)
+	}
+	return fmt.Errorf("%s API error: %d - %s", providerName, resp.StatusCode, string(body))
+}
@@ -23,9 +23,12 @@ func (r MessageRole) String() string {
 
 // Message represents a chat message in a conversation
 type Message struct {
-	Role     MessageRole `json:"role"`                // "user", "assistant", "system", or "tool"
-	Content  string      `json:"content"`             // Message content
-	ToolName string      `json:"tool_name,omitempty"` // Tool name (required when role is "tool")
+	Role       MessageRole      `json:"role"`                   // "user", "assistant", "system", or "tool"
+	Content    string           `json:"content"`                // Message content
+	ToolName   string           `json:"tool_name,omitempty"`    // Tool name (required when role is "tool")
+	ToolCalls  []ToolCallWithID `json:"tool_calls,omitempty"`   // Tool calls requested by the model (assistant messages)
+	ToolCallID string           `json:"tool_call_id,omitempty"` // ID of the tool call this message answers (tool messages)
+	Thinking   string           `json:"thinking,omitempty"`     // Thinking trace of the model (assistant messages, only with keep_thinking)
 }
 
 // ToolCallWithID represents a tool invocation request from the model.
@@ -68,6 +71,12 @@ type Provider interface {
 	EnsureReady(ctx context.Context) error
 }
 
+// ModelChecker is implemented by providers that can verify the configured model is available
+type ModelChecker interface {
+	// CheckModel returns an error if the configured model is not available from the provider
+	CheckModel(ctx context.Context) error
+}
+
 // StreamWriter is an interface for writing streaming responses
 type StreamWriter interface {
 	io.Writer
@@ -84,9 +93,10 @@ type StreamEvent interface {
 type StreamEventType string
 
 const (
-	StreamEventTypeContent  StreamEventType = "content"  // Text content chunk
-	StreamEventTypeToolCall StreamEventType = "toolcall" // Tool call notification
-	StreamEventTypeThinking StreamEventType = "thinking" // Thinking trace chunk
+	StreamEventTypeContent      StreamEventType = "content"      // Text content chunk
+	StreamEventTypeToolCall     StreamEventType = "toolcall"     // Tool call notification
+	StreamEventTypeThinking     StreamEventType = "thinking"     // Thinking trace chunk
+	StreamEventTypeToolProgress StreamEventType = "toolprogress" // Progress notification from a running tool
 )
 
 // ContentEvent represents a content chunk in the stream
@@ -116,3 +126,16 @@ type ThinkingEvent struct {
 func (e *ThinkingEvent) Type() StreamEventType {
 	return StreamEventTypeThinking
 }
+
+// ToolProgressEvent represents a progress notification sent by a tool while it runs. Orla
+// tools send chunks of their output as the message.
+type ToolProgressEvent struct {
+	Name     string
+	Message  string
+	Progress float64
+	Total    float64 // 0 if the total is unknown
+}
+
+func (e *ToolProgressEvent) Type() StreamEventType {
+	return StreamEventTypeToolProgress
+}
@@ -0,0 +1,167 @@
+// Package monitor implements the data-fetch and rendering layer of orla top,
+// a live view of a running orla server's tools, capsules, and tool calls.
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/server"
+)
+
+const (
+	// DefaultRefreshInterval is the default interval between refreshes of the live view
+	DefaultRefreshInterval = 2 * time.Second
+	// maxRecentCallsShown is the maximum number of recent calls rendered
+	maxRecentCallsShown = 15
+	// clearScreen moves the cursor home and clears the terminal
+	clearScreen = "\033[H\033[2J"
+)
+
+// AdminStateURL returns the admin state endpoint URL for a server listening on the given port
+func AdminStateURL(port int) string {
+	return fmt.Sprintf("http://localhost:%d%s", port, server.AdminStatePath)
+}
+
+// FetchAdminState fetches the admin state snapshot from the given admin state endpoint URL,
+// authenticating with the server's admin_token if token is not empty
+func FetchAdminState(ctx context.Context, client *http.Client, url string, token string) (*server.AdminState, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", server.AdminAuthorization(token))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach orla server at %s (is orla serve running?): %w", url, err)
+	}
+	defer core.LogDeferredError(resp.Body.Close)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		// The server says why, e.g. that admin_enabled is not set
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("admin endpoint refused the request with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	default:
+		return nil, fmt.Errorf("admin endpoint returned status %d", resp.StatusCode)
+	}
+
+	var state server.AdminState
+	if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+		return nil, fmt.Errorf("failed to decode admin state: %w", err)
+	}
+
+	return &state, nil
+}
+
+// Render writes a human-readable view of the admin state to w
+func Render(w io.Writer, state *server.AdminState) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	core.MustFprintf(tw, "orla top - %s\n\n", state.GeneratedAt.Local().Format(time.TimeOnly))
+
+	core.MustFprintf(tw, "TOOLS (%d)\n", len(state.Tools))
+	core.MustFprintf(tw, "NAME\tMODE\tCAPSULE\n")
+	for _, tool := range state.Tools {
+		capsuleState := tool.CapsuleState
+		if capsuleState == "" {
+			capsuleState = "-"
+		}
+		core.MustFprintf(tw, "%s\t%s\t%s\n", tool.Name, tool.RuntimeMode, capsuleState)
+	}
+
+	core.MustFprintf(tw, "\nIN-FLIGHT (%d)\n", len(state.InFlight))
+	core.MustFprintf(tw, "ID\tTOOL\tELAPSED\n")
+	for _, call := range state.InFlight {
+		core.MustFprintf(tw, "%d\t%s\t%s\n", call.ID, call.Tool, formatDurationMs(call.DurationMs))
+	}
+
+	recent := state.Recent
+	if len(recent) > maxRecentCallsShown {
+		recent = recent[:maxRecentCallsShown]
+	}
+	core.MustFprintf(tw, "\nRECENT (%d)\n", len(state.Recent))
+	core.MustFprintf(tw, "ID\tTOOL\tLATENCY\tSTATUS\n")
+	for _, call := range recent {
+		status := "ok"
+		if call.IsError {
+			status = "error"
+		}
+		core.MustFprintf(tw, "%d\t%s\t%s\t%s\n", call.ID, call.Tool, formatDurationMs(call.DurationMs), status)
+	}
+
+	return tw.Flush()
+}
+
+// formatDurationMs formats a duration in milliseconds for display
+func formatDurationMs(ms float64) string {
+	d := time.Duration(ms * float64(time.Millisecond))
+	if d < time.Second {
+		return d.Round(time.Microsecond * 100).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+// RunOptions configures the live view
+type RunOptions struct {
+	URL      string
+	Token    string // admin_token of the server, if it sets one
+	Interval time.Duration
+	Once     bool // render a single snapshot and return
+	Clear    bool // clear the screen between refreshes (only useful on a terminal)
+	Writer   io.Writer
+	Client   *http.Client
+}
+
+// Run renders the admin state every opts.Interval until ctx is cancelled
+func Run(ctx context.Context, opts RunOptions) error {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultRefreshInterval
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: opts.Interval}
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		state, err := FetchAdminState(ctx, opts.Client, opts.URL, opts.Token)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		var frame strings.Builder
+		if opts.Clear {
+			frame.WriteString(clearScreen)
+		}
+		if err := Render(&frame, state); err != nil {
+			return fmt.Errorf("failed to render admin state: %w", err)
+		}
+		core.MustFprintf(opts.Writer, "%s", frame.String())
+
+		if opts.Once {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
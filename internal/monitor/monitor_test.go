@@ -0,0 +1,161 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/server"
+)
+
+const cannedAdminState = `{
+  "generated_at": "2026-01-02T15:04:05Z",
+  "tools": [
+    {"name": "fs", "runtime_mode": "simple"},
+    {"name": "watcher", "runtime_mode": "capsule", "capsule_state": "READY"}
+  ],
+  "in_flight": [
+    {"id": 7, "tool": "watcher", "started_at": "2026-01-02T15:04:04Z", "duration_ms": 1250, "is_error": false}
+  ],
+  "recent": [
+    {"id": 6, "tool": "fs", "started_at": "2026-01-02T15:04:03Z", "duration_ms": 12.5, "is_error": false},
+    {"id": 5, "tool": "fs", "started_at": "2026-01-02T15:04:02Z", "duration_ms": 3, "is_error": true}
+  ]
+}`
+
+func newCannedServer(t *testing.T, status int, body string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, server.AdminStatePath, r.URL.Path)
+		w.WriteHeader(status)
+		_, err := w.Write([]byte(body))
+		assert.NoError(t, err)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestAdminStateURL(t *testing.T) {
+	assert.Equal(t, "http://localhost:9090/admin/state", AdminStateURL(9090))
+}
+
+func TestFetchAdminState(t *testing.T) {
+	srv := newCannedServer(t, http.StatusOK, cannedAdminState)
+
+	state, err := FetchAdminState(context.Background(), srv.Client(), srv.URL+server.AdminStatePath, "")
+	require.NoError(t, err)
+
+	require.Len(t, state.Tools, 2)
+	assert.Equal(t, "watcher", state.Tools[1].Name)
+	assert.Equal(t, "READY", state.Tools[1].CapsuleState)
+	require.Len(t, state.InFlight, 1)
+	assert.Equal(t, int64(7), state.InFlight[0].ID)
+	require.Len(t, state.Recent, 2)
+	assert.True(t, state.Recent[1].IsError)
+}
+
+func TestFetchAdminState_Errors(t *testing.T) {
+	srv := newCannedServer(t, http.StatusInternalServerError, "boom")
+	_, err := FetchAdminState(context.Background(), srv.Client(), srv.URL+server.AdminStatePath, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 500")
+
+	srv = newCannedServer(t, http.StatusOK, "not json")
+	_, err = FetchAdminState(context.Background(), srv.Client(), srv.URL+server.AdminStatePath, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decode admin state")
+
+	_, err = FetchAdminState(context.Background(), http.DefaultClient, "http://127.0.0.1:1/admin/state", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is orla serve running?")
+}
+
+func TestFetchAdminState_Token(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != server.AdminAuthorization("s3cret") {
+			http.Error(w, "missing or invalid admin token", http.StatusUnauthorized)
+			return
+		}
+		_, err := w.Write([]byte(cannedAdminState))
+		assert.NoError(t, err)
+	}))
+	t.Cleanup(srv.Close)
+
+	state, err := FetchAdminState(context.Background(), srv.Client(), srv.URL+server.AdminStatePath, "s3cret")
+	require.NoError(t, err)
+	assert.Len(t, state.Tools, 2)
+
+	_, err = FetchAdminState(context.Background(), srv.Client(), srv.URL+server.AdminStatePath, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 401: missing or invalid admin token")
+}
+
+func TestRender(t *testing.T) {
+	srv := newCannedServer(t, http.StatusOK, cannedAdminState)
+	state, err := FetchAdminState(context.Background(), srv.Client(), srv.URL+server.AdminStatePath, "")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, Render(&buf, state))
+	out := buf.String()
+
+	assert.Contains(t, out, "TOOLS (2)")
+	assert.Regexp(t, `fs\s+simple\s+-`, out)
+	assert.Regexp(t, `watcher\s+capsule\s+READY`, out)
+	assert.Contains(t, out, "IN-FLIGHT (1)")
+	assert.Regexp(t, `7\s+watcher\s+1.25s`, out)
+	assert.Contains(t, out, "RECENT (2)")
+	assert.Regexp(t, `6\s+fs\s+12.5ms\s+ok`, out)
+	assert.Regexp(t, `5\s+fs\s+3ms\s+error`, out)
+}
+
+func TestRender_LimitsRecentCalls(t *testing.T) {
+	state := &server.AdminState{GeneratedAt: time.Now()}
+	for i := 0; i < maxRecentCallsShown+5; i++ {
+		state.Recent = append(state.Recent, server.CallRecord{ID: int64(100 + i), Tool: "fs"})
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Render(&buf, state))
+	assert.Contains(t, buf.String(), "RECENT (20)")
+	assert.Contains(t, buf.String(), "114")
+	assert.NotContains(t, buf.String(), "115")
+}
+
+func TestRun_Once(t *testing.T) {
+	srv := newCannedServer(t, http.StatusOK, cannedAdminState)
+
+	var buf bytes.Buffer
+	err := Run(context.Background(), RunOptions{
+		URL:    srv.URL + server.AdminStatePath,
+		Once:   true,
+		Clear:  true,
+		Writer: &buf,
+		Client: srv.Client(),
+	})
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte(clearScreen)))
+	assert.Contains(t, buf.String(), "TOOLS (2)")
+}
+
+func TestRun_StopsOnContextCancel(t *testing.T) {
+	srv := newCannedServer(t, http.StatusOK, cannedAdminState)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var buf bytes.Buffer
+	err := Run(ctx, RunOptions{
+		URL:      srv.URL + server.AdminStatePath,
+		Interval: 10 * time.Millisecond,
+		Writer:   &buf,
+		Client:   srv.Client(),
+	})
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "TOOLS (2)")
+}
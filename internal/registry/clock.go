@@ -0,0 +1,27 @@
+package registry
+
+import (
+	"time"
+
+	"github.com/jonboulle/clockwork"
+)
+
+// defaultClock is the clock the age of cached registries and tag lists is measured with
+var defaultClock clockwork.Clock = clockwork.NewRealClock()
+
+// GetDefaultClock returns the clock used for cache expiry
+func GetDefaultClock() clockwork.Clock {
+	return defaultClock
+}
+
+// SetClock sets the clock used for cache expiry (used for testing). Tests can pass a
+// clockwork.FakeClock started at the current time and advance it past RegistryCacheTTL to expire
+// the cache, instead of changing the modification times of cache files.
+func SetClock(clock clockwork.Clock) {
+	defaultClock = clock
+}
+
+// cacheExpired reports whether a cache file last written at modTime is older than RegistryCacheTTL
+func cacheExpired(modTime time.Time) bool {
+	return defaultClock.Since(modTime) > RegistryCacheTTL
+}
@@ -0,0 +1,31 @@
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+)
+
+// useFakeClock replaces the cache expiry clock with a fake clock at the current time for the
+// duration of the test. Advancing it ages the cache files written during the test.
+func useFakeClock(t *testing.T) *clockwork.FakeClock {
+	t.Helper()
+	clock := clockwork.NewFakeClockAt(time.Now())
+	original := GetDefaultClock()
+	SetClock(clock)
+	t.Cleanup(func() { SetClock(original) })
+	return clock
+}
+
+func TestCacheExpired(t *testing.T) {
+	clock := useFakeClock(t)
+	modTime := clock.Now()
+
+	assert.False(t, cacheExpired(modTime))
+	clock.Advance(RegistryCacheTTL)
+	assert.False(t, cacheExpired(modTime), "a cache exactly RegistryCacheTTL old is still fresh")
+	clock.Advance(time.Second)
+	assert.True(t, cacheExpired(modTime))
+}
@@ -0,0 +1,204 @@
+package registry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// Version range constraints
+//
+// Besides "latest" and exact tags, a tool can be installed at the highest version tag that
+// satisfies a range constraint. A constraint is a space separated list of comparators that must
+// all hold, and alternatives can be joined with "||":
+//
+//	^1.2.3   >=1.2.3 <2.0.0 (^0.2.3 is >=0.2.3 <0.3.0, ^0.0.3 is >=0.0.3 <0.0.4)
+//	~1.2.3   >=1.2.3 <1.3.0 (~1 is >=1.0.0 <2.0.0)
+//	>=1.0.0 <2.0.0, >1.0, <=2, =1.2.3
+//
+// Versions may leave out the minor and patch numbers, which are then 0, and may have a 'v' prefix.
+// Pre-release tags only match a range that names a pre-release of the same version, so
+// ^1.2.0-beta matches v1.2.0-rc.1 but not v1.3.0-beta.
+
+// versionConstraintOperators are the characters a range constraint starts with
+const versionConstraintOperators = "^~<>="
+
+// versionComparator is a single comparison of a version against a canonical semver version
+type versionComparator struct {
+	op      string
+	version string
+}
+
+// matches reports whether version, a canonical semver version, satisfies the comparator
+func (c versionComparator) matches(version string) bool {
+	cmp := semver.Compare(version, c.version)
+	switch c.op {
+	case ">=":
+		return cmp >= 0
+	case ">":
+		return cmp > 0
+	case "<=":
+		return cmp <= 0
+	case "<":
+		return cmp < 0
+	default:
+		return cmp == 0
+	}
+}
+
+// versionConstraint is a parsed range constraint: a version matches it if it satisfies every
+// comparator of any one of its alternatives
+type versionConstraint struct {
+	alternatives [][]versionComparator
+}
+
+// Matches reports whether version, a canonical semver version, satisfies the constraint
+func (c *versionConstraint) Matches(version string) bool {
+	for _, comparators := range c.alternatives {
+		if alternativeMatches(comparators, version) {
+			return true
+		}
+	}
+	return false
+}
+
+// alternativeMatches reports whether version satisfies every comparator of an alternative. A
+// pre-release version must also share its release with a pre-release named by the alternative.
+func alternativeMatches(comparators []versionComparator, version string) bool {
+	prerelease := semver.Prerelease(version)
+	allowed := prerelease == ""
+	for _, comparator := range comparators {
+		if !comparator.matches(version) {
+			return false
+		}
+		if comparatorPrerelease := semver.Prerelease(comparator.version); comparatorPrerelease != "" &&
+			strings.TrimSuffix(comparator.version, comparatorPrerelease) == strings.TrimSuffix(version, prerelease) {
+			allowed = true
+		}
+	}
+	return allowed
+}
+
+// IsVersionRange reports whether constraint is a range constraint (e.g. "^1.2.0" or ">=1.0.0 <2.0.0")
+// rather than "latest" or an exact tag
+func IsVersionRange(constraint string) bool {
+	return strings.ContainsAny(constraint, versionConstraintOperators) || strings.Contains(constraint, "||")
+}
+
+// MatchesVersionRange reports whether version (e.g. "v1.2.3") satisfies the range constraint
+func MatchesVersionRange(constraint, version string) (bool, error) {
+	parsed, err := parseVersionConstraint(constraint)
+	if err != nil {
+		return false, fmt.Errorf("invalid version constraint '%s': %w", constraint, err)
+	}
+	return semver.IsValid(version) && parsed.Matches(semver.Canonical(version)), nil
+}
+
+// parseVersionConstraint parses a range constraint
+func parseVersionConstraint(constraint string) (*versionConstraint, error) {
+	parsed := &versionConstraint{}
+	for _, alternative := range strings.Split(constraint, "||") {
+		fields := strings.Fields(alternative)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("empty version range")
+		}
+
+		var comparators []versionComparator
+		for _, field := range fields {
+			fieldComparators, err := parseVersionComparator(field)
+			if err != nil {
+				return nil, err
+			}
+			comparators = append(comparators, fieldComparators...)
+		}
+		parsed.alternatives = append(parsed.alternatives, comparators)
+	}
+	return parsed, nil
+}
+
+// parseVersionComparator parses one comparator of a range constraint. Caret and tilde ranges
+// expand to a lower and an upper bound.
+func parseVersionComparator(field string) ([]versionComparator, error) {
+	op := ""
+	for _, candidate := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(field, candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return nil, fmt.Errorf("'%s' has no operator (use ^, ~, >=, >, <=, <, or =)", field)
+	}
+
+	version, err := parseConstraintVersion(strings.TrimPrefix(field, op))
+	if err != nil {
+		return nil, err
+	}
+
+	major, minor, patch := version.numbers[0], version.numbers[1], version.numbers[2]
+	var upper string
+	switch op {
+	case "^":
+		// The upper bound bumps the leftmost non-zero number written
+		switch {
+		case major > 0 || version.written == 1:
+			upper = fmt.Sprintf("v%d.0.0", major+1)
+		case minor > 0 || version.written == 2:
+			upper = fmt.Sprintf("v0.%d.0", minor+1)
+		default:
+			upper = fmt.Sprintf("v0.0.%d", patch+1)
+		}
+	case "~":
+		// The upper bound bumps the minor number, or the major number if only it was written
+		upper = fmt.Sprintf("v%d.%d.0", major, minor+1)
+		if version.written == 1 {
+			upper = fmt.Sprintf("v%d.0.0", major+1)
+		}
+	default:
+		return []versionComparator{{op: op, version: version.canonical}}, nil
+	}
+	return []versionComparator{{op: ">=", version: version.canonical}, {op: "<", version: upper}}, nil
+}
+
+// constraintVersion is a possibly partial version written in a range constraint
+type constraintVersion struct {
+	// canonical is the version as a canonical semver version, with missing numbers set to 0
+	canonical string
+	// numbers are the major, minor, and patch numbers
+	numbers [3]int
+	// written is how many of the numbers were written
+	written int
+}
+
+// parseConstraintVersion parses a possibly partial version such as "1.2", "v1.2.3", or "1.2.0-beta"
+func parseConstraintVersion(raw string) (*constraintVersion, error) {
+	notAVersion := fmt.Errorf("'%s' is not a version (e.g. 1.2.0)", raw)
+
+	numbersPart, suffix := strings.TrimPrefix(raw, "v"), ""
+	if i := strings.IndexAny(numbersPart, "-+"); i >= 0 {
+		numbersPart, suffix = numbersPart[:i], numbersPart[i:]
+	}
+
+	numbers := strings.Split(numbersPart, ".")
+	if len(numbers) > 3 {
+		return nil, notAVersion
+	}
+
+	version := &constraintVersion{written: len(numbers)}
+	for i, number := range numbers {
+		n, err := strconv.Atoi(number)
+		if err != nil || n < 0 {
+			return nil, notAVersion
+		}
+		version.numbers[i] = n
+	}
+
+	canonical := fmt.Sprintf("v%d.%d.%d%s", version.numbers[0], version.numbers[1], version.numbers[2], suffix)
+	if !semver.IsValid(canonical) {
+		return nil, notAVersion
+	}
+	version.canonical = semver.Canonical(canonical)
+	return version, nil
+}
@@ -0,0 +1,129 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveVersion_Range(t *testing.T) {
+	tags := []string{
+		"v0.0.3", "v0.0.4", "v0.2.3", "v0.2.9", "v0.3.0",
+		"v1.0.0", "v1.2.0", "v1.2.5", "v1.3.0-beta.1", "v1.3.0", "v1.4.0-rc.1",
+		"v2.0.0-alpha", "v2.0.0", "v2.1.0-beta",
+		"not-a-version",
+	}
+	useTagCacheTest(t, tags...)
+	tool := &ToolEntry{Name: "fs", Repository: "https://example.com/orla-tool-fs"}
+
+	tests := []struct {
+		constraint string
+		expected   string
+	}{
+		// Caret ranges allow changes that do not modify the leftmost non-zero number
+		{constraint: "^1.2.0", expected: "v1.3.0"},
+		{constraint: "^v1.2.0", expected: "v1.3.0"},
+		{constraint: "^1", expected: "v1.3.0"},
+		{constraint: "^0.2.3", expected: "v0.2.9"},
+		{constraint: "^0.0.3", expected: "v0.0.3"},
+		{constraint: "^0.0", expected: "v0.0.4"},
+		{constraint: "^0", expected: "v0.3.0"},
+		// Tilde ranges allow patch changes, or minor changes if only the major number is given
+		{constraint: "~1.2.0", expected: "v1.2.5"},
+		{constraint: "~1.2", expected: "v1.2.5"},
+		{constraint: "~1", expected: "v1.3.0"},
+		{constraint: "~0.2.3", expected: "v0.2.9"},
+		// Comparison operators, combined with spaces
+		{constraint: ">=1.0.0 <2.0.0", expected: "v1.3.0"},
+		{constraint: ">=1.0.0 <1.3.0", expected: "v1.2.5"},
+		{constraint: ">1.2.0 <=1.2.5", expected: "v1.2.5"},
+		{constraint: ">=1.0", expected: "v2.0.0"},
+		{constraint: "<1", expected: "v0.3.0"},
+		{constraint: "=1.2.0", expected: "v1.2.0"},
+		// Alternatives
+		{constraint: "^0.2.0 || ~1.2.0", expected: "v1.2.5"},
+		{constraint: "<0.1.0 || =0.2.3", expected: "v0.2.3"},
+		// Pre-releases only match ranges naming a pre-release of the same version
+		{constraint: ">=1.3.0-beta <1.3.0", expected: "v1.3.0-beta.1"},
+		{constraint: "^1.4.0-rc", expected: "v1.4.0-rc.1"},
+		{constraint: "^2.1.0-alpha", expected: "v2.1.0-beta"},
+		{constraint: ">=2.0.0-alpha <2.1.0", expected: "v2.0.0"},
+		{constraint: "^1.2.0-beta", expected: "v1.3.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			tag, err := ResolveVersion(tool, tt.constraint, true)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, tag)
+		})
+	}
+}
+
+func TestResolveVersion_RangeErrors(t *testing.T) {
+	useTagCacheTest(t, "v0.1.0", "v1.0.0", "v1.1.0-beta")
+	tool := &ToolEntry{Name: "fs", Repository: "https://example.com/orla-tool-fs"}
+
+	tests := []struct {
+		constraint string
+		expected   string
+	}{
+		{constraint: "^2.0.0", expected: "no version of tool 'fs' matches '^2.0.0'"},
+		{constraint: ">=1.1.0", expected: "no version of tool 'fs' matches '>=1.1.0'"},
+		{constraint: "^1.x", expected: "invalid version constraint '^1.x': '1.x' is not a version"},
+		{constraint: ">=", expected: "invalid version constraint '>=': '' is not a version"},
+		{constraint: "^1.2.3.4", expected: "'1.2.3.4' is not a version"},
+		{constraint: ">=1.0.0 2.0.0", expected: "'2.0.0' has no operator"},
+		{constraint: "^1.0.0 ||", expected: "empty version range"},
+		{constraint: "=>1.0.0", expected: "'>1.0.0' is not a version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			_, err := ResolveVersion(tool, tt.constraint, true)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestResolveVersion_ExactAndLatestUnchanged(t *testing.T) {
+	calls := useTagCacheTest(t, "v1.0.0", "v1.1.0", "v1.2.0-beta")
+	tool := &ToolEntry{Name: "fs", Repository: "https://example.com/orla-tool-fs"}
+
+	tag, err := ResolveVersion(tool, "v9.9.9", true)
+	require.NoError(t, err)
+	assert.Equal(t, "v9.9.9", tag, "exact tags are returned without listing tags")
+	assert.Zero(t, *calls)
+
+	tag, err = ResolveVersion(tool, VersionConstraintLatest, true)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.1.0", tag)
+}
+
+func TestMatchesVersionRange(t *testing.T) {
+	matches, err := MatchesVersionRange("^2.0.0", "v2.1.0")
+	require.NoError(t, err)
+	assert.True(t, matches)
+
+	matches, err = MatchesVersionRange("^2.0.0", "v3.0.0")
+	require.NoError(t, err)
+	assert.False(t, matches)
+
+	matches, err = MatchesVersionRange("^2.0.0", "not-a-version")
+	require.NoError(t, err)
+	assert.False(t, matches)
+
+	_, err = MatchesVersionRange("^two", "v2.0.0")
+	assert.Error(t, err)
+}
+
+func TestIsVersionRange(t *testing.T) {
+	for _, constraint := range []string{"^1.2.0", "~1.2", ">=1.0.0 <2.0.0", "=1.0.0", "<2"} {
+		assert.True(t, IsVersionRange(constraint), constraint)
+	}
+	for _, constraint := range []string{"", "latest", "v1.2.0", "1.2.0"} {
+		assert.False(t, IsVersionRange(constraint), constraint)
+	}
+}
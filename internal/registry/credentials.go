@@ -0,0 +1,194 @@
+package registry
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// NetrcEnvVar is the environment variable that overrides the location of the .netrc file
+const NetrcEnvVar = "NETRC"
+
+// Credentials are the login and password used to authenticate to a registry host
+type Credentials struct {
+	Login    string
+	Password string
+}
+
+// GetCredentialsPath returns the path of the orla credentials file (~/.orla/credentials by default)
+func GetCredentialsPath() (string, error) {
+	orlaHome, err := GetOrlaHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get orla home directory: %w", err)
+	}
+	return filepath.Join(orlaHome, "credentials"), nil
+}
+
+// getNetrcPath returns the path of the .netrc file: $NETRC if set, otherwise ~/.netrc
+func getNetrcPath() (string, error) {
+	if netrcPath := os.Getenv(NetrcEnvVar); netrcPath != "" {
+		return netrcPath, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".netrc"), nil
+}
+
+// LoadCredentials reads registry credentials keyed by host from the orla credentials file and
+// the .netrc file, both in netrc format. Missing files are ignored, and entries in the orla
+// credentials file take precedence over entries for the same host in .netrc.
+func LoadCredentials() (map[string]Credentials, error) {
+	credentialsPath, err := GetCredentialsPath()
+	if err != nil {
+		return nil, err
+	}
+	netrcPath, err := getNetrcPath()
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make(map[string]Credentials)
+	// Read .netrc first so that the orla credentials file overrides it
+	for _, path := range []string{netrcPath, credentialsPath} {
+		// #nosec G304 -- path is the user's credentials file
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read credentials file %s: %w", path, err)
+		}
+
+		for host, creds := range parseNetrc(string(data)) {
+			credentials[host] = creds
+		}
+	}
+
+	return credentials, nil
+}
+
+// parseNetrc parses netrc-format data into credentials keyed by machine name. The default
+// entry and macro definitions are ignored, since credentials are only applied to named hosts.
+func parseNetrc(data string) map[string]Credentials {
+	credentials := make(map[string]Credentials)
+
+	var tokens []string
+	inMacdef := false
+	for line := range strings.SplitSeq(data, "\n") {
+		fields := strings.Fields(line)
+		// A macro definition runs until the next blank line
+		if inMacdef {
+			inMacdef = len(fields) > 0
+			continue
+		}
+		for _, field := range fields {
+			if strings.HasPrefix(field, "#") {
+				break
+			}
+			if field == "macdef" {
+				inMacdef = true
+				break
+			}
+			tokens = append(tokens, field)
+		}
+	}
+
+	var host string
+	var creds Credentials
+	flush := func() {
+		if host != "" {
+			credentials[host] = creds
+		}
+		host, creds = "", Credentials{}
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		var value string
+		if i+1 < len(tokens) {
+			value = tokens[i+1]
+		}
+
+		switch tokens[i] {
+		case "machine":
+			flush()
+			host = value
+			i++
+		case "default":
+			flush()
+		case "login":
+			creds.Login = value
+			i++
+		case "password":
+			creds.Password = value
+			i++
+		case "account":
+			i++
+		}
+	}
+	flush()
+
+	return credentials
+}
+
+// gitCredentialEnv returns GIT_CONFIG_* environment variables that make git send creds as an
+// HTTP Authorization header to https://host/. The header is only configured for https, so the
+// credentials are never sent in plaintext. Entries are appended after any GIT_CONFIG_* variables
+// already set in environ.
+func gitCredentialEnv(environ []string, host string, creds Credentials) []string {
+	index := 0
+	for _, entry := range environ {
+		if value, ok := strings.CutPrefix(entry, "GIT_CONFIG_COUNT="); ok {
+			if count, err := strconv.Atoi(value); err == nil && count > 0 {
+				index = count
+			}
+		}
+	}
+
+	token := base64.StdEncoding.EncodeToString([]byte(creds.Login + ":" + creds.Password))
+	return []string{
+		fmt.Sprintf("GIT_CONFIG_KEY_%d=http.https://%s/.extraHeader", index, host),
+		fmt.Sprintf("GIT_CONFIG_VALUE_%d=Authorization: Basic %s", index, token),
+		fmt.Sprintf("GIT_CONFIG_COUNT=%d", index+1),
+	}
+}
+
+// GitCommandEnv returns the environment for a git command that fetches from remoteURL: the
+// current environment plus the registry credentials for the URL's host, if it is an https URL
+// and there are any. Credentials for other hosts are never passed to git. Failing to read the
+// credentials is logged, and git runs without them.
+func GitCommandEnv(remoteURL string) []string {
+	environ := os.Environ()
+
+	parsed, err := url.Parse(remoteURL)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return environ
+	}
+
+	credentials, err := LoadCredentials()
+	if err != nil {
+		zap.L().Warn("Failed to load registry credentials, fetching without them", zap.Error(err))
+		return environ
+	}
+
+	// netrc entries name the host, optionally with the port
+	creds, ok := credentials[parsed.Host]
+	if !ok {
+		creds, ok = credentials[parsed.Hostname()]
+	}
+	if !ok {
+		return environ
+	}
+
+	return append(environ, gitCredentialEnv(environ, parsed.Host, creds)...)
+}
@@ -0,0 +1,183 @@
+package registry
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNetrc(t *testing.T) {
+	data := `# registry credentials
+machine git.example.com
+  login alice
+  password s3cret
+
+machine other.example.com login bob password hunter2 account ignored
+
+macdef init
+machine not-a-host login mallory password nope
+
+default login anonymous password guest
+`
+	credentials := parseNetrc(data)
+	assert.Equal(t, map[string]Credentials{
+		"git.example.com":   {Login: "alice", Password: "s3cret"},
+		"other.example.com": {Login: "bob", Password: "hunter2"},
+	}, credentials)
+}
+
+func TestParseNetrc_Empty(t *testing.T) {
+	assert.Empty(t, parseNetrc(""))
+	assert.Empty(t, parseNetrc("default login anonymous password guest\n"))
+}
+
+func TestLoadCredentials(t *testing.T) {
+	orlaHome := t.TempDir()
+	t.Setenv(OrlaHomeEnvVar, orlaHome)
+	netrcPath := filepath.Join(t.TempDir(), "netrc")
+	t.Setenv(NetrcEnvVar, netrcPath)
+
+	// No credentials files is not an error
+	credentials, err := LoadCredentials()
+	require.NoError(t, err)
+	assert.Empty(t, credentials)
+
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(netrcPath, []byte(
+		"machine git.example.com login netrc-user password netrc-pass\n"+
+			"machine netrc-only.example.com login carol password pass\n"), 0600))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(orlaHome, "credentials"), []byte(
+		"machine git.example.com login orla-user password orla-pass\n"), 0600))
+
+	credentials, err = LoadCredentials()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Credentials{
+		// The orla credentials file takes precedence over .netrc
+		"git.example.com":        {Login: "orla-user", Password: "orla-pass"},
+		"netrc-only.example.com": {Login: "carol", Password: "pass"},
+	}, credentials)
+}
+
+func TestGitCredentialEnv(t *testing.T) {
+	creds := Credentials{Login: "alice", Password: "s3cret"}
+	header := "Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte("alice:s3cret"))
+
+	assert.Equal(t, []string{
+		"GIT_CONFIG_KEY_0=http.https://git.example.com/.extraHeader",
+		"GIT_CONFIG_VALUE_0=" + header,
+		"GIT_CONFIG_COUNT=1",
+	}, gitCredentialEnv(nil, "git.example.com", creds))
+
+	// Entries already configured through the environment are kept
+	env := gitCredentialEnv([]string{"GIT_CONFIG_COUNT=1"}, "git.example.com", creds)
+	assert.Equal(t, "GIT_CONFIG_KEY_1=http.https://git.example.com/.extraHeader", env[0])
+	assert.Equal(t, "GIT_CONFIG_COUNT=2", env[len(env)-1])
+}
+
+// TestGitCommandEnv tests that git commands only get the credentials of the host they fetch
+// from, and only over https
+func TestGitCommandEnv(t *testing.T) {
+	orlaHome := t.TempDir()
+	t.Setenv(OrlaHomeEnvVar, orlaHome)
+	t.Setenv(NetrcEnvVar, filepath.Join(t.TempDir(), "netrc"))
+	t.Setenv("GIT_CONFIG_COUNT", "")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(orlaHome, "credentials"), []byte(
+		"machine git.example.com login alice password s3cret\n"+
+			"machine other.example.com login bob password other\n"), 0600))
+
+	credentialEnv := func(env []string) []string {
+		var entries []string
+		for _, entry := range env {
+			if strings.HasPrefix(entry, "GIT_CONFIG_KEY_") {
+				entries = append(entries, entry)
+			}
+		}
+		return entries
+	}
+
+	assert.Equal(t, []string{"GIT_CONFIG_KEY_0=http.https://git.example.com/.extraHeader"},
+		credentialEnv(GitCommandEnv("https://git.example.com/tools/fs.git")))
+	assert.Equal(t, []string{"GIT_CONFIG_KEY_0=http.https://git.example.com:8443/.extraHeader"},
+		credentialEnv(GitCommandEnv("https://git.example.com:8443/tools/fs.git")))
+	assert.Empty(t, credentialEnv(GitCommandEnv("http://git.example.com/tools/fs.git")))
+	assert.Empty(t, credentialEnv(GitCommandEnv("https://unknown.example.com/tools/fs.git")))
+	assert.Empty(t, credentialEnv(GitCommandEnv("")))
+}
+
+// TestExecGitRunner_AppliesCredentials tests that git commands send the credentials for the
+// registry host read from the credentials file
+func TestExecGitRunner_AppliesCredentials(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping git HTTP test on Windows")
+	}
+
+	var mu sync.Mutex
+	var authHeaders, plainAuthHeaders []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		mu.Unlock()
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+	plainSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		plainAuthHeaders = append(plainAuthHeaders, r.Header.Get("Authorization"))
+		mu.Unlock()
+		http.NotFound(w, r)
+	}))
+	defer plainSrv.Close()
+	// The test server's certificate is self-signed
+	t.Setenv("GIT_SSL_NO_VERIFY", "true")
+
+	serverURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	plainURL, err := url.Parse(plainSrv.URL)
+	require.NoError(t, err)
+
+	orlaHome := t.TempDir()
+	t.Setenv(OrlaHomeEnvVar, orlaHome)
+	t.Setenv(NetrcEnvVar, filepath.Join(t.TempDir(), "netrc"))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(orlaHome, "credentials"), []byte(
+		"machine "+serverURL.Host+" login alice password s3cret\n"+
+			"machine "+plainURL.Host+" login alice password s3cret\n"+
+			"machine unrelated.example.com login bob password other\n"), 0600))
+
+	runner := &execGitRunner{}
+
+	// The server rejects every request, so the commands fail after sending their first request
+	assert.Error(t, runner.Clone(srv.URL+"/registry.git", filepath.Join(t.TempDir(), "repo")))
+	_, err = runner.ListTags(srv.URL + "/tool.git")
+	assert.Error(t, err)
+	toolURL := srv.URL + "/tool.git"
+	_, err = runner.Run(toolURL, "", "clone", "--depth", "1", toolURL, filepath.Join(t.TempDir(), "tool"))
+	assert.Error(t, err)
+	_, err = runner.ListTags(plainSrv.URL + "/tool.git")
+	assert.Error(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.NotEmpty(t, authHeaders)
+	expected := "Basic " + base64.StdEncoding.EncodeToString([]byte("alice:s3cret"))
+	for _, header := range authHeaders {
+		assert.Equal(t, expected, header)
+	}
+	// Credentials are never sent over plain http
+	require.NotEmpty(t, plainAuthHeaders)
+	for _, header := range plainAuthHeaders {
+		assert.Empty(t, header)
+	}
+}
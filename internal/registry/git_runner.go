@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+	"sync"
 )
 
 // GitRunner is an interface for running git commands, allowing for testing with mocks
@@ -11,6 +12,10 @@ type GitRunner interface {
 	Clone(url, targetPath string) error
 	Pull(repoPath string) error
 	ListTags(repoURL string) ([]string, error)
+	// Run runs git with the given arguments in dir (or the current directory if dir is empty)
+	// and returns the combined stdout and stderr output. repoURL is the repository the command
+	// fetches from, whose registry credentials are passed to git.
+	Run(repoURL, dir string, args ...string) ([]byte, error)
 }
 
 // execGitRunner implements GitRunner using exec.Command
@@ -18,6 +23,7 @@ type execGitRunner struct{}
 
 func (e *execGitRunner) Clone(url, targetPath string) error {
 	cmd := exec.Command("git", "clone", "--depth", "1", url, targetPath)
+	cmd.Env = GitCommandEnv(url)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to clone registry repository: %w, output: %s", err, string(output))
@@ -28,12 +34,18 @@ func (e *execGitRunner) Clone(url, targetPath string) error {
 func (e *execGitRunner) Pull(repoPath string) error {
 	cmd := exec.Command("git", "pull")
 	cmd.Dir = repoPath
-	return cmd.Run()
+	cmd.Env = GitCommandEnv(originURL(repoPath))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to pull registry repository: %w, output: %s", err, string(output))
+	}
+	return nil
 }
 
 func (e *execGitRunner) ListTags(repoURL string) ([]string, error) {
 	// Use git ls-remote to list tags without cloning
 	cmd := exec.Command("git", "ls-remote", "--tags", "--refs", repoURL)
+	cmd.Env = GitCommandEnv(repoURL)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tags from repository: %w, output: %s", err, string(output))
@@ -57,6 +69,25 @@ func (e *execGitRunner) ListTags(repoURL string) ([]string, error) {
 	return tags, nil
 }
 
+func (e *execGitRunner) Run(repoURL, dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = GitCommandEnv(repoURL)
+	return cmd.CombinedOutput()
+}
+
+// originURL returns the URL of the origin remote of the repository at repoPath, or an empty
+// string if it has none
+func originURL(repoPath string) string {
+	cmd := exec.Command("git", "remote", "get-url", "origin")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
 // defaultGitRunner is the default GitRunner implementation
 var defaultGitRunner GitRunner = &execGitRunner{}
 
@@ -70,22 +101,37 @@ func SetGitRunner(runner GitRunner) {
 	defaultGitRunner = runner
 }
 
+// MockGitRunnerRunCall records a call to MockGitRunner.Run
+type MockGitRunnerRunCall struct {
+	RepoURL string
+	Dir     string
+	Args    []string
+}
+
 // MockGitRunner is a mock implementation of GitRunner for testing
-// It can be used across packages to test code that depends on GitRunner
+// It can be used across packages to test code that depends on GitRunner. Calls are recorded
+// under a lock, since installs may clone tools concurrently.
 type MockGitRunner struct {
 	CloneErr      error
 	PullErr       error
 	ListTagsErr   error
+	RunErr        error
 	CloneCalls    []struct{ URL, TargetPath string }
 	PullCalls     []string
 	ListTagsCalls []string
+	RunCalls      []MockGitRunnerRunCall
 	CloneFunc     func(url, targetPath string) error
 	PullFunc      func(repoPath string) error
 	ListTagsFunc  func(repoURL string) ([]string, error)
+	RunFunc       func(repoURL, dir string, args ...string) ([]byte, error)
+
+	mu sync.Mutex
 }
 
 func (m *MockGitRunner) Clone(url, targetPath string) error {
+	m.mu.Lock()
 	m.CloneCalls = append(m.CloneCalls, struct{ URL, TargetPath string }{url, targetPath})
+	m.mu.Unlock()
 	if m.CloneFunc != nil {
 		return m.CloneFunc(url, targetPath)
 	}
@@ -93,7 +139,9 @@ func (m *MockGitRunner) Clone(url, targetPath string) error {
 }
 
 func (m *MockGitRunner) Pull(repoPath string) error {
+	m.mu.Lock()
 	m.PullCalls = append(m.PullCalls, repoPath)
+	m.mu.Unlock()
 	if m.PullFunc != nil {
 		return m.PullFunc(repoPath)
 	}
@@ -101,12 +149,35 @@ func (m *MockGitRunner) Pull(repoPath string) error {
 }
 
 func (m *MockGitRunner) ListTags(repoURL string) ([]string, error) {
+	m.mu.Lock()
 	m.ListTagsCalls = append(m.ListTagsCalls, repoURL)
+	m.mu.Unlock()
 	if m.ListTagsFunc != nil {
 		return m.ListTagsFunc(repoURL)
 	}
 	return nil, m.ListTagsErr
 }
 
+func (m *MockGitRunner) Run(repoURL, dir string, args ...string) ([]byte, error) {
+	m.mu.Lock()
+	m.RunCalls = append(m.RunCalls, MockGitRunnerRunCall{RepoURL: repoURL, Dir: dir, Args: args})
+	m.mu.Unlock()
+	if m.RunFunc != nil {
+		return m.RunFunc(repoURL, dir, args...)
+	}
+	return nil, m.RunErr
+}
+
+// RunArgs returns the arguments of each call to Run, in order
+func (m *MockGitRunner) RunArgs() [][]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := make([][]string, 0, len(m.RunCalls))
+	for _, call := range m.RunCalls {
+		args = append(args, call.Args)
+	}
+	return args
+}
+
 // Interface guard
 var _ GitRunner = &MockGitRunner{}
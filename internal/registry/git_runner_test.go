@@ -131,7 +131,11 @@ func TestExecGitRunner_Pull(t *testing.T) {
 		runner := &execGitRunner{}
 		tmpDir := t.TempDir()
 
-		assert.Error(t, runner.Pull(tmpDir))
+		err := runner.Pull(tmpDir)
+		require.Error(t, err)
+		// git's stderr is surfaced in the error
+		assert.Contains(t, err.Error(), "failed to pull registry repository")
+		assert.Contains(t, err.Error(), "not a git repository")
 	})
 
 	t.Run("pull error - non-existent directory", func(t *testing.T) {
@@ -240,6 +244,33 @@ func TestMockGitRunner_Pull(t *testing.T) {
 	})
 }
 
+func TestMockGitRunner_Run(t *testing.T) {
+	t.Run("uses RunFunc when provided", func(t *testing.T) {
+		mock := &MockGitRunner{
+			RunFunc: func(repoURL, dir string, args ...string) ([]byte, error) {
+				assert.Equal(t, "https://example.com/tool.git", repoURL)
+				assert.Equal(t, "/tmp/tool", dir)
+				return []byte("output"), nil
+			},
+		}
+
+		output, err := mock.Run("https://example.com/tool.git", "/tmp/tool", "checkout", "v1.0.0")
+		assert.NoError(t, err)
+		assert.Equal(t, "output", string(output))
+		require.Len(t, mock.RunCalls, 1)
+		assert.Equal(t, "/tmp/tool", mock.RunCalls[0].Dir)
+		assert.Equal(t, [][]string{{"checkout", "v1.0.0"}}, mock.RunArgs())
+	})
+
+	t.Run("uses RunErr when RunFunc is nil", func(t *testing.T) {
+		mock := &MockGitRunner{RunErr: assert.AnError}
+
+		_, err := mock.Run("https://example.com/tool.git", "", "clone")
+		assert.Equal(t, assert.AnError, err)
+		assert.Len(t, mock.RunCalls, 1)
+	})
+}
+
 func TestGetDefaultGitRunner(t *testing.T) {
 	runner := GetDefaultGitRunner()
 	assert.NotNil(t, runner)
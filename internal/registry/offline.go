@@ -0,0 +1,35 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrOffline is wrapped by errors returned when offline mode prevents reaching the network
+var ErrOffline = errors.New("offline mode")
+
+// offline makes registry and tag lookups use only the cache, see SetOffline
+var offline atomic.Bool
+
+// SetOffline enables or disables offline mode. In offline mode, registry indexes and tool tags are
+// only read from the cache, even when it has expired, and git is never run; a lookup that is not
+// cached fails with an error wrapping ErrOffline.
+func SetOffline(enabled bool) {
+	offline.Store(enabled)
+}
+
+// Offline reports whether offline mode is enabled
+func Offline() bool {
+	return offline.Load()
+}
+
+// warnExpiredOfflineCache logs that an expired cache entry is used because orla is offline
+func warnExpiredOfflineCache(what, source string, modTime time.Time) {
+	zap.L().Warn(fmt.Sprintf("Using expired cached %s in offline mode", what),
+		zap.String("source", source),
+		zap.Duration("age", defaultClock.Since(modTime).Round(time.Second)))
+}
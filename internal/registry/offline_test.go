@@ -0,0 +1,133 @@
+package registry
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const offlineTestRegistryURL = "https://example.com/registry"
+
+// useOfflineTest enables offline mode, points the registry cache at a temporary directory, and
+// replaces the git runner with one that records calls, which offline mode should never make
+func useOfflineTest(t *testing.T) *MockGitRunner {
+	t.Helper()
+
+	SetOffline(true)
+	t.Cleanup(func() { SetOffline(false) })
+
+	cacheDir := t.TempDir()
+	originalGetCacheDir := getRegistryCacheDirFunc
+	getRegistryCacheDirFunc = func() (string, error) { return cacheDir, nil }
+	t.Cleanup(func() { getRegistryCacheDirFunc = originalGetCacheDir })
+
+	runner := &MockGitRunner{}
+	originalRunner := defaultGitRunner
+	defaultGitRunner = runner
+	t.Cleanup(func() { defaultGitRunner = originalRunner })
+
+	return runner
+}
+
+// cacheOfflineTestRegistry writes a cached registry index for offlineTestRegistryURL, last
+// written age ago
+func cacheOfflineTestRegistry(t *testing.T, age time.Duration) {
+	t.Helper()
+
+	cacheDir, err := getRegistryCacheDirFunc()
+	require.NoError(t, err)
+	cacheKey, err := sanitizeURLForCache(offlineTestRegistryURL)
+	require.NoError(t, err)
+	cachePath := filepath.Join(cacheDir, cacheKey, "registry.yaml")
+
+	require.NoError(t, saveCachedRegistry(cachePath, &RegistryIndex{
+		Version:     1,
+		RegistryURL: offlineTestRegistryURL,
+		Tools:       []ToolEntry{{Name: "fs", Description: "Filesystem tool"}},
+	}))
+	useFakeClock(t).Advance(age)
+}
+
+// observeWarnings captures warnings logged during the test
+func observeWarnings(t *testing.T) *observer.ObservedLogs {
+	t.Helper()
+	coreLogger, logs := observer.New(zap.WarnLevel)
+	previous := zap.L()
+	zap.ReplaceGlobals(zap.New(coreLogger))
+	t.Cleanup(func() { zap.ReplaceGlobals(previous) })
+	return logs
+}
+
+func TestFetchRegistry_OfflineCached(t *testing.T) {
+	runner := useOfflineTest(t)
+	cacheOfflineTestRegistry(t, time.Minute)
+	logs := observeWarnings(t)
+
+	// The cache is used even when a fresh fetch was asked for
+	for _, useCache := range []bool{true, false} {
+		reg, err := FetchRegistry(offlineTestRegistryURL, useCache)
+		require.NoError(t, err)
+		require.Len(t, reg.Tools, 1)
+		assert.Equal(t, "fs", reg.Tools[0].Name)
+	}
+
+	assert.Empty(t, runner.CloneCalls)
+	assert.Empty(t, runner.PullCalls)
+	assert.Zero(t, logs.Len(), "a fresh cache should not warn")
+}
+
+func TestFetchRegistry_OfflineCacheMiss(t *testing.T) {
+	runner := useOfflineTest(t)
+
+	_, err := FetchRegistry(offlineTestRegistryURL, true)
+	require.Error(t, err)
+	require.ErrorIs(t, err, ErrOffline)
+	assert.Equal(t, "offline mode: no cached registry for "+offlineTestRegistryURL, err.Error())
+
+	assert.Empty(t, runner.CloneCalls, "offline mode should not clone the registry")
+	assert.Empty(t, runner.PullCalls)
+}
+
+func TestFetchRegistry_OfflineExpiredCache(t *testing.T) {
+	runner := useOfflineTest(t)
+	cacheOfflineTestRegistry(t, 2*RegistryCacheTTL)
+	logs := observeWarnings(t)
+
+	reg, err := FetchRegistry(offlineTestRegistryURL, true)
+	require.NoError(t, err)
+	require.Len(t, reg.Tools, 1)
+	assert.Empty(t, runner.CloneCalls)
+
+	warnings := logs.FilterMessage("Using expired cached registry in offline mode").All()
+	require.Len(t, warnings, 1)
+	assert.Equal(t, offlineTestRegistryURL, warnings[0].ContextMap()["source"])
+}
+
+func TestListTags_Offline(t *testing.T) {
+	runner := useOfflineTest(t)
+	repoURL := "https://example.com/orla-tool-fs"
+
+	// Cache miss
+	_, err := ListTags(repoURL, true)
+	require.ErrorIs(t, err, ErrOffline)
+	assert.Contains(t, err.Error(), "offline mode: no cached tags for repository "+repoURL)
+	assert.Empty(t, runner.ListTagsCalls)
+
+	// Expired cache
+	cachePath, err := tagCachePath(repoURL)
+	require.NoError(t, err)
+	require.NoError(t, saveCachedTags(cachePath, &cachedTags{Repository: repoURL, Tags: []string{"v0.1.0", "v0.2.0"}}))
+	useFakeClock(t).Advance(2 * RegistryCacheTTL)
+	logs := observeWarnings(t)
+
+	tag, err := ResolveVersion(&ToolEntry{Name: "fs", Repository: repoURL}, VersionConstraintLatest, false)
+	require.NoError(t, err)
+	assert.Equal(t, "v0.2.0", tag)
+	assert.Empty(t, runner.ListTagsCalls, "offline mode should not list tags from the repository")
+	assert.Len(t, logs.FilterMessage("Using expired cached tags in offline mode").All(), 1)
+}
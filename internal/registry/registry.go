@@ -4,10 +4,13 @@ package registry
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io/fs"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -25,13 +28,55 @@ type RegistryIndex struct {
 	Tools       []ToolEntry `yaml:"tools"`
 }
 
+const (
+	// SupportedRegistryVersion is the newest registry index format this version of orla understands
+	SupportedRegistryVersion = 1
+	// MinSupportedRegistryVersion is the oldest registry index format this version of orla can migrate
+	MinSupportedRegistryVersion = 1
+	// RegistryCacheTTL is how long cached registry indexes and tag lists are used before they are fetched again
+	RegistryCacheTTL = time.Hour
+)
+
+// registryMigrations upgrades a registry index from the keyed version to the next version.
+// Add an entry here whenever SupportedRegistryVersion is bumped.
+var registryMigrations = map[int]func(*RegistryIndex) error{}
+
+// UnsupportedRegistryVersionError is returned when a registry index uses a format version
+// this version of orla cannot read
+type UnsupportedRegistryVersionError struct {
+	Version int
+}
+
+// Error returns the error message for the UnsupportedRegistryVersionError, including how to fix it
+func (e *UnsupportedRegistryVersionError) Error() string {
+	if e.Version > SupportedRegistryVersion {
+		return fmt.Sprintf("registry version %d is newer than this version of orla supports (up to %d); upgrade orla to use this registry",
+			e.Version, SupportedRegistryVersion)
+	}
+	return fmt.Sprintf("registry version %d is no longer supported (minimum %d)", e.Version, MinSupportedRegistryVersion)
+}
+
+// Interface guard for UnsupportedRegistryVersionError
+var _ error = &UnsupportedRegistryVersionError{}
+
 // ToolEntry maintains tool information including name, description, repository, maintainer, and keywords.
+// A tool that runs a package (e.g. from npm with npx) sets Package, Mode and Version instead of Repository.
 type ToolEntry struct {
 	Name        string   `yaml:"name"`
 	Description string   `yaml:"description"`
-	Repository  string   `yaml:"repository"`
+	Repository  string   `yaml:"repository,omitempty"`
 	Maintainer  string   `yaml:"maintainer,omitempty"`
 	Keywords    []string `yaml:"keywords,omitempty"`
+	// Package is the package the tool runs, e.g. "@modelcontextprotocol/server-filesystem"
+	Package string `yaml:"package,omitempty"`
+	// Mode is the runtime mode that runs Package: "npx" or "pipx"
+	Mode core.RuntimeMode `yaml:"mode,omitempty"`
+	// Version is the version the tool is installed as, since a package tool has no git tags
+	Version string `yaml:"version,omitempty"`
+	// Stability is "experimental", "stable" (default), or "deprecated", as declared by the tool's manifest
+	Stability core.ToolStability `yaml:"stability,omitempty"`
+	// Checksums maps version tags (e.g. "v1.2.0") to the SHA256 checksum of the tool's files at that tag
+	Checksums map[string]string `yaml:"checksums,omitempty"`
 }
 
 // getRegistryCacheDirFunc is a function variable for getting cache directory (can be swapped for testing)
@@ -42,6 +87,7 @@ var GetRegistryCacheDirFunc *func() (string, error) = &getRegistryCacheDirFunc
 
 // FetchRegistry fetches the registry index from the given URL
 // If useCache is true, it will use cached registry if available and fresh
+// In offline mode (see SetOffline), only the cached registry is used, whether or not it is fresh
 func FetchRegistry(registryURL string, useCache bool) (*RegistryIndex, error) {
 	cacheDir, err := getRegistryCacheDirFunc()
 	if err != nil {
@@ -61,6 +107,10 @@ func FetchRegistry(registryURL string, useCache bool) (*RegistryIndex, error) {
 	}
 	cachePath := filepath.Join(cacheDir, cacheKey, "registry.yaml")
 
+	if Offline() {
+		return loadOfflineRegistry(registryURL, cachePath)
+	}
+
 	// Check cache if enabled
 	if useCache {
 		cached, errLoad := loadCachedRegistry(cachePath)
@@ -104,61 +154,138 @@ func FetchRegistry(registryURL string, useCache bool) (*RegistryIndex, error) {
 		return nil, fmt.Errorf("failed to read registry.yaml: %w", err)
 	}
 
-	var index RegistryIndex
-	if err := yaml.Unmarshal(data, &index); err != nil {
-		return nil, fmt.Errorf("failed to parse registry.yaml: %w", err)
+	index, err := parseRegistryIndex(data)
+	if err != nil {
+		return nil, err
 	}
 
 	// Update cache
 	if useCache {
-		if err := saveCachedRegistry(cachePath, &index); err != nil {
+		if err := saveCachedRegistry(cachePath, index); err != nil {
 			zap.L().Warn("Failed to cache registry", zap.Error(err))
 		}
 	}
 
+	return index, nil
+}
+
+// parseRegistryIndex parses a registry.yaml file and migrates it to SupportedRegistryVersion
+func parseRegistryIndex(data []byte) (*RegistryIndex, error) {
+	var index RegistryIndex
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse registry.yaml: %w", err)
+	}
+
+	if err := migrateRegistryIndex(&index); err != nil {
+		return nil, err
+	}
+
 	return &index, nil
 }
 
+// migrateRegistryIndex checks the registry index version and upgrades older supported versions
+// to SupportedRegistryVersion. A missing version is treated as the oldest supported version.
+func migrateRegistryIndex(index *RegistryIndex) error {
+	if index.Version == 0 {
+		zap.L().Debug("Registry index has no version, assuming oldest supported version",
+			zap.Int("version", MinSupportedRegistryVersion))
+		index.Version = MinSupportedRegistryVersion
+	}
+
+	if index.Version > SupportedRegistryVersion || index.Version < MinSupportedRegistryVersion {
+		return &UnsupportedRegistryVersionError{Version: index.Version}
+	}
+
+	for index.Version < SupportedRegistryVersion {
+		migrate, ok := registryMigrations[index.Version]
+		if !ok {
+			return fmt.Errorf("no migration from registry version %d", index.Version)
+		}
+		if err := migrate(index); err != nil {
+			return fmt.Errorf("failed to migrate registry from version %d: %w", index.Version, err)
+		}
+		index.Version++
+	}
+
+	return nil
+}
+
 // cloneRegistry clones the registry repository (deprecated: use defaultGitRunner.Clone instead)
 // Kept for backward compatibility with installer package
 func cloneRegistry(registryURL, targetPath string) error {
 	return defaultGitRunner.Clone(registryURL, targetPath)
 }
 
-// loadCachedRegistry loads registry from cache if it's fresh (less than 1 hour old)
+// loadCachedRegistry loads registry from cache if it's fresh (less than RegistryCacheTTL old)
 func loadCachedRegistry(cachePath string) (*RegistryIndex, error) {
+	modTime, err := cachedFileModTime(cachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if cache is fresh (less than RegistryCacheTTL old)
+	if cacheExpired(modTime) {
+		return nil, fmt.Errorf("cache expired")
+	}
+
+	return readCachedRegistry(cachePath)
+}
+
+// loadOfflineRegistry loads registry from cache in offline mode, using an expired cache with a warning
+func loadOfflineRegistry(registryURL, cachePath string) (*RegistryIndex, error) {
+	modTime, err := cachedFileModTime(cachePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: no cached registry for %s", ErrOffline, registryURL)
+		}
+		return nil, fmt.Errorf("%w: failed to read cached registry for %s: %w", ErrOffline, registryURL, err)
+	}
+
+	index, err := readCachedRegistry(cachePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read cached registry for %s: %w", ErrOffline, registryURL, err)
+	}
+
+	if cacheExpired(modTime) {
+		warnExpiredOfflineCache("registry", registryURL, modTime)
+	} else {
+		zap.L().Debug("Using cached registry in offline mode", zap.String("path", cachePath))
+	}
+	return index, nil
+}
+
+// cachedFileModTime returns when the cache file at cachePath was last written
+func cachedFileModTime(cachePath string) (time.Time, error) {
 	// Open cache directory as root for secure file access
-	cacheDir := filepath.Dir(cachePath)
-	root, err := os.OpenRoot(cacheDir)
+	root, err := os.OpenRoot(filepath.Dir(cachePath))
 	if err != nil {
-		return nil, fmt.Errorf("failed to open cache directory: %w", err)
+		return time.Time{}, fmt.Errorf("failed to open cache directory: %w", err)
 	}
 	defer core.LogDeferredError(root.Close)
 
 	// Stat file using os.Root (automatically prevents path traversal)
-	fileName := filepath.Base(cachePath)
-	info, err := root.Stat(fileName)
+	info, err := root.Stat(filepath.Base(cachePath))
 	if err != nil {
-		return nil, err
+		return time.Time{}, err
 	}
+	return info.ModTime(), nil
+}
 
-	// Check if cache is fresh (less than 1 hour old)
-	if time.Since(info.ModTime()) > time.Hour {
-		return nil, fmt.Errorf("cache expired")
+// readCachedRegistry reads and parses the cached registry at cachePath, regardless of its age
+func readCachedRegistry(cachePath string) (*RegistryIndex, error) {
+	root, err := os.OpenRoot(filepath.Dir(cachePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache directory: %w", err)
 	}
+	defer core.LogDeferredError(root.Close)
 
 	// Read file using os.Root (automatically prevents path traversal)
-	data, err := root.ReadFile(fileName)
+	data, err := root.ReadFile(filepath.Base(cachePath))
 	if err != nil {
 		return nil, err
 	}
 
-	var index RegistryIndex
-	if err := yaml.Unmarshal(data, &index); err != nil {
-		return nil, err
-	}
-
-	return &index, nil
+	return parseRegistryIndex(data)
 }
 
 // saveCachedRegistry saves registry to cache
@@ -197,21 +324,36 @@ func SanitizeURLForCache(rawURL string) (string, error) {
 	return sanitizeURLForCache(rawURL)
 }
 
-// sanitizeURLForCache converts a URL to a safe cache key using SHA256 hash
-// This ensures filesystem-safe cache keys that handle all URL formats correctly
-func sanitizeURLForCache(rawURL string) (string, error) {
+// ValidateRegistryURL checks that rawURL is a usable registry URL (it must have a scheme and a host)
+func ValidateRegistryURL(rawURL string) error {
+	_, err := parseRegistryURL(rawURL)
+	return err
+}
+
+// parseRegistryURL parses rawURL and validates that it has the required components (scheme and host)
+func parseRegistryURL(rawURL string) (*url.URL, error) {
 	// Parse URL to normalize it (handles edge cases)
 	parsedURL, err := url.Parse(rawURL)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse URL: %w", err)
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
 	}
 
-	// Validate that URL has required components (scheme and host)
 	if parsedURL.Scheme == "" {
-		return "", fmt.Errorf("URL missing scheme: %s", rawURL)
+		return nil, fmt.Errorf("URL missing scheme: %s", rawURL)
 	}
 	if parsedURL.Host == "" {
-		return "", fmt.Errorf("URL missing host: %s", rawURL)
+		return nil, fmt.Errorf("URL missing host: %s", rawURL)
+	}
+
+	return parsedURL, nil
+}
+
+// sanitizeURLForCache converts a URL to a safe cache key using SHA256 hash
+// This ensures filesystem-safe cache keys that handle all URL formats correctly
+func sanitizeURLForCache(rawURL string) (string, error) {
+	parsedURL, err := parseRegistryURL(rawURL)
+	if err != nil {
+		return "", err
 	}
 
 	// Normalize URL: remove default ports, ensure consistent scheme format
@@ -284,8 +426,14 @@ func extractSemverFromTag(tag string) string {
 
 // ResolveVersion resolves a version constraint to a specific git tag.
 // For "latest", it queries git tags from the repository and selects the latest stable version.
+// For range constraints (e.g. "^1.2.0", see constraint.go), it selects the highest matching version.
 // For explicit tags, it returns the tag as-is (validation happens during clone).
-func ResolveVersion(tool *ToolEntry, constraint string) (string, error) {
+// If useCache is true, a tag list cached within RegistryCacheTTL is used instead of querying the repository.
+func ResolveVersion(tool *ToolEntry, constraint string, useCache bool) (string, error) {
+	if IsVersionRange(constraint) {
+		return resolveVersionRange(tool, constraint, useCache)
+	}
+
 	// Handle explicit tag - user provided it, just return it
 	if constraint != VersionConstraintLatest && constraint != VersionConstraintEmpty {
 		// Tags must start with 'v'
@@ -296,9 +444,9 @@ func ResolveVersion(tool *ToolEntry, constraint string) (string, error) {
 	}
 
 	// Handle "latest" by querying git tags and finding the latest stable version
-	tags, err := defaultGitRunner.ListTags(tool.Repository)
+	tags, err := ListTags(tool.Repository, useCache)
 	if err != nil {
-		return "", fmt.Errorf("failed to list tags from repository: %w", err)
+		return "", err
 	}
 
 	if len(tags) == 0 {
@@ -345,6 +493,71 @@ func ResolveVersion(tool *ToolEntry, constraint string) (string, error) {
 	return latestTag, nil
 }
 
+// resolveVersionRange resolves a range constraint to the tag of the highest version that satisfies it
+func resolveVersionRange(tool *ToolEntry, constraint string, useCache bool) (string, error) {
+	parsed, err := parseVersionConstraint(constraint)
+	if err != nil {
+		return "", fmt.Errorf("invalid version constraint '%s': %w", constraint, err)
+	}
+
+	tags, err := ListTags(tool.Repository, useCache)
+	if err != nil {
+		return "", err
+	}
+
+	var bestTag string
+	var bestVersion string
+	for _, tag := range tags {
+		semverStr := extractSemverFromTag(tag)
+		if semverStr == "" {
+			continue
+		}
+
+		version := semver.Canonical("v" + semverStr)
+		if !parsed.Matches(version) {
+			continue
+		}
+		if bestTag == "" || semver.Compare(version, bestVersion) > 0 {
+			bestTag = tag
+			bestVersion = version
+		}
+	}
+
+	if bestTag == "" {
+		return "", fmt.Errorf("no version of tool '%s' matches '%s'", tool.Name, constraint)
+	}
+
+	return bestTag, nil
+}
+
+// AvailableVersions returns the version tags a tool can be installed at, newest first. Tags that
+// are not semver versions are left out. A package tool has only the version the registry lists.
+// If useCache is true, a tag list cached within RegistryCacheTTL is used instead of querying the repository.
+func AvailableVersions(tool *ToolEntry, useCache bool) ([]string, error) {
+	if tool.Package != "" {
+		if tool.Version == "" {
+			return nil, nil
+		}
+		return []string{tool.Version}, nil
+	}
+
+	tags, err := ListTags(tool.Repository, useCache)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if extractSemverFromTag(tag) != "" {
+			versions = append(versions, tag)
+		}
+	}
+	slices.SortFunc(versions, func(a, b string) int {
+		return semver.Compare(b, a)
+	})
+	return versions, nil
+}
+
 // SearchTools searches the registry for tools matching the query
 // It searches in tool names, descriptions, and keywords (case-insensitive)
 func SearchTools(registry *RegistryIndex, query string) []ToolEntry {
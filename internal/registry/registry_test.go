@@ -80,22 +80,22 @@ func TestResolveVersion(t *testing.T) {
 	defer func() { defaultGitRunner = originalRunner }()
 
 	// Test latest (should return latest stable)
-	tag, err := ResolveVersion(tool, "latest")
+	tag, err := ResolveVersion(tool, "latest", false)
 	require.NoError(t, err)
 	assert.Equal(t, "v0.2.0", tag)
 
 	// Test empty constraint (should return latest stable)
-	tag, err = ResolveVersion(tool, "")
+	tag, err = ResolveVersion(tool, "", false)
 	require.NoError(t, err)
 	assert.Equal(t, "v0.2.0", tag)
 
 	// Test exact tag
-	tag, err = ResolveVersion(tool, "v0.1.0")
+	tag, err = ResolveVersion(tool, "v0.1.0", false)
 	require.NoError(t, err)
 	assert.Equal(t, "v0.1.0", tag)
 
 	// Test tag without 'v' prefix (should fail)
-	_, err = ResolveVersion(tool, "0.1.0")
+	_, err = ResolveVersion(tool, "0.1.0", false)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "must start with 'v'")
 }
@@ -117,7 +117,7 @@ func TestResolveVersion_OnlyPreRelease(t *testing.T) {
 	defer func() { defaultGitRunner = originalRunner }()
 
 	// Should return the latest pre-release when no stable versions exist
-	tag, err := ResolveVersion(tool, "latest")
+	tag, err := ResolveVersion(tool, "latest", false)
 	require.NoError(t, err)
 	assert.Equal(t, "v0.3.0-rc", tag)
 }
@@ -137,7 +137,7 @@ func TestResolveVersion_NoVersions(t *testing.T) {
 	defaultGitRunner = mockRunner
 	defer func() { defaultGitRunner = originalRunner }()
 
-	_, err := ResolveVersion(tool, "latest")
+	_, err := ResolveVersion(tool, "latest", false)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no tags found")
 }
@@ -159,7 +159,7 @@ func TestResolveVersion_MixedVersions(t *testing.T) {
 	defer func() { defaultGitRunner = originalRunner }()
 
 	// Should return latest stable version (v0.3.0), not pre-release
-	tag, err := ResolveVersion(tool, "latest")
+	tag, err := ResolveVersion(tool, "latest", false)
 	require.NoError(t, err)
 	assert.Equal(t, "v0.3.0", tag)
 }
@@ -240,6 +240,7 @@ func TestLoadCachedRegistry(t *testing.T) {
 }
 
 func TestLoadCachedRegistry_Expired(t *testing.T) {
+	clock := useFakeClock(t)
 	tmpDir := t.TempDir()
 	cachePath := filepath.Join(tmpDir, "registry.yaml")
 
@@ -257,9 +258,11 @@ func TestLoadCachedRegistry_Expired(t *testing.T) {
 	// #nosec G306 -- test file permissions are acceptable for temporary test files
 	require.NoError(t, os.WriteFile(cachePath, data, 0644))
 
-	// Modify file time to be older than 1 hour
-	oldTime := time.Now().Add(-2 * time.Hour)
-	require.NoError(t, os.Chtimes(cachePath, oldTime, oldTime))
+	_, err = loadCachedRegistry(cachePath)
+	require.NoError(t, err)
+
+	// Advance the clock past the cache TTL
+	clock.Advance(2 * time.Hour)
 
 	// Load cached registry should fail due to expiration
 	_, err = loadCachedRegistry(cachePath)
@@ -1182,3 +1185,90 @@ func TestExtractVersionFromDir(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRegistryIndex_Versions(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantVersion int
+		wantErr     string
+	}{
+		{
+			name:        "supported version",
+			content:     "version: 1\ntools:\n  - name: fs\n",
+			wantVersion: SupportedRegistryVersion,
+		},
+		{
+			name:        "missing version defaults to oldest supported",
+			content:     "tools:\n  - name: fs\n",
+			wantVersion: MinSupportedRegistryVersion,
+		},
+		{
+			name:    "too new version is rejected",
+			content: "version: 99\ntools:\n  - name: fs\n",
+			wantErr: "upgrade orla",
+		},
+		{
+			name:    "negative version is rejected",
+			content: "version: -1\n",
+			wantErr: "no longer supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, err := parseRegistryIndex([]byte(tt.content))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				var versionErr *UnsupportedRegistryVersionError
+				assert.ErrorAs(t, err, &versionErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantVersion, index.Version)
+			require.Len(t, index.Tools, 1)
+			assert.Equal(t, "fs", index.Tools[0].Name)
+		})
+	}
+}
+
+func TestFetchRegistry_UnsupportedVersion(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	// #nosec G301 -- test directory permissions are acceptable for temporary test files
+	require.NoError(t, os.MkdirAll(cacheDir, 0755))
+
+	cacheKey, err := sanitizeURLForCache(exampleRegistryURL)
+	require.NoError(t, err)
+	registryRepoPath := filepath.Join(cacheDir, cacheKey, "repo")
+
+	mockRunner := &MockGitRunner{
+		CloneFunc: func(url, targetPath string) error {
+			// #nosec G301 -- test directory permissions are acceptable for temporary test files
+			if err := os.MkdirAll(registryRepoPath, 0755); err != nil {
+				return err
+			}
+			// #nosec G306 -- test file permissions are acceptable for temporary test files
+			return os.WriteFile(filepath.Join(registryRepoPath, "registry.yaml"), []byte("version: 2\ntools: []\n"), 0644)
+		},
+	}
+	originalRunner := defaultGitRunner
+	SetGitRunner(mockRunner)
+	defer SetGitRunner(originalRunner)
+
+	originalGetCacheDir := getRegistryCacheDirFunc
+	getRegistryCacheDirFunc = func() (string, error) {
+		return cacheDir, nil
+	}
+	defer func() {
+		getRegistryCacheDirFunc = originalGetCacheDir
+	}()
+
+	_, err = FetchRegistry(exampleRegistryURL, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "registry version 2 is newer than this version of orla supports")
+
+	// The unsupported index is not cached
+	_, statErr := os.Stat(filepath.Join(cacheDir, cacheKey, "registry.yaml"))
+	assert.True(t, os.IsNotExist(statErr))
+}
@@ -0,0 +1,159 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// tagCacheDirName is the directory under the registry cache directory that holds cached tag lists
+const tagCacheDirName = "tags"
+
+// cachedTags is the tag list of a tool repository as stored in the tag cache
+type cachedTags struct {
+	Repository string   `yaml:"repository"`
+	Tags       []string `yaml:"tags"`
+}
+
+// ListTags lists the git tags of a tool repository
+// If useCache is true, it will use the cached tag list if available and fresh
+// In offline mode (see SetOffline), only the cached tag list is used, whether or not it is fresh
+func ListTags(repoURL string, useCache bool) ([]string, error) {
+	cachePath, errCachePath := tagCachePath(repoURL)
+	if Offline() {
+		if errCachePath != nil {
+			return nil, fmt.Errorf("%w: %w", ErrOffline, errCachePath)
+		}
+		return loadOfflineTags(cachePath, repoURL)
+	}
+	if errCachePath != nil {
+		zap.L().Warn("Failed to locate tag cache, listing tags without it", zap.Error(errCachePath))
+		useCache = false
+	}
+
+	if useCache {
+		tags, errLoad := loadCachedTags(cachePath, repoURL)
+		if errLoad == nil {
+			zap.L().Debug("Using cached tags", zap.String("repository", repoURL), zap.String("path", cachePath))
+			return tags, nil
+		}
+		zap.L().Debug("Failed to load cached tags, listing fresh", zap.Error(errLoad), zap.String("path", cachePath))
+	}
+
+	tags, err := defaultGitRunner.ListTags(repoURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags from repository: %w", err)
+	}
+
+	if useCache {
+		if errSave := saveCachedTags(cachePath, &cachedTags{Repository: repoURL, Tags: tags}); errSave != nil {
+			zap.L().Warn("Failed to cache tags", zap.Error(errSave))
+		}
+	}
+
+	return tags, nil
+}
+
+// tagCachePath returns the path of the cached tag list of repoURL. Repositories may be local
+// paths as well as URLs, so the key is a hash of the repository string as given.
+func tagCachePath(repoURL string) (string, error) {
+	cacheDir, err := getRegistryCacheDirFunc()
+	if err != nil {
+		return "", fmt.Errorf("failed to get cache directory: %w", err)
+	}
+
+	hash := sha256.Sum256([]byte(repoURL))
+	return filepath.Join(cacheDir, tagCacheDirName, hex.EncodeToString(hash[:])+".yaml"), nil
+}
+
+// loadCachedTags loads the tag list of repoURL from cache if it's fresh (less than RegistryCacheTTL old)
+func loadCachedTags(cachePath, repoURL string) ([]string, error) {
+	modTime, err := cachedFileModTime(cachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheExpired(modTime) {
+		return nil, fmt.Errorf("cache expired")
+	}
+
+	return readCachedTags(cachePath, repoURL)
+}
+
+// loadOfflineTags loads the tag list of repoURL from cache in offline mode, using an expired
+// cache with a warning
+func loadOfflineTags(cachePath, repoURL string) ([]string, error) {
+	modTime, err := cachedFileModTime(cachePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: no cached tags for repository %s", ErrOffline, repoURL)
+		}
+		return nil, fmt.Errorf("%w: failed to read cached tags for repository %s: %w", ErrOffline, repoURL, err)
+	}
+
+	tags, err := readCachedTags(cachePath, repoURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read cached tags for repository %s: %w", ErrOffline, repoURL, err)
+	}
+
+	if cacheExpired(modTime) {
+		warnExpiredOfflineCache("tags", repoURL, modTime)
+	}
+	return tags, nil
+}
+
+// readCachedTags reads the cached tag list of repoURL at cachePath, regardless of its age
+func readCachedTags(cachePath, repoURL string) ([]string, error) {
+	root, err := os.OpenRoot(filepath.Dir(cachePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache directory: %w", err)
+	}
+	defer core.LogDeferredError(root.Close)
+
+	data, err := root.ReadFile(filepath.Base(cachePath))
+	if err != nil {
+		return nil, err
+	}
+
+	var cached cachedTags
+	if err := yaml.Unmarshal(data, &cached); err != nil {
+		return nil, fmt.Errorf("failed to parse cached tags: %w", err)
+	}
+	if cached.Repository != repoURL {
+		return nil, fmt.Errorf("cached tags are for repository %s", cached.Repository)
+	}
+
+	return cached.Tags, nil
+}
+
+// saveCachedTags saves the tag list of a repository to cache
+func saveCachedTags(cachePath string, cached *cachedTags) error {
+	cacheDir := filepath.Dir(cachePath)
+	// #nosec G301 -- cache directory permissions 0755 are acceptable for user cache
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	root, err := os.OpenRoot(cacheDir)
+	if err != nil {
+		return fmt.Errorf("failed to open cache directory: %w", err)
+	}
+	defer core.LogDeferredError(root.Close)
+
+	data, err := yaml.Marshal(cached)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tags to yaml: %w", err)
+	}
+
+	// #nosec G306 -- cache file permissions 0644 are acceptable for user cache files
+	return root.WriteFile(filepath.Base(cachePath), data, 0644)
+}
@@ -0,0 +1,115 @@
+package registry
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useTagCacheTest points the registry cache at a temporary directory and replaces the git
+// runner with one that lists tags, returning a pointer to the number of ListTags calls
+func useTagCacheTest(t *testing.T, tags ...string) *int {
+	t.Helper()
+
+	cacheDir := t.TempDir()
+	originalGetCacheDir := getRegistryCacheDirFunc
+	getRegistryCacheDirFunc = func() (string, error) { return cacheDir, nil }
+	t.Cleanup(func() { getRegistryCacheDirFunc = originalGetCacheDir })
+
+	calls := 0
+	originalRunner := defaultGitRunner
+	defaultGitRunner = &MockGitRunner{
+		ListTagsFunc: func(repoURL string) ([]string, error) {
+			calls++
+			return tags, nil
+		},
+	}
+	t.Cleanup(func() { defaultGitRunner = originalRunner })
+
+	return &calls
+}
+
+func TestResolveVersion_UsesTagCache(t *testing.T) {
+	calls := useTagCacheTest(t, "v0.1.0", "v0.2.0")
+	tool := &ToolEntry{Name: "fs", Repository: "https://example.com/orla-tool-fs"}
+
+	tag, err := ResolveVersion(tool, VersionConstraintLatest, true)
+	require.NoError(t, err)
+	assert.Equal(t, "v0.2.0", tag)
+	assert.Equal(t, 1, *calls)
+
+	// A second resolution within the TTL is answered from the cache
+	tag, err = ResolveVersion(tool, VersionConstraintLatest, true)
+	require.NoError(t, err)
+	assert.Equal(t, "v0.2.0", tag)
+	assert.Equal(t, 1, *calls, "the cached tags should be used")
+
+	// Another repository is not answered from the cache of the first
+	_, err = ResolveVersion(&ToolEntry{Name: "http", Repository: "https://example.com/orla-tool-http"}, VersionConstraintLatest, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, *calls)
+}
+
+func TestResolveVersion_RefreshBypassesTagCache(t *testing.T) {
+	calls := useTagCacheTest(t, "v0.1.0")
+	tool := &ToolEntry{Name: "fs", Repository: "https://example.com/orla-tool-fs"}
+
+	_, err := ResolveVersion(tool, VersionConstraintLatest, true)
+	require.NoError(t, err)
+	require.Equal(t, 1, *calls)
+
+	_, err = ResolveVersion(tool, VersionConstraintLatest, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, *calls, "without the cache the tags should be listed again")
+}
+
+func TestResolveVersion_ExpiredTagCache(t *testing.T) {
+	clock := useFakeClock(t)
+	calls := useTagCacheTest(t, "v0.1.0")
+	tool := &ToolEntry{Name: "fs", Repository: "https://example.com/orla-tool-fs"}
+
+	_, err := ResolveVersion(tool, VersionConstraintLatest, true)
+	require.NoError(t, err)
+
+	// Age the cached tag list past the TTL
+	clock.Advance(2 * RegistryCacheTTL)
+
+	_, err = ResolveVersion(tool, VersionConstraintLatest, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, *calls, "expired cached tags should be listed again")
+}
+
+func TestResolveVersion_ExplicitTagSkipsTagCache(t *testing.T) {
+	calls := useTagCacheTest(t, "v0.1.0")
+	tool := &ToolEntry{Name: "fs", Repository: "https://example.com/orla-tool-fs"}
+
+	tag, err := ResolveVersion(tool, "v0.3.0", true)
+	require.NoError(t, err)
+	assert.Equal(t, "v0.3.0", tag)
+	assert.Zero(t, *calls)
+
+	cachePath, err := tagCachePath(tool.Repository)
+	require.NoError(t, err)
+	_, err = os.Stat(cachePath)
+	assert.True(t, os.IsNotExist(err), "an explicit tag should not populate the cache")
+}
+
+func TestAvailableVersions(t *testing.T) {
+	useTagCacheTest(t, "v0.2.0", "not-a-version", "v0.10.0", "v0.3.0-beta", "v0.1.0")
+	tool := &ToolEntry{Name: "fs", Repository: "https://example.com/orla-tool-fs"}
+
+	versions, err := AvailableVersions(tool, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v0.10.0", "v0.3.0-beta", "v0.2.0", "v0.1.0"}, versions)
+}
+
+func TestAvailableVersions_PackageTool(t *testing.T) {
+	calls := useTagCacheTest(t, "v9.9.9")
+
+	versions, err := AvailableVersions(&ToolEntry{Name: "filesystem", Package: "@mcp/server-filesystem", Mode: "npx", Version: "1.2.0"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.2.0"}, versions)
+	assert.Zero(t, *calls, "a package tool has no tags to list")
+}
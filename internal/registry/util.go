@@ -11,8 +11,20 @@ import (
 // DefaultRegistryURL is the default registry URL
 const DefaultRegistryURL = "https://github.com/dorcha-inc/orla-registry"
 
-// GetOrlaHomeDir returns the ~/.orla directory path
+// OrlaHomeEnvVar is the environment variable that overrides the orla home directory
+const OrlaHomeEnvVar = "ORLA_HOME"
+
+// GetOrlaHomeDir returns the orla home directory: $ORLA_HOME if set, otherwise ~/.orla.
+// The user config, registry cache, and installed tools all live under this directory.
 func GetOrlaHomeDir() (string, error) {
+	if orlaHome := os.Getenv(OrlaHomeEnvVar); orlaHome != "" {
+		absOrlaHome, err := filepath.Abs(orlaHome)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %w", OrlaHomeEnvVar, err)
+		}
+		return absOrlaHome, nil
+	}
+
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get orla home directory: %w", err)
@@ -20,8 +32,8 @@ func GetOrlaHomeDir() (string, error) {
 	return filepath.Join(homeDir, ".orla"), nil
 }
 
-// GetInstalledToolsDirFunc is a function variable for getting installed tools directory (can be swapped for testing)
-var GetInstalledToolsDirFunc = func() (string, error) {
+// GetInstalledToolsDir returns the installed tools directory (~/.orla/tools by default)
+func GetInstalledToolsDir() (string, error) {
 	orlaHome, err := GetOrlaHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get orla home directory: %w", err)
@@ -29,12 +41,7 @@ var GetInstalledToolsDirFunc = func() (string, error) {
 	return filepath.Join(orlaHome, "tools"), nil
 }
 
-// GetInstalledToolsDir returns the ~/.orla/tools directory path
-func GetInstalledToolsDir() (string, error) {
-	return GetInstalledToolsDirFunc()
-}
-
-// GetRegistryCacheDir returns the ~/.orla/cache/registry directory path
+// GetRegistryCacheDir returns the registry cache directory (~/.orla/cache/registry by default)
 func GetRegistryCacheDir() (string, error) {
 	orlaHome, err := GetOrlaHomeDir()
 	if err != nil {
@@ -43,6 +50,33 @@ func GetRegistryCacheDir() (string, error) {
 	return filepath.Join(orlaHome, "cache", "registry"), nil
 }
 
+// GetResponseCacheDir returns the model response cache directory (~/.orla/cache/responses by default)
+func GetResponseCacheDir() (string, error) {
+	orlaHome, err := GetOrlaHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get orla home directory: %w", err)
+	}
+	return filepath.Join(orlaHome, "cache", "responses"), nil
+}
+
+// GetSessionsDir returns the chat sessions directory (~/.orla/sessions by default)
+func GetSessionsDir() (string, error) {
+	orlaHome, err := GetOrlaHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get orla home directory: %w", err)
+	}
+	return filepath.Join(orlaHome, "sessions"), nil
+}
+
+// GetDisabledToolsPath returns the path of the disabled tools state file (~/.orla/disabled_tools.yaml by default)
+func GetDisabledToolsPath() (string, error) {
+	orlaHome, err := GetOrlaHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get orla home directory: %w", err)
+	}
+	return filepath.Join(orlaHome, "disabled_tools.yaml"), nil
+}
+
 // ExtractVersionFromDir extracts the version from a tool directory path
 // relative to the install directory. The path structure is expected to be:
 // ~/.orla/tools/TOOL-NAME/VERSION/
@@ -1,6 +1,7 @@
 package registry
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -20,6 +21,32 @@ func TestGetInstalledToolsDir(t *testing.T) {
 	assert.Contains(t, dir, "tools")
 }
 
+func TestGetOrlaHomeDir_EnvOverride(t *testing.T) {
+	orlaHome := t.TempDir()
+	t.Setenv(OrlaHomeEnvVar, orlaHome)
+
+	homeDir, err := GetOrlaHomeDir()
+	require.NoError(t, err)
+	assert.Equal(t, orlaHome, homeDir)
+
+	toolsDir, err := GetInstalledToolsDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(orlaHome, "tools"), toolsDir)
+
+	cacheDir, err := GetRegistryCacheDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(orlaHome, "cache", "registry"), cacheDir)
+}
+
+func TestGetOrlaHomeDir_RelativeEnvOverride(t *testing.T) {
+	t.Setenv(OrlaHomeEnvVar, "relative-orla-home")
+
+	homeDir, err := GetOrlaHomeDir()
+	require.NoError(t, err)
+	assert.True(t, filepath.IsAbs(homeDir))
+	assert.Equal(t, "relative-orla-home", filepath.Base(homeDir))
+}
+
 func TestGetRegistryCacheDir(t *testing.T) {
 	dir, err := GetRegistryCacheDir()
 	require.NoError(t, err)
@@ -0,0 +1,307 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/state"
+)
+
+const (
+	// AdminStatePath is the HTTP path of the admin state endpoint
+	AdminStatePath = "/admin/state"
+	// AdminToolsPath is the HTTP path prefix of the admin tool endpoints
+	AdminToolsPath = "/admin/tools/"
+	// AdminToolDisableAction is the admin tool endpoint action that disables a tool
+	AdminToolDisableAction = "disable"
+	// AdminToolEnableAction is the admin tool endpoint action that enables a disabled tool
+	AdminToolEnableAction = "enable"
+	// defaultRecentCallsLimit is the number of completed calls kept for the admin state endpoint
+	defaultRecentCallsLimit = 50
+)
+
+// AdminState is a point-in-time snapshot of the server, served by the admin state endpoint
+type AdminState struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	ToolsHash   string           `json:"tools_hash"`
+	Tools       []AdminToolState `json:"tools"`
+	InFlight    []CallRecord     `json:"in_flight"`
+	Recent      []CallRecord     `json:"recent"`
+}
+
+// AdminToolState describes a registered tool and, for capsule-mode tools, the capsule state
+type AdminToolState struct {
+	Name         string `json:"name"`
+	RuntimeMode  string `json:"runtime_mode"`
+	CapsuleState string `json:"capsule_state,omitempty"`
+	Disabled     bool   `json:"disabled,omitempty"`
+}
+
+// CallRecord describes a single tool call observed by the server. For in-flight calls,
+// DurationMs is the time elapsed so far.
+type CallRecord struct {
+	ID         int64     `json:"id"`
+	Tool       string    `json:"tool"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs float64   `json:"duration_ms"`
+	IsError    bool      `json:"is_error"`
+}
+
+// callTracker records in-flight and recently completed tool calls
+type callTracker struct {
+	mu       sync.Mutex
+	nextID   int64
+	inFlight map[int64]*CallRecord
+	recent   []CallRecord // oldest first, at most limit entries
+	limit    int
+}
+
+// newCallTracker creates a call tracker that keeps up to limit completed calls
+func newCallTracker(limit int) *callTracker {
+	return &callTracker{
+		inFlight: make(map[int64]*CallRecord),
+		limit:    limit,
+	}
+}
+
+// begin records the start of a call to the given tool and returns its ID
+func (c *callTracker) begin(toolName string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	c.inFlight[c.nextID] = &CallRecord{
+		ID:        c.nextID,
+		Tool:      toolName,
+		StartedAt: time.Now(),
+	}
+	return c.nextID
+}
+
+// end records the completion of the call with the given ID
+func (c *callTracker) end(id int64, isError bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	record, ok := c.inFlight[id]
+	if !ok {
+		return
+	}
+	delete(c.inFlight, id)
+
+	record.DurationMs = float64(time.Since(record.StartedAt).Microseconds()) / 1000
+	record.IsError = isError
+
+	c.recent = append(c.recent, *record)
+	if len(c.recent) > c.limit {
+		c.recent = c.recent[len(c.recent)-c.limit:]
+	}
+}
+
+// snapshot returns the in-flight calls (oldest first) and recent calls (newest first)
+func (c *callTracker) snapshot() (inFlight []CallRecord, recent []CallRecord) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	inFlight = make([]CallRecord, 0, len(c.inFlight))
+	for _, record := range c.inFlight {
+		call := *record
+		call.DurationMs = float64(now.Sub(call.StartedAt).Microseconds()) / 1000
+		inFlight = append(inFlight, call)
+	}
+	slices.SortFunc(inFlight, func(a, b CallRecord) int { return int(a.ID - b.ID) })
+
+	recent = make([]CallRecord, len(c.recent))
+	for i, record := range c.recent {
+		recent[len(c.recent)-1-i] = record
+	}
+
+	return inFlight, recent
+}
+
+// AdminState returns a snapshot of the registered tools, capsule states, and tool calls
+func (o *OrlaServer) AdminState() *AdminState {
+	o.mu.RLock()
+	var toolList []*core.ToolManifest
+	if o.config.ToolsRegistry != nil {
+		toolList = o.config.ToolsRegistry.ListTools()
+	}
+	disabledTools := o.disabledTools.Clone()
+	toolsHash := o.toolsHash
+	o.mu.RUnlock()
+
+	tools := make([]AdminToolState, 0, len(toolList))
+	for _, tool := range toolList {
+		toolState := AdminToolState{
+			Name:        tool.Name,
+			RuntimeMode: string(core.RuntimeModeSimple),
+			Disabled:    disabledTools.Contains(tool.Name),
+		}
+		if tool.Runtime != nil && tool.Runtime.Mode != "" {
+			toolState.RuntimeMode = string(tool.Runtime.Mode)
+		}
+		if capsule, ok := o.capsules.Load(tool.Name); ok {
+			toolState.CapsuleState = string(capsule.GetState())
+		}
+		tools = append(tools, toolState)
+	}
+	slices.SortFunc(tools, func(a, b AdminToolState) int { return strings.Compare(a.Name, b.Name) })
+
+	inFlight, recent := o.calls.snapshot()
+
+	return &AdminState{
+		GeneratedAt: time.Now(),
+		ToolsHash:   toolsHash,
+		Tools:       tools,
+		InFlight:    inFlight,
+		Recent:      recent,
+	}
+}
+
+// withAdminAccess serves the admin and capabilities endpoints with next only if the config sets
+// admin_enabled, and only to clients that present the admin_token as a bearer token, or to
+// loopback clients if no token is set. Requests a browser sends from another origin are refused,
+// so that a web page cannot use the endpoints of a server on the same machine.
+func (o *OrlaServer) withAdminAccess(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		o.mu.RLock()
+		enabled, token := o.config.AdminEnabled, o.config.AdminToken
+		o.mu.RUnlock()
+
+		switch {
+		case !enabled:
+			http.Error(w, "admin endpoints are disabled, set admin_enabled in the config", http.StatusForbidden)
+		case crossOriginRequest(r):
+			http.Error(w, "cross-origin requests are not allowed", http.StatusForbidden)
+		case token != "":
+			if !validAdminToken(r, token) {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "missing or invalid admin token", http.StatusUnauthorized)
+				return
+			}
+			next(w, r)
+		case !loopbackRequest(r):
+			http.Error(w, "admin endpoints only accept loopback clients unless admin_token is set", http.StatusForbidden)
+		default:
+			next(w, r)
+		}
+	}
+}
+
+// AdminAuthorization returns the Authorization header value that admin requests send with token
+func AdminAuthorization(token string) string {
+	return "Bearer " + token
+}
+
+// validAdminToken reports whether r carries token as its bearer token
+func validAdminToken(r *http.Request, token string) bool {
+	got := r.Header.Get("Authorization")
+	return subtle.ConstantTimeCompare([]byte(got), []byte(AdminAuthorization(token))) == 1
+}
+
+// crossOriginRequest reports whether r was sent by a browser from a page of another origin.
+// Browsers send Origin with every cross-origin POST, command line clients send none.
+func crossOriginRequest(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	originURL, err := url.Parse(origin)
+	return err != nil || originURL.Host != r.Host
+}
+
+// loopbackRequest reports whether r comes from the same machine
+func loopbackRequest(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// handleAdminState serves the admin state snapshot as JSON
+func (o *OrlaServer) handleAdminState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(o.AdminState()); err != nil {
+		zap.L().Error("Failed to encode admin state", zap.Error(err))
+	}
+}
+
+// AdminToolPath returns the HTTP path of the admin endpoint that applies action to the named tool
+func AdminToolPath(name string, action string) string {
+	return AdminToolsPath + url.PathEscape(name) + "/" + action
+}
+
+// SetToolDisabled disables or enables the named tool on the running server. A disabled tool is
+// removed from the MCP tool list (and its capsule stopped) without being uninstalled; enabling
+// it registers it again. The change is persisted in the disabled tools state file so that it
+// survives reloads and restarts.
+func (o *OrlaServer) SetToolDisabled(name string, disabled bool) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	tool, err := o.config.ToolsRegistry.GetTool(name)
+	if err != nil {
+		return err
+	}
+
+	if o.disabledToolsPath == "" {
+		return fmt.Errorf("disabled tools file is not available")
+	}
+
+	disabledTools, err := state.SetToolDisabled(o.disabledToolsPath, name, disabled)
+	if err != nil {
+		return err
+	}
+	o.disabledTools = disabledTools
+
+	registered := o.registeredTools.Contains(name)
+	switch {
+	case disabled && registered:
+		o.removeTool(tool)
+		zap.L().Info("Disabled tool", zap.String("tool", name))
+	case !disabled && !registered && o.skipReason(tool) == "":
+		o.addTool(tool)
+		zap.L().Info("Enabled tool", zap.String("tool", name))
+	}
+	o.updateToolsHash()
+
+	return nil
+}
+
+// handleAdminToolAction serves the admin endpoints that disable and enable a tool
+func (o *OrlaServer) handleAdminToolAction(disabled bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := o.SetToolDisabled(r.PathValue("name"), disabled)
+
+		var notFoundErr *state.ToolNotFoundError
+		switch {
+		case errors.As(err, &notFoundErr):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case err != nil:
+			zap.L().Error("Failed to update disabled tools", zap.Error(err))
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
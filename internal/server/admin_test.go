@@ -0,0 +1,253 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/registry"
+	"github.com/dorcha-inc/orla/internal/state"
+)
+
+func TestCallTracker(t *testing.T) {
+	tracker := newCallTracker(2)
+
+	first := tracker.begin("a")
+	second := tracker.begin("b")
+
+	inFlight, recent := tracker.snapshot()
+	require.Len(t, inFlight, 2)
+	assert.Equal(t, first, inFlight[0].ID)
+	assert.Equal(t, second, inFlight[1].ID)
+	assert.Empty(t, recent)
+
+	tracker.end(first, false)
+	tracker.end(second, true)
+	third := tracker.begin("c")
+	tracker.end(third, false)
+
+	// Ending an unknown call is a no-op
+	tracker.end(12345, false)
+
+	inFlight, recent = tracker.snapshot()
+	assert.Empty(t, inFlight)
+	// Only the most recent calls are kept, newest first
+	require.Len(t, recent, 2)
+	assert.Equal(t, "c", recent[0].Tool)
+	assert.Equal(t, "b", recent[1].Tool)
+	assert.True(t, recent[1].IsError)
+	assert.GreaterOrEqual(t, recent[0].DurationMs, 0.0)
+}
+
+func TestHandleAdminState(t *testing.T) {
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+
+	callID := srv.calls.begin("test-tool")
+	srv.calls.end(callID, false)
+	srv.calls.begin("test-tool")
+
+	rec := httptest.NewRecorder()
+	srv.handleAdminState(rec, httptest.NewRequest(http.MethodGet, AdminStatePath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var state AdminState
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&state))
+	require.Len(t, state.Tools, 1)
+	assert.Equal(t, "test-tool", state.Tools[0].Name)
+	assert.Equal(t, "simple", state.Tools[0].RuntimeMode)
+	assert.Empty(t, state.Tools[0].CapsuleState)
+	assert.Len(t, state.InFlight, 1)
+	assert.Len(t, state.Recent, 1)
+
+	rec = httptest.NewRecorder()
+	srv.handleAdminState(rec, httptest.NewRequest(http.MethodPost, AdminStatePath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+// listToolNames returns the names of the tools the client session sees
+func listToolNames(t *testing.T, ctx context.Context, session *mcp.ClientSession) []string {
+	t.Helper()
+
+	result, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(result.Tools))
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+func TestSetToolDisabled(t *testing.T) {
+	t.Setenv(registry.OrlaHomeEnvVar, t.TempDir())
+
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.orlaMCPserver.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError(serverSession.Close) })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError(clientSession.Close) })
+
+	assert.Equal(t, []string{"test-tool"}, listToolNames(t, ctx, clientSession))
+
+	// Disabling removes the tool from the running server's tool list
+	require.NoError(t, srv.SetToolDisabled("test-tool", true))
+	assert.Empty(t, listToolNames(t, ctx, clientSession))
+	assert.False(t, srv.registeredTools.Contains("test-tool"))
+	assert.True(t, srv.AdminState().Tools[0].Disabled)
+
+	// The disabled state is persisted and survives a rebuild
+	disabledToolsPath, err := registry.GetDisabledToolsPath()
+	require.NoError(t, err)
+	disabledTools, err := state.LoadDisabledTools(disabledToolsPath)
+	require.NoError(t, err)
+	assert.True(t, disabledTools.Contains("test-tool"))
+
+	srv.rebuildServer()
+	assert.False(t, srv.registeredTools.Contains("test-tool"))
+
+	// Enabling registers the tool again
+	require.NoError(t, srv.SetToolDisabled("test-tool", false))
+	assert.True(t, srv.registeredTools.Contains("test-tool"))
+	assert.False(t, srv.AdminState().Tools[0].Disabled)
+
+	// Enabling an enabled tool is a no-op
+	require.NoError(t, srv.SetToolDisabled("test-tool", false))
+
+	// Unknown tools are rejected
+	var notFoundErr *state.ToolNotFoundError
+	assert.ErrorAs(t, srv.SetToolDisabled("missing-tool", true), &notFoundErr)
+}
+
+func TestSetToolDisabled_ReappearsInToolList(t *testing.T) {
+	t.Setenv(registry.OrlaHomeEnvVar, t.TempDir())
+
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+	require.NoError(t, srv.SetToolDisabled("test-tool", true))
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.orlaMCPserver.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError(serverSession.Close) })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError(clientSession.Close) })
+
+	assert.Empty(t, listToolNames(t, ctx, clientSession))
+
+	require.NoError(t, srv.SetToolDisabled("test-tool", false))
+	assert.Equal(t, []string{"test-tool"}, listToolNames(t, ctx, clientSession))
+
+	result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "test-tool", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+}
+
+func TestHandleAdminToolAction(t *testing.T) {
+	orlaHome := t.TempDir()
+	t.Setenv(registry.OrlaHomeEnvVar, orlaHome)
+
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+
+	req := httptest.NewRequest(http.MethodPost, AdminToolPath("test-tool", AdminToolDisableAction), nil)
+	req.SetPathValue("name", "test-tool")
+	rec := httptest.NewRecorder()
+	srv.handleAdminToolAction(true)(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.False(t, srv.registeredTools.Contains("test-tool"))
+	assert.FileExists(t, filepath.Join(orlaHome, "disabled_tools.yaml"))
+
+	req = httptest.NewRequest(http.MethodPost, AdminToolPath("test-tool", AdminToolEnableAction), nil)
+	req.SetPathValue("name", "test-tool")
+	rec = httptest.NewRecorder()
+	srv.handleAdminToolAction(false)(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.True(t, srv.registeredTools.Contains("test-tool"))
+
+	req = httptest.NewRequest(http.MethodPost, AdminToolPath("missing-tool", AdminToolDisableAction), nil)
+	req.SetPathValue("name", "missing-tool")
+	rec = httptest.NewRecorder()
+	srv.handleAdminToolAction(true)(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAdminToolPath(t *testing.T) {
+	assert.Equal(t, "/admin/tools/fs/disable", AdminToolPath("fs", AdminToolDisableAction))
+	assert.Equal(t, "/admin/tools/my%20tool/enable", AdminToolPath("my tool", AdminToolEnableAction))
+}
+
+func TestAdminAccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		enabled    bool
+		token      string
+		remoteAddr string
+		header     http.Header
+		want       int
+	}{
+		{"disabled by default", false, "", "127.0.0.1:50000", nil, http.StatusForbidden},
+		{"loopback client", true, "", "127.0.0.1:50000", nil, http.StatusOK},
+		{"IPv6 loopback client", true, "", "[::1]:50000", nil, http.StatusOK},
+		{"remote client without a token", true, "", "192.0.2.1:50000", nil, http.StatusForbidden},
+		{"cross-origin browser request", true, "", "127.0.0.1:50000", http.Header{"Origin": {"https://attacker.test"}}, http.StatusForbidden},
+		{"remote client with the token", true, "s3cret", "192.0.2.1:50000", http.Header{"Authorization": {"Bearer s3cret"}}, http.StatusOK},
+		{"loopback client without the token", true, "s3cret", "127.0.0.1:50000", nil, http.StatusUnauthorized},
+		{"wrong token", true, "s3cret", "192.0.2.1:50000", http.Header{"Authorization": {"Bearer guess"}}, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(registry.OrlaHomeEnvVar, t.TempDir())
+			cfg := createTestConfig(t)
+			cfg.AdminEnabled = tt.enabled
+			cfg.AdminToken = tt.token
+			srv := NewOrlaServer(cfg, "")
+			t.Cleanup(srv.Close)
+
+			for _, path := range []string{AdminStatePath, CapabilitiesPath} {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				req.RemoteAddr = tt.remoteAddr
+				maps.Copy(req.Header, tt.header)
+				rec := httptest.NewRecorder()
+				srv.httpMux().ServeHTTP(rec, req)
+				assert.Equal(t, tt.want, rec.Code, path)
+			}
+
+			// Tools are only disabled by requests that are allowed
+			req := httptest.NewRequest(http.MethodPost, AdminToolPath("test-tool", AdminToolDisableAction), nil)
+			req.RemoteAddr = tt.remoteAddr
+			maps.Copy(req.Header, tt.header)
+			rec := httptest.NewRecorder()
+			srv.httpMux().ServeHTTP(rec, req)
+			if tt.want == http.StatusOK {
+				assert.Equal(t, http.StatusNoContent, rec.Code)
+			} else {
+				assert.Equal(t, tt.want, rec.Code)
+			}
+			assert.Equal(t, tt.want != http.StatusOK, srv.registeredTools.Contains("test-tool"))
+		})
+	}
+}
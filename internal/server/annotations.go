@@ -0,0 +1,239 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// contentEnvelopeKey is the top-level key of the content envelope a tool may print to stdout to
+// return multiple, individually annotated content items instead of a single stdout item:
+//
+//	{"orla_content": [
+//	  {"text": "3 files changed", "annotations": {"audience": ["user"], "priority": 1}},
+//	  {"text": "diff --git ...", "annotations": {"audience": ["assistant"], "priority": 0.3}}
+//	]}
+//
+// Items without annotations inherit the manifest's mcp.output_annotations.stdout, and items
+// without a content_type (e.g. "text/markdown") inherit the manifest's mcp.content_type.
+//
+// An item with "type": "image" is returned as image content. It carries the image either
+// base64-encoded in "data" or as the "path" of a file the tool wrote, and its content_type is
+// the image's media type, detected from the image if not given. A relative path is resolved
+// against the directory the tool runs in, and the file may be no larger than the tool's output
+// limit:
+//
+//	{"orla_content": [
+//	  {"text": "CPU usage over the last hour"},
+//	  {"type": "image", "path": "/tmp/chart.png", "content_type": "image/png"}
+//	]}
+const contentEnvelopeKey = "orla_content"
+
+// contentTypeMetaKey is the _meta key of a text content item that carries its media type.
+// MCP text content has no media type of its own; content without one is plain text.
+const contentTypeMetaKey = "contentType"
+
+// ExitCodeMetaKey is the _meta key of a tool result that carries the non-zero exit code of the
+// tool's process. Results of tools that exited with 0, or that have no exit code, do not set it.
+const ExitCodeMetaKey = "exitCode"
+
+// StabilityMetaKey is the _meta key of a listed tool that carries its stability: "experimental",
+// "stable", or "deprecated". The description of an experimental or deprecated tool is also
+// prefixed with it, for clients that do not read _meta.
+const StabilityMetaKey = "stability"
+
+// ResultExitCode returns the exit code carried by a tool result's _meta, and whether it has one
+func ResultExitCode(result *mcp.CallToolResult) (int, bool) {
+	if result == nil {
+		return 0, false
+	}
+	switch exitCode := result.Meta[ExitCodeMetaKey].(type) {
+	case int:
+		return exitCode, true
+	case float64:
+		// Results decoded from JSON carry numbers as float64
+		return int(exitCode), true
+	default:
+		return 0, false
+	}
+}
+
+// Types of content envelope items
+const (
+	contentEnvelopeTypeText  = "text"
+	contentEnvelopeTypeImage = "image"
+)
+
+// contentEnvelopeItem is a single content item in the content envelope
+type contentEnvelopeItem struct {
+	Type        string                  `json:"type,omitempty"` // "text" (the default) or "image"
+	Text        *string                 `json:"text"`
+	Data        []byte                  `json:"data,omitempty"` // base64-encoded image
+	Path        string                  `json:"path,omitempty"` // file holding the image, instead of data
+	Annotations *core.ContentAnnotation `json:"annotations,omitempty"`
+	ContentType string                  `json:"content_type,omitempty"`
+}
+
+// contentTypeMeta returns the _meta that declares the media type of a text content item,
+// or nil for plain text
+func contentTypeMeta(contentType string) mcp.Meta {
+	if contentType == "" || contentType == core.ContentTypePlainText {
+		return nil
+	}
+	return mcp.Meta{contentTypeMetaKey: contentType}
+}
+
+// textContentType returns the media type of text output of a tool with the given content type:
+// the content type itself, or plain text for an image type, whose tools only print text when
+// they fail or in a content envelope
+func textContentType(contentType string) string {
+	if core.IsImageContentType(contentType) {
+		return ""
+	}
+	return contentType
+}
+
+// toMCPAnnotations converts a content annotation to MCP annotations, returning nil if there is nothing to attach
+func toMCPAnnotations(annotation *core.ContentAnnotation) *mcp.Annotations {
+	if annotation == nil || (len(annotation.Audience) == 0 && annotation.Priority == nil) {
+		return nil
+	}
+
+	annotations := &mcp.Annotations{}
+	for _, audience := range annotation.Audience {
+		annotations.Audience = append(annotations.Audience, mcp.Role(audience))
+	}
+	if annotation.Priority != nil {
+		annotations.Priority = *annotation.Priority
+	}
+	return annotations
+}
+
+// parseContentEnvelope parses stdout as a content envelope. It returns false if stdout is not an
+// envelope, in which case stdout is returned to the client as a single text content item. Image
+// files are read relative to runDir and up to maxImageBytes, 0 for no limit.
+func parseContentEnvelope(stdout string, defaultAnnotation *core.ContentAnnotation, defaultContentType string, runDir string, maxImageBytes int64) ([]mcp.Content, bool) {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(stdout), &envelope); err != nil {
+		return nil, false
+	}
+
+	rawItems, ok := envelope[contentEnvelopeKey]
+	if !ok {
+		return nil, false
+	}
+
+	var items []contentEnvelopeItem
+	if err := json.Unmarshal(rawItems, &items); err != nil {
+		return nil, false
+	}
+
+	content := make([]mcp.Content, 0, len(items))
+	for _, item := range items {
+		annotation := item.Annotations
+		if annotation == nil {
+			annotation = defaultAnnotation
+		}
+
+		switch item.Type {
+		case contentEnvelopeTypeImage:
+			image, ok := envelopeImageContent(item, annotation, runDir, maxImageBytes)
+			if !ok {
+				return nil, false
+			}
+			content = append(content, image)
+			continue
+		case "", contentEnvelopeTypeText:
+		default:
+			return nil, false
+		}
+
+		if item.Text == nil {
+			return nil, false
+		}
+		contentType := item.ContentType
+		if contentType == "" {
+			contentType = defaultContentType
+		} else if _, _, err := mime.ParseMediaType(contentType); err != nil {
+			return nil, false
+		}
+		content = append(content, &mcp.TextContent{
+			Text:        *item.Text,
+			Meta:        contentTypeMeta(contentType),
+			Annotations: toMCPAnnotations(annotation),
+		})
+	}
+
+	return content, true
+}
+
+// imageContent returns data as image content of the given image media type, detecting the type
+// from data if it is empty. It returns false if the type is not an image type.
+func imageContent(data []byte, contentType string, annotation *core.ContentAnnotation) (*mcp.ImageContent, bool) {
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	if !core.IsImageContentType(contentType) {
+		return nil, false
+	}
+	return &mcp.ImageContent{
+		Data:        data,
+		MIMEType:    contentType,
+		Annotations: toMCPAnnotations(annotation),
+	}, true
+}
+
+// envelopeImageContent returns the image of an image item of the content envelope, reading it
+// from the item's path if it has no data. It returns false if the item has no image.
+func envelopeImageContent(item contentEnvelopeItem, annotation *core.ContentAnnotation, runDir string, maxBytes int64) (*mcp.ImageContent, bool) {
+	data := item.Data
+	if len(data) == 0 && item.Path != "" {
+		path := item.Path
+		if !filepath.IsAbs(path) && runDir != "" {
+			path = filepath.Join(runDir, path)
+		}
+		var err error
+		data, err = readImageFile(path, maxBytes)
+		if err != nil {
+			zap.L().Warn("Failed to read image of tool output", zap.String("path", path), zap.Error(err))
+			return nil, false
+		}
+	}
+	if len(data) == 0 {
+		return nil, false
+	}
+	return imageContent(data, item.ContentType, annotation)
+}
+
+// readImageFile reads the image file a tool wrote, failing if it is larger than maxBytes. A
+// maxBytes of 0 or less means no limit.
+func readImageFile(path string, maxBytes int64) ([]byte, error) {
+	// #nosec G304 -- the tool names a file it wrote, which it could print to stdout anyway
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer core.LogDeferredError(file.Close)
+
+	if maxBytes <= 0 {
+		return io.ReadAll(file)
+	}
+	// Reading one byte past the limit tells a file at the limit from a larger one
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("image file is larger than the output limit of %d bytes", maxBytes)
+	}
+	return data, nil
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// argEchoScript prints each argument it receives in brackets, one per line, then its stdin
+const argEchoScript = `#!/bin/sh
+for arg in "$@"; do
+  echo "[$arg]"
+done
+echo "stdin:$(cat)"
+`
+
+// callArgEchoTool calls a simple mode tool running argEchoScript with the given arg_style and
+// returns the call result
+func callArgEchoTool(t *testing.T, style core.ArgStyle, input map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	return callEchoTool(t, &core.ToolManifest{ArgStyle: style}, input)
+}
+
+// callEchoTool calls a simple mode tool running argEchoScript with the settings of tool and
+// returns the call result
+func callEchoTool(t *testing.T, tool *core.ToolManifest, input map[string]any) *mcp.CallToolResult {
+	t.Helper()
+
+	toolPath := filepath.Join(t.TempDir(), "args.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(toolPath, []byte(argEchoScript), 0755))
+
+	srv := NewOrlaServer(createTestConfig(t), "")
+	t.Cleanup(srv.Close)
+
+	tool.Name = "args-tool"
+	tool.Description = "Echoes its arguments"
+	tool.Path = toolPath
+	tool.Interpreter = "/bin/sh"
+	result, _, err := srv.handleToolCall(context.Background(), tool, input)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Content)
+	return result
+}
+
+// resultText returns the text of the first content item of result
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	text, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	return text.Text
+}
+
+func TestHandleToolCall_ArgStyles(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	input := map[string]any{
+		"create_dirs": true,
+		"path":        "a b",
+		"stdin":       "piped",
+	}
+
+	tests := []struct {
+		style core.ArgStyle
+		want  string
+	}{
+		{"", "[--create-dirs]\n[true]\n[--path]\n[a b]\nstdin:piped\n"},
+		{core.ArgStyleFlags, "[--create-dirs]\n[true]\n[--path]\n[a b]\nstdin:piped\n"},
+		{core.ArgStyleFlagsEquals, "[--create-dirs=true]\n[--path=a b]\nstdin:piped\n"},
+		{core.ArgStylePositional, "[true]\n[a b]\nstdin:piped\n"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.style), func(t *testing.T) {
+			result := callArgEchoTool(t, tt.style, input)
+			require.False(t, result.IsError)
+			assert.Equal(t, tt.want, resultText(t, result))
+		})
+	}
+}
+
+func TestHandleToolCall_ArgStyleJSONStdin(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	result := callArgEchoTool(t, core.ArgStyleJSONStdin, map[string]any{
+		"create_dirs": true,
+		"path":        "a b",
+		"count":       2,
+	})
+	require.False(t, result.IsError)
+	// No arguments, the input as JSON with its names and types unchanged
+	assert.Equal(t, `stdin:{"count":2,"create_dirs":true,"path":"a b"}`+"\n", resultText(t, result))
+
+	result = callArgEchoTool(t, core.ArgStyleJSONStdin, map[string]any{"stdin": "piped"})
+	require.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "arg_style json-stdin")
+}
+
+func TestHandleToolCall_StdinFormats(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	input := map[string]any{
+		"path":  "a b",
+		"count": 2,
+		"stdin": "piped",
+	}
+
+	tests := []struct {
+		format core.StdinFormat
+		want   string
+	}{
+		{"", "[--count]\n[2]\n[--path]\n[a b]\nstdin:piped\n"},
+		{core.StdinFormatRaw, "[--count]\n[2]\n[--path]\n[a b]\nstdin:piped\n"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			result := callEchoTool(t, &core.ToolManifest{StdinFormat: tt.format}, input)
+			require.False(t, result.IsError)
+			assert.Equal(t, tt.want, resultText(t, result))
+		})
+	}
+}
+
+func TestHandleToolCall_StdinFormatJSONArgs(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	// The arguments are still passed, and a copy of them is written to stdin
+	tool := &core.ToolManifest{StdinFormat: core.StdinFormatJSONArgs}
+	result := callEchoTool(t, tool, map[string]any{"path": "a b", "count": 2})
+	require.False(t, result.IsError)
+	assert.Equal(t, "[--count]\n[2]\n[--path]\n[a b]\n"+`stdin:{"count":2,"path":"a b"}`+"\n", resultText(t, result))
+
+	// The stdin arguments are left out of the JSON object
+	result = callEchoTool(t, tool, map[string]any{"path": "a b", "stdin": "piped"})
+	require.False(t, result.IsError)
+	assert.Equal(t, "[--path]\n[a b]\n"+`stdin:{"path":"a b"}`+"\n", resultText(t, result))
+}
+
+func TestHandleToolCall_StdinFormatNone(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	tool := &core.ToolManifest{StdinFormat: core.StdinFormatNone}
+	result := callEchoTool(t, tool, map[string]any{"path": "a b"})
+	require.False(t, result.IsError)
+	assert.Equal(t, "[--path]\n[a b]\nstdin:\n", resultText(t, result))
+
+	result = callEchoTool(t, tool, map[string]any{"stdin": "piped"})
+	require.True(t, result.IsError)
+	assert.Contains(t, resultText(t, result), "stdin_format none")
+}
@@ -0,0 +1,236 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// auditRedactedValue replaces the values of redacted arguments in the audit log
+const auditRedactedValue = "[REDACTED]"
+
+// auditRecord is one line of the audit log, written when a tool call completes
+type auditRecord struct {
+	Time        time.Time      `json:"time"`
+	Tool        string         `json:"tool"`
+	RuntimeMode string         `json:"runtime_mode"`
+	Arguments   map[string]any `json:"arguments,omitempty"`
+	Status      string         `json:"status"`              // ok, error, or timeout, as in the tool call metrics
+	ExitCode    *int           `json:"exit_code,omitempty"` // exit code of simple and persistent mode tools that ran to completion
+	Error       string         `json:"error,omitempty"`
+	DurationMs  float64        `json:"duration_ms"`
+	SessionID   string         `json:"session_id,omitempty"` // MCP session of the caller, empty for orla run and stdio
+}
+
+// auditLogger appends a JSON line per tool call to the audit log at audit_log_path. It is kept
+// apart from the zap log so that it is complete whatever the log level, and append-only.
+type auditLogger struct {
+	mu         sync.Mutex
+	path       string
+	file       *os.File
+	redactKeys map[string]struct{} // lowercased argument names whose values are masked
+}
+
+// openAuditLogger opens the audit log at path for appending, creating it if needed
+func openAuditLogger(path string, redactKeys []string) (*auditLogger, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		// #nosec G301 -- the audit log directory is owned by the user running the server
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+		}
+	}
+
+	// #nosec G304 -- the audit log path comes from the config
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	logger := &auditLogger{path: path, file: file}
+	logger.setRedactKeys(redactKeys)
+	return logger, nil
+}
+
+// setRedactKeys replaces the argument names whose values are masked
+func (a *auditLogger) setRedactKeys(redactKeys []string) {
+	keys := make(map[string]struct{}, len(redactKeys))
+	for _, key := range redactKeys {
+		keys[strings.ToLower(key)] = struct{}{}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.redactKeys = keys
+}
+
+// record appends a record to the audit log, redacting its arguments
+func (a *auditLogger) record(record *auditRecord) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.file == nil {
+		return os.ErrClosed
+	}
+
+	if args, ok := a.redact(record.Arguments).(map[string]any); ok {
+		record.Arguments = args
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	// A single write of a whole line to a file opened with O_APPEND is not interleaved with
+	// the lines of other processes appending to the same log
+	if _, err := a.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
+
+// redact returns a copy of value with the values of redacted keys masked at any depth. The caller
+// must hold a.mu.
+func (a *auditLogger) redact(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for key, item := range v {
+			if _, ok := a.redactKeys[strings.ToLower(key)]; ok {
+				redacted[key] = auditRedactedValue
+				continue
+			}
+			redacted[key] = a.redact(item)
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(v))
+		for i, item := range v {
+			redacted[i] = a.redact(item)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
+
+// close closes the audit log. Records of calls that complete afterwards are dropped.
+func (a *auditLogger) close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.file == nil {
+		return nil
+	}
+	err := a.file.Close()
+	a.file = nil
+	return err
+}
+
+// updateAuditLogLocked opens, reopens, or closes the audit log to match the current config. The
+// caller must hold o.mu for writing.
+func (o *OrlaServer) updateAuditLogLocked() {
+	path := o.config.AuditLogPath
+	if o.audit != nil && o.audit.path == path {
+		o.audit.setRedactKeys(o.config.AuditRedactKeys)
+		return
+	}
+
+	o.closeAuditLogLocked()
+	if path == "" {
+		return
+	}
+
+	audit, err := openAuditLogger(path, o.config.AuditRedactKeys)
+	if err != nil {
+		zap.L().Error("Failed to open audit log, tool calls are not audited", zap.String("audit_log_path", path), zap.Error(err))
+		return
+	}
+	o.audit = audit
+}
+
+// closeAuditLogLocked closes the audit log, if any. The caller must hold o.mu for writing.
+func (o *OrlaServer) closeAuditLogLocked() {
+	if o.audit == nil {
+		return
+	}
+	if err := o.audit.close(); err != nil {
+		zap.L().Warn("Failed to close audit log", zap.String("audit_log_path", o.audit.path), zap.Error(err))
+	}
+	o.audit = nil
+}
+
+// auditToolCall records a completed tool call in the audit log, if audit_log_path is set
+func (o *OrlaServer) auditToolCall(
+	tool *core.ToolManifest,
+	input map[string]any,
+	sessionID string,
+	startTime time.Time,
+	result *mcp.CallToolResult,
+	callErr error,
+	status string,
+) {
+	o.mu.RLock()
+	audit := o.audit
+	o.mu.RUnlock()
+	if audit == nil {
+		return
+	}
+
+	runtimeMode := core.RuntimeModeSimple
+	if tool.Runtime != nil {
+		runtimeMode = tool.Runtime.Mode
+	}
+
+	record := &auditRecord{
+		Time:        startTime.UTC(),
+		Tool:        tool.Name,
+		RuntimeMode: string(runtimeMode),
+		Arguments:   input,
+		Status:      status,
+		DurationMs:  float64(time.Since(startTime).Microseconds()) / 1000,
+		SessionID:   sessionID,
+	}
+	if runtimeMode != core.RuntimeModeCapsule {
+		record.ExitCode = toolCallExitCode(result, callErr)
+	}
+	if callErr != nil {
+		record.Error = callErr.Error()
+	}
+
+	if err := audit.record(record); err != nil {
+		zap.L().Error("Failed to write audit record", zap.String("tool", tool.Name), zap.Error(err))
+	}
+}
+
+// toolCallExitCode returns the exit code of a tool process from its call result: the exit code
+// in its _meta if it failed, 0 if it succeeded, or nil if it did not run to completion
+func toolCallExitCode(result *mcp.CallToolResult, callErr error) *int {
+	if result == nil || callErr != nil {
+		return nil
+	}
+	if exitCode, ok := result.Meta[ExitCodeMetaKey].(int); ok {
+		return &exitCode
+	}
+	if result.IsError {
+		return nil
+	}
+	exitCode := 0
+	return &exitCode
+}
+
+// callSessionID returns the ID of the MCP session a tool call request arrived on, or "" if none
+func callSessionID(req *mcp.CallToolRequest) string {
+	if req == nil || req.Session == nil {
+		return ""
+	}
+	return req.Session.ID()
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// readAuditRecords reads the records of the audit log at path
+func readAuditRecords(t *testing.T, path string) []auditRecord {
+	t.Helper()
+
+	file, err := os.Open(path) // #nosec G304 -- test reads the audit log it configured
+	require.NoError(t, err)
+	defer core.LogDeferredError(file.Close)
+
+	var records []auditRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record auditRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record), scanner.Text())
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+// createAuditTestConfig returns a test config that writes the audit log to a temporary file
+func createAuditTestConfig(t *testing.T) (*config.OrlaConfig, string) {
+	t.Helper()
+
+	cfg := createTestConfig(t)
+	cfg.AuditLogPath = filepath.Join(t.TempDir(), "audit", "calls.jsonl")
+	cfg.AuditRedactKeys = []string{"api_key", "password"}
+	return cfg, cfg.AuditLogPath
+}
+
+func TestAuditLog_RecordsToolCalls(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg, auditPath := createAuditTestConfig(t)
+	failPath := filepath.Join(cfg.ToolsDir, "fail-tool.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(failPath, []byte("#!/bin/sh\nexit 3\n"), 0755))
+	require.NoError(t, cfg.ToolsRegistry.AddTool(&core.ToolManifest{Name: "fail-tool", Description: "A failing tool", Path: failPath}))
+
+	srv := NewOrlaServer(cfg, "")
+	t.Cleanup(srv.Close)
+
+	before := time.Now().UTC()
+	result, err := srv.CallTool(context.Background(), "test-tool", map[string]any{
+		"query":   "weather",
+		"API_KEY": "s3cret",
+		"options": map[string]any{"password": "hunter2", "retries": 2},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	result, err = srv.CallTool(context.Background(), "fail-tool", map[string]any{})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+
+	records := readAuditRecords(t, auditPath)
+	require.Len(t, records, 2)
+
+	ok := records[0]
+	assert.Equal(t, "test-tool", ok.Tool)
+	assert.Equal(t, string(core.RuntimeModeSimple), ok.RuntimeMode)
+	assert.Equal(t, toolCallResultOK, ok.Status)
+	require.NotNil(t, ok.ExitCode)
+	assert.Equal(t, 0, *ok.ExitCode)
+	assert.Empty(t, ok.Error)
+	assert.Empty(t, ok.SessionID)
+	assert.False(t, ok.Time.Before(before.Truncate(time.Second)))
+	assert.GreaterOrEqual(t, ok.DurationMs, 0.0)
+	assert.Equal(t, map[string]any{
+		"query":   "weather",
+		"API_KEY": auditRedactedValue,
+		"options": map[string]any{"password": auditRedactedValue, "retries": float64(2)},
+	}, ok.Arguments)
+
+	failed := records[1]
+	assert.Equal(t, "fail-tool", failed.Tool)
+	assert.Equal(t, toolCallResultError, failed.Status)
+	require.NotNil(t, failed.ExitCode)
+	assert.Equal(t, 3, *failed.ExitCode)
+
+	// The raw audit log never contains the redacted values
+	data, err := os.ReadFile(auditPath) // #nosec G304 -- test reads the audit log it configured
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cret")
+	assert.NotContains(t, string(data), "hunter2")
+}
+
+func TestAuditLog_CapsuleToolCall(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping capsule test on Windows")
+	}
+
+	cfg, auditPath := createAuditTestConfig(t)
+	tool := addSlowCapsuleTool(t, cfg)
+	srv := NewOrlaServer(cfg, "")
+	t.Cleanup(srv.Close)
+
+	result, output, err := srv.executeToolCall(context.Background(), tool, map[string]any{"password": "hunter2"}, nil, "session-1", nil)
+	require.NoError(t, err)
+	require.False(t, result.IsError, output)
+
+	records := readAuditRecords(t, auditPath)
+	require.Len(t, records, 1)
+	assert.Equal(t, "slow-tool", records[0].Tool)
+	assert.Equal(t, string(core.RuntimeModeCapsule), records[0].RuntimeMode)
+	assert.Equal(t, toolCallResultOK, records[0].Status)
+	assert.Nil(t, records[0].ExitCode)
+	assert.Equal(t, "session-1", records[0].SessionID)
+	assert.Equal(t, map[string]any{"password": auditRedactedValue}, records[0].Arguments)
+	assert.GreaterOrEqual(t, records[0].DurationMs, 1000.0)
+}
+
+func TestAuditLog_CapsuleNotRunning(t *testing.T) {
+	cfg, auditPath := createAuditTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+	t.Cleanup(srv.Close)
+
+	tool := &core.ToolManifest{Name: "missing-capsule", Runtime: &core.RuntimeConfig{Mode: core.RuntimeModeCapsule}}
+	_, _, err := srv.executeToolCall(context.Background(), tool, nil, nil, "", nil)
+	require.Error(t, err)
+
+	records := readAuditRecords(t, auditPath)
+	require.Len(t, records, 1)
+	assert.Equal(t, toolCallResultError, records[0].Status)
+	assert.Contains(t, records[0].Error, "capsule not found")
+}
+
+func TestAuditLog_Disabled(t *testing.T) {
+	srv := NewOrlaServer(createTestConfig(t), "")
+	t.Cleanup(srv.Close)
+	assert.Nil(t, srv.audit)
+}
+
+func TestAuditLogger_RedactsNestedValues(t *testing.T) {
+	audit, err := openAuditLogger(filepath.Join(t.TempDir(), "audit.jsonl"), []string{"Token"})
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, audit.close()) })
+
+	args := map[string]any{
+		"items": []any{map[string]any{"token": "a"}, "token"},
+		"name":  "x",
+	}
+	assert.Equal(t, map[string]any{
+		"items": []any{map[string]any{"token": auditRedactedValue}, "token"},
+		"name":  "x",
+	}, audit.redact(args))
+	// The arguments passed to the tool are left untouched
+	assert.Equal(t, "a", args["items"].([]any)[0].(map[string]any)["token"])
+
+	require.NoError(t, audit.close())
+	assert.ErrorIs(t, audit.record(&auditRecord{Tool: "x"}), os.ErrClosed)
+}
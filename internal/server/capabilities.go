@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// CapabilitiesPath is the HTTP path of the capabilities endpoint
+const CapabilitiesPath = "/capabilities"
+
+// Capabilities describes the features the server has enabled, so that clients can check what
+// they can rely on before using it. It is built from the active config and rebuilt on reload.
+type Capabilities struct {
+	Version             string   `json:"version"`
+	HTTPTransport       string   `json:"http_transport"`
+	Endpoints           []string `json:"endpoints"`
+	Streaming           bool     `json:"streaming"`             // tool output streamed as progress notifications over SSE
+	Resources           bool     `json:"resources"`             // MCP resources, not supported yet
+	Prompts             bool     `json:"prompts"`               // MCP prompts, not supported yet
+	Metrics             bool     `json:"metrics"`               // the Prometheus metrics endpoint, enabled with metrics_enabled
+	Auth                bool     `json:"auth"`                  // authentication of HTTP clients, not supported yet
+	Admin               bool     `json:"admin"`                 // the admin endpoints used by orla top and orla tool disable/enable, enabled with admin_enabled
+	ToolsHashHeader     bool     `json:"tools_hash_header"`     // the Orla-Tools-Hash header on MCP responses
+	HideDeprecatedTools bool     `json:"hide_deprecated_tools"` // deprecated tools are not registered
+	TraceTools          bool     `json:"trace_tools"`           // tool command lines are logged
+	ToolFilter          bool     `json:"tool_filter"`           // tools are limited with orla serve --only/--skip
+}
+
+// buildCapabilities returns the capabilities of the server under its current config. The caller
+// must hold o.mu.
+func (o *OrlaServer) buildCapabilities() *Capabilities {
+	transport := o.httpTransport()
+
+	endpoints := []string{MCPPath}
+	if transport != config.OrlaHTTPTransportStreamable {
+		endpoints = append(endpoints, MCPJSONPath)
+	}
+	if o.config.AdminEnabled {
+		endpoints = append(endpoints, AdminStatePath, AdminToolsPath, CapabilitiesPath)
+	}
+	metricsPath, metrics := o.metricsPath()
+	if metrics {
+		endpoints = append(endpoints, metricsPath)
+	}
+
+	return &Capabilities{
+		Version:             core.OrlaVersion(),
+		HTTPTransport:       string(transport),
+		Endpoints:           endpoints,
+		Streaming:           transport != config.OrlaHTTPTransportHTTP,
+		Metrics:             metrics,
+		Admin:               o.config.AdminEnabled,
+		ToolsHashHeader:     true,
+		HideDeprecatedTools: o.config.HideDeprecatedTools,
+		TraceTools:          o.config.TraceTools,
+		ToolFilter:          len(o.toolFilter.Only) > 0 || len(o.toolFilter.Skip) > 0,
+	}
+}
+
+// Capabilities returns the capabilities of the server, as of the last start or reload
+func (o *OrlaServer) Capabilities() *Capabilities {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.capabilities
+}
+
+// handleCapabilities serves the capabilities of the server as JSON
+func (o *OrlaServer) handleCapabilities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(o.Capabilities()); err != nil {
+		zap.L().Error("Failed to encode capabilities", zap.Error(err))
+	}
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/config"
+)
+
+func TestCapabilities(t *testing.T) {
+	tests := []struct {
+		name       string
+		configure  func(cfg *config.OrlaConfig)
+		toolFilter ToolFilter
+		expected   Capabilities
+	}{
+		{
+			name:      "defaults",
+			configure: func(*config.OrlaConfig) {},
+			expected: Capabilities{
+				HTTPTransport:   string(config.OrlaHTTPTransportBoth),
+				Endpoints:       []string{MCPPath, MCPJSONPath},
+				Streaming:       true,
+				ToolsHashHeader: true,
+			},
+		},
+		{
+			name: "plain HTTP with admin, deprecated tools hidden, and tracing",
+			configure: func(cfg *config.OrlaConfig) {
+				cfg.AdminEnabled = true
+				cfg.HTTPTransport = config.OrlaHTTPTransportHTTP
+				cfg.HideDeprecatedTools = true
+				cfg.TraceTools = true
+			},
+			toolFilter: ToolFilter{Only: []string{"test-tool"}},
+			expected: Capabilities{
+				HTTPTransport:       string(config.OrlaHTTPTransportHTTP),
+				Endpoints:           []string{MCPPath, MCPJSONPath, AdminStatePath, AdminToolsPath, CapabilitiesPath},
+				Admin:               true,
+				ToolsHashHeader:     true,
+				HideDeprecatedTools: true,
+				TraceTools:          true,
+				ToolFilter:          true,
+			},
+		},
+		{
+			name: "metrics on a custom path",
+			configure: func(cfg *config.OrlaConfig) {
+				cfg.MetricsEnabled = true
+				cfg.MetricsPath = "/stats"
+			},
+			expected: Capabilities{
+				HTTPTransport:   string(config.OrlaHTTPTransportBoth),
+				Endpoints:       []string{MCPPath, MCPJSONPath, "/stats"},
+				Streaming:       true,
+				Metrics:         true,
+				ToolsHashHeader: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig(t)
+			tt.configure(cfg)
+			srv := NewOrlaServerWithToolFilter(cfg, "", tt.toolFilter)
+
+			capabilities := srv.Capabilities()
+			require.NotNil(t, capabilities)
+			assert.NotEmpty(t, capabilities.Version)
+			tt.expected.Version = capabilities.Version
+			assert.Equal(t, tt.expected, *capabilities)
+			assert.False(t, capabilities.Resources || capabilities.Prompts || capabilities.Auth,
+				"unsupported features should not be advertised")
+		})
+	}
+}
+
+func TestCapabilities_Reload(t *testing.T) {
+	cfg := createTestConfig(t)
+	configPath := filepath.Join(t.TempDir(), "orla.yaml")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte("tools_dir: "+cfg.ToolsDir+"\n"), 0644))
+
+	srv := NewOrlaServer(cfg, configPath)
+	assert.True(t, srv.Capabilities().Streaming)
+	assert.False(t, srv.Capabilities().HideDeprecatedTools)
+
+	configYAML := "tools_dir: " + cfg.ToolsDir + "\nhttp_transport: http\nhide_deprecated_tools: true\n"
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configYAML), 0644))
+	require.NoError(t, srv.Reload())
+
+	capabilities := srv.Capabilities()
+	assert.Equal(t, string(config.OrlaHTTPTransportHTTP), capabilities.HTTPTransport)
+	assert.False(t, capabilities.Streaming, "plain HTTP does not stream tool output")
+	assert.True(t, capabilities.HideDeprecatedTools)
+	assert.Contains(t, capabilities.Endpoints, MCPPath, "/mcp is served with every transport")
+}
+
+func TestHandleCapabilities(t *testing.T) {
+	cfg := createTestConfig(t)
+	cfg.HTTPTransport = config.OrlaHTTPTransportStreamable
+	cfg.AdminEnabled = true
+	srv := NewOrlaServer(cfg, "")
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, CapabilitiesPath, nil)
+	req.RemoteAddr = "127.0.0.1:50000"
+	srv.httpMux().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var capabilities Capabilities
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &capabilities))
+	assert.Equal(t, *srv.Capabilities(), capabilities)
+	assert.Equal(t, []string{MCPPath, AdminStatePath, AdminToolsPath, CapabilitiesPath}, capabilities.Endpoints)
+
+	rec = httptest.NewRecorder()
+	srv.handleCapabilities(rec, httptest.NewRequest(http.MethodPost, CapabilitiesPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
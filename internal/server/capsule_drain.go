@@ -0,0 +1,66 @@
+package server
+
+import (
+	"reflect"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// takeCapsules removes all capsules from o.capsules and returns them by tool name. Rebuilding the
+// tools takes the running capsules this way, so that calls are no longer routed to them, and then
+// either reuses each one (see reusableCapsule) or drains it (see drainCapsules).
+func (o *OrlaServer) takeCapsules() map[string]*core.CapsuleManager {
+	capsules := make(map[string]*core.CapsuleManager)
+	o.capsules.Range(func(name string, capsule *core.CapsuleManager) bool {
+		// Deleting each capsule keeps its supervisor from replacing it while it is set aside
+		o.capsules.Delete(name)
+		capsules[name] = capsule
+		return true
+	})
+	return capsules
+}
+
+// reusableCapsule returns the capsule that tool ran in before the tools were rebuilt, if it is
+// still running and healthy and was started for the same path, version, and runtime config.
+// The capsule is taken from o.previousCapsules, so it is not drained. The caller must hold o.mu.
+func (o *OrlaServer) reusableCapsule(tool *core.ToolManifest) (*core.CapsuleManager, bool) {
+	capsule, ok := o.previousCapsules[tool.Name]
+	if !ok || !capsule.IsReady() || !capsule.IsHealthy() || !sameCapsuleManifest(capsule.Tool(), tool) {
+		return nil, false
+	}
+
+	delete(o.previousCapsules, tool.Name)
+	return capsule, true
+}
+
+// sameCapsuleManifest reports whether a capsule started for manifest a can serve manifest b
+func sameCapsuleManifest(a, b *core.ToolManifest) bool {
+	return a.Path == b.Path && a.Version == b.Version && reflect.DeepEqual(a.Runtime, b.Runtime)
+}
+
+// drainCapsules drains capsules in the background: each refuses new calls at once, and is stopped
+// once its calls in flight finish or grace runs out, whichever comes first
+func drainCapsules(capsules map[string]*core.CapsuleManager, grace time.Duration) {
+	for name, capsule := range capsules {
+		capsule.BeginDrain()
+		go func() {
+			drained, err := capsule.Drain(grace)
+			if err != nil {
+				zap.L().Error("Failed to stop drained capsule",
+					zap.String("tool", name),
+					zap.Error(err))
+				return
+			}
+			if !drained {
+				zap.L().Warn("Stopped capsule with calls still in flight after the drain timeout",
+					zap.String("tool", name),
+					zap.Duration("timeout", grace))
+				return
+			}
+			zap.L().Debug("Drained capsule", zap.String("tool", name))
+		}()
+	}
+}
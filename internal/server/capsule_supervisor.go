@@ -0,0 +1,138 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// startCapsule starts a capsule for tool and supervises it, so that it is restarted if its process
+// exits unexpectedly. restarts is the number of times the tool's capsule was already restarted,
+// and maxOutputBytes limits the size of each message the capsule writes. The caller stores the
+// capsule in o.capsules.
+func (o *OrlaServer) startCapsule(tool *core.ToolManifest, restarts int, maxOutputBytes int64) (*core.CapsuleManager, error) {
+	capsule := core.NewCapsuleManager(tool)
+	capsule.SetMaxOutputBytes(maxOutputBytes)
+	if err := capsule.Start(); err != nil {
+		return nil, err
+	}
+
+	go o.superviseCapsule(tool, capsule, restarts)
+	return capsule, nil
+}
+
+// superviseCapsule waits for the process of capsule to exit. If it exited unexpectedly, the capsule
+// is restarted up to the tool's runtime.max_restarts times in total, waiting with a doubling backoff
+// before each attempt. If the restarts are exhausted, the tool is removed from the server.
+func (o *OrlaServer) superviseCapsule(tool *core.ToolManifest, capsule *core.CapsuleManager, restarts int) {
+	<-capsule.Done()
+	if !capsule.Crashed() {
+		return
+	}
+
+	maxRestarts := core.DefaultCapsuleMaxRestarts
+	if tool.Runtime != nil && tool.Runtime.MaxRestarts > 0 {
+		maxRestarts = tool.Runtime.MaxRestarts
+	}
+
+	backoff := time.Duration(core.DefaultCapsuleRestartBackoffMs) * time.Millisecond << restarts
+	for ; restarts < maxRestarts; restarts++ {
+		time.Sleep(backoff)
+		backoff *= 2
+
+		// The tool may have been removed or reloaded in the meantime
+		if current, ok := o.capsules.Load(tool.Name); !ok || current != capsule {
+			return
+		}
+
+		zap.L().Warn("Restarting capsule that exited unexpectedly",
+			zap.String("tool", tool.Name),
+			zap.Int("attempt", restarts+1),
+			zap.Int("max_restarts", maxRestarts))
+
+		restarted, err := o.startCapsule(tool, restarts+1, o.toolExecutor().MaxOutputBytes())
+		if err != nil {
+			zap.L().Error("Failed to restart capsule", zap.String("tool", tool.Name), zap.Error(err))
+			continue
+		}
+
+		if !o.replaceCapsule(tool.Name, capsule, restarted) {
+			o.stopReplacedCapsule(tool.Name, restarted)
+			return
+		}
+		o.metrics.observeCapsuleRestart(tool.Name)
+		zap.L().Info("Capsule restarted", zap.String("tool", tool.Name))
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if current, ok := o.capsules.Load(tool.Name); !ok || current != capsule {
+		return
+	}
+	o.removeTool(tool)
+	zap.L().Error("Capsule keeps exiting unexpectedly, the tool is unavailable until orla reloads",
+		zap.String("tool", tool.Name),
+		zap.Int("restarts", maxRestarts))
+}
+
+// restartCapsule replaces the unhealthy capsule of tool with a newly started one and returns it.
+// If another call already replaced it, the replacement is returned instead.
+func (o *OrlaServer) restartCapsule(tool *core.ToolManifest, unhealthy *core.CapsuleManager) (*core.CapsuleManager, error) {
+	o.capsuleRestartMu.Lock()
+	defer o.capsuleRestartMu.Unlock()
+
+	current, ok := o.capsules.Load(tool.Name)
+	if !ok {
+		return nil, fmt.Errorf("capsule not found: %s", tool.Name)
+	}
+	if current != unhealthy {
+		return current, nil
+	}
+
+	zap.L().Warn("Restarting unhealthy capsule", zap.String("tool", tool.Name))
+	if err := unhealthy.Stop(); err != nil {
+		zap.L().Error("Failed to stop unhealthy capsule", zap.String("tool", tool.Name), zap.Error(err))
+	}
+
+	capsule, err := o.startCapsule(tool, 0, o.toolExecutor().MaxOutputBytes())
+	if err != nil {
+		o.capsules.Compute(tool.Name, func(current *core.CapsuleManager, loaded bool) (*core.CapsuleManager, bool) {
+			return current, !loaded || current == unhealthy
+		})
+		return nil, fmt.Errorf("failed to restart capsule: %w", err)
+	}
+
+	if !o.replaceCapsule(tool.Name, unhealthy, capsule) {
+		o.stopReplacedCapsule(tool.Name, capsule)
+		return nil, fmt.Errorf("capsule not found: %s", tool.Name)
+	}
+	o.metrics.observeCapsuleRestart(tool.Name)
+	zap.L().Info("Capsule restarted", zap.String("tool", tool.Name))
+	return capsule, nil
+}
+
+// replaceCapsule stores capsule as the capsule of the named tool if old is still its capsule,
+// and reports whether it did. It fails if the tool was removed or reloaded since old was started.
+func (o *OrlaServer) replaceCapsule(name string, old *core.CapsuleManager, capsule *core.CapsuleManager) bool {
+	replaced := false
+	o.capsules.Compute(name, func(current *core.CapsuleManager, loaded bool) (*core.CapsuleManager, bool) {
+		if !loaded || current != old {
+			return current, !loaded
+		}
+		replaced = true
+		return capsule, false
+	})
+	return replaced
+}
+
+// stopReplacedCapsule stops a restarted capsule that is not needed, because its tool was removed
+// or reloaded while it was starting
+func (o *OrlaServer) stopReplacedCapsule(name string, capsule *core.CapsuleManager) {
+	if err := capsule.Stop(); err != nil {
+		zap.L().Error("Failed to stop restarted capsule", zap.String("tool", name), zap.Error(err))
+	}
+}
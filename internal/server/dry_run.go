@@ -0,0 +1,119 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// dryRun reports whether tool calls are only described rather than executed, as set by dry_run
+func (o *OrlaServer) dryRun() bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.config.DryRun
+}
+
+// dryRunCommandResult describes the command a simple mode tool call would run, without running it
+func dryRunCommandResult(executor *core.OrlaToolExecutor, tool *core.ToolManifest, args []string, input map[string]any) (*mcp.CallToolResult, map[string]any, error) {
+	trace := executor.TraceCommand(tool, args)
+	if trace.Args == nil {
+		trace.Args = []string{}
+	}
+	interpreter := tool.Interpreter
+	if interpreter == "" {
+		interpreter = "none"
+	}
+	stdin := describeCallStdin(tool, input)
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "Dry run: tool '%s' was not executed. It would run:\n", tool.Name)
+	fmt.Fprintf(&text, "Command: %s\n", trace.CommandLine())
+	fmt.Fprintf(&text, "Interpreter: %s\n", interpreter)
+	fmt.Fprintf(&text, "Working directory: %s\n", trace.Dir)
+	fmt.Fprintf(&text, "Arguments: %s\n", dryRunJSON(trace.Args))
+	fmt.Fprintf(&text, "Stdin: %s", stdin)
+	if len(trace.Env) > 0 {
+		fmt.Fprintf(&text, "\nEnvironment: %s", strings.Join(trace.Env, " "))
+	}
+
+	output := map[string]any{
+		"dry_run":     true,
+		"command":     trace.CommandLine(),
+		"program":     trace.Program,
+		"args":        trace.Args,
+		"interpreter": tool.Interpreter,
+		"cwd":         trace.Dir,
+		"stdin":       stdin,
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text.String()}}}, dryRunOutput(tool, output), nil
+}
+
+// dryRunRequestResult describes the request a capsule or persistent mode tool call would send to
+// the tool's running process, without sending it
+func dryRunRequestResult(tool *core.ToolManifest, runtimeMode core.RuntimeMode, request any) (*mcp.CallToolResult, map[string]any, error) {
+	text := fmt.Sprintf("Dry run: tool '%s' was not called. It would send its %s process:\n%s",
+		tool.Name, runtimeMode, dryRunJSON(request))
+	output := map[string]any{
+		"dry_run":      true,
+		"runtime_mode": string(runtimeMode),
+		"request":      request,
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, dryRunOutput(tool, output), nil
+}
+
+// dryRunOutput returns the structured output of a dry run, or nil for a tool that declares an
+// output schema: the SDK validates structured output against it, and the description of the call
+// would not match. The description is in the result's text content either way.
+func dryRunOutput(tool *core.ToolManifest, output map[string]any) map[string]any {
+	if tool.MCP != nil && tool.MCP.OutputSchema != nil {
+		return nil
+	}
+	return output
+}
+
+// capsuleDryRunRequest returns the JSON-RPC request a capsule call sends, without its ID, which
+// is only assigned when the call is sent
+func capsuleDryRunRequest(tool *core.ToolManifest, input map[string]any) map[string]any {
+	return map[string]any{
+		"jsonrpc": "2.0",
+		"method":  "tools/call",
+		"params": map[string]any{
+			"name":      tool.Name,
+			"arguments": input,
+		},
+	}
+}
+
+// describeCallStdin describes where the stdin of a simple mode tool call would come from
+func describeCallStdin(tool *core.ToolManifest, input map[string]any) string {
+	if core.ArgStyleOf(tool) == core.ArgStyleJSONStdin {
+		return "the call's arguments as a JSON object"
+	}
+	switch core.StdinFormatOf(tool) {
+	case core.StdinFormatJSONArgs:
+		return "a copy of the call's arguments without the stdin arguments as a JSON object"
+	case core.StdinFormatNone:
+		return "none"
+	}
+	if _, ok := input[stdinArgKey].(string); ok {
+		return fmt.Sprintf("the %s argument", stdinArgKey)
+	}
+	if input[stdinFileArgKey] != nil {
+		return fmt.Sprintf("the file %v", input[stdinFileArgKey])
+	}
+	return "none"
+}
+
+// dryRunJSON returns value as JSON for a dry run description. Tool arguments always encode, as
+// they were decoded from JSON.
+func dryRunJSON(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
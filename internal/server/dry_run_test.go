@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// dryRunText returns the text of a dry run result
+func dryRunText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	require.False(t, result.IsError)
+	require.Len(t, result.Content, 1)
+	text, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	return text.Text
+}
+
+func TestDryRun_SimpleTool(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	cfg.DryRun = true
+	srv := NewOrlaServer(cfg, "")
+	t.Cleanup(srv.Close)
+
+	markerPath := filepath.Join(t.TempDir(), "ran")
+	toolPath := filepath.Join(t.TempDir(), "touch-tool.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(toolPath, []byte("#!/bin/sh\ntouch '"+markerPath+"'\n"), 0755))
+	tool := &core.ToolManifest{
+		Name:        "touch-tool",
+		Description: "Touch tool",
+		Path:        toolPath,
+		Interpreter: "/bin/sh",
+	}
+
+	result, output, err := srv.handleToolCall(context.Background(), tool, map[string]any{"name": "two words", "stdin": "hello"})
+	require.NoError(t, err)
+	text := dryRunText(t, result)
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Contains(t, text, "Dry run: tool 'touch-tool' was not executed")
+	assert.Contains(t, text, "Command: /bin/sh "+toolPath+" --name 'two words'")
+	assert.Contains(t, text, "Interpreter: /bin/sh")
+	assert.Contains(t, text, "Working directory: "+cwd)
+	assert.Contains(t, text, `Arguments: ["`+toolPath+`","--name","two words"]`)
+	assert.Contains(t, text, "Stdin: the stdin argument")
+
+	assert.Equal(t, true, output["dry_run"])
+	assert.Equal(t, "/bin/sh", output["program"])
+	assert.Equal(t, []string{toolPath, "--name", "two words"}, output["args"])
+
+	// The tool never ran
+	assert.NoFileExists(t, markerPath)
+}
+
+func TestDryRun_CapsuleTool(t *testing.T) {
+	cfg := createTestConfig(t)
+	cfg.DryRun = true
+	srv := NewOrlaServer(cfg, "")
+	t.Cleanup(srv.Close)
+
+	// The capsule is not running, and is not needed to describe the call
+	tool := &core.ToolManifest{
+		Name:    "capsule-tool",
+		Runtime: &core.RuntimeConfig{Mode: core.RuntimeModeCapsule},
+		MCP: &core.MCPConfig{InputSchema: map[string]any{
+			"type":     "object",
+			"required": []any{"query"},
+		}},
+	}
+
+	result, output, err := srv.handleToolCall(context.Background(), tool, map[string]any{"query": "weather"})
+	require.NoError(t, err)
+	text := dryRunText(t, result)
+	assert.Contains(t, text, "Dry run: tool 'capsule-tool' was not called. It would send its capsule process:")
+	assert.Contains(t, text, `{"jsonrpc":"2.0","method":"tools/call","params":{"arguments":{"query":"weather"},"name":"capsule-tool"}}`)
+	assert.Equal(t, string(core.RuntimeModeCapsule), output["runtime_mode"])
+}
+
+func TestDryRun_PersistentTool(t *testing.T) {
+	cfg := createTestConfig(t)
+	cfg.DryRun = true
+	srv := NewOrlaServer(cfg, "")
+	t.Cleanup(srv.Close)
+
+	tool := &core.ToolManifest{
+		Name:    "persistent-tool",
+		Runtime: &core.RuntimeConfig{Mode: core.RuntimeModePersistent},
+	}
+
+	result, _, err := srv.handleToolCall(context.Background(), tool, nil)
+	require.NoError(t, err)
+	text := dryRunText(t, result)
+	assert.Contains(t, text, "It would send its persistent process:\n{}")
+}
+
+func TestDryRun_ToolWithOutputSchema(t *testing.T) {
+	cfg := createTestConfig(t)
+	cfg.DryRun = true
+	srv := NewOrlaServer(cfg, "")
+	t.Cleanup(srv.Close)
+
+	// The description of the call does not match the tool's output schema, so it is only sent as text
+	tool := &core.ToolManifest{
+		Name:        "schema-tool",
+		Description: "Schema tool",
+		Path:        filepath.Join(t.TempDir(), "schema-tool.sh"),
+		MCP: &core.MCPConfig{OutputSchema: map[string]any{
+			"type":       "object",
+			"required":   []any{"answer"},
+			"properties": map[string]any{"answer": map[string]any{"type": "string"}},
+		}},
+	}
+	require.NoError(t, cfg.ToolsRegistry.AddTool(tool))
+	srv.rebuildServer()
+
+	session := connectTestClient(t, srv)
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "schema-tool"})
+	require.NoError(t, err)
+	assert.Contains(t, dryRunText(t, result), "Dry run: tool 'schema-tool' was not executed")
+	assert.Nil(t, result.StructuredContent)
+}
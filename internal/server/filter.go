@@ -0,0 +1,98 @@
+package server
+
+import (
+	"fmt"
+	"path"
+	"slices"
+
+	"go.uber.org/zap"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// ToolFilter restricts the tools a server registers, e.g. from the --only and --skip flags of
+// orla serve. It applies on top of tools disabled with orla tool disable, and across reloads.
+type ToolFilter struct {
+	// Only lists the tools to serve. If empty, all tools are served.
+	Only []string
+	// Skip lists tools not to serve, even if they are listed in Only
+	Skip []string
+}
+
+// Allows reports whether the filter lets the named tool be served
+func (f ToolFilter) Allows(name string) bool {
+	if len(f.Only) > 0 && !slices.Contains(f.Only, name) {
+		return false
+	}
+	return !slices.Contains(f.Skip, name)
+}
+
+// warnUnknownTools logs the tools named by the filter that are not among tools, which are
+// usually typos
+func (f ToolFilter) warnUnknownTools(tools []*core.ToolManifest) {
+	for _, flag := range []struct {
+		name  string
+		names []string
+	}{
+		{"only", f.Only},
+		{"skip", f.Skip},
+	} {
+		for _, name := range flag.names {
+			if !slices.ContainsFunc(tools, func(tool *core.ToolManifest) bool { return tool.Name == name }) {
+				zap.L().Warn("Tool filter names a tool that does not exist",
+					zap.String("filter", flag.name),
+					zap.String("tool", name))
+			}
+		}
+	}
+}
+
+// skipReason returns why the server does not register tool, or "" if it does. Tools are skipped
+// if they are filtered out with orla serve --only/--skip or the config's tool_allowlist and
+// tool_denylist, disabled with orla disable, enabled: false, or orla tool disable, or deprecated
+// while hide_deprecated_tools is set. The caller must hold o.mu.
+func (o *OrlaServer) skipReason(tool *core.ToolManifest) string {
+	if !o.toolFilter.Allows(tool.Name) {
+		return "excluded by the tool filter"
+	}
+	if allowed, reason := configToolListsAllow(o.config, tool.Name); !allowed {
+		return reason
+	}
+	if !o.config.ToolEnabled(tool) {
+		return "disabled by its manifest or the config's tool_overrides"
+	}
+	if o.disabledTools.Contains(tool.Name) {
+		return "disabled with orla tool disable"
+	}
+	if o.config.HideDeprecatedTools && core.StabilityOf(tool) == core.ToolStabilityDeprecated {
+		return "deprecated, and hide_deprecated_tools is set"
+	}
+	return ""
+}
+
+// configToolListsAllow reports whether the config's tool_allowlist and tool_denylist let the named
+// tool be served, and if not, why. A tool is served if it matches a pattern of the allowlist, or
+// the allowlist is empty, and matches none of the denylist, which wins over the allowlist.
+func configToolListsAllow(cfg *config.OrlaConfig, name string) (bool, string) {
+	if pattern, ok := matchToolPattern(cfg.ToolDenylist, name); ok {
+		return false, fmt.Sprintf("matches tool_denylist pattern '%s'", pattern)
+	}
+	if len(cfg.ToolAllowlist) > 0 {
+		if _, ok := matchToolPattern(cfg.ToolAllowlist, name); !ok {
+			return false, "matches no tool_allowlist pattern"
+		}
+	}
+	return true, ""
+}
+
+// matchToolPattern returns the first of the glob patterns that matches the tool name. The config
+// validates the patterns, so malformed ones are treated as not matching.
+func matchToolPattern(patterns []string, name string) (string, bool) {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return pattern, true
+		}
+	}
+	return "", false
+}
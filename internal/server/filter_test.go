@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/registry"
+	"github.com/dorcha-inc/orla/internal/state"
+)
+
+// createFilterTestConfig creates a test config with the tools alpha, beta, and gamma
+func createFilterTestConfig(t *testing.T) *config.OrlaConfig {
+	t.Helper()
+
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping shell script tools on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	for _, name := range []string{"alpha", "beta", "gamma"} {
+		// #nosec G306 -- test file permissions are acceptable for temporary test files
+		require.NoError(t, os.WriteFile(filepath.Join(cfg.ToolsDir, name+".sh"), []byte("#!/bin/sh\necho "+name+"\n"), 0755))
+	}
+	require.NoError(t, os.Remove(filepath.Join(cfg.ToolsDir, "test-tool.sh")))
+
+	toolsRegistry, err := state.NewToolsRegistryFromDirectory(cfg.ToolsDir)
+	require.NoError(t, err)
+	cfg.ToolsRegistry = toolsRegistry
+	return cfg
+}
+
+// connectTestClient connects an in-memory MCP client to srv
+func connectTestClient(t *testing.T, srv *OrlaServer) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.orlaMCPserver.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError(serverSession.Close) })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError(clientSession.Close) })
+	return clientSession
+}
+
+func TestToolFilter_Allows(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  ToolFilter
+		allowed []string
+		denied  []string
+	}{
+		{
+			name:    "empty filter allows everything",
+			filter:  ToolFilter{},
+			allowed: []string{"alpha", "beta"},
+		},
+		{
+			name:    "only allows the named tools",
+			filter:  ToolFilter{Only: []string{"alpha", "gamma"}},
+			allowed: []string{"alpha", "gamma"},
+			denied:  []string{"beta"},
+		},
+		{
+			name:    "skip denies the named tools",
+			filter:  ToolFilter{Skip: []string{"beta"}},
+			allowed: []string{"alpha", "gamma"},
+			denied:  []string{"beta"},
+		},
+		{
+			name:    "skip wins over only",
+			filter:  ToolFilter{Only: []string{"alpha", "beta"}, Skip: []string{"beta"}},
+			allowed: []string{"alpha"},
+			denied:  []string{"beta", "gamma"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range tt.allowed {
+				assert.True(t, tt.filter.Allows(name), name)
+			}
+			for _, name := range tt.denied {
+				assert.False(t, tt.filter.Allows(name), name)
+			}
+		})
+	}
+}
+
+func TestNewOrlaServerWithToolFilter_Only(t *testing.T) {
+	t.Setenv(registry.OrlaHomeEnvVar, t.TempDir())
+
+	cfg := createFilterTestConfig(t)
+	srv := NewOrlaServerWithToolFilter(cfg, "", ToolFilter{Only: []string{"alpha", "gamma"}})
+	session := connectTestClient(t, srv)
+
+	assert.ElementsMatch(t, []string{"alpha", "gamma"}, srv.registeredTools.ToSlice())
+	assert.ElementsMatch(t, []string{"alpha", "gamma"}, listToolNames(t, context.Background(), session))
+}
+
+func TestNewOrlaServerWithToolFilter_Skip(t *testing.T) {
+	t.Setenv(registry.OrlaHomeEnvVar, t.TempDir())
+
+	cfg := createFilterTestConfig(t)
+	srv := NewOrlaServerWithToolFilter(cfg, "", ToolFilter{Skip: []string{"beta"}})
+	session := connectTestClient(t, srv)
+
+	assert.ElementsMatch(t, []string{"alpha", "gamma"}, srv.registeredTools.ToSlice())
+	assert.ElementsMatch(t, []string{"alpha", "gamma"}, listToolNames(t, context.Background(), session))
+
+	// The filter still applies after a rebuild
+	srv.rebuildServer()
+	assert.False(t, srv.registeredTools.Contains("beta"))
+}
+
+func TestNewOrlaServerWithToolFilter_WithDisabledTools(t *testing.T) {
+	t.Setenv(registry.OrlaHomeEnvVar, t.TempDir())
+
+	cfg := createFilterTestConfig(t)
+	srv := NewOrlaServerWithToolFilter(cfg, "", ToolFilter{Only: []string{"alpha", "beta"}})
+
+	// Disabled tools are not served even if the filter allows them
+	require.NoError(t, srv.SetToolDisabled("alpha", true))
+	assert.ElementsMatch(t, []string{"beta"}, srv.registeredTools.ToSlice())
+
+	// Enabling a tool the filter excludes does not serve it
+	require.NoError(t, srv.SetToolDisabled("gamma", true))
+	require.NoError(t, srv.SetToolDisabled("gamma", false))
+	assert.False(t, srv.registeredTools.Contains("gamma"))
+
+	require.NoError(t, srv.SetToolDisabled("alpha", false))
+	assert.ElementsMatch(t, []string{"alpha", "beta"}, srv.registeredTools.ToSlice())
+}
+
+func TestConfigToolListsAllow(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowlist []string
+		denylist  []string
+		allowed   []string
+		denied    map[string]string // tool name to reason
+	}{
+		{
+			name:    "empty lists allow everything",
+			allowed: []string{"alpha", "beta"},
+		},
+		{
+			name:      "allowlist only",
+			allowlist: []string{"alpha", "g*"},
+			allowed:   []string{"alpha", "gamma"},
+			denied:    map[string]string{"beta": "matches no tool_allowlist pattern"},
+		},
+		{
+			name:     "denylist only",
+			denylist: []string{"b?ta"},
+			allowed:  []string{"alpha", "gamma"},
+			denied:   map[string]string{"beta": "matches tool_denylist pattern 'b?ta'"},
+		},
+		{
+			name:      "denylist wins over allowlist",
+			allowlist: []string{"*a"},
+			denylist:  []string{"beta"},
+			allowed:   []string{"alpha", "gamma"},
+			denied: map[string]string{
+				"beta":       "matches tool_denylist pattern 'beta'",
+				"delta-tool": "matches no tool_allowlist pattern",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.OrlaConfig{ToolAllowlist: tt.allowlist, ToolDenylist: tt.denylist}
+			for _, name := range tt.allowed {
+				allowed, reason := configToolListsAllow(cfg, name)
+				assert.True(t, allowed, name)
+				assert.Empty(t, reason)
+			}
+			for name, wantReason := range tt.denied {
+				allowed, reason := configToolListsAllow(cfg, name)
+				assert.False(t, allowed, name)
+				assert.Equal(t, wantReason, reason)
+			}
+		})
+	}
+}
+
+func TestRebuildServer_ToolAllowlistAndDenylist(t *testing.T) {
+	t.Setenv(registry.OrlaHomeEnvVar, t.TempDir())
+
+	cfg := createFilterTestConfig(t)
+	cfg.ToolAllowlist = []string{"alpha", "b*"}
+	cfg.ToolDenylist = []string{"beta"}
+	srv := NewOrlaServer(cfg, "")
+	session := connectTestClient(t, srv)
+
+	assert.ElementsMatch(t, []string{"alpha"}, srv.registeredTools.ToSlice())
+	assert.ElementsMatch(t, []string{"alpha"}, listToolNames(t, context.Background(), session))
+
+	// Enabling a tool the config excludes does not serve it
+	require.NoError(t, srv.SetToolDisabled("gamma", true))
+	require.NoError(t, srv.SetToolDisabled("gamma", false))
+	assert.False(t, srv.registeredTools.Contains("gamma"))
+}
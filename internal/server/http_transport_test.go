@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// initializeRequest is a raw MCP initialize request
+const initializeRequest = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test-client","version":"1.0.0"}}}`
+
+// postMCP sends a raw JSON-RPC message to an MCP endpoint the way a Streamable HTTP client does
+func postMCP(t *testing.T, url, sessionID, body string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", sessionID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError(resp.Body.Close) })
+	return resp
+}
+
+// readBody reads the body of resp
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+// newHTTPTestServer serves srv's HTTP endpoints on a local test server
+func newHTTPTestServer(t *testing.T, srv *OrlaServer) *httptest.Server {
+	t.Helper()
+	httpServer := httptest.NewServer(srv.httpMux())
+	t.Cleanup(httpServer.Close)
+	return httpServer
+}
+
+// TestServeHTTP_StreamableSSE tests that a session initialized over the Streamable HTTP endpoint
+// is answered with SSE streams and can call tools
+func TestServeHTTP_StreamableSSE(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	cfg.HTTPTransport = config.OrlaHTTPTransportStreamable
+	httpServer := newHTTPTestServer(t, NewOrlaServer(cfg, ""))
+	url := httpServer.URL + MCPPath
+
+	// Initialize a session and call the tool with raw requests, reading the SSE responses
+	resp := postMCP(t, url, "", initializeRequest)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.NotEmpty(t, resp.Header.Get(ToolsHashHeader))
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	require.NotEmpty(t, sessionID)
+	body := readBody(t, resp)
+	assert.Contains(t, body, "event: message")
+	assert.Contains(t, body, `"serverInfo":{"name":"orla"`)
+
+	resp = postMCP(t, url, sessionID, `{"jsonrpc":"2.0","method":"notifications/initialized","params":{}}`)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	resp = postMCP(t, url, sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"test-tool","arguments":{}}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Contains(t, readBody(t, resp), `hello world`)
+
+	// The SDK's Streamable HTTP client works against the endpoint as well
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	clientSession, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: url}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError(clientSession.Close) })
+
+	result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "test-tool"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok, "First content should be TextContent")
+	assert.Equal(t, "hello world\n", textContent.Text)
+}
+
+// TestServeHTTP_JSONResponses tests that the plain HTTP endpoint answers with JSON responses
+func TestServeHTTP_JSONResponses(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	cfg.HTTPTransport = config.OrlaHTTPTransportHTTP
+	httpServer := newHTTPTestServer(t, NewOrlaServer(cfg, ""))
+	url := httpServer.URL + MCPJSONPath
+
+	// With only the plain HTTP transport, MCPPath answers with JSON as well
+	for _, path := range []string{MCPJSONPath, MCPPath} {
+		resp := postMCP(t, httpServer.URL+path, "", initializeRequest)
+		require.Equal(t, http.StatusOK, resp.StatusCode, path)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"), path)
+		assert.Contains(t, readBody(t, resp), `"serverInfo":{"name":"orla"`, path)
+	}
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	clientSession, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: url}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError(clientSession.Close) })
+
+	result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "test-tool"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+}
+
+// TestServeHTTP_TransportSelection tests that http_transport selects the MCP endpoints served
+func TestServeHTTP_TransportSelection(t *testing.T) {
+	tests := []struct {
+		transport config.OrlaHTTPTransport
+		mounted   map[string]bool
+	}{
+		{transport: "", mounted: map[string]bool{MCPPath: true, MCPJSONPath: true}},
+		{transport: config.OrlaHTTPTransportBoth, mounted: map[string]bool{MCPPath: true, MCPJSONPath: true}},
+		{transport: config.OrlaHTTPTransportStreamable, mounted: map[string]bool{MCPPath: true, MCPJSONPath: false}},
+		{transport: config.OrlaHTTPTransportHTTP, mounted: map[string]bool{MCPPath: true, MCPJSONPath: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.transport), func(t *testing.T) {
+			cfg := createTestConfig(t)
+			cfg.HTTPTransport = tt.transport
+			httpServer := newHTTPTestServer(t, NewOrlaServer(cfg, ""))
+
+			for path, mounted := range tt.mounted {
+				resp := postMCP(t, httpServer.URL+path, "", initializeRequest)
+				if mounted {
+					assert.Equal(t, http.StatusOK, resp.StatusCode, "%s should be served", path)
+				} else {
+					assert.Equal(t, http.StatusNotFound, resp.StatusCode, "%s should not be served", path)
+				}
+			}
+		})
+	}
+}
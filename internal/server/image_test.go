@@ -0,0 +1,170 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// writeTestPNG writes a small PNG image to a temporary file and returns its path and bytes
+func writeTestPNG(t *testing.T) (string, []byte) {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	path := filepath.Join(t.TempDir(), "chart.png")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+	return path, buf.Bytes()
+}
+
+// TestHandleToolCall_ImageContentType tests that the stdout of a tool with an image content type
+// is returned as image content
+func TestHandleToolCall_ImageContentType(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	srv := NewOrlaServer(createTestConfig(t), "")
+	pngPath, pngData := writeTestPNG(t)
+
+	toolPath := filepath.Join(t.TempDir(), "chart-tool.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(toolPath, []byte("#!/bin/sh\ncat '"+pngPath+"'\n"), 0755))
+	tool := &core.ToolManifest{
+		Name:        "chart-tool",
+		Description: "Chart tool",
+		Path:        toolPath,
+		Interpreter: "/bin/sh",
+		MCP:         &core.MCPConfig{ContentType: "image/png"},
+	}
+
+	result, output, err := srv.handleToolCall(context.Background(), tool, map[string]any{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	require.Len(t, result.Content, 1)
+
+	img, ok := result.Content[0].(*mcp.ImageContent)
+	require.True(t, ok)
+	assert.Equal(t, "image/png", img.MIMEType)
+	assert.Equal(t, pngData, img.Data)
+	// The image is only returned as content, not again as structured output
+	assert.NotContains(t, output, "stdout")
+
+	data, err := json.Marshal(img)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"type":"image"`)
+	assert.Contains(t, string(data), `"data":"`+base64.StdEncoding.EncodeToString(pngData)+`"`)
+
+	// A failing image tool prints an error, which is returned as plain text
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(toolPath, []byte("#!/bin/sh\necho 'no data to plot'\nexit 1\n"), 0755))
+	result, _, err = srv.handleToolCall(context.Background(), tool, map[string]any{})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	text, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Equal(t, "no data to plot\n", text.Text)
+	assert.Nil(t, text.Meta)
+}
+
+// TestParseContentEnvelope_Image tests image items of the content envelope, given as data or as
+// the path of a file the tool wrote
+func TestParseContentEnvelope_Image(t *testing.T) {
+	pngPath, pngData := writeTestPNG(t)
+	envelope, err := json.Marshal(map[string]any{
+		contentEnvelopeKey: []map[string]any{
+			{"text": "CPU usage"},
+			{"type": "image", "path": pngPath, "content_type": "image/png", "annotations": map[string]any{"audience": []string{"user"}}},
+			{"type": "image", "data": base64.StdEncoding.EncodeToString(pngData)},
+		},
+	})
+	require.NoError(t, err)
+
+	content, ok := parseContentEnvelope(string(envelope), nil, "", "", 0)
+	require.True(t, ok)
+	require.Len(t, content, 3)
+
+	assert.Equal(t, "CPU usage", content[0].(*mcp.TextContent).Text)
+
+	fromPath, ok := content[1].(*mcp.ImageContent)
+	require.True(t, ok)
+	assert.Equal(t, "image/png", fromPath.MIMEType)
+	assert.Equal(t, pngData, fromPath.Data)
+	require.NotNil(t, fromPath.Annotations)
+	assert.Equal(t, []mcp.Role{"user"}, fromPath.Annotations.Audience)
+
+	// Without a content type, the media type is detected from the image
+	fromData, ok := content[2].(*mcp.ImageContent)
+	require.True(t, ok)
+	assert.Equal(t, "image/png", fromData.MIMEType)
+	assert.Equal(t, pngData, fromData.Data)
+}
+
+// TestParseContentEnvelope_RelativeImagePath tests that a relative image path is resolved against
+// the directory the tool runs in, not orla's working directory
+func TestParseContentEnvelope_RelativeImagePath(t *testing.T) {
+	pngPath, pngData := writeTestPNG(t)
+	stdout := `{"orla_content": [{"type": "image", "path": "chart.png"}]}`
+
+	content, ok := parseContentEnvelope(stdout, nil, "", filepath.Dir(pngPath), 0)
+	require.True(t, ok)
+	require.Len(t, content, 1)
+	image, ok := content[0].(*mcp.ImageContent)
+	require.True(t, ok)
+	assert.Equal(t, pngData, image.Data)
+
+	// Without a run directory, the path is relative to orla's working directory
+	_, ok = parseContentEnvelope(stdout, nil, "", "", 0)
+	assert.False(t, ok)
+}
+
+// TestParseContentEnvelope_OversizedImageFile tests that an image file larger than the output
+// limit is not read, and that one at the limit is
+func TestParseContentEnvelope_OversizedImageFile(t *testing.T) {
+	pngPath, pngData := writeTestPNG(t)
+	stdout := `{"orla_content": [{"type": "image", "path": "chart.png"}]}`
+	runDir := filepath.Dir(pngPath)
+
+	_, ok := parseContentEnvelope(stdout, nil, "", runDir, int64(len(pngData))-1)
+	assert.False(t, ok)
+
+	content, ok := parseContentEnvelope(stdout, nil, "", runDir, int64(len(pngData)))
+	require.True(t, ok)
+	require.Len(t, content, 1)
+	image, ok := content[0].(*mcp.ImageContent)
+	require.True(t, ok)
+	assert.Equal(t, pngData, image.Data)
+}
+
+// TestParseContentEnvelope_InvalidImage tests that envelopes with unusable image items are not
+// treated as envelopes
+func TestParseContentEnvelope_InvalidImage(t *testing.T) {
+	for _, stdout := range []string{
+		`{"orla_content": [{"type": "image"}]}`,
+		`{"orla_content": [{"type": "image", "path": "/nonexistent/chart.png"}]}`,
+		`{"orla_content": [{"type": "image", "data": "not base64!"}]}`,
+		`{"orla_content": [{"type": "image", "data": "aGVsbG8="}]}`,
+		`{"orla_content": [{"type": "image", "data": "aGVsbG8=", "content_type": "text/plain"}]}`,
+		`{"orla_content": [{"type": "audio", "data": "aGVsbG8="}]}`,
+	} {
+		_, ok := parseContentEnvelope(stdout, nil, "", "", 0)
+		assert.False(t, ok, stdout)
+	}
+}
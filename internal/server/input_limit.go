@@ -0,0 +1,43 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// checkInputSize enforces the tool's max_input_bytes. The size of a call's input is the total
+// length of its flag values plus the size of its stdin, however the stdin is supplied. dir is the
+// directory the tool runs in, against which a relative stdin_file is resolved.
+func checkInputSize(tool *core.ToolManifest, input map[string]any, dir string) error {
+	if tool.MaxInputBytes <= 0 {
+		return nil
+	}
+
+	size := inputSize(tool, input, dir)
+	if size > tool.MaxInputBytes {
+		return fmt.Errorf("input of %d bytes exceeds the limit of %d bytes for tool '%s' (max_input_bytes); "+
+			"flag values and stdin count toward the limit, so send less data in this call", size, tool.MaxInputBytes, tool.Name)
+	}
+	return nil
+}
+
+// inputSize returns the number of bytes a tool call passes to the tool as flag values and stdin.
+// stdin_file counts the size of the file; a file the tool may not read counts as empty and is
+// reported when stdin is opened.
+func inputSize(tool *core.ToolManifest, input map[string]any, dir string) int64 {
+	var size int64
+	for key, value := range input {
+		if path, isString := value.(string); key == stdinFileArgKey && isString {
+			if file, err := openStdinFile(tool, path, dir); err == nil {
+				if info, errStat := file.Stat(); errStat == nil {
+					size += info.Size()
+				}
+				core.LogDeferredError(file.Close)
+			}
+			continue
+		}
+		size += int64(len(fmt.Sprintf("%v", value)))
+	}
+	return size
+}
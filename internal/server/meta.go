@@ -0,0 +1,47 @@
+package server
+
+import (
+	"encoding/json"
+
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// callMeta returns the _meta fields of a tool call request, or nil if it has none
+func callMeta(req *mcp.CallToolRequest) map[string]any {
+	if req == nil || req.Params == nil {
+		return nil
+	}
+	return req.Params.GetMeta()
+}
+
+// metaEnv returns the environment variables that pass the _meta fields listed in the tool's
+// mcp.pass_meta to the tool. Fields that are not listed are never passed, since _meta can carry
+// auth context the tool should not see. String values are passed as is and other values as JSON.
+func metaEnv(tool *core.ToolManifest, meta map[string]any) map[string]string {
+	if tool.MCP == nil || len(tool.MCP.PassMeta) == 0 || len(meta) == 0 {
+		return nil
+	}
+
+	env := make(map[string]string, len(tool.MCP.PassMeta))
+	for _, key := range tool.MCP.PassMeta {
+		value, ok := meta[key]
+		if !ok {
+			continue
+		}
+
+		if str, isString := value.(string); isString {
+			env[core.MetaEnvName(key)] = str
+			continue
+		}
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			zap.L().Debug("Failed to encode _meta field for tool", zap.String("tool", tool.Name), zap.String("field", key), zap.Error(err))
+			continue
+		}
+		env[core.MetaEnvName(key)] = string(encoded)
+	}
+	return env
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/state"
+)
+
+// TestToolCall_PassesAllowedMeta tests that only the _meta fields a tool lists in mcp.pass_meta
+// reach the tool process
+func TestToolCall_PassesAllowedMeta(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	toolPath := filepath.Join(t.TempDir(), "meta.sh")
+	script := "#!/bin/sh\necho \"trace=$ORLA_META_TRACE_ID auth=$ORLA_META_AUTHORIZATION\"\n"
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(toolPath, []byte(script), 0755))
+
+	registry := state.NewToolsRegistry()
+	require.NoError(t, registry.AddTool(&core.ToolManifest{
+		Name:        "meta",
+		Description: "Prints _meta environment variables",
+		Path:        toolPath,
+		Interpreter: "/bin/sh",
+		MCP:         &core.MCPConfig{PassMeta: []string{"trace-id"}},
+	}))
+	srv := NewOrlaServer(&config.OrlaConfig{ToolsRegistry: registry, Port: 8080, Timeout: 30}, "")
+	require.NotNil(t, srv)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.orlaMCPserver.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError(serverSession.Close) })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError(clientSession.Close) })
+
+	result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{
+		Meta:      mcp.Meta{"trace-id": "abc123", "authorization": "Bearer secret"},
+		Name:      "meta",
+		Arguments: map[string]any{},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Equal(t, "trace=abc123 auth=\n", textContent.Text)
+}
+
+func TestMetaEnv(t *testing.T) {
+	tool := &core.ToolManifest{
+		Name: "meta",
+		MCP:  &core.MCPConfig{PassMeta: []string{"trace-id", "tenant", "missing"}},
+	}
+	meta := map[string]any{
+		"trace-id":      "abc123",
+		"tenant":        map[string]any{"id": 7},
+		"authorization": "Bearer secret",
+	}
+
+	assert.Equal(t, map[string]string{
+		"ORLA_META_TRACE_ID": "abc123",
+		"ORLA_META_TENANT":   `{"id":7}`,
+	}, metaEnv(tool, meta))
+
+	assert.Nil(t, metaEnv(&core.ToolManifest{Name: "no-allowlist"}, meta))
+	assert.Nil(t, metaEnv(tool, nil))
+}
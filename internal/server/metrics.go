@@ -0,0 +1,200 @@
+package server
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+
+	"github.com/dorcha-inc/orla/internal/config"
+)
+
+// Tool call results, the values of the result label of the tool call metrics
+const (
+	toolCallResultOK      = "ok"
+	toolCallResultError   = "error"
+	toolCallResultTimeout = "timeout"
+)
+
+// toolCallDurationBuckets are the upper bounds, in seconds, of the tool call duration histogram
+// buckets, the default buckets of the Prometheus client libraries
+var toolCallDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// toolCallKey identifies the tool call metrics of one tool and result
+type toolCallKey struct {
+	tool   string
+	result string
+}
+
+// durationHistogram is a Prometheus histogram of tool call durations
+type durationHistogram struct {
+	buckets []uint64 // counts of observations at most each of toolCallDurationBuckets, not cumulative
+	count   uint64
+	sum     float64
+}
+
+// serverMetrics collects the metrics of the server, served in the Prometheus text format by the
+// metrics endpoint when metrics_enabled is set
+type serverMetrics struct {
+	mu              sync.Mutex
+	toolCalls       map[toolCallKey]*durationHistogram
+	capsuleRestarts map[string]uint64 // keyed by tool name
+}
+
+// newServerMetrics creates an empty set of server metrics
+func newServerMetrics() *serverMetrics {
+	return &serverMetrics{
+		toolCalls:       make(map[toolCallKey]*durationHistogram),
+		capsuleRestarts: make(map[string]uint64),
+	}
+}
+
+// observeToolCall records a completed call of the named tool
+func (m *serverMetrics) observeToolCall(toolName, result string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := toolCallKey{tool: toolName, result: result}
+	histogram, ok := m.toolCalls[key]
+	if !ok {
+		histogram = &durationHistogram{buckets: make([]uint64, len(toolCallDurationBuckets))}
+		m.toolCalls[key] = histogram
+	}
+
+	seconds := duration.Seconds()
+	if i, _ := slices.BinarySearch(toolCallDurationBuckets, seconds); i < len(toolCallDurationBuckets) {
+		histogram.buckets[i]++
+	}
+	histogram.count++
+	histogram.sum += seconds
+}
+
+// observeCapsuleRestart records a restart of the named tool's capsule
+func (m *serverMetrics) observeCapsuleRestart(toolName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.capsuleRestarts[toolName]++
+}
+
+// write writes the metrics to w in the Prometheus text exposition format. activeCapsules is the
+// number of running capsules, which is read from the server rather than tracked.
+func (m *serverMetrics) write(w io.Writer, activeCapsules int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+
+	keys := make([]toolCallKey, 0, len(m.toolCalls))
+	for key := range m.toolCalls {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b toolCallKey) int {
+		return cmp.Or(strings.Compare(a.tool, b.tool), strings.Compare(a.result, b.result))
+	})
+
+	b.WriteString("# HELP orla_tool_calls_total Tool calls handled by the server, by tool and result.\n")
+	b.WriteString("# TYPE orla_tool_calls_total counter\n")
+	for _, key := range keys {
+		fmt.Fprintf(&b, "orla_tool_calls_total{%s} %d\n", toolCallLabels(key), m.toolCalls[key].count)
+	}
+
+	b.WriteString("# HELP orla_tool_call_duration_seconds Duration of tool calls, by tool and result.\n")
+	b.WriteString("# TYPE orla_tool_call_duration_seconds histogram\n")
+	for _, key := range keys {
+		histogram := m.toolCalls[key]
+		labels := toolCallLabels(key)
+		var cumulative uint64
+		for i, bound := range toolCallDurationBuckets {
+			cumulative += histogram.buckets[i]
+			fmt.Fprintf(&b, "orla_tool_call_duration_seconds_bucket{%s,le=\"%g\"} %d\n", labels, bound, cumulative)
+		}
+		fmt.Fprintf(&b, "orla_tool_call_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, histogram.count)
+		fmt.Fprintf(&b, "orla_tool_call_duration_seconds_sum{%s} %g\n", labels, histogram.sum)
+		fmt.Fprintf(&b, "orla_tool_call_duration_seconds_count{%s} %d\n", labels, histogram.count)
+	}
+
+	tools := make([]string, 0, len(m.capsuleRestarts))
+	for tool := range m.capsuleRestarts {
+		tools = append(tools, tool)
+	}
+	slices.Sort(tools)
+
+	b.WriteString("# HELP orla_capsule_restarts_total Restarts of capsules that exited or became unhealthy, by tool.\n")
+	b.WriteString("# TYPE orla_capsule_restarts_total counter\n")
+	for _, tool := range tools {
+		fmt.Fprintf(&b, "orla_capsule_restarts_total{tool=\"%s\"} %d\n", escapeLabelValue(tool), m.capsuleRestarts[tool])
+	}
+
+	b.WriteString("# HELP orla_active_capsules Capsules currently running.\n")
+	b.WriteString("# TYPE orla_active_capsules gauge\n")
+	fmt.Fprintf(&b, "orla_active_capsules %d\n", activeCapsules)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// toolCallLabels formats the labels of the tool call metrics for key
+func toolCallLabels(key toolCallKey) string {
+	return fmt.Sprintf("tool=\"%s\",result=\"%s\"", escapeLabelValue(key.tool), key.result)
+}
+
+// escapeLabelValue escapes a label value for the Prometheus text format
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// toolCallResult classifies a tool call for the metrics: failed calls that ran for the tool's
+// whole timeout are counted as timeouts
+func toolCallResult(result *mcp.CallToolResult, err error, duration, timeout time.Duration) string {
+	if err == nil && result != nil && !result.IsError {
+		return toolCallResultOK
+	}
+	if duration >= timeout {
+		return toolCallResultTimeout
+	}
+	return toolCallResultError
+}
+
+// currentMetricsPath returns the HTTP path of the metrics endpoint under the current config, and
+// whether the endpoint is served
+func (o *OrlaServer) currentMetricsPath() (string, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.metricsPath()
+}
+
+// metricsPath returns the HTTP path of the metrics endpoint, and whether the endpoint is served:
+// only if metrics_enabled is set and the path is not taken by another endpoint. The caller must
+// hold o.mu.
+func (o *OrlaServer) metricsPath() (string, bool) {
+	if !o.config.MetricsEnabled {
+		return "", false
+	}
+
+	path := cmp.Or(o.config.MetricsPath, config.DefaultMetricsPath)
+	if slices.Contains([]string{MCPPath, MCPJSONPath, AdminStatePath, CapabilitiesPath}, path) || strings.HasPrefix(path, AdminToolsPath) {
+		zap.L().Error("Metrics path is taken by another endpoint, not serving metrics", zap.String("metrics_path", path))
+		return "", false
+	}
+	return path, true
+}
+
+// handleMetrics serves the server metrics in the Prometheus text format
+func (o *OrlaServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := o.metrics.write(w, o.capsules.Size()); err != nil {
+		zap.L().Error("Failed to write metrics", zap.Error(err))
+	}
+}
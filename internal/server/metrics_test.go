@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/config"
+)
+
+// scrapeMetrics fetches the metrics endpoint of srv and returns the response
+func scrapeMetrics(t *testing.T, srv *OrlaServer, path string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = "127.0.0.1:50000"
+	srv.httpMux().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	cfg.MetricsEnabled = true
+	srv := NewOrlaServer(cfg, "")
+	t.Cleanup(srv.Close)
+
+	rec := scrapeMetrics(t, srv, config.DefaultMetricsPath)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	assert.NotContains(t, rec.Body.String(), `orla_tool_calls_total{tool="test-tool"`)
+	assert.Contains(t, rec.Body.String(), "orla_active_capsules 0\n")
+
+	result, err := srv.CallTool(context.Background(), "test-tool", map[string]any{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	rec = scrapeMetrics(t, srv, config.DefaultMetricsPath)
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "# TYPE orla_tool_calls_total counter\n")
+	assert.Contains(t, body, `orla_tool_calls_total{tool="test-tool",result="ok"} 1`+"\n")
+	assert.Contains(t, body, "# TYPE orla_tool_call_duration_seconds histogram\n")
+	assert.Contains(t, body, `orla_tool_call_duration_seconds_bucket{tool="test-tool",result="ok",le="+Inf"} 1`+"\n")
+	assert.Contains(t, body, `orla_tool_call_duration_seconds_count{tool="test-tool",result="ok"} 1`+"\n")
+
+	rec = httptest.NewRecorder()
+	srv.handleMetrics(rec, httptest.NewRequest(http.MethodPost, config.DefaultMetricsPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestMetricsEndpoint_Disabled(t *testing.T) {
+	srv := NewOrlaServer(createTestConfig(t), "")
+	t.Cleanup(srv.Close)
+
+	assert.Equal(t, http.StatusNotFound, scrapeMetrics(t, srv, config.DefaultMetricsPath).Code)
+}
+
+func TestMetricsEndpoint_CustomPath(t *testing.T) {
+	cfg := createTestConfig(t)
+	cfg.MetricsEnabled = true
+	cfg.MetricsPath = "/stats"
+	srv := NewOrlaServer(cfg, "")
+	t.Cleanup(srv.Close)
+
+	assert.Equal(t, http.StatusOK, scrapeMetrics(t, srv, "/stats").Code)
+	assert.Equal(t, http.StatusNotFound, scrapeMetrics(t, srv, config.DefaultMetricsPath).Code)
+}
+
+func TestMetricsEndpoint_PathTaken(t *testing.T) {
+	cfg := createTestConfig(t)
+	cfg.MetricsEnabled = true
+	cfg.MetricsPath = CapabilitiesPath
+	cfg.AdminEnabled = true
+	srv := NewOrlaServer(cfg, "")
+	t.Cleanup(srv.Close)
+
+	// The capabilities endpoint is still served, and metrics are not advertised
+	rec := scrapeMetrics(t, srv, CapabilitiesPath)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.False(t, srv.Capabilities().Metrics)
+}
+
+func TestServerMetrics_Write(t *testing.T) {
+	metrics := newServerMetrics()
+	metrics.observeToolCall("b-tool", toolCallResultError, 30*time.Millisecond)
+	metrics.observeToolCall("a-tool", toolCallResultOK, 5*time.Millisecond)
+	metrics.observeToolCall("a-tool", toolCallResultOK, 2*time.Second)
+	metrics.observeToolCall("a-tool", toolCallResultTimeout, time.Minute)
+	metrics.observeCapsuleRestart(`quoted"tool`)
+	metrics.observeCapsuleRestart(`quoted"tool`)
+
+	var b strings.Builder
+	require.NoError(t, metrics.write(&b, 3))
+	body := b.String()
+
+	for _, line := range []string{
+		`orla_tool_calls_total{tool="a-tool",result="ok"} 2`,
+		`orla_tool_calls_total{tool="a-tool",result="timeout"} 1`,
+		`orla_tool_calls_total{tool="b-tool",result="error"} 1`,
+		`orla_tool_call_duration_seconds_bucket{tool="a-tool",result="ok",le="0.005"} 1`,
+		`orla_tool_call_duration_seconds_bucket{tool="a-tool",result="ok",le="1"} 1`,
+		`orla_tool_call_duration_seconds_bucket{tool="a-tool",result="ok",le="2.5"} 2`,
+		`orla_tool_call_duration_seconds_bucket{tool="a-tool",result="ok",le="+Inf"} 2`,
+		`orla_tool_call_duration_seconds_sum{tool="a-tool",result="ok"} 2.005`,
+		`orla_tool_call_duration_seconds_bucket{tool="a-tool",result="timeout",le="10"} 0`,
+		`orla_tool_call_duration_seconds_bucket{tool="a-tool",result="timeout",le="+Inf"} 1`,
+		`orla_tool_call_duration_seconds_bucket{tool="b-tool",result="error",le="0.025"} 0`,
+		`orla_tool_call_duration_seconds_bucket{tool="b-tool",result="error",le="0.05"} 1`,
+		`orla_capsule_restarts_total{tool="quoted\"tool"} 2`,
+		`orla_active_capsules 3`,
+	} {
+		assert.Contains(t, body, line+"\n")
+	}
+
+	// Series are written in tool and result order
+	assert.Less(t, strings.Index(body, `{tool="a-tool",result="ok"} 2`), strings.Index(body, `{tool="a-tool",result="timeout"} 1`))
+	assert.Less(t, strings.Index(body, `{tool="a-tool",result="timeout"} 1`), strings.Index(body, `{tool="b-tool",result="error"} 1`))
+}
+
+func TestToolCallResult(t *testing.T) {
+	ok := &mcp.CallToolResult{}
+	failed := &mcp.CallToolResult{IsError: true}
+
+	assert.Equal(t, toolCallResultOK, toolCallResult(ok, nil, time.Second, 30*time.Second))
+	assert.Equal(t, toolCallResultError, toolCallResult(failed, nil, time.Second, 30*time.Second))
+	assert.Equal(t, toolCallResultError, toolCallResult(ok, errors.New("capsule not found"), time.Second, 30*time.Second))
+	assert.Equal(t, toolCallResultTimeout, toolCallResult(failed, nil, 30*time.Second, 30*time.Second))
+	assert.Equal(t, toolCallResultError, toolCallResult(nil, nil, time.Second, 30*time.Second))
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// outputJSONPathOf returns the mcp.output_json_path of a tool, or "" if it does not set one
+func outputJSONPathOf(tool *core.ToolManifest) string {
+	if tool.MCP == nil {
+		return ""
+	}
+	return tool.MCP.OutputJSONPath
+}
+
+// selectToolOutput returns the part of a tool's output at path. Output given as a string is
+// parsed as JSON first.
+func selectToolOutput(path string, output any) (any, error) {
+	parsedPath, err := core.ParseOutputJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if text, ok := output.(string); ok {
+		if err := json.Unmarshal([]byte(text), &output); err != nil {
+			return nil, fmt.Errorf("tool output is not valid JSON, so output_json_path '%s' cannot be applied: %w", path, err)
+		}
+	}
+
+	return parsedPath.Extract(output)
+}
+
+// selectToolStdout returns the part of a tool's JSON stdout at path, as the stdout the rest of
+// the response is built from: a selected string as it is, and any other value as JSON
+func selectToolStdout(path string, stdout string) (string, error) {
+	selected, err := selectToolOutput(path, stdout)
+	if err != nil {
+		return "", err
+	}
+
+	if text, ok := selected.(string); ok {
+		return text, nil
+	}
+	data, err := json.Marshal(selected)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize the output at output_json_path '%s': %w", path, err)
+	}
+	return string(data), nil
+}
+
+// outputJSONPathErrorResult is the result of a tool call whose output does not contain its
+// output_json_path
+func outputJSONPathErrorResult(err error) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("Failed to select tool output: %v", err),
+			},
+		},
+	}
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// outputPathTestStdout is the output of the tools in the output_json_path tests: a response whose
+// useful part is nested under data.result
+const outputPathTestStdout = `{"status":"ok","request_id":"abc","data":{"result":{"name":"orla","stars":42},"tags":["mcp","go"]}}`
+
+// createOutputPathTool returns a tool that prints stdout and exits with exitCode, selecting its
+// output at outputJSONPath
+func createOutputPathTool(t *testing.T, stdout string, exitCode int, outputJSONPath string, outputSchema map[string]any) *core.ToolManifest {
+	t.Helper()
+	toolPath := filepath.Join(t.TempDir(), "api-tool.sh")
+	toolContent := fmt.Sprintf("#!/bin/sh\necho '%s'\nexit %d\n", stdout, exitCode)
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(toolPath, []byte(toolContent), 0755))
+	return &core.ToolManifest{
+		Name:        "api-tool",
+		Description: "Calls an API",
+		Path:        toolPath,
+		Interpreter: "/bin/sh",
+		MCP: &core.MCPConfig{
+			OutputSchema:   outputSchema,
+			OutputJSONPath: outputJSONPath,
+		},
+	}
+}
+
+func TestHandleToolCall_OutputJSONPath_WithOutputSchema(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	srv := NewOrlaServer(createTestConfig(t), "")
+	outputSchema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":  map[string]any{"type": "string"},
+			"stars": map[string]any{"type": "integer"},
+		},
+		"required": []any{"name", "stars"},
+	}
+
+	for _, path := range []string{"$.data.result", "/data/result", "$['data']['result']"} {
+		t.Run(path, func(t *testing.T) {
+			tool := createOutputPathTool(t, outputPathTestStdout, 0, path, outputSchema)
+
+			result, output, err := srv.handleToolCall(context.Background(), tool, map[string]any{})
+			require.NoError(t, err)
+			require.False(t, result.IsError)
+			assert.Equal(t, map[string]any{"name": "orla", "stars": float64(42)}, output,
+				"only the selected part should be the structured output")
+		})
+	}
+}
+
+func TestHandleToolCall_OutputJSONPath_WithoutOutputSchema(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	srv := NewOrlaServer(createTestConfig(t), "")
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "object", path: "$.data.result", expected: `{"name":"orla","stars":42}`},
+		{name: "array element", path: "$.data.tags[1]", expected: "go"},
+		{name: "pointer into array", path: "/data/tags", expected: `["mcp","go"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := createOutputPathTool(t, outputPathTestStdout, 0, tt.path, nil)
+
+			result, output, err := srv.handleToolCall(context.Background(), tool, map[string]any{})
+			require.NoError(t, err)
+			require.False(t, result.IsError)
+			assert.Equal(t, tt.expected, output["stdout"])
+
+			textContent, ok := result.Content[0].(*mcp.TextContent)
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, textContent.Text)
+		})
+	}
+}
+
+func TestHandleToolCall_OutputJSONPath_Missing(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	srv := NewOrlaServer(createTestConfig(t), "")
+
+	tests := []struct {
+		name     string
+		stdout   string
+		path     string
+		contains string
+	}{
+		{
+			name:     "missing member",
+			stdout:   outputPathTestStdout,
+			path:     "$.data.results",
+			contains: "output_json_path '$.data.results' not found in tool output: no member 'results' in an object",
+		},
+		{
+			name:     "index out of range",
+			stdout:   outputPathTestStdout,
+			path:     "$.data.tags[5]",
+			contains: "no index 5 in an array of 2 items",
+		},
+		{
+			name:     "not JSON",
+			stdout:   "plain text",
+			path:     "$.data",
+			contains: "tool output is not valid JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := createOutputPathTool(t, tt.stdout, 0, tt.path, nil)
+
+			result, output, err := srv.handleToolCall(context.Background(), tool, map[string]any{})
+			require.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Nil(t, output)
+
+			require.Len(t, result.Content, 1)
+			textContent, ok := result.Content[0].(*mcp.TextContent)
+			require.True(t, ok)
+			assert.Contains(t, textContent.Text, tt.contains)
+		})
+	}
+}
+
+func TestHandleToolCall_OutputJSONPath_FailedCall(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	srv := NewOrlaServer(createTestConfig(t), "")
+
+	// The output of a failed call is returned as it is, since it is usually an error message
+	tool := createOutputPathTool(t, "connection refused", 3, "$.data.result", nil)
+
+	result, output, err := srv.handleToolCall(context.Background(), tool, map[string]any{})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Equal(t, "connection refused\n", output["stdout"])
+	assert.Equal(t, 3, output["exit_code"])
+}
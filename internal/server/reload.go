@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// defaultRebuildWait is how long a tool call that arrives while the server rebuilds its tools
+// waits for the rebuild to finish before it is answered with a retryable error
+const defaultRebuildWait = 5 * time.Second
+
+// RetryableMetaKey is the _meta key of a tool result for a call that was not run and can be
+// retried as is, e.g. because the server was reloading its tools
+const RetryableMetaKey = "retryable"
+
+// ServerReloadingError is returned for a tool call that arrived while the server was rebuilding
+// its tools and did not finish within the wait. The call was not run.
+type ServerReloadingError struct {
+	Tool   string
+	Waited time.Duration
+}
+
+func (e *ServerReloadingError) Error() string {
+	return fmt.Sprintf("server is reloading its tools, call to '%s' was not run after waiting %s; retry it shortly", e.Tool, e.Waited)
+}
+
+// Interface guard for ServerReloadingError
+var _ error = &ServerReloadingError{}
+
+// rebuildGate lets tool calls wait for an in-progress rebuild of the server's tools, during which
+// capsules and persistent processes are stopped and started again
+type rebuildGate struct {
+	mu   sync.Mutex
+	done chan struct{} // closed when the current rebuild finishes, nil if none is in progress
+}
+
+// begin marks the start of a rebuild. Rebuilds hold the server's write lock, so they never overlap.
+func (g *rebuildGate) begin() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.done = make(chan struct{})
+}
+
+// end marks the end of the rebuild started with begin, releasing the calls waiting for it
+func (g *rebuildGate) end() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.done != nil {
+		close(g.done)
+		g.done = nil
+	}
+}
+
+// wait blocks until no rebuild is in progress, for at most timeout. It reports whether the
+// rebuild finished in time; ctx ending also stops the wait.
+func (g *rebuildGate) wait(ctx context.Context, timeout time.Duration) bool {
+	g.mu.Lock()
+	done := g.done
+	g.mu.Unlock()
+	if done == nil {
+		return true
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// waitForRebuild waits briefly for an in-progress rebuild of the server's tools to finish. It
+// returns nil once the call can go ahead, or, if the rebuild is still running, a result marked
+// retryable to answer the call with. The error is returned in the result rather than as a Go
+// error, since the MCP SDK replaces the result of a handler that fails, dropping its _meta.
+func (o *OrlaServer) waitForRebuild(ctx context.Context, toolName string) *mcp.CallToolResult {
+	if o.rebuild.wait(ctx, o.rebuildWait) {
+		return nil
+	}
+
+	err := &ServerReloadingError{Tool: toolName, Waited: o.rebuildWait}
+	core.LogToolExecution(toolName, o.rebuildWait.Seconds(), err)
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("Server is reloading its tools: %v", err),
+			},
+		},
+		Meta: mcp.Meta{RetryableMetaKey: true},
+	}
+}
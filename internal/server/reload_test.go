@@ -0,0 +1,240 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/state"
+)
+
+// TestRebuildGate tests that wait returns at once with no rebuild in progress and otherwise
+// until the rebuild ends or the timeout or context runs out
+func TestRebuildGate(t *testing.T) {
+	var gate rebuildGate
+	assert.True(t, gate.wait(context.Background(), time.Millisecond))
+
+	gate.begin()
+	assert.False(t, gate.wait(context.Background(), 10*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, gate.wait(ctx, time.Minute))
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		gate.end()
+	}()
+	assert.True(t, gate.wait(context.Background(), time.Minute))
+	assert.True(t, gate.wait(context.Background(), time.Millisecond))
+}
+
+// TestCallTool_DuringRebuild tests that a call issued during a rebuild waits for it and then runs
+func TestCallTool_DuringRebuild(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	srv := NewOrlaServer(createTestConfig(t), "")
+	t.Cleanup(srv.Close)
+
+	srv.rebuild.begin()
+	type callResult struct {
+		result *mcp.CallToolResult
+		err    error
+	}
+	done := make(chan callResult, 1)
+	go func() {
+		result, err := srv.CallTool(context.Background(), "test-tool", map[string]any{})
+		done <- callResult{result, err}
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("call returned before the rebuild finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	srv.rebuild.end()
+	select {
+	case res := <-done:
+		require.NoError(t, res.err)
+		assert.False(t, res.result.IsError)
+		assert.Contains(t, res.result.Content[0].(*mcp.TextContent).Text, "hello world")
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not return after the rebuild finished")
+	}
+}
+
+// TestCallTool_RebuildTimeout tests that a call that outlasts its wait for a rebuild gets a
+// retryable error instead of running
+func TestCallTool_RebuildTimeout(t *testing.T) {
+	srv := NewOrlaServer(createTestConfig(t), "")
+	t.Cleanup(srv.Close)
+	srv.rebuildWait = 20 * time.Millisecond
+
+	srv.rebuild.begin()
+	defer srv.rebuild.end()
+
+	result, err := srv.CallTool(context.Background(), "test-tool", map[string]any{})
+	var reloadingErr *ServerReloadingError
+	require.True(t, errors.As(err, &reloadingErr))
+	assert.Equal(t, "test-tool", reloadingErr.Tool)
+	assert.Equal(t, 20*time.Millisecond, reloadingErr.Waited)
+
+	require.NotNil(t, result)
+	assert.True(t, result.IsError)
+	assert.Equal(t, true, result.Meta[RetryableMetaKey])
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "Server is reloading its tools")
+
+	// The MCP path answers with the same result and no Go error, so the SDK keeps its _meta
+	tool, err := srv.config.ToolsRegistry.GetTool("test-tool")
+	require.NoError(t, err)
+	result, _, err = srv.handleToolCall(context.Background(), tool, map[string]any{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, true, result.Meta[RetryableMetaKey])
+}
+
+// slowCapsuleScript is a capsule that answers each call after a second
+const slowCapsuleScript = `#!/bin/sh
+echo '{"jsonrpc":"2.0","method":"orla.hello","params":{"name":"slow-tool","version":"1.0.0","capabilities":["tools"]}}'
+
+while IFS= read -r line; do
+  REQ_ID=$(echo "$line" | sed -n 's/.*"id":\([0-9]*\).*/\1/p')
+  if [ -n "$REQ_ID" ]; then
+    sleep 1
+    echo "{\"jsonrpc\":\"2.0\",\"id\":$REQ_ID,\"result\":\"done\"}"
+  fi
+done
+`
+
+// addSlowCapsuleTool adds a capsule-mode tool running slowCapsuleScript to cfg and returns it
+func addSlowCapsuleTool(t *testing.T, cfg *config.OrlaConfig) *core.ToolManifest {
+	t.Helper()
+
+	scriptPath := filepath.Join(t.TempDir(), "slow-capsule.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(scriptPath, []byte(slowCapsuleScript), 0755))
+
+	tool := &core.ToolManifest{
+		Name:        "slow-tool",
+		Version:     "1.0.0",
+		Description: "A slow capsule mode tool",
+		Path:        scriptPath,
+		Runtime: &core.RuntimeConfig{
+			Mode:             core.RuntimeModeCapsule,
+			StartupTimeoutMs: 5000,
+		},
+	}
+	require.NoError(t, cfg.ToolsRegistry.AddTool(tool))
+	return tool
+}
+
+// reloadWithTool rebuilds the server's tools the way Reload does, with a new config whose tools
+// registry has tool in place of the tool of the same name
+func reloadWithTool(t *testing.T, srv *OrlaServer, tool *core.ToolManifest) {
+	t.Helper()
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	tools := &state.ToolsRegistry{}
+	for _, existing := range srv.config.ToolsRegistry.ListTools() {
+		if existing.Name != tool.Name {
+			require.NoError(t, tools.AddTool(existing))
+		}
+	}
+	require.NoError(t, tools.AddTool(tool))
+
+	cfg := *srv.config
+	cfg.ToolsRegistry = tools
+	srv.config = &cfg
+	srv.rebuildServerLocked()
+}
+
+// TestRebuildServer_ReusesUnchangedCapsule tests that rebuilding keeps the capsule of a tool
+// whose path, version, and runtime config did not change
+func TestRebuildServer_ReusesUnchangedCapsule(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	cfg := createTestConfig(t)
+	tool := addSlowCapsuleTool(t, cfg)
+	srv := NewOrlaServer(cfg, "")
+	t.Cleanup(srv.Close)
+
+	capsule1, ok := srv.capsules.Load(tool.Name)
+	require.True(t, ok)
+
+	// A reload builds new manifests, which only need to match
+	unchangedTool := *tool
+	unchangedTool.Description = "A slow capsule mode tool, described differently"
+	reloadWithTool(t, srv, &unchangedTool)
+
+	capsule2, ok := srv.capsules.Load(tool.Name)
+	require.True(t, ok)
+	assert.Same(t, capsule1, capsule2)
+	assert.True(t, capsule2.IsReady())
+	assert.False(t, capsule2.IsDraining())
+}
+
+// TestRebuildServer_DrainsInFlightCapsuleCall tests that a capsule call in flight while the server
+// reloads and replaces the capsule still returns successfully
+func TestRebuildServer_DrainsInFlightCapsuleCall(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	cfg := createTestConfig(t)
+	cfg.CapsuleDrainTimeout = 10
+	tool := addSlowCapsuleTool(t, cfg)
+	srv := NewOrlaServer(cfg, "")
+	t.Cleanup(srv.Close)
+
+	oldCapsule, ok := srv.capsules.Load(tool.Name)
+	require.True(t, ok)
+
+	type callResult struct {
+		result *mcp.CallToolResult
+		err    error
+	}
+	done := make(chan callResult, 1)
+	go func() {
+		result, err := srv.CallTool(context.Background(), tool.Name, map[string]any{})
+		done <- callResult{result, err}
+	}()
+
+	// Reload with a new version of the tool while the call is in flight
+	time.Sleep(200 * time.Millisecond)
+	updatedTool := *tool
+	updatedTool.Version = "1.1.0"
+	reloadWithTool(t, srv, &updatedTool)
+
+	newCapsule, ok := srv.capsules.Load(tool.Name)
+	require.True(t, ok)
+	assert.NotSame(t, oldCapsule, newCapsule)
+	assert.True(t, oldCapsule.IsDraining())
+
+	select {
+	case res := <-done:
+		require.NoError(t, res.err)
+		assert.False(t, res.result.IsError)
+		assert.Contains(t, res.result.Content[0].(*mcp.TextContent).Text, "done")
+	case <-time.After(10 * time.Second):
+		t.Fatal("in-flight call did not return")
+	}
+
+	assert.Eventually(t, func() bool { return oldCapsule.GetState() == core.CapsuleStateStopped }, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// toolRunFunc runs one attempt of a simple mode tool call
+type toolRunFunc func(ctx context.Context, attempt int) (*core.OrlaToolExecutionResult, error)
+
+// isTransientFailure reports whether a tool run failed in a way its retry settings allow
+// retrying: a non-zero exit code that is listed in retry_on_exit_codes, or any non-zero exit code
+// if none are listed. Failures to run the tool at all, such as timeouts, are not retried.
+func isTransientFailure(retry *core.RetryConfig, result *core.OrlaToolExecutionResult, err error) bool {
+	if err != nil || result == nil || result.ExitCode == 0 {
+		return false
+	}
+	if len(retry.RetryOnExitCodes) == 0 {
+		return true
+	}
+	return slices.Contains(retry.RetryOnExitCodes, result.ExitCode)
+}
+
+// executeWithRetry runs a simple mode tool call, retrying transient failures as configured by the
+// tool's retry settings. All attempts and the delays between them share the tool's timeout, so
+// a retry that cannot start before the timeout expires is skipped and the last failure is
+// returned.
+func (o *OrlaServer) executeWithRetry(ctx context.Context, executor *core.OrlaToolExecutor, tool *core.ToolManifest, run toolRunFunc) (*core.OrlaToolExecutionResult, error) {
+	retry := tool.Retry
+	if retry == nil || retry.Attempts <= 1 {
+		return run(ctx, 1)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, executor.TimeoutFor(tool))
+	defer cancel()
+
+	delay := time.Duration(max(retry.BackoffMs, 0)) * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		result, err := run(ctx, attempt)
+		if attempt >= retry.Attempts || !isTransientFailure(retry, result, err) {
+			return result, err
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+			zap.L().Warn("Not retrying tool, the timeout would expire first",
+				zap.String("tool", tool.Name),
+				zap.Int("attempt", attempt),
+				zap.Int("exit_code", result.ExitCode))
+			return result, err
+		}
+
+		zap.L().Info("Retrying tool after transient failure",
+			zap.String("tool", tool.Name),
+			zap.Int("attempt", attempt),
+			zap.Int("exit_code", result.ExitCode),
+			zap.Duration("backoff", delay))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
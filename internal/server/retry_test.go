@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// flakyToolScript fails with exit code $FAIL_CODE until it has run $SUCCEED_ON times, counting
+// its runs in $COUNT_FILE, then echoes its stdin
+const flakyToolScript = `#!/bin/sh
+n=$(cat "$COUNT_FILE" 2>/dev/null || echo 0)
+n=$((n + 1))
+echo "$n" > "$COUNT_FILE"
+if [ "$n" -lt "$SUCCEED_ON" ]; then
+  echo "attempt $n failed" >&2
+  exit "$FAIL_CODE"
+fi
+echo "attempt $n: $(cat)"
+`
+
+// newFlakyTool writes a tool that fails with failCode until its succeedOn-th run, and returns it
+// with the path of the file counting its runs
+func newFlakyTool(t *testing.T, failCode string, succeedOn string, retry *core.RetryConfig) (*core.ToolManifest, string) {
+	t.Helper()
+	dir := t.TempDir()
+	toolPath := filepath.Join(dir, "flaky.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(toolPath, []byte(flakyToolScript), 0755))
+	countFile := filepath.Join(dir, "count")
+
+	return &core.ToolManifest{
+		Name:        "flaky",
+		Description: "Flaky tool",
+		Path:        toolPath,
+		Interpreter: "/bin/sh",
+		Runtime: &core.RuntimeConfig{
+			Env: map[string]string{
+				"COUNT_FILE": countFile,
+				"FAIL_CODE":  failCode,
+				"SUCCEED_ON": succeedOn,
+			},
+		},
+		Retry: retry,
+	}, countFile
+}
+
+// readRunCount returns how many times the flaky tool ran
+func readRunCount(t *testing.T, countFile string) string {
+	t.Helper()
+	// #nosec G304 -- test file path is controlled
+	data, err := os.ReadFile(countFile)
+	require.NoError(t, err)
+	return strings.TrimSpace(string(data))
+}
+
+func TestHandleToolCall_RetrySucceedsWithinBudget(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	srv := NewOrlaServer(createTestConfig(t), "")
+	tool, countFile := newFlakyTool(t, "75", "3", &core.RetryConfig{Attempts: 3, BackoffMs: 10, RetryOnExitCodes: []int{75}})
+
+	result, _, err := srv.handleToolCall(context.Background(), tool, map[string]any{"stdin": "payload"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, "3", readRunCount(t, countFile))
+
+	// Every attempt receives the full stdin
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Equal(t, "attempt 3: payload\n", textContent.Text)
+}
+
+func TestHandleToolCall_RetryExhausted(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	srv := NewOrlaServer(createTestConfig(t), "")
+	tool, countFile := newFlakyTool(t, "75", "10", &core.RetryConfig{Attempts: 3, BackoffMs: 10})
+
+	result, output, err := srv.handleToolCall(context.Background(), tool, map[string]any{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "3", readRunCount(t, countFile))
+	assert.Equal(t, 75, output["exit_code"])
+	assert.Equal(t, "attempt 3 failed\n", output["stderr"])
+}
+
+func TestHandleToolCall_RetryOnlyListedExitCodes(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	srv := NewOrlaServer(createTestConfig(t), "")
+	tool, countFile := newFlakyTool(t, "1", "2", &core.RetryConfig{Attempts: 3, RetryOnExitCodes: []int{75}})
+
+	result, _, err := srv.handleToolCall(context.Background(), tool, map[string]any{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "1", readRunCount(t, countFile), "exit code 1 is not transient for this tool")
+}
+
+func TestHandleToolCall_RetryHonorsTimeout(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	cfg.Timeout = 1
+	srv := NewOrlaServer(cfg, "")
+	// The backoff is longer than the whole timeout, so the retry cannot run
+	tool, countFile := newFlakyTool(t, "75", "2", &core.RetryConfig{Attempts: 3, BackoffMs: 5000})
+
+	result, _, err := srv.handleToolCall(context.Background(), tool, map[string]any{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "1", readRunCount(t, countFile))
+}
+
+func TestIsTransientFailure(t *testing.T) {
+	anyCode := &core.RetryConfig{Attempts: 2}
+	listed := &core.RetryConfig{Attempts: 2, RetryOnExitCodes: []int{75, 111}}
+
+	assert.False(t, isTransientFailure(anyCode, &core.OrlaToolExecutionResult{ExitCode: 0}, nil))
+	assert.True(t, isTransientFailure(anyCode, &core.OrlaToolExecutionResult{ExitCode: 1}, nil))
+	assert.True(t, isTransientFailure(listed, &core.OrlaToolExecutionResult{ExitCode: 111}, nil))
+	assert.False(t, isTransientFailure(listed, &core.OrlaToolExecutionResult{ExitCode: 1}, nil))
+	assert.False(t, isTransientFailure(anyCode, nil, errors.New("failed to start command")))
+	assert.False(t, isTransientFailure(anyCode, &core.OrlaToolExecutionResult{ExitCode: -1}, errors.New("tool execution timed out after 1s")))
+}
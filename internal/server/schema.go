@@ -0,0 +1,156 @@
+package server
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// defaultSchemaCacheSize is the number of compiled schemas kept by the process-wide schema cache
+const defaultSchemaCacheSize = 256
+
+// compiledSchemas caches compiled tool input schemas across calls and reloads. Entries are keyed by
+// the schema's contents, so a tool whose schema changes gets a new entry and the old one ages out.
+var compiledSchemas = newSchemaCache(defaultSchemaCacheSize)
+
+// schemaCache is an LRU cache of compiled JSON Schemas keyed by a hash of the schema
+type schemaCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // front is most recently used
+}
+
+// schemaCacheEntry is a single compiled schema in the cache
+type schemaCacheEntry struct {
+	key      string
+	resolved *jsonschema.Resolved
+}
+
+// newSchemaCache creates a schema cache holding at most capacity compiled schemas
+func newSchemaCache(capacity int) *schemaCache {
+	return &schemaCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// schemaKey returns the cache key for a schema. encoding/json sorts map keys, so equal schemas
+// always hash to the same key.
+func schemaKey(schema map[string]any) (string, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// get returns the compiled form of schema, compiling and caching it on a miss
+func (c *schemaCache) get(schema map[string]any) (*jsonschema.Resolved, error) {
+	key, err := schemaKey(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		return element.Value.(*schemaCacheEntry).resolved, nil
+	}
+
+	resolved, err := core.CompileSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	c.entries[key] = c.order.PushFront(&schemaCacheEntry{key: key, resolved: resolved})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*schemaCacheEntry).key)
+	}
+
+	return resolved, nil
+}
+
+// len returns the number of cached schemas
+func (c *schemaCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// validateToolInput fills in the defaults of the tool's input schema for omitted arguments and
+// validates the result against the schema, if the tool has one, as the MCP SDK does before it
+// calls the handler of a session's tool call. It returns the input to run the tool with; the
+// caller's map is not modified.
+func validateToolInput(tool *core.ToolManifest, input map[string]any) (map[string]any, error) {
+	if tool.MCP == nil || tool.MCP.InputSchema == nil {
+		return input, nil
+	}
+	resolved, err := compiledSchemas.get(tool.MCP.InputSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	withDefaults := maps.Clone(input)
+	if withDefaults == nil {
+		withDefaults = map[string]any{}
+	}
+	if err := resolved.ApplyDefaults(&withDefaults); err != nil {
+		return nil, err
+	}
+	if err := resolved.Validate(withDefaults); err != nil {
+		return nil, err
+	}
+	return withDefaults, nil
+}
+
+// missingRequiredProperties returns the properties listed in the top-level "required" keyword of
+// schema that are absent from instance, in schema order
+func missingRequiredProperties(schema map[string]any, instance map[string]any) []string {
+	var required []string
+	switch names := schema["required"].(type) {
+	case []string:
+		required = names
+	case []any:
+		for _, name := range names {
+			if nameStr, ok := name.(string); ok {
+				required = append(required, nameStr)
+			}
+		}
+	}
+
+	var missing []string
+	for _, name := range required {
+		if _, ok := instance[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// invalidArgumentsResult builds the error result for a tool call whose arguments fail validation
+func invalidArgumentsResult(err error) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("Invalid arguments: %v", err),
+			},
+		},
+	}
+}
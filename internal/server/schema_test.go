@@ -0,0 +1,237 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+func testObjectSchema(required string) map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			required: map[string]any{"type": "string"},
+		},
+		"required": []any{required},
+	}
+}
+
+// cachedSchema returns the compiled form of schema held by cache, or nil if it is not cached
+func cachedSchema(t *testing.T, cache *schemaCache, schema map[string]any) *jsonschema.Resolved {
+	t.Helper()
+	key, err := schemaKey(schema)
+	require.NoError(t, err)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if element, ok := cache.entries[key]; ok {
+		return element.Value.(*schemaCacheEntry).resolved
+	}
+	return nil
+}
+
+func TestSchemaCache_ReusesCompiledSchema(t *testing.T) {
+	cache := newSchemaCache(4)
+	schema := testObjectSchema("query")
+
+	first, err := cache.get(schema)
+	require.NoError(t, err)
+
+	// An equal but distinct map hashes to the same entry
+	second, err := cache.get(testObjectSchema("query"))
+	require.NoError(t, err)
+
+	assert.Same(t, first, second)
+	assert.Equal(t, 1, cache.len())
+
+	require.NoError(t, first.Validate(map[string]any{"query": "logs"}))
+	require.Error(t, first.Validate(map[string]any{}))
+}
+
+func TestSchemaCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newSchemaCache(2)
+	a, b, c := testObjectSchema("a"), testObjectSchema("b"), testObjectSchema("c")
+
+	_, err := cache.get(a)
+	require.NoError(t, err)
+	_, err = cache.get(b)
+	require.NoError(t, err)
+	// Touch a so that b is the least recently used
+	_, err = cache.get(a)
+	require.NoError(t, err)
+	_, err = cache.get(c)
+	require.NoError(t, err)
+	assert.Equal(t, 2, cache.len())
+
+	// a is still cached, b was evicted
+	assert.NotNil(t, cachedSchema(t, cache, a))
+	assert.Nil(t, cachedSchema(t, cache, b))
+	assert.NotNil(t, cachedSchema(t, cache, c))
+}
+
+func TestSchemaCache_InvalidSchema(t *testing.T) {
+	cache := newSchemaCache(4)
+
+	_, err := cache.get(map[string]any{"type": 42})
+	require.Error(t, err)
+	assert.Equal(t, 0, cache.len())
+}
+
+func TestMissingRequiredProperties(t *testing.T) {
+	schema := map[string]any{
+		"type":     "object",
+		"required": []any{"name", "count", "items"},
+	}
+
+	assert.Equal(t, []string{"name", "items"}, missingRequiredProperties(schema, map[string]any{"count": 1}))
+	assert.Empty(t, missingRequiredProperties(schema, map[string]any{"name": "a", "count": 1, "items": nil}))
+
+	// Schemas built in Go may use []string
+	assert.Equal(t, []string{"ok"}, missingRequiredProperties(map[string]any{"required": []string{"ok"}}, map[string]any{}))
+
+	// No required keyword
+	assert.Empty(t, missingRequiredProperties(map[string]any{"type": "object"}, map[string]any{}))
+}
+
+// weatherInputTool returns a tool whose input schema requires a city and types its properties
+func weatherInputTool(additionalProperties any) *core.ToolManifest {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"city":    map[string]any{"type": "string"},
+			"days":    map[string]any{"type": "integer"},
+			"scale":   map[string]any{"type": "number"},
+			"verbose": map[string]any{"type": "boolean"},
+			"units":   map[string]any{"type": []any{"string", "null"}},
+			"tags":    map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+		"required": []any{"city", "days"},
+	}
+	if additionalProperties != nil {
+		schema["additionalProperties"] = additionalProperties
+	}
+	return &core.ToolManifest{Name: "weather", MCP: &core.MCPConfig{InputSchema: schema}}
+}
+
+func TestValidateToolInput(t *testing.T) {
+	_, err := validateToolInput(weatherInputTool(nil), map[string]any{"city": "Dublin", "days": float64(3), "debug": true})
+	require.NoError(t, err)
+	_, err = validateToolInput(&core.ToolManifest{Name: "plain"}, map[string]any{"anything": 1})
+	require.NoError(t, err)
+
+	_, err = validateToolInput(weatherInputTool(nil), map[string]any{"days": "three"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "days")
+
+	_, err = validateToolInput(weatherInputTool(false), map[string]any{"city": "Dublin", "days": float64(3), "debug": true})
+	require.Error(t, err)
+}
+
+// TestCallTool_InvalidArgumentsNotExecuted tests that a tool is not run with arguments that do not
+// match its input schema, whether it is called through an MCP session or with CallTool
+func TestCallTool_InvalidArgumentsNotExecuted(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+	require.NotNil(t, srv)
+
+	// The tool leaves a marker file behind if it is run
+	markerPath := filepath.Join(t.TempDir(), "ran")
+	toolPath := filepath.Join(t.TempDir(), "weather.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(toolPath, []byte("#!/bin/sh\ntouch "+markerPath+"\necho sunny\n"), 0755))
+
+	tool := weatherInputTool(nil)
+	tool.Description = "Weather tool"
+	tool.Path = toolPath
+	tool.Interpreter = "/bin/sh"
+	require.NoError(t, cfg.ToolsRegistry.AddTool(tool))
+	srv.rebuildServer()
+
+	t.Run("MCP session", func(t *testing.T) {
+		clientSession := connectTestClient(t, srv)
+		_, err := clientSession.CallTool(context.Background(), &mcp.CallToolParams{
+			Name:      "weather",
+			Arguments: map[string]any{"days": "three"},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "days")
+		assert.NoFileExists(t, markerPath)
+	})
+
+	t.Run("CallTool", func(t *testing.T) {
+		result, err := srv.CallTool(context.Background(), "weather", map[string]any{"days": "three"})
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+		textContent, ok := result.Content[0].(*mcp.TextContent)
+		require.True(t, ok)
+		assert.Contains(t, textContent.Text, "Invalid arguments")
+		assert.Contains(t, textContent.Text, "days")
+		assert.NoFileExists(t, markerPath)
+	})
+
+	result, err := srv.CallTool(context.Background(), "weather", map[string]any{"city": "Dublin", "days": float64(3)})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.FileExists(t, markerPath)
+}
+
+// TestValidateToolInput_ReusesCompiledSchema tests that repeated validations against the same
+// input schema reuse its compiled form
+func TestValidateToolInput_ReusesCompiledSchema(t *testing.T) {
+	tool := &core.ToolManifest{Name: "cached", MCP: &core.MCPConfig{InputSchema: testObjectSchema("cached-query")}}
+
+	_, err := validateToolInput(tool, map[string]any{"cached-query": "logs"})
+	require.NoError(t, err)
+	compiled := cachedSchema(t, compiledSchemas, tool.MCP.InputSchema)
+	require.NotNil(t, compiled)
+
+	for range 3 {
+		_, err = validateToolInput(tool, map[string]any{"cached-query": "logs"})
+		require.NoError(t, err)
+	}
+	_, err = validateToolInput(tool, map[string]any{})
+	require.Error(t, err)
+	assert.Same(t, compiled, cachedSchema(t, compiledSchemas, tool.MCP.InputSchema))
+}
+
+func BenchmarkValidateAgainstSchema(b *testing.B) {
+	schema := testObjectSchema("query")
+	instance := map[string]any{"query": "logs"}
+
+	b.Run("cached", func(b *testing.B) {
+		cache := newSchemaCache(defaultSchemaCacheSize)
+		for b.Loop() {
+			resolved, err := cache.get(schema)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := resolved.Validate(instance); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("uncached", func(b *testing.B) {
+		for b.Loop() {
+			resolved, err := core.CompileSchema(schema)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := resolved.Validate(instance); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -4,8 +4,12 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -16,48 +20,100 @@ import (
 
 	"github.com/dorcha-inc/orla/internal/config"
 	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/registry"
+	"github.com/dorcha-inc/orla/internal/state"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // OrlaServer stores the state and dependencies for the Orla MCP server.
+// mu guards the fields that reload replaces (config, executor, orlaMCPserver, and the state
+// rebuilt with them): rebuilds take the write lock, and tool calls and handlers read them under
+// the read lock.
 type OrlaServer struct {
-	config          *config.OrlaConfig
-	configPath      string
-	executor        *core.OrlaToolExecutor
-	orlaMCPserver   *mcp.Server
-	mu              sync.RWMutex
-	httpHandler     *mcp.StreamableHTTPHandler
-	capsules        *xsync.MapOf[string, *core.CapsuleManager] // the key here is the tool name
-	registeredTools mapset.Set[string]                         // the key here is the tool name
+	config            *config.OrlaConfig
+	configPath        string
+	executor          *core.OrlaToolExecutor
+	orlaMCPserver     *mcp.Server
+	mu                sync.RWMutex
+	reloadMu          sync.Mutex                                    // serializes reloads, since loading the config uses the global viper instance
+	httpHandler       *mcp.StreamableHTTPHandler                    // Streamable HTTP transport, answers with SSE streams
+	jsonHTTPHandler   *mcp.StreamableHTTPHandler                    // plain HTTP transport, answers with JSON responses
+	capsules          *xsync.MapOf[string, *core.CapsuleManager]    // the key here is the tool name
+	capsuleRestartMu  sync.Mutex                                    // serializes restarts of unhealthy capsules
+	persistents       *xsync.MapOf[string, *core.PersistentProcess] // processes of persistent-mode tools, keyed by tool name
+	registeredTools   mapset.Set[string]                            // the key here is the tool name
+	calls             *callTracker                                  // in-flight and recent tool calls for the admin endpoint
+	metrics           *serverMetrics                                // tool call and capsule metrics for the metrics endpoint
+	audit             *auditLogger                                  // audit log of tool calls, nil unless audit_log_path is set
+	mcpNames          *mcpToolNamer                                 // MCP names assigned to registered tools, reset on rebuild
+	disabledTools     mapset.Set[string]                            // tools disabled with orla tool disable, skipped on rebuild
+	disabledToolsPath string                                        // state file persisting disabledTools, empty if unavailable
+	toolsHash         string                                        // hash of the registered tool definitions, see ToolsHash
+	toolFilter        ToolFilter                                    // tools to serve or skip, from orla serve --only/--skip
+	traceToolsFlag    bool                                          // tracing forced on by orla serve --trace-tools, kept across reloads
+	capabilities      *Capabilities                                 // features enabled by the current config, rebuilt on reload
+	rebuild           rebuildGate                                   // lets tool calls wait for an in-progress rebuild
+	rebuildWait       time.Duration                                 // how long a tool call waits for a rebuild, see defaultRebuildWait
+	watchDebounce     time.Duration                                 // how long the file watcher waits for changes to stop, see defaultWatchDebounce
+	previousCapsules  map[string]*core.CapsuleManager               // capsules running before the current rebuild, nil outside of rebuilds
 }
 
 // NewOrlaServer creates a new OrlaServer instance
 func NewOrlaServer(cfg *config.OrlaConfig, configPath string) *OrlaServer {
+	return NewOrlaServerWithToolFilter(cfg, configPath, ToolFilter{})
+}
+
+// NewOrlaServerWithToolFilter creates a new OrlaServer instance that only registers the tools
+// the filter allows
+func NewOrlaServerWithToolFilter(cfg *config.OrlaConfig, configPath string, toolFilter ToolFilter) *OrlaServer {
 	executor := core.NewOrlaToolExecutor(cfg.Timeout)
+	executor.SetMaxOutputBytes(cfg.MaxOutputBytes)
+	executor.SetWorkingDir(cfg.ToolWorkingDir)
+
+	disabledToolsPath, err := registry.GetDisabledToolsPath()
+	if err != nil {
+		zap.L().Warn("Failed to locate disabled tools file, tools cannot be disabled at runtime", zap.Error(err))
+	}
 
 	orlaServer := &OrlaServer{
-		config:          cfg,
-		configPath:      configPath,
-		executor:        executor,
-		capsules:        xsync.NewMapOf[string, *core.CapsuleManager](),
-		registeredTools: mapset.NewSet[string](),
+		config:            cfg,
+		configPath:        configPath,
+		executor:          executor,
+		capsules:          xsync.NewMapOf[string, *core.CapsuleManager](),
+		persistents:       xsync.NewMapOf[string, *core.PersistentProcess](),
+		registeredTools:   mapset.NewSet[string](),
+		calls:             newCallTracker(defaultRecentCallsLimit),
+		metrics:           newServerMetrics(),
+		disabledToolsPath: disabledToolsPath,
+		toolFilter:        toolFilter,
+		rebuildWait:       defaultRebuildWait,
+		watchDebounce:     defaultWatchDebounce,
 	}
 
 	orlaServer.rebuildServer()
 
-	// Create HTTP handler that manages sessions, Origin validation, etc.
-	orlaServer.httpHandler = mcp.NewStreamableHTTPHandler(
+	// Create HTTP handlers that manage sessions, Origin validation, etc.
+	orlaServer.httpHandler = orlaServer.newHTTPHandler(false)
+	orlaServer.jsonHTTPHandler = orlaServer.newHTTPHandler(true)
+
+	return orlaServer
+}
+
+// newHTTPHandler creates an MCP handler for HTTP clients that serves the current MCP server.
+// With jsonResponse, each request is answered with a single JSON response instead of an SSE
+// stream, for clients that do not read SSE.
+func (o *OrlaServer) newHTTPHandler(jsonResponse bool) *mcp.StreamableHTTPHandler {
+	return mcp.NewStreamableHTTPHandler(
 		func(*http.Request) *mcp.Server {
-			orlaServer.mu.RLock()
-			defer orlaServer.mu.RUnlock()
-			return orlaServer.orlaMCPserver
+			o.mu.RLock()
+			defer o.mu.RUnlock()
+			return o.orlaMCPserver
 		},
 		&mcp.StreamableHTTPOptions{
-			Stateless: false,
+			Stateless:    false,
+			JSONResponse: jsonResponse,
 		},
 	)
-
-	return orlaServer
 }
 
 // rebuildServer rebuilds OrlaServer's state with current tools.
@@ -70,6 +126,15 @@ func (o *OrlaServer) rebuildServer() {
 	o.mu.Lock()
 	defer o.mu.Unlock()
 
+	o.rebuildServerLocked()
+}
+
+// rebuildServerLocked rebuilds OrlaServer's state with current tools. The caller must hold o.mu
+// for writing. Tool calls that arrive meanwhile wait for it, see waitForRebuild.
+func (o *OrlaServer) rebuildServerLocked() {
+	o.rebuild.begin()
+	defer o.rebuild.end()
+
 	// Create new Orla MCP server.
 	// note(jadidbourbaki): this does *not* break existing connections, because
 	// each connection handler (handleTCPConnection/ServeStdio) captures a reference
@@ -84,7 +149,12 @@ func (o *OrlaServer) rebuildServer() {
 
 	// Use the tools registry loaded from config (state.Load builds it)
 	tools := o.config.ToolsRegistry
-	toolList := tools.ListTools()
+	// Register tools in name order so that MCP name collisions resolve deterministically. The
+	// sorted list is a copy, the registry's tools are left as they are.
+	toolList := slices.SortedFunc(slices.Values(tools.ListTools()), func(a, b *core.ToolManifest) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	o.mcpNames = newMCPToolNamer()
 
 	// Log tool discovery results
 	if len(toolList) == 0 {
@@ -97,54 +167,163 @@ func (o *OrlaServer) rebuildServer() {
 			zap.String("directory", o.config.ToolsDir))
 	}
 
-	// Stop existing capsules before rebuilding
-	o.stopAllCapsules()
+	// Set the running capsules aside, so that capsules of unchanged tools are reused and the others
+	// are drained rather than stopped in the middle of a call. Persistent tool processes are stopped.
+	o.previousCapsules = o.takeCapsules()
+	o.stopAllPersistentProcesses()
+
+	// Register each discovered tool that is not excluded, see skipReason
+	o.registeredTools.Clear()
+	o.disabledTools = o.loadDisabledTools()
+	o.toolFilter.warnUnknownTools(toolList)
+	for _, tool := range toolList {
+		if reason := o.skipReason(tool); reason != "" {
+			zap.L().Info("Skipping tool", zap.String("tool", tool.Name), zap.String("reason", reason))
+			continue
+		}
+		o.addTool(tool)
+	}
+	drainCapsules(o.previousCapsules, time.Duration(o.config.CapsuleDrainTimeout)*time.Second)
+	o.previousCapsules = nil
 
-	// Register each discovered tool
-	for i, tool := range toolList {
-		runtimeMode := core.RuntimeModeSimple
-		if tool.Runtime != nil {
-			runtimeMode = tool.Runtime.Mode
+	o.updateAuditLogLocked()
+	o.updateToolsHash()
+	o.capabilities = o.buildCapabilities()
+}
+
+// loadDisabledTools reads the set of disabled tools from the state file. Failing to read it is
+// logged and treated as no tools being disabled, so a corrupt state file never takes tools offline.
+func (o *OrlaServer) loadDisabledTools() mapset.Set[string] {
+	if o.disabledToolsPath == "" {
+		return mapset.NewSet[string]()
+	}
+
+	disabledTools, err := state.LoadDisabledTools(o.disabledToolsPath)
+	if err != nil {
+		zap.L().Error("Failed to load disabled tools, enabling all tools",
+			zap.String("path", o.disabledToolsPath),
+			zap.Error(err))
+		return mapset.NewSet[string]()
+	}
+	return disabledTools
+}
+
+// addTool starts the tool's capsule if it runs in capsule mode and registers the tool with the MCP
+// server. Tools that require a newer orla, whose package manager is not installed, whose working
+// directory does not exist, or whose capsule fails to start are skipped.
+func (o *OrlaServer) addTool(tool *core.ToolManifest) {
+	if err := core.CheckMinOrlaVersion(tool.Name, tool.MinOrlaVersion); err != nil {
+		zap.L().Warn("Skipping tool registration",
+			zap.String("tool", tool.Name),
+			zap.String("min_orla_version", tool.MinOrlaVersion),
+			zap.Error(err))
+		return
+	}
+
+	// Every call of a package tool would fail without its package manager
+	if core.IsPackageTool(tool) {
+		if _, err := core.ResolvePackageManager(tool); err != nil {
+			zap.L().Error("Package manager not found, skipping tool registration",
+				zap.String("tool", tool.Name),
+				zap.Error(err))
+			return
 		}
-		zap.L().Info("Registering tool with MCP server",
-			zap.Int("index", i),
-			zap.String("name", tool.Name),
-			zap.String("path", tool.Path),
-			zap.String("description", tool.Description),
-			zap.String("runtime_mode", string(runtimeMode)))
-
-		// Start capsule if tool is in capsule mode
-		if runtimeMode == core.RuntimeModeCapsule {
-			capsule := core.NewCapsuleManager(tool)
-
-			startErr := capsule.Start()
+	}
+
+	runtimeMode := core.RuntimeModeSimple
+	if tool.Runtime != nil {
+		runtimeMode = tool.Runtime.Mode
+	}
+
+	// The tool's process would fail to start outside an existing directory
+	if workingDir := toolRunDir(o.executor, tool); workingDir != "" {
+		if err := core.CheckWorkingDir(workingDir); err != nil {
+			zap.L().Error("Working directory not found, skipping tool registration",
+				zap.String("tool", tool.Name),
+				zap.Error(err))
+			return
+		}
+	}
+
+	zap.L().Info("Registering tool with MCP server",
+		zap.String("name", tool.Name),
+		zap.String("path", tool.Path),
+		zap.String("description", tool.Description),
+		zap.String("runtime_mode", string(runtimeMode)))
+
+	// Start capsule if tool is in capsule mode. While the tools are rebuilt, the capsule of an
+	// unchanged tool keeps running instead.
+	if runtimeMode == core.RuntimeModeCapsule {
+		if capsule, ok := o.reusableCapsule(tool); ok {
+			o.capsules.Store(tool.Name, capsule)
+			zap.L().Info("Reusing capsule of unchanged tool",
+				zap.String("tool", tool.Name))
+		} else {
+			capsule, startErr := o.startCapsule(tool, 0, o.executor.MaxOutputBytes())
 			if startErr != nil {
 				zap.L().Error("Failed to start capsule, skipping tool registration",
 					zap.String("tool", tool.Name),
 					zap.Error(startErr))
 				// Skip registration if capsule fails to start
-				continue
+				return
 			}
 
 			o.capsules.Store(tool.Name, capsule)
 			zap.L().Info("Capsule started",
 				zap.String("tool", tool.Name))
 		}
+	}
+
+	// A persistent-mode tool's process is started by its first call
+	if runtimeMode == core.RuntimeModePersistent {
+		persistent := core.NewPersistentProcess(tool)
+		persistent.SetMaxOutputBytes(o.executor.MaxOutputBytes())
+		o.persistents.Store(tool.Name, persistent)
+	}
+
+	o.registerTool(tool)
+}
+
+// removeTool unregisters the tool from the MCP server and stops its capsule, if any
+func (o *OrlaServer) removeTool(tool *core.ToolManifest) {
+	if mcpName, ok := o.mcpNames.release(tool.Name); ok {
+		o.orlaMCPserver.RemoveTools(mcpName)
+	}
+
+	if capsule, ok := o.capsules.LoadAndDelete(tool.Name); ok {
+		if err := capsule.Stop(); err != nil {
+			zap.L().Error("Failed to stop capsule",
+				zap.String("tool", tool.Name),
+				zap.Error(err))
+		}
+	}
 
-		o.registerTool(tool)
+	if persistent, ok := o.persistents.LoadAndDelete(tool.Name); ok {
+		persistent.Stop()
 	}
+
+	o.registeredTools.Remove(tool.Name)
 }
 
 // registerTool registers a single tool with the MCP server
 func (o *OrlaServer) registerTool(tool *core.ToolManifest) {
 	// Create a handler function for this tool using map[string]any for input
 	// Wrap with panic recovery at the handler boundary since this is the single point
-	// where we can return proper MCP error responses
+	// where we can return proper MCP error responses. The output is returned as any so that a
+	// call without structured output returns a nil interface, which the SDK neither validates
+	// against the tool's output schema nor sends as structured content; a nil map would be.
 	handler := func(ctx context.Context, req *mcp.CallToolRequest, input map[string]any) (
 		result *mcp.CallToolResult,
-		output map[string]any,
+		output any,
 		err error,
 	) {
+		// Track the call for the admin endpoint. This is deferred first so that it
+		// observes the result after any panic recovery below.
+		callID := o.calls.begin(tool.Name)
+		defer func() {
+			o.calls.end(callID, err != nil || (result != nil && result.IsError))
+		}()
+
 		// Panic recovery at the handler boundary
 		defer func() {
 			if r := recover(); r != nil {
@@ -162,17 +341,31 @@ func (o *OrlaServer) registerTool(tool *core.ToolManifest) {
 				err = fmt.Errorf("panic recovered: %v", r)
 			}
 		}()
-		return o.handleToolCall(ctx, tool, input)
+		result, outputMap, err := o.executeToolCall(ctx, tool, input, callMeta(req), callSessionID(req), newOutputStreamer(ctx, req))
+		if outputMap != nil {
+			output = outputMap
+		}
+		return result, output, err
 	}
 
+	// Raw tool names come from filenames and manifests and may not be valid MCP names.
+	// When the name had to be changed, keep the original as the display title.
+	mcpName := o.mcpNames.assign(tool.Name)
+
 	// Add tool to MCP server
 	zap.L().Debug("Calling mcp.AddTool",
 		zap.String("tool", tool.Name),
+		zap.String("mcp_name", mcpName),
 		zap.String("description", tool.Description))
 
+	stability := core.StabilityOf(tool)
 	mcpTool := &mcp.Tool{
-		Name:        tool.Name,
-		Description: tool.Description,
+		Name:        mcpName,
+		Description: core.DescriptionWithStability(stability, tool.Description),
+		Meta:        mcp.Meta{StabilityMetaKey: string(stability)},
+	}
+	if mcpName != tool.Name {
+		mcpTool.Title = tool.Name
 	}
 
 	// Add input schema if available
@@ -192,7 +385,9 @@ func (o *OrlaServer) registerTool(tool *core.ToolManifest) {
 }
 
 // buildToolResponse builds an MCP CallToolResult and outputMap from tool execution output.
-// It handles both structured (with output schema) and unstructured output.
+// It handles both structured (with output schema) and unstructured output. If outputJSONPath is
+// set, the stdout of a successful call is replaced by the part of it at that path. Image files
+// named in a content envelope are read relative to runDir and up to maxImageBytes.
 func buildToolResponse(
 	toolName string,
 	stdout string,
@@ -200,17 +395,55 @@ func buildToolResponse(
 	exitCode int,
 	execErr error,
 	outputSchema map[string]any,
+	annotations *core.OutputAnnotationsConfig,
+	contentType string,
+	outputJSONPath string,
+	runDir string,
+	maxImageBytes int64,
 ) (*mcp.CallToolResult, map[string]any) {
-	// Build content from stdout
-	content := []mcp.Content{
-		&mcp.TextContent{
-			Text: stdout,
-		},
+	if outputJSONPath != "" && execErr == nil && exitCode == 0 {
+		selected, err := selectToolStdout(outputJSONPath, stdout)
+		if err != nil {
+			zap.L().Error("Failed to select tool output",
+				zap.String("tool", toolName),
+				zap.String("output_json_path", outputJSONPath),
+				zap.Error(err))
+			return outputJSONPathErrorResult(err), nil
+		}
+		stdout = selected
+	}
+
+	var stdoutAnnotation, stderrAnnotation *core.ContentAnnotation
+	if annotations != nil {
+		stdoutAnnotation, stderrAnnotation = annotations.Stdout, annotations.Stderr
+	}
+
+	// Build content from stdout. A tool with an image content type prints the image itself,
+	// unless it failed. Without an output schema, the tool may print a content envelope to return
+	// several annotated content items.
+	var content []mcp.Content
+	isImage := core.IsImageContentType(contentType) && stdout != "" && execErr == nil && exitCode == 0
+	switch {
+	case isImage:
+		image, _ := imageContent([]byte(stdout), contentType, stdoutAnnotation)
+		content = []mcp.Content{image}
+	case outputSchema == nil:
+		content, _ = parseContentEnvelope(stdout, stdoutAnnotation, textContentType(contentType), runDir, maxImageBytes)
+	}
+	if content == nil {
+		content = []mcp.Content{
+			&mcp.TextContent{
+				Text:        stdout,
+				Meta:        contentTypeMeta(textContentType(contentType)),
+				Annotations: toMCPAnnotations(stdoutAnnotation),
+			},
+		}
 	}
 
 	if stderr != "" {
 		content = append(content, &mcp.TextContent{
-			Text: fmt.Sprintf("stderr: %s", stderr),
+			Text:        fmt.Sprintf("stderr: %s", stderr),
+			Annotations: toMCPAnnotations(stderrAnnotation),
 		})
 	}
 
@@ -226,6 +459,9 @@ func buildToolResponse(
 		IsError: isError,
 		Content: content,
 	}
+	if exitCode != 0 {
+		callToolResult.Meta = mcp.Meta{ExitCodeMetaKey: exitCode}
+	}
 
 	outputMap := make(map[string]any)
 
@@ -233,9 +469,12 @@ func buildToolResponse(
 		outputMap["error"] = execErr.Error()
 	}
 
-	// If no output schema, wrap with default format
+	// If no output schema, wrap with default format. The bytes of an image are only returned as
+	// its content.
 	if outputSchema == nil {
-		outputMap["stdout"] = stdout
+		if !isImage {
+			outputMap["stdout"] = stdout
+		}
 		outputMap["stderr"] = stderr
 		outputMap["exit_code"] = exitCode
 		return callToolResult, outputMap
@@ -281,6 +520,22 @@ func buildToolResponse(
 		}, nil
 	}
 
+	// Report missing required properties by name: it is the most common way for tool output to
+	// drift from its schema, and the generic validation error is harder to read
+	if missing := missingRequiredProperties(outputSchema, parsedMap); len(missing) > 0 {
+		zap.L().Error("Tool output is missing required properties",
+			zap.String("tool", toolName),
+			zap.Strings("missing", missing))
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Tool output is missing required properties of the output schema: %s", strings.Join(missing, ", ")),
+				},
+			},
+		}, nil
+	}
+
 	outputMap = parsedMap
 
 	// Note(jadidbourbaki): After some experimentation, it seems like we
@@ -290,12 +545,67 @@ func buildToolResponse(
 	return callToolResult, outputMap
 }
 
-// handleToolCall handles a tool execution request
+// handleToolCall handles a tool execution request with buffered output
 func (o *OrlaServer) handleToolCall(
 	ctx context.Context,
 	tool *core.ToolManifest,
 	input map[string]any,
 ) (*mcp.CallToolResult, map[string]any, error) {
+	return o.executeToolCall(ctx, tool, input, nil, "", nil)
+}
+
+// executeToolCall executes a tool call like handleToolCall and records it in the metrics and the
+// audit log. The _meta fields of the call that the tool allows are passed to simple mode tools as
+// environment variables. sessionID is the caller's MCP session, if any. If stdoutStream is
+// non-nil, the stdout of simple mode tools is also written to it while the tool runs.
+func (o *OrlaServer) executeToolCall(
+	ctx context.Context,
+	tool *core.ToolManifest,
+	input map[string]any,
+	meta map[string]any,
+	sessionID string,
+	stdoutStream io.Writer,
+) (*mcp.CallToolResult, map[string]any, error) {
+	startTime := time.Now()
+	result, output, err := o.runToolCall(ctx, tool, input, meta, stdoutStream)
+
+	duration := time.Since(startTime)
+	status := toolCallResult(result, err, duration, o.toolExecutor().TimeoutFor(tool))
+	o.metrics.observeToolCall(tool.Name, status, duration)
+	o.auditToolCall(tool, input, sessionID, startTime, result, err, status)
+	return result, output, err
+}
+
+// runToolCall runs a tool call for executeToolCall in the way the tool's runtime mode requires
+func (o *OrlaServer) runToolCall(
+	ctx context.Context,
+	tool *core.ToolManifest,
+	input map[string]any,
+	meta map[string]any,
+	stdoutStream io.Writer,
+) (*mcp.CallToolResult, map[string]any, error) {
+	// Calls that arrive while the tools are rebuilt wait for the rebuild rather than run against
+	// capsules and processes that are being restarted
+	if result := o.waitForRebuild(ctx, tool.Name); result != nil {
+		return result, nil, nil
+	}
+
+	// Relative stdin_file paths are resolved against the directory the tool runs in
+	runDir := toolRunDir(o.toolExecutor(), tool)
+
+	// Reject oversized input before it reaches the tool
+	if err := checkInputSize(tool, input, runDir); err != nil {
+		core.LogToolExecution(tool.Name, 0, err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Input too large: %v", err),
+				},
+			},
+		}, nil, nil
+	}
+
 	// Check if tool is in capsule mode
 	runtimeMode := core.RuntimeModeSimple
 	if tool.Runtime != nil {
@@ -304,42 +614,89 @@ func (o *OrlaServer) handleToolCall(
 
 	// For capsule mode, communicate with the running process via JSON-RPC
 	if runtimeMode == core.RuntimeModeCapsule {
+		if o.dryRun() {
+			return dryRunRequestResult(tool, runtimeMode, capsuleDryRunRequest(tool, input))
+		}
 		return o.handleCapsuleToolCall(ctx, tool, input)
 	}
 
 	startTime := time.Now()
 
-	// For simple mode, execute on-demand
-	// Convert input map to arguments
-	var args []string
-	stdin := ""
-
-	for k, v := range input {
-		if k == "stdin" {
-			if stdinVal, ok := v.(string); ok {
-				stdin = stdinVal
+	// For persistent mode, send the call to the tool's long-running process
+	if runtimeMode == core.RuntimeModePersistent {
+		if o.dryRun() {
+			if input == nil {
+				input = map[string]any{}
 			}
-			continue
+			return dryRunRequestResult(tool, runtimeMode, input)
 		}
-		// Convert underscores to hyphens for command-line arguments (standard convention)
-		argName := strings.ReplaceAll(k, "_", "-")
-		args = append(args, fmt.Sprintf("--%s", argName))
-		args = append(args, fmt.Sprintf("%v", v))
+		return o.handlePersistentToolCall(ctx, tool, input, startTime)
+	}
+
+	// For simple mode, execute on-demand
+	stdin, closeStdin, err := resolveCallStdin(tool, input, runDir)
+	if err != nil {
+		core.LogToolExecution(tool.Name, time.Since(startTime).Seconds(), err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Invalid stdin: %v", err),
+				},
+			},
+		}, nil, nil
+	}
+	defer func() { closeStdin() }()
+
+	// Convert input map to arguments in the tool's arg_style
+	args := core.ToolArgs(tool, withoutStdinArgs(input))
+
+	if o.traceTools() {
+		o.toolExecutor().TraceCommand(tool, args).Log()
+	}
+
+	// In dry run mode the command is described instead of run
+	if o.dryRun() {
+		return dryRunCommandResult(o.toolExecutor(), tool, args, input)
 	}
 
-	// Execute tool
-	result, err := o.executor.Execute(ctx, tool, args, stdin)
+	// Execute tool, retrying transient failures if the manifest allows it
+	executor := o.toolExecutor()
+	callEnv := metaEnv(tool, meta)
+	result, err := o.executeWithRetry(ctx, executor, tool, func(ctx context.Context, attempt int) (*core.OrlaToolExecutionResult, error) {
+		if attempt > 1 {
+			// The previous attempt consumed stdin, so it is opened again
+			closeStdin()
+			stdin, closeStdin, err = resolveCallStdin(tool, input, runDir)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return executor.ExecuteStreaming(ctx, tool, args, callEnv, stdin, stdoutStream)
+	})
 
 	if err != nil {
 		duration := time.Since(startTime).Seconds()
 		core.LogToolExecution(tool.Name, duration, err)
 		// Provide more helpful error messages
 		errorMsg := fmt.Sprintf("Tool execution failed: %v", err)
+		var interpreterErr *core.InterpreterNotFoundError
+		if errors.As(err, &interpreterErr) {
+			errorMsg = fmt.Sprintf("Interpreter not found: %v", interpreterErr)
+		}
+		var limitErr *core.OutputLimitError
+		if errors.As(err, &limitErr) {
+			errorMsg = fmt.Sprintf("Tool '%s' was stopped because its %s exceeded %d bytes (max_output_bytes), its output was truncated at the limit",
+				tool.Name, limitErr.Stream, limitErr.Limit)
+			if result != nil && result.Stdout != "" {
+				errorMsg += "\n\nTruncated output:\n" + result.Stdout
+			}
+		}
 		if result != nil && result.Error != nil {
 			// Check for timeout errors and provide helpful message
 			timeoutErrMsg := result.Error.Error()
 			if strings.Contains(timeoutErrMsg, "timed out") {
-				errorMsg = fmt.Sprintf("Tool '%s' timed out after %d seconds. Consider increasing the 'timeout' value in your configuration file.", tool.Name, o.config.Timeout)
+				errorMsg = timeoutErrorMessage(tool, executor.TimeoutFor(tool))
 			}
 		}
 		return &mcp.CallToolResult{
@@ -357,6 +714,13 @@ func (o *OrlaServer) handleToolCall(
 		outputSchema = tool.MCP.OutputSchema
 	}
 
+	var outputAnnotations *core.OutputAnnotationsConfig
+	var contentType string
+	if tool.MCP != nil {
+		outputAnnotations = tool.MCP.OutputAnnotations
+		contentType = tool.MCP.ContentType
+	}
+
 	callToolResult, outputMap := buildToolResponse(
 		tool.Name,
 		result.Stdout,
@@ -364,6 +728,11 @@ func (o *OrlaServer) handleToolCall(
 		result.ExitCode,
 		result.Error,
 		outputSchema,
+		outputAnnotations,
+		contentType,
+		outputJSONPathOf(tool),
+		runDir,
+		executor.MaxOutputBytes(),
 	)
 
 	duration := time.Since(startTime).Seconds()
@@ -372,33 +741,137 @@ func (o *OrlaServer) handleToolCall(
 	return callToolResult, outputMap, nil
 }
 
+// timeoutErrorMessage describes a tool call that hit its timeout, naming the setting that limits it
+func timeoutErrorMessage(tool *core.ToolManifest, timeout time.Duration) string {
+	seconds := int(timeout / time.Second)
+	if tool.TimeoutSeconds > 0 {
+		return fmt.Sprintf("Tool '%s' timed out after %d seconds (its timeout_seconds limit). Consider increasing 'timeout_seconds' in the tool's tool.yaml.", tool.Name, seconds)
+	}
+	return fmt.Sprintf("Tool '%s' timed out after %d seconds (the server-wide timeout). Consider increasing the 'timeout' value in your configuration file.", tool.Name, seconds)
+}
+
 // Reload reloads configuration and rescans tools directory
-func (o *OrlaServer) Reload() error {
+func (o *OrlaServer) Reload() (err error) {
 	// Panic recovery for reload operation
 	defer func() {
 		if r := recover(); r != nil {
 			core.LogPanicRecovery("reload", r)
+			err = fmt.Errorf("panic recovered during reload: %v", r)
 		}
 	}()
 
-	var newCfg *config.OrlaConfig
+	o.reloadMu.Lock()
+	defer o.reloadMu.Unlock()
+
 	newCfg, err := config.LoadConfig(o.configPath)
 
 	if err != nil {
 		return fmt.Errorf("failed to reload configuration: %w", err)
 	}
 
-	// Recreate executor in case timeout changed
+	// Swap the config and executor and rebuild under one lock, so that a tool call never sees the
+	// new config with the old server or the other way around
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// Recreate executor in case timeout, max_output_bytes, or tool_working_dir changed
 	o.executor = core.NewOrlaToolExecutor(newCfg.Timeout)
+	o.executor.SetMaxOutputBytes(newCfg.MaxOutputBytes)
+	o.executor.SetWorkingDir(newCfg.ToolWorkingDir)
+	if o.traceToolsFlag {
+		newCfg.TraceTools = true
+	}
 	o.config = newCfg
 
-	o.rebuildServer()
+	o.rebuildServerLocked()
 	return nil
 }
 
+// toolExecutor returns the executor of the current config. A tool call reads it once, so all of
+// its attempts run with the same timeout even if the server is reloaded meanwhile.
+func (o *OrlaServer) toolExecutor() *core.OrlaToolExecutor {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.executor
+}
+
+// toolRunDir returns the directory the tool's process runs in: its working_dir, or else the
+// directory of its entrypoint for capsule and persistent mode tools and the executor's working
+// directory for simple mode tools. It is empty if the tool runs in orla's working directory.
+func toolRunDir(executor *core.OrlaToolExecutor, tool *core.ToolManifest) string {
+	if tool.WorkingDir != "" {
+		return tool.WorkingDir
+	}
+	if tool.Runtime != nil && (tool.Runtime.Mode == core.RuntimeModeCapsule || tool.Runtime.Mode == core.RuntimeModePersistent) {
+		if tool.Path == "" {
+			return ""
+		}
+		return filepath.Dir(tool.Path)
+	}
+	return executor.WorkingDirFor(tool)
+}
+
+// EnableToolTracing logs the command line of every tool execution, as orla serve --trace-tools
+// does. Unlike trace_tools in the config file, it stays on when the config is reloaded.
+func (o *OrlaServer) EnableToolTracing() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.traceToolsFlag = true
+	o.config.TraceTools = true
+	o.capabilities = o.buildCapabilities()
+}
+
+// traceTools reports whether the current config logs the command line of every tool execution
+func (o *OrlaServer) traceTools() bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.config.TraceTools
+}
+
+// CallTool calls the named tool with the given input outside of an MCP session, the same way an
+// MCP client's tools/call request would. It is used by orla run. The MCP SDK validates the
+// arguments of tools/call requests before they reach the tool handler, so CallTool validates them
+// against the tool's input schema itself.
+// If the server is still rebuilding its tools after a short wait, it returns a *ServerReloadingError.
+func (o *OrlaServer) CallTool(ctx context.Context, name string, input map[string]any) (*mcp.CallToolResult, error) {
+	if result := o.waitForRebuild(ctx, name); result != nil {
+		return result, &ServerReloadingError{Tool: name, Waited: o.rebuildWait}
+	}
+
+	o.mu.RLock()
+	tool, err := o.config.ToolsRegistry.GetTool(name)
+	o.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	input, err = validateToolInput(tool, input)
+	if err != nil {
+		core.LogToolExecution(tool.Name, 0, err)
+		return invalidArgumentsResult(err), nil
+	}
+
+	result, _, err := o.handleToolCall(ctx, tool, input)
+	return result, err
+}
+
+// Close stops the capsules of capsule-mode tools, the processes of persistent-mode tools, and the
+// simple mode tool calls still running
+func (o *OrlaServer) Close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.stopAllCapsules()
+	o.stopAllPersistentProcesses()
+	core.TerminateRunningTools()
+	o.closeAuditLogLocked()
+}
+
 // stopAllCapsules stops all running capsules
 func (o *OrlaServer) stopAllCapsules() {
 	o.capsules.Range(func(name string, capsule *core.CapsuleManager) bool {
+		// Deleting each capsule before stopping it keeps its supervisor from replacing it
+		o.capsules.Delete(name)
 		if err := capsule.Stop(); err != nil {
 			zap.L().Error("Failed to stop capsule",
 				zap.String("tool", name),
@@ -408,8 +881,16 @@ func (o *OrlaServer) stopAllCapsules() {
 		}
 		return true
 	})
+}
 
-	o.capsules.Clear()
+// stopAllPersistentProcesses stops the processes of all persistent-mode tools
+func (o *OrlaServer) stopAllPersistentProcesses() {
+	o.persistents.Range(func(name string, persistent *core.PersistentProcess) bool {
+		persistent.Stop()
+		return true
+	})
+
+	o.persistents.Clear()
 }
 
 // handleCapsuleToolCall handles tool calls for capsule mode tools by sending JSON-RPC requests to the running process
@@ -420,8 +901,15 @@ func (o *OrlaServer) handleCapsuleToolCall(
 ) (*mcp.CallToolResult, map[string]any, error) {
 	callStartTime := time.Now()
 
-	// Get the capsule manager for this tool
+	// Get the capsule manager for this tool. A rebuild that started after the call arrived stops
+	// the capsule and starts it again, so a missing capsule is looked up again after it.
 	capsule, capsuleOk := o.capsules.Load(tool.Name)
+	if !capsuleOk {
+		if result := o.waitForRebuild(ctx, tool.Name); result != nil {
+			return result, nil, nil
+		}
+		capsule, capsuleOk = o.capsules.Load(tool.Name)
+	}
 
 	if !capsuleOk {
 		duration := time.Since(callStartTime).Seconds()
@@ -437,8 +925,65 @@ func (o *OrlaServer) handleCapsuleToolCall(
 		}, nil, fmt.Errorf("capsule not found: %s", tool.Name)
 	}
 
-	// Send JSON-RPC request to capsule
-	jsonrpcResponse, callErr := capsule.CallTool(ctx, input)
+	// A capsule that stopped answering health checks would only time out, so it is restarted first
+	if !capsule.IsHealthy() {
+		restarted, restartErr := o.restartCapsule(tool, capsule)
+		if restartErr != nil {
+			duration := time.Since(callStartTime).Seconds()
+			core.LogToolExecution(tool.Name, duration, restartErr)
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Capsule '%s' is unhealthy and failed to restart: %v", tool.Name, restartErr),
+					},
+				},
+			}, nil, restartErr
+		}
+		capsule = restarted
+	}
+
+	// Capsules that support streaming input receive stdin as chunks rather than as an argument
+	call := func(capsule *core.CapsuleManager) (*core.JSONRPCResponse, error) {
+		// Send JSON-RPC request to capsule
+		return capsule.CallTool(ctx, input)
+	}
+	if capsule.HasCapability(core.CapsuleCapabilityStreamingInput) && hasStdinArg(input) {
+		stdinReader, closeStdin, stdinErr := resolveToolStdin(tool, input, toolRunDir(o.toolExecutor(), tool))
+		defer closeStdin()
+		if stdinErr != nil {
+			duration := time.Since(callStartTime).Seconds()
+			core.LogToolExecution(tool.Name, duration, stdinErr)
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: fmt.Sprintf("Invalid stdin: %v", stdinErr),
+					},
+				},
+			}, nil, nil
+		}
+		if stdinReader == nil {
+			stdinReader = strings.NewReader("")
+		}
+
+		arguments := withoutStdinArgs(input)
+		call = func(capsule *core.CapsuleManager) (*core.JSONRPCResponse, error) {
+			return capsule.CallToolWithInput(ctx, arguments, stdinReader)
+		}
+	}
+	jsonrpcResponse, callErr := call(capsule)
+
+	// A capsule that started draining after it was looked up refuses the call without running it,
+	// so the call is sent to the capsule that replaced it instead
+	if errors.Is(callErr, core.ErrCapsuleDraining) {
+		if result := o.waitForRebuild(ctx, tool.Name); result != nil {
+			return result, nil, nil
+		}
+		if replacement, ok := o.capsules.Load(tool.Name); ok {
+			jsonrpcResponse, callErr = call(replacement)
+		}
+	}
 
 	if callErr != nil {
 		duration := time.Since(callStartTime).Seconds()
@@ -467,16 +1012,27 @@ func (o *OrlaServer) handleCapsuleToolCall(
 		}, nil, fmt.Errorf("JSON-RPC error: %s", jsonrpcResponse.Error.Message)
 	}
 
+	// Keep only the part of the result at the tool's output_json_path
+	capsuleResult := jsonrpcResponse.Result
+	if outputJSONPath := outputJSONPathOf(tool); outputJSONPath != "" {
+		selected, selectErr := selectToolOutput(outputJSONPath, capsuleResult)
+		if selectErr != nil {
+			core.LogToolExecution(tool.Name, time.Since(callStartTime).Seconds(), selectErr)
+			return outputJSONPathErrorResult(selectErr), nil, nil
+		}
+		capsuleResult = selected
+	}
+
 	// Convert JSON-RPC result to stdout string
 	var stdoutStr string
 
-	if jsonrpcResponse.Result != nil {
+	if capsuleResult != nil {
 		// If result is a string, use it directly
-		if strResult, ok := jsonrpcResponse.Result.(string); ok {
+		if strResult, ok := capsuleResult.(string); ok {
 			stdoutStr = strResult
 		} else {
 			// Otherwise, marshal to JSON string
-			resultBytes, jsonErr := json.Marshal(jsonrpcResponse.Result)
+			resultBytes, jsonErr := json.Marshal(capsuleResult)
 			if jsonErr != nil {
 				duration := time.Since(callStartTime).Seconds()
 				core.LogToolExecution(tool.Name, duration, fmt.Errorf("failed to serialize capsule result: %w", jsonErr))
@@ -498,10 +1054,17 @@ func (o *OrlaServer) handleCapsuleToolCall(
 		outputSchema = tool.MCP.OutputSchema
 	}
 
+	var outputAnnotations *core.OutputAnnotationsConfig
+	var contentType string
+	if tool.MCP != nil {
+		outputAnnotations = tool.MCP.OutputAnnotations
+		contentType = tool.MCP.ContentType
+	}
+
 	// If we have an output schema and the result is already a map, use it directly
 	// Otherwise, let buildToolResponse parse it from the stdout string
 	if outputSchema != nil {
-		resultMap, resultMapOk := jsonrpcResponse.Result.(map[string]any)
+		resultMap, resultMapOk := capsuleResult.(map[string]any)
 		if resultMapOk {
 			// Result is already a map, use it directly
 			callToolResult := &mcp.CallToolResult{
@@ -517,6 +1080,7 @@ func (o *OrlaServer) handleCapsuleToolCall(
 	}
 
 	// Use the shared response builder (will parse JSON from stdout if needed)
+	executor := o.toolExecutor()
 	callToolResult, outputMap := buildToolResponse(
 		tool.Name,
 		stdoutStr,
@@ -524,6 +1088,11 @@ func (o *OrlaServer) handleCapsuleToolCall(
 		0,   // No exit code for capsule mode
 		nil, // No execution error for capsule mode
 		outputSchema,
+		outputAnnotations,
+		contentType,
+		"", // output_json_path was applied to the capsule result above
+		toolRunDir(executor, tool),
+		executor.MaxOutputBytes(),
 	)
 
 	duration := time.Since(callStartTime).Seconds()
@@ -532,22 +1101,32 @@ func (o *OrlaServer) handleCapsuleToolCall(
 	return callToolResult, outputMap, nil
 }
 
-// Serve starts the server on the given address using HTTP (Streamable HTTP transport per MCP spec)
-// The StreamableHTTPHandler manages sessions, Origin validation, and HTTP protocol details
-func (o *OrlaServer) Serve(ctx context.Context, addr string) error {
-	mux := http.NewServeMux()
+const (
+	// MCPPath is the HTTP path of the MCP Streamable HTTP endpoint, or of the plain HTTP
+	// endpoint when that is the only transport served
+	MCPPath = "/mcp"
+	// MCPJSONPath is the HTTP path of the plain HTTP MCP endpoint, which answers with JSON responses
+	MCPJSONPath = "/mcp/json"
+)
 
-	// MCP endpoint that handles both POST (client requests) and GET (SSE stream)
-	// StreamableHTTPHandler handles session management, Origin validation, etc.
-	mux.Handle("/mcp", o.httpHandler)
+// Serve starts the server on the given address using HTTP. The config's http_transport selects the
+// MCP endpoints: the Streamable HTTP transport per MCP spec at MCPPath, plain HTTP with JSON
+// responses at MCPJSONPath and MCPPath, or both. If the config sets watch_files, the server reloads
+// when its files change until ctx is done.
+func (o *OrlaServer) Serve(ctx context.Context, addr string) error {
+	if err := o.startFileWatcher(ctx); err != nil {
+		zap.L().Error("Failed to watch files, changes need a reload", zap.Error(err))
+	}
 
 	server := &http.Server{
 		Addr:              addr,
-		Handler:           mux,
+		Handler:           o.httpMux(),
 		ReadHeaderTimeout: 10 * time.Second,
 	}
 
-	zap.L().Info("Server listening", zap.String("address", addr))
+	zap.L().Info("Server listening",
+		zap.String("address", addr),
+		zap.String("http_transport", string(o.currentHTTPTransport())))
 
 	// Graceful shutdown
 	go func() {
@@ -566,8 +1145,68 @@ func (o *OrlaServer) Serve(ctx context.Context, addr string) error {
 	return nil
 }
 
-// ServeStdio starts the server using stdio transport (per MCP spec)
+// currentHTTPTransport returns the MCP endpoints to serve over HTTP under the current config
+func (o *OrlaServer) currentHTTPTransport() config.OrlaHTTPTransport {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.httpTransport()
+}
+
+// httpTransport returns the MCP endpoints to serve over HTTP, both unless the config selects one.
+// The caller must hold o.mu.
+func (o *OrlaServer) httpTransport() config.OrlaHTTPTransport {
+	if o.config.HTTPTransport == "" {
+		return config.OrlaHTTPTransportBoth
+	}
+	return o.config.HTTPTransport
+}
+
+// httpMux returns the handler of the HTTP server: the MCP endpoints selected by the config and
+// the admin endpoints
+func (o *OrlaServer) httpMux() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	// MCP endpoints that handle both POST (client requests) and GET (SSE stream)
+	// StreamableHTTPHandler handles session management, Origin validation, etc.
+	// Responses carry the tools hash so that clients can tell when the tool list changed.
+	// MCPPath is served in every mode so that clients configured with it keep working: with the
+	// plain HTTP transport alone it answers with JSON responses as MCPJSONPath does.
+	transport := o.currentHTTPTransport()
+	if transport == config.OrlaHTTPTransportHTTP {
+		mux.Handle(MCPPath, o.withToolsHashHeader(o.jsonHTTPHandler))
+	} else {
+		mux.Handle(MCPPath, o.withToolsHashHeader(o.httpHandler))
+	}
+	if transport != config.OrlaHTTPTransportStreamable {
+		mux.Handle(MCPJSONPath, o.withToolsHashHeader(o.jsonHTTPHandler))
+	}
+
+	// Admin endpoint exposing registered tools, capsule states, and recent calls (used by orla top).
+	// The admin and capabilities endpoints are refused unless admin_enabled is set, see withAdminAccess.
+	mux.HandleFunc(AdminStatePath, o.withAdminAccess(o.handleAdminState))
+
+	// Admin endpoints that disable and enable tools at runtime (used by orla tool disable/enable)
+	mux.HandleFunc(http.MethodPost+" "+AdminToolsPath+"{name}/"+AdminToolDisableAction, o.withAdminAccess(o.handleAdminToolAction(true)))
+	mux.HandleFunc(http.MethodPost+" "+AdminToolsPath+"{name}/"+AdminToolEnableAction, o.withAdminAccess(o.handleAdminToolAction(false)))
+
+	// Capabilities endpoint describing the features enabled by the config
+	mux.HandleFunc(CapabilitiesPath, o.withAdminAccess(o.handleCapabilities))
+
+	// Prometheus metrics endpoint, if enabled
+	if metricsPath, ok := o.currentMetricsPath(); ok {
+		mux.HandleFunc(metricsPath, o.handleMetrics)
+	}
+
+	return mux
+}
+
+// ServeStdio starts the server using stdio transport (per MCP spec). Like Serve, it watches the
+// server's files if the config sets watch_files.
 func (o *OrlaServer) ServeStdio(ctx context.Context) error {
+	if err := o.startFileWatcher(ctx); err != nil {
+		zap.L().Error("Failed to watch files, changes need a reload", zap.Error(err))
+	}
+
 	transport := &mcp.StdioTransport{}
 	// Capture the server instance with a read lock to ensure consistency.
 	// Note: stdio mode typically runs once at startup, so hot reload during
@@ -577,3 +1216,86 @@ func (o *OrlaServer) ServeStdio(ctx context.Context) error {
 	o.mu.RUnlock()
 	return server.Run(ctx, transport)
 }
+
+// handlePersistentToolCall handles tool calls for persistent mode tools by sending them to the
+// tool's long-running process. The input has already been validated.
+func (o *OrlaServer) handlePersistentToolCall(
+	ctx context.Context,
+	tool *core.ToolManifest,
+	input map[string]any,
+	startTime time.Time,
+) (*mcp.CallToolResult, map[string]any, error) {
+	// As with capsules, a rebuild that started after the call arrived restarts the process
+	persistent, ok := o.persistents.Load(tool.Name)
+	if !ok {
+		if result := o.waitForRebuild(ctx, tool.Name); result != nil {
+			return result, nil, nil
+		}
+		persistent, ok = o.persistents.Load(tool.Name)
+	}
+	if !ok {
+		err := fmt.Errorf("persistent tool not found: %s", tool.Name)
+		core.LogToolExecution(tool.Name, time.Since(startTime).Seconds(), err)
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Persistent tool '%s' is not running", tool.Name),
+				},
+			},
+		}, nil, err
+	}
+
+	executor := o.toolExecutor()
+	timeout := executor.TimeoutFor(tool)
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result, err := persistent.Call(callCtx, input)
+	if err != nil {
+		core.LogToolExecution(tool.Name, time.Since(startTime).Seconds(), err)
+		errorMsg := fmt.Sprintf("Tool execution failed: %v", err)
+		var interpreterErr *core.InterpreterNotFoundError
+		switch {
+		case errors.As(err, &interpreterErr):
+			errorMsg = fmt.Sprintf("Interpreter not found: %v", interpreterErr)
+		case errors.Is(err, context.DeadlineExceeded):
+			errorMsg = timeoutErrorMessage(tool, timeout)
+		}
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: errorMsg,
+				},
+			},
+		}, nil, nil
+	}
+
+	var outputSchema map[string]any
+	var outputAnnotations *core.OutputAnnotationsConfig
+	var contentType string
+	if tool.MCP != nil {
+		outputSchema = tool.MCP.OutputSchema
+		outputAnnotations = tool.MCP.OutputAnnotations
+		contentType = tool.MCP.ContentType
+	}
+
+	callToolResult, outputMap := buildToolResponse(
+		tool.Name,
+		result.Stdout,
+		"", // stderr of a persistent tool is logged, not attributed to calls
+		result.ExitCode,
+		nil,
+		outputSchema,
+		outputAnnotations,
+		contentType,
+		outputJSONPathOf(tool),
+		toolRunDir(executor, tool),
+		executor.MaxOutputBytes(),
+	)
+
+	core.LogToolExecution(tool.Name, time.Since(startTime).Seconds(), nil)
+
+	return callToolResult, outputMap, nil
+}
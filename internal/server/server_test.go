@@ -1,7 +1,9 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,6 +16,8 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/dorcha-inc/orla/internal/config"
 	"github.com/dorcha-inc/orla/internal/core"
@@ -383,6 +387,113 @@ echo "$name: $message"
 	assert.Contains(t, textContent.Text, "test: hello world")
 }
 
+// TestHandleToolCall_TraceTools tests that the command line of a tool execution is traced
+func TestHandleToolCall_TraceTools(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	coreLogger, logs := observer.New(zap.InfoLevel)
+	restoreLogger := zap.ReplaceGlobals(zap.New(coreLogger))
+	t.Cleanup(restoreLogger)
+
+	cfg := createTestConfig(t)
+	cfg.TraceTools = true
+	srv := NewOrlaServer(cfg, "")
+	require.NotNil(t, srv)
+
+	toolPath := filepath.Join(t.TempDir(), "trace-tool.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(toolPath, []byte("#!/bin/sh\necho traced\n"), 0755))
+
+	tool := &core.ToolManifest{
+		Name:        "trace-tool",
+		Description: "Trace tool",
+		Path:        toolPath,
+		Interpreter: "/bin/sh",
+		Runtime: &core.RuntimeConfig{
+			Args: []string{"--mode", "fast"},
+			Env: map[string]string{
+				"REGION":    "eu-west-1",
+				"API_TOKEN": "super-secret",
+			},
+		},
+	}
+
+	input := map[string]any{
+		"max_count": 3,
+		"verbose":   true,
+		"tags":      []any{"a", "b"},
+		"query":     "hello world",
+		"stdin":     "not an argument",
+	}
+
+	result, _, err := srv.handleToolCall(context.Background(), tool, input)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	traces := logs.FilterMessage("Tool command").All()
+	require.Len(t, traces, 1)
+	fields := traces[0].ContextMap()
+
+	assert.Equal(t, "trace-tool", fields["tool"])
+	command, ok := fields["command"].(string)
+	require.True(t, ok)
+	assert.True(t, strings.HasPrefix(command, "/bin/sh "+toolPath+" "), "command should start with the interpreter and entrypoint: %s", command)
+	assert.Contains(t, command, "--max-count 3")
+	assert.Contains(t, command, "--verbose true")
+	assert.Contains(t, command, "--tags '[a b]'")
+	assert.Contains(t, command, "--query 'hello world'")
+	assert.True(t, strings.HasSuffix(command, " --mode fast"), "runtime args should come last: %s", command)
+	assert.NotContains(t, command, "stdin")
+
+	assert.Equal(t, []any{"API_TOKEN=[REDACTED]", "REGION=eu-west-1"}, fields["env"])
+	assert.NotEmpty(t, fields["cwd"])
+}
+
+// TestEnableToolTracing_Reload tests that tracing enabled as by orla serve --trace-tools stays on
+// when the config, which does not enable it, is reloaded
+func TestEnableToolTracing_Reload(t *testing.T) {
+	cfg := createTestConfig(t)
+	configPath := filepath.Join(t.TempDir(), "orla.yaml")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte("tools_dir: "+cfg.ToolsDir+"\n"), 0644))
+
+	srv := NewOrlaServer(cfg, configPath)
+	t.Cleanup(srv.Close)
+	assert.False(t, srv.traceTools())
+
+	srv.EnableToolTracing()
+	assert.True(t, srv.traceTools())
+	assert.True(t, srv.Capabilities().TraceTools)
+
+	require.NoError(t, srv.Reload())
+	assert.True(t, srv.traceTools())
+	assert.True(t, srv.Capabilities().TraceTools)
+}
+
+// TestHandleToolCall_TraceToolsDisabled tests that tool executions are not traced by default
+func TestHandleToolCall_TraceToolsDisabled(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	coreLogger, logs := observer.New(zap.InfoLevel)
+	restoreLogger := zap.ReplaceGlobals(zap.New(coreLogger))
+	t.Cleanup(restoreLogger)
+
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+	require.NotNil(t, srv)
+
+	tools := cfg.ToolsRegistry.ListTools()
+	require.GreaterOrEqual(t, len(tools), 1)
+
+	_, _, err := srv.handleToolCall(context.Background(), tools[0], map[string]any{})
+	require.NoError(t, err)
+	assert.Empty(t, logs.FilterMessage("Tool command").All())
+}
+
 // TestHandleToolCall_WithStdin tests tool execution with stdin
 func TestHandleToolCall_WithStdin(t *testing.T) {
 	if runtime.GOOS == windowsOS {
@@ -426,6 +537,471 @@ echo "received: $input"
 	assert.Contains(t, textContent.Text, "received: test input")
 }
 
+// TestHandleToolCall_ToolExitsBeforeReadingStdin tests that a tool that reads only the first line
+// of a large stdin and exits is judged by its exit code, not by the failed write of the rest
+func TestHandleToolCall_ToolExitsBeforeReadingStdin(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	srv := NewOrlaServer(createTestConfig(t), "")
+
+	toolPath := filepath.Join(t.TempDir(), "first-line.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(toolPath, []byte("#!/bin/sh\nread line\necho \"first: $line\"\nexit \"$EXIT_CODE\"\n"), 0755))
+	tool := &core.ToolManifest{
+		Name:        "first-line",
+		Description: "Reads one line",
+		Path:        toolPath,
+		Interpreter: "/bin/sh",
+		Runtime:     &core.RuntimeConfig{Env: map[string]string{"EXIT_CODE": "0"}},
+	}
+
+	// Far more stdin than fits in a pipe buffer, so the tool exits while it is still being written
+	input := map[string]any{"stdin": "hello\n" + strings.Repeat("unread line\n", 1<<18)}
+
+	result, output, err := srv.handleToolCall(context.Background(), tool, input)
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, 0, output["exit_code"])
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Equal(t, "first: hello\n", textContent.Text)
+
+	// A failing exit code is still reported as a failure
+	tool.Runtime.Env["EXIT_CODE"] = "3"
+	result, output, err = srv.handleToolCall(context.Background(), tool, input)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, 3, output["exit_code"])
+}
+
+// createEchoStdinTool creates a tool that echoes its stdin back to stdout
+func createEchoStdinTool(t *testing.T) *core.ToolManifest {
+	t.Helper()
+	toolPath := filepath.Join(t.TempDir(), "echo-stdin.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(toolPath, []byte("#!/bin/sh\ncat\n"), 0755))
+
+	return &core.ToolManifest{
+		Name:        "echo-stdin",
+		Description: "Echoes stdin",
+		Path:        toolPath,
+		Interpreter: "/bin/sh",
+	}
+}
+
+// TestHandleToolCall_WithStdinFile tests that stdin_file streams a file's contents to the tool's stdin
+func TestHandleToolCall_WithStdinFile(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+	require.NotNil(t, srv)
+	tool := createEchoStdinTool(t)
+
+	dataDir := t.TempDir()
+	tool.StdinFileRoot = dataDir
+	contents := strings.Repeat("line of piped input\n", 1000)
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "input.txt"), []byte(contents), 0644))
+	outsideFile := filepath.Join(t.TempDir(), "secret.txt")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(outsideFile, []byte("secret"), 0644))
+
+	t.Run("absolute path", func(t *testing.T) {
+		result, _, err := srv.handleToolCall(context.Background(), tool, map[string]any{
+			"stdin_file": filepath.Join(dataDir, "input.txt"),
+		})
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		textContent, ok := result.Content[0].(*mcp.TextContent)
+		require.True(t, ok, "First content should be TextContent")
+		assert.Equal(t, contents, textContent.Text)
+	})
+
+	t.Run("relative path resolves against the tool's working directory", func(t *testing.T) {
+		relTool := *tool
+		relTool.WorkingDir = dataDir
+
+		result, _, err := srv.handleToolCall(context.Background(), &relTool, map[string]any{
+			"stdin_file": "input.txt",
+		})
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		textContent, ok := result.Content[0].(*mcp.TextContent)
+		require.True(t, ok, "First content should be TextContent")
+		assert.Equal(t, contents, textContent.Text)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		result, _, err := srv.handleToolCall(context.Background(), tool, map[string]any{
+			"stdin_file": filepath.Join(dataDir, "missing.txt"),
+		})
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+		textContent, ok := result.Content[0].(*mcp.TextContent)
+		require.True(t, ok, "First content should be TextContent")
+		assert.Contains(t, textContent.Text, "failed to open stdin_file")
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		result, _, err := srv.handleToolCall(context.Background(), tool, map[string]any{
+			"stdin_file": dataDir,
+		})
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+		textContent, ok := result.Content[0].(*mcp.TextContent)
+		require.True(t, ok, "First content should be TextContent")
+		assert.Contains(t, textContent.Text, "must be a regular file")
+	})
+
+	t.Run("outside stdin_file_root", func(t *testing.T) {
+		for _, path := range []string{outsideFile, filepath.Join(dataDir, "..", filepath.Base(filepath.Dir(outsideFile)), "secret.txt")} {
+			result, _, err := srv.handleToolCall(context.Background(), tool, map[string]any{
+				"stdin_file": path,
+			})
+			require.NoError(t, err)
+			require.True(t, result.IsError)
+			textContent, ok := result.Content[0].(*mcp.TextContent)
+			require.True(t, ok, "First content should be TextContent")
+			assert.Contains(t, textContent.Text, "is outside the tool's stdin_file_root")
+		}
+	})
+
+	t.Run("symlink out of stdin_file_root", func(t *testing.T) {
+		linkPath := filepath.Join(dataDir, "link.txt")
+		require.NoError(t, os.Symlink(outsideFile, linkPath))
+
+		result, _, err := srv.handleToolCall(context.Background(), tool, map[string]any{
+			"stdin_file": linkPath,
+		})
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+		textContent, ok := result.Content[0].(*mcp.TextContent)
+		require.True(t, ok, "First content should be TextContent")
+		assert.Contains(t, textContent.Text, "failed to open stdin_file")
+	})
+
+	t.Run("tool without stdin_file_root", func(t *testing.T) {
+		result, _, err := srv.handleToolCall(context.Background(), createEchoStdinTool(t), map[string]any{
+			"stdin_file": filepath.Join(dataDir, "input.txt"),
+		})
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+		textContent, ok := result.Content[0].(*mcp.TextContent)
+		require.True(t, ok, "First content should be TextContent")
+		assert.Contains(t, textContent.Text, "tool 'echo-stdin' does not accept stdin_file")
+	})
+}
+
+// TestHandleToolCall_NonStringStdin tests that a stdin argument that is not a string is ignored
+func TestHandleToolCall_NonStringStdin(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+	require.NotNil(t, srv)
+	tool := createEchoStdinTool(t)
+
+	result, _, err := srv.handleToolCall(context.Background(), tool, map[string]any{"stdin": 42})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok, "First content should be TextContent")
+	assert.Empty(t, textContent.Text)
+}
+
+// TestHandleToolCall_StdinArgsMutuallyExclusive tests that only one stdin source may be given
+func TestHandleToolCall_StdinArgsMutuallyExclusive(t *testing.T) {
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+	require.NotNil(t, srv)
+	tool := createEchoStdinTool(t)
+
+	result, _, err := srv.handleToolCall(context.Background(), tool, map[string]any{
+		"stdin":      "inline",
+		"stdin_file": "/tmp/input.txt",
+	})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok, "First content should be TextContent")
+	assert.Contains(t, textContent.Text, "only one of stdin and stdin_file may be provided")
+}
+
+// TestHandleToolCall_MaxInputBytes tests that input over the tool's max_input_bytes is rejected before execution
+func TestHandleToolCall_MaxInputBytes(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+	require.NotNil(t, srv)
+	tool := createEchoStdinTool(t)
+	tool.MaxInputBytes = 10
+
+	t.Run("just under the limit", func(t *testing.T) {
+		// 4 bytes of flag values and 6 bytes of stdin
+		result, _, err := srv.handleToolCall(context.Background(), tool, map[string]any{
+			"mode":  "fast",
+			"stdin": "abcdef",
+		})
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		textContent, ok := result.Content[0].(*mcp.TextContent)
+		require.True(t, ok, "First content should be TextContent")
+		assert.Equal(t, "abcdef", textContent.Text)
+	})
+
+	t.Run("over the limit", func(t *testing.T) {
+		result, output, err := srv.handleToolCall(context.Background(), tool, map[string]any{
+			"mode":  "fast",
+			"stdin": "abcdefg",
+		})
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+		assert.Nil(t, output, "the tool should not run")
+		textContent, ok := result.Content[0].(*mcp.TextContent)
+		require.True(t, ok, "First content should be TextContent")
+		assert.Contains(t, textContent.Text, "input of 11 bytes exceeds the limit of 10 bytes for tool 'echo-stdin' (max_input_bytes)")
+	})
+}
+
+// TestHandleToolCall_BareInterpreter tests that a bare interpreter name is looked up in PATH
+func TestHandleToolCall_BareInterpreter(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+	require.NotNil(t, srv)
+
+	t.Run("resolves", func(t *testing.T) {
+		tool := createEchoStdinTool(t)
+		tool.Interpreter = "sh"
+
+		result, _, err := srv.handleToolCall(context.Background(), tool, map[string]any{"stdin": "hello"})
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		textContent, ok := result.Content[0].(*mcp.TextContent)
+		require.True(t, ok, "First content should be TextContent")
+		assert.Equal(t, "hello", textContent.Text)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		tool := createEchoStdinTool(t)
+		tool.Interpreter = "orla-missing-interpreter"
+
+		result, _, err := srv.handleToolCall(context.Background(), tool, map[string]any{"stdin": "hello"})
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+		textContent, ok := result.Content[0].(*mcp.TextContent)
+		require.True(t, ok, "First content should be TextContent")
+		assert.Contains(t, textContent.Text, "Interpreter not found: interpreter 'orla-missing-interpreter' for tool 'echo-stdin' was not found in PATH")
+	})
+}
+
+// TestInputSize tests that every way of supplying stdin counts toward the input size
+func TestInputSize(t *testing.T) {
+	dataDir := t.TempDir()
+	inputFile := filepath.Join(dataDir, "input.txt")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(inputFile, []byte("0123456789"), 0644))
+	tool := &core.ToolManifest{Name: "sized", StdinFileRoot: dataDir}
+
+	assert.Equal(t, int64(0), inputSize(tool, map[string]any{}, ""))
+	assert.Equal(t, int64(7), inputSize(tool, map[string]any{"count": 42, "flag": "hello"}, ""))
+	assert.Equal(t, int64(5), inputSize(tool, map[string]any{"stdin": "hello"}, ""))
+	assert.Equal(t, int64(10), inputSize(tool, map[string]any{"stdin_file": inputFile}, ""))
+	assert.Equal(t, int64(10), inputSize(tool, map[string]any{"stdin_file": "input.txt"}, dataDir))
+	assert.Equal(t, int64(0), inputSize(tool, map[string]any{"stdin_file": filepath.Join(dataDir, "missing.txt")}, ""))
+	assert.Equal(t, int64(0), inputSize(&core.ToolManifest{Name: "closed"}, map[string]any{"stdin_file": inputFile}, ""))
+}
+
+// TestHandleToolCall_OutputAnnotations tests that manifest output annotations are attached to content items
+func TestHandleToolCall_OutputAnnotations(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+	require.NotNil(t, srv)
+
+	toolPath := filepath.Join(t.TempDir(), "annotated-tool.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(toolPath, []byte("#!/bin/sh\necho result\necho progress >&2\n"), 0755))
+
+	highPriority, lowPriority := 1.0, 0.1
+	tool := &core.ToolManifest{
+		Name:        "annotated-tool",
+		Description: "Annotated tool",
+		Path:        toolPath,
+		Interpreter: "/bin/sh",
+		MCP: &core.MCPConfig{
+			OutputAnnotations: &core.OutputAnnotationsConfig{
+				Stdout: &core.ContentAnnotation{
+					Audience: []core.ContentAnnotationAudience{core.ContentAnnotationAudienceUser, core.ContentAnnotationAudienceAssistant},
+					Priority: &highPriority,
+				},
+				Stderr: &core.ContentAnnotation{
+					Audience: []core.ContentAnnotationAudience{core.ContentAnnotationAudienceAssistant},
+					Priority: &lowPriority,
+				},
+			},
+		},
+	}
+
+	result, _, err := srv.handleToolCall(context.Background(), tool, map[string]any{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	require.Len(t, result.Content, 2)
+
+	stdoutContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Equal(t, "result\n", stdoutContent.Text)
+	require.NotNil(t, stdoutContent.Annotations)
+	assert.Equal(t, []mcp.Role{"user", "assistant"}, stdoutContent.Annotations.Audience)
+	assert.Equal(t, 1.0, stdoutContent.Annotations.Priority)
+
+	stderrContent, ok := result.Content[1].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Equal(t, "stderr: progress\n", stderrContent.Text)
+	require.NotNil(t, stderrContent.Annotations)
+	assert.Equal(t, []mcp.Role{"assistant"}, stderrContent.Annotations.Audience)
+	assert.Equal(t, 0.1, stderrContent.Annotations.Priority)
+}
+
+// TestHandleToolCall_ContentEnvelope tests that a tool can emit individually annotated content items
+func TestHandleToolCall_ContentEnvelope(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+	require.NotNil(t, srv)
+
+	envelope := `{"orla_content":[{"text":"summary","annotations":{"audience":["user"],"priority":0.9}},{"text":"details"}]}`
+	toolPath := filepath.Join(t.TempDir(), "envelope-tool.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(toolPath, []byte("#!/bin/sh\necho '"+envelope+"'\n"), 0755))
+
+	defaultPriority := 0.5
+	tool := &core.ToolManifest{
+		Name:        "envelope-tool",
+		Description: "Envelope tool",
+		Path:        toolPath,
+		Interpreter: "/bin/sh",
+		MCP: &core.MCPConfig{
+			OutputAnnotations: &core.OutputAnnotationsConfig{
+				Stdout: &core.ContentAnnotation{Priority: &defaultPriority},
+			},
+		},
+	}
+
+	result, _, err := srv.handleToolCall(context.Background(), tool, map[string]any{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	require.Len(t, result.Content, 2)
+
+	summary, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Equal(t, "summary", summary.Text)
+	require.NotNil(t, summary.Annotations)
+	assert.Equal(t, []mcp.Role{"user"}, summary.Annotations.Audience)
+	assert.Equal(t, 0.9, summary.Annotations.Priority)
+
+	// Items without annotations inherit the manifest's stdout annotations
+	details, ok := result.Content[1].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Equal(t, "details", details.Text)
+	require.NotNil(t, details.Annotations)
+	assert.Equal(t, 0.5, details.Annotations.Priority)
+}
+
+// TestParseContentEnvelope_NotEnvelope tests that ordinary stdout is not treated as an envelope
+func TestParseContentEnvelope_NotEnvelope(t *testing.T) {
+	for _, stdout := range []string{
+		"plain text",
+		`{"result": "ok"}`,
+		`{"orla_content": "not a list"}`,
+		`{"orla_content": [{"annotations": {"priority": 1}}]}`,
+		`{"orla_content": [{"text": "x", "content_type": "not a media type"}]}`,
+	} {
+		_, ok := parseContentEnvelope(stdout, nil, "", "", 0)
+		assert.False(t, ok, stdout)
+	}
+}
+
+// TestHandleToolCall_ContentType tests that the declared content type is attached to the returned content
+func TestHandleToolCall_ContentType(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+	require.NotNil(t, srv)
+
+	toolPath := filepath.Join(t.TempDir(), "markdown-tool.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(toolPath, []byte("#!/bin/sh\necho '# Report'\necho progress >&2\n"), 0755))
+
+	tool := &core.ToolManifest{
+		Name:        "markdown-tool",
+		Description: "Markdown tool",
+		Path:        toolPath,
+		Interpreter: "/bin/sh",
+		MCP:         &core.MCPConfig{ContentType: "text/markdown"},
+	}
+
+	result, _, err := srv.handleToolCall(context.Background(), tool, map[string]any{})
+	require.NoError(t, err)
+	require.Len(t, result.Content, 2)
+
+	stdoutContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Equal(t, "text/markdown", stdoutContent.Meta[contentTypeMetaKey])
+
+	data, err := json.Marshal(stdoutContent)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"_meta":{"contentType":"text/markdown"}`)
+
+	// The content type describes stdout only
+	stderrContent, ok := result.Content[1].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Nil(t, stderrContent.Meta)
+
+	// Without a declared content type, output is plain text
+	tool.MCP = nil
+	result, _, err = srv.handleToolCall(context.Background(), tool, map[string]any{})
+	require.NoError(t, err)
+	stdoutContent, ok = result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Nil(t, stdoutContent.Meta)
+}
+
+// TestParseContentEnvelope_ContentType tests that envelope items declare or inherit a content type
+func TestParseContentEnvelope_ContentType(t *testing.T) {
+	stdout := `{"orla_content":[{"text":"{}","content_type":"application/json"},{"text":"**done**"},{"text":"raw","content_type":"text/plain"}]}`
+
+	content, ok := parseContentEnvelope(stdout, nil, "text/markdown", "", 0)
+	require.True(t, ok)
+	require.Len(t, content, 3)
+
+	assert.Equal(t, mcp.Meta{contentTypeMetaKey: "application/json"}, content[0].(*mcp.TextContent).Meta)
+	assert.Equal(t, mcp.Meta{contentTypeMetaKey: "text/markdown"}, content[1].(*mcp.TextContent).Meta)
+	assert.Nil(t, content[2].(*mcp.TextContent).Meta)
+}
+
 // TestHandleToolCall_Error tests tool execution with an error
 func TestHandleToolCall_Error(t *testing.T) {
 	if runtime.GOOS == windowsOS {
@@ -486,6 +1062,37 @@ func TestHandleToolCall_CommandNotFound(t *testing.T) {
 	assert.Contains(t, textContent.Text, "Tool execution failed")
 }
 
+// TestHandleToolCall_MaxOutputBytes tests that a tool writing more than max_output_bytes fails
+// with its output truncated
+func TestHandleToolCall_MaxOutputBytes(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	cfg.MaxOutputBytes = 64
+	floodPath := filepath.Join(cfg.ToolsDir, "flood-tool.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(floodPath, []byte("#!/bin/sh\nyes\n"), 0755))
+	require.NoError(t, cfg.ToolsRegistry.AddTool(&core.ToolManifest{Name: "flood-tool", Description: "A tool that never stops writing", Path: floodPath}))
+
+	srv := NewOrlaServer(cfg, "")
+	t.Cleanup(srv.Close)
+
+	result, err := srv.CallTool(context.Background(), "flood-tool", map[string]any{})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, textContent.Text, "stdout exceeded 64 bytes (max_output_bytes)")
+	assert.Contains(t, textContent.Text, "Truncated output:\n"+strings.Repeat("y\n", 32))
+
+	// Output under the limit is unaffected
+	result, err = srv.CallTool(context.Background(), "test-tool", map[string]any{})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+}
+
 // TestReload tests reloading configuration
 func TestReload(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -753,6 +1360,86 @@ func TestHandleToolCall_Timeout(t *testing.T) {
 	assert.Contains(t, textContent.Text, "timed out")
 }
 
+// TestHandleToolCall_ToolTimeoutLongerThanGlobal tests that a tool's timeout_seconds lets it run
+// past the server-wide timeout
+func TestHandleToolCall_ToolTimeoutLongerThanGlobal(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	cfg.Timeout = 1
+	srv := NewOrlaServer(cfg, "")
+	require.NotNil(t, srv)
+
+	toolPath := filepath.Join(t.TempDir(), "slow-tool.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(toolPath, []byte("#!/bin/sh\nsleep 1.5\necho done\n"), 0755))
+
+	tool := &core.ToolManifest{
+		Name:           "slow-tool",
+		Description:    "Slow tool",
+		Path:           toolPath,
+		Interpreter:    "/bin/sh",
+		TimeoutSeconds: 10,
+	}
+
+	result, _, err := srv.handleToolCall(context.Background(), tool, map[string]any{})
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.False(t, result.IsError, "tool should not hit the server-wide timeout: %v", result.Content)
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, textContent.Text, "done")
+}
+
+// TestHandleToolCall_ToolTimeoutShorterThanGlobal tests that a tool's timeout_seconds stops it
+// before the server-wide timeout, and that the error names the tool and its limit
+func TestHandleToolCall_ToolTimeoutShorterThanGlobal(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	cfg.Timeout = 30
+	srv := NewOrlaServer(cfg, "")
+	require.NotNil(t, srv)
+
+	// exec so that killing the tool on timeout also ends the sleep holding its output open
+	toolPath := filepath.Join(t.TempDir(), "fast-fail-tool.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(toolPath, []byte("#!/bin/sh\nexec sleep 5\n"), 0755))
+
+	tool := &core.ToolManifest{
+		Name:           "fast-fail-tool",
+		Description:    "Tool that should fail fast",
+		Path:           toolPath,
+		Interpreter:    "/bin/sh",
+		TimeoutSeconds: 1,
+	}
+
+	start := time.Now()
+	result, _, err := srv.handleToolCall(context.Background(), tool, map[string]any{})
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	require.NotNil(t, result)
+	assert.True(t, result.IsError)
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, textContent.Text, "Tool 'fast-fail-tool' timed out after 1 seconds")
+	assert.Contains(t, textContent.Text, "timeout_seconds")
+}
+
+func TestTimeoutErrorMessage(t *testing.T) {
+	msg := timeoutErrorMessage(&core.ToolManifest{Name: "global"}, 30*time.Second)
+	assert.Contains(t, msg, "Tool 'global' timed out after 30 seconds")
+	assert.Contains(t, msg, "server-wide timeout")
+
+	msg = timeoutErrorMessage(&core.ToolManifest{Name: "own", TimeoutSeconds: 5}, 5*time.Second)
+	assert.Contains(t, msg, "Tool 'own' timed out after 5 seconds")
+	assert.Contains(t, msg, "timeout_seconds")
+}
+
 // TestHandleToolCall_WithStderrAndExitCode tests tool execution with stderr and non-zero exit code
 func TestHandleToolCall_WithStderrAndExitCode(t *testing.T) {
 	if runtime.GOOS == windowsOS {
@@ -814,6 +1501,11 @@ func TestHandleToolCall_WithStderrAndExitCode(t *testing.T) {
 	}
 	assert.True(t, foundExitCode, "exit_code should be included in content")
 
+	// The exit code is also carried in the result's _meta
+	exitCode, ok := ResultExitCode(result)
+	assert.True(t, ok)
+	assert.Equal(t, 42, exitCode)
+
 	// Verify output structure
 	assert.Equal(t, "stdout message\n", output["stdout"])
 	assert.Contains(t, output["stderr"], "error message")
@@ -877,6 +1569,80 @@ echo '{"success":true,"count":5,"items":["a","b","c"]}'
 	assert.Nil(t, result.Content)
 }
 
+// TestHandleToolCall_WithOutputSchema_Required tests that output missing a required property of the
+// output schema is an error, and that output with all required properties is accepted
+func TestHandleToolCall_WithOutputSchema_Required(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	srv := NewOrlaServer(createTestConfig(t), "")
+	require.NotNil(t, srv)
+
+	outputSchema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"path":  map[string]any{"type": "string"},
+			"size":  map[string]any{"type": "integer"},
+			"mtime": map[string]any{"type": "string"},
+		},
+		"required": []any{"path", "size"},
+	}
+
+	createJSONTool := func(t *testing.T, stdout string) *core.ToolManifest {
+		t.Helper()
+		toolPath := filepath.Join(t.TempDir(), "stat-tool.sh")
+		// #nosec G306 -- test file permissions are acceptable for temporary test files
+		require.NoError(t, os.WriteFile(toolPath, []byte(fmt.Sprintf("#!/bin/sh\necho '%s'\n", stdout)), 0755))
+		return &core.ToolManifest{
+			Name:        "stat-tool",
+			Description: "Stat tool",
+			Path:        toolPath,
+			Interpreter: "/bin/sh",
+			MCP: &core.MCPConfig{
+				OutputSchema: outputSchema,
+			},
+		}
+	}
+
+	t.Run("missing required property", func(t *testing.T) {
+		tool := createJSONTool(t, `{"path":"/tmp/a","mtime":"2024-01-01"}`)
+
+		result, output, err := srv.handleToolCall(context.Background(), tool, map[string]any{})
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+		assert.Nil(t, output)
+
+		require.Len(t, result.Content, 1)
+		textContent, ok := result.Content[0].(*mcp.TextContent)
+		require.True(t, ok)
+		assert.Equal(t, "Tool output is missing required properties of the output schema: size", textContent.Text)
+	})
+
+	t.Run("required property with wrong type", func(t *testing.T) {
+		// The MCP SDK validates the rest of the output schema when it sends the result
+		cfg := createTestConfig(t)
+		srv := NewOrlaServer(cfg, "")
+		require.NoError(t, cfg.ToolsRegistry.AddTool(createJSONTool(t, `{"path":"/tmp/a","size":"large"}`)))
+		srv.rebuildServer()
+
+		_, err := connectTestClient(t, srv).CallTool(context.Background(), &mcp.CallToolParams{Name: "stat-tool"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "validating tool output")
+		assert.Contains(t, err.Error(), "size")
+	})
+
+	t.Run("output satisfies schema", func(t *testing.T) {
+		tool := createJSONTool(t, `{"path":"/tmp/a","size":42}`)
+
+		result, output, err := srv.handleToolCall(context.Background(), tool, map[string]any{})
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		assert.Equal(t, "/tmp/a", output["path"])
+		assert.Equal(t, float64(42), output["size"])
+	})
+}
+
 // TestHandleToolCall_WithOutputSchema_InvalidJSON tests tool with output schema but invalid JSON
 func TestHandleToolCall_WithOutputSchema_InvalidJSON(t *testing.T) {
 	if runtime.GOOS == windowsOS {
@@ -1027,6 +1793,77 @@ func TestHandleToolCall_WithOutputSchema_EmptyStdout(t *testing.T) {
 	assert.Contains(t, textContent.Text, "not valid JSON")
 }
 
+// TestCallTool_AppliesSchemaDefaults tests that input schema defaults are passed for omitted
+// arguments, by the MCP SDK for calls in a session and by CallTool for calls made outside one
+func TestCallTool_AppliesSchemaDefaults(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+	require.NotNil(t, srv)
+
+	// The tool echoes its arguments one per line
+	toolPath := filepath.Join(t.TempDir(), "args-tool.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(toolPath, []byte("#!/bin/sh\nfor arg in \"$@\"; do echo \"$arg\"; done\n"), 0755))
+
+	tool := &core.ToolManifest{
+		Name:        "args-tool",
+		Description: "Args tool",
+		Path:        toolPath,
+		Interpreter: "/bin/sh",
+		MCP: &core.MCPConfig{
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"format": map[string]any{"type": "string", "default": "json"},
+					"limit":  map[string]any{"type": "integer", "default": 10},
+					"query":  map[string]any{"type": "string"},
+				},
+			},
+		},
+	}
+	require.NoError(t, cfg.ToolsRegistry.AddTool(tool))
+	srv.rebuildServer()
+	session := connectTestClient(t, srv)
+
+	calls := map[string]func(input map[string]any) (*mcp.CallToolResult, error){
+		"session": func(input map[string]any) (*mcp.CallToolResult, error) {
+			return session.CallTool(context.Background(), &mcp.CallToolParams{Name: "args-tool", Arguments: input})
+		},
+		"CallTool": func(input map[string]any) (*mcp.CallToolResult, error) {
+			return srv.CallTool(context.Background(), "args-tool", input)
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			input := map[string]any{"query": "logs"}
+			result, err := call(input)
+			require.NoError(t, err)
+			require.False(t, result.IsError)
+			textContent, ok := result.Content[0].(*mcp.TextContent)
+			require.True(t, ok)
+			assert.Contains(t, textContent.Text, "--format\njson\n")
+			assert.Contains(t, textContent.Text, "--limit\n10\n")
+			assert.Contains(t, textContent.Text, "--query\nlogs\n")
+			// The caller's input is not modified
+			assert.Equal(t, map[string]any{"query": "logs"}, input)
+
+			// Provided arguments are kept, and properties without a default stay absent
+			result, err = call(map[string]any{"format": "yaml"})
+			require.NoError(t, err)
+			require.False(t, result.IsError)
+			textContent, ok = result.Content[0].(*mcp.TextContent)
+			require.True(t, ok)
+			assert.Contains(t, textContent.Text, "--format\nyaml\n")
+			assert.NotContains(t, textContent.Text, "json")
+			assert.NotContains(t, textContent.Text, "--query")
+		})
+	}
+}
+
 // TestHandleToolCall_UnderscoreToHyphen tests that argument names are converted from underscore to hyphen
 func TestHandleToolCall_UnderscoreToHyphen(t *testing.T) {
 	if runtime.GOOS == windowsOS {
@@ -1436,6 +2273,50 @@ func TestRebuildServer_Concurrent(t *testing.T) {
 	assert.NotNil(t, srv.httpHandler)
 }
 
+// TestReload_ConcurrentToolCalls tests that reloads and rebuilds running alongside tool calls and
+// admin requests do not race (run with -race)
+func TestReload_ConcurrentToolCalls(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	configPath := filepath.Join(t.TempDir(), "orla.yaml")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte("tools_dir: "+cfg.ToolsDir+"\ntimeout: 10\ntrace_tools: true\n"), 0644))
+
+	srv := NewOrlaServer(cfg, configPath)
+	t.Cleanup(srv.Close)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				assert.NoError(t, srv.Reload())
+				srv.rebuildServer()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				result, err := srv.CallTool(context.Background(), "test-tool", map[string]any{})
+				if assert.NoError(t, err) {
+					assert.False(t, result.IsError)
+				}
+				assert.NotNil(t, srv.AdminState())
+				assert.NotNil(t, srv.Capabilities())
+				assert.NotEmpty(t, srv.ToolsHash())
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, srv.config.Timeout)
+	assert.True(t, srv.Capabilities().TraceTools)
+}
+
 // TestNewOrlaServer_WithNilToolsRegistry tests server creation with nil ToolsRegistry
 // This should not panic but might cause issues - testing edge case
 func TestNewOrlaServer_WithNilToolsRegistry(t *testing.T) {
@@ -1569,7 +2450,7 @@ func TestRebuildServer_WithCapsuleMode(t *testing.T) {
 	})
 }
 
-// TestRebuildServer_StopsExistingCapsules tests that rebuilding stops existing capsules
+// TestRebuildServer_StopsExistingCapsules tests that rebuilding stops the capsules of changed tools
 func TestRebuildServer_StopsExistingCapsules(t *testing.T) {
 	if runtime.GOOS == windowsOS {
 		t.Skip("Windows capsule script tests not implemented")
@@ -1602,7 +2483,10 @@ func TestRebuildServer_StopsExistingCapsules(t *testing.T) {
 	require.True(t, ok1)
 	require.NotNil(t, capsule1)
 
-	// Second rebuild - should stop old capsule and start new one
+	// Second rebuild with a new version of the tool - should stop old capsule and start new one
+	updatedTool := *capsuleTool
+	updatedTool.Version = "1.1.0"
+	cfg.ToolsRegistry.Tools[capsuleTool.Name] = &updatedTool
 	srv.rebuildServer()
 
 	capsule2, ok2 := srv.capsules.Load("capsule-tool")
@@ -1611,6 +2495,7 @@ func TestRebuildServer_StopsExistingCapsules(t *testing.T) {
 
 	// Verify capsules are different instances (old one was stopped)
 	assert.NotEqual(t, capsule1, capsule2)
+	assert.Eventually(t, func() bool { return capsule1.GetState() == core.CapsuleStateStopped }, 5*time.Second, 10*time.Millisecond)
 
 	// Cleanup
 	srv.capsules.Range(func(_ string, cap *core.CapsuleManager) bool {
@@ -1619,8 +2504,98 @@ func TestRebuildServer_StopsExistingCapsules(t *testing.T) {
 	})
 }
 
-// TestHandleToolCall_CapsuleMode tests tool execution for capsule mode tools
-func TestHandleToolCall_CapsuleMode(t *testing.T) {
+// TestHandleToolCall_CapsuleMode tests tool execution for capsule mode tools
+func TestHandleToolCall_CapsuleMode(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+	require.NotNil(t, srv)
+
+	// Create a capsule mode tool
+	scriptPath := createRespondingCapsuleScript(t)
+	capsuleTool := &core.ToolManifest{
+		Name:        "capsule-tool",
+		Version:     "1.0.0",
+		Description: "A capsule mode tool",
+		Path:        scriptPath,
+		Runtime: &core.RuntimeConfig{
+			Mode:             core.RuntimeModeCapsule,
+			StartupTimeoutMs: 5000,
+		},
+	}
+
+	err := cfg.ToolsRegistry.AddTool(capsuleTool)
+	require.NoError(t, err)
+
+	// Rebuild to start the capsule
+	srv.rebuildServer()
+
+	// Wait a bit for capsule to be ready
+	time.Sleep(100 * time.Millisecond)
+
+	// Call the tool
+	result, output, err := srv.handleToolCall(context.Background(), capsuleTool, map[string]any{"arg1": "value1"})
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.False(t, result.IsError, "Capsule tool call should succeed")
+
+	// Verify output structure (buildToolResponse parses JSON from stdout)
+	require.NotNil(t, output)
+	assert.Contains(t, output, "stdout")
+	// The stdout should contain the JSON result
+	stdout, ok := output["stdout"].(string)
+	require.True(t, ok)
+	assert.Contains(t, stdout, "test result")
+
+	// Cleanup
+	srv.capsules.Range(func(_ string, cap *core.CapsuleManager) bool {
+		_ = cap.Stop() //nolint:errcheck // cleanup in test
+		return true
+	})
+}
+
+// createStreamingCapsuleScript creates a capsule that advertises streaming_input, acks each input
+// chunk, and responds with the total number of bytes received and whether a stdin argument leaked
+// into the tools/call arguments
+func createStreamingCapsuleScript(t *testing.T) string {
+	t.Helper()
+
+	scriptContent := `#!/bin/sh
+echo '{"jsonrpc":"2.0","method":"orla.hello","params":{"name":"stream-tool","version":"1.0.0","capabilities":["tools","streaming_input"]}}'
+
+while IFS= read -r line; do
+  case "$line" in
+    *'"method":"tools/call"'*)
+      REQ_ID=$(echo "$line" | sed -n 's/.*"id":\([0-9]*\).*/\1/p')
+      TOTAL=0
+      LEAKED=false
+      case "$line" in *'"stdin'*) LEAKED=true ;; esac
+      ;;
+    *'"method":"orla.input/chunk"'*)
+      SEQ=$(echo "$line" | sed -n 's/.*"seq":\([0-9]*\).*/\1/p')
+      DATA=$(echo "$line" | sed -n 's/.*"data":"\([^"]*\)".*/\1/p')
+      TOTAL=$((TOTAL + $(printf '%s' "$DATA" | base64 -d | wc -c)))
+      echo "{\"jsonrpc\":\"2.0\",\"method\":\"orla.input/ack\",\"params\":{\"request_id\":$REQ_ID,\"seq\":$SEQ}}"
+      ;;
+    *'"method":"orla.input/end"'*)
+      echo "{\"jsonrpc\":\"2.0\",\"id\":$REQ_ID,\"result\":{\"total_bytes\":$TOTAL,\"stdin_arg_leaked\":$LEAKED}}"
+      ;;
+  esac
+done
+`
+
+	scriptFile := filepath.Join(t.TempDir(), "streaming-capsule.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(scriptFile, []byte(scriptContent), 0755))
+	return scriptFile
+}
+
+// TestHandleToolCall_CapsuleMode_StreamingInput tests that stdin arguments are streamed to
+// capsules that advertise streaming_input rather than passed as arguments
+func TestHandleToolCall_CapsuleMode_StreamingInput(t *testing.T) {
 	if runtime.GOOS == windowsOS {
 		t.Skip("Windows capsule script tests not implemented")
 	}
@@ -1629,47 +2604,65 @@ func TestHandleToolCall_CapsuleMode(t *testing.T) {
 	srv := NewOrlaServer(cfg, "")
 	require.NotNil(t, srv)
 
-	// Create a capsule mode tool
-	scriptPath := createRespondingCapsuleScript(t)
 	capsuleTool := &core.ToolManifest{
-		Name:        "capsule-tool",
+		Name:        "stream-tool",
 		Version:     "1.0.0",
-		Description: "A capsule mode tool",
-		Path:        scriptPath,
+		Description: "A capsule that accepts streamed input",
+		Path:        createStreamingCapsuleScript(t),
 		Runtime: &core.RuntimeConfig{
 			Mode:             core.RuntimeModeCapsule,
 			StartupTimeoutMs: 5000,
 		},
 	}
-
-	err := cfg.ToolsRegistry.AddTool(capsuleTool)
-	require.NoError(t, err)
-
-	// Rebuild to start the capsule
+	capsuleTool.StdinFileRoot = filepath.Dir(capsuleTool.Path)
+	require.NoError(t, cfg.ToolsRegistry.AddTool(capsuleTool))
 	srv.rebuildServer()
-
-	// Wait a bit for capsule to be ready
-	time.Sleep(100 * time.Millisecond)
-
-	// Call the tool
-	result, output, err := srv.handleToolCall(context.Background(), capsuleTool, map[string]any{"arg1": "value1"})
-	require.NoError(t, err)
-	require.NotNil(t, result)
-	assert.False(t, result.IsError, "Capsule tool call should succeed")
-
-	// Verify output structure (buildToolResponse parses JSON from stdout)
-	require.NotNil(t, output)
-	assert.Contains(t, output, "stdout")
-	// The stdout should contain the JSON result
-	stdout, ok := output["stdout"].(string)
-	require.True(t, ok)
-	assert.Contains(t, stdout, "test result")
-
-	// Cleanup
-	srv.capsules.Range(func(_ string, cap *core.CapsuleManager) bool {
+	defer srv.capsules.Range(func(_ string, cap *core.CapsuleManager) bool {
 		_ = cap.Stop() //nolint:errcheck // cleanup in test
 		return true
 	})
+
+	// Spans several chunks and more than one window
+	content := bytes.Repeat([]byte("0123456789"), 50_000)
+	// A relative stdin_file resolves against the capsule's directory
+	require.NoError(t, os.WriteFile(filepath.Join(capsuleTool.StdinFileRoot, "input.bin"), content, 0600))
+	inputFile := "input.bin"
+
+	tests := []struct {
+		name      string
+		input     map[string]any
+		wantBytes int
+	}{
+		{name: "stdin_file", input: map[string]any{"stdin_file": inputFile}, wantBytes: len(content)},
+		{name: "stdin", input: map[string]any{"stdin": "hello"}, wantBytes: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, output, err := srv.handleToolCall(context.Background(), capsuleTool, tt.input)
+			require.NoError(t, err)
+			require.NotNil(t, result)
+			require.False(t, result.IsError)
+
+			stdout, ok := output["stdout"].(string)
+			require.True(t, ok)
+			var parsed map[string]any
+			require.NoError(t, json.Unmarshal([]byte(stdout), &parsed))
+			assert.Equal(t, float64(tt.wantBytes), parsed["total_bytes"])
+			assert.Equal(t, false, parsed["stdin_arg_leaked"])
+		})
+	}
+
+	// Invalid stdin arguments are reported without calling the capsule
+	result, _, err := srv.handleToolCall(context.Background(), capsuleTool, map[string]any{
+		"stdin":      "a",
+		"stdin_file": inputFile,
+	})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, textContent.Text, "Invalid stdin")
 }
 
 // TestHandleToolCall_CapsuleMode_NotRunning tests handling when capsule is not running
@@ -1800,6 +2793,202 @@ func TestHandleCapsuleToolCall_CallToolError(t *testing.T) {
 	assert.Nil(t, output)
 }
 
+// TestHandleCapsuleToolCall_RestartsUnhealthyCapsule tests that a capsule that stopped answering
+// health checks is restarted before the next call is sent to it
+func TestHandleCapsuleToolCall_RestartsUnhealthyCapsule(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+	require.NotNil(t, srv)
+	t.Cleanup(srv.Close)
+
+	// The first run of the capsule answers one request and then wedges, later runs keep answering
+	tmpDir := t.TempDir()
+	scriptContent := `#!/bin/sh
+echo '{"jsonrpc":"2.0","method":"orla.hello","params":{"name":"capsule-tool","version":"1.0.0","capabilities":["tools"]}}'
+
+WEDGE=1
+if [ -f started ]; then WEDGE=0; fi
+touch started
+
+while IFS= read -r line; do
+  REQ_ID=$(echo "$line" | sed -n 's/.*"id":\([0-9]*\).*/\1/p')
+  echo "{\"jsonrpc\":\"2.0\",\"id\":$REQ_ID,\"result\":{\"output\":\"test result\"}}"
+  if [ "$WEDGE" = 1 ]; then exec sleep 1000; fi
+done
+`
+	scriptPath := filepath.Join(tmpDir, "wedging-capsule.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(scriptPath, []byte(scriptContent), 0755))
+
+	capsuleTool := &core.ToolManifest{
+		Name:        "capsule-tool",
+		Version:     "1.0.0",
+		Description: "A capsule mode tool",
+		Path:        scriptPath,
+		Runtime: &core.RuntimeConfig{
+			Mode:                  core.RuntimeModeCapsule,
+			StartupTimeoutMs:      5000,
+			HealthCheckIntervalMs: 50,
+			HealthCheckFailures:   2,
+		},
+	}
+	require.NoError(t, cfg.ToolsRegistry.AddTool(capsuleTool))
+	srv.rebuildServer()
+
+	wedged, ok := srv.capsules.Load("capsule-tool")
+	require.True(t, ok)
+	require.Eventually(t, func() bool { return !wedged.IsHealthy() }, 5*time.Second, 10*time.Millisecond)
+
+	result, _, err := srv.handleCapsuleToolCall(context.Background(), capsuleTool, map[string]any{})
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.False(t, result.IsError)
+
+	restarted, ok := srv.capsules.Load("capsule-tool")
+	require.True(t, ok)
+	assert.NotSame(t, wedged, restarted)
+	assert.True(t, restarted.IsHealthy())
+}
+
+// createCrashingCapsuleScript creates a capsule script whose first run exits after serving one
+// request, while later runs keep serving requests
+func createCrashingCapsuleScript(t *testing.T) string {
+	t.Helper()
+
+	scriptContent := `#!/bin/sh
+echo '{"jsonrpc":"2.0","method":"orla.hello","params":{"name":"capsule-tool","version":"1.0.0","capabilities":["tools"]}}'
+
+CRASH=1
+if [ -f started ]; then CRASH=0; fi
+touch started
+
+while IFS= read -r line; do
+  REQ_ID=$(echo "$line" | sed -n 's/.*"id":\([0-9]*\).*/\1/p')
+  echo "{\"jsonrpc\":\"2.0\",\"id\":$REQ_ID,\"result\":{\"output\":\"test result\"}}"
+  if [ "$CRASH" = 1 ]; then exit 1; fi
+done
+`
+	scriptPath := filepath.Join(t.TempDir(), "crashing-capsule.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(scriptPath, []byte(scriptContent), 0755))
+	return scriptPath
+}
+
+// TestHandleCapsuleToolCall_RestartsCrashedCapsule tests that a capsule whose process exits
+// unexpectedly is restarted, and serves calls again
+func TestHandleCapsuleToolCall_RestartsCrashedCapsule(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+	require.NotNil(t, srv)
+	t.Cleanup(srv.Close)
+
+	capsuleTool := &core.ToolManifest{
+		Name:        "capsule-tool",
+		Version:     "1.0.0",
+		Description: "A capsule mode tool",
+		Path:        createCrashingCapsuleScript(t),
+		Runtime: &core.RuntimeConfig{
+			Mode:             core.RuntimeModeCapsule,
+			StartupTimeoutMs: 5000,
+		},
+	}
+	require.NoError(t, cfg.ToolsRegistry.AddTool(capsuleTool))
+	srv.rebuildServer()
+
+	crashing, ok := srv.capsules.Load("capsule-tool")
+	require.True(t, ok)
+
+	// The first call is served, then the process exits
+	result, _, err := srv.handleCapsuleToolCall(context.Background(), capsuleTool, map[string]any{})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+
+	select {
+	case <-crashing.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("capsule process did not exit")
+	}
+	assert.True(t, crashing.Crashed())
+
+	require.Eventually(t, func() bool {
+		current, ok := srv.capsules.Load("capsule-tool")
+		return ok && current != crashing && current.IsReady()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	result, _, err = srv.handleCapsuleToolCall(context.Background(), capsuleTool, map[string]any{})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.True(t, srv.registeredTools.Contains("capsule-tool"))
+}
+
+// TestSuperviseCapsule_RestartsExhausted tests that a tool whose capsule keeps exiting is removed
+// once its restarts are exhausted
+func TestSuperviseCapsule_RestartsExhausted(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Windows capsule script tests not implemented")
+	}
+
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+	require.NotNil(t, srv)
+	t.Cleanup(srv.Close)
+
+	// The capsule exits as soon as it receives a request, on every run
+	scriptPath := filepath.Join(t.TempDir(), "exiting-capsule.sh")
+	scriptContent := `#!/bin/sh
+echo '{"jsonrpc":"2.0","method":"orla.hello","params":{"name":"capsule-tool","version":"1.0.0","capabilities":["tools"]}}'
+read -r line
+exit 1
+`
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(scriptPath, []byte(scriptContent), 0755))
+
+	capsuleTool := &core.ToolManifest{
+		Name:        "capsule-tool",
+		Version:     "1.0.0",
+		Description: "A capsule mode tool",
+		Path:        scriptPath,
+		Runtime: &core.RuntimeConfig{
+			Mode:             core.RuntimeModeCapsule,
+			StartupTimeoutMs: 5000,
+			MaxRestarts:      1,
+		},
+	}
+	require.NoError(t, cfg.ToolsRegistry.AddTool(capsuleTool))
+	srv.rebuildServer()
+	require.True(t, srv.registeredTools.Contains("capsule-tool"))
+
+	// Each call makes the current capsule exit
+	crash := func() *core.CapsuleManager {
+		capsule, ok := srv.capsules.Load("capsule-tool")
+		require.True(t, ok)
+		_, _, _ = srv.handleCapsuleToolCall(context.Background(), capsuleTool, map[string]any{}) //nolint:errcheck // the call fails as the capsule exits
+		<-capsule.Done()
+		return capsule
+	}
+
+	first := crash()
+	require.Eventually(t, func() bool {
+		current, ok := srv.capsules.Load("capsule-tool")
+		return ok && current != first && current.IsReady()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	crash()
+	require.Eventually(t, func() bool {
+		return !srv.registeredTools.Contains("capsule-tool")
+	}, 5*time.Second, 10*time.Millisecond)
+	_, ok := srv.capsules.Load("capsule-tool")
+	assert.False(t, ok)
+}
+
 // createErrorCapsuleScript creates a capsule script that returns JSON-RPC errors
 func createErrorCapsuleScript(t *testing.T) string {
 	t.Helper()
@@ -1865,3 +3054,268 @@ func TestRebuildServer_CapsuleFailsToStart(t *testing.T) {
 
 	assert.False(t, srv.registeredTools.Contains("failing-capsule-tool"), "Tool should not be registered")
 }
+
+// TestRebuildServer_SkipsToolsRequiringNewerOrla tests that tools whose min_orla_version is newer
+// than the running orla are not registered
+func TestRebuildServer_SkipsToolsRequiringNewerOrla(t *testing.T) {
+	original := core.OrlaVersion()
+	core.SetOrlaVersion("0.3.0")
+	t.Cleanup(func() { core.SetOrlaVersion(original) })
+
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+	require.NotNil(t, srv)
+
+	for name, minOrlaVersion := range map[string]string{
+		"compatible-tool": "0.3.0",
+		"future-tool":     "2.0.0",
+	} {
+		require.NoError(t, cfg.ToolsRegistry.AddTool(&core.ToolManifest{
+			Name:           name,
+			Description:    "A versioned tool",
+			Path:           "/bin/true",
+			MinOrlaVersion: minOrlaVersion,
+		}))
+	}
+
+	srv.rebuildServer()
+
+	assert.True(t, srv.registeredTools.Contains("compatible-tool"))
+	assert.False(t, srv.registeredTools.Contains("future-tool"), "Tool requiring a newer orla should not be registered")
+}
+
+// TestToolCall_VirtualTool tests that a tool defined in config by an inline command is registered
+// and runs the command with the call's arguments
+func TestToolCall_VirtualTool(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	configPath := filepath.Join(t.TempDir(), "orla.yaml")
+	configContent := `
+tools_registry:
+  tools:
+    greet:
+      name: greet
+      description: Greets someone
+      command: echo "hello, $2 from $0"
+      mcp:
+        input_schema:
+          type: object
+          properties:
+            name:
+              type: string
+          required: [name]
+`
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	cfg, err := config.LoadConfig(configPath)
+	require.NoError(t, err)
+	srv := NewOrlaServer(cfg, configPath)
+	require.NotNil(t, srv)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.orlaMCPserver.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError(serverSession.Close) })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError(clientSession.Close) })
+
+	result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "greet",
+		Arguments: map[string]any{"name": "orla"},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok, "First content should be TextContent")
+	assert.Equal(t, "hello, orla from greet\n", textContent.Text)
+}
+
+// TestToolCall_PackageTool tests that a tool that runs a package is run by its package manager,
+// and that a tool whose package manager is not installed is not registered
+func TestToolCall_PackageTool(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	// A fake npx that prints the arguments it is run with
+	binDir := t.TempDir()
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "npx"), []byte("#!/bin/sh\necho npx \"$@\"\n"), 0755))
+
+	configPath := filepath.Join(t.TempDir(), "orla.yaml")
+	configContent := fmt.Sprintf(`
+tools_registry:
+  tools:
+    cowsay:
+      name: cowsay
+      description: Says things
+      runtime:
+        mode: npx
+        package: cowsay
+        args: [moo]
+        env:
+          PATH: %s
+    black:
+      name: black
+      description: Formats Python
+      runtime:
+        mode: pipx
+        package: black
+        env:
+          PATH: /nonexistent
+`, binDir)
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	cfg, err := config.LoadConfig(configPath)
+	require.NoError(t, err)
+	srv := NewOrlaServer(cfg, configPath)
+	require.NotNil(t, srv)
+
+	assert.True(t, srv.registeredTools.Contains("cowsay"))
+	assert.False(t, srv.registeredTools.Contains("black"), "Tool without its package manager should not be registered")
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.orlaMCPserver.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError(serverSession.Close) })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError(clientSession.Close) })
+
+	result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "cowsay"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok, "First content should be TextContent")
+	assert.Equal(t, "npx -y cowsay moo\n", textContent.Text)
+}
+
+// addPersistentTestTool adds a persistent-mode tool to the config's registry. For each call it
+// prints its process ID and how many calls it has served, and it never answers a call whose
+// input contains "hang".
+func addPersistentTestTool(t *testing.T, cfg *config.OrlaConfig) *core.ToolManifest {
+	t.Helper()
+
+	toolPath := filepath.Join(t.TempDir(), "counter.sh")
+	script := `#!/bin/sh
+count=0
+while IFS= read -r line; do
+  count=$((count + 1))
+  case "$line" in *hang*) continue ;; esac
+  echo "pid=$$ call=$count"
+  case "$line" in *fail*) echo "ORLA_END 1" ;; *) echo "ORLA_END" ;; esac
+done
+`
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(toolPath, []byte(script), 0755))
+
+	tool := &core.ToolManifest{
+		Name:        "counter",
+		Description: "Counts its calls",
+		Path:        toolPath,
+		Interpreter: "/bin/sh",
+		Runtime:     &core.RuntimeConfig{Mode: core.RuntimeModePersistent},
+	}
+	require.NoError(t, cfg.ToolsRegistry.AddTool(tool))
+	return tool
+}
+
+func TestHandleToolCall_PersistentTool(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	addPersistentTestTool(t, cfg)
+	srv := NewOrlaServer(cfg, "")
+	t.Cleanup(srv.Close)
+
+	persistent, ok := srv.persistents.Load("counter")
+	require.True(t, ok)
+	assert.False(t, persistent.IsRunning(), "the process is started by the first call")
+
+	// Sequential calls are all served by one process
+	var pid string
+	for i := 1; i <= 3; i++ {
+		result, err := srv.CallTool(context.Background(), "counter", map[string]any{})
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		textContent, ok := result.Content[0].(*mcp.TextContent)
+		require.True(t, ok)
+
+		callPID, call, found := strings.Cut(strings.TrimSpace(textContent.Text), " ")
+		require.True(t, found, "unexpected output %q", textContent.Text)
+		assert.Equal(t, fmt.Sprintf("call=%d", i), call)
+		if pid == "" {
+			pid = callPID
+		}
+		assert.Equal(t, pid, callPID)
+	}
+	assert.True(t, persistent.IsRunning())
+
+	// A non-zero code on the end line fails the call
+	result, err := srv.CallTool(context.Background(), "counter", map[string]any{"mode": "fail"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	// Rebuilding stops the process
+	srv.rebuildServer()
+	assert.False(t, persistent.IsRunning())
+}
+
+func TestHandleToolCall_PersistentToolTimeout(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	tool := addPersistentTestTool(t, cfg)
+	tool.TimeoutSeconds = 1
+	srv := NewOrlaServer(cfg, "")
+	t.Cleanup(srv.Close)
+
+	result, err := srv.CallTool(context.Background(), "counter", map[string]any{"mode": "hang"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, textContent.Text, "Tool 'counter' timed out after 1 seconds")
+
+	// The next call gets a fresh process
+	result, err = srv.CallTool(context.Background(), "counter", map[string]any{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	textContent, ok = result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, textContent.Text, "call=1")
+}
+
+func TestResultExitCode(t *testing.T) {
+	_, ok := ResultExitCode(nil)
+	assert.False(t, ok)
+
+	_, ok = ResultExitCode(&mcp.CallToolResult{})
+	assert.False(t, ok)
+
+	exitCode, ok := ResultExitCode(&mcp.CallToolResult{Meta: mcp.Meta{ExitCodeMetaKey: 3}})
+	assert.True(t, ok)
+	assert.Equal(t, 3, exitCode)
+
+	// Results decoded from JSON carry the exit code as a float64
+	exitCode, ok = ResultExitCode(&mcp.CallToolResult{Meta: mcp.Meta{ExitCodeMetaKey: float64(7)}})
+	assert.True(t, ok)
+	assert.Equal(t, 7, exitCode)
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// createStabilityTestConfig creates a test config where alpha is stable, beta is experimental,
+// and gamma is deprecated
+func createStabilityTestConfig(t *testing.T) *config.OrlaConfig {
+	t.Helper()
+
+	cfg := createFilterTestConfig(t)
+	for name, stability := range map[string]core.ToolStability{
+		"alpha": "",
+		"beta":  core.ToolStabilityExperimental,
+		"gamma": core.ToolStabilityDeprecated,
+	} {
+		tool, err := cfg.ToolsRegistry.GetTool(name)
+		require.NoError(t, err)
+		tool.Description = "The " + name + " tool"
+		tool.Stability = stability
+	}
+	return cfg
+}
+
+// listToolsByName lists the tools registered with srv, keyed by name
+func listToolsByName(t *testing.T, srv *OrlaServer) map[string]*mcp.Tool {
+	t.Helper()
+
+	result, err := connectTestClient(t, srv).ListTools(context.Background(), nil)
+	require.NoError(t, err)
+
+	tools := make(map[string]*mcp.Tool, len(result.Tools))
+	for _, tool := range result.Tools {
+		tools[tool.Name] = tool
+	}
+	return tools
+}
+
+func TestRegisterTool_Stability(t *testing.T) {
+	tools := listToolsByName(t, NewOrlaServer(createStabilityTestConfig(t), ""))
+	require.Len(t, tools, 3)
+
+	assert.Equal(t, "The alpha tool", tools["alpha"].Description)
+	assert.Equal(t, "[experimental] The beta tool", tools["beta"].Description)
+	assert.Equal(t, "[deprecated] The gamma tool", tools["gamma"].Description)
+
+	assert.Equal(t, "stable", tools["alpha"].Meta[StabilityMetaKey])
+	assert.Equal(t, "experimental", tools["beta"].Meta[StabilityMetaKey])
+	assert.Equal(t, "deprecated", tools["gamma"].Meta[StabilityMetaKey])
+}
+
+func TestRebuildServer_HideDeprecatedTools(t *testing.T) {
+	cfg := createStabilityTestConfig(t)
+	cfg.HideDeprecatedTools = true
+	srv := NewOrlaServer(cfg, "")
+
+	tools := listToolsByName(t, srv)
+	assert.Contains(t, tools, "alpha")
+	assert.Contains(t, tools, "beta", "experimental tools are not hidden")
+	assert.NotContains(t, tools, "gamma")
+	assert.False(t, srv.registeredTools.Contains("gamma"))
+}
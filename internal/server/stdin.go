@@ -0,0 +1,177 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+const (
+	// stdinArgKey is the tool argument whose string value is passed to the tool's stdin
+	stdinArgKey = "stdin"
+	// stdinFileArgKey is the tool argument naming a file whose contents are streamed to the tool's stdin
+	stdinFileArgKey = "stdin_file"
+	// maxStdinBytes is the maximum size of stdin supplied through stdin_file
+	maxStdinBytes = 64 << 20
+)
+
+// isStdinArg reports whether the tool argument key supplies stdin rather than a command-line flag
+func isStdinArg(key string) bool {
+	return key == stdinArgKey || key == stdinFileArgKey
+}
+
+// hasStdinArg reports whether any of the tool arguments supply stdin
+func hasStdinArg(input map[string]any) bool {
+	for key := range input {
+		if isStdinArg(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutStdinArgs returns a copy of the tool arguments without the ones that supply stdin
+func withoutStdinArgs(input map[string]any) map[string]any {
+	arguments := make(map[string]any, len(input))
+	for key, value := range input {
+		if !isStdinArg(key) {
+			arguments[key] = value
+		}
+	}
+	return arguments
+}
+
+// resolveCallStdin builds the stdin reader for a simple mode tool call. A tool with the json-stdin
+// arg_style reads its whole input as a JSON object from stdin instead of its command line. Other
+// tools get their arguments on the command line and stdin in their stdin_format: from the stdin
+// arguments (raw, see resolveToolStdin), as a copy of the other arguments in a JSON object
+// (json-args), or not at all (none). Tools with stdin_format none reject the stdin arguments. dir
+// is the directory the tool runs in. The returned close function must always be called.
+func resolveCallStdin(tool *core.ToolManifest, input map[string]any, dir string) (io.Reader, func(), error) {
+	noop := func() {}
+	if core.ArgStyleOf(tool) == core.ArgStyleJSONStdin {
+		if hasStdinArg(input) {
+			return nil, noop, fmt.Errorf("tools with arg_style %s receive their input on stdin, %s and %s are not supported",
+				core.ArgStyleJSONStdin, stdinArgKey, stdinFileArgKey)
+		}
+		return jsonStdin(input)
+	}
+
+	switch format := core.StdinFormatOf(tool); format {
+	case core.StdinFormatJSONArgs:
+		// The stdin arguments are not passed on the command line either, so they are dropped
+		return jsonStdin(withoutStdinArgs(input))
+	case core.StdinFormatNone:
+		if hasStdinArg(input) {
+			return nil, noop, fmt.Errorf("tools with stdin_format %s do not read the stdin arguments, %s and %s are not supported",
+				format, stdinArgKey, stdinFileArgKey)
+		}
+		// Without a reader the tool's stdin is the null device, which is at its end at once
+		return nil, noop, nil
+	default:
+		return resolveToolStdin(tool, input, dir)
+	}
+}
+
+// jsonStdin returns a stdin reader of input as a JSON object. The returned close function must
+// always be called.
+func jsonStdin(input map[string]any) (io.Reader, func(), error) {
+	noop := func() {}
+	if input == nil {
+		input = map[string]any{}
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, noop, fmt.Errorf("failed to encode input as JSON: %w", err)
+	}
+	return bytes.NewReader(data), noop, nil
+}
+
+// resolveToolStdin builds the stdin reader for a tool call from the stdin or stdin_file argument.
+// At most one of them may be given, and a stdin that is not a string is ignored. stdin_file is
+// only accepted for tools that set stdin_file_root, see openStdinFile. The returned close function
+// must always be called.
+func resolveToolStdin(tool *core.ToolManifest, input map[string]any, dir string) (io.Reader, func(), error) {
+	noop := func() {}
+
+	if _, ok := input[stdinFileArgKey]; ok {
+		if _, ok := input[stdinArgKey]; ok {
+			return nil, noop, fmt.Errorf("only one of %s and %s may be provided", stdinArgKey, stdinFileArgKey)
+		}
+		path, ok := input[stdinFileArgKey].(string)
+		if !ok {
+			return nil, noop, fmt.Errorf("%s must be a string, got %T", stdinFileArgKey, input[stdinFileArgKey])
+		}
+		file, err := openStdinFile(tool, path, dir)
+		if err != nil {
+			return nil, noop, err
+		}
+		// Guard against the file growing after the size check
+		return io.LimitReader(file, maxStdinBytes), func() { core.LogDeferredError(file.Close) }, nil
+	}
+
+	stdin, ok := input[stdinArgKey].(string)
+	if !ok || stdin == "" {
+		return nil, noop, nil
+	}
+	return strings.NewReader(stdin), noop, nil
+}
+
+// openStdinFile opens the regular file at path for streaming to the tool's stdin, enforcing
+// maxStdinBytes. A relative path is resolved against dir, the directory the tool runs in. The file
+// must be inside the tool's stdin_file_root and is opened through an os.Root, so that neither ..
+// nor symlinks lead out of it.
+func openStdinFile(tool *core.ToolManifest, path, dir string) (*os.File, error) {
+	if tool.StdinFileRoot == "" {
+		return nil, fmt.Errorf("tool '%s' does not accept %s, its tool.yaml must set stdin_file_root to allow it",
+			tool.Name, stdinFileArgKey)
+	}
+	if path == "" {
+		return nil, fmt.Errorf("%s cannot be empty", stdinFileArgKey)
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s path: %w", stdinFileArgKey, err)
+	}
+	relPath, err := filepath.Rel(tool.StdinFileRoot, absPath)
+	if err != nil || !filepath.IsLocal(relPath) {
+		return nil, fmt.Errorf("%s %s is outside the tool's stdin_file_root %s", stdinFileArgKey, absPath, tool.StdinFileRoot)
+	}
+
+	root, err := os.OpenRoot(tool.StdinFileRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stdin_file_root: %w", err)
+	}
+	defer core.LogDeferredError(root.Close)
+
+	file, err := root.Open(relPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", stdinFileArgKey, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to stat %s: %w", stdinFileArgKey, err)
+	}
+	if !info.Mode().IsRegular() {
+		_ = file.Close()
+		return nil, fmt.Errorf("%s must be a regular file: %s", stdinFileArgKey, absPath)
+	}
+	if info.Size() > maxStdinBytes {
+		_ = file.Close()
+		return nil, fmt.Errorf("%s %s is %d bytes, which exceeds the maximum stdin size of %d bytes",
+			stdinFileArgKey, absPath, info.Size(), maxStdinBytes)
+	}
+	return file, nil
+}
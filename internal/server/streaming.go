@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"io"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.uber.org/zap"
+)
+
+// progressNotifier sends progress notifications to an MCP client (implemented by *mcp.ServerSession)
+type progressNotifier interface {
+	NotifyProgress(ctx context.Context, params *mcp.ProgressNotificationParams) error
+}
+
+// outputStreamer forwards chunks of a tool's stdout to the client as progress notifications.
+// Each notification carries a chunk as its message and the number of bytes streamed so far as
+// its progress. The tool result still contains the complete output.
+type outputStreamer struct {
+	ctx      context.Context
+	notifier progressNotifier
+	token    any
+	streamed int
+}
+
+// newOutputStreamer returns a writer that streams tool output to the client that made req, or
+// nil if the client did not ask for streaming. Clients advertise that they consume streamed
+// output by sending a progress token with the tool call; without one, MCP does not allow progress
+// notifications for the call and output is only returned, buffered, in the tool result.
+func newOutputStreamer(ctx context.Context, req *mcp.CallToolRequest) io.Writer {
+	if req == nil || req.Session == nil || req.Params == nil {
+		return nil
+	}
+
+	token := req.Params.GetProgressToken()
+	if token == nil {
+		return nil
+	}
+
+	return &outputStreamer{ctx: ctx, notifier: req.Session, token: token}
+}
+
+func (s *outputStreamer) Write(p []byte) (int, error) {
+	s.streamed += len(p)
+	err := s.notifier.NotifyProgress(s.ctx, &mcp.ProgressNotificationParams{
+		ProgressToken: s.token,
+		Message:       string(p),
+		Progress:      float64(s.streamed),
+	})
+	if err != nil {
+		zap.L().Debug("Failed to stream tool output", zap.Error(err))
+		return 0, err
+	}
+	return len(p), nil
+}
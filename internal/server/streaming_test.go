@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/state"
+)
+
+// chunksScript prints two chunks of output
+const chunksScript = "#!/bin/sh\necho first\nsleep 0.1\necho second\n"
+
+// connectStreamingTestClient serves a tool named chunks that runs script and connects a client
+// that records the progress notifications it receives
+func connectStreamingTestClient(t *testing.T, script string, streamable bool) (*mcp.ClientSession, func() []string) {
+	t.Helper()
+
+	toolPath := filepath.Join(t.TempDir(), "chunks.sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(toolPath, []byte(script), 0755))
+
+	registry := state.NewToolsRegistry()
+	require.NoError(t, registry.AddTool(&core.ToolManifest{
+		Name:        "chunks",
+		Description: "Prints output in chunks",
+		Path:        toolPath,
+		Interpreter: "/bin/sh",
+		Streamable:  streamable,
+	}))
+	srv := NewOrlaServer(&config.OrlaConfig{ToolsRegistry: registry, Port: 8080, Timeout: 30}, "")
+	require.NotNil(t, srv)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.orlaMCPserver.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError(serverSession.Close) })
+
+	var mu sync.Mutex
+	var chunks []string
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			chunks = append(chunks, req.Params.Message)
+		},
+	})
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError(clientSession.Close) })
+
+	return clientSession, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), chunks...)
+	}
+}
+
+// TestToolCall_StreamsOutputToCapableClient tests that a client that sends a progress token
+// receives tool output in chunks as well as in the result
+func TestToolCall_StreamsOutputToCapableClient(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	clientSession, receivedChunks := connectStreamingTestClient(t, chunksScript, false)
+
+	params := &mcp.CallToolParams{
+		Meta:      mcp.Meta{"progressToken": "call-1"},
+		Name:      "chunks",
+		Arguments: map[string]any{},
+	}
+	result, err := clientSession.CallTool(context.Background(), params)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Equal(t, "first\nsecond\n", textContent.Text)
+
+	require.Eventually(t, func() bool {
+		return strings.Join(receivedChunks(), "") == "first\nsecond\n"
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.GreaterOrEqual(t, len(receivedChunks()), 2, "output should arrive as it is produced")
+}
+
+// TestToolCall_BuffersOutputForNonStreamingClient tests that a client without a progress token
+// receives the complete output in the result and no notifications
+func TestToolCall_BuffersOutputForNonStreamingClient(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	clientSession, receivedChunks := connectStreamingTestClient(t, chunksScript, false)
+
+	result, err := clientSession.CallTool(context.Background(), &mcp.CallToolParams{Name: "chunks", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Equal(t, "first\nsecond\n", textContent.Text)
+	assert.Empty(t, receivedChunks())
+}
+
+// TestToolCall_StreamableToolStreamsLines tests that a streamable tool that prints progress in a
+// loop, a line at a time in several writes, delivers one progress notification per line while it
+// runs, and the aggregated output in the result
+func TestToolCall_StreamableToolStreamsLines(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	script := `#!/bin/sh
+for i in 1 2 3; do
+  printf "step $i"
+  sleep 0.05
+  printf " done\n"
+done
+printf "finished"
+`
+	clientSession, receivedChunks := connectStreamingTestClient(t, script, true)
+
+	params := &mcp.CallToolParams{
+		Meta:      mcp.Meta{"progressToken": "call-1"},
+		Name:      "chunks",
+		Arguments: map[string]any{},
+	}
+	result, err := clientSession.CallTool(context.Background(), params)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Equal(t, "step 1 done\nstep 2 done\nstep 3 done\nfinished", textContent.Text)
+
+	expected := []string{"step 1 done\n", "step 2 done\n", "step 3 done\n", "finished"}
+	require.Eventually(t, func() bool { return len(receivedChunks()) == len(expected) }, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, expected, receivedChunks())
+}
+
+// failingNotifier is a progressNotifier whose client is gone
+type failingNotifier struct{}
+
+func (failingNotifier) NotifyProgress(context.Context, *mcp.ProgressNotificationParams) error {
+	return errors.New("connection closed")
+}
+
+func TestOutputStreamer(t *testing.T) {
+	assert.Nil(t, newOutputStreamer(context.Background(), nil))
+	assert.Nil(t, newOutputStreamer(context.Background(), &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{}}))
+
+	streamer := &outputStreamer{ctx: context.Background(), notifier: failingNotifier{}, token: "t"}
+	_, err := streamer.Write([]byte("chunk"))
+	require.Error(t, err)
+}
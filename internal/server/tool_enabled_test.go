@@ -0,0 +1,41 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/config"
+)
+
+func TestRebuildServer_DisabledTools(t *testing.T) {
+	cfg := createFilterTestConfig(t)
+	disabled := false
+	alpha, err := cfg.ToolsRegistry.GetTool("alpha")
+	require.NoError(t, err)
+	alpha.Enabled = &disabled
+	cfg.ToolOverrides = map[string]config.ToolOverride{"beta": {Enabled: &disabled}}
+
+	srv := NewOrlaServer(cfg, "")
+	assert.False(t, srv.registeredTools.Contains("alpha"), "tools whose manifest sets enabled: false are skipped")
+	assert.False(t, srv.registeredTools.Contains("beta"), "tools disabled in tool_overrides are skipped")
+	assert.True(t, srv.registeredTools.Contains("gamma"))
+
+	tools := listToolsByName(t, srv)
+	assert.NotContains(t, tools, "alpha")
+	assert.NotContains(t, tools, "beta")
+	assert.Contains(t, tools, "gamma")
+}
+
+func TestRebuildServer_ToolOverrideEnablesTool(t *testing.T) {
+	cfg := createFilterTestConfig(t)
+	disabled, enabled := false, true
+	alpha, err := cfg.ToolsRegistry.GetTool("alpha")
+	require.NoError(t, err)
+	alpha.Enabled = &disabled
+	cfg.ToolOverrides = map[string]config.ToolOverride{"alpha": {Enabled: &enabled}}
+
+	srv := NewOrlaServer(cfg, "")
+	assert.True(t, srv.registeredTools.Contains("alpha"))
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// maxMCPToolNameLength is the maximum length of an MCP tool name
+	maxMCPToolNameLength = 128
+	// fallbackMCPToolName is used when a raw tool name is empty
+	fallbackMCPToolName = "tool"
+)
+
+// SanitizeMCPToolName maps a raw tool name (from a filename or manifest) to a valid MCP tool name.
+// The rules are:
+//   - ASCII letters, digits, '_', '-' and '.' are kept as they are
+//   - whitespace and any other character become '_', with a run of them replaced by a single '_'
+//   - an empty name becomes "tool"
+//   - the result is truncated to 128 characters
+//
+// A name that is already valid is only truncated. Sanitization can map different raw names to the
+// same MCP name; see mcpToolNamer for how collisions are resolved.
+func SanitizeMCPToolName(raw string) string {
+	var b strings.Builder
+	replaced := false
+	for _, r := range raw {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+			replaced = false
+		case replaced:
+			continue
+		default:
+			r = '_'
+			replaced = true
+		}
+		b.WriteRune(r)
+	}
+
+	name := b.String()
+	if name == "" {
+		name = fallbackMCPToolName
+	}
+	if len(name) > maxMCPToolNameLength {
+		name = name[:maxMCPToolNameLength]
+	}
+	return name
+}
+
+// mcpToolNamer assigns unique MCP tool names to raw tool names
+type mcpToolNamer struct {
+	assigned map[string]string // MCP name -> raw name
+}
+
+// newMCPToolNamer creates an mcpToolNamer with no names assigned
+func newMCPToolNamer() *mcpToolNamer {
+	return &mcpToolNamer{assigned: make(map[string]string)}
+}
+
+// assign returns the MCP name for the raw tool name. If the sanitized name is already taken by
+// another tool, a numeric suffix ("_2", "_3", ...) is appended until the name is unique.
+func (n *mcpToolNamer) assign(raw string) string {
+	base := SanitizeMCPToolName(raw)
+	name := base
+	for i := 2; ; i++ {
+		if _, taken := n.assigned[name]; !taken {
+			break
+		}
+		suffix := fmt.Sprintf("_%d", i)
+		name = base
+		if len(name)+len(suffix) > maxMCPToolNameLength {
+			name = name[:maxMCPToolNameLength-len(suffix)]
+		}
+		name += suffix
+	}
+
+	if name != base {
+		zap.L().Warn("Tool name collides with another tool after sanitization, using a suffixed MCP name",
+			zap.String("tool", raw),
+			zap.String("mcp_name", name),
+			zap.String("collides_with", n.assigned[base]))
+	} else if name != raw {
+		zap.L().Info("Sanitized tool name for MCP",
+			zap.String("tool", raw),
+			zap.String("mcp_name", name))
+	}
+
+	n.assigned[name] = raw
+	return name
+}
+
+// release frees the MCP name assigned to the raw tool name and returns it, so that the tool can be
+// removed from the MCP server. The second return value is false if no name was assigned.
+func (n *mcpToolNamer) release(raw string) (string, bool) {
+	for name, assignedRaw := range n.assigned {
+		if assignedRaw == raw {
+			delete(n.assigned, name)
+			return name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/state"
+)
+
+// validMCPToolName matches names allowed by the MCP tool name rules
+var validMCPToolName = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,128}$`)
+
+func TestSanitizeMCPToolName(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{raw: "fs", want: "fs"},
+		{raw: "read-file.v2", want: "read-file.v2"},
+		{raw: "My Tool", want: "My_Tool"},
+		{raw: "UPPER", want: "UPPER"},
+		{raw: "  padded  name  ", want: "_padded_name_"},
+		{raw: "weird/name!?", want: "weird_name_"},
+		{raw: "tab\tand\nnewline", want: "tab_and_newline"},
+		{raw: "héllo wörld", want: "h_llo_w_rld"},
+		{raw: "__already__underscored__", want: "__already__underscored__"},
+		{raw: "!!!", want: "_"},
+		{raw: "", want: "tool"},
+		{raw: strings.Repeat("a", 200), want: strings.Repeat("a", 128)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got := SanitizeMCPToolName(tt.raw)
+			assert.Equal(t, tt.want, got)
+			assert.Regexp(t, validMCPToolName, got)
+		})
+	}
+}
+
+func TestMCPToolNamer_Collisions(t *testing.T) {
+	namer := newMCPToolNamer()
+
+	assert.Equal(t, "my_tool", namer.assign("my tool"))
+	assert.Equal(t, "my_tool_2", namer.assign("my_tool"))
+	assert.Equal(t, "my_tool_3", namer.assign("my/tool"))
+	assert.Equal(t, "other", namer.assign("other"))
+
+	// Suffixes still fit in the length limit
+	long := strings.Repeat("b", 200)
+	first := namer.assign(long)
+	second := namer.assign(long + "!")
+	assert.Len(t, first, maxMCPToolNameLength)
+	assert.Len(t, second, maxMCPToolNameLength)
+	assert.True(t, strings.HasSuffix(second, "_2"))
+	assert.NotEqual(t, first, second)
+}
+
+func TestMCPToolNamer_Release(t *testing.T) {
+	namer := newMCPToolNamer()
+	assert.Equal(t, "my_tool", namer.assign("my tool"))
+
+	name, ok := namer.release("my tool")
+	assert.True(t, ok)
+	assert.Equal(t, "my_tool", name)
+
+	_, ok = namer.release("my tool")
+	assert.False(t, ok)
+
+	// A released name can be assigned again without a suffix
+	assert.Equal(t, "my_tool", namer.assign("my_tool"))
+}
+
+// TestRebuildServer_SanitizesToolNames tests that tools with invalid names are exposed under
+// valid, unique MCP names, with the original name kept as the title
+func TestRebuildServer_SanitizesToolNames(t *testing.T) {
+	registry := state.NewToolsRegistry()
+	for _, name := range []string{"my tool", "my_tool", "bad/name!", "fs"} {
+		require.NoError(t, registry.AddTool(&core.ToolManifest{
+			Name:        name,
+			Description: "A test tool",
+			Path:        "/bin/true",
+		}))
+	}
+
+	cfg := &config.OrlaConfig{
+		ToolsRegistry: registry,
+		Port:          8080,
+		Timeout:       30,
+	}
+	srv := NewOrlaServer(cfg, "")
+	require.NotNil(t, srv)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := srv.orlaMCPserver.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	defer core.LogDeferredError(serverSession.Close)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer core.LogDeferredError(clientSession.Close)
+
+	result, err := clientSession.ListTools(ctx, nil)
+	require.NoError(t, err)
+
+	titles := make(map[string]string)
+	for _, tool := range result.Tools {
+		assert.Regexp(t, validMCPToolName, tool.Name)
+		titles[tool.Name] = tool.Title
+	}
+
+	// Tools are registered in name order, so "my tool" claims "my_tool" before "my_tool" does
+	assert.Equal(t, map[string]string{
+		"my_tool":   "my tool",
+		"my_tool_2": "my_tool",
+		"bad_name_": "bad/name!",
+		"fs":        "",
+	}, titles)
+}
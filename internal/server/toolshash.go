@@ -0,0 +1,82 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// ToolsHashHeader is the HTTP response header carrying the tools hash on the MCP endpoint.
+// Clients that cache the tool list can skip listing tools again while it is unchanged.
+const ToolsHashHeader = "Orla-Tools-Hash"
+
+// toolDefinition is the part of a registered tool that MCP clients see when listing tools
+type toolDefinition struct {
+	Name         string         `json:"name"`
+	Description  string         `json:"description"`
+	InputSchema  map[string]any `json:"input_schema,omitempty"`
+	OutputSchema map[string]any `json:"output_schema,omitempty"`
+}
+
+// computeToolsHash returns a stable hash of the names, descriptions, and schemas of the given
+// tools. It does not depend on the order of tools, and JSON encoding sorts schema keys, so the
+// hash only changes when a tool definition does.
+func computeToolsHash(tools []*core.ToolManifest) string {
+	definitions := make([]toolDefinition, 0, len(tools))
+	for _, tool := range tools {
+		definition := toolDefinition{Name: tool.Name, Description: tool.Description}
+		if tool.MCP != nil {
+			definition.InputSchema = tool.MCP.InputSchema
+			definition.OutputSchema = tool.MCP.OutputSchema
+		}
+		definitions = append(definitions, definition)
+	}
+	slices.SortFunc(definitions, func(a, b toolDefinition) int { return strings.Compare(a.Name, b.Name) })
+
+	hash := sha256.New()
+	encoder := json.NewEncoder(hash)
+	for _, definition := range definitions {
+		if err := encoder.Encode(definition); err != nil {
+			// Schemas are decoded from YAML or JSON, so this is not expected; hash the name alone
+			zap.L().Warn("Failed to encode tool definition for the tools hash",
+				zap.String("tool", definition.Name),
+				zap.Error(err))
+			_, _ = hash.Write([]byte(definition.Name + "\n"))
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// updateToolsHash recomputes the tools hash from the registered tools. The caller must hold o.mu.
+func (o *OrlaServer) updateToolsHash() {
+	tools := make([]*core.ToolManifest, 0, o.registeredTools.Cardinality())
+	for _, tool := range o.config.ToolsRegistry.ListTools() {
+		if o.registeredTools.Contains(tool.Name) {
+			tools = append(tools, tool)
+		}
+	}
+	o.toolsHash = computeToolsHash(tools)
+}
+
+// ToolsHash returns the hash of the tools currently registered with the MCP server. It changes
+// whenever a tool is added, removed, or has its description or schemas changed.
+func (o *OrlaServer) ToolsHash() string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.toolsHash
+}
+
+// withToolsHashHeader sets the tools hash header on every response served by next
+func (o *OrlaServer) withToolsHashHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ToolsHashHeader, o.ToolsHash())
+		next.ServeHTTP(w, r)
+	})
+}
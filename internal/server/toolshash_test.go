@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/registry"
+	"github.com/dorcha-inc/orla/internal/state"
+)
+
+// hashTestTool returns a tool with an input schema for the tools hash tests
+func hashTestTool(name string, cityType string) *core.ToolManifest {
+	return &core.ToolManifest{
+		Name:        name,
+		Description: "Look up the weather",
+		MCP: &core.MCPConfig{
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"city": map[string]any{"type": cityType},
+					"days": map[string]any{"type": "integer"},
+				},
+			},
+		},
+	}
+}
+
+func TestComputeToolsHash(t *testing.T) {
+	weather := hashTestTool("weather", "string")
+	other := &core.ToolManifest{Name: "other", Description: "Another tool"}
+
+	hash := computeToolsHash([]*core.ToolManifest{weather, other})
+	assert.Len(t, hash, 64)
+
+	// Stable across calls, tool order, and equal manifests
+	assert.Equal(t, hash, computeToolsHash([]*core.ToolManifest{weather, other}))
+	assert.Equal(t, hash, computeToolsHash([]*core.ToolManifest{other, hashTestTool("weather", "string")}))
+
+	// Fields clients do not see do not change the hash
+	withPath := hashTestTool("weather", "string")
+	withPath.Path = "/elsewhere/weather"
+	withPath.Version = "2.0.0"
+	assert.Equal(t, hash, computeToolsHash([]*core.ToolManifest{withPath, other}))
+
+	// A schema, description, or tool set change does
+	assert.NotEqual(t, hash, computeToolsHash([]*core.ToolManifest{hashTestTool("weather", "number"), other}))
+	described := hashTestTool("weather", "string")
+	described.Description = "Look up the forecast"
+	assert.NotEqual(t, hash, computeToolsHash([]*core.ToolManifest{described, other}))
+	assert.NotEqual(t, hash, computeToolsHash([]*core.ToolManifest{weather}))
+
+	assert.NotEmpty(t, computeToolsHash(nil))
+}
+
+func TestToolsHash_Rebuild(t *testing.T) {
+	cfg := createTestConfig(t)
+	require.NoError(t, cfg.ToolsRegistry.AddTool(hashTestTool("weather", "string")))
+	srv := NewOrlaServer(cfg, "")
+
+	hash := srv.ToolsHash()
+	require.NotEmpty(t, hash)
+
+	// Rebuilding with the same tools keeps the hash
+	srv.rebuildServer()
+	assert.Equal(t, hash, srv.ToolsHash())
+
+	// Rebuilding after a tool's schema changed changes it
+	testTool, err := cfg.ToolsRegistry.GetTool("test-tool")
+	require.NoError(t, err)
+	cfg.ToolsRegistry = &state.ToolsRegistry{Tools: map[string]*core.ToolManifest{
+		"test-tool": testTool,
+		"weather":   hashTestTool("weather", "number"),
+	}}
+	srv.rebuildServer()
+	assert.NotEqual(t, hash, srv.ToolsHash())
+	assert.Equal(t, srv.ToolsHash(), srv.AdminState().ToolsHash)
+}
+
+func TestToolsHash_DisableTool(t *testing.T) {
+	t.Setenv(registry.OrlaHomeEnvVar, t.TempDir())
+
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+	hash := srv.ToolsHash()
+
+	// Disabling a tool removes it from the tool list, so the hash changes
+	require.NoError(t, srv.SetToolDisabled("test-tool", true))
+	assert.NotEqual(t, hash, srv.ToolsHash())
+
+	require.NoError(t, srv.SetToolDisabled("test-tool", false))
+	assert.Equal(t, hash, srv.ToolsHash())
+}
+
+func TestToolsHash_HTTPHeader(t *testing.T) {
+	cfg := createTestConfig(t)
+	srv := NewOrlaServer(cfg, "")
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", srv.withToolsHashHeader(srv.httpHandler))
+	httpServer := httptest.NewServer(mux)
+	t.Cleanup(httpServer.Close)
+
+	// The client may open a standalone SSE stream alongside its requests
+	var headersMu sync.Mutex
+	var headers []string
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		if err == nil {
+			headersMu.Lock()
+			headers = append(headers, resp.Header.Get(ToolsHashHeader))
+			headersMu.Unlock()
+		}
+		return resp, err
+	})}
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:   httpServer.URL + "/mcp",
+		HTTPClient: httpClient,
+	}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError(session.Close) })
+
+	_, err = session.ListTools(ctx, nil)
+	require.NoError(t, err)
+
+	headersMu.Lock()
+	defer headersMu.Unlock()
+	require.NotEmpty(t, headers)
+	for _, header := range headers {
+		assert.Equal(t, srv.ToolsHash(), header)
+	}
+
+	// The admin state reports the same hash
+	rec := httptest.NewRecorder()
+	srv.handleAdminState(rec, httptest.NewRequest(http.MethodGet, AdminStatePath, nil))
+	var adminState AdminState
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&adminState))
+	assert.Equal(t, srv.ToolsHash(), adminState.ToolsHash)
+}
+
+// roundTripFunc adapts a function to an http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
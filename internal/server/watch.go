@@ -0,0 +1,295 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/installer"
+)
+
+// defaultWatchDebounce is how long the file watcher waits for changes to stop before it reloads,
+// so that a save that writes several files reloads once
+const defaultWatchDebounce = 500 * time.Millisecond
+
+// fileWatcher reloads the server when its config file or a tool in its tools directory changes.
+// Other files in the tools directory, such as caches that running tools write next to their
+// entrypoints, are ignored so that a tool does not cause a reload each time it runs. fsnotify does
+// not watch directories recursively, so every directory of the tools directory is watched, and
+// config files are watched through their directories, since editors often save by replacing the
+// file.
+type fileWatcher struct {
+	server      *OrlaServer
+	watcher     *fsnotify.Watcher
+	debounce    time.Duration
+	configFiles []string // config files that the server loads on reload
+	toolsDir    string   // tools directory of the current config, empty if it has none
+}
+
+// startFileWatcher starts watching the server's files if the config sets watch_files. The
+// watcher stops when ctx is done.
+func (o *OrlaServer) startFileWatcher(ctx context.Context) error {
+	o.mu.RLock()
+	watchFiles := o.config.WatchFiles
+	o.mu.RUnlock()
+	if !watchFiles {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	w := &fileWatcher{
+		server:      o,
+		watcher:     watcher,
+		debounce:    o.watchDebounce,
+		configFiles: o.watchedConfigFiles(),
+	}
+	w.sync()
+	zap.L().Info("Watching files, the config and tools are reloaded when they change",
+		zap.Strings("config_files", w.configFiles),
+		zap.String("tools_dir", w.toolsDir))
+
+	go w.run(ctx)
+	return nil
+}
+
+// watchedConfigFiles returns the config files that Reload loads: the config path the server was
+// started with, or else the project and user config files
+func (o *OrlaServer) watchedConfigFiles() []string {
+	var paths []string
+	if o.configPath != "" {
+		paths = append(paths, o.configPath)
+	} else {
+		if projectPath, err := config.GetProjectConfigPath(); err == nil {
+			paths = append(paths, projectPath)
+		}
+		if userPath, err := config.GetUserConfigPath(); err == nil {
+			paths = append(paths, userPath)
+		}
+	}
+
+	configFiles := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if absPath, err := filepath.Abs(path); err == nil {
+			configFiles = append(configFiles, absPath)
+		}
+	}
+	return configFiles
+}
+
+// run handles file events until ctx is done, reloading once no change has been seen for the
+// debounce interval
+func (w *fileWatcher) run(ctx context.Context) {
+	defer func() {
+		if err := w.watcher.Close(); err != nil {
+			zap.L().Error("Failed to close file watcher", zap.Error(err))
+		}
+	}()
+
+	changed := make(map[string]struct{})
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			// Watch directories created in the tools directory, e.g. by orla tool install
+			if event.Has(fsnotify.Create) && w.inToolsDir(event.Name) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					w.addTree(event.Name)
+				}
+			}
+			if !w.relevant(event.Name) {
+				continue
+			}
+			zap.L().Debug("Watched file changed", zap.String("path", event.Name), zap.String("op", event.Op.String()))
+			changed[event.Name] = struct{}{}
+			timer.Reset(w.debounce)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			zap.L().Error("File watcher error", zap.Error(err))
+		case <-timer.C:
+			paths := make([]string, 0, len(changed))
+			for path := range changed {
+				paths = append(paths, path)
+			}
+			slices.Sort(paths)
+			clear(changed)
+
+			zap.L().Info("Files changed, reloading configuration and tools", zap.Strings("changed", paths))
+			if err := w.server.Reload(); err != nil {
+				zap.L().Error("Failed to reload", zap.Error(err))
+			} else {
+				zap.L().Info("Successfully reloaded configuration and tools")
+			}
+			// The reloaded config may have a different tools directory
+			w.sync()
+		}
+	}
+}
+
+// sync watches the directories of the config files and of the current config's tools directory,
+// and stops watching any others
+func (w *fileWatcher) sync() {
+	w.server.mu.RLock()
+	toolsDir := w.server.config.ToolsDir
+	w.server.mu.RUnlock()
+
+	w.toolsDir = ""
+	if toolsDir != "" {
+		if absToolsDir, err := filepath.Abs(toolsDir); err == nil {
+			w.toolsDir = absToolsDir
+		}
+	}
+
+	wanted := make(map[string]struct{})
+	for _, configFile := range w.configFiles {
+		wanted[filepath.Dir(configFile)] = struct{}{}
+	}
+	if w.toolsDir != "" {
+		dirs := toolsDirTree(w.toolsDir)
+		if len(dirs) == 0 {
+			// Watch the parent until the tools directory is created
+			dirs = []string{filepath.Dir(w.toolsDir)}
+		}
+		for _, dir := range dirs {
+			wanted[dir] = struct{}{}
+		}
+	}
+
+	for _, dir := range w.watcher.WatchList() {
+		if _, ok := wanted[dir]; !ok {
+			_ = w.watcher.Remove(dir)
+		}
+	}
+	watching := w.watcher.WatchList()
+	for dir := range wanted {
+		if !slices.Contains(watching, dir) {
+			w.add(dir)
+		}
+	}
+}
+
+// addTree watches dir and the directories in it
+func (w *fileWatcher) addTree(dir string) {
+	for _, path := range toolsDirTree(dir) {
+		w.add(path)
+	}
+}
+
+// add watches dir. A directory that does not exist is skipped, as it has nothing to watch yet.
+func (w *fileWatcher) add(dir string) {
+	if err := w.watcher.Add(dir); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		zap.L().Warn("Failed to watch directory, changes in it need a reload",
+			zap.String("directory", dir),
+			zap.Error(err))
+	}
+}
+
+// relevant reports whether a change to path can change the config or tools: path is a config
+// file, a tool.yaml manifest, a registered tool's entrypoint or a directory holding one, or an
+// executable file or a directory containing one, which the next scan would register as a tool
+func (w *fileWatcher) relevant(path string) bool {
+	if slices.Contains(w.configFiles, path) {
+		return true
+	}
+	if !w.inToolsDir(path) {
+		return false
+	}
+	if filepath.Base(path) == installer.ToolManifestFileName || w.server.hasToolIn(path) {
+		return true
+	}
+
+	// The path may be gone already, in which case it was not a registered tool
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	if info.IsDir() {
+		return containsTool(path)
+	}
+	return core.IsExecutable(info)
+}
+
+// hasToolIn reports whether a registered tool's entrypoint is path or in the directory path
+func (o *OrlaServer) hasToolIn(path string) bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if o.config.ToolsRegistry == nil {
+		return false
+	}
+	for _, tool := range o.config.ToolsRegistry.ListTools() {
+		if tool.Path == path || strings.HasPrefix(tool.Path, path+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsTool reports whether dir contains a tool.yaml manifest or an executable file, e.g.
+// when a tool directory is moved into the tools directory
+func containsTool(dir string) bool {
+	found := errors.New("found")
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if d.Name() == installer.ToolManifestFileName {
+			return found
+		}
+		if info, err := d.Info(); err == nil && core.IsExecutable(info) {
+			return found
+		}
+		return nil
+	})
+	return errors.Is(err, found)
+}
+
+// inToolsDir reports whether path is the tools directory or in it
+func (w *fileWatcher) inToolsDir(path string) bool {
+	return w.toolsDir != "" && (path == w.toolsDir || strings.HasPrefix(path, w.toolsDir+string(filepath.Separator)))
+}
+
+// toolsDirTree returns dir and every directory in it, or nothing if dir cannot be read
+func toolsDirTree(dir string) []string {
+	var dirs []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip directories that cannot be read rather than giving up on the rest
+			if path == dir {
+				return err
+			}
+			return fs.SkipDir
+		}
+		if d.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil
+	}
+	return dirs
+}
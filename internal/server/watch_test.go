@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/config"
+)
+
+// createWatchTestServer writes a config with the given watch_files setting and a tools directory
+// with one tool, and returns a server for it that debounces file changes briefly
+func createWatchTestServer(t *testing.T, watchFiles bool) (*OrlaServer, string) {
+	t.Helper()
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping executable tool file test on Windows")
+	}
+
+	tmpDir := t.TempDir()
+	toolsDir := filepath.Join(tmpDir, "tools")
+	// #nosec G301 -- test directory permissions are acceptable for temporary test files
+	require.NoError(t, os.MkdirAll(toolsDir, 0755))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(toolsDir, "tool1.sh"), []byte("#!/bin/sh\necho tool1\n"), 0755))
+
+	configPath := filepath.Join(tmpDir, "orla.yaml")
+	configYAML := "tools_dir: ./tools\n"
+	if watchFiles {
+		configYAML += "watch_files: true\n"
+	}
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configYAML), 0644))
+
+	cfg, err := config.LoadConfig(configPath)
+	require.NoError(t, err)
+	srv := NewOrlaServer(cfg, configPath)
+	srv.watchDebounce = 50 * time.Millisecond
+	t.Cleanup(srv.Close)
+	return srv, toolsDir
+}
+
+// TestFileWatcher_NewTool tests that a tool written to the tools directory is registered without
+// an explicit Reload, including one in a directory created after the watcher started
+func TestFileWatcher_NewTool(t *testing.T) {
+	srv, toolsDir := createWatchTestServer(t, true)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, srv.startFileWatcher(ctx))
+	require.True(t, srv.registeredTools.Contains("tool1"))
+
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(toolsDir, "tool2.sh"), []byte("#!/bin/sh\necho tool2\n"), 0755))
+	require.Eventually(t, func() bool { return srv.registeredTools.Contains("tool2") }, 5*time.Second, 10*time.Millisecond)
+
+	nestedDir := filepath.Join(toolsDir, "nested")
+	// #nosec G301 -- test directory permissions are acceptable for temporary test files
+	require.NoError(t, os.Mkdir(nestedDir, 0755))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(nestedDir, "tool3.sh"), []byte("#!/bin/sh\necho tool3\n"), 0755))
+	assert.Eventually(t, func() bool { return srv.registeredTools.Contains("tool3") }, 5*time.Second, 10*time.Millisecond)
+}
+
+// TestFileWatcher_ConfigChange tests that a change to the config file is reloaded
+func TestFileWatcher_ConfigChange(t *testing.T) {
+	srv, toolsDir := createWatchTestServer(t, true)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, srv.startFileWatcher(ctx))
+
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(srv.configPath, []byte("tools_dir: ./tools\nwatch_files: true\ntimeout: 45\n"), 0644))
+	assert.Eventually(t, func() bool {
+		srv.mu.RLock()
+		defer srv.mu.RUnlock()
+		return srv.config.Timeout == 45 && srv.config.ToolsDir == toolsDir
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// TestFileWatcher_StopsWithContext tests that changes are no longer reloaded once the context
+// passed to the watcher is done
+func TestFileWatcher_StopsWithContext(t *testing.T) {
+	srv, toolsDir := createWatchTestServer(t, true)
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, srv.startFileWatcher(ctx))
+	cancel()
+
+	// Give the watcher time to see the cancellation before the change
+	time.Sleep(50 * time.Millisecond)
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(toolsDir, "tool2.sh"), []byte("#!/bin/sh\necho tool2\n"), 0755))
+	time.Sleep(10 * srv.watchDebounce)
+	assert.False(t, srv.registeredTools.Contains("tool2"))
+}
+
+// TestFileWatcher_Disabled tests that files are not watched unless watch_files is set
+func TestFileWatcher_Disabled(t *testing.T) {
+	srv, toolsDir := createWatchTestServer(t, false)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, srv.startFileWatcher(ctx))
+
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(toolsDir, "tool2.sh"), []byte("#!/bin/sh\necho tool2\n"), 0755))
+	time.Sleep(10 * srv.watchDebounce)
+	assert.False(t, srv.registeredTools.Contains("tool2"))
+}
+
+// TestFileWatcher_Relevant tests that only changes to the config and to tools cause a reload, not
+// other files that tools write in the tools directory
+func TestFileWatcher_Relevant(t *testing.T) {
+	srv, toolsDir := createWatchTestServer(t, true)
+	w := &fileWatcher{server: srv, configFiles: []string{srv.configPath}, toolsDir: toolsDir}
+
+	cacheDir := filepath.Join(toolsDir, "__pycache__")
+	// #nosec G301 -- test directory permissions are acceptable for temporary test files
+	require.NoError(t, os.Mkdir(cacheDir, 0755))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(cacheDir, "tool.cpython-312.pyc"), []byte("cache"), 0644))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(toolsDir, "output.log"), []byte("log"), 0644))
+
+	toolDir := filepath.Join(toolsDir, "pkg")
+	// #nosec G301 -- test directory permissions are acceptable for temporary test files
+	require.NoError(t, os.Mkdir(toolDir, 0755))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(toolDir, "tool.yaml"), []byte("name: pkg\n"), 0644))
+
+	assert.True(t, w.relevant(srv.configPath))
+	assert.True(t, w.relevant(filepath.Join(toolsDir, "tool1.sh")), "registered tool")
+	assert.True(t, w.relevant(toolsDir), "directory holding a registered tool")
+	assert.True(t, w.relevant(filepath.Join(toolsDir, "removed", "tool.yaml")), "removed manifest")
+	assert.True(t, w.relevant(toolDir), "directory with a manifest")
+
+	assert.False(t, w.relevant(cacheDir))
+	assert.False(t, w.relevant(filepath.Join(cacheDir, "tool.cpython-312.pyc")))
+	assert.False(t, w.relevant(filepath.Join(toolsDir, "output.log")))
+	assert.False(t, w.relevant(filepath.Join(toolsDir, "removed.log")))
+	assert.False(t, w.relevant(filepath.Join(filepath.Dir(toolsDir), "other.yaml")))
+}
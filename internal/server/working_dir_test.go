@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// addPwdTool writes a tool that prints the directory it runs in and adds it to cfg's registry
+func addPwdTool(t *testing.T, cfg *config.OrlaConfig, name, workingDir string) {
+	t.Helper()
+	scriptPath := filepath.Join(cfg.ToolsDir, name+".sh")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(scriptPath, []byte("#!/bin/sh\npwd -P\n"), 0755))
+	require.NoError(t, cfg.ToolsRegistry.AddTool(&core.ToolManifest{Name: name, Description: "Print the working directory", Path: scriptPath, WorkingDir: workingDir}))
+}
+
+func TestCallTool_WorkingDir(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping pwd test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	// Resolve symlinks, as pwd -P does, e.g. for /tmp on macOS
+	toolDir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	serverDir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	cfg.ToolWorkingDir = serverDir
+	addPwdTool(t, cfg, "pwd-tool", toolDir)
+	addPwdTool(t, cfg, "pwd-default", "")
+
+	srv := NewOrlaServer(cfg, "")
+	t.Cleanup(srv.Close)
+
+	for name, want := range map[string]string{"pwd-tool": toolDir, "pwd-default": serverDir} {
+		result, err := srv.CallTool(context.Background(), name, map[string]any{})
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		require.Len(t, result.Content, 1)
+		text, ok := result.Content[0].(*mcp.TextContent)
+		require.True(t, ok)
+		assert.Equal(t, want, strings.TrimSpace(text.Text), name)
+	}
+}
+
+func TestRebuildServer_MissingWorkingDir(t *testing.T) {
+	if runtime.GOOS == windowsOS {
+		t.Skip("Skipping pwd test on Windows")
+	}
+
+	cfg := createTestConfig(t)
+	addPwdTool(t, cfg, "pwd-tool", filepath.Join(t.TempDir(), "missing"))
+
+	srv := NewOrlaServer(cfg, "")
+	t.Cleanup(srv.Close)
+	assert.False(t, srv.registeredTools.Contains("pwd-tool"))
+	assert.True(t, srv.registeredTools.Contains("test-tool"))
+}
@@ -0,0 +1,100 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+// disabledToolsFile is the on-disk format of the disabled tools state file
+type disabledToolsFile struct {
+	DisabledTools []string `yaml:"disabled_tools"`
+}
+
+// LoadDisabledTools reads the names of the disabled tools from the state file at path.
+// A missing state file means no tools are disabled.
+func LoadDisabledTools(path string) (mapset.Set[string], error) {
+	// #nosec G304 -- path is the disabled tools state file under the orla home directory
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return mapset.NewSet[string](), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read disabled tools file: %w", err)
+	}
+
+	var file disabledToolsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse disabled tools file: %w", err)
+	}
+
+	return mapset.NewSet(file.DisabledTools...), nil
+}
+
+// SetToolDisabled marks the named tool as disabled or enabled in the state file at path
+// and returns the updated set of disabled tools
+func SetToolDisabled(path string, name string, disabled bool) (mapset.Set[string], error) {
+	disabledTools, err := LoadDisabledTools(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if disabled {
+		disabledTools.Add(name)
+	} else {
+		disabledTools.Remove(name)
+	}
+
+	names := disabledTools.ToSlice()
+	slices.Sort(names)
+	data, err := yaml.Marshal(&disabledToolsFile{DisabledTools: names})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal disabled tools: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data); err != nil {
+		return nil, fmt.Errorf("failed to write disabled tools file: %w", err)
+	}
+
+	return disabledTools, nil
+}
+
+// writeFileAtomic writes data to path through a temporary file in the same directory, so that
+// a concurrent reader never observes a partially written file
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	// #nosec G301 -- orla home directory permissions 0755 match the rest of ~/.orla
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tempFile, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := tempFile.Name()
+	defer core.LogDeferredError(func() error {
+		if err := os.Remove(tempPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+
+	if _, err := tempFile.Write(data); err != nil {
+		core.LogDeferredError(tempFile.Close)
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tempPath, path)
+}
@@ -0,0 +1,54 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDisabledTools_MissingFile(t *testing.T) {
+	disabledTools, err := LoadDisabledTools(filepath.Join(t.TempDir(), "disabled_tools.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, 0, disabledTools.Cardinality())
+}
+
+func TestLoadDisabledTools_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "disabled_tools.yaml")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(path, []byte("disabled_tools: [unterminated"), 0644))
+
+	_, err := LoadDisabledTools(path)
+	assert.Error(t, err)
+}
+
+func TestSetToolDisabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orla", "disabled_tools.yaml")
+
+	disabledTools, err := SetToolDisabled(path, "fs", true)
+	require.NoError(t, err)
+	assert.True(t, disabledTools.Contains("fs"))
+
+	_, err = SetToolDisabled(path, "http", true)
+	require.NoError(t, err)
+
+	// #nosec G304 -- test file path is under the test's temporary directory
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "disabled_tools:\n    - fs\n    - http\n", string(data))
+
+	disabledTools, err = SetToolDisabled(path, "fs", false)
+	require.NoError(t, err)
+	assert.False(t, disabledTools.Contains("fs"))
+
+	loaded, err := LoadDisabledTools(path)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"http"}, loaded.ToSlice())
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -171,26 +171,15 @@ func ScanInstalledTools(installDir string) (map[string]*core.ToolManifest, error
 				return nil // Skip invalid manifests
 			}
 
-			// Open tool directory as root for secure file access
-			toolRoot, errOpen := os.OpenRoot(toolDir)
-			if errOpen != nil {
-				zap.L().Warn("Failed to open tool directory, skipping", zap.String("path", toolDir), zap.Error(errOpen))
+			absEntrypoint, interpreter, errResolve := resolveEntrypoint(manifest, toolDir)
+			if errResolve != nil {
+				zap.L().Warn("Failed to resolve entrypoint, skipping", zap.String("path", toolDir), zap.Error(errResolve))
 				return nil
 			}
-			defer core.LogDeferredError(toolRoot.Close)
-
-			// Parse interpreter from entrypoint using os.Root (automatically prevents path traversal)
-			interpreter, errParse := ParseShebangFromRoot(toolRoot, manifest.Entrypoint)
-			if errParse != nil {
-				// Not an error for binary executables
-				zap.L().Debug("Failed to parse shebang (could be a binary executable)", zap.String("path", manifest.Entrypoint), zap.Error(errParse))
-			}
 
-			// Resolve absolute entrypoint path for tool entry
-			entrypointPath := filepath.Join(toolDir, manifest.Entrypoint)
-			absEntrypoint, errResolve := filepath.Abs(entrypointPath)
-			if errResolve != nil {
-				zap.L().Warn("Failed to resolve entrypoint path, skipping", zap.String("path", entrypointPath), zap.Error(errResolve))
+			// A version other than the one the tool's active version link points at is never used
+			if active := installer.ActiveVersion(filepath.Dir(toolDir)); active != "" && filepath.Base(toolDir) != active {
+				zap.L().Debug("Skipping inactive version of tool", zap.String("tool", manifest.Name), zap.String("version", manifest.Version))
 				return nil
 			}
 
@@ -217,6 +206,10 @@ func ScanInstalledTools(installDir string) (map[string]*core.ToolManifest, error
 			// Populate resolved fields
 			manifest.Path = absEntrypoint
 			manifest.Interpreter = interpreter
+			if errResolve := core.ResolveToolDirs(manifest, toolDir); errResolve != nil {
+				zap.L().Warn("Failed to resolve tool directories, skipping", zap.String("path", toolDir), zap.Error(errResolve))
+				return nil
+			}
 
 			// Ensure Runtime is initialized
 			if manifest.Runtime == nil {
@@ -237,6 +230,65 @@ func ScanInstalledTools(installDir string) (map[string]*core.ToolManifest, error
 	return toolMap, nil
 }
 
+// resolveEntrypoint returns the absolute path of the manifest's entrypoint in toolDir and the
+// interpreter from its shebang, which is empty for binary executables. Tools that run a package
+// have no entrypoint, so their path is the tool directory.
+func resolveEntrypoint(manifest *core.ToolManifest, toolDir string) (string, string, error) {
+	if core.IsPackageTool(manifest) {
+		absToolDir, err := filepath.Abs(toolDir)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to resolve tool directory path: %w", err)
+		}
+		return absToolDir, "", nil
+	}
+
+	// Open tool directory as root for secure file access
+	toolRoot, err := os.OpenRoot(toolDir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open tool directory: %w", err)
+	}
+	defer core.LogDeferredError(toolRoot.Close)
+
+	// Parse interpreter from entrypoint using os.Root (automatically prevents path traversal)
+	interpreter, err := ParseShebangFromRoot(toolRoot, manifest.Entrypoint)
+	if err != nil {
+		// Not an error for binary executables
+		zap.L().Debug("Failed to parse shebang (could be a binary executable)", zap.String("path", manifest.Entrypoint), zap.Error(err))
+	}
+
+	// Resolve absolute entrypoint path for tool entry
+	absEntrypoint, err := filepath.Abs(filepath.Join(toolDir, manifest.Entrypoint))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve entrypoint path: %w", err)
+	}
+
+	return absEntrypoint, interpreter, nil
+}
+
+// LoadToolFromManifest loads the tool described by the manifest file at manifestPath, bypassing
+// discovery and installation. The entrypoint is resolved relative to the manifest's directory.
+func LoadToolFromManifest(manifestPath string) (*core.ToolManifest, error) {
+	manifest, err := installer.LoadManifestFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	toolDir := filepath.Dir(manifestPath)
+	if err := installer.ValidateManifest(manifest, toolDir); err != nil {
+		return nil, err
+	}
+
+	manifest.Path, manifest.Interpreter, err = resolveEntrypoint(manifest, toolDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := core.ResolveToolDirs(manifest, toolDir); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
 // getVersionFromPath extracts version from path like ~/.orla/tools/TOOL-NAME/VERSION/
 // Returns an error if toolPath is not within installDir (security check via os.Root)
 // toolPath is expected to be an absolute path
@@ -3,9 +3,11 @@ package state
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/installer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
@@ -97,6 +99,45 @@ func TestScanInstalledTools_MultipleVersions(t *testing.T) {
 	assert.Len(t, tools, 1)
 	// Should use the newer version (0.2.0)
 	assert.Equal(t, "Filesystem tool v2", tools["fs"].Description)
+
+	// An active version link selects an older version
+	require.NoError(t, installer.SetActiveVersion(filepath.Join(tmpDir, "fs"), "0.1.0"))
+	tools, err = ScanInstalledTools(tmpDir)
+	require.NoError(t, err)
+	assert.Len(t, tools, 1)
+	assert.Equal(t, "Filesystem tool v1", tools["fs"].Description)
+	assert.Equal(t, filepath.Join(v1Dir, "bin", "fs"), tools["fs"].Path)
+}
+
+func TestScanInstalledTools_PackageTool(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Package tools are installed as a tool.yaml without an entrypoint, one version per directory
+	for _, version := range []string{"1.0.0", "1.1.0"} {
+		toolDir := filepath.Join(tmpDir, "fs-server", version)
+		// #nosec G301 -- test directory permissions are acceptable for temporary test files
+		require.NoError(t, os.MkdirAll(toolDir, 0755))
+
+		data, err := yaml.Marshal(&core.ToolManifest{
+			Name:        "fs-server",
+			Version:     version,
+			Description: "Filesystem tools",
+			Runtime:     &core.RuntimeConfig{Mode: core.RuntimeModeNpx, Package: "@modelcontextprotocol/server-filesystem@" + version},
+		})
+		require.NoError(t, err)
+		// #nosec G306 -- test file permissions are acceptable for temporary test files
+		require.NoError(t, os.WriteFile(filepath.Join(toolDir, "tool.yaml"), data, 0644))
+	}
+
+	tools, err := ScanInstalledTools(tmpDir)
+	require.NoError(t, err)
+	require.Contains(t, tools, "fs-server")
+
+	tool := tools["fs-server"]
+	assert.Equal(t, "1.1.0", tool.Version)
+	assert.Equal(t, filepath.Join(tmpDir, "fs-server", "1.1.0"), tool.Path)
+	assert.Empty(t, tool.Interpreter)
+	assert.Equal(t, "@modelcontextprotocol/server-filesystem@1.1.0", tool.Runtime.Package)
 }
 
 func TestScanInstalledTools_EmptyDirectory(t *testing.T) {
@@ -510,3 +551,33 @@ func TestScanInstalledTools_InvalidShebang(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, tools)
 }
+
+func TestLoadToolFromManifest(t *testing.T) {
+	toolDir := t.TempDir()
+	// #nosec G301 -- test directory permissions are acceptable for temporary test files
+	require.NoError(t, os.MkdirAll(filepath.Join(toolDir, "bin"), 0755))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(toolDir, "bin", "tool.sh"), []byte("#!/bin/sh\necho hi\n"), 0755))
+
+	manifestPath := filepath.Join(toolDir, "dev.yaml")
+	manifest := "name: dev\nversion: 1.0.0\ndescription: A tool in development\nentrypoint: bin/tool.sh\n"
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(manifestPath, []byte(manifest), 0644))
+
+	tool, err := LoadToolFromManifest(manifestPath)
+	require.NoError(t, err)
+	assert.Equal(t, "dev", tool.Name)
+	assert.Equal(t, filepath.Join(toolDir, "bin", "tool.sh"), tool.Path)
+	assert.Equal(t, "/bin/sh", tool.Interpreter)
+	require.NotNil(t, tool.Runtime)
+	assert.Equal(t, core.RuntimeModeSimple, tool.Runtime.Mode)
+
+	// The entrypoint must exist relative to the manifest
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(manifestPath, []byte(strings.Replace(manifest, "bin/tool.sh", "bin/missing.sh", 1)), 0644))
+	_, err = LoadToolFromManifest(manifestPath)
+	assert.Error(t, err)
+
+	_, err = LoadToolFromManifest(filepath.Join(toolDir, "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package state
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/dorcha-inc/orla/internal/core"
 )
@@ -27,8 +28,11 @@ func NewToolNotFoundError(name string) *ToolNotFoundError {
 // This ensures that ToolNotFoundError implements the error interface.
 var _ error = &ToolNotFoundError{}
 
-// ToolsRegistry maintains a registry of tools and their entries.
+// ToolsRegistry maintains a registry of tools and their entries. Its methods are safe for
+// concurrent use; Tools is exported for decoding and must not be accessed directly once the
+// registry is shared.
 type ToolsRegistry struct {
+	mu    sync.RWMutex
 	Tools map[string]*core.ToolManifest `yaml:"tools"` // the tools in the registry
 }
 
@@ -50,6 +54,12 @@ func NewToolsRegistryFromDirectory(dir string) (*ToolsRegistry, error) {
 
 // AddTool adds a tool to the registry
 func (r *ToolsRegistry) AddTool(tool *core.ToolManifest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Tools == nil {
+		r.Tools = make(map[string]*core.ToolManifest)
+	}
 	if _, ok := r.Tools[tool.Name]; ok {
 		return NewDuplicateToolNameError(tool.Name)
 	}
@@ -59,6 +69,9 @@ func (r *ToolsRegistry) AddTool(tool *core.ToolManifest) error {
 
 // GetTool returns a tool from the registry
 func (r *ToolsRegistry) GetTool(name string) (*core.ToolManifest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	tool, ok := r.Tools[name]
 	if !ok {
 		return nil, NewToolNotFoundError(name)
@@ -66,11 +79,23 @@ func (r *ToolsRegistry) GetTool(name string) (*core.ToolManifest, error) {
 	return tool, nil
 }
 
-// ListTools returns all tools in the registry
+// ListTools returns all tools in the registry. The returned slice is a copy and may be
+// modified by the caller.
 func (r *ToolsRegistry) ListTools() []*core.ToolManifest {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	tools := make([]*core.ToolManifest, 0, len(r.Tools))
 	for _, tool := range r.Tools {
 		tools = append(tools, tool)
 	}
 	return tools
 }
+
+// Len returns the number of tools in the registry
+func (r *ToolsRegistry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.Tools)
+}
@@ -1,8 +1,10 @@
 package state
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/dorcha-inc/orla/internal/core"
@@ -207,6 +209,52 @@ func TestNewToolsRegistryFromDirectory(t *testing.T) {
 	assert.Contains(t, bashTool.Interpreter, "bash")
 }
 
+// TestToolsRegistry_ConcurrentAccess adds, lists, and looks up tools from many goroutines at
+// once. Run with -race to detect unsynchronized access to the registry.
+func TestToolsRegistry_ConcurrentAccess(t *testing.T) {
+	registry := NewToolsRegistry()
+
+	const writers = 8
+	const toolsPerWriter = 50
+
+	var wg sync.WaitGroup
+	for w := range writers {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := range toolsPerWriter {
+				name := fmt.Sprintf("tool-%d-%d", w, i)
+				assert.NoError(t, registry.AddTool(&core.ToolManifest{Name: name, Path: "/bin/" + name}))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := range toolsPerWriter {
+				tools := registry.ListTools()
+				// The returned slice is the caller's own copy
+				if len(tools) > 0 {
+					tools[0] = nil
+				}
+				_, _ = registry.GetTool(fmt.Sprintf("tool-%d-%d", w, i))
+				_ = registry.Len()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, writers*toolsPerWriter, registry.Len())
+	for _, tool := range registry.ListTools() {
+		assert.NotNil(t, tool)
+	}
+}
+
+// TestToolsRegistry_AddTool_ZeroValue tests that tools can be added to a zero-value registry
+func TestToolsRegistry_AddTool_ZeroValue(t *testing.T) {
+	var registry ToolsRegistry
+	require.NoError(t, registry.AddTool(&core.ToolManifest{Name: "tool"}))
+	assert.Equal(t, 1, registry.Len())
+}
+
 // TestNewToolsRegistryFromDirectory_EmptyDirectory tests creating a registry from an empty directory
 func TestNewToolsRegistryFromDirectory_EmptyDirectory(t *testing.T) {
 	tmpDir := t.TempDir()
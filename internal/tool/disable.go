@@ -0,0 +1,86 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/registry"
+	"github.com/dorcha-inc/orla/internal/server"
+	"github.com/dorcha-inc/orla/internal/state"
+)
+
+// adminRequestTimeout bounds how long orla tool disable/enable waits for a running server
+const adminRequestTimeout = 5 * time.Second
+
+// ServerURL returns the base URL of an orla server listening on the given port
+func ServerURL(port int) string {
+	return fmt.Sprintf("http://localhost:%d", port)
+}
+
+// SetToolDisabled disables or enables a tool without uninstalling it. The change is saved to the
+// disabled tools state file, so it survives server restarts and reloads, and is applied to the
+// orla server running at serverURL, if one is reachable.
+func SetToolDisabled(toolName string, disabled bool, serverURL string) error {
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	action, verb := server.AdminToolEnableAction, "Enabled"
+	if disabled {
+		action, verb = server.AdminToolDisableAction, "Disabled"
+
+		// Only check that the tool exists when disabling, so that a tool which has since been
+		// removed can still be enabled to clean up the state file
+		if _, err := cfg.ToolsRegistry.GetTool(toolName); err != nil {
+			return fmt.Errorf("failed to disable tool '%s': %w", toolName, err)
+		}
+	}
+
+	disabledToolsPath, err := registry.GetDisabledToolsPath()
+	if err != nil {
+		return err
+	}
+	if _, err := state.SetToolDisabled(disabledToolsPath, toolName, disabled); err != nil {
+		return fmt.Errorf("failed to update disabled tools: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), adminRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, serverURL+server.AdminToolPath(toolName, action), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if cfg.AdminToken != "" {
+		req.Header.Set("Authorization", server.AdminAuthorization(cfg.AdminToken))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		core.MustFprintf(os.Stdout, "%s tool '%s'\n", verb, toolName)
+		core.MustFprintf(os.Stdout, "No running orla server reached at %s, the change takes effect when the server starts or reloads.\n", serverURL)
+		return nil
+	}
+	defer core.LogDeferredError(resp.Body.Close)
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		core.MustFprintf(os.Stdout, "%s tool '%s' on the running orla server\n", verb, toolName)
+	case http.StatusNotFound:
+		core.MustFprintf(os.Stdout, "%s tool '%s'\n", verb, toolName)
+		core.MustFprintf(os.Stdout, "The running orla server does not have this tool, the change takes effect when it is added.\n")
+	case http.StatusUnauthorized, http.StatusForbidden:
+		core.MustFprintf(os.Stdout, "%s tool '%s'\n", verb, toolName)
+		core.MustFprintf(os.Stdout, "The orla server at %s refused the admin request (status %d), the change takes effect when it starts or reloads. Set admin_enabled, and admin_token if the server is not on this machine, to apply changes at once.\n", serverURL, resp.StatusCode)
+	default:
+		return fmt.Errorf("saved the change, but the orla server at %s returned status %d", serverURL, resp.StatusCode)
+	}
+
+	return nil
+}
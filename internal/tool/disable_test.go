@@ -0,0 +1,142 @@
+package tool
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/registry"
+	"github.com/dorcha-inc/orla/internal/state"
+)
+
+// setupDisableTest creates a project config with a single tool named greet, changes into its
+// directory, and returns the path of the disabled tools state file
+func setupDisableTest(t *testing.T) string {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	t.Setenv(registry.OrlaHomeEnvVar, filepath.Join(tmpDir, "orla-home"))
+
+	configContent := `
+tools_registry:
+  tools:
+    greet:
+      name: greet
+      description: Greets someone
+      command: echo hello
+`
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "orla.yaml"), []byte(configContent), 0644))
+
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError1(os.Chdir, originalDir) })
+	require.NoError(t, os.Chdir(tmpDir))
+
+	disabledToolsPath, err := registry.GetDisabledToolsPath()
+	require.NoError(t, err)
+	return disabledToolsPath
+}
+
+func TestSetToolDisabled_NotifiesRunningServer(t *testing.T) {
+	disabledToolsPath := setupDisableTest(t)
+
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	require.NoError(t, SetToolDisabled("greet", true, srv.URL))
+	disabledTools, err := state.LoadDisabledTools(disabledToolsPath)
+	require.NoError(t, err)
+	assert.True(t, disabledTools.Contains("greet"))
+
+	require.NoError(t, SetToolDisabled("greet", false, srv.URL))
+	disabledTools, err = state.LoadDisabledTools(disabledToolsPath)
+	require.NoError(t, err)
+	assert.False(t, disabledTools.Contains("greet"))
+
+	assert.Equal(t, []string{
+		"POST /admin/tools/greet/disable",
+		"POST /admin/tools/greet/enable",
+	}, requests)
+}
+
+func TestSetToolDisabled_NoRunningServer(t *testing.T) {
+	disabledToolsPath := setupDisableTest(t)
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	serverURL := srv.URL
+	srv.Close()
+
+	// The change is still saved so that it applies when the server next starts
+	require.NoError(t, SetToolDisabled("greet", true, serverURL))
+	disabledTools, err := state.LoadDisabledTools(disabledToolsPath)
+	require.NoError(t, err)
+	assert.True(t, disabledTools.Contains("greet"))
+}
+
+func TestSetToolDisabled_ServerError(t *testing.T) {
+	setupDisableTest(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	err := SetToolDisabled("greet", true, srv.URL)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 500")
+}
+
+func TestSetToolDisabled_UnknownTool(t *testing.T) {
+	disabledToolsPath := setupDisableTest(t)
+
+	err := SetToolDisabled("missing", true, ServerURL(0))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tool not found")
+	assert.NoFileExists(t, disabledToolsPath)
+}
+
+func TestSetToolDisabled_AdminToken(t *testing.T) {
+	setupDisableTest(t)
+	// #nosec G302 -- test file permissions are acceptable for temporary test files
+	f, err := os.OpenFile("orla.yaml", os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("admin_token: s3cret\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	var authorization string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	require.NoError(t, SetToolDisabled("greet", true, srv.URL))
+	assert.Equal(t, "Bearer s3cret", authorization)
+}
+
+func TestSetToolDisabled_AdminRefused(t *testing.T) {
+	disabledToolsPath := setupDisableTest(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "admin endpoints are disabled", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	// The change is saved and applies when the server next starts or reloads
+	require.NoError(t, SetToolDisabled("greet", true, srv.URL))
+	disabledTools, err := state.LoadDisabledTools(disabledToolsPath)
+	require.NoError(t, err)
+	assert.True(t, disabledTools.Contains("greet"))
+}
@@ -0,0 +1,160 @@
+package tool
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+)
+
+const (
+	// DocsFormatMarkdown renders the tool catalog as a markdown page
+	DocsFormatMarkdown = "markdown"
+	// DocsFormatJSON renders the tool catalog as a JSON array of tool docs
+	DocsFormatJSON = "json"
+)
+
+// ExportDocsOptions configures the output of the tool catalog docs
+type ExportDocsOptions struct {
+	Format string
+	Writer io.Writer
+}
+
+// ToolDoc describes a tool for the generated tool catalog
+type ToolDoc struct {
+	Name         string         `json:"name"`
+	Version      string         `json:"version,omitempty"`
+	Description  string         `json:"description"`
+	RuntimeMode  string         `json:"runtime_mode"`
+	Keywords     []string       `json:"keywords,omitempty"`
+	Repository   string         `json:"repository,omitempty"`
+	Homepage     string         `json:"homepage,omitempty"`
+	InputSchema  map[string]any `json:"input_schema,omitempty"`
+	OutputSchema map[string]any `json:"output_schema,omitempty"`
+	// Examples are sample inputs, taken from the `examples` keyword of the input schema
+	Examples []any `json:"examples,omitempty"`
+}
+
+// newToolDoc builds the catalog entry of a tool from its manifest
+func newToolDoc(tool *core.ToolManifest) ToolDoc {
+	doc := ToolDoc{
+		Name:        tool.Name,
+		Version:     tool.Version,
+		Description: tool.Description,
+		RuntimeMode: string(core.RuntimeModeSimple),
+		Keywords:    tool.Keywords,
+		Repository:  tool.Repository,
+		Homepage:    tool.Homepage,
+	}
+	if tool.Runtime != nil && tool.Runtime.Mode != "" {
+		doc.RuntimeMode = string(tool.Runtime.Mode)
+	}
+	if tool.MCP != nil {
+		doc.InputSchema = tool.MCP.InputSchema
+		doc.OutputSchema = tool.MCP.OutputSchema
+		if examples, ok := tool.MCP.InputSchema["examples"].([]any); ok {
+			doc.Examples = examples
+		}
+	}
+	return doc
+}
+
+// ExportDocs writes documentation for every tool the orla server would register, in the
+// requested format, for generating a tool catalog page
+func ExportDocs(opts ExportDocsOptions) error {
+	if opts.Writer == nil {
+		opts.Writer = os.Stdout
+	}
+	if opts.Format == "" {
+		opts.Format = DocsFormatMarkdown
+	}
+	if opts.Format != DocsFormatMarkdown && opts.Format != DocsFormatJSON {
+		return fmt.Errorf("unsupported docs format %q (supported: %s, %s)", opts.Format, DocsFormatMarkdown, DocsFormatJSON)
+	}
+
+	// Load config to get the tools registry (handles project > user > default precedence)
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	docs := []ToolDoc{}
+	if cfg.ToolsRegistry != nil {
+		for _, tool := range cfg.ToolsRegistry.ListTools() {
+			docs = append(docs, newToolDoc(tool))
+		}
+	}
+	slices.SortFunc(docs, func(a, b ToolDoc) int { return strings.Compare(a.Name, b.Name) })
+
+	if opts.Format == DocsFormatJSON {
+		encoder := json.NewEncoder(opts.Writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(docs)
+	}
+
+	return writeMarkdownDocs(opts.Writer, docs)
+}
+
+// writeMarkdownDocs renders the tool catalog as markdown, one section per tool
+func writeMarkdownDocs(w io.Writer, docs []ToolDoc) error {
+	core.MustFprintf(w, "# Tool Catalog\n")
+
+	if len(docs) == 0 {
+		core.MustFprintf(w, "\nNo tools installed.\n")
+		return nil
+	}
+
+	for _, doc := range docs {
+		core.MustFprintf(w, "\n## %s\n\n", doc.Name)
+		if doc.Description != "" {
+			core.MustFprintf(w, "%s\n\n", strings.TrimSpace(doc.Description))
+		}
+
+		if doc.Version != "" {
+			core.MustFprintf(w, "- **Version:** %s\n", doc.Version)
+		}
+		core.MustFprintf(w, "- **Runtime mode:** %s\n", doc.RuntimeMode)
+		if len(doc.Keywords) > 0 {
+			core.MustFprintf(w, "- **Keywords:** %s\n", strings.Join(doc.Keywords, ", "))
+		}
+		if doc.Repository != "" {
+			core.MustFprintf(w, "- **Repository:** %s\n", doc.Repository)
+		}
+		if doc.Homepage != "" {
+			core.MustFprintf(w, "- **Homepage:** %s\n", doc.Homepage)
+		}
+
+		if doc.InputSchema != nil {
+			if err := writeMarkdownJSONBlock(w, "Input schema", doc.InputSchema); err != nil {
+				return fmt.Errorf("failed to render input schema of %s: %w", doc.Name, err)
+			}
+		}
+		if doc.OutputSchema != nil {
+			if err := writeMarkdownJSONBlock(w, "Output schema", doc.OutputSchema); err != nil {
+				return fmt.Errorf("failed to render output schema of %s: %w", doc.Name, err)
+			}
+		}
+		for i, example := range doc.Examples {
+			if err := writeMarkdownJSONBlock(w, fmt.Sprintf("Example %d", i+1), example); err != nil {
+				return fmt.Errorf("failed to render example of %s: %w", doc.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeMarkdownJSONBlock writes a titled subsection holding value as a fenced JSON code block
+func writeMarkdownJSONBlock(w io.Writer, title string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	core.MustFprintf(w, "\n### %s\n\n```json\n%s\n```\n", title, data)
+	return nil
+}
@@ -0,0 +1,136 @@
+package tool
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// setupDocsTools installs a tool with schemas and examples, and a flat executable tool, in toolsDir
+func setupDocsTools(t *testing.T, toolsDir string) {
+	t.Helper()
+
+	toolDir := filepath.Join(toolsDir, "weather", "1.2.0")
+	// #nosec G301 -- test directory permissions are acceptable for temporary test files
+	require.NoError(t, os.MkdirAll(filepath.Join(toolDir, "bin"), 0755))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(toolDir, "bin", "weather"), []byte("#!/bin/sh\necho sunny\n"), 0755))
+
+	manifest := &core.ToolManifest{
+		Name:        "weather",
+		Version:     "1.2.0",
+		Description: "Look up the weather forecast",
+		Entrypoint:  "bin/weather",
+		Keywords:    []string{"weather", "forecast"},
+		Homepage:    "https://example.com/weather",
+		MCP: &core.MCPConfig{
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"city": map[string]any{"type": "string"},
+				},
+				"required": []any{"city"},
+				"examples": []any{map[string]any{"city": "Dublin"}},
+			},
+			OutputSchema: map[string]any{"type": "string"},
+		},
+		Runtime: &core.RuntimeConfig{Mode: core.RuntimeModeCapsule},
+	}
+	manifestData, err := yaml.Marshal(manifest)
+	require.NoError(t, err)
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(toolDir, "tool.yaml"), manifestData, 0644))
+
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(toolsDir, "hello.sh"), []byte("#!/bin/sh\necho hello\n"), 0755))
+}
+
+func TestExportDocs_JSON(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping executable discovery test on Windows")
+	}
+
+	_, toolsDir, cleanup := setupTestConfig(t)
+	defer cleanup()
+	setupDocsTools(t, toolsDir)
+
+	var buf bytes.Buffer
+	require.NoError(t, ExportDocs(ExportDocsOptions{Format: DocsFormatJSON, Writer: &buf}))
+
+	var docs []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &docs))
+	require.Len(t, docs, 2)
+
+	// Tools are sorted by name
+	hello := docs[0]
+	assert.Equal(t, "hello", hello["name"])
+	assert.Equal(t, "simple", hello["runtime_mode"])
+	assert.NotContains(t, hello, "input_schema")
+	assert.NotContains(t, hello, "examples")
+
+	weather := docs[1]
+	assert.Equal(t, "weather", weather["name"])
+	assert.Equal(t, "1.2.0", weather["version"])
+	assert.Equal(t, "Look up the weather forecast", weather["description"])
+	assert.Equal(t, "capsule", weather["runtime_mode"])
+	assert.Equal(t, []any{"weather", "forecast"}, weather["keywords"])
+	assert.Equal(t, "https://example.com/weather", weather["homepage"])
+	inputSchema, ok := weather["input_schema"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, []any{"city"}, inputSchema["required"])
+	assert.Equal(t, map[string]any{"type": "string"}, weather["output_schema"])
+	assert.Equal(t, []any{map[string]any{"city": "Dublin"}}, weather["examples"])
+}
+
+func TestExportDocs_Markdown(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping executable discovery test on Windows")
+	}
+
+	_, toolsDir, cleanup := setupTestConfig(t)
+	defer cleanup()
+	setupDocsTools(t, toolsDir)
+
+	var buf bytes.Buffer
+	require.NoError(t, ExportDocs(ExportDocsOptions{Writer: &buf}))
+
+	output := buf.String()
+	assert.Contains(t, output, "# Tool Catalog\n")
+	assert.Contains(t, output, "\n## hello\n")
+	assert.Contains(t, output, "\n## weather\n\nLook up the weather forecast\n")
+	assert.Contains(t, output, "- **Version:** 1.2.0\n")
+	assert.Contains(t, output, "- **Runtime mode:** capsule\n")
+	assert.Contains(t, output, "- **Keywords:** weather, forecast\n")
+	assert.Contains(t, output, "### Input schema\n\n```json\n")
+	assert.Contains(t, output, `"required": [`)
+	assert.Contains(t, output, "### Output schema\n\n```json\n{\n  \"type\": \"string\"\n}\n```\n")
+	assert.Contains(t, output, "### Example 1\n\n```json\n{\n  \"city\": \"Dublin\"\n}\n```\n")
+	assert.Less(t, bytes.Index(buf.Bytes(), []byte("## hello")), bytes.Index(buf.Bytes(), []byte("## weather")))
+}
+
+func TestExportDocs_NoTools(t *testing.T) {
+	_, _, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	var buf bytes.Buffer
+	require.NoError(t, ExportDocs(ExportDocsOptions{Format: DocsFormatJSON, Writer: &buf}))
+	assert.Equal(t, "[]\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, ExportDocs(ExportDocsOptions{Format: DocsFormatMarkdown, Writer: &buf}))
+	assert.Contains(t, buf.String(), "No tools installed.")
+}
+
+func TestExportDocs_UnsupportedFormat(t *testing.T) {
+	err := ExportDocs(ExportDocsOptions{Format: "html", Writer: &bytes.Buffer{}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported docs format "html"`)
+}
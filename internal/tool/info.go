@@ -4,23 +4,40 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/dorcha-inc/orla/internal/config"
 	"github.com/dorcha-inc/orla/internal/core"
 	"github.com/dorcha-inc/orla/internal/installer"
+	"github.com/dorcha-inc/orla/internal/registry"
 	"go.uber.org/zap"
 	"golang.org/x/mod/semver"
 )
 
 // InfoOptions configures the output format for tool info
 type InfoOptions struct {
-	JSON   bool
-	Writer io.Writer
+	JSON bool
+	// Registry shows the tool's registry entry even if the tool is installed
+	Registry bool
+	// RegistryURL is the registry tools are looked up in (default: default_registry from config)
+	RegistryURL string
+	Writer      io.Writer
 }
 
-// GetToolInfo retrieves and displays detailed information about an installed tool
+// RegistryToolInfo is the information shown for a tool that is looked up in the registry
+type RegistryToolInfo struct {
+	registry.ToolEntry
+	Registry string   `json:"registry"`
+	Versions []string `json:"versions"`
+}
+
+// GetToolInfo retrieves and displays detailed information about a tool. An installed tool is
+// shown from its manifest; a tool that is not installed, or any tool if opts.Registry is set, is
+// shown from its registry entry along with the versions it can be installed at.
 func GetToolInfo(toolName string, opts InfoOptions) error {
 	if opts.Writer == nil {
 		opts.Writer = os.Stdout
@@ -31,19 +48,35 @@ func GetToolInfo(toolName string, opts InfoOptions) error {
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	if opts.RegistryURL == "" {
+		opts.RegistryURL = cfg.DefaultRegistry
+	}
+
+	if opts.Registry {
+		return showRegistryToolInfo(toolName, opts)
+	}
+
 	if cfg.ToolsDir == "" {
 		return fmt.Errorf("tools directory not configured")
 	}
-	installDir := cfg.ToolsDir
 
-	// Find the tool directory (use latest version if multiple exist)
-	toolBaseDir := filepath.Join(installDir, toolName)
+	// Find the tool directory (use the active version, or the latest if multiple exist)
+	toolBaseDir := filepath.Join(cfg.ToolsDir, toolName)
 	if _, err := os.Stat(toolBaseDir); os.IsNotExist(err) {
-		return fmt.Errorf("tool '%s' is not installed", toolName)
+		zap.L().Debug("Tool is not installed, looking it up in the registry", zap.String("tool", toolName))
+		if errRegistry := showRegistryToolInfo(toolName, opts); errRegistry != nil {
+			return fmt.Errorf("tool '%s' is not installed: %w", toolName, errRegistry)
+		}
+		return nil
 	}
 
-	// Find the latest version
-	toolDir, version, err := findLatestToolVersion(toolBaseDir)
+	return showInstalledToolInfo(toolBaseDir, opts)
+}
+
+// showInstalledToolInfo displays the manifest of the active installed version of a tool, which is
+// the latest unless another version was made active with --replace
+func showInstalledToolInfo(toolBaseDir string, opts InfoOptions) error {
+	toolDir, version, err := findActiveToolVersion(toolBaseDir)
 	if err != nil {
 		return fmt.Errorf("failed to find tool version: %w", err)
 	}
@@ -84,7 +117,16 @@ func GetToolInfo(toolName string, opts InfoOptions) error {
 		core.MustFprintf(opts.Writer, "Homepage:    %s\n", manifest.Homepage)
 	}
 
-	core.MustFprintf(opts.Writer, "Entrypoint:  %s\n", manifest.Entrypoint)
+	core.MustFprintf(opts.Writer, "Stability:   %s\n", core.StabilityOf(manifest))
+
+	if core.IsPackageTool(manifest) {
+		core.MustFprintf(opts.Writer, "Package:     %s\n", manifest.Runtime.Package)
+	} else {
+		core.MustFprintf(opts.Writer, "Entrypoint:  %s\n", manifest.Entrypoint)
+	}
+	if manifest.Interpreter != "" {
+		core.MustFprintf(opts.Writer, "Interpreter: %s\n", manifest.Interpreter)
+	}
 	core.MustFprintf(opts.Writer, "Path:        %s\n", toolDir)
 
 	if len(manifest.Keywords) > 0 {
@@ -98,11 +140,23 @@ func GetToolInfo(toolName string, opts InfoOptions) error {
 		}
 	}
 
+	if len(manifest.Env) > 0 || len(manifest.EnvPassthrough) > 0 {
+		core.MustFprintf(opts.Writer, "Environment: restricted, passes through %v\n", manifest.EnvPassthrough)
+		for _, key := range slices.Sorted(maps.Keys(manifest.Env)) {
+			core.MustFprintf(opts.Writer, "  %s=%s\n", key, manifest.Env[key])
+		}
+	}
+
 	// Note: Permissions field may not exist in current ToolManifest struct
 	// This is a placeholder for future RFC 3 permissions support
 
+	runtimeMode := core.RuntimeModeSimple
+	if manifest.Runtime != nil && manifest.Runtime.Mode != "" {
+		runtimeMode = manifest.Runtime.Mode
+	}
+	core.MustFprintf(opts.Writer, "Runtime Mode: %s\n", runtimeMode)
+
 	if manifest.Runtime != nil {
-		core.MustFprintf(opts.Writer, "Runtime Mode: %s\n", manifest.Runtime.Mode)
 		if len(manifest.Runtime.Env) > 0 {
 			core.MustFprintf(opts.Writer, "Environment Variables:\n")
 			for k, v := range manifest.Runtime.Env {
@@ -114,9 +168,129 @@ func GetToolInfo(toolName string, opts InfoOptions) error {
 		}
 	}
 
+	if manifest.MCP != nil {
+		printSchemaSummary(opts.Writer, "Input Schema", manifest.MCP.InputSchema)
+		printSchemaSummary(opts.Writer, "Output Schema", manifest.MCP.OutputSchema)
+	}
+
 	return nil
 }
 
+// printSchemaSummary prints the properties a JSON schema declares, one per line with their type
+// and whether they are required, e.g. "  - path (string, required): File to read"
+func printSchemaSummary(w io.Writer, title string, schema map[string]any) {
+	if len(schema) == 0 {
+		return
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	if len(properties) == 0 {
+		schemaType, _ := schema["type"].(string)
+		core.MustFprintf(w, "%s: %s\n", title, schemaType)
+		return
+	}
+
+	required := make(map[string]bool)
+	if names, ok := schema["required"].([]any); ok {
+		for _, name := range names {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	core.MustFprintf(w, "%s:\n", title)
+	for _, name := range slices.Sorted(maps.Keys(properties)) {
+		property, _ := properties[name].(map[string]any)
+		details := make([]string, 0, 2)
+		if propertyType, ok := property["type"].(string); ok {
+			details = append(details, propertyType)
+		}
+		if required[name] {
+			details = append(details, "required")
+		}
+
+		line := "  - " + name
+		if len(details) > 0 {
+			line += " (" + strings.Join(details, ", ") + ")"
+		}
+		if description, ok := property["description"].(string); ok && description != "" {
+			line += ": " + description
+		}
+		core.MustFprintf(w, "%s\n", line)
+	}
+}
+
+// showRegistryToolInfo displays the registry entry of a tool and the versions it can be installed at
+func showRegistryToolInfo(toolName string, opts InfoOptions) error {
+	reg, err := registry.FetchRegistry(opts.RegistryURL, true)
+	if err != nil {
+		return fmt.Errorf("failed to fetch registry: %w", err)
+	}
+
+	tool, err := registry.FindTool(reg, toolName)
+	if err != nil {
+		if suggestion := registry.SuggestSimilarToolName(reg, toolName); suggestion != "" {
+			return fmt.Errorf("tool '%s' not found in registry %s. Did you mean: %s?", toolName, opts.RegistryURL, suggestion)
+		}
+		return fmt.Errorf("tool '%s' not found in registry %s", toolName, opts.RegistryURL)
+	}
+
+	versions, err := registry.AvailableVersions(tool, true)
+	if err != nil {
+		return fmt.Errorf("failed to list versions of tool '%s': %w", toolName, err)
+	}
+
+	if opts.JSON {
+		encoder := json.NewEncoder(opts.Writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(&RegistryToolInfo{ToolEntry: *tool, Registry: opts.RegistryURL, Versions: versions})
+	}
+
+	core.MustFprintf(opts.Writer, "Name:        %s\n", tool.Name)
+	core.MustFprintf(opts.Writer, "Description: %s\n", tool.Description)
+	core.MustFprintf(opts.Writer, "Stability:   %s\n", registryStability(tool))
+	if tool.Maintainer != "" {
+		core.MustFprintf(opts.Writer, "Maintainer:  %s\n", tool.Maintainer)
+	}
+	if tool.Package != "" {
+		core.MustFprintf(opts.Writer, "Package:     %s (%s)\n", tool.Package, tool.Mode)
+	} else {
+		core.MustFprintf(opts.Writer, "Repository:  %s\n", tool.Repository)
+	}
+	if len(tool.Keywords) > 0 {
+		core.MustFprintf(opts.Writer, "Keywords:    %v\n", tool.Keywords)
+	}
+	core.MustFprintf(opts.Writer, "Registry:    %s\n", opts.RegistryURL)
+
+	if len(versions) == 0 {
+		core.MustFprintf(opts.Writer, "Versions:    none\n")
+		return nil
+	}
+	core.MustFprintf(opts.Writer, "Versions:\n")
+	for _, version := range versions {
+		core.MustFprintf(opts.Writer, "  - %s\n", version)
+	}
+	return nil
+}
+
+// registryStability returns the stability of a registry entry, which is stable unless it says otherwise
+func registryStability(tool *registry.ToolEntry) core.ToolStability {
+	if tool.Stability == "" {
+		return core.ToolStabilityStable
+	}
+	return tool.Stability
+}
+
+// findActiveToolVersion finds the version of a tool its active version link points at, or the
+// latest version if it has none
+func findActiveToolVersion(toolBaseDir string) (toolDir string, version string, err error) {
+	if active := installer.ActiveVersion(toolBaseDir); active != "" {
+		return filepath.Join(toolBaseDir, active), active, nil
+	}
+	return findLatestToolVersion(toolBaseDir)
+}
+
 // findLatestToolVersion finds the latest version of a tool in the tool base directory
 func findLatestToolVersion(toolBaseDir string) (toolDir string, version string, err error) {
 	entries, err := os.ReadDir(toolBaseDir)
@@ -11,30 +11,47 @@ import (
 
 	"github.com/dorcha-inc/orla/internal/core"
 	"github.com/dorcha-inc/orla/internal/installer"
+	"github.com/dorcha-inc/orla/internal/registry"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 )
 
 func TestGetToolInfo_NotInstalled(t *testing.T) {
-	_, _, cleanup := setupTestConfig(t)
+	tmpDir, _, cleanup := setupTestConfig(t)
 	defer cleanup()
+	setupTestRegistry(t, tmpDir, []registry.ToolEntry{
+		{Name: "filesystem", Description: "File system operations", Repository: "https://example.com/orla-tool-filesystem"},
+	})
 
 	var buf bytes.Buffer
 	err := GetToolInfo("nonexistent-tool", InfoOptions{
-		Writer: &buf,
+		RegistryURL: getTestRegistryURL(),
+		Writer:      &buf,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tool 'nonexistent-tool' is not installed")
+	assert.Contains(t, err.Error(), "not found in registry "+getTestRegistryURL())
+	assert.Empty(t, buf.String())
+
+	// A close match in the registry is suggested
+	err = GetToolInfo("filesystm", InfoOptions{
+		RegistryURL: getTestRegistryURL(),
+		Writer:      &buf,
 	})
 	require.Error(t, err)
-	assert.Contains(t, err.Error(), "is not installed")
+	assert.Contains(t, err.Error(), "Did you mean: filesystem?")
 }
 
 func TestGetToolInfo_DefaultWriter(t *testing.T) {
-	_, _, cleanup := setupTestConfig(t)
+	tmpDir, _, cleanup := setupTestConfig(t)
 	defer cleanup()
+	setupTestRegistry(t, tmpDir, nil)
 
 	// Test that nil writer defaults to os.Stdout
 	err := GetToolInfo("nonexistent-tool", InfoOptions{
-		Writer: nil, // Should default to os.Stdout
+		RegistryURL: getTestRegistryURL(),
+		Writer:      nil, // Should default to os.Stdout
 	})
 	// We expect an error, but the important thing is it didn't panic
 	assert.Error(t, err)
@@ -158,6 +175,35 @@ func TestGetToolInfo_WithRuntime(t *testing.T) {
 	assert.Contains(t, output, "Arguments:")
 }
 
+func TestGetToolInfo_WithEnvAllowlist(t *testing.T) {
+	_, toolsDir, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	toolDir := filepath.Join(toolsDir, "test-tool", "1.0.0")
+	// #nosec G301 -- test directory permissions are acceptable for temporary test files
+	require.NoError(t, os.MkdirAll(toolDir, 0755))
+
+	toolManifest := &core.ToolManifest{
+		Name:           "test-tool",
+		Version:        "1.0.0",
+		Description:    "A test tool",
+		Entrypoint:     "bin/tool",
+		Env:            map[string]string{"REGION": "eu-west-1"},
+		EnvPassthrough: []string{"PATH", "HOME"},
+	}
+	manifestData, err := yaml.Marshal(toolManifest)
+	require.NoError(t, err)
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(toolDir, installer.ToolManifestFileName), manifestData, 0644))
+
+	var buf bytes.Buffer
+	require.NoError(t, GetToolInfo("test-tool", InfoOptions{Writer: &buf}))
+
+	output := buf.String()
+	assert.Contains(t, output, "Environment: restricted, passes through [PATH HOME]\n")
+	assert.Contains(t, output, "  REGION=eu-west-1\n")
+}
+
 func TestGetToolInfo_JSON(t *testing.T) {
 	_, toolsDir, cleanup := setupTestConfig(t)
 	defer cleanup()
@@ -454,3 +500,144 @@ func TestGetToolInfo_JSON_WithHotLoad(t *testing.T) {
 	hotLoadStr := strings.ToLower(strings.TrimSpace(fmt.Sprintf("%v", info.Runtime.HotLoad)))
 	assert.Contains(t, hotLoadStr, "restart")
 }
+
+// useInfoTestTags makes the registry list tags for tool repositories without running git
+func useInfoTestTags(t *testing.T, tags ...string) {
+	t.Helper()
+	originalRunner := registry.GetDefaultGitRunner()
+	registry.SetGitRunner(&registry.MockGitRunner{
+		ListTagsFunc: func(repoURL string) ([]string, error) {
+			return tags, nil
+		},
+	})
+	t.Cleanup(func() { registry.SetGitRunner(originalRunner) })
+}
+
+func TestGetToolInfo_InstalledManifestDetails(t *testing.T) {
+	_, toolsDir, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	toolDir := filepath.Join(toolsDir, "read-file", "0.2.0")
+	// #nosec G301 -- test directory permissions are acceptable for temporary test files
+	require.NoError(t, os.MkdirAll(toolDir, 0755))
+
+	toolManifest := &core.ToolManifest{
+		Name:        "read-file",
+		Version:     "0.2.0",
+		Description: "Reads a file",
+		Entrypoint:  "read.py",
+		Interpreter: "/usr/bin/env python3",
+		Stability:   core.ToolStabilityExperimental,
+		MCP: &core.MCPConfig{
+			InputSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"path":  map[string]any{"type": "string", "description": "File to read"},
+					"lines": map[string]any{"type": "integer"},
+				},
+				"required": []any{"path"},
+			},
+			OutputSchema: map[string]any{"type": "string"},
+		},
+	}
+	manifestData, err := yaml.Marshal(toolManifest)
+	require.NoError(t, err)
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(toolDir, installer.ToolManifestFileName), manifestData, 0644))
+
+	var buf bytes.Buffer
+	require.NoError(t, GetToolInfo("read-file", InfoOptions{Writer: &buf}))
+
+	output := buf.String()
+	assert.Contains(t, output, "Stability:   experimental")
+	assert.Contains(t, output, "Interpreter: /usr/bin/env python3")
+	assert.Contains(t, output, "Path:        "+toolDir)
+	assert.Contains(t, output, "Runtime Mode: simple")
+	assert.Contains(t, output, "Input Schema:\n  - lines (integer)\n  - path (string, required): File to read\n")
+	assert.Contains(t, output, "Output Schema: string\n")
+	assert.NotContains(t, output, "Versions:", "an installed tool is shown without looking it up in the registry")
+}
+
+func TestGetToolInfo_RegistryOnly(t *testing.T) {
+	tmpDir, _, cleanup := setupTestConfig(t)
+	defer cleanup()
+	setupTestRegistry(t, tmpDir, []registry.ToolEntry{
+		{
+			Name:        "filesystem",
+			Description: "File system operations",
+			Repository:  "https://example.com/orla-tool-filesystem",
+			Maintainer:  "orla",
+			Keywords:    []string{"files"},
+		},
+	})
+	useInfoTestTags(t, "v0.1.0", "v0.10.0", "v0.2.0", "nightly")
+
+	var buf bytes.Buffer
+	require.NoError(t, GetToolInfo("filesystem", InfoOptions{RegistryURL: getTestRegistryURL(), Writer: &buf}))
+
+	output := buf.String()
+	assert.Contains(t, output, "Name:        filesystem")
+	assert.Contains(t, output, "Description: File system operations")
+	assert.Contains(t, output, "Stability:   stable")
+	assert.Contains(t, output, "Maintainer:  orla")
+	assert.Contains(t, output, "Repository:  https://example.com/orla-tool-filesystem")
+	assert.Contains(t, output, "Registry:    "+getTestRegistryURL())
+	assert.Contains(t, output, "Versions:\n  - v0.10.0\n  - v0.2.0\n  - v0.1.0\n")
+	assert.NotContains(t, output, "nightly")
+
+	buf.Reset()
+	require.NoError(t, GetToolInfo("filesystem", InfoOptions{RegistryURL: getTestRegistryURL(), JSON: true, Writer: &buf}))
+
+	var info RegistryToolInfo
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &info))
+	assert.Equal(t, "filesystem", info.Name)
+	assert.Equal(t, getTestRegistryURL(), info.Registry)
+	assert.Equal(t, []string{"v0.10.0", "v0.2.0", "v0.1.0"}, info.Versions)
+}
+
+func TestGetToolInfo_RegistryPackageTool(t *testing.T) {
+	tmpDir, _, cleanup := setupTestConfig(t)
+	defer cleanup()
+	setupTestRegistry(t, tmpDir, []registry.ToolEntry{
+		{Name: "mcp-filesystem", Description: "Filesystem MCP server", Package: "@modelcontextprotocol/server-filesystem", Mode: core.RuntimeModeNpx, Version: "2025.1.0"},
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, GetToolInfo("mcp-filesystem", InfoOptions{RegistryURL: getTestRegistryURL(), Writer: &buf}))
+
+	output := buf.String()
+	assert.Contains(t, output, "Package:     @modelcontextprotocol/server-filesystem (npx)")
+	assert.Contains(t, output, "Versions:\n  - 2025.1.0\n")
+}
+
+func TestGetToolInfo_ForceRegistry(t *testing.T) {
+	tmpDir, toolsDir, cleanup := setupTestConfig(t)
+	defer cleanup()
+	setupTestRegistry(t, tmpDir, []registry.ToolEntry{
+		{Name: "test-tool", Description: "A test tool from the registry", Repository: "https://example.com/orla-tool-test"},
+	})
+	useInfoTestTags(t, "v1.0.0", "v1.1.0")
+
+	toolDir := filepath.Join(toolsDir, "test-tool", "1.0.0")
+	// #nosec G301 -- test directory permissions are acceptable for temporary test files
+	require.NoError(t, os.MkdirAll(toolDir, 0755))
+	manifestData, err := yaml.Marshal(&core.ToolManifest{Name: "test-tool", Version: "1.0.0", Description: "A test tool", Entrypoint: "bin/tool"})
+	require.NoError(t, err)
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(toolDir, installer.ToolManifestFileName), manifestData, 0644))
+
+	var buf bytes.Buffer
+	require.NoError(t, GetToolInfo("test-tool", InfoOptions{Registry: true, RegistryURL: getTestRegistryURL(), Writer: &buf}))
+
+	output := buf.String()
+	assert.Contains(t, output, "Description: A test tool from the registry")
+	assert.Contains(t, output, "Versions:\n  - v1.1.0\n  - v1.0.0\n")
+	assert.NotContains(t, output, "Path:")
+
+	// A tool missing from the registry is reported as such, even if it is installed
+	setupTestRegistry(t, tmpDir, nil)
+	err = GetToolInfo("test-tool", InfoOptions{Registry: true, RegistryURL: getTestRegistryURL(), Writer: &buf})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tool 'test-tool' not found in registry")
+	assert.NotContains(t, err.Error(), "is not installed")
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/dorcha-inc/orla/internal/config"
 	"github.com/dorcha-inc/orla/internal/core"
@@ -15,8 +17,20 @@ import (
 type InstallOptions struct {
 	RegistryURL string
 	Version     string
-	LocalPath   string
-	Writer      io.Writer
+	// Ref installs the tool at a git branch or commit instead of a version, see installer.InstallToolAtRef
+	Ref       string
+	LocalPath string
+	// ToolsDir installs into this directory instead of the configured tools_dir
+	ToolsDir string
+	// Refresh fetches the registry index and tool tags fresh instead of using the cache
+	Refresh bool
+	// Offline uses only the cached registry and tool tags and never clones, as does offline in the config
+	Offline bool
+	// Replace makes the installed version the tool's active version once the install succeeded
+	Replace bool
+	Writer  io.Writer
+	// Progress receives install progress events (default: plain text on Writer)
+	Progress installer.ProgressReporter
 }
 
 // InstallTool installs a tool from the registry or local path
@@ -30,23 +44,43 @@ func InstallTool(toolName string, opts InstallOptions) error {
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
-	if cfg.ToolsDir == "" {
-		return fmt.Errorf("tools directory not configured")
+	toolsDir, err := resolveInstallDir(cfg, opts.ToolsDir)
+	if err != nil {
+		return err
 	}
-	toolsDir := cfg.ToolsDir
 
 	// Handle local installation
 	if opts.LocalPath != "" {
-		if err := installer.InstallLocalTool(opts.LocalPath, toolsDir, opts.Writer); err != nil {
+		if opts.Ref != "" {
+			return fmt.Errorf("--ref is only supported when installing from the registry")
+		}
+		if opts.Replace {
+			return fmt.Errorf("--replace is only supported when installing from the registry")
+		}
+		if err := installer.InstallLocalTool(opts.LocalPath, toolsDir, progressReporter(opts.Progress, opts.Writer)); err != nil {
 			return fmt.Errorf("failed to install local tool: %w", err)
 		}
-		core.MustFprintf(opts.Writer, "Tool is now available. Restart orla server to use it.\n")
+		printAvailability(opts.Writer, cfg, toolsDir, "Tool is now available. Restart orla server to use it.")
 		return nil
 	}
 
-	// Use default registry if not specified
+	// Use the configured default registry if not specified
 	if opts.RegistryURL == "" {
-		opts.RegistryURL = registry.DefaultRegistryURL
+		opts.RegistryURL = cfg.DefaultRegistry
+	}
+	applyOffline(cfg, opts.Offline)
+
+	if opts.Ref != "" {
+		if opts.Version != "" && opts.Version != "latest" {
+			return fmt.Errorf("--ref cannot be combined with a version constraint")
+		}
+		if err := installer.InstallToolAtRef(opts.RegistryURL, toolName, opts.Ref, toolsDir, progressReporter(opts.Progress, opts.Writer), !opts.Refresh, opts.Replace); err != nil {
+			return fmt.Errorf("failed to install tool: %w", err)
+		}
+
+		core.MustFprintf(opts.Writer, "Successfully installed %s at %s\n", toolName, opts.Ref)
+		printAvailability(opts.Writer, cfg, toolsDir, "Tool is now available. Restart orla server to use it.")
+		return nil
 	}
 
 	// Use "latest" if version not specified
@@ -55,12 +89,117 @@ func InstallTool(toolName string, opts InstallOptions) error {
 	}
 
 	// Install the tool
-	if err := installer.InstallTool(opts.RegistryURL, toolName, opts.Version, toolsDir, opts.Writer); err != nil {
+	if err := installer.InstallTool(opts.RegistryURL, toolName, opts.Version, toolsDir, progressReporter(opts.Progress, opts.Writer), !opts.Refresh, opts.Replace); err != nil {
 		return fmt.Errorf("failed to install tool: %w", err)
 	}
 
 	core.MustFprintf(opts.Writer, "Successfully installed %s\n", toolName)
-	core.MustFprintf(opts.Writer, "Tool is now available. Restart orla server to use it.\n")
+	printAvailability(opts.Writer, cfg, toolsDir, "Tool is now available. Restart orla server to use it.")
 
 	return nil
 }
+
+// InstallTools installs several tools from the registry in parallel, bounded by the
+// max_concurrent_clones config. Tools without a version in specs use opts.Version.
+// Every tool is attempted; an error is returned if any of them failed to install.
+func InstallTools(specs []installer.ToolSpec, opts InstallOptions) error {
+	if opts.Writer == nil {
+		opts.Writer = os.Stdout
+	}
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	toolsDir, err := resolveInstallDir(cfg, opts.ToolsDir)
+	if err != nil {
+		return err
+	}
+
+	if opts.RegistryURL == "" {
+		opts.RegistryURL = cfg.DefaultRegistry
+	}
+	applyOffline(cfg, opts.Offline)
+
+	if opts.Version == "" {
+		opts.Version = "latest"
+	}
+	for i := range specs {
+		if specs[i].Version == "" {
+			specs[i].Version = opts.Version
+		}
+	}
+
+	results, err := installer.InstallTools(opts.RegistryURL, specs, toolsDir, cfg.MaxConcurrentClones, progressReporter(opts.Progress, opts.Writer), !opts.Refresh, opts.Replace)
+	if err != nil {
+		return fmt.Errorf("failed to install tools: %w", err)
+	}
+
+	var failed []string
+	for _, result := range results {
+		if result.Err != nil {
+			core.MustFprintf(opts.Writer, "✗ %s: %v\n", result.Spec.Name, result.Err)
+			failed = append(failed, result.Spec.Name)
+			continue
+		}
+		core.MustFprintf(opts.Writer, "✓ Installed %s\n", result.Spec.Name)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to install %d of %d tools: %s", len(failed), len(results), strings.Join(failed, ", "))
+	}
+
+	printAvailability(opts.Writer, cfg, toolsDir, "Tools are now available. Restart orla server to use them.")
+	return nil
+}
+
+// applyOffline sets offline mode for registry lookups if the command or the config asks for it
+func applyOffline(cfg *config.OrlaConfig, offline bool) {
+	registry.SetOffline(offline || cfg.Offline)
+}
+
+// progressReporter returns progress, or a plain text progress reporter on w if progress is nil
+func progressReporter(progress installer.ProgressReporter, w io.Writer) installer.ProgressReporter {
+	if progress != nil {
+		return progress
+	}
+	return installer.NewTextProgress(w)
+}
+
+// resolveInstallDir returns the directory to install tools into: intoDir (relative to the current
+// directory) if given, otherwise the configured tools directory
+func resolveInstallDir(cfg *config.OrlaConfig, intoDir string) (string, error) {
+	if intoDir == "" {
+		if cfg.ToolsDir == "" {
+			return "", fmt.Errorf("tools directory not configured")
+		}
+		return cfg.ToolsDir, nil
+	}
+
+	absDir, err := filepath.Abs(intoDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve install directory %s: %w", intoDir, err)
+	}
+	return absDir, nil
+}
+
+// printAvailability prints message if tools installed in toolsDir are discovered with the current
+// config, or how to configure tools_dir so that they are
+func printAvailability(w io.Writer, cfg *config.OrlaConfig, toolsDir string, message string) {
+	if toolsDir == cfg.ToolsDir {
+		core.MustFprintf(w, "%s\n", message)
+		return
+	}
+
+	// A project's tools_dir is resolved relative to the directory of its orla.yaml
+	configuredDir := toolsDir
+	if cwd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(cwd, toolsDir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			configuredDir = "./" + filepath.ToSlash(rel)
+		}
+	}
+
+	core.MustFprintf(w, "Installed into %s, which is not the configured tools directory (%s).\n", toolsDir, cfg.ToolsDir)
+	core.MustFprintf(w, "To use tools from it, set tools_dir in the project's orla.yaml:\n")
+	core.MustFprintf(w, "  tools_dir: %s\n", configuredDir)
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/dorcha-inc/orla/internal/config"
 	"github.com/dorcha-inc/orla/internal/core"
 	"github.com/dorcha-inc/orla/internal/installer"
 	"github.com/dorcha-inc/orla/internal/registry"
@@ -126,6 +127,60 @@ func TestInstallTool_LocalPath(t *testing.T) {
 	assert.Contains(t, output, "Tool is now available. Restart orla server to use it.")
 }
 
+func TestInstallTool_Into(t *testing.T) {
+	t.Setenv("ORLA_HOME", t.TempDir())
+	projectDir := t.TempDir()
+	localToolDir := filepath.Join(t.TempDir(), "local-tool")
+
+	manifestData, err := yaml.Marshal(&core.ToolManifest{
+		Name:        "project-tool",
+		Version:     "0.1.0",
+		Description: "A project-local tool",
+		Entrypoint:  "bin/tool",
+	})
+	require.NoError(t, err)
+	// #nosec G301 -- test directory permissions are acceptable for temporary test files
+	require.NoError(t, os.MkdirAll(filepath.Join(localToolDir, "bin"), 0755))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(localToolDir, installer.ToolManifestFileName), manifestData, 0644))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(localToolDir, "bin", "tool"), []byte("#!/bin/sh\necho 'project tool'"), 0755))
+
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer core.LogDeferredError1(os.Chdir, originalDir)
+	require.NoError(t, os.Chdir(projectDir))
+
+	// Without a project config, the tool lands outside the configured tools directory
+	var buf bytes.Buffer
+	require.NoError(t, InstallTool("", InstallOptions{
+		LocalPath: localToolDir,
+		ToolsDir:  "./tools",
+		Writer:    &buf,
+	}))
+	_, err = os.Stat(filepath.Join(projectDir, "tools", "project-tool", "0.1.0", installer.ToolManifestFileName))
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "not the configured tools directory")
+	assert.Contains(t, buf.String(), "tools_dir: ./tools")
+
+	// With the project's tools_dir pointing at it, the tool is discovered from the project directory
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "orla.yaml"), []byte("tools_dir: ./tools\n"), 0644))
+	cfg, err := config.LoadConfig("")
+	require.NoError(t, err)
+	discovered, err := cfg.ToolsRegistry.GetTool("project-tool")
+	require.NoError(t, err)
+	assert.Equal(t, "0.1.0", discovered.Version)
+
+	buf.Reset()
+	require.NoError(t, InstallTool("", InstallOptions{
+		LocalPath: localToolDir,
+		ToolsDir:  "./tools",
+		Writer:    &buf,
+	}))
+	assert.Contains(t, buf.String(), "Tool is now available. Restart orla server to use it.")
+}
+
 func TestInstallTool_LocalPath_Error(t *testing.T) {
 	tmpDir := t.TempDir()
 	installDir := filepath.Join(tmpDir, "tools")
@@ -292,3 +347,80 @@ func TestInstallTool_LocalPathTakesPrecedence(t *testing.T) {
 	// Should not contain "Successfully installed" which is only for registry installs
 	assert.NotContains(t, output, "Successfully installed")
 }
+
+func TestInstallTool_UsesConfiguredDefaultRegistry(t *testing.T) {
+	tmpDir := t.TempDir()
+	setupTestRegistry(t, tmpDir, []registry.ToolEntry{
+		{Name: "other-tool", Description: "Some other tool"},
+	})
+	mockRunner := setFailingGitRunner(t)
+	writeDefaultRegistryConfig(t, getTestRegistryURL())
+
+	var buf bytes.Buffer
+	err := InstallTool("fs", InstallOptions{
+		Writer: &buf,
+	})
+	require.Error(t, err)
+	// The tool lookup ran against the cached default registry rather than failing to fetch one
+	assert.Contains(t, err.Error(), "tool 'fs' not found in registry")
+	assert.Empty(t, mockRunner.CloneCalls)
+}
+
+func TestInstallTool_RegistryFlagOverridesDefault(t *testing.T) {
+	tmpDir := t.TempDir()
+	setupTestRegistry(t, tmpDir, []registry.ToolEntry{
+		{Name: "fs", Description: "Filesystem operations tool"},
+	})
+	mockRunner := setFailingGitRunner(t)
+	writeDefaultRegistryConfig(t, getTestRegistryURL())
+
+	overrideURL := "https://example.com/override-registry"
+	var buf bytes.Buffer
+	err := InstallTool("fs", InstallOptions{
+		RegistryURL: overrideURL,
+		Writer:      &buf,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to fetch registry")
+
+	require.Len(t, mockRunner.CloneCalls, 1)
+	assert.Equal(t, overrideURL, mockRunner.CloneCalls[0].URL)
+}
+
+func TestInstallTool_RefConflicts(t *testing.T) {
+	tmpDir := t.TempDir()
+	setupTestRegistry(t, tmpDir, []registry.ToolEntry{
+		{Name: "fs", Description: "Filesystem operations tool"},
+	})
+	mockRunner := setFailingGitRunner(t)
+	writeDefaultRegistryConfig(t, getTestRegistryURL())
+
+	err := InstallTool("fs", InstallOptions{Ref: "main", Version: "^1.0.0", Writer: &bytes.Buffer{}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--ref cannot be combined with a version constraint")
+
+	err = InstallTool("", InstallOptions{Ref: "main", LocalPath: tmpDir, Writer: &bytes.Buffer{}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--ref is only supported when installing from the registry")
+	assert.Empty(t, mockRunner.CloneCalls)
+}
+
+func TestInstallTools_ReportsEachFailure(t *testing.T) {
+	tmpDir := t.TempDir()
+	setupTestRegistry(t, tmpDir, []registry.ToolEntry{
+		{Name: "other-tool", Description: "Some other tool"},
+	})
+	setFailingGitRunner(t)
+	writeDefaultRegistryConfig(t, getTestRegistryURL())
+
+	var buf bytes.Buffer
+	err := InstallTools([]installer.ToolSpec{{Name: "fs"}, {Name: "http", Version: "v0.1.0"}}, InstallOptions{
+		Writer: &buf,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to install 2 of 2 tools: fs, http")
+
+	output := buf.String()
+	assert.Contains(t, output, "✗ fs: tool 'fs' not found in registry")
+	assert.Contains(t, output, "✗ http: tool 'http' not found in registry")
+}
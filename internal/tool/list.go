@@ -64,7 +64,7 @@ func ListTools(opts ListOptions) error {
 		core.MustFprintf(w, "----\t-------\t-----------\n")
 
 		for _, tool := range tools {
-			description := tool.Description
+			description := core.DescriptionWithStability(tool.Stability, tool.Description)
 			if len(description) > 60 {
 				description = description[:57] + "..."
 			}
@@ -74,8 +74,12 @@ func ListTools(opts ListOptions) error {
 		return w.Flush()
 	}
 
-	// Simple format by default: tool-name (version)
+	// Simple format by default: tool-name (version), followed by the stability of tools that are not stable
 	for _, tool := range tools {
+		if tool.Stability != core.ToolStabilityStable {
+			core.MustFprintf(opts.Writer, "%s (%s) [%s]\n", tool.Name, tool.Version, tool.Stability)
+			continue
+		}
 		core.MustFprintf(opts.Writer, "%s (%s)\n", tool.Name, tool.Version)
 	}
 
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -361,6 +363,81 @@ func TestListTools_JSON(t *testing.T) {
 	assert.True(t, toolNames["tool2"])
 }
 
+func TestListTools_JSONFields(t *testing.T) {
+	_, toolsDir, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	// A tool without a runtime section runs in simple mode
+	simpleDir := filepath.Join(toolsDir, "simple-tool", "1.0.0")
+	capsuleDir := filepath.Join(toolsDir, "capsule-tool", "0.2.0")
+	for dir, manifest := range map[string]*core.ToolManifest{
+		simpleDir:  {Name: "simple-tool", Version: "1.0.0", Description: "Runs per call", Entrypoint: "bin/tool"},
+		capsuleDir: {Name: "capsule-tool", Version: "0.2.0", Description: "Runs as a capsule", Entrypoint: "bin/tool", Runtime: &core.RuntimeConfig{Mode: core.RuntimeModeCapsule}},
+	} {
+		// #nosec G301 -- test directory permissions are acceptable for temporary test files
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		data, err := yaml.Marshal(manifest)
+		require.NoError(t, err)
+		// #nosec G306 -- test file permissions are acceptable for temporary test files
+		require.NoError(t, os.WriteFile(filepath.Join(dir, installer.ToolManifestFileName), data, 0644))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, ListTools(ListOptions{JSON: true, Writer: &buf}))
+
+	var tools []map[string]string
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &tools))
+	require.Len(t, tools, 2)
+
+	byName := make(map[string]map[string]string)
+	for _, tool := range tools {
+		assert.ElementsMatch(t, []string{"name", "version", "description", "install_path", "runtime_mode", "stability"}, slices.Collect(maps.Keys(tool)))
+		byName[tool["name"]] = tool
+	}
+
+	assert.Equal(t, map[string]string{
+		"name":         "simple-tool",
+		"version":      "1.0.0",
+		"description":  "Runs per call",
+		"install_path": simpleDir,
+		"runtime_mode": "simple",
+		"stability":    "stable",
+	}, byName["simple-tool"])
+	assert.Equal(t, "capsule", byName["capsule-tool"]["runtime_mode"])
+	assert.Equal(t, capsuleDir, byName["capsule-tool"]["install_path"])
+}
+
+func TestListTools_Stability(t *testing.T) {
+	_, toolsDir, cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	for _, manifest := range []*core.ToolManifest{
+		{Name: "old-tool", Version: "1.0.0", Description: "Going away", Entrypoint: "bin/tool", Stability: core.ToolStabilityDeprecated},
+		{Name: "new-tool", Version: "0.1.0", Description: "Still changing", Entrypoint: "bin/tool", Stability: core.ToolStabilityExperimental},
+		{Name: "tool", Version: "2.0.0", Description: "Settled", Entrypoint: "bin/tool"},
+	} {
+		dir := filepath.Join(toolsDir, manifest.Name, manifest.Version)
+		// #nosec G301 -- test directory permissions are acceptable for temporary test files
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		data, err := yaml.Marshal(manifest)
+		require.NoError(t, err)
+		// #nosec G306 -- test file permissions are acceptable for temporary test files
+		require.NoError(t, os.WriteFile(filepath.Join(dir, installer.ToolManifestFileName), data, 0644))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, ListTools(ListOptions{Writer: &buf}))
+	assert.Contains(t, buf.String(), "old-tool (1.0.0) [deprecated]\n")
+	assert.Contains(t, buf.String(), "new-tool (0.1.0) [experimental]\n")
+	assert.Contains(t, buf.String(), "tool (2.0.0)\n")
+	assert.NotContains(t, buf.String(), "[stable]")
+
+	buf.Reset()
+	require.NoError(t, ListTools(ListOptions{Verbose: true, Writer: &buf}))
+	assert.Contains(t, buf.String(), "[deprecated] Going away")
+	assert.Contains(t, buf.String(), "[experimental] Still changing")
+}
+
 func TestListTools_DefaultWriter(t *testing.T) {
 	_, toolsDir, cleanup := setupTestConfig(t)
 	defer cleanup()
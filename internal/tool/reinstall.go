@@ -0,0 +1,93 @@
+package tool
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/installer"
+)
+
+// ReinstallOptions configures tool reinstallation
+type ReinstallOptions struct {
+	All    bool
+	Writer io.Writer
+	// Progress receives install progress events (default: plain text on Writer)
+	Progress installer.ProgressReporter
+}
+
+// ReinstallTools reinstalls the installed versions of the named tools (or of every installed tool
+// if opts.All is set) from the sources they were installed from
+func ReinstallTools(toolNames []string, opts ReinstallOptions) error {
+	if opts.Writer == nil {
+		opts.Writer = os.Stdout
+	}
+
+	if opts.All && len(toolNames) > 0 {
+		return fmt.Errorf("cannot combine --all with tool names")
+	}
+	if !opts.All && len(toolNames) == 0 {
+		return fmt.Errorf("specify tools to reinstall or use --all")
+	}
+
+	// Load config to get ToolsDir (handles project > user > default precedence)
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.ToolsDir == "" {
+		return fmt.Errorf("tools directory not configured")
+	}
+	toolsDir := cfg.ToolsDir
+
+	installed, err := installer.ListInstalledVersions(toolsDir)
+	if err != nil {
+		return fmt.Errorf("failed to list installed tools: %w", err)
+	}
+
+	var targets []installer.InstalledVersion
+	if opts.All {
+		targets = installed
+	} else {
+		for _, name := range toolNames {
+			found := false
+			for _, version := range installed {
+				if version.Name == name {
+					targets = append(targets, version)
+					found = true
+				}
+			}
+			if !found {
+				return fmt.Errorf("tool '%s' not installed", name)
+			}
+		}
+	}
+
+	if len(targets) == 0 {
+		core.MustFprintf(opts.Writer, "No tools installed\n")
+		return nil
+	}
+
+	var failed []string
+	for _, target := range targets {
+		label := fmt.Sprintf("%s@%s", target.Name, target.Version)
+		if err := installer.ReinstallTool(target.Name, target.Version, toolsDir, cfg.DefaultRegistry, progressReporter(opts.Progress, opts.Writer)); err != nil {
+			core.MustFprintf(opts.Writer, "✗ Failed to reinstall %s: %v\n", label, err)
+			failed = append(failed, label)
+			continue
+		}
+		core.MustFprintf(opts.Writer, "✓ Reinstalled %s\n", label)
+	}
+
+	if len(failed) > 0 {
+		slices.Sort(failed)
+		return fmt.Errorf("failed to reinstall %d of %d tools: %s", len(failed), len(targets), strings.Join(failed, ", "))
+	}
+
+	core.MustFprintf(opts.Writer, "Restart orla server to use the reinstalled tools.\n")
+	return nil
+}
@@ -0,0 +1,90 @@
+package tool
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/installer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// setupReinstallTest installs a local tool into a project-local tools directory and
+// changes into the project directory so config.LoadConfig finds its orla.yaml
+func setupReinstallTest(t *testing.T) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	toolsDir := filepath.Join(tmpDir, "tools")
+
+	sourceDir := filepath.Join(tmpDir, "source")
+	// #nosec G301 -- test directory permissions are acceptable for temporary test files
+	require.NoError(t, os.MkdirAll(sourceDir, 0755))
+	manifestData, err := yaml.Marshal(&core.ToolManifest{
+		Name:        "test-tool",
+		Version:     "1.0.0",
+		Description: "Test tool",
+		Entrypoint:  "tool.sh",
+	})
+	require.NoError(t, err)
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, installer.ToolManifestFileName), manifestData, 0644))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "tool.sh"), []byte("#!/bin/sh\necho ok\n"), 0755))
+	require.NoError(t, installer.InstallLocalTool(sourceDir, toolsDir, nil))
+
+	configContent := fmt.Sprintf("tools_dir: %s\n", toolsDir)
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "orla.yaml"), []byte(configContent), 0644))
+
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError1(os.Chdir, originalDir) })
+	require.NoError(t, os.Chdir(tmpDir))
+
+	return filepath.Join(toolsDir, "test-tool", "1.0.0")
+}
+
+func TestReinstallTools_All(t *testing.T) {
+	installDir := setupReinstallTest(t)
+
+	// Corrupt the install
+	require.NoError(t, os.Remove(filepath.Join(installDir, "tool.sh")))
+
+	var out bytes.Buffer
+	require.NoError(t, ReinstallTools(nil, ReinstallOptions{All: true, Writer: &out}))
+	assert.Contains(t, out.String(), "✓ Reinstalled test-tool@1.0.0")
+
+	_, err := os.Stat(filepath.Join(installDir, "tool.sh"))
+	assert.NoError(t, err)
+}
+
+func TestReinstallTools_ByName(t *testing.T) {
+	setupReinstallTest(t)
+
+	var out bytes.Buffer
+	require.NoError(t, ReinstallTools([]string{"test-tool"}, ReinstallOptions{Writer: &out}))
+	assert.Contains(t, out.String(), "✓ Reinstalled test-tool@1.0.0")
+}
+
+func TestReinstallTools_NotInstalled(t *testing.T) {
+	setupReinstallTest(t)
+
+	err := ReinstallTools([]string{"nonexistent-tool"}, ReinstallOptions{Writer: &bytes.Buffer{}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tool 'nonexistent-tool' not installed")
+}
+
+func TestReinstallTools_InvalidArguments(t *testing.T) {
+	err := ReinstallTools(nil, ReinstallOptions{Writer: &bytes.Buffer{}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "specify tools to reinstall or use --all")
+
+	err = ReinstallTools([]string{"test-tool"}, ReinstallOptions{All: true, Writer: &bytes.Buffer{}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot combine --all with tool names")
+}
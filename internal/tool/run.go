@@ -0,0 +1,139 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dorcha-inc/orla/internal/config"
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/server"
+	"github.com/dorcha-inc/orla/internal/state"
+)
+
+// Exit codes of orla run for failures of orla rather than of the tool. Otherwise orla run exits
+// with the tool's exit code, or 1 if the tool failed without one (e.g. it timed out).
+const (
+	// RunExitCodeError is the exit code of orla run when orla fails to run the tool, e.g. because
+	// its arguments or manifest are invalid
+	RunExitCodeError = 125
+	// RunExitCodeToolNotFound is the exit code of orla run when the tool's manifest or entrypoint
+	// does not exist
+	RunExitCodeToolNotFound = 127
+)
+
+// ToolErrorError is returned by RunManifest when the tool ran but reported an error
+type ToolErrorError struct {
+	Tool string
+	// ExitCode is the exit code of the tool's process, or 0 if the tool failed without one
+	ExitCode int
+}
+
+// Error returns the error message for the ToolErrorError
+func (e *ToolErrorError) Error() string {
+	if e.ExitCode != 0 {
+		return fmt.Sprintf("tool '%s' exited with code %d", e.Tool, e.ExitCode)
+	}
+	return fmt.Sprintf("tool '%s' reported an error", e.Tool)
+}
+
+// Interface guard for ToolErrorError
+var _ error = &ToolErrorError{}
+
+// ParseRunArgs converts KEY=VALUE arguments into tool call input. Values that are valid JSON
+// (numbers, booleans, arrays, objects, quoted strings) are decoded, anything else is passed
+// as a string.
+func ParseRunArgs(args []string) (map[string]any, error) {
+	input := make(map[string]any, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid argument %q: expected KEY=VALUE", arg)
+		}
+
+		var decoded any
+		if err := json.Unmarshal([]byte(value), &decoded); err == nil {
+			input[key] = decoded
+		} else {
+			input[key] = value
+		}
+	}
+	return input, nil
+}
+
+// RunManifest runs the tool described by the manifest at manifestPath with the given input,
+// bypassing discovery and installation, and writes its output to stdout (or to stderr if the
+// tool reports an error). The call goes through the same path as an MCP tools/call request,
+// so capsule-mode tools are started for the call and stopped afterwards.
+func RunManifest(ctx context.Context, manifestPath string, input map[string]any, stdout io.Writer, stderr io.Writer) error {
+	tool, err := state.LoadToolFromManifest(manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to load tool from %s: %w", manifestPath, err)
+	}
+
+	// Load config for settings such as the tool timeout; only the manifest's tool is served
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.ToolsRegistry = state.NewToolsRegistry()
+	if err := cfg.ToolsRegistry.AddTool(tool); err != nil {
+		return err
+	}
+
+	srv := server.NewOrlaServer(cfg, "")
+	defer srv.Close()
+
+	result, err := srv.CallTool(ctx, tool.Name, input)
+	if err != nil {
+		return fmt.Errorf("failed to run tool '%s': %w", tool.Name, err)
+	}
+
+	out := stdout
+	if result.IsError {
+		out = stderr
+	}
+	for _, content := range result.Content {
+		if textContent, ok := content.(*mcp.TextContent); ok && textContent.Text != "" {
+			core.MustFprintf(out, "%s", textContent.Text)
+			if !strings.HasSuffix(textContent.Text, "\n") {
+				core.MustFprintf(out, "\n")
+			}
+		}
+	}
+
+	if result.IsError {
+		exitCode, _ := server.ResultExitCode(result)
+		return &ToolErrorError{Tool: tool.Name, ExitCode: exitCode}
+	}
+	return nil
+}
+
+// RunExitCode returns the exit code of orla run for an error returned by ParseRunArgs or
+// RunManifest: the tool's exit code if it failed with one, or one of the reserved RunExitCode
+// codes if orla failed
+func RunExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var toolErr *ToolErrorError
+	if errors.As(err, &toolErr) {
+		if toolErr.ExitCode != 0 {
+			return toolErr.ExitCode
+		}
+		return 1
+	}
+
+	var notFoundErr *state.ToolNotFoundError
+	if errors.Is(err, fs.ErrNotExist) || errors.As(err, &notFoundErr) {
+		return RunExitCodeToolNotFound
+	}
+	return RunExitCodeError
+}
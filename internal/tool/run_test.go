@@ -0,0 +1,163 @@
+package tool
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dorcha-inc/orla/internal/core"
+	"github.com/dorcha-inc/orla/internal/registry"
+)
+
+// writeRunTestTool writes a tool with writeTestTool into a new tool directory and changes into
+// an empty project directory, so that no other tools are discovered. It returns
+// the path of the manifest.
+func writeRunTestTool(t *testing.T, manifest string, script string) string {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping tool execution test on Windows")
+	}
+
+	t.Setenv(registry.OrlaHomeEnvVar, t.TempDir())
+
+	manifestPath := writeTestTool(t, t.TempDir(), manifest, script)
+
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	t.Cleanup(func() { core.LogDeferredError1(os.Chdir, originalDir) })
+	require.NoError(t, os.Chdir(t.TempDir()))
+
+	return manifestPath
+}
+
+func TestParseRunArgs(t *testing.T) {
+	input, err := ParseRunArgs([]string{"name=World", "count=3", "verbose=true", `tags=["a","b"]`, "expr=a=b", "empty="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"name":    "World",
+		"count":   float64(3),
+		"verbose": true,
+		"tags":    []any{"a", "b"},
+		"expr":    "a=b",
+		"empty":   "",
+	}, input)
+
+	_, err = ParseRunArgs([]string{"novalue"})
+	assert.Error(t, err)
+	_, err = ParseRunArgs([]string{"=value"})
+	assert.Error(t, err)
+}
+
+func TestRunManifest_SimpleTool(t *testing.T) {
+	manifestPath := writeRunTestTool(t, `
+name: greet
+version: 1.0.0
+description: Greets someone
+entrypoint: bin/tool
+`, "#!/bin/sh\necho \"hello $*\"\n")
+
+	var stdout, stderr bytes.Buffer
+	err := RunManifest(context.Background(), manifestPath, map[string]any{"name": "World"}, &stdout, &stderr)
+	require.NoError(t, err)
+	assert.Equal(t, "hello --name World\n", stdout.String())
+	assert.Empty(t, stderr.String())
+}
+
+func TestRunManifest_ToolError(t *testing.T) {
+	manifestPath := writeRunTestTool(t, `
+name: failing
+version: 1.0.0
+description: Always fails
+entrypoint: bin/tool
+`, "#!/bin/sh\necho boom >&2\nexit 3\n")
+
+	var stdout, stderr bytes.Buffer
+	err := RunManifest(context.Background(), manifestPath, map[string]any{}, &stdout, &stderr)
+	var toolErr *ToolErrorError
+	require.ErrorAs(t, err, &toolErr)
+	assert.Equal(t, "failing", toolErr.Tool)
+	assert.Equal(t, 3, toolErr.ExitCode)
+	assert.Empty(t, stdout.String())
+	assert.Contains(t, stderr.String(), "boom")
+}
+
+func TestRunManifest_CapsuleTool(t *testing.T) {
+	manifestPath := writeRunTestTool(t, `
+name: capsule
+version: 1.0.0
+description: A capsule mode tool
+entrypoint: bin/tool
+runtime:
+  mode: capsule
+  startup_timeout_ms: 5000
+`, `#!/bin/sh
+echo '{"jsonrpc":"2.0","method":"orla.hello","params":{"name":"capsule","version":"1.0.0","capabilities":["tools"]}}'
+while IFS= read -r line; do
+  REQ_ID=$(echo "$line" | sed -n 's/.*"id":\([0-9]*\).*/\1/p')
+  if [ -n "$REQ_ID" ]; then
+    echo "{\"jsonrpc\":\"2.0\",\"id\":$REQ_ID,\"result\":{\"output\":\"capsule result\"}}"
+  fi
+done
+`)
+
+	var stdout, stderr bytes.Buffer
+	err := RunManifest(context.Background(), manifestPath, map[string]any{}, &stdout, &stderr)
+	require.NoError(t, err)
+	assert.Contains(t, stdout.String(), "capsule result")
+}
+
+func TestRunManifest_InvalidManifest(t *testing.T) {
+	manifestPath := writeRunTestTool(t, `
+name: broken
+version: 1.0.0
+description: Points at a missing entrypoint
+entrypoint: bin/missing.sh
+`, "#!/bin/sh\n")
+
+	err := RunManifest(context.Background(), manifestPath, map[string]any{}, &bytes.Buffer{}, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load tool")
+
+	err = RunManifest(context.Background(), filepath.Join(t.TempDir(), "missing.yaml"), map[string]any{}, &bytes.Buffer{}, &bytes.Buffer{})
+	assert.Error(t, err)
+}
+
+func TestRunExitCode_ToolExitCode(t *testing.T) {
+	manifestPath := writeRunTestTool(t, `
+name: answer
+version: 1.0.0
+description: Exits with 42
+entrypoint: bin/tool
+`, "#!/bin/sh\nexit 42\n")
+
+	err := RunManifest(context.Background(), manifestPath, map[string]any{}, &bytes.Buffer{}, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Equal(t, 42, RunExitCode(err))
+	assert.Contains(t, err.Error(), "exited with code 42")
+}
+
+func TestRunExitCode_MissingTool(t *testing.T) {
+	t.Setenv(registry.OrlaHomeEnvVar, t.TempDir())
+
+	err := RunManifest(context.Background(), filepath.Join(t.TempDir(), "missing.yaml"), map[string]any{}, &bytes.Buffer{}, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Equal(t, RunExitCodeToolNotFound, RunExitCode(err))
+}
+
+func TestRunExitCode(t *testing.T) {
+	assert.Equal(t, 0, RunExitCode(nil))
+	assert.Equal(t, 7, RunExitCode(&ToolErrorError{Tool: "tool", ExitCode: 7}))
+	// A tool that failed without an exit code, e.g. on timeout
+	assert.Equal(t, 1, RunExitCode(&ToolErrorError{Tool: "tool"}))
+
+	_, err := ParseRunArgs([]string{"novalue"})
+	require.Error(t, err)
+	assert.Equal(t, RunExitCodeError, RunExitCode(err))
+}
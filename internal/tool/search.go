@@ -7,6 +7,7 @@ import (
 	"os"
 	"text/tabwriter"
 
+	"github.com/dorcha-inc/orla/internal/config"
 	"github.com/dorcha-inc/orla/internal/core"
 	"github.com/dorcha-inc/orla/internal/registry"
 )
@@ -16,7 +17,9 @@ type SearchOptions struct {
 	RegistryURL string
 	Verbose     bool
 	JSON        bool
-	Writer      io.Writer
+	// Offline uses only the cached registry, as does offline in the config
+	Offline bool
+	Writer  io.Writer
 }
 
 // SearchTools searches the registry for tools matching the query
@@ -25,10 +28,16 @@ func SearchTools(query string, opts SearchOptions) error {
 		opts.Writer = os.Stdout
 	}
 
-	// Use default registry if not specified
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Use the configured default registry if not specified
 	if opts.RegistryURL == "" {
-		opts.RegistryURL = registry.DefaultRegistryURL
+		opts.RegistryURL = cfg.DefaultRegistry
 	}
+	applyOffline(cfg, opts.Offline)
 
 	// Fetch registry
 	reg, err := registry.FetchRegistry(opts.RegistryURL, true)
@@ -67,7 +76,7 @@ func SearchTools(query string, opts SearchOptions) error {
 		}
 
 		for _, tool := range results {
-			description := tool.Description
+			description := core.DescriptionWithStability(tool.Stability, tool.Description)
 			if len(description) > 60 {
 				description = description[:57] + "..."
 			}
@@ -82,7 +91,7 @@ func SearchTools(query string, opts SearchOptions) error {
 	} else {
 		// Simple format by default: tool-name: description
 		for _, tool := range results {
-			description := tool.Description
+			description := core.DescriptionWithStability(tool.Stability, tool.Description)
 			if len(description) > 80 {
 				description = description[:77] + "..."
 			}
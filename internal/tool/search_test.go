@@ -3,11 +3,13 @@ package tool
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/dorcha-inc/orla/internal/core"
 	"github.com/dorcha-inc/orla/internal/registry"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -67,6 +69,23 @@ func TestSearchTools_SingleResult_Simple(t *testing.T) {
 	assert.Contains(t, output, "Install a tool with: orla tool install TOOL-NAME")
 }
 
+func TestSearchTools_Stability(t *testing.T) {
+	tmpDir := t.TempDir()
+	setupTestRegistry(t, tmpDir, []registry.ToolEntry{
+		{Name: "fs-tool", Description: "Filesystem operations tool", Stability: core.ToolStabilityDeprecated},
+		{Name: "fs-next", Description: "Next filesystem tool", Stability: core.ToolStabilityExperimental},
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, SearchTools("fs", SearchOptions{RegistryURL: getTestRegistryURL(), Writer: &buf}))
+	assert.Contains(t, buf.String(), "fs-tool: [deprecated] Filesystem operations tool")
+	assert.Contains(t, buf.String(), "fs-next: [experimental] Next filesystem tool")
+
+	buf.Reset()
+	require.NoError(t, SearchTools("fs", SearchOptions{RegistryURL: getTestRegistryURL(), Verbose: true, Writer: &buf}))
+	assert.Contains(t, buf.String(), "[deprecated] Filesystem operations tool")
+}
+
 func TestSearchTools_MultipleResults_Simple(t *testing.T) {
 	tmpDir := t.TempDir()
 	setupTestRegistry(t, tmpDir, []registry.ToolEntry{
@@ -278,6 +297,33 @@ func TestSearchTools_CaseInsensitive(t *testing.T) {
 
 // Helper functions
 
+func TestSearchTools_Offline(t *testing.T) {
+	tmpDir := t.TempDir()
+	setupTestRegistry(t, tmpDir, []registry.ToolEntry{
+		{Name: "fs-tool", Description: "Filesystem operations tool"},
+	})
+	t.Cleanup(func() { registry.SetOffline(false) })
+
+	var buf bytes.Buffer
+	err := SearchTools("fs", SearchOptions{
+		RegistryURL: getTestRegistryURL(),
+		Offline:     true,
+		Writer:      &buf,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "fs-tool")
+	assert.True(t, registry.Offline())
+
+	// A registry that was never cached is not fetched
+	err = SearchTools("fs", SearchOptions{
+		RegistryURL: "https://example.com/uncached-registry",
+		Offline:     true,
+		Writer:      &buf,
+	})
+	require.ErrorIs(t, err, registry.ErrOffline)
+	assert.Contains(t, err.Error(), "offline mode: no cached registry for https://example.com/uncached-registry")
+}
+
 func setupTestRegistry(t *testing.T, tmpDir string, tools []registry.ToolEntry) {
 	cacheDir := filepath.Join(tmpDir, "cache")
 	// #nosec G301 -- test directory permissions are acceptable for temporary test files
@@ -328,3 +374,69 @@ func getTestRegistryURL() string {
 	// Return a registry URL that will use the cached registry we set up
 	return "https://example.com/test-registry"
 }
+
+// writeDefaultRegistryConfig writes an orla.yaml setting default_registry in a temp directory and changes to it
+func writeDefaultRegistryConfig(t *testing.T, registryURL string) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "orla.yaml")
+	configContent := "default_registry: " + registryURL + "\n"
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(tmpDir))
+	t.Cleanup(func() {
+		_ = os.Chdir(originalDir)
+	})
+}
+
+// setFailingGitRunner installs a mock git runner whose clones always fail
+func setFailingGitRunner(t *testing.T) *registry.MockGitRunner {
+	mockRunner := &registry.MockGitRunner{CloneErr: fmt.Errorf("clone disabled in tests")}
+	originalRunner := registry.GetDefaultGitRunner()
+	registry.SetGitRunner(mockRunner)
+	t.Cleanup(func() {
+		registry.SetGitRunner(originalRunner)
+	})
+	return mockRunner
+}
+
+func TestSearchTools_UsesConfiguredDefaultRegistry(t *testing.T) {
+	tmpDir := t.TempDir()
+	setupTestRegistry(t, tmpDir, []registry.ToolEntry{
+		{Name: "fs", Description: "Filesystem operations tool"},
+	})
+	mockRunner := setFailingGitRunner(t)
+	writeDefaultRegistryConfig(t, getTestRegistryURL())
+
+	var buf bytes.Buffer
+	err := SearchTools("fs", SearchOptions{
+		Writer: &buf,
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, buf.String(), "fs: Filesystem operations tool")
+	assert.Empty(t, mockRunner.CloneCalls, "cached default registry should be used without cloning")
+}
+
+func TestSearchTools_RegistryFlagOverridesDefault(t *testing.T) {
+	tmpDir := t.TempDir()
+	setupTestRegistry(t, tmpDir, []registry.ToolEntry{
+		{Name: "fs", Description: "Filesystem operations tool"},
+	})
+	mockRunner := setFailingGitRunner(t)
+	writeDefaultRegistryConfig(t, getTestRegistryURL())
+
+	overrideURL := "https://example.com/override-registry"
+	var buf bytes.Buffer
+	err := SearchTools("fs", SearchOptions{
+		RegistryURL: overrideURL,
+		Writer:      &buf,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to fetch registry")
+
+	require.Len(t, mockRunner.CloneCalls, 1)
+	assert.Equal(t, overrideURL, mockRunner.CloneCalls[0].URL)
+}
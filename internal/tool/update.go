@@ -5,17 +5,25 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 
 	"github.com/dorcha-inc/orla/internal/config"
 	"github.com/dorcha-inc/orla/internal/core"
 	"github.com/dorcha-inc/orla/internal/installer"
-	"github.com/dorcha-inc/orla/internal/registry"
 )
 
 // UpdateOptions configures tool update functionality given a registry URL
 type UpdateOptions struct {
 	RegistryURL string
-	Writer      io.Writer
+	// Refresh fetches the registry index and tool tags fresh instead of using the cache
+	Refresh bool
+	// Offline uses only the cached registry and tool tags and never clones, as does offline in the config
+	Offline bool
+	// Replace makes the latest version the tool's active version once it is installed
+	Replace bool
+	Writer  io.Writer
+	// Progress receives install progress events (default: plain text on Writer)
+	Progress installer.ProgressReporter
 }
 
 // UpdateTool updates a tool to the latest version from the given registry URL
@@ -34,17 +42,37 @@ func UpdateTool(toolName string, opts UpdateOptions) error {
 	}
 	toolsDir := cfg.ToolsDir
 
-	// Use default registry if not specified
+	// Use the configured default registry if not specified
 	if opts.RegistryURL == "" {
-		opts.RegistryURL = registry.DefaultRegistryURL
+		opts.RegistryURL = cfg.DefaultRegistry
 	}
+	applyOffline(cfg, opts.Offline)
 
 	// Update the tool
-	if err := installer.UpdateTool(opts.RegistryURL, toolName, toolsDir, opts.Writer); err != nil {
+	if err := installer.UpdateTool(opts.RegistryURL, toolName, toolsDir, progressReporter(opts.Progress, opts.Writer), !opts.Refresh, opts.Replace); err != nil {
 		return fmt.Errorf("failed to update tool: %w", err)
 	}
 
 	core.MustFprintf(opts.Writer, "✓ Successfully updated %s to latest version\n", toolName)
+	if active, latest, pinned := pinnedToOlderVersion(filepath.Join(toolsDir, toolName)); pinned && !opts.Replace {
+		core.MustFprintf(opts.Writer, "⚠ %s is still using version %s (its %s link); run `orla tool update %s --replace` to switch to %s.\n",
+			toolName, active, installer.ActiveVersionLinkName, toolName, latest)
+		return nil
+	}
 	core.MustFprintf(opts.Writer, "Restart orla server to use the updated version.\n")
 	return nil
 }
+
+// pinnedToOlderVersion reports whether the active version link of the tool in toolBaseDir points
+// at a version other than the latest installed one, which orla then keeps using
+func pinnedToOlderVersion(toolBaseDir string) (active, latest string, pinned bool) {
+	active = installer.ActiveVersion(toolBaseDir)
+	if active == "" {
+		return "", "", false
+	}
+	_, latest, err := findLatestToolVersion(toolBaseDir)
+	if err != nil {
+		return "", "", false
+	}
+	return active, latest, active != latest
+}
@@ -140,3 +140,30 @@ func TestUpdateTool_CustomRegistryURL(t *testing.T) {
 	// The error should be from the installer, not from config loading
 	assert.NotContains(t, err.Error(), "tools directory not configured")
 }
+
+func TestPinnedToOlderVersion(t *testing.T) {
+	toolBaseDir := t.TempDir()
+	for _, version := range []string{"1.0.0", "2.0.0"} {
+		versionDir := filepath.Join(toolBaseDir, version)
+		// #nosec G301 -- test directory permissions are acceptable for temporary test files
+		require.NoError(t, os.MkdirAll(versionDir, 0755))
+		data, err := yaml.Marshal(&core.ToolManifest{Name: "tool", Version: version, Description: "Tool", Entrypoint: "bin/tool"})
+		require.NoError(t, err)
+		// #nosec G306 -- test file permissions are acceptable for temporary test files
+		require.NoError(t, os.WriteFile(filepath.Join(versionDir, installer.ToolManifestFileName), data, 0644))
+	}
+
+	// Without an active version link the latest version is used
+	_, _, pinned := pinnedToOlderVersion(toolBaseDir)
+	assert.False(t, pinned)
+
+	require.NoError(t, installer.SetActiveVersion(toolBaseDir, "1.0.0"))
+	active, latest, pinned := pinnedToOlderVersion(toolBaseDir)
+	assert.True(t, pinned)
+	assert.Equal(t, "1.0.0", active)
+	assert.Equal(t, "2.0.0", latest)
+
+	require.NoError(t, installer.SetActiveVersion(toolBaseDir, "2.0.0"))
+	_, _, pinned = pinnedToOlderVersion(toolBaseDir)
+	assert.False(t, pinned)
+}
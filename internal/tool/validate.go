@@ -0,0 +1,73 @@
+package tool
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/dorcha-inc/orla/internal/installer"
+)
+
+// ValidateOptions configures checking the tool manifests in a directory
+type ValidateOptions struct {
+	Dir    string
+	Writer io.Writer
+}
+
+// ValidateTools checks every tool.yaml under opts.Dir with the installer's manifest validation and
+// writes a pass/fail report per tool. It returns an error if any manifest fails.
+func ValidateTools(opts ValidateOptions) error {
+	if opts.Writer == nil {
+		opts.Writer = os.Stdout
+	}
+
+	manifestPaths, err := findManifests(opts.Dir)
+	if err != nil {
+		return err
+	}
+	if len(manifestPaths) == 0 {
+		_, _ = fmt.Fprintf(opts.Writer, "No %s files found in %s\n", installer.ToolManifestFileName, opts.Dir)
+		return nil
+	}
+
+	failed := 0
+	for _, manifestPath := range manifestPaths {
+		manifest, issues := installer.CheckManifest(filepath.Dir(manifestPath))
+		if len(issues) == 0 {
+			_, _ = fmt.Fprintf(opts.Writer, "PASS %s (%s)\n", manifestPath, manifest.Name)
+			continue
+		}
+
+		failed++
+		_, _ = fmt.Fprintf(opts.Writer, "FAIL %s\n", manifestPath)
+		for _, issue := range issues {
+			_, _ = fmt.Fprintf(opts.Writer, "  %s\n", issue)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tools failed validation", failed, len(manifestPaths))
+	}
+	_, _ = fmt.Fprintf(opts.Writer, "All %d tools passed validation\n", len(manifestPaths))
+	return nil
+}
+
+// findManifests returns the paths of the tool manifests under dir, in lexical order
+func findManifests(dir string) ([]string, error) {
+	var manifestPaths []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && entry.Name() == installer.ToolManifestFileName {
+			manifestPaths = append(manifestPaths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s for tool manifests: %w", dir, err)
+	}
+	return manifestPaths, nil
+}
@@ -0,0 +1,70 @@
+package tool
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestTool writes a tool directory at toolDir with the given tool.yaml and an executable
+// bin/tool running script, and returns the path of the manifest
+func writeTestTool(t *testing.T, toolDir, manifest, script string) string {
+	t.Helper()
+	// #nosec G301 -- test directory permissions are acceptable for temporary test files
+	require.NoError(t, os.MkdirAll(filepath.Join(toolDir, "bin"), 0755))
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(filepath.Join(toolDir, "bin", "tool"), []byte(script), 0755))
+	manifestPath := filepath.Join(toolDir, "tool.yaml")
+	// #nosec G306 -- test file permissions are acceptable for temporary test files
+	require.NoError(t, os.WriteFile(manifestPath, []byte(manifest), 0644))
+	return manifestPath
+}
+
+func TestValidateTools_AllPass(t *testing.T) {
+	dir := t.TempDir()
+	writeTestTool(t, filepath.Join(dir, "alpha"), "name: alpha\nversion: 1.0.0\ndescription: Alpha\nentrypoint: bin/tool\n", "#!/bin/sh\necho test\n")
+	writeTestTool(t, filepath.Join(dir, "beta"), "name: beta\nversion: 1.0.0\ndescription: Beta\nentrypoint: bin/tool\n", "#!/bin/sh\necho test\n")
+
+	var buf bytes.Buffer
+	require.NoError(t, ValidateTools(ValidateOptions{Dir: dir, Writer: &buf}))
+
+	output := buf.String()
+	assert.Contains(t, output, "PASS "+filepath.Join(dir, "alpha", "tool.yaml")+" (alpha)\n")
+	assert.Contains(t, output, "PASS "+filepath.Join(dir, "beta", "tool.yaml")+" (beta)\n")
+	assert.Contains(t, output, "All 2 tools passed validation\n")
+}
+
+func TestValidateTools_Failure(t *testing.T) {
+	dir := t.TempDir()
+	writeTestTool(t, filepath.Join(dir, "alpha"), "name: alpha\nversion: 1.0.0\ndescription: Alpha\nentrypoint: bin/tool\n", "#!/bin/sh\necho test\n")
+	writeTestTool(t, filepath.Join(dir, "broken"), "name: broken\ndescription: Broken\nentrypoint: bin/missing\n", "#!/bin/sh\necho test\n")
+
+	var buf bytes.Buffer
+	err := ValidateTools(ValidateOptions{Dir: dir, Writer: &buf})
+	require.Error(t, err)
+	assert.Equal(t, "1 of 2 tools failed validation", err.Error())
+
+	output := buf.String()
+	assert.Contains(t, output, "PASS "+filepath.Join(dir, "alpha", "tool.yaml"))
+	assert.Contains(t, output, "FAIL "+filepath.Join(dir, "broken", "tool.yaml")+"\n")
+	assert.Contains(t, output, "  missing required field \"version\"\n")
+	assert.NotContains(t, output, "passed validation")
+}
+
+func TestValidateTools_NoManifests(t *testing.T) {
+	dir := t.TempDir()
+
+	var buf bytes.Buffer
+	require.NoError(t, ValidateTools(ValidateOptions{Dir: dir, Writer: &buf}))
+	assert.Contains(t, buf.String(), "No tool.yaml files found in "+dir)
+}
+
+func TestValidateTools_MissingDir(t *testing.T) {
+	err := ValidateTools(ValidateOptions{Dir: filepath.Join(t.TempDir(), "missing"), Writer: &bytes.Buffer{}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to search")
+}
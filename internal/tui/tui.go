@@ -30,6 +30,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
@@ -387,6 +388,22 @@ func Default() *UI {
 	return defaultUI
 }
 
+// TruncateLines limits text to its first maxLines lines for display, replacing the rest with a
+// "...(N more lines)" notice. A trailing newline does not count as an extra line. Text is returned
+// unchanged if maxLines is zero or negative or the text fits.
+func TruncateLines(text string, maxLines int) string {
+	if maxLines <= 0 {
+		return text
+	}
+
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if len(lines) <= maxLines {
+		return text
+	}
+
+	return strings.Join(lines[:maxLines], "\n") + fmt.Sprintf("\n...(%d more lines)\n", len(lines)-maxLines)
+}
+
 // Reset resets the default UI instance (useful for testing)
 func Reset() {
 	defaultUI = New()
@@ -527,3 +527,19 @@ func TestReset_CreatesNewInstance(t *testing.T) {
 	// But at least verify it doesn't crash
 	assert.NotNil(t, newUI)
 }
+
+func TestTruncateLines(t *testing.T) {
+	text := "one\ntwo\nthree\nfour\nfive\n"
+
+	assert.Equal(t, "one\ntwo\n...(3 more lines)\n", TruncateLines(text, 2))
+	assert.Equal(t, "one\ntwo\nthree\nfour\n...(1 more lines)\n", TruncateLines(text, 4))
+
+	// Text that fits, or no limit, is returned unchanged
+	assert.Equal(t, text, TruncateLines(text, 5))
+	assert.Equal(t, text, TruncateLines(text, 0))
+	assert.Equal(t, text, TruncateLines(text, -1))
+	assert.Equal(t, "", TruncateLines("", 1))
+
+	// Text without a trailing newline
+	assert.Equal(t, "a\n...(2 more lines)\n", TruncateLines("a\nb\nc", 1))
+}